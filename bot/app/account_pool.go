@@ -0,0 +1,101 @@
+package app
+
+import (
+	"io"
+	"time"
+
+	"github.com/liuran001/MusicBot-Go/bot/config"
+	logpkg "github.com/liuran001/MusicBot-Go/bot/logger"
+	"github.com/liuran001/MusicBot-Go/bot/platform"
+	platformplugins "github.com/liuran001/MusicBot-Go/bot/platform/plugins"
+)
+
+// poolAccounts builds one more plugin instance per [plugins.<name>@<account>]
+// section and wraps contrib's platforms in account pools, so requests rotate
+// across the accounts. Only the platforms of the extra instances are used;
+// their commands, recognizers and tag providers would duplicate the primary's.
+func poolAccounts(conf *config.Config, name string, factory platformplugins.Factory, contrib *platformplugins.Contribution, log *logpkg.Logger) {
+	labels := conf.PluginAccounts(name)
+	if contrib == nil || len(labels) == 0 {
+		return
+	}
+	primaries := contrib.Platforms
+	if len(primaries) == 0 && contrib.Platform != nil {
+		primaries = []platform.Platform{contrib.Platform}
+	}
+	accounts := make(map[string][]platform.PoolAccount, len(primaries))
+	for _, plat := range primaries {
+		if plat != nil {
+			accounts[plat.Name()] = []platform.PoolAccount{{Label: platform.PrimaryAccount, Platform: plat, VIP: conf.GetPluginBool(name, "vip")}}
+		}
+	}
+	for _, label := range labels {
+		accountConf, err := conf.ForAccount(name, label)
+		if err != nil {
+			continue
+		}
+		if pluginCfg, ok := accountConf.GetPluginConfig(name); ok {
+			if _, hasKey := pluginCfg["enabled"]; hasKey && !accountConf.GetPluginBool(name, "enabled") {
+				continue
+			}
+		}
+		accountContrib, err := factory(accountConf, log)
+		if err != nil || accountContrib == nil {
+			if log != nil {
+				log.Error("plugin account init failed", "plugin", name, "account", label, "error", err)
+			}
+			continue
+		}
+		extras := accountContrib.Platforms
+		if len(extras) == 0 && accountContrib.Platform != nil {
+			extras = []platform.Platform{accountContrib.Platform}
+		}
+		for _, plat := range extras {
+			if plat == nil {
+				continue
+			}
+			if _, ok := accounts[plat.Name()]; !ok {
+				closePlatform(plat)
+				continue
+			}
+			accounts[plat.Name()] = append(accounts[plat.Name()], platform.PoolAccount{Label: label, Platform: plat, VIP: accountConf.GetPluginBool(name, "vip")})
+		}
+	}
+
+	strategy := platform.ParsePoolStrategy(conf.GetPluginString(name, "account_strategy"))
+	cooldown := time.Duration(conf.GetPluginInt(name, "account_cooldown")) * time.Second
+	pooled := make([]platform.Platform, 0, len(primaries))
+	for _, plat := range primaries {
+		if plat == nil {
+			continue
+		}
+		members := accounts[plat.Name()]
+		if len(members) < 2 {
+			pooled = append(pooled, plat)
+			continue
+		}
+		pool, err := platform.NewAccountPool(strategy, cooldown, members...)
+		if err != nil {
+			if log != nil {
+				log.Error("plugin account pool failed", "plugin", name, "error", err)
+			}
+			for _, member := range members[1:] {
+				closePlatform(member.Platform)
+			}
+			pooled = append(pooled, plat)
+			continue
+		}
+		if log != nil {
+			log.Info("plugin account pool enabled", "platform", plat.Name(), "accounts", len(members), "strategy", string(strategy))
+		}
+		pooled = append(pooled, pool)
+	}
+	contrib.Platforms = pooled
+	contrib.Platform = nil
+}
+
+func closePlatform(plat platform.Platform) {
+	if closer, ok := plat.(io.Closer); ok {
+		_ = closer.Close()
+	}
+}
@@ -3,24 +3,32 @@ package app
 import (
 	"context"
 	"fmt"
+	"path/filepath"
 	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	botpkg "github.com/liuran001/MusicBot-Go/bot"
 	"github.com/liuran001/MusicBot-Go/bot/admincmd"
+	"github.com/liuran001/MusicBot-Go/bot/bandwidth"
 	"github.com/liuran001/MusicBot-Go/bot/config"
 	"github.com/liuran001/MusicBot-Go/bot/db"
 	"github.com/liuran001/MusicBot-Go/bot/download"
 	"github.com/liuran001/MusicBot-Go/bot/dynplugin"
+	"github.com/liuran001/MusicBot-Go/bot/filelink"
+	"github.com/liuran001/MusicBot-Go/bot/httpproxy"
 	"github.com/liuran001/MusicBot-Go/bot/i18n"
 	"github.com/liuran001/MusicBot-Go/bot/id3"
 	logpkg "github.com/liuran001/MusicBot-Go/bot/logger"
 	"github.com/liuran001/MusicBot-Go/bot/platform"
 	platformplugins "github.com/liuran001/MusicBot-Go/bot/platform/plugins"
+	"github.com/liuran001/MusicBot-Go/bot/preview"
 	"github.com/liuran001/MusicBot-Go/bot/recognize"
 	"github.com/liuran001/MusicBot-Go/bot/telegram"
 	"github.com/liuran001/MusicBot-Go/bot/telegram/handler"
+	"github.com/liuran001/MusicBot-Go/bot/updater"
 	"github.com/liuran001/MusicBot-Go/bot/worker"
 	"github.com/mymmrac/telego"
 	th "github.com/mymmrac/telego/telegohandler"
@@ -37,6 +45,7 @@ type App struct {
 	DownloadPool             *worker.Pool
 	PlatformManager          platform.Manager
 	DynPlugins               *dynplugin.Manager
+	Updater                  *updater.Updater
 	AdminIDs                 map[int64]struct{}
 	adminSet                 *handler.AdminSet
 	AdminCommands            []admincmd.Command
@@ -47,6 +56,16 @@ type App struct {
 	Build                    BuildInfo
 	botHandler               *th.BotHandler
 	musicHandler             *handler.MusicHandler
+	rateLimiter              *telegram.RateLimiter
+	resourceLimiter          *handler.ResourceRateLimiter
+	downloadBandwidth        *bandwidth.Shaper
+	uploadBandwidth          *bandwidth.Shaper
+	// downloadProxyRoutes is swapped whole on reload; downloads read it
+	// instead of the Config the reload replaces.
+	downloadProxyRoutes atomic.Pointer[proxyRoutes]
+	fileLinks           *filelink.Server
+	// reloadMu serialises /reload with single script plugin reloads.
+	reloadMu sync.Mutex
 }
 
 func registerContribution(
@@ -92,6 +111,74 @@ func registerContribution(
 	}
 }
 
+// builtinAdminCommands are the admin commands Start registers ahead of the
+// plugin ones, plus /reload and /rmcache, which the router matches first.
+// Any of them shadows a plugin command of the same name.
+var builtinAdminCommands = map[string]struct{}{
+	"login": {}, "profile": {}, "credentials": {}, "proxy": {},
+	"scripts": {}, "plugin": {}, "wl": {}, "reload": {}, "rmcache": {},
+}
+
+// scriptCommandConflicts reports whether a script admin command named name
+// would be shadowed by a built-in or an already registered command.
+func scriptCommandConflicts(name string, existing []admincmd.Command) bool {
+	name = strings.TrimSpace(name)
+	if _, ok := builtinAdminCommands[name]; ok {
+		return true
+	}
+	for _, cmd := range existing {
+		if strings.TrimSpace(cmd.Name) == name {
+			return true
+		}
+	}
+	return false
+}
+
+// mergeScriptContributions adds the admin commands, setting definitions and
+// tag providers declared by script plugins. Entries that collide with a
+// built-in or compiled plugin's are skipped, so a script cannot shadow
+// built-in behaviour.
+func mergeScriptContributions(
+	dynManager *dynplugin.Manager,
+	pluginTagProviders map[string]id3.ID3TagProvider,
+	adminCommands *[]admincmd.Command,
+	pluginSettingDefinitions *[]botpkg.PluginSettingDefinition,
+	log *logpkg.Logger,
+) {
+	contrib := dynManager.Contributions()
+	for name, provider := range contrib.TagProviders {
+		if _, exists := pluginTagProviders[name]; exists {
+			continue
+		}
+		pluginTagProviders[name] = provider
+	}
+	for _, cmd := range contrib.Commands {
+		if scriptCommandConflicts(cmd.Name, *adminCommands) {
+			if log != nil {
+				log.Warn("script admin command conflicts with an existing command; ignored", "command", cmd.Name)
+			}
+			continue
+		}
+		*adminCommands = append(*adminCommands, cmd)
+	}
+	for _, def := range contrib.SettingDefinitions {
+		duplicate := false
+		for _, existing := range *pluginSettingDefinitions {
+			if existing.Plugin == def.Plugin && existing.Key == def.Key {
+				duplicate = true
+				break
+			}
+		}
+		if duplicate {
+			if log != nil {
+				log.Warn("script setting conflicts with an existing setting; ignored", "plugin", def.Plugin, "key", def.Key)
+			}
+			continue
+		}
+		*pluginSettingDefinitions = append(*pluginSettingDefinitions, def)
+	}
+}
+
 // BuildInfo provides build-time metadata.
 type BuildInfo struct {
 	RuntimeVer string
@@ -149,11 +236,13 @@ func loadConfigAndLogger(configPath string) (*config.Config, *logpkg.Logger, err
 		conf.GetString("LogLevel"),
 		conf.GetString("LogFormat"),
 		conf.GetBool("LogSource"),
-		conf.GetString("BOT_TOKEN"),
+		append([]string{conf.GetString("BOT_TOKEN")}, conf.CredentialValues()...)...,
 	)
 	if err != nil {
 		return nil, nil, err
 	}
+	rewrapStaleCredentials(conf, log)
+	applyProxyPools(conf, log)
 	return conf, log, nil
 }
 
@@ -279,7 +368,7 @@ func initPluginRuntime(ctx context.Context, conf *config.Config, log *logpkg.Log
 			continue
 		}
 
-		factory, ok := platformplugins.Get(name)
+		factory, ok := platformplugins.Lookup(conf, name)
 		if !ok {
 			continue
 		}
@@ -291,6 +380,7 @@ func initPluginRuntime(ctx context.Context, conf *config.Config, log *logpkg.Log
 			}
 			continue
 		}
+		poolAccounts(conf, name, factory, contrib, log)
 		registerContribution(platformManager, pluginTagProviders, &recognizeService, &adminCommands, &pluginSettingDefinitions, contrib, log)
 	}
 
@@ -303,6 +393,7 @@ func initPluginRuntime(ctx context.Context, conf *config.Config, log *logpkg.Log
 			log.Warn("dynamic plugin load failed", "error", err)
 		}
 	}
+	mergeScriptContributions(dynManager, pluginTagProviders, &adminCommands, &pluginSettingDefinitions, log)
 
 	return
 }
@@ -357,40 +448,34 @@ func (a *App) Start(ctx context.Context) error {
 	}
 
 	proxyAddr := strings.TrimSpace(a.Config.GetString("DownloadProxy"))
+	a.downloadBandwidth = bandwidth.NewShaper(a.Config.DownloadBandwidth())
+	a.uploadBandwidth = bandwidth.NewShaper(a.Config.UploadBandwidth())
+	a.downloadProxyRoutes.Store(newProxyRoutes(a.Config))
 
 	downloadService := download.NewDownloadService(download.DownloadServiceOptions{
-		Timeout:              time.Duration(a.Config.GetInt("DownloadTimeout")) * time.Second,
-		Proxy:                proxyAddr,
+		Timeout: time.Duration(a.Config.GetInt("DownloadTimeout")) * time.Second,
+		Proxy:   proxyAddr,
+		ProxyPool: func(platformName string) string {
+			return a.downloadProxyRoutes.Load().downloadPool(platformName)
+		},
 		CheckMD5:             a.Config.GetBool("CheckMD5"),
 		MaxRetries:           a.Config.GetInt("DownloadMaxRetries"),
 		EnableMultipart:      a.Config.GetBool("EnableMultipartDownload"),
 		MultipartConcurrency: a.Config.GetInt("MultipartConcurrency"),
 		MultipartMinSize:     int64(a.Config.GetInt("MultipartMinSizeMB")) * 1024 * 1024,
+		Bandwidth:            a.downloadBandwidth,
 	})
 	id3Service := id3.NewID3Service(a.Logger)
 
 	tagProviders := a.TagProviders
 
-	rateLimitPerSecond := a.Config.GetFloat64("RateLimitPerSecond")
-	if rateLimitPerSecond <= 0 {
-		rateLimitPerSecond = 1.0
-	}
-	rateLimitBurst := a.Config.GetInt("RateLimitBurst")
-	if rateLimitBurst <= 0 {
-		rateLimitBurst = 3
-	}
-	globalRateLimitPerSecond := a.Config.GetFloat64("GlobalRateLimitPerSecond")
-	if globalRateLimitPerSecond < 0 {
-		globalRateLimitPerSecond = 0
-	}
-	globalRateLimitBurst := a.Config.GetInt("GlobalRateLimitBurst")
-	if globalRateLimitBurst < 0 {
-		globalRateLimitBurst = 0
-	}
+	rateLimitPerSecond, rateLimitBurst, globalRateLimitPerSecond, globalRateLimitBurst := telegramRateLimits(a.Config)
 	rateLimiter := telegram.NewRateLimiterWithGlobal(rateLimitPerSecond, rateLimitBurst, globalRateLimitPerSecond, globalRateLimitBurst)
 	rateLimiter.SetLogger(a.Logger)
 	rateLimiter.StartQueue(ctx, a.Config.GetInt("TelegramSendWorkerCount"), a.Config.GetInt("TelegramSendQueueSize"))
 	resourceLimiter := handler.NewResourceRateLimiter(buildResourceRateLimits(a.Config))
+	a.rateLimiter = rateLimiter
+	a.resourceLimiter = resourceLimiter
 	enableAprilFools := a.Config.GetBool("EnableAprilFools")
 	aprilFoolsTextPrankProbability := a.Config.GetFloat64("AprilFoolsTextPrankProbability")
 	aprilFoolsTrackHijackProbability := a.Config.GetFloat64("AprilFoolsTrackHijackProbability")
@@ -427,11 +512,17 @@ func (a *App) Start(ctx context.Context) error {
 	whitelistIDs := parseIDSet(a.Config.GetString("WhitelistChatIDs"))
 	whitelist := handler.NewWhitelist(a.Config.GetBool("EnableWhitelist"), whitelistIDs, a.AdminIDs, a.ConfigPath)
 
-	adminCommands := make([]admincmd.Command, 0, len(a.AdminCommands)+2)
+	adminCommands := make([]admincmd.Command, 0, len(a.AdminCommands)+3)
 	adminCommands = append(adminCommands,
 		handler.BuildAccountLoginCommand(a.PlatformManager),
 		a.BuildProfileCommand(),
+		a.BuildCredentialCommand(),
+		a.BuildProxyCommand(),
 	)
+	if a.DynPlugins != nil {
+		a.startPluginUpdater(ctx)
+		adminCommands = append(adminCommands, a.BuildScriptStatsCommand(), a.BuildPluginCommand())
+	}
 	if whitelist.Enabled() {
 		adminCommands = append(adminCommands, BuildWhitelistCommand(whitelist))
 	}
@@ -444,6 +535,22 @@ func (a *App) Start(ctx context.Context) error {
 		adminCommandNames = append(adminCommandNames, cmd.Name)
 	}
 
+	// 超出上传上限的文件可经临时下载链接发送（需配置 FileLinkListen）。
+	var fileLinks handler.FileLinkPublisher
+	if listen := strings.TrimSpace(a.Config.GetString("FileLinkListen")); listen != "" {
+		ttl := time.Duration(a.Config.GetInt("FileLinkTTLMinutes")) * time.Minute
+		server, err := filelink.New(a.Config.GetString("FileLinkBaseURL"), filepath.Join(cacheDir, "links"), ttl)
+		if err == nil {
+			err = server.Start(ctx, listen)
+		}
+		if err != nil {
+			a.Logger.Warn("file link server disabled", "listen", listen, "error", err)
+		} else {
+			a.fileLinks = server
+			fileLinks = server
+		}
+	}
+
 	defaultQuality := a.Config.GetString("DefaultQuality")
 	defaultLyricFormat := strings.TrimSpace(a.Config.GetString("DefaultLyricFormat"))
 	if defaultLyricFormat == "" {
@@ -451,6 +558,26 @@ func (a *App) Start(ctx context.Context) error {
 	}
 	pageSize := a.Config.GetInt("ListPageSize")
 	inlinePageSize := a.Config.GetInt("InlineListPageSize")
+	// Previews are cut and encoded with ffmpeg; without it the ▶ buttons are
+	// left off the result lists.
+	var previewHandler *handler.PreviewHandler
+	if a.Config.GetBool("EnablePreview") {
+		if preview.Available() {
+			previewHandler = &handler.PreviewHandler{
+				PlatformManager: a.PlatformManager,
+				DownloadService: downloadService,
+				Store:           a.DB,
+				RateLimiter:     rateLimiter,
+				ResourceLimiter: resourceLimiter,
+				Logger:          a.Logger,
+				CacheDir:        cacheDir,
+				ClipLength:      time.Duration(a.Config.GetInt("PreviewClipSeconds")) * time.Second,
+				Offset:          time.Duration(a.Config.GetInt("PreviewOffsetSeconds")) * time.Second,
+			}
+		} else if a.Logger != nil {
+			a.Logger.Warn("ffmpeg not found, track previews disabled")
+		}
+	}
 	playlistHandler := &handler.PlaylistHandler{
 		PlatformManager: a.PlatformManager,
 		Repo:            a.DB,
@@ -458,8 +585,10 @@ func (a *App) Start(ctx context.Context) error {
 		ResourceLimiter: resourceLimiter,
 		DefaultQuality:  defaultQuality,
 		PageSize:        pageSize,
+		Preview:         previewHandler,
 	}
 	playlistCallback := &handler.PlaylistCallbackHandler{Playlist: playlistHandler, RateLimiter: rateLimiter}
+	chartsHandler := &handler.ChartsHandler{PlatformManager: a.PlatformManager, Playlist: playlistHandler, RateLimiter: rateLimiter, ResourceLimiter: resourceLimiter}
 
 	musicHandler := &handler.MusicHandler{
 		Repo:                      a.DB,
@@ -490,12 +619,33 @@ func (a *App) Start(ctx context.Context) error {
 		UploadBot:                 a.Telegram.UploadClient(),
 		RateLimiter:               rateLimiter,
 		ResourceLimiter:           resourceLimiter,
+		UploadLimitBytes:          a.Config.UploadLimitBytes(),
+		OversizeStrategies:        a.Config.UploadOversizeStrategies(),
+		FileLinks:                 fileLinks,
 		Playlist:                  playlistHandler,
 		RecognizeEnabled:          a.Config.GetBool("EnableRecognize"),
+		LoudnessAnalysis:          a.Config.GetBool("EnableLoudnessAnalysis"),
+		SpectralAnalysis:          a.Config.GetBool("EnableSpectralAnalysis"),
+		IntegrityCheck:            a.Config.GetBool("EnableIntegrityCheck"),
+		DownloadBandwidth:         a.downloadBandwidth,
+		UploadBandwidth:           a.uploadBandwidth,
 		EnableQueueObservability:  a.Config.GetBool("BotDebug"),
 		PluginSettingDefinitions:  a.PluginSettingDefinitions,
 	}
 	musicHandler.Artist = &handler.ArtistHandler{PlatformManager: a.PlatformManager, RateLimiter: rateLimiter, ResourceLimiter: resourceLimiter, Logger: a.Logger}
+	// Artist release watching is disabled with ArtistWatchIntervalMinutes = 0.
+	var artistWatch *handler.ArtistWatchHandler
+	artistWatchInterval := time.Duration(a.Config.GetInt("ArtistWatchIntervalMinutes")) * time.Minute
+	if artistWatchInterval > 0 && a.DB != nil {
+		artistWatch = &handler.ArtistWatchHandler{Store: a.DB, PlatformManager: a.PlatformManager, RateLimiter: rateLimiter, ResourceLimiter: resourceLimiter, Music: musicHandler, PerChatLimit: a.Config.GetInt("ArtistWatchPerChatLimit"), Logger: a.Logger}
+		musicHandler.Artist.Watch = artistWatch
+	}
+	// Playlist sync is disabled with PlaylistSyncIntervalMinutes = 0.
+	var playlistSync *handler.PlaylistSyncHandler
+	playlistSyncInterval := time.Duration(a.Config.GetInt("PlaylistSyncIntervalMinutes")) * time.Minute
+	if playlistSyncInterval > 0 && a.DB != nil {
+		playlistSync = &handler.PlaylistSyncHandler{Store: a.DB, PlatformManager: a.PlatformManager, RateLimiter: rateLimiter, ResourceLimiter: resourceLimiter, Music: musicHandler, PerChatLimit: a.Config.GetInt("PlaylistSyncPerChatLimit"), Logger: a.Logger}
+	}
 	a.musicHandler = musicHandler
 	musicHandler.StartWorker(ctx)
 
@@ -508,7 +658,7 @@ func (a *App) Start(ctx context.Context) error {
 		DefaultLyricFormat:       defaultLyricFormat,
 		PluginSettingDefinitions: a.PluginSettingDefinitions,
 	}
-	searchHandler := &handler.SearchHandler{PlatformManager: a.PlatformManager, Repo: a.DB, RateLimiter: rateLimiter, ResourceLimiter: resourceLimiter, DefaultPlatform: defaultPlatform, FallbackPlatform: searchFallback, PageSize: pageSize}
+	searchHandler := &handler.SearchHandler{PlatformManager: a.PlatformManager, Repo: a.DB, RateLimiter: rateLimiter, ResourceLimiter: resourceLimiter, DefaultPlatform: defaultPlatform, FallbackPlatform: searchFallback, PageSize: pageSize, Preview: previewHandler}
 	favoritesHandler := &handler.FavoritesHandler{Repo: a.DB, PlatformManager: a.PlatformManager, RateLimiter: rateLimiter, Music: musicHandler, BotName: botName, Logger: a.Logger, PageSize: pageSize}
 	favoriteCallback := &handler.FavoriteCallbackHandler{Repo: a.DB, PlatformManager: a.PlatformManager, RateLimiter: rateLimiter, Music: musicHandler, Favorites: favoritesHandler, BotName: botName, Logger: a.Logger, PageSize: pageSize}
 	adminHandler := &handler.AdminCommandHandler{
@@ -518,7 +668,7 @@ func (a *App) Start(ctx context.Context) error {
 		Commands:    adminCommands,
 	}
 	searchCallback := &handler.SearchCallbackHandler{Search: searchHandler, RateLimiter: rateLimiter}
-	reloadHandler := &handler.ReloadHandler{Reload: a.ReloadAll, RateLimiter: rateLimiter, Logger: a.Logger, AdminIDs: a.adminSet}
+	reloadHandler := &handler.ReloadHandler{Reload: a.ReloadConfig, Preview: a.PreviewReload, RateLimiter: rateLimiter, Logger: a.Logger, AdminIDs: a.adminSet}
 
 	enableRecognize := a.Config.GetBool("EnableRecognize")
 
@@ -562,8 +712,10 @@ func (a *App) Start(ctx context.Context) error {
 		Music:                    musicHandler,
 		Playlist:                 playlistHandler,
 		Artist:                   musicHandler.Artist,
+		Charts:                   chartsHandler,
 		Search:                   searchHandler,
 		Lyric:                    routerLyricHandler,
+		Cover:                    &handler.CoverHandler{Music: musicHandler, RateLimiter: rateLimiter},
 		Recognize:                recognizeHandler,
 		GuestMode:                guestModeHandler,
 		MentionRouter:            mentionRouter,
@@ -577,6 +729,7 @@ func (a *App) Start(ctx context.Context) error {
 		SettingsCallback:         &handler.SettingsCallbackHandler{Repo: a.DB, PlatformManager: a.PlatformManager, SettingsHandler: settingsHandler, RateLimiter: rateLimiter},
 		SearchCallback:           searchCallback,
 		PlaylistCallback:         playlistCallback,
+		ChartCallback:            &handler.ChartCallbackHandler{Charts: chartsHandler, RateLimiter: rateLimiter},
 		InlineCollectionCallback: &handler.InlineCollectionCallbackHandler{Chosen: chosenInlineHandler, RateLimiter: rateLimiter},
 		LyricCallback:            &handler.LyricCallbackHandler{PlatformManager: a.PlatformManager, RateLimiter: rateLimiter, ResourceLimiter: resourceLimiter, Repo: a.DB, DefaultPlatform: defaultPlatform, FallbackPlatform: searchFallback, InlineUploadChatID: int64(a.Config.GetInt("InlineUploadChatID")), UploadBot: a.Telegram.UploadClient()},
 		FavoriteCallback:         favoriteCallback,
@@ -606,6 +759,25 @@ func (a *App) Start(ctx context.Context) error {
 	}
 	a.botHandler = botHandler
 
+	if previewHandler != nil {
+		router.PreviewCallback = previewHandler
+	}
+
+	if artistWatch != nil {
+		router.ArtistWatch = artistWatch
+		router.ArtistWatchCallback = &handler.ArtistWatchCallbackHandler{Watch: artistWatch}
+		watcher := &handler.ArtistReleaseWatcher{Store: a.DB, PlatformManager: a.PlatformManager, RateLimiter: rateLimiter, ResourceLimiter: resourceLimiter, Interval: artistWatchInterval, BatchSize: a.Config.GetInt("ArtistWatchBatchSize"), Logger: a.Logger}
+		watcher.Start(ctx, a.Telegram.Client())
+	}
+	if playlistSync != nil {
+		router.PlaylistSync = playlistSync
+		router.PlaylistSyncCallback = &handler.PlaylistSyncCallbackHandler{Sync: playlistSync}
+		watcher := &handler.PlaylistSyncWatcher{Store: a.DB, PlatformManager: a.PlatformManager, RateLimiter: rateLimiter, ResourceLimiter: resourceLimiter, Music: musicHandler, Interval: playlistSyncInterval, BatchSize: a.Config.GetInt("PlaylistSyncBatchSize"), Logger: a.Logger}
+		watcher.Start(ctx, a.Telegram.Client())
+	}
+	transfer := &handler.TransferHandler{PlatformManager: a.PlatformManager, RateLimiter: rateLimiter, ResourceLimiter: resourceLimiter, MaxTracks: a.Config.GetInt("TransferMaxTracks"), Logger: a.Logger}
+	router.Transfer = transfer
+	router.TransferCallback = &handler.TransferCallbackHandler{Transfer: transfer}
 	router.Register(botHandler, botName)
 
 	a.registerLocalizedCommands(ctx, enableRecognize)
@@ -633,6 +805,11 @@ var botCommandSpecs = []localizedCommandSpec{
 	{command: "music", descKey: "cmd_music"},
 	{command: "search", descKey: "cmd_search"},
 	{command: "lyric", descKey: "cmd_lyric"},
+	{command: "cover", descKey: "cover_cmd"},
+	{command: "charts", descKey: "chart_cmd"},
+	{command: "watch", descKey: "artw_cmd"},
+	{command: "subscribe", descKey: "psub_cmd"},
+	{command: "transfer", descKey: "trf_cmd"},
 	{command: "fav", descKey: "cmd_fav"},
 	{command: "settings", descKey: "cmd_settings"},
 	{command: "recognize", descKey: "cmd_recognize", recognize: true},
@@ -823,14 +1000,24 @@ func BuildWhitelistCommand(wl *handler.Whitelist) admincmd.Command {
 
 // ReloadAll reloads config and reinitializes all platform plugins at runtime.
 func (a *App) ReloadAll(ctx context.Context) error {
-	if strings.TrimSpace(a.ConfigPath) == "" {
-		return fmt.Errorf("config path missing")
-	}
-	conf, err := config.Load(a.ConfigPath)
+	_, err := a.ReloadConfig(ctx)
+	return err
+}
+
+// ReloadConfig validates the config file, swaps it in, reinitializes all
+// platform plugins and applies the live-reloadable settings. It returns the
+// changed keys; those marked Restart only take effect after a restart. An
+// invalid file leaves the running config untouched.
+func (a *App) ReloadConfig(ctx context.Context) ([]config.Change, error) {
+	a.reloadMu.Lock()
+	defer a.reloadMu.Unlock()
+	conf, changes, err := a.loadReloadCandidate()
 	if err != nil {
-		return err
+		return nil, err
 	}
 	a.Config = conf
+	applyProxyPools(conf, a.Logger)
+	a.downloadProxyRoutes.Store(newProxyRoutes(conf))
 	// 重建 admin 集合并原子发布：所有 handler 共享 a.adminSet，Replace 后立即对它们生效，
 	// 且不与并发读冲突（handler 通过 AdminSet 的 atomic 快照读取，不再触碰 a.AdminIDs）。
 	a.AdminIDs = parseIDSet(conf.GetString("BotAdmin"))
@@ -839,7 +1026,7 @@ func (a *App) ReloadAll(ctx context.Context) error {
 	if dm, ok := a.PlatformManager.(*platform.DefaultManager); ok {
 		dm.Reset()
 	} else {
-		return fmt.Errorf("platform manager does not support reset")
+		return nil, fmt.Errorf("platform manager does not support reset")
 	}
 
 	dynManager := a.DynPlugins
@@ -869,7 +1056,7 @@ func (a *App) ReloadAll(ctx context.Context) error {
 			}
 			continue
 		}
-		factory, ok := platformplugins.Get(name)
+		factory, ok := platformplugins.Lookup(conf, name)
 		if !ok {
 			continue
 		}
@@ -880,6 +1067,7 @@ func (a *App) ReloadAll(ctx context.Context) error {
 			}
 			continue
 		}
+		poolAccounts(conf, name, factory, contrib, a.Logger)
 		registerContribution(a.PlatformManager, pluginTagProviders, &recognizeService, &adminCommands, &pluginSettingDefinitions, contrib, a.Logger)
 	}
 
@@ -888,6 +1076,7 @@ func (a *App) ReloadAll(ctx context.Context) error {
 			a.Logger.Warn("dynamic plugin reload failed", "error", err)
 		}
 	}
+	mergeScriptContributions(dynManager, pluginTagProviders, &adminCommands, &pluginSettingDefinitions, a.Logger)
 	a.TagProviders = pluginTagProviders
 	a.AdminCommands = adminCommands
 	a.PluginSettingDefinitions = pluginSettingDefinitions
@@ -900,7 +1089,8 @@ func (a *App) ReloadAll(ctx context.Context) error {
 			a.Logger.Warn("failed to start recognition service after reload", "error", err)
 		}
 	}
-	return nil
+	a.applyLiveSettings(conf)
+	return changes, nil
 }
 
 // ReloadDynamicPlugins reloads script-based plugins from disk.
@@ -997,6 +1187,12 @@ func (a *App) Shutdown(ctx context.Context) error {
 	if dm, ok := a.PlatformManager.(*platform.DefaultManager); ok {
 		_ = dm.Close()
 	}
+	// 停止代理池健康探测。
+	httpproxy.SetPools(nil)
+	if a.fileLinks != nil {
+		_ = a.fileLinks.Close()
+		a.fileLinks = nil
+	}
 
 	if a.DB != nil {
 		if err := a.DB.Close(); err != nil {
@@ -1054,6 +1250,20 @@ func mapGormLogLevel(level, fallback string) gormlogger.LogLevel {
 	}
 }
 
+// telegramRateLimits reads the send-side RateLimiter settings, falling back to
+// 1 msg/s with a burst of 3 per chat and disabling invalid global values.
+func telegramRateLimits(c *config.Config) (perSecond float64, burst int, globalPerSecond float64, globalBurst int) {
+	perSecond = c.GetFloat64("RateLimitPerSecond")
+	if perSecond <= 0 {
+		perSecond = 1.0
+	}
+	burst = c.GetInt("RateLimitBurst")
+	if burst <= 0 {
+		burst = 3
+	}
+	return perSecond, burst, max(c.GetFloat64("GlobalRateLimitPerSecond"), 0), max(c.GetInt("GlobalRateLimitBurst"), 0)
+}
+
 // buildResourceRateLimits assembles the per-action rate-limit rules for the
 // ResourceRateLimiter from config. Each action has its own window/per-user/
 // per-platform/global quota. A non-positive quota disables that dimension; an
@@ -1087,5 +1297,13 @@ func buildResourceRateLimits(c *config.Config) map[string]handler.ResourceLimit
 		handler.ActionPlaylist:  rule("PlaylistRateLimit"),
 		handler.ActionEpisode:   rule("EpisodeRateLimit"),
 		handler.ActionArtist:    rule("ArtistRateLimit"),
+		handler.ActionTransfer:  rule("TransferRateLimit"),
+		handler.ActionPreview:   rule("PreviewRateLimit"),
+		// Searches inside /transfer jobs; only per-platform and global apply.
+		handler.ActionTransferSearch: rule("TransferSearchRateLimit"),
+		// Background artist-release polling; only its per-platform and global
+		// quotas are meaningful.
+		handler.ActionArtistWatch:  rule("ArtistWatchRateLimit"),
+		handler.ActionPlaylistSync: rule("PlaylistSyncRateLimit"),
 	}
 }
@@ -0,0 +1,57 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/liuran001/MusicBot-Go/bot/admincmd"
+	"github.com/liuran001/MusicBot-Go/bot/config"
+	logpkg "github.com/liuran001/MusicBot-Go/bot/logger"
+)
+
+// rewrapStaleCredentials encrypts plaintext credentials and re-seals ones
+// under a retired key at startup, so enabling encryption or rotating the key
+// only needs a restart.
+func rewrapStaleCredentials(conf *config.Config, log *logpkg.Logger) {
+	enabled, keyID, stale := conf.CredentialEncryption()
+	if !enabled || stale == 0 {
+		return
+	}
+	written, err := conf.RewrapCredentials()
+	if err != nil {
+		log.Warn("failed to rewrap stored credentials", "key_id", keyID, "error", err)
+		return
+	}
+	log.Info("rewrapped stored credentials with current key", "key_id", keyID, "count", written)
+}
+
+// BuildCredentialCommand returns the /credentials admin command, which shows
+// the encryption state and rewraps stored credentials after a key rotation.
+func (a *App) BuildCredentialCommand() admincmd.Command {
+	return admincmd.Command{
+		Name:        "credentials",
+		Description: "凭证加密状态 (status/rotate)",
+		Handler: func(ctx context.Context, args string) (string, error) {
+			switch strings.ToLower(strings.TrimSpace(args)) {
+			case "", "status":
+				enabled, keyID, stale := a.Config.CredentialEncryption()
+				if !enabled {
+					return "凭证未加密：未配置 CredentialKey / CredentialKeyFile", nil
+				}
+				return fmt.Sprintf("凭证已加密\n当前密钥: %s\n待重新加密: %d", keyID, stale), nil
+			case "rotate", "rewrap":
+				a.reloadMu.Lock()
+				defer a.reloadMu.Unlock()
+				written, err := a.Config.RewrapCredentials()
+				if err != nil {
+					return fmt.Sprintf("重新加密失败: %v", err), nil
+				}
+				_, keyID, _ := a.Config.CredentialEncryption()
+				return fmt.Sprintf("已用密钥 %s 重新加密 %d 项凭证", keyID, written), nil
+			default:
+				return "用法:\n/credentials status\n/credentials rotate", nil
+			}
+		},
+	}
+}
@@ -0,0 +1,121 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/liuran001/MusicBot-Go/bot/admincmd"
+	"github.com/liuran001/MusicBot-Go/bot/updater"
+)
+
+const pluginCommandUsage = "用法:\n/plugin list\n/plugin reload <name>\n/plugin install <路径> [sha256]"
+
+// startPluginUpdater watches PluginScriptDir and reloads a script plugin when
+// its files change (PluginWatchInterval = 0 disables watching), and backs
+// /plugin install.
+func (a *App) startPluginUpdater(ctx context.Context) {
+	keys, err := updater.ParseTrustedKeys(a.Config.GetString("PluginTrustedKeys"))
+	if err != nil && a.Logger != nil {
+		a.Logger.Warn("invalid PluginTrustedKeys, signed installs will fail", "error", err)
+	}
+	scriptDir := strings.TrimSpace(a.Config.GetString("PluginScriptDir"))
+	if scriptDir == "" {
+		scriptDir = "./plugins/scripts"
+	}
+	a.Updater = updater.New(scriptDir, updater.Options{
+		Interval: time.Duration(a.Config.GetInt("PluginWatchInterval")) * time.Second,
+		Plugins: func() []string {
+			return a.Config.PluginNames()
+		},
+		Reload: a.reloadScriptPlugin,
+		Pin: func(name string) string {
+			return a.Config.GetPluginString(name, "version")
+		},
+		RequireChecksum:  a.Config.GetBool("PluginRequireChecksum"),
+		RequireSignature: a.Config.GetBool("PluginRequireSignature"),
+		TrustedKeys:      keys,
+	}, a.Logger)
+	if err := a.Updater.Prime(); err != nil && a.Logger != nil {
+		a.Logger.Warn("failed to scan script plugin dir", "dir", scriptDir, "error", err)
+	}
+	a.Updater.Start(ctx)
+}
+
+// reloadScriptPlugin swaps in one script plugin. A newly installed plugin gets
+// a [plugins.<name>] section, since a full reload only loads configured ones.
+func (a *App) reloadScriptPlugin(ctx context.Context, name string) error {
+	a.reloadMu.Lock()
+	defer a.reloadMu.Unlock()
+	if _, ok := a.Config.GetPluginConfig(name); !ok {
+		if err := a.Config.PersistPluginConfig(name, map[string]string{"enabled": "true"}); err != nil {
+			return err
+		}
+	}
+	return a.DynPlugins.ReloadPlugin(ctx, a.Config, a.PlatformManager, name)
+}
+
+// BuildPluginCommand returns the /plugin admin command for listing, reloading
+// and installing script plugins.
+func (a *App) BuildPluginCommand() admincmd.Command {
+	return admincmd.Command{
+		Name:        "plugin",
+		Description: "脚本插件管理 (list/reload/install)",
+		Handler: func(ctx context.Context, args string) (string, error) {
+			fields := strings.Fields(strings.TrimSpace(args))
+			if len(fields) == 0 {
+				return a.renderPluginList(ctx), nil
+			}
+			switch strings.ToLower(fields[0]) {
+			case "list":
+				return a.renderPluginList(ctx), nil
+			case "reload":
+				if len(fields) < 2 {
+					return "用法: /plugin reload <name>", nil
+				}
+				if err := a.Updater.ReloadPlugin(ctx, fields[1]); err != nil {
+					return fmt.Sprintf("重载 %s 失败，已保留旧版本: %v", fields[1], err), nil
+				}
+				return fmt.Sprintf("已重载脚本插件: %s", fields[1]), nil
+			case "install":
+				if len(fields) < 2 {
+					return "用法: /plugin install <路径> [sha256]", nil
+				}
+				checksum := ""
+				if len(fields) > 2 {
+					checksum = fields[2]
+				}
+				manifest, err := a.Updater.Install(ctx, fields[1], checksum)
+				if err != nil {
+					return fmt.Sprintf("安装失败: %v", err), nil
+				}
+				return fmt.Sprintf("已安装脚本插件: %s %s", manifest.Name, manifest.Version), nil
+			default:
+				return pluginCommandUsage, nil
+			}
+		},
+	}
+}
+
+func (a *App) renderPluginList(ctx context.Context) string {
+	infos := a.DynPlugins.PluginInfos()
+	lines := make([]string, 0, len(infos)+3)
+	if len(infos) == 0 {
+		lines = append(lines, "未加载脚本插件")
+	} else {
+		lines = append(lines, "脚本插件:")
+		for _, info := range infos {
+			version := info.Version
+			if version == "" {
+				version = "未知版本"
+			}
+			lines = append(lines, fmt.Sprintf("%s %s", info.Name, version))
+		}
+	}
+	changed, err := a.Updater.CheckUpdate(ctx)
+	if err == nil && len(changed) > 0 {
+		lines = append(lines, "", "文件已变更，待重载: "+strings.Join(changed, ", "))
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,136 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/liuran001/MusicBot-Go/bot/admincmd"
+	"github.com/liuran001/MusicBot-Go/bot/config"
+	"github.com/liuran001/MusicBot-Go/bot/httpproxy"
+	logpkg "github.com/liuran001/MusicBot-Go/bot/logger"
+)
+
+// applyProxyPools builds the [proxy_pool.*] pools, starts their probes and
+// publishes them to httpproxy, closing the previous set. On error the
+// running pools are kept.
+func applyProxyPools(conf *config.Config, log *logpkg.Logger) {
+	specs, err := conf.ProxyPoolSpecs()
+	if err != nil {
+		log.Error("invalid proxy pools", "error", err)
+		return
+	}
+	pools := make([]*httpproxy.Pool, 0, len(specs))
+	for _, spec := range specs {
+		pool, err := httpproxy.NewPool(spec)
+		if err != nil {
+			log.Error("proxy pool init failed", "pool", spec.Name, "error", err)
+			continue
+		}
+		pool.Start()
+		pools = append(pools, pool)
+	}
+	httpproxy.SetPools(pools)
+	if len(pools) > 0 {
+		log.Info("proxy pools ready", "count", len(pools))
+	}
+}
+
+// proxyRoutes maps platforms to the proxy pool their downloads go through.
+// It is resolved once per config so requests never read a Config that a
+// reload is replacing.
+type proxyRoutes struct {
+	byPlatform map[string]string
+	fallback   string
+}
+
+func newProxyRoutes(conf *config.Config) *proxyRoutes {
+	routes := &proxyRoutes{
+		byPlatform: make(map[string]string),
+		fallback:   conf.ResolveDownloadProxyPool(""),
+	}
+	platforms := conf.PluginNames()
+	if specs, err := conf.ProxyPoolSpecs(); err == nil {
+		for _, spec := range specs {
+			platforms = append(platforms, spec.Platforms...)
+		}
+	}
+	for _, name := range platforms {
+		key := strings.ToLower(strings.TrimSpace(name))
+		if _, ok := routes.byPlatform[key]; !ok && key != "" {
+			routes.byPlatform[key] = conf.ResolveDownloadProxyPool(key)
+		}
+	}
+	return routes
+}
+
+// downloadPool returns the pool for platform, or "" for the DownloadProxy /
+// direct path. A nil table routes everything direct.
+func (r *proxyRoutes) downloadPool(platform string) string {
+	if r == nil {
+		return ""
+	}
+	if pool, ok := r.byPlatform[strings.ToLower(strings.TrimSpace(platform))]; ok {
+		return pool
+	}
+	return r.fallback
+}
+
+// BuildProxyCommand returns the /proxy admin command, which shows the health
+// of each proxy pool and can trigger an immediate probe.
+func (a *App) BuildProxyCommand() admincmd.Command {
+	return admincmd.Command{
+		Name:        "proxy",
+		Description: "代理池状态 (status/probe)",
+		Handler: func(ctx context.Context, args string) (string, error) {
+			pools := httpproxy.Pools()
+			switch strings.ToLower(strings.TrimSpace(args)) {
+			case "", "status":
+			case "probe", "check":
+				for _, pool := range pools {
+					pool.Probe(ctx)
+				}
+			default:
+				return "用法:\n/proxy status\n/proxy probe", nil
+			}
+			if len(pools) == 0 {
+				return "未配置代理池（[proxy_pool.<名称>]）", nil
+			}
+			blocks := make([]string, 0, len(pools))
+			for _, pool := range pools {
+				blocks = append(blocks, renderProxyPool(pool.Status(), time.Now()))
+			}
+			return strings.Join(blocks, "\n\n"), nil
+		},
+	}
+}
+
+func renderProxyPool(status httpproxy.PoolStatus, now time.Time) string {
+	header := "🌐 " + status.Name
+	if len(status.Platforms) > 0 {
+		header += "（" + strings.Join(status.Platforms, ", ") + "）"
+	}
+	lines := []string{header}
+	for _, member := range status.Members {
+		icon := "✅"
+		state := "可用"
+		if !member.Healthy {
+			icon = "❌"
+			state = fmt.Sprintf("暂停至 %s", member.DownUntil.Format("15:04:05"))
+		}
+		latency := "-"
+		if member.Latency > 0 {
+			latency = fmt.Sprintf("%dms", member.Latency.Milliseconds())
+		}
+		line := fmt.Sprintf("%s %s %s · 延迟 %s · 请求 %d / 失败 %d", icon, member.Proxy, state, latency, member.Requests, member.Failures)
+		if !member.LastProbe.IsZero() {
+			line += fmt.Sprintf(" · %s前探测", now.Sub(member.LastProbe).Truncate(time.Second))
+		}
+		if member.LastError != "" && !member.Healthy {
+			line += "\n   " + member.LastError
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
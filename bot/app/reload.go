@@ -0,0 +1,104 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/liuran001/MusicBot-Go/bot/config"
+)
+
+// liveReloadKeys are top-level settings ReloadConfig applies to the running
+// bot. Plugin sections are always live since every plugin is rebuilt.
+var liveReloadKeys = map[string]struct{}{
+	"botadmin":                       {},
+	"ratelimitpersecond":             {},
+	"ratelimitburst":                 {},
+	"globalratelimitpersecond":       {},
+	"globalratelimitburst":           {},
+	"resourceratelimitwindowseconds": {},
+	"downloadqueuewaitlimit":         {},
+	"downloadqueueperuserlimit":      {},
+	"downloadqueueperchatlimit":      {},
+	"downloadqueuegloballimit":       {},
+	"downloadbandwidthlimitkb":       {},
+	"downloadbandwidthperuserkb":     {},
+	"uploadbandwidthlimitkb":         {},
+	"uploadbandwidthperuserkb":       {},
+	// Consumed by plugin factories, which are rebuilt on reload.
+	"apiproxyenabled": {},
+	"apiproxytype":    {},
+	"apiproxyhost":    {},
+	"apiproxyport":    {},
+	"apiproxyauth":    {},
+	"apiproxyheaders": {},
+	"apiproxypool":    {},
+	// Proxy pools are rebuilt on reload and resolved per download.
+	"downloadproxypool": {},
+}
+
+// resourceRuleSuffixes match the per-action quotas read by
+// buildResourceRateLimits, e.g. SearchRateLimitPerUser.
+var resourceRuleSuffixes = []string{"ratelimitperuser", "ratelimitperchat", "ratelimitperplatform", "ratelimitglobal"}
+
+// requiresRestart reports whether a changed key only takes effect on restart.
+func requiresRestart(key string) bool {
+	lower := strings.ToLower(key)
+	if strings.HasPrefix(lower, "plugins.") || strings.HasPrefix(lower, "proxy_pool.") {
+		return false
+	}
+	if _, ok := liveReloadKeys[lower]; ok {
+		return false
+	}
+	for _, suffix := range resourceRuleSuffixes {
+		if strings.HasSuffix(lower, suffix) {
+			return false
+		}
+	}
+	return true
+}
+
+// PreviewReload validates the config file and reports what /reload would
+// change, without applying anything.
+func (a *App) PreviewReload(ctx context.Context) ([]config.Change, error) {
+	_, changes, err := a.loadReloadCandidate()
+	return changes, err
+}
+
+// loadReloadCandidate loads and validates the config file and diffs it
+// against the running config.
+func (a *App) loadReloadCandidate() (*config.Config, []config.Change, error) {
+	if strings.TrimSpace(a.ConfigPath) == "" {
+		return nil, nil, fmt.Errorf("config path missing")
+	}
+	conf, err := config.Load(a.ConfigPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	changes := config.Diff(a.Config, conf)
+	for i := range changes {
+		changes[i].Restart = requiresRestart(changes[i].Key)
+	}
+	return conf, changes, nil
+}
+
+// applyLiveSettings pushes limiter and queue settings into the components
+// built by Start. Before Start has run there is nothing to update.
+func (a *App) applyLiveSettings(conf *config.Config) {
+	if a.rateLimiter != nil {
+		a.rateLimiter.SetLimits(telegramRateLimits(conf))
+	}
+	if a.resourceLimiter != nil {
+		a.resourceLimiter.SetRules(buildResourceRateLimits(conf))
+	}
+	a.downloadBandwidth.SetLimits(conf.DownloadBandwidth())
+	a.uploadBandwidth.SetLimits(conf.UploadBandwidth())
+	if a.musicHandler != nil {
+		a.musicHandler.SetDownloadQueueLimits(
+			conf.GetInt("DownloadQueueWaitLimit"),
+			conf.GetInt("DownloadQueuePerUserLimit"),
+			conf.GetInt("DownloadQueuePerChatLimit"),
+			conf.GetInt("DownloadQueueGlobalLimit"),
+		)
+	}
+}
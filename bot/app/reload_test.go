@@ -0,0 +1,65 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/liuran001/MusicBot-Go/bot/config"
+)
+
+func TestRequiresRestart(t *testing.T) {
+	cases := map[string]bool{
+		"RateLimitPerSecond":             false,
+		"DownloadQueuePerChatLimit":      false,
+		"SearchRateLimitPerUser":         false,
+		"PlaylistSyncRateLimitGlobal":    false,
+		"ResourceRateLimitWindowSeconds": false,
+		"BotAdmin":                       false,
+		"plugins.netease.cookie":         false,
+		"proxy_pool.overseas.proxies":    false,
+		"DownloadProxyPool":              false,
+		"DownloadProxy":                  true,
+		"WorkerPoolSize":                 true,
+		"UploadQueueSize":                true,
+		"BOT_TOKEN":                      true,
+		"Database":                       true,
+	}
+	for key, want := range cases {
+		if got := requiresRestart(key); got != want {
+			t.Errorf("requiresRestart(%q) = %v, want %v", key, got, want)
+		}
+	}
+}
+
+func TestProxyRoutesFollowSwappedConfig(t *testing.T) {
+	load := func(content string) *config.Config {
+		path := filepath.Join(t.TempDir(), "config.ini")
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		conf, err := config.Load(path)
+		if err != nil {
+			t.Fatalf("load: %v", err)
+		}
+		return conf
+	}
+	var a App
+	if got := a.downloadProxyRoutes.Load().downloadPool("netease"); got != "" {
+		t.Fatalf("routes before start = %q, want direct", got)
+	}
+	a.downloadProxyRoutes.Store(newProxyRoutes(load("BOT_TOKEN = test\n[proxy_pool.asia]\nproxies = http://a.example:8080\nplatforms = NetEase\n")))
+	if got := a.downloadProxyRoutes.Load().downloadPool("netease"); got != "asia" {
+		t.Fatalf("netease routed to %q, want asia", got)
+	}
+	if got := a.downloadProxyRoutes.Load().downloadPool("qqmusic"); got != "" {
+		t.Fatalf("qqmusic routed to %q, want direct", got)
+	}
+	a.downloadProxyRoutes.Store(newProxyRoutes(load("BOT_TOKEN = test\nDownloadProxyPool = eu\n[proxy_pool.eu]\nproxies = http://b.example:8080\n[plugins.netease]\ndownload_proxy_pool = none\n")))
+	if got := a.downloadProxyRoutes.Load().downloadPool("netease"); got != "" {
+		t.Fatalf("netease after reload routed to %q, want direct", got)
+	}
+	if got := a.downloadProxyRoutes.Load().downloadPool("qqmusic"); got != "eu" {
+		t.Fatalf("qqmusic after reload routed to %q, want eu", got)
+	}
+}
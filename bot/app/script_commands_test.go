@@ -0,0 +1,39 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/liuran001/MusicBot-Go/bot/admincmd"
+	"github.com/liuran001/MusicBot-Go/bot/telegram/handler"
+)
+
+func TestScriptCommandConflicts(t *testing.T) {
+	a := &App{}
+	builtins := []admincmd.Command{
+		handler.BuildAccountLoginCommand(nil),
+		a.BuildProfileCommand(),
+		a.BuildCredentialCommand(),
+		a.BuildProxyCommand(),
+		a.BuildScriptStatsCommand(),
+		a.BuildPluginCommand(),
+		BuildWhitelistCommand(nil),
+	}
+	for _, cmd := range builtins {
+		if !scriptCommandConflicts(cmd.Name, nil) {
+			t.Errorf("built-in admin command %q is not reserved", cmd.Name)
+		}
+	}
+	for _, name := range []string{"reload", "rmcache"} {
+		if !scriptCommandConflicts(name, nil) {
+			t.Errorf("router command %q is not reserved", name)
+		}
+	}
+
+	compiled := []admincmd.Command{{Name: "netease"}}
+	if !scriptCommandConflicts(" netease ", compiled) {
+		t.Error("a compiled plugin command should block the script one")
+	}
+	if scriptCommandConflicts("myscript", compiled) {
+		t.Error("an unused name should be accepted")
+	}
+}
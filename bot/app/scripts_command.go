@@ -0,0 +1,61 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/liuran001/MusicBot-Go/bot/admincmd"
+	"github.com/liuran001/MusicBot-Go/bot/dynplugin"
+)
+
+// BuildScriptStatsCommand returns the /scripts admin command, which lists each
+// loaded script plugin's instance pool and per-function latency and error
+// counters since the last (re)load.
+func (a *App) BuildScriptStatsCommand() admincmd.Command {
+	return admincmd.Command{
+		Name:        "scripts",
+		Description: "脚本插件调用统计 (延迟/错误)",
+		Handler: func(ctx context.Context, args string) (string, error) {
+			return renderScriptStats(a.DynPlugins.ScriptStats()), nil
+		},
+	}
+}
+
+func renderScriptStats(stats []dynplugin.ScriptStats) string {
+	if len(stats) == 0 {
+		return "未加载脚本插件"
+	}
+	lines := make([]string, 0, len(stats)*4)
+	lines = append(lines, "脚本插件统计 (自上次加载起):")
+	for _, plugin := range stats {
+		timeout := "不限"
+		if plugin.CallTimeout > 0 {
+			timeout = plugin.CallTimeout.String()
+		}
+		rateLimit := "不限"
+		if plugin.CallsPerMinute > 0 {
+			rateLimit = fmt.Sprintf("%d/分钟", plugin.CallsPerMinute)
+		}
+		lines = append(lines, "", fmt.Sprintf("%s: 实例 %d/%d, 已重建 %d, 超时未结束 %d, 超时 %s, 限速 %s",
+			plugin.Plugin, plugin.Live, plugin.PoolSize, plugin.Retired, plugin.Abandoned, timeout, rateLimit))
+		if len(plugin.Funcs) == 0 {
+			lines = append(lines, "  暂无调用")
+			continue
+		}
+		for _, fn := range plugin.Funcs {
+			lines = append(lines, fmt.Sprintf("  %s: 调用 %d, 错误 %d, 超时 %d, panic %d, 拒绝 %d, 平均 %s, 最大 %s",
+				fn.Name, fn.Calls, fn.Errors, fn.Timeouts, fn.Panics, fn.Rejected,
+				formatScriptLatency(fn.AvgLatency), formatScriptLatency(fn.MaxLatency)))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func formatScriptLatency(d time.Duration) string {
+	if d >= time.Second {
+		return d.Round(10 * time.Millisecond).String()
+	}
+	return d.Round(time.Millisecond).String()
+}
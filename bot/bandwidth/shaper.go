@@ -0,0 +1,275 @@
+// Package bandwidth shapes transfer byte rates with token buckets, globally,
+// per platform and per user, and meters the live throughput.
+package bandwidth
+
+import (
+	"context"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+const (
+	// maxChunk bounds a single shaped read, so a read never asks a bucket for
+	// more tokens than its burst holds.
+	maxChunk = 32 * 1024
+
+	userEntryTTL      = 30 * time.Minute
+	userPrunePeriod   = 5 * time.Minute
+	unlimited         = 0
+	meterWindowSecond = 5
+)
+
+// Limits are rates in bytes per second; 0 leaves a dimension unlimited.
+// PerPlatform is keyed by lowercase platform name.
+type Limits struct {
+	Global      int64
+	PerUser     int64
+	PerPlatform map[string]int64
+}
+
+// Shaper rate-limits readers. All methods are safe for concurrent use, and a
+// nil *Shaper passes readers through untouched.
+type Shaper struct {
+	mu        sync.Mutex
+	limits    Limits
+	global    *rate.Limiter
+	platforms map[string]*rate.Limiter
+	users     map[int64]*userLimiter
+	lastPrune time.Time
+	meter     meter
+}
+
+type userLimiter struct {
+	limiter  *rate.Limiter
+	lastUsed time.Time
+}
+
+type userContextKey struct{}
+
+// WithUser tags ctx with the user a transfer is made for, so the per-user
+// limit applies to it.
+func WithUser(ctx context.Context, userID int64) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return context.WithValue(ctx, userContextKey{}, userID)
+}
+
+func userFrom(ctx context.Context) int64 {
+	if ctx == nil {
+		return 0
+	}
+	userID, _ := ctx.Value(userContextKey{}).(int64)
+	return userID
+}
+
+// NewShaper returns a shaper enforcing limits.
+func NewShaper(limits Limits) *Shaper {
+	s := &Shaper{
+		platforms: make(map[string]*rate.Limiter),
+		users:     make(map[int64]*userLimiter),
+		lastPrune: time.Now(),
+	}
+	s.SetLimits(limits)
+	return s
+}
+
+// SetLimits changes the limits in place, e.g. after a config reload. Buckets
+// that stay limited keep their current token balance.
+func (s *Shaper) SetLimits(limits Limits) {
+	if s == nil {
+		return
+	}
+	platforms := make(map[string]int64, len(limits.PerPlatform))
+	for name, limit := range limits.PerPlatform {
+		if limit > 0 {
+			platforms[strings.ToLower(strings.TrimSpace(name))] = limit
+		}
+	}
+	limits.PerPlatform = platforms
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.limits = limits
+	s.global = retune(s.global, limits.Global)
+	for name, limiter := range s.platforms {
+		if _, ok := platforms[name]; !ok {
+			delete(s.platforms, name)
+			continue
+		}
+		s.platforms[name] = retune(limiter, platforms[name])
+	}
+	for name, limit := range platforms {
+		if _, ok := s.platforms[name]; !ok {
+			s.platforms[name] = newLimiter(limit)
+		}
+	}
+	if limits.PerUser <= unlimited {
+		clear(s.users)
+		return
+	}
+	for _, entry := range s.users {
+		entry.limiter = retune(entry.limiter, limits.PerUser)
+	}
+}
+
+// Limits returns the limits in force.
+func (s *Shaper) Limits() Limits {
+	if s == nil {
+		return Limits{}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.limits
+}
+
+// Rate returns the throughput over the last few seconds in bytes per second.
+func (s *Shaper) Rate() int64 {
+	if s == nil {
+		return 0
+	}
+	return s.meter.rate(time.Now())
+}
+
+// Reader wraps r so reads are metered and wait for the global bucket, the
+// bucket of platform (may be "") and the bucket of the user tagged on ctx
+// with WithUser. Waiting is cut short when ctx is done.
+func (s *Shaper) Reader(ctx context.Context, r io.Reader, platform string) io.Reader {
+	if s == nil || r == nil {
+		return r
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return &shapedReader{
+		ctx:      ctx,
+		r:        r,
+		s:        s,
+		platform: strings.ToLower(strings.TrimSpace(platform)),
+		userID:   userFrom(ctx),
+	}
+}
+
+// buckets returns the limiters a read for platform and userID must pass.
+// Limits are looked up on every read so a reload applies to transfers
+// already running.
+func (s *Shaper) buckets(platform string, userID int64, now time.Time) []*rate.Limiter {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]*rate.Limiter, 0, 3)
+	if s.global != nil {
+		out = append(out, s.global)
+	}
+	if limiter := s.platforms[platform]; limiter != nil && platform != "" {
+		out = append(out, limiter)
+	}
+	if userID != 0 && s.limits.PerUser > unlimited {
+		entry := s.users[userID]
+		if entry == nil {
+			entry = &userLimiter{limiter: newLimiter(s.limits.PerUser)}
+			s.users[userID] = entry
+		}
+		entry.lastUsed = now
+		out = append(out, entry.limiter)
+	}
+	if now.Sub(s.lastPrune) >= userPrunePeriod {
+		s.lastPrune = now
+		for id, entry := range s.users {
+			if now.Sub(entry.lastUsed) > userEntryTTL {
+				delete(s.users, id)
+			}
+		}
+	}
+	return out
+}
+
+func newLimiter(bytesPerSecond int64) *rate.Limiter {
+	return rate.NewLimiter(rate.Limit(bytesPerSecond), burstFor(bytesPerSecond))
+}
+
+// retune updates limiter to bytesPerSecond, creating it when needed, or
+// returns nil when the dimension became unlimited.
+func retune(limiter *rate.Limiter, bytesPerSecond int64) *rate.Limiter {
+	if bytesPerSecond <= unlimited {
+		return nil
+	}
+	if limiter == nil {
+		return newLimiter(bytesPerSecond)
+	}
+	limiter.SetLimit(rate.Limit(bytesPerSecond))
+	limiter.SetBurst(burstFor(bytesPerSecond))
+	return limiter
+}
+
+// burstFor allows up to one second of traffic at once, and never less than a
+// single read.
+func burstFor(bytesPerSecond int64) int {
+	return int(max(bytesPerSecond, maxChunk))
+}
+
+type shapedReader struct {
+	ctx      context.Context
+	r        io.Reader
+	s        *Shaper
+	platform string
+	userID   int64
+}
+
+func (r *shapedReader) Read(p []byte) (int, error) {
+	now := time.Now()
+	limiters := r.s.buckets(r.platform, r.userID, now)
+	if len(limiters) > 0 && len(p) > maxChunk {
+		p = p[:maxChunk]
+	}
+	n, err := r.r.Read(p)
+	if n <= 0 {
+		return n, err
+	}
+	r.s.meter.add(now, int64(n))
+	for _, limiter := range limiters {
+		if waitErr := limiter.WaitN(r.ctx, n); waitErr != nil {
+			if ctxErr := r.ctx.Err(); ctxErr != nil {
+				return n, ctxErr
+			}
+			return n, waitErr
+		}
+	}
+	return n, err
+}
+
+// meter counts bytes in one-second buckets over a short sliding window.
+type meter struct {
+	mu      sync.Mutex
+	seconds [meterWindowSecond]int64
+	bytes   [meterWindowSecond]int64
+}
+
+func (m *meter) add(now time.Time, n int64) {
+	sec := now.Unix()
+	i := sec % meterWindowSecond
+	m.mu.Lock()
+	if m.seconds[i] != sec {
+		m.seconds[i] = sec
+		m.bytes[i] = 0
+	}
+	m.bytes[i] += n
+	m.mu.Unlock()
+}
+
+// rate averages the last full seconds, leaving out the one in progress.
+func (m *meter) rate(now time.Time) int64 {
+	sec := now.Unix()
+	var total int64
+	m.mu.Lock()
+	for i := range m.seconds {
+		if age := sec - m.seconds[i]; age >= 1 && age < meterWindowSecond {
+			total += m.bytes[i]
+		}
+	}
+	m.mu.Unlock()
+	return total / (meterWindowSecond - 1)
+}
@@ -0,0 +1,93 @@
+package bandwidth
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+)
+
+func TestShaperReaderLimitsRate(t *testing.T) {
+	const limit = 256 * 1024
+	s := NewShaper(Limits{Global: limit})
+	src := bytes.NewReader(make([]byte, 2*limit))
+
+	start := time.Now()
+	n, err := io.Copy(io.Discard, s.Reader(context.Background(), src, "netease"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 2*limit {
+		t.Fatalf("copied %d bytes, want %d", n, 2*limit)
+	}
+	// The first second is burst; the second has to wait for tokens.
+	if elapsed := time.Since(start); elapsed < 800*time.Millisecond {
+		t.Fatalf("copy took %s, want about 1s", elapsed)
+	}
+}
+
+func TestShaperBuckets(t *testing.T) {
+	s := NewShaper(Limits{PerUser: 1024, PerPlatform: map[string]int64{" QQMusic ": 2048}})
+	now := time.Now()
+
+	if got := len(s.buckets("netease", 0, now)); got != 0 {
+		t.Fatalf("anonymous netease read has %d buckets, want 0", got)
+	}
+	if got := len(s.buckets("qqmusic", 42, now)); got != 2 {
+		t.Fatalf("qqmusic read for a user has %d buckets, want 2", got)
+	}
+	if a, b := s.buckets("", 1, now)[0], s.buckets("", 2, now)[0]; a == b {
+		t.Fatal("users must not share a bucket")
+	}
+
+	s.SetLimits(Limits{Global: 4096})
+	if got := len(s.buckets("qqmusic", 42, now)); got != 1 {
+		t.Fatalf("after reload read has %d buckets, want only the global one", got)
+	}
+	if len(s.users) != 0 {
+		t.Fatalf("per-user buckets kept after the limit was removed: %d", len(s.users))
+	}
+}
+
+func TestShaperReaderCanceled(t *testing.T) {
+	s := NewShaper(Limits{PerUser: maxChunk})
+	ctx, cancel := context.WithCancel(WithUser(context.Background(), 7))
+	r := s.Reader(ctx, bytes.NewReader(make([]byte, 4*maxChunk)), "")
+	buf := make([]byte, maxChunk)
+	if _, err := r.Read(buf); err != nil {
+		t.Fatal(err)
+	}
+	cancel()
+	if _, err := r.Read(buf); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Read after cancel = %v, want context.Canceled", err)
+	}
+}
+
+func TestNilShaperPassesThrough(t *testing.T) {
+	var s *Shaper
+	src := bytes.NewReader([]byte("abc"))
+	if r := s.Reader(context.Background(), src, "netease"); r != src {
+		t.Fatal("nil shaper wrapped the reader")
+	}
+	if s.Rate() != 0 {
+		t.Fatal("nil shaper reported throughput")
+	}
+}
+
+func TestMeterRate(t *testing.T) {
+	var m meter
+	base := time.Unix(1_000_000, 0)
+	for i := range 4 {
+		m.add(base.Add(time.Duration(i)*time.Second), 1000)
+	}
+	// The second in progress is left out of the average.
+	m.add(base.Add(4*time.Second), 1_000_000)
+	if got := m.rate(base.Add(4 * time.Second)); got != 1000 {
+		t.Fatalf("rate = %d, want 1000", got)
+	}
+	if got := m.rate(base.Add(time.Minute)); got != 0 {
+		t.Fatalf("stale rate = %d, want 0", got)
+	}
+}
@@ -0,0 +1,85 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// accountSeparator splits [plugins.<name>@<account>] section names. Such a
+// section configures one extra account of plugin <name>; the bot pools it with
+// the account configured in [plugins.<name>].
+const accountSeparator = "@"
+
+// PluginAccounts returns the labels of the extra accounts configured for
+// plugin, sorted.
+func (c *Config) PluginAccounts(plugin string) []string {
+	labels := make([]string, 0)
+	for name := range c.plugins {
+		base, label, ok := strings.Cut(name, accountSeparator)
+		if ok && base == plugin && strings.TrimSpace(label) != "" {
+			labels = append(labels, label)
+		}
+	}
+	sort.Strings(labels)
+	return labels
+}
+
+// ForAccount returns the config a plugin instance for one extra account is
+// built from. Keys of [plugins.<plugin>@<account>] overlay [plugins.<plugin>],
+// and state the plugin persists under its own name (QR login, cookie import,
+// renewals) is written to the account section. Everything else is shared
+// with c.
+func (c *Config) ForAccount(plugin, account string) (*Config, error) {
+	section := plugin + accountSeparator + account
+	accountCfg, ok := c.plugins[section]
+	if !ok {
+		return nil, fmt.Errorf("plugins.%s not configured", section)
+	}
+	plugins := make(map[string]PluginConfig, len(c.plugins))
+	for name, cfg := range c.plugins {
+		plugins[name] = cfg
+	}
+	merged := make(PluginConfig, len(c.plugins[plugin])+len(accountCfg))
+	for key, value := range c.plugins[plugin] {
+		merged[key] = value
+	}
+	for key, value := range accountCfg {
+		merged[key] = value
+	}
+	plugins[plugin] = merged
+	return &Config{
+		v:           c.v,
+		plugins:     plugins,
+		botProfiles: c.botProfiles,
+		proxyPools:  c.proxyPools,
+		keyNames:    c.keyNames,
+		path:        c.path,
+		statePath:   c.statePath,
+		creds:       c.creds,
+		envKeys:     c.envKeys,
+		parent:      c,
+		account:     account,
+		accountOf:   plugin,
+	}, nil
+}
+
+// persistAccount redirects PersistPluginConfig of an account view to its
+// parent, writing the pooled plugin's keys to the account section.
+func (c *Config) persistAccount(plugin string, pairs map[string]string) error {
+	if plugin != c.accountOf {
+		return c.parent.PersistPluginConfig(plugin, pairs)
+	}
+	if err := c.parent.PersistPluginConfig(plugin+accountSeparator+c.account, pairs); err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	merged := c.plugins[plugin]
+	for key, value := range pairs {
+		if key = strings.TrimSpace(key); key != "" {
+			merged[key] = value
+		}
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestPluginAccounts(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.ini")
+	content := "BOT_TOKEN = test\n\n[plugins.netease]\nmusic_u = main\nspoof_ip = false\n\n[plugins.netease@alt]\nmusic_u = second\nvip = true\n\n[plugins.netease@spare]\n"
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	conf, err := Load(path)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if got := conf.PluginNames(); !reflect.DeepEqual(got, []string{"netease"}) {
+		t.Fatalf("PluginNames() = %v, account sections must not be listed", got)
+	}
+	if got := conf.PluginAccounts("netease"); !reflect.DeepEqual(got, []string{"alt", "spare"}) {
+		t.Fatalf("PluginAccounts() = %v", got)
+	}
+	if _, err := conf.ForAccount("netease", "missing"); err == nil {
+		t.Fatal("ForAccount should reject unconfigured accounts")
+	}
+
+	alt, err := conf.ForAccount("netease", "alt")
+	if err != nil {
+		t.Fatalf("ForAccount: %v", err)
+	}
+	if got := alt.GetPluginString("netease", "music_u"); got != "second" {
+		t.Fatalf("account music_u = %q", got)
+	}
+	if alt.GetPluginBool("netease", "spoof_ip") || !alt.GetPluginBool("netease", "vip") {
+		t.Fatal("account view should overlay the account section on the base section")
+	}
+	if conf.GetPluginBool("netease", "vip") {
+		t.Fatal("account keys must not leak into the base section")
+	}
+
+	if err := alt.PluginPersister("netease")(map[string]string{"music_u": "renewed"}); err != nil {
+		t.Fatalf("persist: %v", err)
+	}
+	if got := alt.GetPluginString("netease", "music_u"); got != "renewed" {
+		t.Fatalf("account view music_u = %q after persist", got)
+	}
+	if got := conf.GetPluginString("netease", "music_u"); got != "main" {
+		t.Fatalf("base music_u = %q, persisted account state must not touch it", got)
+	}
+	written, _ := os.ReadFile(path)
+	section := string(written)[strings.Index(string(written), "[plugins.netease@alt]"):]
+	if !strings.Contains(section, "music_u") || !strings.Contains(section, "renewed") {
+		t.Fatalf("account state should be written to its own section:\n%s", written)
+	}
+}
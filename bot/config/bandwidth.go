@@ -0,0 +1,58 @@
+package config
+
+import (
+	"fmt"
+
+	"github.com/liuran001/MusicBot-Go/bot/bandwidth"
+)
+
+// pluginDownloadBandwidthKey caps one platform's downloads, in KB/s, from its
+// [plugins.<name>] section.
+const pluginDownloadBandwidthKey = "download_bandwidth_kb"
+
+// DownloadBandwidth returns the download byte-rate limits: the global and
+// per-user DownloadBandwidth*KB keys plus each plugin's download_bandwidth_kb.
+func (c *Config) DownloadBandwidth() bandwidth.Limits {
+	limits := bandwidth.Limits{
+		Global:  kbToBytes(c.GetInt("DownloadBandwidthLimitKB")),
+		PerUser: kbToBytes(c.GetInt("DownloadBandwidthPerUserKB")),
+	}
+	for _, name := range c.PluginNames() {
+		if kb := c.GetPluginInt(name, pluginDownloadBandwidthKey); kb > 0 {
+			if limits.PerPlatform == nil {
+				limits.PerPlatform = make(map[string]int64)
+			}
+			limits.PerPlatform[name] = kbToBytes(kb)
+		}
+	}
+	return limits
+}
+
+// UploadBandwidth returns the byte-rate limits for uploads to Telegram.
+func (c *Config) UploadBandwidth() bandwidth.Limits {
+	return bandwidth.Limits{
+		Global:  kbToBytes(c.GetInt("UploadBandwidthLimitKB")),
+		PerUser: kbToBytes(c.GetInt("UploadBandwidthPerUserKB")),
+	}
+}
+
+func kbToBytes(kb int) int64 {
+	if kb <= 0 {
+		return 0
+	}
+	return int64(kb) * 1024
+}
+
+func (c *Config) validateBandwidth() error {
+	for _, key := range []string{"DownloadBandwidthLimitKB", "DownloadBandwidthPerUserKB", "UploadBandwidthLimitKB", "UploadBandwidthPerUserKB"} {
+		if c.GetInt(key) < 0 {
+			return fmt.Errorf("%s must be non-negative", key)
+		}
+	}
+	for _, name := range c.PluginNames() {
+		if c.GetPluginInt(name, pluginDownloadBandwidthKey) < 0 {
+			return fmt.Errorf("plugins.%s.%s must be non-negative", name, pluginDownloadBandwidthKey)
+		}
+	}
+	return nil
+}
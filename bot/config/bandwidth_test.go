@@ -0,0 +1,40 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/liuran001/MusicBot-Go/bot/bandwidth"
+)
+
+func TestDownloadBandwidth(t *testing.T) {
+	conf, err := loadUploadConfig(t, `DownloadBandwidthLimitKB = 4096
+DownloadBandwidthPerUserKB = 1024
+UploadBandwidthLimitKB = 2048
+
+[plugins.kuwo]
+download_bandwidth_kb = 512
+
+[plugins.netease]
+enabled = true
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := bandwidth.Limits{Global: 4096 * 1024, PerUser: 1024 * 1024, PerPlatform: map[string]int64{"kuwo": 512 * 1024}}
+	if got := conf.DownloadBandwidth(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("DownloadBandwidth() = %+v, want %+v", got, want)
+	}
+	if got := conf.UploadBandwidth(); got.Global != 2048*1024 || got.PerUser != 0 {
+		t.Fatalf("UploadBandwidth() = %+v", got)
+	}
+}
+
+func TestValidateBandwidthRejectsNegative(t *testing.T) {
+	if _, err := loadUploadConfig(t, "UploadBandwidthPerUserKB = -1\n"); err == nil {
+		t.Fatal("expected negative UploadBandwidthPerUserKB to be rejected")
+	}
+	if _, err := loadUploadConfig(t, "[plugins.kuwo]\ndownload_bandwidth_kb = -5\n"); err == nil {
+		t.Fatal("expected negative download_bandwidth_kb to be rejected")
+	}
+}
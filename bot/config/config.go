@@ -10,6 +10,7 @@ import (
 	"sync"
 	"unicode/utf8"
 
+	"github.com/liuran001/MusicBot-Go/bot/credential"
 	"github.com/liuran001/MusicBot-Go/bot/httpproxy"
 	"github.com/spf13/viper"
 	"gopkg.in/ini.v1"
@@ -23,11 +24,31 @@ type Config struct {
 	v           *viper.Viper
 	plugins     map[string]PluginConfig
 	botProfiles map[string]map[string]string
+	proxyPools  map[string]map[string]string
+	keyNames    map[string]string
 	path        string
-	mu          sync.Mutex
+	statePath   string
+	creds       *credential.Store
+	// envKeys marks plugin keys supplied by the environment; they are never
+	// written back when credentials are rewrapped.
+	envKeys map[string]map[string]struct{}
+	// envSeeds holds envSeedHash of every plugin key the environment set,
+	// recorded in the state file next to values persisted over it.
+	envSeeds map[string]map[string]string
+	// staleCreds counts stored credentials not yet sealed with the current key.
+	staleCreds int
+	// parent, account and accountOf are set on the per-account views built
+	// by ForAccount.
+	parent    *Config
+	account   string
+	accountOf string
+	mu        sync.Mutex
 }
 
-// Load reads an INI config file and prepares defaults.
+// Load reads an INI config file and prepares defaults. MUSICBOT_* environment
+// variables (and their *_FILE forms) override the file, and the StateFile, if
+// set, overrides both for the plugin keys the bot persisted itself, unless
+// the environment value changed since.
 func Load(path string) (*Config, error) {
 	v := viper.New()
 	v.SetEnvPrefix("MUSIC163BOT")
@@ -45,11 +66,20 @@ func Load(path string) (*Config, error) {
 			v:           v,
 			plugins:     make(map[string]PluginConfig),
 			botProfiles: make(map[string]map[string]string),
+			proxyPools:  make(map[string]map[string]string),
+			keyNames:    make(map[string]string),
 			path:        path,
 		}
 
+		for _, key := range cfg.Section("").Keys() {
+			c.keyNames[strings.ToLower(key.Name())] = key.Name()
+		}
 		loadPlugins(cfg, c)
 		loadBotProfiles(cfg, c)
+		loadProxyPools(cfg, c)
+		if err := c.applyOverrides(os.Environ()); err != nil {
+			return nil, err
+		}
 		if err := c.Validate(); err != nil {
 			return nil, fmt.Errorf("validate config: %w", err)
 		}
@@ -65,15 +95,21 @@ func Load(path string) (*Config, error) {
 		v:           v,
 		plugins:     make(map[string]PluginConfig),
 		botProfiles: make(map[string]map[string]string),
+		proxyPools:  make(map[string]map[string]string),
+		keyNames:    make(map[string]string),
 		path:        path,
 	}
+	if err := c.applyOverrides(os.Environ()); err != nil {
+		return nil, err
+	}
 	if err := c.Validate(); err != nil {
 		return nil, fmt.Errorf("validate config: %w", err)
 	}
 	return c, nil
 }
 
-// PersistPluginConfig writes plugin key-values back to current config file.
+// PersistPluginConfig writes plugin key-values back to the StateFile, or to the
+// current config file when no StateFile is configured.
 // It always upserts [plugins.<name>] and missing keys/sections automatically.
 func (c *Config) PersistPluginConfig(plugin string, pairs map[string]string) error {
 	if c == nil {
@@ -86,8 +122,14 @@ func (c *Config) PersistPluginConfig(plugin string, pairs map[string]string) err
 	if len(pairs) == 0 {
 		return nil
 	}
+	if c.parent != nil {
+		return c.persistAccount(plugin, pairs)
+	}
 
-	path := strings.TrimSpace(c.path)
+	path := c.statePath
+	if path == "" {
+		path = strings.TrimSpace(c.path)
+	}
 	if path == "" {
 		path = strings.TrimSpace(c.v.ConfigFileUsed())
 	}
@@ -104,11 +146,31 @@ func (c *Config) PersistPluginConfig(plugin string, pairs map[string]string) err
 
 	persistPairs := make(map[string]string, len(pairs))
 	for key, value := range pairs {
+		if credential.IsSecretKey(key) {
+			sealed, err := c.creds.Encrypt(value)
+			if err != nil {
+				return fmt.Errorf("encrypt %s: %w", key, err)
+			}
+			value = sealed
+		}
 		persistPairs[key] = formatINIPersistValue(value)
 	}
 	if err := upsertINIWithoutReformat(path, "plugins."+plugin, persistPairs); err != nil {
 		return err
 	}
+	if c.statePath != "" {
+		seeds := make(map[string]string)
+		for key := range pairs {
+			if seed, ok := c.envSeeds[plugin][strings.TrimSpace(key)]; ok {
+				seeds[envSeedKey(plugin, strings.TrimSpace(key))] = seed
+			}
+		}
+		if len(seeds) > 0 {
+			if err := upsertINIWithoutReformat(path, envSeedSection, seeds); err != nil {
+				return err
+			}
+		}
+	}
 
 	pluginCfg, ok := c.plugins[plugin]
 	if !ok || pluginCfg == nil {
@@ -121,6 +183,7 @@ func (c *Config) PersistPluginConfig(plugin string, pairs map[string]string) err
 			continue
 		}
 		pluginCfg[key] = value
+		delete(c.envKeys[plugin], key)
 	}
 
 	return nil
@@ -514,6 +577,16 @@ func (c *Config) Validate() error {
 		}
 	}
 
+	if err := c.validateProxyPools(); err != nil {
+		return err
+	}
+	if err := c.validateUploadPolicy(); err != nil {
+		return err
+	}
+	if err := c.validateBandwidth(); err != nil {
+		return err
+	}
+
 	if c.GetBool("EnableMultipartDownload") && c.GetInt("MultipartConcurrency") <= 0 {
 		return fmt.Errorf("multipart concurrency must be greater than 0 when multipart download is enabled")
 	}
@@ -539,8 +612,12 @@ func (c *Config) Validate() error {
 }
 
 func setDefaults(v *viper.Viper) {
+	// Registered so MUSICBOT_BOT_TOKEN can supply it; Validate requires it.
+	v.SetDefault("BOT_TOKEN", "")
 	v.SetDefault("BotAPI", "https://api.telegram.org")
 	v.SetDefault("BotDebug", false)
+	v.SetDefault("ApiProxyPool", "")
+	v.SetDefault("DownloadProxyPool", "")
 	v.SetDefault("CacheDir", "./cache")
 	v.SetDefault("DownloadTimeout", 60)
 	v.SetDefault("CheckMD5", true)
@@ -560,6 +637,13 @@ func setDefaults(v *viper.Viper) {
 	v.SetDefault("EnableMultipartDownload", true)
 	v.SetDefault("MultipartConcurrency", 4)
 	v.SetDefault("MultipartMinSizeMB", 5)
+	v.SetDefault("EnableLoudnessAnalysis", true)
+	v.SetDefault("EnableSpectralAnalysis", true)
+	v.SetDefault("EnableIntegrityCheck", true)
+	// Voice-note previews; an offset of 0 searches for the chorus.
+	v.SetDefault("EnablePreview", true)
+	v.SetDefault("PreviewClipSeconds", 30)
+	v.SetDefault("PreviewOffsetSeconds", 0)
 	v.SetDefault("ListPageSize", 8)
 	v.SetDefault("InlineListPageSize", 30)
 	v.SetDefault("WorkerPoolSize", 4)
@@ -606,6 +690,34 @@ func setDefaults(v *viper.Viper) {
 	v.SetDefault("ArtistRateLimitPerChat", 10)
 	v.SetDefault("ArtistRateLimitPerPlatform", 12)
 	v.SetDefault("ArtistRateLimitGlobal", 25)
+	v.SetDefault("TransferRateLimitPerUser", 2)
+	v.SetDefault("TransferRateLimitPerChat", 4)
+	v.SetDefault("TransferRateLimitPerPlatform", 6)
+	v.SetDefault("TransferRateLimitGlobal", 10)
+	v.SetDefault("PreviewRateLimitPerUser", 10)
+	v.SetDefault("PreviewRateLimitPerChat", 20)
+	v.SetDefault("PreviewRateLimitPerPlatform", 30)
+	v.SetDefault("PreviewRateLimitGlobal", 60)
+	// Searches run by /transfer jobs, apart from the interactive search quota;
+	// a job takes no more tracks than this budget searches in 15 minutes.
+	v.SetDefault("TransferSearchRateLimitPerPlatform", 30)
+	v.SetDefault("TransferSearchRateLimitGlobal", 60)
+	// Tracks matched per /transfer job.
+	v.SetDefault("TransferMaxTracks", 200)
+	v.SetDefault("ArtistWatchRateLimitPerPlatform", 6)
+	v.SetDefault("ArtistWatchRateLimitGlobal", 15)
+	// Artist release watching: poll interval (0 disables /watch and the follow
+	// button), followed artists per chat, and subscriptions checked per poll.
+	v.SetDefault("ArtistWatchIntervalMinutes", 30)
+	v.SetDefault("ArtistWatchPerChatLimit", 20)
+	v.SetDefault("ArtistWatchBatchSize", 50)
+	v.SetDefault("PlaylistSyncRateLimitPerPlatform", 6)
+	v.SetDefault("PlaylistSyncRateLimitGlobal", 15)
+	// Playlist sync: poll interval (0 disables /subscribe), subscribed
+	// playlists per chat, and subscriptions checked per poll.
+	v.SetDefault("PlaylistSyncIntervalMinutes", 60)
+	v.SetDefault("PlaylistSyncPerChatLimit", 10)
+	v.SetDefault("PlaylistSyncBatchSize", 30)
 	v.SetDefault("DownloadWorkerPoolSize", 0)
 	v.SetDefault("DownloadConcurrency", 4)
 	v.SetDefault("DownloadMaxRetries", 3)
@@ -617,10 +729,37 @@ func setDefaults(v *viper.Viper) {
 	v.SetDefault("UploadWorkerCount", 1)
 	v.SetDefault("UploadQueueSize", 20)
 	v.SetDefault("InlineUploadChatID", 0)
+	// Bandwidth caps in KB/s (0 = unlimited); platforms add their own with
+	// download_bandwidth_kb in their plugin section.
+	v.SetDefault("DownloadBandwidthLimitKB", 0)
+	v.SetDefault("DownloadBandwidthPerUserKB", 0)
+	v.SetDefault("UploadBandwidthLimitKB", 0)
+	v.SetDefault("UploadBandwidthPerUserKB", 0)
+	// Upload size policy: limit in MB (0 = 50 on the public Bot API, 2000 on a
+	// local one), the oversize strategies in order, and the temporary link
+	// server used by the "link" strategy (disabled without FileLinkListen).
+	v.SetDefault("UploadLimitMB", 0)
+	v.SetDefault("UploadOversizeStrategy", "downgrade,transcode,split,link")
+	v.SetDefault("FileLinkListen", "")
+	v.SetDefault("FileLinkBaseURL", "")
+	v.SetDefault("FileLinkTTLMinutes", 60)
 	v.SetDefault("EnableAprilFools", false)
 	v.SetDefault("AprilFoolsTextPrankProbability", 0.01)
 	v.SetDefault("AprilFoolsTrackHijackProbability", 0.15)
 	v.SetDefault("PluginScriptDir", "./plugins/scripts")
+	v.SetDefault("PluginScriptPoolSize", 2)
+	v.SetDefault("PluginScriptCallTimeout", 30)
+	v.SetDefault("PluginScriptCallRateLimit", 0)
+	// Script plugin watching (seconds, 0 disables) and install verification.
+	v.SetDefault("PluginWatchInterval", 5)
+	v.SetDefault("PluginRequireChecksum", false)
+	v.SetDefault("PluginRequireSignature", false)
+	v.SetDefault("PluginTrustedKeys", "")
+	// Writable INI for credentials the bot refreshes itself (empty = config file).
+	v.SetDefault("StateFile", "")
+	// Master keys for encrypting stored credentials (current key first).
+	v.SetDefault("CredentialKey", "")
+	v.SetDefault("CredentialKeyFile", "")
 }
 
 // GetString returns a string value.
@@ -655,13 +794,17 @@ func (c *Config) GetPluginConfig(name string) (PluginConfig, bool) {
 	return cfg, ok
 }
 
-// PluginNames returns the configured plugin names.
+// PluginNames returns the configured plugin names. Extra account sections
+// ([plugins.<name>@<account>]) are listed by PluginAccounts instead.
 func (c *Config) PluginNames() []string {
 	if len(c.plugins) == 0 {
 		return nil
 	}
 	nameList := make([]string, 0, len(c.plugins))
 	for name := range c.plugins {
+		if strings.Contains(name, accountSeparator) {
+			continue
+		}
 		nameList = append(nameList, name)
 	}
 	sort.Strings(nameList)
@@ -733,8 +876,13 @@ func (c *Config) GetPluginBool(plugin, key string) bool {
 }
 
 // ResolveAPIProxyConfig returns effective API proxy config for a plugin.
-// Plugin-level api_proxy_* overrides global ApiProxy* only when explicitly set.
+// A proxy pool (api_proxy_pool, a pool listing the plugin, or ApiProxyPool)
+// wins over single proxies. Plugin-level api_proxy_* overrides global
+// ApiProxy* only when explicitly set.
 func (c *Config) ResolveAPIProxyConfig(plugin string) httpproxy.Config {
+	if pool := c.resolveProxyPool(plugin, "api_proxy_pool", "ApiProxyPool"); pool != "" {
+		return httpproxy.Config{Enabled: true, Pool: pool}.Normalized()
+	}
 	resolved := httpproxy.Config{
 		Enabled: c.GetBool("ApiProxyEnabled"),
 		Type:    c.GetString("ApiProxyType"),
@@ -0,0 +1,126 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/liuran001/MusicBot-Go/bot/credential"
+)
+
+// openCredentials builds the credential store from CredentialKey and
+// CredentialKeyFile (keys from both are combined, CredentialKey first) and
+// decrypts every stored plugin credential in place, so plugins only ever see
+// plaintext.
+func (c *Config) openCredentials() error {
+	keys := credential.ParseKeys(c.GetString("CredentialKey"))
+	if path := strings.TrimSpace(c.GetString("CredentialKeyFile")); path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("read credential key file: %w", err)
+		}
+		keys = append(keys, credential.ParseKeys(string(data))...)
+	}
+	store, err := credential.NewStore(keys)
+	if err != nil {
+		return err
+	}
+	c.creds = store
+	c.staleCreds = 0
+	for plugin, pluginCfg := range c.plugins {
+		for key, raw := range pluginCfg {
+			value, ok := raw.(string)
+			if !ok {
+				continue
+			}
+			plain, current, err := store.Decrypt(value)
+			if err != nil {
+				return fmt.Errorf("plugins.%s.%s: %w", plugin, key, err)
+			}
+			pluginCfg[key] = plain
+			if store == nil || !credential.IsSecretKey(key) || plain == "" || c.fromEnv(plugin, key) {
+				continue
+			}
+			if !current {
+				c.staleCreds++
+			}
+		}
+	}
+	return nil
+}
+
+func (c *Config) fromEnv(plugin, key string) bool {
+	_, ok := c.envKeys[plugin][key]
+	return ok
+}
+
+// CredentialEncryption reports whether stored credentials are encrypted, the
+// current key ID, and how many stored credentials are still in plaintext or
+// sealed with an older key.
+func (c *Config) CredentialEncryption() (enabled bool, keyID string, stale int) {
+	if c == nil || c.creds == nil {
+		return false, "", 0
+	}
+	return true, c.creds.KeyID(), c.staleCreds
+}
+
+// CredentialValues returns the plaintext of every configured plugin
+// credential, for exact-match log redaction.
+func (c *Config) CredentialValues() []string {
+	values := make([]string, 0)
+	for _, pluginCfg := range c.plugins {
+		for key, raw := range pluginCfg {
+			if value, ok := raw.(string); ok && value != "" && credential.IsSecretKey(key) {
+				values = append(values, value)
+			}
+		}
+	}
+	return values
+}
+
+// RewrapCredentials rewrites every stored plugin credential with the current
+// key, completing a key rotation or encrypting credentials that were stored in
+// plaintext. Values supplied by the environment are skipped. It returns the
+// number of values written.
+func (c *Config) RewrapCredentials() (int, error) {
+	if c == nil || c.creds == nil {
+		return 0, fmt.Errorf("no credential key configured")
+	}
+	// Every section, including extra account sections.
+	names := make([]string, 0, len(c.plugins))
+	for name := range c.plugins {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	written := 0
+	for _, plugin := range names {
+		pairs := make(map[string]string)
+		for key, raw := range c.plugins[plugin] {
+			value, ok := raw.(string)
+			if !ok || value == "" || !credential.IsSecretKey(key) || c.fromEnv(plugin, key) {
+				continue
+			}
+			pairs[key] = value
+		}
+		if len(pairs) == 0 {
+			continue
+		}
+		if err := c.PersistPluginConfig(plugin, pairs); err != nil {
+			return written, fmt.Errorf("plugins.%s: %w", plugin, err)
+		}
+		written += len(pairs)
+	}
+	c.staleCreds = 0
+	return written, nil
+}
+
+// PluginPersister returns the function plugins use to save account state
+// (QR login, cookie import, auto renew). Every credential write goes through
+// it, so values land in the StateFile and are encrypted when a credential key
+// is configured.
+func (c *Config) PluginPersister(plugin string) func(map[string]string) error {
+	return func(pairs map[string]string) error {
+		return c.PersistPluginConfig(plugin, pairs)
+	}
+}
@@ -0,0 +1,76 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestEncryptedCredentials(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.ini")
+	statePath := filepath.Join(dir, "state.ini")
+	keyFile := filepath.Join(dir, "credential.key")
+	if err := os.WriteFile(path, []byte("BOT_TOKEN = test\nStateFile = "+statePath+"\nCredentialKeyFile = "+keyFile+"\n\n[plugins.netease]\nmusic_u = plain-in-file\nspoof_ip = true\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keyFile, []byte("first key\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	conf, err := Load(path)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if enabled, _, stale := conf.CredentialEncryption(); !enabled || stale != 1 {
+		t.Fatalf("CredentialEncryption() = %v, stale %d; want the plaintext music_u pending", enabled, stale)
+	}
+	if err := conf.PluginPersister("netease")(map[string]string{"music_u": "refreshed", "nickname": "alice"}); err != nil {
+		t.Fatalf("persist: %v", err)
+	}
+	state, _ := os.ReadFile(statePath)
+	if strings.Contains(string(state), "refreshed") || !strings.Contains(string(state), "enc:v2:") || !strings.Contains(string(state), "nickname = alice") {
+		t.Fatalf("state file should hold only the credential encrypted:\n%s", state)
+	}
+	if got := conf.GetPluginString("netease", "music_u"); got != "refreshed" {
+		t.Fatalf("in-memory value = %q, want plaintext", got)
+	}
+
+	// Rotate: new key first, old key kept for decryption.
+	if err := os.WriteFile(keyFile, []byte("second key\nfirst key\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	rotated, err := Load(path)
+	if err != nil {
+		t.Fatalf("load after rotation: %v", err)
+	}
+	if got := rotated.GetPluginString("netease", "music_u"); got != "refreshed" {
+		t.Fatalf("decrypted value = %q", got)
+	}
+	if _, _, stale := rotated.CredentialEncryption(); stale != 1 {
+		t.Fatalf("value sealed with the old key should be stale, got %d", stale)
+	}
+	if written, err := rotated.RewrapCredentials(); err != nil || written != 1 {
+		t.Fatalf("RewrapCredentials() = %d, %v", written, err)
+	}
+
+	// The old key can now be dropped.
+	if err := os.WriteFile(keyFile, []byte("second key\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	final, err := Load(path)
+	if err != nil {
+		t.Fatalf("load with only the new key: %v", err)
+	}
+	if got := final.GetPluginString("netease", "music_u"); got != "refreshed" {
+		t.Fatalf("value after rotation = %q", got)
+	}
+
+	if err := os.WriteFile(keyFile, []byte("wrong key\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(path); err == nil || !strings.Contains(err.Error(), "plugins.netease.music_u") {
+		t.Fatalf("unknown key should fail the load naming the value, got %v", err)
+	}
+}
@@ -0,0 +1,121 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/liuran001/MusicBot-Go/bot/credential"
+)
+
+// Change is one setting that differs between two loaded configs. Plugin keys
+// are reported as "plugins.<name>.<key>". Values of secret-looking keys are
+// left empty and Secret is set, so a diff can be shown in chat safely.
+type Change struct {
+	Key    string
+	Old    string
+	New    string
+	Secret bool
+	// Restart is set by the caller when the change cannot be applied live.
+	Restart bool
+}
+
+// Diff lists the settings and plugin keys that differ from old to updated,
+// sorted by key. Keys only present through defaults compare equal, so only
+// edits to the file (or environment) show up.
+func Diff(old, updated *Config) []Change {
+	if old == nil || updated == nil {
+		return nil
+	}
+	changes := make([]Change, 0)
+	add := func(key, before, after string) {
+		if before == after {
+			return
+		}
+		change := Change{Key: key, Old: before, New: after}
+		if credential.IsSecretKey(key) {
+			change.Secret = true
+			change.Old, change.New = "", ""
+		}
+		changes = append(changes, change)
+	}
+
+	keys := make(map[string]struct{})
+	for _, key := range old.v.AllKeys() {
+		keys[key] = struct{}{}
+	}
+	for _, key := range updated.v.AllKeys() {
+		keys[key] = struct{}{}
+	}
+	for key := range keys {
+		add(updated.displayKey(old, key), fmt.Sprint(old.v.Get(key)), fmt.Sprint(updated.v.Get(key)))
+	}
+
+	plugins := make(map[string]struct{})
+	for name := range old.plugins {
+		plugins[name] = struct{}{}
+	}
+	for name := range updated.plugins {
+		plugins[name] = struct{}{}
+	}
+	for name := range plugins {
+		before, after := old.plugins[name], updated.plugins[name]
+		pluginKeys := make(map[string]struct{})
+		for key := range before {
+			pluginKeys[key] = struct{}{}
+		}
+		for key := range after {
+			pluginKeys[key] = struct{}{}
+		}
+		for key := range pluginKeys {
+			add("plugins."+name+"."+key, pluginValue(before, key), pluginValue(after, key))
+		}
+	}
+
+	pools := make(map[string]struct{})
+	for name := range old.proxyPools {
+		pools[name] = struct{}{}
+	}
+	for name := range updated.proxyPools {
+		pools[name] = struct{}{}
+	}
+	for name := range pools {
+		before, after := old.proxyPools[name], updated.proxyPools[name]
+		poolKeys := make(map[string]struct{})
+		for key := range before {
+			poolKeys[key] = struct{}{}
+		}
+		for key := range after {
+			poolKeys[key] = struct{}{}
+		}
+		for key := range poolKeys {
+			if key == "proxies" && before[key] != after[key] {
+				// Entries may carry user:pass.
+				changes = append(changes, Change{Key: proxyPoolPrefix + name + "." + key, Secret: true})
+				continue
+			}
+			add(proxyPoolPrefix+name+"."+key, before[key], after[key])
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Key < changes[j].Key })
+	return changes
+}
+
+// displayKey restores the spelling used in the INI file; viper lowercases keys.
+func (c *Config) displayKey(other *Config, key string) string {
+	if name, ok := c.keyNames[key]; ok {
+		return name
+	}
+	if name, ok := other.keyNames[key]; ok {
+		return name
+	}
+	return key
+}
+
+func pluginValue(cfg PluginConfig, key string) string {
+	value, ok := cfg[key]
+	if !ok || value == nil {
+		return ""
+	}
+	return fmt.Sprint(value)
+}
@@ -0,0 +1,67 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	dir := t.TempDir()
+	load := func(name, content string) *Config {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		conf, err := Load(path)
+		if err != nil {
+			t.Fatalf("load %s: %v", name, err)
+		}
+		return conf
+	}
+	old := load("old.ini", `BOT_TOKEN = old-token
+DownloadQueueWaitLimit = 20
+WorkerPoolSize = 4
+
+[plugins.netease]
+cookie = MUSIC_U=a
+api_url = https://a.example
+
+[plugins.kuwo]
+enabled = true
+`)
+	updated := load("new.ini", `BOT_TOKEN = new-token
+DownloadQueueWaitLimit = 50
+WorkerPoolSize = 4
+DownloadQueuePerUserLimit = 2
+
+[plugins.netease]
+cookie = MUSIC_U=b
+api_url = https://b.example
+`)
+
+	// DownloadQueuePerUserLimit only restates its default, so it is not a change.
+	changes := Diff(old, updated)
+	got := make(map[string]Change, len(changes))
+	for _, change := range changes {
+		got[change.Key] = change
+	}
+	if len(changes) != 5 {
+		t.Fatalf("changes = %+v", changes)
+	}
+	if change := got["DownloadQueueWaitLimit"]; change.Old != "20" || change.New != "50" {
+		t.Fatalf("wait limit change = %+v", change)
+	}
+	if change := got["BOT_TOKEN"]; !change.Secret || change.Old != "" || change.New != "" {
+		t.Fatalf("token must be redacted: %+v", change)
+	}
+	if change := got["plugins.netease.cookie"]; !change.Secret {
+		t.Fatalf("plugin cookie must be redacted: %+v", change)
+	}
+	if change := got["plugins.netease.api_url"]; change.Old != "https://a.example" || change.New != "https://b.example" {
+		t.Fatalf("api_url change = %+v", change)
+	}
+	if _, ok := got["plugins.kuwo.enabled"]; !ok {
+		t.Fatalf("removed plugin section should be reported: %+v", changes)
+	}
+}
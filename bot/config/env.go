@@ -0,0 +1,207 @@
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"gopkg.in/ini.v1"
+)
+
+// EnvPrefix starts every environment override. MUSICBOT_<KEY> sets a
+// top-level key, matched case-insensitively with underscores ignored, so
+// MUSICBOT_BOT_TOKEN, MUSICBOT_DEFAULT_PLATFORM and MUSICBOT_DEFAULTPLATFORM
+// all work. MUSICBOT_PLUGINS_<NAME>_<KEY> sets key <key> (lowercased) of
+// [plugins.<name>]. Appending _FILE to any of them reads the value from that
+// file instead, for Docker/Kubernetes secrets; the plain variable wins when
+// both are set. A top-level key that itself ends in File (StateFile) is set
+// directly by MUSICBOT_STATE_FILE.
+const EnvPrefix = "MUSICBOT_"
+
+const (
+	envPluginsPrefix = "PLUGINS_"
+	envFileSuffix    = "_FILE"
+	// envSeedSection of the state file maps "<plugin>.<key>" to the
+	// envSeedHash of the environment value a persisted value replaced.
+	envSeedSection = "env_seeds"
+)
+
+// applyOverrides layers the environment and then the state file over the
+// values read from the config file.
+func (c *Config) applyOverrides(environ []string) error {
+	if err := c.applyEnv(environ); err != nil {
+		return err
+	}
+	c.statePath = strings.TrimSpace(c.GetString("StateFile"))
+	if err := c.loadState(); err != nil {
+		return err
+	}
+	return c.openCredentials()
+}
+
+// applyEnv applies MUSICBOT_* variables. Unknown top-level keys are ignored
+// since they cannot be told apart from unrelated variables.
+func (c *Config) applyEnv(environ []string) error {
+	keys := make(map[string]string)
+	for _, key := range c.v.AllKeys() {
+		keys[normalizeEnvKey(key)] = key
+	}
+	values, err := envOverrides(environ, func(name string) bool {
+		_, ok := keys[normalizeEnvKey(name)]
+		return ok
+	})
+	if err != nil {
+		return err
+	}
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		value := values[name]
+		if rest, ok := strings.CutPrefix(name, envPluginsPrefix); ok {
+			plugin, key := c.splitPluginEnv(rest)
+			if plugin == "" || key == "" {
+				continue
+			}
+			pluginCfg, ok := c.plugins[plugin]
+			if !ok || pluginCfg == nil {
+				pluginCfg = make(PluginConfig)
+				c.plugins[plugin] = pluginCfg
+			}
+			pluginCfg[key] = value
+			if c.envKeys == nil {
+				c.envKeys = make(map[string]map[string]struct{})
+			}
+			if c.envKeys[plugin] == nil {
+				c.envKeys[plugin] = make(map[string]struct{})
+			}
+			c.envKeys[plugin][key] = struct{}{}
+			if c.envSeeds == nil {
+				c.envSeeds = make(map[string]map[string]string)
+			}
+			if c.envSeeds[plugin] == nil {
+				c.envSeeds[plugin] = make(map[string]string)
+			}
+			c.envSeeds[plugin][key] = envSeedHash(value)
+			continue
+		}
+		if key, ok := keys[normalizeEnvKey(name)]; ok {
+			c.v.Set(key, value)
+		}
+	}
+	return nil
+}
+
+// envOverrides collects MUSICBOT_* variables without the prefix, resolving
+// *_FILE indirection for names that are not themselves top-level keys.
+func envOverrides(environ []string, isKey func(string) bool) (map[string]string, error) {
+	plain := make(map[string]string)
+	files := make(map[string]string)
+	for _, entry := range environ {
+		name, value, ok := strings.Cut(entry, "=")
+		if !ok {
+			continue
+		}
+		name, ok = strings.CutPrefix(name, EnvPrefix)
+		if !ok || name == "" {
+			continue
+		}
+		if base, isFile := strings.CutSuffix(name, envFileSuffix); isFile && base != "" && !isKey(name) {
+			files[base] = value
+			continue
+		}
+		plain[name] = value
+	}
+	for name, path := range files {
+		if _, ok := plain[name]; ok {
+			continue
+		}
+		data, err := os.ReadFile(strings.TrimSpace(path))
+		if err != nil {
+			return nil, fmt.Errorf("read %s%s%s: %w", EnvPrefix, name, envFileSuffix, err)
+		}
+		plain[name] = strings.TrimRight(string(data), "\r\n")
+	}
+	return plain, nil
+}
+
+// splitPluginEnv splits NAME_KEY. Configured plugin names are tried first
+// (longest match) so names containing underscores resolve correctly;
+// otherwise the name is the first segment.
+func (c *Config) splitPluginEnv(rest string) (plugin, key string) {
+	upper := strings.ToUpper(rest)
+	for name := range c.plugins {
+		prefix := strings.ToUpper(name) + "_"
+		if strings.HasPrefix(upper, prefix) && len(name) > len(plugin) {
+			plugin = name
+		}
+	}
+	if plugin != "" {
+		return plugin, strings.ToLower(rest[len(plugin)+1:])
+	}
+	name, key, ok := strings.Cut(rest, "_")
+	if !ok {
+		return "", ""
+	}
+	return strings.ToLower(name), strings.ToLower(key)
+}
+
+func normalizeEnvKey(key string) string {
+	return strings.ToLower(strings.ReplaceAll(key, "_", ""))
+}
+
+// envSeedHash identifies an environment value without storing it.
+func envSeedHash(value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return hex.EncodeToString(sum[:8])
+}
+
+func envSeedKey(plugin, key string) string {
+	return plugin + "." + key
+}
+
+// loadState overlays the [plugins.*] sections of the state file. A key also
+// set in the environment keeps the state value only when it was persisted
+// over that same environment value, e.g. a refreshed token replacing its
+// seed; a changed or unrecorded environment value wins. A missing file is
+// fine; it is created on the first PersistPluginConfig.
+func (c *Config) loadState() error {
+	if c.statePath == "" {
+		return nil
+	}
+	if _, err := os.Stat(c.statePath); os.IsNotExist(err) {
+		return nil
+	}
+	state, err := ini.Load(c.statePath)
+	if err != nil {
+		return fmt.Errorf("read state file: %w", err)
+	}
+	seeds := state.Section(envSeedSection)
+	const pluginPrefix = "plugins."
+	for _, section := range state.Sections() {
+		name, ok := strings.CutPrefix(section.Name(), pluginPrefix)
+		if !ok || name == "" {
+			continue
+		}
+		pluginCfg, ok := c.plugins[name]
+		if !ok || pluginCfg == nil {
+			pluginCfg = make(PluginConfig)
+			c.plugins[name] = pluginCfg
+		}
+		for _, key := range section.Keys() {
+			if c.fromEnv(name, key.Name()) {
+				if seeds.Key(envSeedKey(name, key.Name())).String() != c.envSeeds[name][key.Name()] {
+					continue
+				}
+				delete(c.envKeys[name], key.Name())
+			}
+			pluginCfg[key.Name()] = key.Value()
+		}
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestEnvOverrides(t *testing.T) {
+	dir := t.TempDir()
+	secret := filepath.Join(dir, "sp_dc")
+	if err := os.WriteFile(secret, []byte("file-sp-dc\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "config.ini")
+	if err := os.WriteFile(path, []byte(`DefaultPlatform = netease
+
+[plugins.netease]
+music_u = from-file
+
+[plugins.my_mirror]
+base_url = https://a.example
+`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("MUSICBOT_BOT_TOKEN", "env-token")
+	t.Setenv("MUSICBOT_DEFAULT_PLATFORM", "qqmusic")
+	t.Setenv("MUSICBOT_PLUGINS_NETEASE_MUSIC_U", "env-music-u")
+	t.Setenv("MUSICBOT_PLUGINS_SPOTIFY_SP_DC_FILE", secret)
+	t.Setenv("MUSICBOT_PLUGINS_MY_MIRROR_BASE_URL", "https://b.example")
+	t.Setenv("MUSICBOT_PLUGINS_NETEASE_COOKIE", "plain")
+	t.Setenv("MUSICBOT_PLUGINS_NETEASE_COOKIE_FILE", filepath.Join(dir, "missing"))
+
+	conf, err := Load(path)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	checks := map[string]string{
+		"BOT_TOKEN":          conf.GetString("BOT_TOKEN"),
+		"DefaultPlatform":    conf.GetString("DefaultPlatform"),
+		"netease.music_u":    conf.GetPluginString("netease", "music_u"),
+		"spotify.sp_dc":      conf.GetPluginString("spotify", "sp_dc"),
+		"my_mirror.base_url": conf.GetPluginString("my_mirror", "base_url"),
+		"netease.cookie":     conf.GetPluginString("netease", "cookie"),
+	}
+	want := map[string]string{
+		"BOT_TOKEN":          "env-token",
+		"DefaultPlatform":    "qqmusic",
+		"netease.music_u":    "env-music-u",
+		"spotify.sp_dc":      "file-sp-dc",
+		"my_mirror.base_url": "https://b.example",
+		"netease.cookie":     "plain",
+	}
+	for key, got := range checks {
+		if got != want[key] {
+			t.Errorf("%s = %q, want %q", key, got, want[key])
+		}
+	}
+
+	t.Setenv("MUSICBOT_PLUGINS_NETEASE_COOKIE", "")
+	os.Unsetenv("MUSICBOT_PLUGINS_NETEASE_COOKIE")
+	if _, err := Load(path); err == nil {
+		t.Fatal("an unreadable *_FILE must fail the load")
+	}
+}
+
+func TestStateFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.ini")
+	base := "BOT_TOKEN = test\n\n[plugins.netease]\nmusic_u = from-file\n"
+	if err := os.WriteFile(path, []byte(base), 0o400); err != nil {
+		t.Fatal(err)
+	}
+	statePath := filepath.Join(dir, "state", "state.ini")
+	t.Setenv("MUSICBOT_STATE_FILE", statePath)
+	t.Setenv("MUSICBOT_PLUGINS_NETEASE_MUSIC_U", "from-env")
+
+	conf, err := Load(path)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if err := conf.PersistPluginConfig("netease", map[string]string{"music_u": "refreshed"}); err != nil {
+		t.Fatalf("persist: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil || string(data) != base {
+		t.Fatalf("base config must stay untouched, got %q, %v", data, err)
+	}
+
+	reloaded, err := Load(path)
+	if err != nil {
+		t.Fatalf("reload: %v", err)
+	}
+	if got := reloaded.GetPluginString("netease", "music_u"); got != "refreshed" {
+		t.Fatalf("a value persisted over the same env seed should survive a restart, got %q", got)
+	}
+
+	t.Setenv("MUSICBOT_PLUGINS_NETEASE_MUSIC_U", "new-seed")
+	reloaded, err = Load(path)
+	if err != nil {
+		t.Fatalf("reload with a new seed: %v", err)
+	}
+	if got := reloaded.GetPluginString("netease", "music_u"); got != "new-seed" {
+		t.Fatalf("a changed env seed should win over the state file, got %q", got)
+	}
+
+	os.Unsetenv("MUSICBOT_PLUGINS_NETEASE_MUSIC_U")
+	reloaded, err = Load(path)
+	if err != nil {
+		t.Fatalf("reload without env: %v", err)
+	}
+	if got := reloaded.GetPluginString("netease", "music_u"); got != "refreshed" {
+		t.Fatalf("state file should win over the config file, got %q", got)
+	}
+}
@@ -0,0 +1,162 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/liuran001/MusicBot-Go/bot/httpproxy"
+	"gopkg.in/ini.v1"
+)
+
+// proxyPoolPrefix is the INI section prefix for named proxy pools, e.g.
+// [proxy_pool.overseas].
+const proxyPoolPrefix = "proxy_pool."
+
+// loadProxyPools reads every [proxy_pool.<name>] section into c.proxyPools.
+func loadProxyPools(cfg *ini.File, c *Config) {
+	for _, section := range cfg.Sections() {
+		name, ok := strings.CutPrefix(section.Name(), proxyPoolPrefix)
+		name = strings.ToLower(strings.TrimSpace(name))
+		if !ok || name == "" {
+			continue
+		}
+		values := make(map[string]string)
+		for _, key := range section.Keys() {
+			values[strings.ToLower(key.Name())] = strings.TrimSpace(key.Value())
+		}
+		c.proxyPools[name] = values
+	}
+}
+
+// ProxyPoolSpecs parses the configured proxy pools, sorted by name. Keys:
+// proxies (comma or newline separated scheme://[auth@]host:port), platforms,
+// probe_url, probe_interval, cooldown and timeout (seconds).
+func (c *Config) ProxyPoolSpecs() ([]httpproxy.PoolSpec, error) {
+	names := make([]string, 0, len(c.proxyPools))
+	for name := range c.proxyPools {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	specs := make([]httpproxy.PoolSpec, 0, len(names))
+	for _, name := range names {
+		values := c.proxyPools[name]
+		spec := httpproxy.PoolSpec{Name: name, ProbeURL: values["probe_url"], Platforms: splitList(values["platforms"])}
+		for _, raw := range splitList(values["proxies"]) {
+			proxy, err := httpproxy.ParseProxyURL(raw)
+			if err != nil {
+				return nil, fmt.Errorf("proxy_pool.%s: %w", name, err)
+			}
+			proxy.Headers = httpproxy.ParseHeaders(values["headers"])
+			spec.Proxies = append(spec.Proxies, proxy)
+		}
+		if len(spec.Proxies) == 0 {
+			return nil, fmt.Errorf("proxy_pool.%s: proxies is empty", name)
+		}
+		for key, target := range map[string]*time.Duration{"probe_interval": &spec.ProbeInterval, "cooldown": &spec.Cooldown, "timeout": &spec.Timeout} {
+			raw, ok := values[key]
+			if !ok || raw == "" {
+				continue
+			}
+			seconds, err := strconv.Atoi(raw)
+			if err != nil {
+				return nil, fmt.Errorf("proxy_pool.%s.%s: %w", name, key, err)
+			}
+			*target = time.Duration(seconds) * time.Second
+		}
+		specs = append(specs, spec)
+	}
+	return specs, nil
+}
+
+// validateProxyPools checks the pool sections and that every pool referenced
+// by ApiProxyPool, DownloadProxyPool or a plugin exists.
+func (c *Config) validateProxyPools() error {
+	if _, err := c.ProxyPoolSpecs(); err != nil {
+		return err
+	}
+	check := func(where, name string) error {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" || isDirectPool(name) {
+			return nil
+		}
+		if _, ok := c.proxyPools[name]; !ok {
+			return fmt.Errorf("%s: proxy pool %q not configured", where, name)
+		}
+		return nil
+	}
+	for _, key := range []string{"ApiProxyPool", "DownloadProxyPool"} {
+		if err := check(key, c.GetString(key)); err != nil {
+			return err
+		}
+	}
+	for plugin := range c.plugins {
+		for _, key := range []string{"api_proxy_pool", "download_proxy_pool"} {
+			if err := check("plugins."+plugin+"."+key, c.GetPluginString(plugin, key)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// ResolveDownloadProxyPool returns the proxy pool audio downloads of platform
+// go through, or "" for the DownloadProxy / direct path.
+func (c *Config) ResolveDownloadProxyPool(platform string) string {
+	return c.resolveProxyPool(platform, "download_proxy_pool", "DownloadProxyPool")
+}
+
+// resolveProxyPool picks the pool for plugin: its own pluginKey first (none or
+// direct opts out), then a pool listing the plugin in platforms, then the
+// global key.
+func (c *Config) resolveProxyPool(plugin, pluginKey, globalKey string) string {
+	if pluginCfg, ok := c.GetPluginConfig(plugin); ok {
+		if _, exists := pluginCfg[pluginKey]; exists {
+			name := strings.ToLower(strings.TrimSpace(c.GetPluginString(plugin, pluginKey)))
+			if isDirectPool(name) {
+				return ""
+			}
+			return name
+		}
+	}
+	names := make([]string, 0, len(c.proxyPools))
+	for name := range c.proxyPools {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		for _, platform := range splitList(c.proxyPools[name]["platforms"]) {
+			if strings.EqualFold(platform, plugin) {
+				return name
+			}
+		}
+	}
+	name := strings.ToLower(strings.TrimSpace(c.GetString(globalKey)))
+	if isDirectPool(name) {
+		return ""
+	}
+	return name
+}
+
+func isDirectPool(name string) bool {
+	switch name {
+	case "none", "direct", "off":
+		return true
+	}
+	return false
+}
+
+func splitList(raw string) []string {
+	parts := strings.FieldsFunc(raw, func(r rune) bool {
+		return r == ',' || r == '\n' || r == ' '
+	})
+	out := make([]string, 0, len(parts))
+	for _, part := range parts {
+		if part = strings.TrimSpace(part); part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
@@ -0,0 +1,84 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func loadProxyPoolConfig(t *testing.T, content string) *Config {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.ini")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	conf, err := Load(path)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	return conf
+}
+
+func TestProxyPoolRouting(t *testing.T) {
+	conf := loadProxyPoolConfig(t, `BOT_TOKEN = test
+DownloadProxyPool = Overseas
+
+[proxy_pool.Overseas]
+proxies = http://a.example:8080, baidu://token@b.example:443
+platforms = spotify
+probe_interval = 30
+timeout = 5
+
+[plugins.spotify]
+
+[plugins.netease]
+api_proxy_pool = overseas
+download_proxy_pool = none
+`)
+	specs, err := conf.ProxyPoolSpecs()
+	if err != nil {
+		t.Fatalf("ProxyPoolSpecs: %v", err)
+	}
+	if len(specs) != 1 || specs[0].Name != "overseas" || len(specs[0].Proxies) != 2 {
+		t.Fatalf("unexpected specs: %+v", specs)
+	}
+	if specs[0].ProbeInterval != 30*time.Second || specs[0].Timeout != 5*time.Second || specs[0].Proxies[1].Type != "baidu" {
+		t.Fatalf("unexpected spec values: %+v", specs[0])
+	}
+
+	if got := conf.ResolveAPIProxyConfig("spotify"); got.Pool != "overseas" || !got.EnabledAndReady() {
+		t.Fatalf("pool listing the platform should route its API: %+v", got)
+	}
+	if got := conf.ResolveAPIProxyConfig("netease"); got.Pool != "overseas" {
+		t.Fatalf("api_proxy_pool should select the pool: %+v", got)
+	}
+	if got := conf.ResolveAPIProxyConfig("qqmusic"); got.Pool != "" {
+		t.Fatalf("unlisted platform without ApiProxyPool should not use a pool: %+v", got)
+	}
+	if got := conf.ResolveDownloadProxyPool("netease"); got != "" {
+		t.Fatalf("download_proxy_pool = none should opt out, got %q", got)
+	}
+	if got := conf.ResolveDownloadProxyPool("qqmusic"); got != "overseas" {
+		t.Fatalf("DownloadProxyPool should apply globally, got %q", got)
+	}
+}
+
+func TestValidateProxyPools(t *testing.T) {
+	cases := map[string]string{
+		"unknown pool":    "[plugins.netease]\napi_proxy_pool = missing\n",
+		"unknown global":  "DownloadProxyPool = missing\n",
+		"bad scheme":      "[proxy_pool.bad]\nproxies = socks5://h:1\n",
+		"missing proxies": "[proxy_pool.empty]\nplatforms = netease\n",
+		"bad interval":    "[proxy_pool.slow]\nproxies = h:1\nprobe_interval = soon\n",
+	}
+	for name, body := range cases {
+		path := filepath.Join(t.TempDir(), "config.ini")
+		if err := os.WriteFile(path, []byte("BOT_TOKEN = test\n"+body), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := Load(path); err == nil {
+			t.Fatalf("%s: Load should fail validation", name)
+		}
+	}
+}
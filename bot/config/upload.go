@@ -0,0 +1,76 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+const (
+	// officialBotAPI is the public Bot API endpoint; any other BotAPI is
+	// treated as a local server with the larger upload limit.
+	officialBotAPI = "https://api.telegram.org"
+
+	publicUploadLimitMB = 50
+	localUploadLimitMB  = 2000
+)
+
+// Oversize strategies, tried in the order listed in UploadOversizeStrategy.
+const (
+	OversizeDowngrade = "downgrade"
+	OversizeTranscode = "transcode"
+	OversizeSplit     = "split"
+	OversizeLink      = "link"
+)
+
+// UploadLimitBytes returns the largest file the bot may upload: UploadLimitMB
+// when set, otherwise 50MB for the public Bot API and 2000MB for a local one.
+func (c *Config) UploadLimitBytes() int64 {
+	mb := c.GetInt("UploadLimitMB")
+	if mb <= 0 {
+		mb = publicUploadLimitMB
+		if api := strings.TrimRight(strings.TrimSpace(c.GetString("BotAPI")), "/"); api != "" && api != officialBotAPI {
+			mb = localUploadLimitMB
+		}
+	}
+	return int64(mb) * 1024 * 1024
+}
+
+// UploadOversizeStrategies returns the configured oversize strategies in
+// order, lowercased, with duplicates removed. "none" disables them all.
+func (c *Config) UploadOversizeStrategies() []string {
+	seen := make(map[string]bool)
+	out := make([]string, 0, 4)
+	for _, name := range splitList(strings.ToLower(c.GetString("UploadOversizeStrategy"))) {
+		if name == "none" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		out = append(out, name)
+	}
+	return out
+}
+
+func (c *Config) validateUploadPolicy() error {
+	if c.GetInt("UploadLimitMB") < 0 {
+		return fmt.Errorf("uploadlimitmb must be non-negative")
+	}
+	for _, name := range c.UploadOversizeStrategies() {
+		switch name {
+		case OversizeDowngrade, OversizeTranscode, OversizeSplit, OversizeLink:
+		default:
+			return fmt.Errorf("UploadOversizeStrategy: unknown strategy %q", name)
+		}
+	}
+	if strings.TrimSpace(c.GetString("FileLinkListen")) == "" {
+		return nil
+	}
+	base, err := url.Parse(strings.TrimSpace(c.GetString("FileLinkBaseURL")))
+	if err != nil || (base.Scheme != "http" && base.Scheme != "https") || base.Host == "" {
+		return fmt.Errorf("FileLinkBaseURL must be an absolute http(s) URL when FileLinkListen is set")
+	}
+	if c.GetInt("FileLinkTTLMinutes") <= 0 {
+		return fmt.Errorf("filelinkttlminutes must be greater than 0")
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func loadUploadConfig(t *testing.T, content string) (*Config, error) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.ini")
+	if err := os.WriteFile(path, []byte("BOT_TOKEN = test\n"+content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return Load(path)
+}
+
+func TestUploadLimitBytes(t *testing.T) {
+	cases := []struct {
+		content string
+		wantMB  int64
+	}{
+		{"", 50},
+		{"BotAPI = https://api.telegram.org/\n", 50},
+		{"BotAPI = http://127.0.0.1:8081\n", 2000},
+		{"BotAPI = http://127.0.0.1:8081\nUploadLimitMB = 200\n", 200},
+	}
+	for _, tc := range cases {
+		conf, err := loadUploadConfig(t, tc.content)
+		if err != nil {
+			t.Fatalf("load %q: %v", tc.content, err)
+		}
+		if got := conf.UploadLimitBytes(); got != tc.wantMB*1024*1024 {
+			t.Fatalf("UploadLimitBytes(%q) = %d, want %d MB", tc.content, got, tc.wantMB)
+		}
+	}
+}
+
+func TestUploadOversizeStrategies(t *testing.T) {
+	conf, err := loadUploadConfig(t, "UploadOversizeStrategy = Transcode, link, transcode\n")
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if got := conf.UploadOversizeStrategies(); !reflect.DeepEqual(got, []string{"transcode", "link"}) {
+		t.Fatalf("UploadOversizeStrategies() = %v", got)
+	}
+	conf, err = loadUploadConfig(t, "UploadOversizeStrategy = none\n")
+	if err != nil {
+		t.Fatalf("load none: %v", err)
+	}
+	if got := conf.UploadOversizeStrategies(); len(got) != 0 {
+		t.Fatalf("none should disable all strategies, got %v", got)
+	}
+
+	for _, bad := range []string{
+		"UploadOversizeStrategy = shrink\n",
+		"UploadLimitMB = -1\n",
+		"FileLinkListen = :8090\n",
+		"FileLinkListen = :8090\nFileLinkBaseURL = example.com\n",
+	} {
+		if _, err := loadUploadConfig(t, bad); err == nil {
+			t.Fatalf("Load(%q) should fail validation", bad)
+		}
+	}
+	if _, err := loadUploadConfig(t, "FileLinkListen = :8090\nFileLinkBaseURL = https://bot.example.com/dl\n"); err != nil {
+		t.Fatalf("valid file link config rejected: %v", err)
+	}
+}
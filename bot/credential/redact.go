@@ -0,0 +1,94 @@
+package credential
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// Placeholder replaces credential values in logs and admin output.
+const Placeholder = "[REDACTED]"
+
+// secretKeyMarkers flag config keys whose values must never be echoed back.
+// Each is matched against whole words of the key, see keyWords, so "auth"
+// catches ApiProxyAuth but not author, and "key" not keyword.
+var secretKeyMarkers = markerWords("token", "tokens", "cookie", "cookies", "password", "passwd", "secret", "auth", "authorization", "music_u", "sp_dc", "sessdata", "bili_jct", "qm_keyst", "key", "apikey")
+
+// IsSecretKey reports whether a setting or plugin key holds a credential.
+func IsSecretKey(key string) bool {
+	return hasMarker(keyWords(key), secretKeyMarkers)
+}
+
+// logFieldMarkers is the narrower set used for structured log fields, where
+// generic names such as "key" (cache keys) are common.
+var logFieldMarkers = markerWords("token", "tokens", "cookie", "cookies", "password", "passwd", "secret", "music_u", "sp_dc", "sessdata", "bili_jct")
+
+// IsCredentialField reports whether a structured log field or map key names a
+// credential value.
+func IsCredentialField(name string) bool {
+	return hasMarker(keyWords(name), logFieldMarkers) || strings.HasSuffix(strings.ToLower(name), "_key")
+}
+
+// keyWords splits a key into lowercase words on '_', '-', '.' and camelCase
+// boundaries: "SpotifyClientSecret" gives spotify, client, secret and
+// "APIKey" gives api, key.
+func keyWords(key string) []string {
+	var words []string
+	runes := []rune(key)
+	start := 0
+	flush := func(end int) {
+		if end > start {
+			words = append(words, strings.ToLower(string(runes[start:end])))
+		}
+	}
+	for i, r := range runes {
+		switch {
+		case r == '_' || r == '-' || r == '.':
+			flush(i)
+			start = i + 1
+		case i > start && unicode.IsUpper(r):
+			prev := runes[i-1]
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextLower) {
+				flush(i)
+				start = i
+			}
+		}
+	}
+	flush(len(runes))
+	return words
+}
+
+func markerWords(markers ...string) [][]string {
+	out := make([][]string, 0, len(markers))
+	for _, marker := range markers {
+		out = append(out, keyWords(marker))
+	}
+	return out
+}
+
+// hasMarker reports whether words contain any marker as consecutive words.
+func hasMarker(words []string, markers [][]string) bool {
+	for _, marker := range markers {
+		for i := 0; i+len(marker) <= len(words); i++ {
+			if slices.Equal(words[i:i+len(marker)], marker) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// credentialPairPattern matches name=value / name: value pairs whose name is
+// a known cookie or token field, as found in Cookie headers, query strings and
+// error messages.
+var credentialPairPattern = regexp.MustCompile(`(?i)\b(music_u|__csrf|sp_dc|sp_key|sessdata|bili_jct|ac_time_value|qm_keyst|qqmusic_key|psrf_qqaccess_token|psrf_qqrefresh_token|media[-_]user[-_]token|refresh_token|access_token|[a-z_]*_token|token)(\s*[=:]\s*)([^\s;&,"']+)`)
+
+// RedactText masks the values of credential-looking name=value pairs.
+func RedactText(text string) string {
+	if text == "" {
+		return text
+	}
+	return credentialPairPattern.ReplaceAllString(text, "${1}${2}"+Placeholder)
+}
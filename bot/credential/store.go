@@ -0,0 +1,222 @@
+package credential
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"golang.org/x/crypto/argon2"
+)
+
+// encryptedPrefix marks a stored value as ciphertext: enc:v2:<key id>:<data>,
+// where data is base64url(salt || nonce || AES-256-GCM sealed value). The
+// salt feeds the passphrase KDF; values sealed with a raw key carry one too.
+const encryptedPrefix = "enc:v2:"
+
+const (
+	saltSize = 16
+	// Argon2id parameters, RFC 9106's second recommended option.
+	argonTime    = 3
+	argonMemory  = 64 * 1024
+	argonThreads = 4
+)
+
+// keyIDSalt is the fixed salt deriving key IDs from passphrases. It keeps the
+// ID, which is stored next to every value, as costly to brute-force as the key.
+var keyIDSalt = []byte("musicbot-go credential key id")
+
+// ErrUnknownKey is returned when a value was encrypted with a key that is not
+// in the key list, e.g. after an old key was removed too early.
+var ErrUnknownKey = errors.New("credential encrypted with an unknown key")
+
+// Store encrypts credential values at rest. The first key encrypts; every key
+// can decrypt, so a master key is rotated by prepending the new key, rewriting
+// the stored values and then dropping the old key.
+type Store struct {
+	keys []*masterKey
+}
+
+// masterKey is one configured key. A passphrase is stretched with Argon2id per
+// salt; a raw 32-byte key is used as is.
+type masterKey struct {
+	id         string
+	passphrase []byte
+	raw        cipher.AEAD
+	// salt is used for every value this process seals.
+	salt []byte
+
+	mu      sync.Mutex
+	derived map[string]cipher.AEAD
+}
+
+// ParseKeys splits a key list on newlines and commas, skipping blanks and
+// # comments.
+func ParseKeys(raw string) []string {
+	keys := make([]string, 0)
+	for _, line := range strings.Split(raw, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		for _, part := range strings.Split(line, ",") {
+			if part = strings.TrimSpace(part); part != "" {
+				keys = append(keys, part)
+			}
+		}
+	}
+	return keys
+}
+
+// NewStore builds a store from master keys, current key first. A key is a
+// base64 encoded 32-byte value or any passphrase, which is stretched with
+// Argon2id and a random salt stored next to each value. It returns nil when no
+// key is given, meaning credentials stay in plaintext.
+func NewStore(secrets []string) (*Store, error) {
+	store := &Store{}
+	seen := make(map[string]struct{}, len(secrets))
+	for _, secret := range secrets {
+		key, err := newMasterKey(secret)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := seen[key.id]; ok {
+			continue
+		}
+		seen[key.id] = struct{}{}
+		store.keys = append(store.keys, key)
+	}
+	if len(store.keys) == 0 {
+		return nil, nil
+	}
+	return store, nil
+}
+
+func newMasterKey(secret string) (*masterKey, error) {
+	secret = strings.TrimSpace(secret)
+	key := &masterKey{derived: make(map[string]cipher.AEAD)}
+	if decoded, err := base64.StdEncoding.DecodeString(secret); err == nil && len(decoded) == 32 {
+		sum := sha256.Sum256(decoded)
+		key.id = hex.EncodeToString(sum[:4])
+		if key.raw, err = newAEAD(decoded); err != nil {
+			return nil, fmt.Errorf("credential key %s: %w", key.id, err)
+		}
+	} else {
+		key.passphrase = []byte(secret)
+		key.id = hex.EncodeToString(argon2.IDKey(key.passphrase, keyIDSalt, argonTime, argonMemory, argonThreads, 4))
+	}
+	key.salt = make([]byte, saltSize)
+	if _, err := rand.Read(key.salt); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+func newAEAD(material []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(material)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// aead returns the cipher for values sealed with salt, deriving it once per
+// salt.
+func (k *masterKey) aead(salt []byte) (cipher.AEAD, error) {
+	if k.raw != nil {
+		return k.raw, nil
+	}
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if aead, ok := k.derived[string(salt)]; ok {
+		return aead, nil
+	}
+	aead, err := newAEAD(argon2.IDKey(k.passphrase, salt, argonTime, argonMemory, argonThreads, 32))
+	if err != nil {
+		return nil, fmt.Errorf("credential key %s: %w", k.id, err)
+	}
+	k.derived[string(salt)] = aead
+	return aead, nil
+}
+
+// KeyID identifies the current key without revealing it.
+func (s *Store) KeyID() string {
+	if s == nil {
+		return ""
+	}
+	return s.keys[0].id
+}
+
+// IsEncrypted reports whether value is stored ciphertext.
+func IsEncrypted(value string) bool {
+	return strings.HasPrefix(value, encryptedPrefix)
+}
+
+// Encrypt seals value with the current key. Empty values stay empty so a
+// cleared credential still reads as unset.
+func (s *Store) Encrypt(value string) (string, error) {
+	if s == nil || value == "" || IsEncrypted(value) {
+		return value, nil
+	}
+	key := s.keys[0]
+	aead, err := key.aead(key.salt)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	prefix := append(append(make([]byte, 0, saltSize+len(nonce)), key.salt...), nonce...)
+	sealed := aead.Seal(prefix, nonce, []byte(value), []byte(key.id))
+	return encryptedPrefix + key.id + ":" + base64.RawURLEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt opens a value produced by Encrypt; plaintext is returned unchanged.
+// current reports whether the value is already sealed with the current key in
+// the current format, so callers know what to rewrite after a rotation.
+func (s *Store) Decrypt(value string) (plain string, current bool, err error) {
+	if !IsEncrypted(value) {
+		return value, false, nil
+	}
+	id, data, ok := strings.Cut(value[len(encryptedPrefix):], ":")
+	if !ok {
+		return "", false, fmt.Errorf("malformed encrypted credential")
+	}
+	if s == nil {
+		return "", false, fmt.Errorf("credential encrypted with key %s but no key configured", id)
+	}
+	sealed, err := base64.RawURLEncoding.DecodeString(data)
+	if err != nil {
+		return "", false, fmt.Errorf("malformed encrypted credential: %w", err)
+	}
+	for i, key := range s.keys {
+		if key.id != id {
+			continue
+		}
+		if len(sealed) < saltSize {
+			return "", false, fmt.Errorf("malformed encrypted credential")
+		}
+		aead, err := key.aead(sealed[:saltSize])
+		if err != nil {
+			return "", false, err
+		}
+		sealed = sealed[saltSize:]
+		size := aead.NonceSize()
+		if len(sealed) < size {
+			return "", false, fmt.Errorf("malformed encrypted credential")
+		}
+		opened, err := aead.Open(nil, sealed[:size], sealed[size:], []byte(id))
+		if err != nil {
+			return "", false, fmt.Errorf("decrypt credential with key %s: %w", id, err)
+		}
+		return string(opened), i == 0, nil
+	}
+	return "", false, fmt.Errorf("%w %s", ErrUnknownKey, id)
+}
@@ -0,0 +1,141 @@
+package credential
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestStoreRoundTripAndRotation(t *testing.T) {
+	oldKey := base64.StdEncoding.EncodeToString([]byte("0123456789abcdef0123456789abcdef"))
+	old, err := NewStore([]string{oldKey})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sealed, err := old.Encrypt("MUSIC_U=abc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !IsEncrypted(sealed) || strings.Contains(sealed, "abc") {
+		t.Fatalf("sealed value leaks plaintext: %q", sealed)
+	}
+	if again, _ := old.Encrypt(sealed); again != sealed {
+		t.Fatal("encrypting ciphertext must be a no-op")
+	}
+
+	rotated, err := NewStore(ParseKeys("# new key first\nnew passphrase\n" + oldKey))
+	if err != nil {
+		t.Fatal(err)
+	}
+	plain, current, err := rotated.Decrypt(sealed)
+	if err != nil || plain != "MUSIC_U=abc" || current {
+		t.Fatalf("Decrypt with retired key = %q, %v, %v", plain, current, err)
+	}
+	resealed, _ := rotated.Encrypt(plain)
+	if _, current, _ := rotated.Decrypt(resealed); !current {
+		t.Fatal("value sealed after rotation should use the current key")
+	}
+
+	fresh, _ := NewStore([]string{"new passphrase"})
+	if _, _, err := fresh.Decrypt(sealed); !errors.Is(err, ErrUnknownKey) {
+		t.Fatalf("dropped key should report ErrUnknownKey, got %v", err)
+	}
+	var none *Store
+	if _, _, err := none.Decrypt(sealed); err == nil {
+		t.Fatal("ciphertext without any key must fail")
+	}
+	if value, _, err := none.Decrypt("plain"); err != nil || value != "plain" {
+		t.Fatalf("plaintext should pass through, got %q, %v", value, err)
+	}
+
+	tampered := sealed[:len(sealed)-2] + "AA"
+	if _, _, err := old.Decrypt(tampered); err == nil {
+		t.Fatal("tampered ciphertext must fail authentication")
+	}
+}
+
+func TestStorePassphraseUsesSaltedKDF(t *testing.T) {
+	store, err := NewStore([]string{"correct horse"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sealed, err := store.Encrypt("sp_dc=abc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(sealed, encryptedPrefix) {
+		t.Fatalf("sealed value %q does not use the current format", sealed)
+	}
+	data, _ := base64.RawURLEncoding.DecodeString(sealed[strings.LastIndex(sealed, ":")+1:])
+	if len(data) < saltSize || string(data[:saltSize]) != string(store.keys[0].salt) {
+		t.Fatal("sealed value should start with the KDF salt")
+	}
+	if sum := sha256.Sum256([]byte("correct horse")); store.KeyID() == hex.EncodeToString(sum[:4]) {
+		t.Fatal("passphrase key ID must not be an unsalted SHA-256")
+	}
+
+	// A second process draws another salt and still opens the value.
+	other, _ := NewStore([]string{"correct horse"})
+	if plain, current, err := other.Decrypt(sealed); err != nil || plain != "sp_dc=abc" || !current {
+		t.Fatalf("Decrypt in another store = %q, %v, %v", plain, current, err)
+	}
+	wrong, _ := NewStore([]string{"wrong horse"})
+	if _, _, err := wrong.Decrypt(sealed); !errors.Is(err, ErrUnknownKey) {
+		t.Fatalf("other passphrase should not match the key ID, got %v", err)
+	}
+}
+
+func TestNewStoreWithoutKeys(t *testing.T) {
+	store, err := NewStore(ParseKeys(" \n# only a comment\n"))
+	if err != nil || store != nil {
+		t.Fatalf("NewStore() = %v, %v; want nil store", store, err)
+	}
+	if value, _ := store.Encrypt("secret"); value != "secret" {
+		t.Fatal("nil store must leave values in plaintext")
+	}
+}
+
+func TestRedactText(t *testing.T) {
+	cases := map[string]string{
+		"MUSIC_U=abc; __csrf=def; os=pc":   "MUSIC_U=[REDACTED]; __csrf=[REDACTED]; os=pc",
+		"refresh failed: sp_dc: xyz":       "refresh failed: sp_dc: [REDACTED]",
+		"concept_token=t&userid=1":         "concept_token=[REDACTED]&userid=1",
+		"media-user-token=abc":             "media-user-token=[REDACTED]",
+		"plain message with token count 3": "plain message with token count 3",
+	}
+	for input, want := range cases {
+		if got := RedactText(input); got != want {
+			t.Errorf("RedactText(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
+
+func TestIsSecretKeyMatchesWholeWords(t *testing.T) {
+	cases := map[string]bool{
+		"BOT_TOKEN":      true,
+		"CredentialKey":  true,
+		"ApiProxyAuth":   true,
+		"music_u":        true,
+		"SP_DC":          true,
+		"client-secret":  true,
+		"APIKey":         true,
+		"qqmusic.key":    true,
+		"author":         false,
+		"keyword":        false,
+		"AuthorName":     false,
+		"monkey":         false,
+		"sp_dc_fallback": true,
+		"music":          false,
+	}
+	for key, want := range cases {
+		if got := IsSecretKey(key); got != want {
+			t.Errorf("IsSecretKey(%q) = %v, want %v (words %v)", key, got, want, keyWords(key))
+		}
+	}
+	if IsCredentialField("keyword") || !IsCredentialField("refresh_token") || !IsCredentialField("cache_key") {
+		t.Error("IsCredentialField should match whole words and the _key suffix")
+	}
+}
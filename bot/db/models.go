@@ -1,6 +1,8 @@
 package db
 
 import (
+	"encoding/json"
+	"strings"
 	"time"
 
 	"github.com/liuran001/MusicBot-Go/bot"
@@ -10,14 +12,21 @@ import (
 // SongInfoModel mirrors the song_infos schema with multi-platform support.
 type SongInfoModel struct {
 	gorm.Model
-	Platform        string `gorm:"not null;default:'netease';index:idx_platform_track_quality,unique"`
-	TrackID         string `gorm:"not null;default:'';index:idx_platform_track_quality,unique"`
-	Quality         string `gorm:"not null;default:'hires';index:idx_platform_track_quality,unique"`
+	Platform        string `gorm:"not null;default:'netease';index:idx_platform_track_quality_format,unique"`
+	TrackID         string `gorm:"not null;default:'';index:idx_platform_track_quality_format,unique"`
+	Quality         string `gorm:"not null;default:'hires';index:idx_platform_track_quality_format,unique"`
+	OutputFormat    string `gorm:"not null;default:'';index:idx_platform_track_quality_format,unique"`
 	QualityVerified bool   `gorm:"not null;default:false"`
 	QualityRevision int    `gorm:"not null;default:0"`
 	AudioCodec      string
 	SampleRate      int
 	BitDepth        int
+	LoudnessLUFS    float64
+	TruePeak        float64
+	SpectralVerdict string
+	SpectralCutoff  int
+	EffectiveBits   int
+	IntegrityStatus string
 	MusicID         int // Deprecated: Legacy NetEase music ID (kept for backward compatibility)
 	SongName        string
 	SongArtists     string
@@ -66,11 +75,18 @@ func toInternal(model SongInfoModel) *bot.SongInfo {
 		Platform:        model.Platform,
 		TrackID:         model.TrackID,
 		Quality:         model.Quality,
+		OutputFormat:    model.OutputFormat,
 		QualityVerified: model.QualityVerified,
 		QualityRevision: model.QualityRevision,
 		AudioCodec:      model.AudioCodec,
 		SampleRate:      model.SampleRate,
 		BitDepth:        model.BitDepth,
+		LoudnessLUFS:    model.LoudnessLUFS,
+		TruePeak:        model.TruePeak,
+		SpectralVerdict: model.SpectralVerdict,
+		SpectralCutoff:  model.SpectralCutoff,
+		EffectiveBits:   model.EffectiveBits,
+		IntegrityStatus: model.IntegrityStatus,
 		MusicID:         model.MusicID,
 		SongName:        model.SongName,
 		SongArtists:     model.SongArtists,
@@ -105,11 +121,18 @@ func toModel(info *bot.SongInfo) *SongInfoModel {
 		Platform:        info.Platform,
 		TrackID:         info.TrackID,
 		Quality:         info.Quality,
+		OutputFormat:    info.OutputFormat,
 		QualityVerified: info.QualityVerified,
 		QualityRevision: info.QualityRevision,
 		AudioCodec:      info.AudioCodec,
 		SampleRate:      info.SampleRate,
 		BitDepth:        info.BitDepth,
+		LoudnessLUFS:    info.LoudnessLUFS,
+		TruePeak:        info.TruePeak,
+		SpectralVerdict: info.SpectralVerdict,
+		SpectralCutoff:  info.SpectralCutoff,
+		EffectiveBits:   info.EffectiveBits,
+		IntegrityStatus: info.IntegrityStatus,
 		MusicID:         info.MusicID,
 		SongName:        info.SongName,
 		SongArtists:     info.SongArtists,
@@ -160,12 +183,22 @@ func deletedAtPtr(value gorm.DeletedAt) *time.Time {
 // UserSettingsModel stores user preferences for the bot.
 type UserSettingsModel struct {
 	gorm.Model
-	UserID             int64  `gorm:"uniqueIndex;not null"`
-	DefaultPlatform    string `gorm:"not null;default:'netease'"`
-	DefaultQuality     string `gorm:"not null;default:'hires'"`
-	AutoDeleteList     bool   `gorm:"not null;default:false"`
-	AutoLinkDetect     bool   `gorm:"not null;default:true"`
-	DefaultLyricFormat string `gorm:"not null;default:'lrc'"`
+	UserID          int64  `gorm:"uniqueIndex;not null"`
+	DefaultPlatform string `gorm:"not null;default:'netease'"`
+	DefaultQuality  string `gorm:"not null;default:'hires'"`
+	// DefaultOutputFormat is the transcode target for downloads; "original"
+	// uploads the file as delivered by the platform.
+	DefaultOutputFormat string `gorm:"not null;default:'original'"`
+	AutoDeleteList      bool   `gorm:"not null;default:false"`
+	AutoLinkDetect      bool   `gorm:"not null;default:true"`
+	DefaultLyricFormat  string `gorm:"not null;default:'lrc'"`
+	// Upload templates; empty means the built-in output. TagTemplates is a
+	// JSON object of tag field to template.
+	FilenameTemplate string `gorm:"type:text;not null;default:''"`
+	CaptionTemplate  string `gorm:"type:text;not null;default:''"`
+	TagTemplates     string `gorm:"type:text;not null;default:''"`
+	// CoverEmbed is the embedded-artwork policy; empty keeps the default.
+	CoverEmbed string `gorm:"not null;default:''"`
 	// Language is the persisted UI-language override (2-letter ISO 639-1, e.g.
 	// "zh"/"en"/"ja"). Empty means "auto-detect from the Telegram client".
 	Language string `gorm:"not null;default:''"`
@@ -182,12 +215,22 @@ func (UserSettingsModel) TableName() string {
 // GroupSettingsModel stores group preferences for the bot.
 type GroupSettingsModel struct {
 	gorm.Model
-	ChatID             int64  `gorm:"uniqueIndex;not null"`
-	DefaultPlatform    string `gorm:"not null;default:'netease'"`
-	DefaultQuality     string `gorm:"not null;default:'hires'"`
-	AutoDeleteList     bool   `gorm:"not null;default:true"`
-	AutoLinkDetect     bool   `gorm:"not null;default:true"`
-	DefaultLyricFormat string `gorm:"not null;default:'lrc'"`
+	ChatID          int64  `gorm:"uniqueIndex;not null"`
+	DefaultPlatform string `gorm:"not null;default:'netease'"`
+	DefaultQuality  string `gorm:"not null;default:'hires'"`
+	// DefaultOutputFormat is the transcode target for downloads; "original"
+	// uploads the file as delivered by the platform.
+	DefaultOutputFormat string `gorm:"not null;default:'original'"`
+	AutoDeleteList      bool   `gorm:"not null;default:true"`
+	AutoLinkDetect      bool   `gorm:"not null;default:true"`
+	DefaultLyricFormat  string `gorm:"not null;default:'lrc'"`
+	// Upload templates; empty means the built-in output. TagTemplates is a
+	// JSON object of tag field to template.
+	FilenameTemplate string `gorm:"type:text;not null;default:''"`
+	CaptionTemplate  string `gorm:"type:text;not null;default:''"`
+	TagTemplates     string `gorm:"type:text;not null;default:''"`
+	// CoverEmbed is the embedded-artwork policy; empty keeps the default.
+	CoverEmbed string `gorm:"not null;default:''"`
 	// Language is the persisted UI-language override (2-letter ISO 639-1, e.g.
 	// "zh"/"en"/"ja"). Empty means "auto-detect from the Telegram client".
 	Language string `gorm:"not null;default:''"`
@@ -279,3 +322,148 @@ func toFavoriteModel(fav *bot.Favorite) *FavoriteModel {
 	}
 	return model
 }
+
+// ArtistSubscriptionModel stores one chat's subscription to an artist's new
+// releases. KnownReleaseIDs is a newline-separated list of release IDs already
+// seen, newest first, capped by the watcher.
+type ArtistSubscriptionModel struct {
+	gorm.Model
+	ChatID          int64  `gorm:"uniqueIndex:idx_artist_sub_chat_artist,priority:1;not null"`
+	Platform        string `gorm:"uniqueIndex:idx_artist_sub_chat_artist,priority:2;not null"`
+	ArtistID        string `gorm:"uniqueIndex:idx_artist_sub_chat_artist,priority:3;not null"`
+	ArtistName      string
+	ArtistURL       string
+	CreatedByUserID int64
+	Language        string
+	KnownReleaseIDs string     `gorm:"type:text"`
+	LastCheckedAt   *time.Time `gorm:"index"`
+	// LastPolledAt is the last poll attempt, successful or not; it orders the
+	// due list, while LastCheckedAt marks a seeded subscription.
+	LastPolledAt *time.Time `gorm:"index"`
+}
+
+func (ArtistSubscriptionModel) TableName() string {
+	return "artist_subscriptions"
+}
+
+func toArtistSubscription(model ArtistSubscriptionModel) *bot.ArtistSubscription {
+	return &bot.ArtistSubscription{
+		ID:              model.ID,
+		CreatedAt:       model.CreatedAt,
+		UpdatedAt:       model.UpdatedAt,
+		ChatID:          model.ChatID,
+		Platform:        model.Platform,
+		ArtistID:        model.ArtistID,
+		ArtistName:      model.ArtistName,
+		ArtistURL:       model.ArtistURL,
+		CreatedByUserID: model.CreatedByUserID,
+		Language:        model.Language,
+		KnownReleaseIDs: splitReleaseIDs(model.KnownReleaseIDs),
+		LastCheckedAt:   model.LastCheckedAt,
+		LastPolledAt:    model.LastPolledAt,
+	}
+}
+
+// PlaylistSubscriptionModel stores one chat's playlist sync subscription.
+// TrackIDs is the newline-separated snapshot of the playlist's track IDs.
+type PlaylistSubscriptionModel struct {
+	gorm.Model
+	ChatID          int64  `gorm:"uniqueIndex:idx_playlist_sub_chat_playlist,priority:1;not null"`
+	Platform        string `gorm:"uniqueIndex:idx_playlist_sub_chat_playlist,priority:2;not null"`
+	PlaylistID      string `gorm:"uniqueIndex:idx_playlist_sub_chat_playlist,priority:3;not null"`
+	Title           string
+	URL             string
+	CreatedByUserID int64
+	Language        string
+	AutoDownload    bool
+	TrackIDs        string     `gorm:"type:text"`
+	LastCheckedAt   *time.Time `gorm:"index"`
+}
+
+func (PlaylistSubscriptionModel) TableName() string {
+	return "playlist_subscriptions"
+}
+
+func toPlaylistSubscription(model PlaylistSubscriptionModel) *bot.PlaylistSubscription {
+	return &bot.PlaylistSubscription{
+		ID:              model.ID,
+		CreatedAt:       model.CreatedAt,
+		UpdatedAt:       model.UpdatedAt,
+		ChatID:          model.ChatID,
+		Platform:        model.Platform,
+		PlaylistID:      model.PlaylistID,
+		Title:           model.Title,
+		URL:             model.URL,
+		CreatedByUserID: model.CreatedByUserID,
+		Language:        model.Language,
+		AutoDownload:    model.AutoDownload,
+		TrackIDs:        splitReleaseIDs(model.TrackIDs),
+		LastCheckedAt:   model.LastCheckedAt,
+	}
+}
+
+// PreviewClipModel caches the Telegram file ID of a track's preview voice
+// note. Start and duration are stored in milliseconds.
+type PreviewClipModel struct {
+	gorm.Model
+	Platform   string `gorm:"uniqueIndex:idx_preview_platform_track,priority:1;not null"`
+	TrackID    string `gorm:"uniqueIndex:idx_preview_platform_track,priority:2;not null"`
+	FileID     string `gorm:"not null"`
+	Title      string
+	StartMs    int64
+	DurationMs int64
+	Waveform   string
+}
+
+func (PreviewClipModel) TableName() string {
+	return "preview_clips"
+}
+
+func toPreviewClip(model PreviewClipModel) *bot.PreviewClip {
+	return &bot.PreviewClip{
+		Platform: model.Platform,
+		TrackID:  model.TrackID,
+		FileID:   model.FileID,
+		Title:    model.Title,
+		Start:    time.Duration(model.StartMs) * time.Millisecond,
+		Duration: time.Duration(model.DurationMs) * time.Millisecond,
+		Waveform: model.Waveform,
+	}
+}
+
+func splitReleaseIDs(raw string) []string {
+	if strings.TrimSpace(raw) == "" {
+		return nil
+	}
+	ids := make([]string, 0)
+	for _, id := range strings.Split(raw, "\n") {
+		if id = strings.TrimSpace(id); id != "" {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// encodeTagTemplates stores per-field tag templates as a JSON object; an
+// empty map is stored as "".
+func encodeTagTemplates(templates map[string]string) string {
+	if len(templates) == 0 {
+		return ""
+	}
+	raw, err := json.Marshal(templates)
+	if err != nil {
+		return ""
+	}
+	return string(raw)
+}
+
+func decodeTagTemplates(raw string) map[string]string {
+	if strings.TrimSpace(raw) == "" {
+		return nil
+	}
+	var templates map[string]string
+	if err := json.Unmarshal([]byte(raw), &templates); err != nil {
+		return nil
+	}
+	return templates
+}
@@ -74,10 +74,10 @@ func NewSQLiteRepository(cacheDSN, dataDSN string, gormLogger logger.Interface)
 		return nil, fmt.Errorf("data migration failed: %w", err)
 	}
 
-	if err := cacheDB.AutoMigrate(&SongInfoModel{}); err != nil {
+	if err := cacheDB.AutoMigrate(&SongInfoModel{}, &PreviewClipModel{}); err != nil {
 		return nil, err
 	}
-	if err := dataDB.AutoMigrate(&UserSettingsModel{}, &BotStatModel{}, &GroupSettingsModel{}, &PluginSettingModel{}, &FavoriteModel{}); err != nil {
+	if err := dataDB.AutoMigrate(&UserSettingsModel{}, &BotStatModel{}, &GroupSettingsModel{}, &PluginSettingModel{}, &FavoriteModel{}, &ArtistSubscriptionModel{}, &PlaylistSubscriptionModel{}); err != nil {
 		return nil, err
 	}
 
@@ -100,6 +100,9 @@ func NewSQLiteRepository(cacheDSN, dataDSN string, gormLogger logger.Interface)
 	if err := migrateToQualityBasedCache(cacheDB); err != nil {
 		return nil, err
 	}
+	if err := migrateToOutputFormatVariants(cacheDB); err != nil {
+		return nil, err
+	}
 	if err := ensureSQLiteIndexes(cacheDB); err != nil {
 		return nil, err
 	}
@@ -288,6 +291,19 @@ func migrateToQualityBasedCache(db *gorm.DB) error {
 	return nil
 }
 
+// migrateToOutputFormatVariants drops the pre-variant unique index so a
+// transcoded copy can sit beside the original under the same quality, keyed by
+// (platform, track_id, quality, output_format) instead.
+func migrateToOutputFormatVariants(db *gorm.DB) error {
+	if err := db.Exec("DROP INDEX IF EXISTS idx_platform_track_quality").Error; err != nil {
+		return fmt.Errorf("drop quality index: %w", err)
+	}
+	if err := db.Exec("CREATE UNIQUE INDEX IF NOT EXISTS idx_platform_track_quality_format ON song_infos(platform, track_id, quality, output_format)").Error; err != nil {
+		return fmt.Errorf("create output format unique index: %w", err)
+	}
+	return nil
+}
+
 func migrateSettingsAutoLinkDetect(db *gorm.DB) error {
 	var userColumnExists bool
 	if err := db.Raw("SELECT COUNT(*) > 0 FROM pragma_table_info('user_settings') WHERE name='auto_link_detect'").Scan(&userColumnExists).Error; err != nil {
@@ -421,13 +437,100 @@ func (r *Repository) FindByMusicID(ctx context.Context, musicID int) (*bot.SongI
 // FindByPlatformTrackID returns a cached song by platform, track ID and quality.
 func (r *Repository) FindByPlatformTrackID(ctx context.Context, platform, trackID, quality string) (*bot.SongInfo, error) {
 	var model SongInfoModel
-	err := r.cacheDB.WithContext(ctx).Where("platform = ? AND track_id = ? AND quality = ?", platform, trackID, quality).First(&model).Error
+	err := r.cacheDB.WithContext(ctx).Where("platform = ? AND track_id = ? AND quality = ? AND output_format = ''", platform, trackID, quality).First(&model).Error
+	if err != nil {
+		return nil, err
+	}
+	return toInternal(model), nil
+}
+
+// FindSongVariant returns a cached transcode of a track at the given source
+// quality, or nil when none has been uploaded yet.
+func (r *Repository) FindSongVariant(ctx context.Context, platform, trackID, quality, outputFormat string) (*bot.SongInfo, error) {
+	if r == nil || r.cacheDB == nil {
+		return nil, errors.New("repository not configured")
+	}
+	var model SongInfoModel
+	err := r.cacheDB.WithContext(ctx).Where("platform = ? AND track_id = ? AND quality = ? AND output_format = ?", platform, trackID, quality, outputFormat).First(&model).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
 	if err != nil {
 		return nil, err
 	}
 	return toInternal(model), nil
 }
 
+// DeleteSongVariant removes one cached transcode, leaving the original and
+// other formats in place.
+func (r *Repository) DeleteSongVariant(ctx context.Context, platform, trackID, quality, outputFormat string) error {
+	if r == nil || r.cacheDB == nil {
+		return errors.New("repository not configured")
+	}
+	return r.cacheDB.WithContext(ctx).Delete(&SongInfoModel{}, "platform = ? AND track_id = ? AND quality = ? AND output_format = ?", platform, trackID, quality, outputFormat).Error
+}
+
+// FindPreview returns the cached preview clip of a track, or nil when none
+// was uploaded yet.
+func (r *Repository) FindPreview(ctx context.Context, platform, trackID string) (*bot.PreviewClip, error) {
+	if r == nil || r.cacheDB == nil {
+		return nil, errors.New("repository not configured")
+	}
+	var model PreviewClipModel
+	err := r.cacheDB.WithContext(ctx).Where("platform = ? AND track_id = ?", platform, trackID).First(&model).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return toPreviewClip(model), nil
+}
+
+// SavePreview stores a track's preview clip, replacing any previous one.
+func (r *Repository) SavePreview(ctx context.Context, clip *bot.PreviewClip) error {
+	if r == nil || r.cacheDB == nil {
+		return errors.New("repository not configured")
+	}
+	if clip == nil {
+		return errors.New("nil preview clip")
+	}
+	platform := strings.TrimSpace(clip.Platform)
+	trackID := strings.TrimSpace(clip.TrackID)
+	if platform == "" || trackID == "" || strings.TrimSpace(clip.FileID) == "" {
+		return errors.New("invalid preview clip")
+	}
+	model := PreviewClipModel{
+		Platform:   platform,
+		TrackID:    trackID,
+		FileID:     strings.TrimSpace(clip.FileID),
+		Title:      clip.Title,
+		StartMs:    clip.Start.Milliseconds(),
+		DurationMs: clip.Duration.Milliseconds(),
+		Waveform:   clip.Waveform,
+	}
+	return r.cacheDB.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "platform"}, {Name: "track_id"}},
+		DoUpdates: clause.Assignments(map[string]any{
+			"file_id":     model.FileID,
+			"title":       model.Title,
+			"start_ms":    model.StartMs,
+			"duration_ms": model.DurationMs,
+			"waveform":    model.Waveform,
+			"updated_at":  time.Now(),
+		}),
+	}).Create(&model).Error
+}
+
+// DeletePreview drops a track's cached preview, e.g. after Telegram rejected
+// its file ID.
+func (r *Repository) DeletePreview(ctx context.Context, platform, trackID string) error {
+	if r == nil || r.cacheDB == nil {
+		return errors.New("repository not configured")
+	}
+	return r.cacheDB.WithContext(ctx).Unscoped().Delete(&PreviewClipModel{}, "platform = ? AND track_id = ?", platform, trackID).Error
+}
+
 // SearchCachedSongs searches cached songs by keyword with optional platform/quality filters.
 func (r *Repository) SearchCachedSongs(ctx context.Context, keyword, platformName, quality string, limit int) ([]*bot.SongInfo, error) {
 	if r == nil || r.cacheDB == nil {
@@ -450,6 +553,7 @@ func (r *Repository) SearchCachedSongs(ctx context.Context, keyword, platformNam
 	query := r.cacheDB.WithContext(ctx).Model(&SongInfoModel{}).
 		Where("file_id <> ''").
 		Where("song_name <> ''").
+		Where("output_format = ''").
 		Where("(LOWER(song_name) LIKE ? OR LOWER(song_artists) LIKE ? OR LOWER(song_album) LIKE ?)", likeValue, likeValue, likeValue)
 
 	if strings.TrimSpace(platformName) != "" {
@@ -492,7 +596,8 @@ func (r *Repository) FindRandomCachedSong(ctx context.Context) (*bot.SongInfo, e
 	query := reusableRandomCacheQuery(r.cacheDB.WithContext(ctx).
 		Model(&SongInfoModel{}).
 		Where("file_id <> ''").
-		Where("song_name <> ''"))
+		Where("song_name <> ''").
+		Where("output_format = ''"))
 
 	var count int64
 	if err := query.Count(&count).Error; err != nil {
@@ -507,7 +612,8 @@ func (r *Repository) FindRandomCachedSong(ctx context.Context) (*bot.SongInfo, e
 	err := reusableRandomCacheQuery(r.cacheDB.WithContext(ctx).
 		Model(&SongInfoModel{}).
 		Where("file_id <> ''").
-		Where("song_name <> ''")).
+		Where("song_name <> ''").
+		Where("output_format = ''")).
 		Offset(int(offset)).
 		Limit(1).
 		Take(&model).Error
@@ -555,6 +661,7 @@ func (r *Repository) Create(ctx context.Context, song *bot.SongInfo) error {
 				{Name: "platform"},
 				{Name: "track_id"},
 				{Name: "quality"},
+				{Name: "output_format"},
 			},
 			DoUpdates: clause.AssignmentColumns([]string{
 				"deleted_at",
@@ -564,6 +671,12 @@ func (r *Repository) Create(ctx context.Context, song *bot.SongInfo) error {
 				"audio_codec",
 				"sample_rate",
 				"bit_depth",
+				"loudness_lufs",
+				"true_peak",
+				"spectral_verdict",
+				"spectral_cutoff",
+				"effective_bits",
+				"integrity_status",
 				"music_id",
 				"song_name",
 				"song_artists",
@@ -590,7 +703,7 @@ func (r *Repository) Create(ctx context.Context, song *bot.SongInfo) error {
 		}).Create(model).Error; err != nil {
 			return err
 		}
-		if err := tx.Where("platform = ? AND track_id = ? AND quality = ?", model.Platform, model.TrackID, model.Quality).First(model).Error; err != nil {
+		if err := tx.Where("platform = ? AND track_id = ? AND quality = ? AND output_format = ?", model.Platform, model.TrackID, model.Quality, model.OutputFormat).First(model).Error; err != nil {
 			return err
 		}
 		song.ID = model.ID
@@ -617,6 +730,8 @@ func (r *Repository) Update(ctx context.Context, song *bot.SongInfo) error {
 //     (platform, track_id, quality) forbids two colliding rows, so if a record
 //     already exists under newQuality we flag it verified and drop the stale
 //     oldQuality row; otherwise we relabel the existing row to newQuality.
+//
+// Only original (non-transcoded) rows are touched.
 func (r *Repository) VerifyAndUpdateQuality(ctx context.Context, platform, trackID, oldQuality, newQuality string) error {
 	if r == nil || r.cacheDB == nil {
 		return errors.New("repository not configured")
@@ -624,28 +739,28 @@ func (r *Repository) VerifyAndUpdateQuality(ctx context.Context, platform, track
 	return r.cacheDB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
 		if oldQuality == newQuality {
 			return tx.Model(&SongInfoModel{}).
-				Where("platform = ? AND track_id = ? AND quality = ?", platform, trackID, oldQuality).
+				Where("platform = ? AND track_id = ? AND quality = ? AND output_format = ''", platform, trackID, oldQuality).
 				Update("quality_verified", true).Error
 		}
 
 		var existing SongInfoModel
-		err := tx.Where("platform = ? AND track_id = ? AND quality = ?", platform, trackID, newQuality).First(&existing).Error
+		err := tx.Where("platform = ? AND track_id = ? AND quality = ? AND output_format = ''", platform, trackID, newQuality).First(&existing).Error
 		if err == nil {
 			// A record already exists under the correct quality: keep it, mark it
 			// verified, and drop the stale oldQuality row.
 			if updErr := tx.Model(&SongInfoModel{}).
-				Where("platform = ? AND track_id = ? AND quality = ?", platform, trackID, newQuality).
+				Where("platform = ? AND track_id = ? AND quality = ? AND output_format = ''", platform, trackID, newQuality).
 				Update("quality_verified", true).Error; updErr != nil {
 				return updErr
 			}
-			return tx.Delete(&SongInfoModel{}, "platform = ? AND track_id = ? AND quality = ?", platform, trackID, oldQuality).Error
+			return tx.Delete(&SongInfoModel{}, "platform = ? AND track_id = ? AND quality = ? AND output_format = ''", platform, trackID, oldQuality).Error
 		}
 		if !errors.Is(err, gorm.ErrRecordNotFound) {
 			return err
 		}
 		// No record under newQuality: relabel the existing oldQuality row.
 		return tx.Model(&SongInfoModel{}).
-			Where("platform = ? AND track_id = ? AND quality = ?", platform, trackID, oldQuality).
+			Where("platform = ? AND track_id = ? AND quality = ? AND output_format = ''", platform, trackID, oldQuality).
 			Updates(map[string]any{"quality": newQuality, "quality_verified": true}).Error
 	})
 }
@@ -657,30 +772,38 @@ func (r *Repository) Delete(ctx context.Context, musicID int) error {
 	})
 }
 
-// DeleteAll clears all cached songs.
+// DeleteAll clears all cached songs and preview clips.
 func (r *Repository) DeleteAll(ctx context.Context) error {
 	if r == nil || r.cacheDB == nil {
 		return errors.New("repository not configured")
 	}
+	if err := r.cacheDB.WithContext(ctx).Session(&gorm.Session{AllowGlobalUpdate: true}).Unscoped().Delete(&PreviewClipModel{}).Error; err != nil {
+		return err
+	}
 	return r.cacheDB.WithContext(ctx).
 		Session(&gorm.Session{AllowGlobalUpdate: true}).
 		Delete(&SongInfoModel{}).Error
 }
 
-// DeleteAllByPlatform clears cached songs for a specific platform.
+// DeleteAllByPlatform clears cached songs and preview clips for a specific
+// platform.
 func (r *Repository) DeleteAllByPlatform(ctx context.Context, platform string) error {
 	if r == nil || r.cacheDB == nil {
 		return errors.New("repository not configured")
 	}
+	if err := r.cacheDB.WithContext(ctx).Unscoped().Where("platform = ?", platform).Delete(&PreviewClipModel{}).Error; err != nil {
+		return err
+	}
 	return r.cacheDB.WithContext(ctx).
 		Where("platform = ?", platform).
 		Delete(&SongInfoModel{}).Error
 }
 
-// DeleteByPlatformTrackID removes a song by platform, track ID and quality.
+// DeleteByPlatformTrackID removes the original song by platform, track ID and
+// quality; transcoded variants are removed with DeleteSongVariant.
 func (r *Repository) DeleteByPlatformTrackID(ctx context.Context, platform, trackID, quality string) error {
 	return r.cacheDB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
-		return tx.Delete(&SongInfoModel{}, "platform = ? AND track_id = ? AND quality = ?", platform, trackID, quality).Error
+		return tx.Delete(&SongInfoModel{}, "platform = ? AND track_id = ? AND quality = ? AND output_format = ''", platform, trackID, quality).Error
 	})
 }
 
@@ -850,9 +973,14 @@ func userSettingsToInternal(settings UserSettingsModel) *bot.UserSettings {
 		UserID:                         settings.UserID,
 		DefaultPlatform:                settings.DefaultPlatform,
 		DefaultQuality:                 settings.DefaultQuality,
+		DefaultOutputFormat:            settings.DefaultOutputFormat,
 		AutoDeleteList:                 settings.AutoDeleteList,
 		AutoLinkDetect:                 settings.AutoLinkDetect,
 		DefaultLyricFormat:             settings.DefaultLyricFormat,
+		FilenameTemplate:               settings.FilenameTemplate,
+		CaptionTemplate:                settings.CaptionTemplate,
+		TagTemplates:                   decodeTagTemplates(settings.TagTemplates),
+		CoverEmbed:                     settings.CoverEmbed,
 		Language:                       settings.Language,
 		DefaultLyricIncludeTranslation: settings.DefaultLyricIncludeTranslation,
 		DefaultLyricIncludeRoma:        settings.DefaultLyricIncludeRoma,
@@ -872,9 +1000,14 @@ func groupSettingsToInternal(settings GroupSettingsModel) *bot.GroupSettings {
 		ChatID:                         settings.ChatID,
 		DefaultPlatform:                settings.DefaultPlatform,
 		DefaultQuality:                 settings.DefaultQuality,
+		DefaultOutputFormat:            settings.DefaultOutputFormat,
 		AutoDeleteList:                 settings.AutoDeleteList,
 		AutoLinkDetect:                 settings.AutoLinkDetect,
 		DefaultLyricFormat:             settings.DefaultLyricFormat,
+		FilenameTemplate:               settings.FilenameTemplate,
+		CaptionTemplate:                settings.CaptionTemplate,
+		TagTemplates:                   decodeTagTemplates(settings.TagTemplates),
+		CoverEmbed:                     settings.CoverEmbed,
 		Language:                       settings.Language,
 		DefaultLyricIncludeTranslation: settings.DefaultLyricIncludeTranslation,
 		DefaultLyricIncludeRoma:        settings.DefaultLyricIncludeRoma,
@@ -887,11 +1020,12 @@ func (r *Repository) GetUserSettings(ctx context.Context, userID int64) (*bot.Us
 	err := r.dataDB.WithContext(ctx).
 		Where(UserSettingsModel{UserID: userID}).
 		Attrs(UserSettingsModel{
-			DefaultPlatform:    r.defaultPlatform,
-			DefaultQuality:     r.defaultQuality,
-			AutoDeleteList:     false,
-			AutoLinkDetect:     true,
-			DefaultLyricFormat: r.defaultLyricFormat,
+			DefaultPlatform:     r.defaultPlatform,
+			DefaultQuality:      r.defaultQuality,
+			DefaultOutputFormat: "original",
+			AutoDeleteList:      false,
+			AutoLinkDetect:      true,
+			DefaultLyricFormat:  r.defaultLyricFormat,
 		}).
 		FirstOrCreate(&settings).Error
 	if isSQLiteUniqueConstraint(err) {
@@ -909,11 +1043,12 @@ func (r *Repository) GetGroupSettings(ctx context.Context, chatID int64) (*bot.G
 	err := r.dataDB.WithContext(ctx).
 		Where(GroupSettingsModel{ChatID: chatID}).
 		Attrs(GroupSettingsModel{
-			DefaultPlatform:    r.defaultPlatform,
-			DefaultQuality:     r.defaultQuality,
-			AutoDeleteList:     true,
-			AutoLinkDetect:     true,
-			DefaultLyricFormat: r.defaultLyricFormat,
+			DefaultPlatform:     r.defaultPlatform,
+			DefaultQuality:      r.defaultQuality,
+			DefaultOutputFormat: "original",
+			AutoDeleteList:      true,
+			AutoLinkDetect:      true,
+			DefaultLyricFormat:  r.defaultLyricFormat,
 		}).
 		FirstOrCreate(&settings).Error
 	if isSQLiteUniqueConstraint(err) {
@@ -941,13 +1076,18 @@ func (r *Repository) UpdateUserSettings(ctx context.Context, settings *bot.UserS
 			CreatedAt: settings.CreatedAt,
 			UpdatedAt: settings.UpdatedAt,
 		},
-		UserID:             settings.UserID,
-		DefaultPlatform:    settings.DefaultPlatform,
-		DefaultQuality:     settings.DefaultQuality,
-		AutoDeleteList:     settings.AutoDeleteList,
-		AutoLinkDetect:     settings.AutoLinkDetect,
-		DefaultLyricFormat: settings.DefaultLyricFormat,
-		Language:           settings.Language,
+		UserID:              settings.UserID,
+		DefaultPlatform:     settings.DefaultPlatform,
+		DefaultQuality:      settings.DefaultQuality,
+		DefaultOutputFormat: settings.DefaultOutputFormat,
+		AutoDeleteList:      settings.AutoDeleteList,
+		AutoLinkDetect:      settings.AutoLinkDetect,
+		DefaultLyricFormat:  settings.DefaultLyricFormat,
+		FilenameTemplate:    settings.FilenameTemplate,
+		CaptionTemplate:     settings.CaptionTemplate,
+		TagTemplates:        encodeTagTemplates(settings.TagTemplates),
+		CoverEmbed:          settings.CoverEmbed,
+		Language:            settings.Language,
 
 		DefaultLyricIncludeTranslation: settings.DefaultLyricIncludeTranslation,
 		DefaultLyricIncludeRoma:        settings.DefaultLyricIncludeRoma,
@@ -966,13 +1106,18 @@ func (r *Repository) UpdateGroupSettings(ctx context.Context, settings *bot.Grou
 			CreatedAt: settings.CreatedAt,
 			UpdatedAt: settings.UpdatedAt,
 		},
-		ChatID:             settings.ChatID,
-		DefaultPlatform:    settings.DefaultPlatform,
-		DefaultQuality:     settings.DefaultQuality,
-		AutoDeleteList:     settings.AutoDeleteList,
-		AutoLinkDetect:     settings.AutoLinkDetect,
-		DefaultLyricFormat: settings.DefaultLyricFormat,
-		Language:           settings.Language,
+		ChatID:              settings.ChatID,
+		DefaultPlatform:     settings.DefaultPlatform,
+		DefaultQuality:      settings.DefaultQuality,
+		DefaultOutputFormat: settings.DefaultOutputFormat,
+		AutoDeleteList:      settings.AutoDeleteList,
+		AutoLinkDetect:      settings.AutoLinkDetect,
+		DefaultLyricFormat:  settings.DefaultLyricFormat,
+		FilenameTemplate:    settings.FilenameTemplate,
+		CaptionTemplate:     settings.CaptionTemplate,
+		TagTemplates:        encodeTagTemplates(settings.TagTemplates),
+		CoverEmbed:          settings.CoverEmbed,
+		Language:            settings.Language,
 
 		DefaultLyricIncludeTranslation: settings.DefaultLyricIncludeTranslation,
 		DefaultLyricIncludeRoma:        settings.DefaultLyricIncludeRoma,
@@ -1191,6 +1336,331 @@ func (r *Repository) RandomFavorite(ctx context.Context, scopeType string, scope
 	return toFavorite(model), nil
 }
 
+// AddArtistSubscription subscribes a chat to an artist. Re-following refreshes
+// the stored name, URL and language but keeps the known releases, so it never re-announces.
+func (r *Repository) AddArtistSubscription(ctx context.Context, sub *bot.ArtistSubscription) error {
+	if r == nil || r.dataDB == nil {
+		return errors.New("repository not configured")
+	}
+	if sub == nil {
+		return errors.New("nil artist subscription")
+	}
+	platform := strings.TrimSpace(sub.Platform)
+	artistID := strings.TrimSpace(sub.ArtistID)
+	if sub.ChatID == 0 || platform == "" || artistID == "" {
+		return errors.New("invalid artist subscription key")
+	}
+	model := ArtistSubscriptionModel{
+		ChatID:          sub.ChatID,
+		Platform:        platform,
+		ArtistID:        artistID,
+		ArtistName:      strings.TrimSpace(sub.ArtistName),
+		ArtistURL:       strings.TrimSpace(sub.ArtistURL),
+		CreatedByUserID: sub.CreatedByUserID,
+		Language:        strings.TrimSpace(sub.Language),
+		KnownReleaseIDs: strings.Join(sub.KnownReleaseIDs, "\n"),
+	}
+	return r.dataDB.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "chat_id"}, {Name: "platform"}, {Name: "artist_id"}},
+		DoUpdates: clause.Assignments(map[string]any{
+			"artist_name": model.ArtistName,
+			"artist_url":  model.ArtistURL,
+			"language":    model.Language,
+			"updated_at":  time.Now(),
+		}),
+	}).Create(&model).Error
+}
+
+// RemoveArtistSubscription hard-deletes a chat's subscription by ID. The chat
+// ID is part of the filter so one chat can never drop another's subscription.
+func (r *Repository) RemoveArtistSubscription(ctx context.Context, chatID int64, id uint) error {
+	if r == nil || r.dataDB == nil {
+		return errors.New("repository not configured")
+	}
+	if chatID == 0 || id == 0 {
+		return errors.New("invalid artist subscription key")
+	}
+	return r.dataDB.WithContext(ctx).Unscoped().
+		Where("id = ? AND chat_id = ?", id, chatID).
+		Delete(&ArtistSubscriptionModel{}).Error
+}
+
+// IsArtistSubscribed reports whether a chat follows (platform, artistID).
+func (r *Repository) IsArtistSubscribed(ctx context.Context, chatID int64, platform, artistID string) (bool, error) {
+	if r == nil || r.dataDB == nil {
+		return false, errors.New("repository not configured")
+	}
+	if chatID == 0 {
+		return false, nil
+	}
+	var count int64
+	err := r.dataDB.WithContext(ctx).Model(&ArtistSubscriptionModel{}).
+		Where("chat_id = ? AND platform = ? AND artist_id = ?", chatID, strings.TrimSpace(platform), strings.TrimSpace(artistID)).
+		Count(&count).Error
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// ListArtistSubscriptions returns a chat's subscriptions, oldest first.
+func (r *Repository) ListArtistSubscriptions(ctx context.Context, chatID int64) ([]*bot.ArtistSubscription, error) {
+	if r == nil || r.dataDB == nil {
+		return nil, errors.New("repository not configured")
+	}
+	if chatID == 0 {
+		return nil, nil
+	}
+	var models []ArtistSubscriptionModel
+	err := r.dataDB.WithContext(ctx).
+		Where("chat_id = ?", chatID).
+		Order("created_at ASC").
+		Find(&models).Error
+	if err != nil {
+		return nil, err
+	}
+	results := make([]*bot.ArtistSubscription, 0, len(models))
+	for _, model := range models {
+		results = append(results, toArtistSubscription(model))
+	}
+	return results, nil
+}
+
+// CountArtistSubscriptions returns how many artists a chat follows.
+func (r *Repository) CountArtistSubscriptions(ctx context.Context, chatID int64) (int64, error) {
+	if r == nil || r.dataDB == nil {
+		return 0, errors.New("repository not configured")
+	}
+	if chatID == 0 {
+		return 0, nil
+	}
+	var count int64
+	err := r.dataDB.WithContext(ctx).Model(&ArtistSubscriptionModel{}).
+		Where("chat_id = ?", chatID).
+		Count(&count).Error
+	return count, err
+}
+
+// ListDueArtistSubscriptions returns up to limit subscriptions across all
+// chats, least recently polled first (never-polled ones lead). Rows from
+// before LastPolledAt existed fall back to LastCheckedAt.
+func (r *Repository) ListDueArtistSubscriptions(ctx context.Context, limit int) ([]*bot.ArtistSubscription, error) {
+	if r == nil || r.dataDB == nil {
+		return nil, errors.New("repository not configured")
+	}
+	if limit <= 0 {
+		limit = 100
+	}
+	var models []ArtistSubscriptionModel
+	err := r.dataDB.WithContext(ctx).
+		Order("COALESCE(last_polled_at, last_checked_at) IS NOT NULL, COALESCE(last_polled_at, last_checked_at) ASC, id ASC").
+		Limit(limit).
+		Find(&models).Error
+	if err != nil {
+		return nil, err
+	}
+	results := make([]*bot.ArtistSubscription, 0, len(models))
+	for _, model := range models {
+		results = append(results, toArtistSubscription(model))
+	}
+	return results, nil
+}
+
+// MarkArtistSubscriptionChecked records a completed poll and the release IDs
+// seen so far.
+func (r *Repository) MarkArtistSubscriptionChecked(ctx context.Context, id uint, knownReleaseIDs []string, checkedAt time.Time) error {
+	if r == nil || r.dataDB == nil {
+		return errors.New("repository not configured")
+	}
+	if id == 0 {
+		return errors.New("invalid artist subscription key")
+	}
+	return r.dataDB.WithContext(ctx).Model(&ArtistSubscriptionModel{}).
+		Where("id = ?", id).
+		Updates(map[string]any{
+			"known_release_ids": strings.Join(knownReleaseIDs, "\n"),
+			"last_checked_at":   checkedAt,
+			"last_polled_at":    checkedAt,
+		}).Error
+}
+
+// MarkArtistSubscriptionPolled records a failed poll: the subscription moves
+// to the back of the due list but stays unseeded.
+func (r *Repository) MarkArtistSubscriptionPolled(ctx context.Context, id uint, polledAt time.Time) error {
+	if r == nil || r.dataDB == nil {
+		return errors.New("repository not configured")
+	}
+	if id == 0 {
+		return errors.New("invalid artist subscription key")
+	}
+	return r.dataDB.WithContext(ctx).Model(&ArtistSubscriptionModel{}).
+		Where("id = ?", id).
+		Update("last_polled_at", polledAt).Error
+}
+
+// AddPlaylistSubscription subscribes a chat to a playlist with its initial
+// track snapshot. Re-subscribing refreshes the title, URL, language and
+// auto-download flag but keeps the stored snapshot, so additions made since
+// the last check are still announced.
+func (r *Repository) AddPlaylistSubscription(ctx context.Context, sub *bot.PlaylistSubscription) error {
+	if r == nil || r.dataDB == nil {
+		return errors.New("repository not configured")
+	}
+	if sub == nil {
+		return errors.New("nil playlist subscription")
+	}
+	platform := strings.TrimSpace(sub.Platform)
+	playlistID := strings.TrimSpace(sub.PlaylistID)
+	if sub.ChatID == 0 || platform == "" || playlistID == "" {
+		return errors.New("invalid playlist subscription key")
+	}
+	model := PlaylistSubscriptionModel{
+		ChatID:          sub.ChatID,
+		Platform:        platform,
+		PlaylistID:      playlistID,
+		Title:           strings.TrimSpace(sub.Title),
+		URL:             strings.TrimSpace(sub.URL),
+		CreatedByUserID: sub.CreatedByUserID,
+		Language:        strings.TrimSpace(sub.Language),
+		AutoDownload:    sub.AutoDownload,
+		TrackIDs:        strings.Join(sub.TrackIDs, "\n"),
+		LastCheckedAt:   sub.LastCheckedAt,
+	}
+	return r.dataDB.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "chat_id"}, {Name: "platform"}, {Name: "playlist_id"}},
+		DoUpdates: clause.Assignments(map[string]any{
+			"title":         model.Title,
+			"url":           model.URL,
+			"language":      model.Language,
+			"auto_download": model.AutoDownload,
+			"updated_at":    time.Now(),
+		}),
+	}).Create(&model).Error
+}
+
+// RemovePlaylistSubscription hard-deletes a chat's playlist subscription by ID.
+func (r *Repository) RemovePlaylistSubscription(ctx context.Context, chatID int64, id uint) error {
+	if r == nil || r.dataDB == nil {
+		return errors.New("repository not configured")
+	}
+	if chatID == 0 || id == 0 {
+		return errors.New("invalid playlist subscription key")
+	}
+	return r.dataDB.WithContext(ctx).Unscoped().
+		Where("id = ? AND chat_id = ?", id, chatID).
+		Delete(&PlaylistSubscriptionModel{}).Error
+}
+
+// SetPlaylistSubscriptionAutoDownload toggles auto-download for one of a
+// chat's playlist subscriptions.
+func (r *Repository) SetPlaylistSubscriptionAutoDownload(ctx context.Context, chatID int64, id uint, enabled bool) error {
+	if r == nil || r.dataDB == nil {
+		return errors.New("repository not configured")
+	}
+	if chatID == 0 || id == 0 {
+		return errors.New("invalid playlist subscription key")
+	}
+	return r.dataDB.WithContext(ctx).Model(&PlaylistSubscriptionModel{}).
+		Where("id = ? AND chat_id = ?", id, chatID).
+		Update("auto_download", enabled).Error
+}
+
+// IsPlaylistSubscribed reports whether a chat follows (platform, playlistID).
+func (r *Repository) IsPlaylistSubscribed(ctx context.Context, chatID int64, platform, playlistID string) (bool, error) {
+	if r == nil || r.dataDB == nil {
+		return false, errors.New("repository not configured")
+	}
+	if chatID == 0 {
+		return false, nil
+	}
+	var count int64
+	err := r.dataDB.WithContext(ctx).Model(&PlaylistSubscriptionModel{}).
+		Where("chat_id = ? AND platform = ? AND playlist_id = ?", chatID, strings.TrimSpace(platform), strings.TrimSpace(playlistID)).
+		Count(&count).Error
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// ListPlaylistSubscriptions returns a chat's playlist subscriptions, oldest first.
+func (r *Repository) ListPlaylistSubscriptions(ctx context.Context, chatID int64) ([]*bot.PlaylistSubscription, error) {
+	if r == nil || r.dataDB == nil {
+		return nil, errors.New("repository not configured")
+	}
+	if chatID == 0 {
+		return nil, nil
+	}
+	var models []PlaylistSubscriptionModel
+	err := r.dataDB.WithContext(ctx).
+		Where("chat_id = ?", chatID).
+		Order("created_at ASC").
+		Find(&models).Error
+	if err != nil {
+		return nil, err
+	}
+	results := make([]*bot.PlaylistSubscription, 0, len(models))
+	for _, model := range models {
+		results = append(results, toPlaylistSubscription(model))
+	}
+	return results, nil
+}
+
+// CountPlaylistSubscriptions returns how many playlists a chat follows.
+func (r *Repository) CountPlaylistSubscriptions(ctx context.Context, chatID int64) (int64, error) {
+	if r == nil || r.dataDB == nil {
+		return 0, errors.New("repository not configured")
+	}
+	if chatID == 0 {
+		return 0, nil
+	}
+	var count int64
+	err := r.dataDB.WithContext(ctx).Model(&PlaylistSubscriptionModel{}).
+		Where("chat_id = ?", chatID).
+		Count(&count).Error
+	return count, err
+}
+
+// ListDuePlaylistSubscriptions returns up to limit playlist subscriptions
+// across all chats, least recently checked first.
+func (r *Repository) ListDuePlaylistSubscriptions(ctx context.Context, limit int) ([]*bot.PlaylistSubscription, error) {
+	if r == nil || r.dataDB == nil {
+		return nil, errors.New("repository not configured")
+	}
+	if limit <= 0 {
+		limit = 100
+	}
+	var models []PlaylistSubscriptionModel
+	err := r.dataDB.WithContext(ctx).
+		Order("last_checked_at IS NOT NULL, last_checked_at ASC, id ASC").
+		Limit(limit).
+		Find(&models).Error
+	if err != nil {
+		return nil, err
+	}
+	results := make([]*bot.PlaylistSubscription, 0, len(models))
+	for _, model := range models {
+		results = append(results, toPlaylistSubscription(model))
+	}
+	return results, nil
+}
+
+// MarkPlaylistSubscriptionChecked replaces the track snapshot after a poll.
+func (r *Repository) MarkPlaylistSubscriptionChecked(ctx context.Context, id uint, trackIDs []string, checkedAt time.Time) error {
+	if r == nil || r.dataDB == nil {
+		return errors.New("repository not configured")
+	}
+	if id == 0 {
+		return errors.New("invalid playlist subscription key")
+	}
+	return r.dataDB.WithContext(ctx).Model(&PlaylistSubscriptionModel{}).
+		Where("id = ?", id).
+		Updates(map[string]any{
+			"track_ids":       strings.Join(trackIDs, "\n"),
+			"last_checked_at": checkedAt,
+		}).Error
+}
+
 // FindCachedSongMeta returns cached metadata for a track regardless of quality,
 // preferring the most recently updated row. Used to denormalize song name/artist
 // into a favorite when only (platform, trackID) is known (e.g. a button click).
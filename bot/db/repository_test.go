@@ -5,6 +5,7 @@ import (
 	"io"
 	"log/slog"
 	"os"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -619,6 +620,144 @@ func TestCreateUpsertRefreshesVerifiedAudioProfile(t *testing.T) {
 	}
 }
 
+func TestSongVariantsStayApartFromOriginal(t *testing.T) {
+	repo := newTempRepo(t)
+	ctx := context.Background()
+
+	// Simulate an install from before output formats: the old three-column
+	// unique index would reject a variant row under the same quality.
+	if err := repo.cacheDB.Exec("DROP INDEX IF EXISTS idx_platform_track_quality_format").Error; err != nil {
+		t.Fatalf("drop variant index: %v", err)
+	}
+	if err := repo.cacheDB.Exec("CREATE UNIQUE INDEX idx_platform_track_quality ON song_infos(platform, track_id, quality)").Error; err != nil {
+		t.Fatalf("create legacy index: %v", err)
+	}
+	if err := migrateToOutputFormatVariants(repo.cacheDB); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+
+	original := &bot.SongInfo{Platform: "netease", TrackID: "42", Quality: "lossless", SongName: "S", FileExt: "flac", FileID: "orig"}
+	if err := repo.Create(ctx, original); err != nil {
+		t.Fatalf("create original: %v", err)
+	}
+	variant := &bot.SongInfo{Platform: "netease", TrackID: "42", Quality: "lossless", OutputFormat: "mp3_320", SongName: "S", FileExt: "mp3", FileID: "mp3"}
+	if err := repo.Create(ctx, variant); err != nil {
+		t.Fatalf("create variant: %v", err)
+	}
+	if variant.ID == original.ID {
+		t.Fatalf("variant overwrote original row %d", original.ID)
+	}
+
+	loaded, err := repo.FindByPlatformTrackID(ctx, "netease", "42", "lossless")
+	if err != nil || loaded == nil || loaded.FileID != "orig" || loaded.OutputFormat != "" {
+		t.Fatalf("original lookup returned %+v, %v", loaded, err)
+	}
+	found, err := repo.FindSongVariant(ctx, "netease", "42", "lossless", "mp3_320")
+	if err != nil || found == nil || found.FileID != "mp3" || found.FileExt != "mp3" {
+		t.Fatalf("variant lookup returned %+v, %v", found, err)
+	}
+	if missing, err := repo.FindSongVariant(ctx, "netease", "42", "lossless", "opus_160"); err != nil || missing != nil {
+		t.Fatalf("expected no opus variant, got %+v, %v", missing, err)
+	}
+	if results, err := repo.SearchCachedSongs(ctx, "S", "", "", 10); err != nil || len(results) != 1 || results[0].FileID != "orig" {
+		t.Fatalf("search should only return the original, got %+v, %v", results, err)
+	}
+
+	if err := repo.DeleteSongVariant(ctx, "netease", "42", "lossless", "mp3_320"); err != nil {
+		t.Fatalf("delete variant: %v", err)
+	}
+	if gone, _ := repo.FindSongVariant(ctx, "netease", "42", "lossless", "mp3_320"); gone != nil {
+		t.Fatalf("variant still present: %+v", gone)
+	}
+	if kept, err := repo.FindByPlatformTrackID(ctx, "netease", "42", "lossless"); err != nil || kept == nil {
+		t.Fatalf("deleting the variant removed the original: %v", err)
+	}
+}
+
+func TestRepositoryGroupSettingsTemplates(t *testing.T) {
+	repo := newTempRepo(t)
+	ctx := context.Background()
+
+	settings, err := repo.GetGroupSettings(ctx, -100)
+	if err != nil {
+		t.Fatalf("get group settings: %v", err)
+	}
+	if settings.FilenameTemplate != "" || settings.CaptionTemplate != "" || settings.TagTemplates != nil {
+		t.Fatalf("expected no templates by default, got %+v", settings)
+	}
+	settings.FilenameTemplate = "{{.Artists}} - {{.Album}} - {{pad 2 .TrackNumber}} - {{.Title}}"
+	settings.CaptionTemplate = "<b>{{.Title}}</b>\n{{.Codec}}"
+	settings.TagTemplates = map[string]string{"albumartist": "{{default .Artists .AlbumArtist}}"}
+	if err := repo.UpdateGroupSettings(ctx, settings); err != nil {
+		t.Fatalf("update group settings: %v", err)
+	}
+	got, err := repo.GetGroupSettings(ctx, -100)
+	if err != nil {
+		t.Fatalf("reload group settings: %v", err)
+	}
+	if got.FilenameTemplate != settings.FilenameTemplate || got.CaptionTemplate != settings.CaptionTemplate || got.TagTemplates["albumartist"] != settings.TagTemplates["albumartist"] {
+		t.Fatalf("templates not persisted: %+v", got)
+	}
+}
+
+func TestRepositoryUserSettingsCoverEmbed(t *testing.T) {
+	repo := newTempRepo(t)
+	ctx := context.Background()
+
+	settings, err := repo.GetUserSettings(ctx, 7)
+	if err != nil {
+		t.Fatalf("get user settings: %v", err)
+	}
+	if settings.CoverEmbed != "" {
+		t.Fatalf("expected the default cover policy, got %q", settings.CoverEmbed)
+	}
+	settings.CoverEmbed = "original"
+	if err := repo.UpdateUserSettings(ctx, settings); err != nil {
+		t.Fatalf("update user settings: %v", err)
+	}
+	got, err := repo.GetUserSettings(ctx, 7)
+	if err != nil || got.CoverEmbed != "original" {
+		t.Fatalf("cover policy not persisted: %+v, %v", got, err)
+	}
+}
+
+func TestRepositoryPreviews(t *testing.T) {
+	repo := newTempRepo(t)
+	ctx := context.Background()
+
+	if missing, err := repo.FindPreview(ctx, "netease", "42"); err != nil || missing != nil {
+		t.Fatalf("expected no preview, got %+v, %v", missing, err)
+	}
+	clip := &bot.PreviewClip{Platform: "netease", TrackID: "42", FileID: "v1", Title: "Song - Artist", Start: 61500 * time.Millisecond, Duration: 30 * time.Second, Waveform: "▁▅█"}
+	if err := repo.SavePreview(ctx, clip); err != nil {
+		t.Fatalf("save preview: %v", err)
+	}
+	clip.FileID = "v2"
+	if err := repo.SavePreview(ctx, clip); err != nil {
+		t.Fatalf("replace preview: %v", err)
+	}
+	found, err := repo.FindPreview(ctx, "netease", "42")
+	if err != nil || found == nil || *found != *clip {
+		t.Fatalf("preview lookup returned %+v, %v; want %+v", found, err, clip)
+	}
+	if err := repo.SavePreview(ctx, &bot.PreviewClip{Platform: "qqmusic", TrackID: "7", FileID: "q"}); err != nil {
+		t.Fatalf("save second preview: %v", err)
+	}
+
+	if err := repo.DeletePreview(ctx, "netease", "42"); err != nil {
+		t.Fatalf("delete preview: %v", err)
+	}
+	if gone, _ := repo.FindPreview(ctx, "netease", "42"); gone != nil {
+		t.Fatalf("preview still present: %+v", gone)
+	}
+	if err := repo.DeleteAllByPlatform(ctx, "qqmusic"); err != nil {
+		t.Fatalf("delete all by platform: %v", err)
+	}
+	if gone, _ := repo.FindPreview(ctx, "qqmusic", "7"); gone != nil {
+		t.Fatalf("platform cache clear kept preview: %+v", gone)
+	}
+}
+
 func TestFindRandomCachedSongSkipsLegacyAppleEnhancedCache(t *testing.T) {
 	repo := newTempRepo(t)
 	ctx := context.Background()
@@ -747,3 +886,176 @@ func TestRepositoryFavorites(t *testing.T) {
 		t.Fatalf("expected nil random for empty scope, got %+v err=%v", rnd, err)
 	}
 }
+
+func TestRepositoryArtistSubscriptions(t *testing.T) {
+	file, err := os.CreateTemp("", "music163bot-*.db")
+	if err != nil {
+		t.Fatalf("create temp db: %v", err)
+	}
+	path := file.Name()
+	_ = file.Close()
+	defer os.Remove(path)
+
+	file2, err := os.CreateTemp("", "music163bot-data-*.db")
+	if err != nil {
+		t.Fatalf("create temp data db: %v", err)
+	}
+	dataPath := file2.Name()
+	_ = file2.Close()
+	defer os.Remove(dataPath)
+
+	base := slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{Level: slog.LevelError}))
+	gormLogger := logpkg.NewGormLogger(base, logger.Silent)
+	repo, err := NewSQLiteRepository(path, dataPath, gormLogger)
+	if err != nil {
+		t.Fatalf("new repo: %v", err)
+	}
+	ctx := context.Background()
+
+	const chatA int64 = 1001
+	const chatB int64 = -100200300
+
+	if err := repo.AddArtistSubscription(ctx, &bot.ArtistSubscription{ChatID: chatA, Platform: "netease", ArtistID: "6452", ArtistName: "周杰伦", CreatedByUserID: chatA}); err != nil {
+		t.Fatalf("add subscription: %v", err)
+	}
+	if err := repo.AddArtistSubscription(ctx, &bot.ArtistSubscription{ChatID: chatB, Platform: "netease", ArtistID: "6452", ArtistName: "周杰伦"}); err != nil {
+		t.Fatalf("add channel subscription: %v", err)
+	}
+	if ok, err := repo.IsArtistSubscribed(ctx, chatA, "netease", "6452"); err != nil || !ok {
+		t.Fatalf("expected subscribed, got ok=%v err=%v", ok, err)
+	}
+
+	subs, err := repo.ListArtistSubscriptions(ctx, chatA)
+	if err != nil || len(subs) != 1 {
+		t.Fatalf("list subscriptions = %d, err=%v", len(subs), err)
+	}
+	checkedAt := time.Now()
+	if err := repo.MarkArtistSubscriptionChecked(ctx, subs[0].ID, []string{"2", "1"}, checkedAt); err != nil {
+		t.Fatalf("mark checked: %v", err)
+	}
+
+	// Re-following refreshes the name but must not reset the known releases.
+	if err := repo.AddArtistSubscription(ctx, &bot.ArtistSubscription{ChatID: chatA, Platform: "netease", ArtistID: "6452", ArtistName: "Jay Chou"}); err != nil {
+		t.Fatalf("re-add subscription: %v", err)
+	}
+	if count, _ := repo.CountArtistSubscriptions(ctx, chatA); count != 1 {
+		t.Fatalf("count after re-add = %d, want 1", count)
+	}
+
+	due, err := repo.ListDueArtistSubscriptions(ctx, 10)
+	if err != nil || len(due) != 2 {
+		t.Fatalf("due subscriptions = %d, err=%v", len(due), err)
+	}
+	if due[0].ChatID != chatB {
+		t.Fatalf("never-checked subscription should come first, got chat %d", due[0].ChatID)
+	}
+	checked := due[1]
+	if checked.ArtistName != "Jay Chou" || strings.Join(checked.KnownReleaseIDs, ",") != "2,1" || checked.LastCheckedAt == nil {
+		t.Fatalf("checked subscription = %+v", checked)
+	}
+
+	// A failed poll of the unseeded subscription rotates it without seeding it.
+	if err := repo.MarkArtistSubscriptionPolled(ctx, due[0].ID, checkedAt.Add(time.Hour)); err != nil {
+		t.Fatalf("mark polled: %v", err)
+	}
+	due, err = repo.ListDueArtistSubscriptions(ctx, 10)
+	if err != nil || len(due) != 2 || due[0].ChatID != chatA {
+		t.Fatalf("polled subscription should rotate behind the checked one: %+v, err=%v", due, err)
+	}
+	if due[1].LastCheckedAt != nil || due[1].LastPolledAt == nil {
+		t.Fatalf("polled subscription = %+v, want polled but unseeded", due[1])
+	}
+
+	// A chat cannot remove another chat's subscription.
+	if err := repo.RemoveArtistSubscription(ctx, chatB, checked.ID); err != nil {
+		t.Fatalf("remove (wrong chat): %v", err)
+	}
+	if count, _ := repo.CountArtistSubscriptions(ctx, chatA); count != 1 {
+		t.Fatalf("foreign remove deleted the subscription")
+	}
+	if err := repo.RemoveArtistSubscription(ctx, chatA, checked.ID); err != nil {
+		t.Fatalf("remove: %v", err)
+	}
+	if ok, _ := repo.IsArtistSubscribed(ctx, chatA, "netease", "6452"); ok {
+		t.Fatalf("expected unsubscribed after remove")
+	}
+}
+
+func TestRepositoryPlaylistSubscriptions(t *testing.T) {
+	file, err := os.CreateTemp("", "music163bot-*.db")
+	if err != nil {
+		t.Fatalf("create temp db: %v", err)
+	}
+	path := file.Name()
+	_ = file.Close()
+	defer os.Remove(path)
+
+	file2, err := os.CreateTemp("", "music163bot-data-*.db")
+	if err != nil {
+		t.Fatalf("create temp data db: %v", err)
+	}
+	dataPath := file2.Name()
+	_ = file2.Close()
+	defer os.Remove(dataPath)
+
+	base := slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{Level: slog.LevelError}))
+	gormLogger := logpkg.NewGormLogger(base, logger.Silent)
+	repo, err := NewSQLiteRepository(path, dataPath, gormLogger)
+	if err != nil {
+		t.Fatalf("new repo: %v", err)
+	}
+	ctx := context.Background()
+
+	const chatA int64 = 1001
+	snapshotAt := time.Now()
+	sub := &bot.PlaylistSubscription{ChatID: chatA, Platform: "netease", PlaylistID: "19723756", Title: "飙升榜", TrackIDs: []string{"1", "2"}, LastCheckedAt: &snapshotAt}
+	if err := repo.AddPlaylistSubscription(ctx, sub); err != nil {
+		t.Fatalf("add subscription: %v", err)
+	}
+	if ok, err := repo.IsPlaylistSubscribed(ctx, chatA, "netease", "19723756"); err != nil || !ok {
+		t.Fatalf("expected subscribed, got ok=%v err=%v", ok, err)
+	}
+	subs, err := repo.ListPlaylistSubscriptions(ctx, chatA)
+	if err != nil || len(subs) != 1 {
+		t.Fatalf("list subscriptions = %d, err=%v", len(subs), err)
+	}
+	if strings.Join(subs[0].TrackIDs, ",") != "1,2" || subs[0].AutoDownload {
+		t.Fatalf("stored subscription = %+v", subs[0])
+	}
+
+	// Re-subscribing updates the flags but keeps the snapshot.
+	if err := repo.AddPlaylistSubscription(ctx, &bot.PlaylistSubscription{ChatID: chatA, Platform: "netease", PlaylistID: "19723756", Title: "Soaring", AutoDownload: true, TrackIDs: []string{"9"}}); err != nil {
+		t.Fatalf("re-add subscription: %v", err)
+	}
+	if err := repo.MarkPlaylistSubscriptionChecked(ctx, subs[0].ID, []string{"1", "2", "3"}, time.Now()); err != nil {
+		t.Fatalf("mark checked: %v", err)
+	}
+	due, err := repo.ListDuePlaylistSubscriptions(ctx, 10)
+	if err != nil || len(due) != 1 {
+		t.Fatalf("due subscriptions = %d, err=%v", len(due), err)
+	}
+	if due[0].Title != "Soaring" || !due[0].AutoDownload || strings.Join(due[0].TrackIDs, ",") != "1,2,3" {
+		t.Fatalf("due subscription = %+v", due[0])
+	}
+
+	if err := repo.SetPlaylistSubscriptionAutoDownload(ctx, chatA+1, due[0].ID, false); err != nil {
+		t.Fatalf("toggle (wrong chat): %v", err)
+	}
+	if subs, _ := repo.ListPlaylistSubscriptions(ctx, chatA); len(subs) != 1 || !subs[0].AutoDownload {
+		t.Fatalf("foreign toggle changed the subscription: %+v", subs)
+	}
+	if err := repo.SetPlaylistSubscriptionAutoDownload(ctx, chatA, due[0].ID, false); err != nil {
+		t.Fatalf("toggle: %v", err)
+	}
+	subs, _ = repo.ListPlaylistSubscriptions(ctx, chatA)
+	if len(subs) != 1 || subs[0].AutoDownload {
+		t.Fatalf("auto download should be off: %+v", subs)
+	}
+
+	if err := repo.RemovePlaylistSubscription(ctx, chatA, due[0].ID); err != nil {
+		t.Fatalf("remove: %v", err)
+	}
+	if count, _ := repo.CountPlaylistSubscriptions(ctx, chatA); count != 0 {
+		t.Fatalf("count after remove = %d", count)
+	}
+}
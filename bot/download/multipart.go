@@ -12,6 +12,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/liuran001/MusicBot-Go/bot/bandwidth"
 	"github.com/liuran001/MusicBot-Go/bot/platform"
 )
 
@@ -23,6 +24,8 @@ type MultipartDownloadOptions struct {
 	MinSize int64
 	// Size of each part in bytes (default: auto-calculated)
 	PartSize int64
+	// Bandwidth shapes part bodies; nil leaves them unlimited.
+	Bandwidth *bandwidth.Shaper
 }
 
 // MultipartDownloader handles concurrent chunk downloads
@@ -32,6 +35,7 @@ type MultipartDownloader struct {
 	concurrency int
 	minSize     int64
 	partSize    int64
+	bandwidth   *bandwidth.Shaper
 }
 
 var errRangeNotSupported = errors.New("range request not supported by server")
@@ -119,6 +123,7 @@ func NewMultipartDownloader(client *http.Client, timeout time.Duration, opts Mul
 		concurrency: opts.Concurrency,
 		minSize:     opts.MinSize,
 		partSize:    opts.PartSize,
+		bandwidth:   opts.Bandwidth,
 	}
 }
 
@@ -233,12 +238,13 @@ func (md *MultipartDownloader) downloadSingle(ctx context.Context, rawURL string
 	}
 	defer file.Close()
 
+	body := md.bandwidth.Reader(ctx, resp.Body, downloadPlatform(ctx))
 	bufp := bufPool.Get().(*[]byte)
 	defer bufPool.Put(bufp)
 	buf := *bufp
 	var written int64
 	for {
-		nr, readErr := resp.Body.Read(buf)
+		nr, readErr := body.Read(buf)
 		if nr > 0 {
 			nw, writeErr := file.Write(buf[:nr])
 			if nw > 0 {
@@ -453,6 +459,7 @@ func (md *MultipartDownloader) downloadPart(ctx context.Context, rawURL string,
 	}()
 
 	// Download part with progress tracking
+	body := md.bandwidth.Reader(ctx, resp.Body, downloadPlatform(ctx))
 	bufp := bufPool.Get().(*[]byte)
 	defer bufPool.Put(bufp)
 	buf := *bufp
@@ -473,7 +480,7 @@ func (md *MultipartDownloader) downloadPart(ctx context.Context, rawURL string,
 		if remaining < int64(len(buf)) {
 			readBuf = readBuf[:remaining]
 		}
-		nr, err := resp.Body.Read(readBuf)
+		nr, err := body.Read(readBuf)
 		if nr > 0 {
 			nw, ew := file.Write(buf[0:nr])
 			if nw > 0 {
@@ -0,0 +1,158 @@
+package download
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/liuran001/MusicBot-Go/bot/platform"
+)
+
+// ErrRangeUnsupported is returned by DownloadRange for sources that cannot be
+// fetched partially, such as platforms with their own Downloader (encrypted or
+// segmented streams). Callers fall back to Download.
+var ErrRangeUnsupported = errors.New("download: source does not support partial fetches")
+
+// DownloadRange writes bytes [offset, offset+length) of the source to destPath
+// and returns how many bytes were written. Servers that ignore Range are read
+// from the start, discarding offset bytes and stopping after length, so the
+// transfer stays bounded either way. A source shorter than the requested range
+// is not an error: the tail of a track simply yields fewer bytes.
+//
+// Unlike Download, the result is neither shared between concurrent callers nor
+// checked against the declared size or MD5, which only describe the whole file.
+func (s *DownloadService) DownloadRange(ctx context.Context, info *platform.DownloadInfo, destPath string, offset, length int64) (int64, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if info == nil || info.URL == "" {
+		return 0, errors.New("download info missing")
+	}
+	if destPath == "" {
+		return 0, errors.New("dest path missing")
+	}
+	if offset < 0 || length <= 0 {
+		return 0, fmt.Errorf("invalid range %d+%d", offset, length)
+	}
+	if info.Downloader != nil {
+		return 0, ErrRangeUnsupported
+	}
+	if err := os.MkdirAll(filepath.Dir(destPath), os.ModePerm); err != nil {
+		return 0, err
+	}
+
+	policy := immutableDownloadPolicy(info)
+	ctx = context.WithValue(ctx, downloadPolicyContextKey{}, policy)
+	ctx = withDownloadOwnedHeader(ctx, "Range")
+
+	var lastErr error
+	for _, raw := range candidateDownloadURLs(info) {
+		baseURL := rewriteNeteaseHost(raw)
+		if policy.validateURL != nil {
+			if err := policy.validateURL(baseURL); err != nil {
+				lastErr = err
+				continue
+			}
+		}
+		written, err := s.downloadRangeOnce(ctx, baseURL, policy.headers, info.MaxChunkSize, destPath, offset, length)
+		if err == nil {
+			return written, nil
+		}
+		lastErr = err
+		_ = os.Remove(destPath)
+		if ctx.Err() != nil {
+			return 0, ctx.Err()
+		}
+	}
+	return 0, lastErr
+}
+
+// downloadRangeOnce fetches the range from one URL, split into requests of at
+// most maxChunk bytes when the source demands bounded ranges.
+func (s *DownloadService) downloadRangeOnce(ctx context.Context, rawURL string, headers map[string]string, maxChunk int64, destPath string, offset, length int64) (written int64, retErr error) {
+	file, err := os.Create(destPath)
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		if closeErr := file.Close(); closeErr != nil && retErr == nil {
+			retErr = closeErr
+		}
+	}()
+
+	end := offset + length
+	for pos := offset; pos < end; {
+		chunkEnd := end
+		if maxChunk > 0 && chunkEnd-pos > maxChunk {
+			chunkEnd = pos + maxChunk
+		}
+		n, eof, err := s.fetchRange(ctx, rawURL, headers, maxChunk > 0, file, pos, chunkEnd)
+		written += n
+		if err != nil {
+			return written, err
+		}
+		pos += n
+		if eof || n == 0 {
+			break
+		}
+	}
+	if written == 0 {
+		return 0, errors.New("range download returned no data")
+	}
+	return written, nil
+}
+
+// fetchRange copies bytes [start, end) into w. eof reports that the source
+// ended before end.
+func (s *DownloadService) fetchRange(ctx context.Context, rawURL string, headers map[string]string, rangeRequired bool, w io.Writer, start, end int64) (int64, bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return 0, false, err
+	}
+	for k, v := range headers {
+		if !strings.EqualFold(k, "Range") {
+			req.Header.Set(k, v)
+		}
+	}
+	req.Header.Set("Range", fmt.Sprintf("bytes=%d-%d", start, end-1))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, false, err
+	}
+	defer resp.Body.Close()
+
+	body := io.Reader(resp.Body)
+	switch resp.StatusCode {
+	case http.StatusPartialContent:
+		if contentRange := resp.Header.Get("Content-Range"); !strings.HasPrefix(contentRange, fmt.Sprintf("bytes %d-", start)) {
+			return 0, false, fmt.Errorf("range content mismatch: got %q, expected start %d", contentRange, start)
+		}
+	case http.StatusRequestedRangeNotSatisfiable:
+		return 0, true, nil
+	case http.StatusOK:
+		if rangeRequired {
+			return 0, false, errRangeNotSupported
+		}
+		if _, err := io.CopyN(io.Discard, body, start); err != nil {
+			if err == io.EOF {
+				return 0, true, nil
+			}
+			return 0, false, err
+		}
+	default:
+		return 0, false, fmt.Errorf("range download failed with status %d", resp.StatusCode)
+	}
+
+	want := end - start
+	n, err := io.Copy(w, io.LimitReader(body, want))
+	if err != nil {
+		return n, false, err
+	}
+	return n, n < want, nil
+}
@@ -0,0 +1,122 @@
+package download
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/liuran001/MusicBot-Go/bot/platform"
+)
+
+const rangeTestBody = "0123456789abcdefghijklmnopqrstuvwxyz"
+
+func serveRange(t *testing.T, honorRange bool, maxChunk int) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Token") != "ok" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		if !honorRange {
+			_, _ = w.Write([]byte(rangeTestBody))
+			return
+		}
+		var start, end int
+		if _, err := fmt.Sscanf(r.Header.Get("Range"), "bytes=%d-%d", &start, &end); err != nil {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		if maxChunk > 0 && end-start+1 > maxChunk {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		if start >= len(rangeTestBody) {
+			w.WriteHeader(http.StatusRequestedRangeNotSatisfiable)
+			return
+		}
+		end = min(end, len(rangeTestBody)-1)
+		w.Header().Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", start, end, len(rangeTestBody)))
+		w.Header().Set("Content-Length", strconv.Itoa(end-start+1))
+		w.WriteHeader(http.StatusPartialContent)
+		_, _ = w.Write([]byte(rangeTestBody[start : end+1]))
+	}))
+}
+
+func TestDownloadRange(t *testing.T) {
+	for _, tt := range []struct {
+		name           string
+		honorRange     bool
+		maxChunk       int
+		offset, length int64
+		want           string
+	}{
+		{name: "partial content", honorRange: true, offset: 10, length: 6, want: "abcdef"},
+		{name: "ignored range", offset: 10, length: 6, want: "abcdef"},
+		{name: "bounded chunks", honorRange: true, maxChunk: 4, offset: 2, length: 10, want: "23456789ab"},
+		{name: "past end", honorRange: true, offset: 30, length: 20, want: "uvwxyz"},
+		{name: "ignored range past end", offset: 30, length: 20, want: "uvwxyz"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			server := serveRange(t, tt.honorRange, tt.maxChunk)
+			defer server.Close()
+			info := &platform.DownloadInfo{
+				URL:          server.URL + "/track.mp3",
+				Headers:      map[string]string{"X-Token": "ok"},
+				Size:         999,
+				MaxChunkSize: int64(tt.maxChunk),
+			}
+			dest := filepath.Join(t.TempDir(), "range.mp3")
+			written, err := newPolicyTestService(false).DownloadRange(context.Background(), info, dest, tt.offset, tt.length)
+			if err != nil {
+				t.Fatalf("DownloadRange() error = %v", err)
+			}
+			data, err := os.ReadFile(dest)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(data) != tt.want || written != int64(len(tt.want)) {
+				t.Fatalf("DownloadRange() = (%d, %q), want %q", written, data, tt.want)
+			}
+		})
+	}
+}
+
+func TestDownloadRangeRejectsCustomDownloader(t *testing.T) {
+	info := &platform.DownloadInfo{
+		URL: "https://example.com/encrypted.m4a",
+		Downloader: func(context.Context, *platform.DownloadInfo, string, func(int64, int64)) (int64, error) {
+			t.Fatal("custom downloader must not be called")
+			return 0, nil
+		},
+	}
+	_, err := newPolicyTestService(false).DownloadRange(context.Background(), info, filepath.Join(t.TempDir(), "x"), 0, 10)
+	if !errors.Is(err, ErrRangeUnsupported) {
+		t.Fatalf("DownloadRange() error = %v, want ErrRangeUnsupported", err)
+	}
+}
+
+func TestDownloadRangeValidatesURL(t *testing.T) {
+	info := &platform.DownloadInfo{
+		URL: "https://example.com/track.mp3",
+		ValidateURL: func(raw string) error {
+			if strings.Contains(raw, "example.com") {
+				return errors.New("untrusted host")
+			}
+			return nil
+		},
+	}
+	dest := filepath.Join(t.TempDir(), "x")
+	if _, err := newPolicyTestService(false).DownloadRange(context.Background(), info, dest, 0, 10); err == nil {
+		t.Fatal("DownloadRange() accepted a URL rejected by the validator")
+	}
+	if _, err := os.Stat(dest); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("destination created for rejected URL: %v", err)
+	}
+}
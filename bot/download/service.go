@@ -14,6 +14,8 @@ import (
 	"sync"
 	"time"
 
+	"github.com/liuran001/MusicBot-Go/bot/bandwidth"
+	"github.com/liuran001/MusicBot-Go/bot/httpproxy"
 	"github.com/liuran001/MusicBot-Go/bot/platform"
 	"github.com/liuran001/MusicBot-Go/bot/util"
 )
@@ -29,6 +31,7 @@ type DownloadService struct {
 	multipartEnabled    bool
 	multipartOpts       MultipartDownloadOptions
 	multipartDownloader *MultipartDownloader
+	bandwidth           *bandwidth.Shaper
 	inflightMu          sync.Mutex
 	inflight            map[string]*inflightDownload
 }
@@ -51,17 +54,24 @@ type downloadPolicy struct {
 }
 
 type downloadPolicyContextKey struct{}
+type downloadPlatformContextKey struct{}
 type prevalidatedURLContextKey struct{}
 type downloadOwnedHeadersContextKey struct{}
 
 type DownloadServiceOptions struct {
-	Timeout              time.Duration
-	Proxy                string
+	Timeout time.Duration
+	Proxy   string
+	// ProxyPool names the httpproxy pool downloads of a platform go through;
+	// "" keeps Proxy. The platform comes from WithPlatform on the context.
+	ProxyPool            func(platform string) string
 	CheckMD5             bool
 	MaxRetries           int
 	EnableMultipart      bool
 	MultipartConcurrency int
 	MultipartMinSize     int64
+	// Bandwidth shapes response bodies, by the platform from WithPlatform
+	// and the user from bandwidth.WithUser; nil leaves them unlimited.
+	Bandwidth *bandwidth.Shaper
 }
 
 const (
@@ -99,6 +109,9 @@ func NewDownloadService(opts DownloadServiceOptions) *DownloadService {
 	client := &http.Client{
 		Transport: transport,
 	}
+	if opts.ProxyPool != nil {
+		client.Transport = &pooledTransport{base: transport, route: opts.ProxyPool}
+	}
 	client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
 		if policy, ok := req.Context().Value(downloadPolicyContextKey{}).(downloadPolicy); ok {
 			if policy.validateURL != nil {
@@ -125,6 +138,7 @@ func NewDownloadService(opts DownloadServiceOptions) *DownloadService {
 		checkMD5:         opts.CheckMD5,
 		maxRetries:       opts.MaxRetries,
 		multipartEnabled: opts.EnableMultipart,
+		bandwidth:        opts.Bandwidth,
 		inflight:         make(map[string]*inflightDownload),
 	}
 	if s.maxRetries <= 0 {
@@ -134,6 +148,7 @@ func NewDownloadService(opts DownloadServiceOptions) *DownloadService {
 	s.multipartOpts = MultipartDownloadOptions{
 		Concurrency: opts.MultipartConcurrency,
 		MinSize:     opts.MultipartMinSize,
+		Bandwidth:   opts.Bandwidth,
 	}
 	// Always build the chunked/multipart downloader, even when multipart is
 	// disabled: some sources (e.g. googlevideo, advertised via
@@ -144,6 +159,35 @@ func NewDownloadService(opts DownloadServiceOptions) *DownloadService {
 	return s
 }
 
+// WithPlatform tags ctx with the platform a download belongs to, so the
+// ProxyPool option can route it.
+func WithPlatform(ctx context.Context, platform string) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return context.WithValue(ctx, downloadPlatformContextKey{}, platform)
+}
+
+func downloadPlatform(ctx context.Context) string {
+	platform, _ := ctx.Value(downloadPlatformContextKey{}).(string)
+	return platform
+}
+
+// pooledTransport sends a request through the platform's proxy pool, or the
+// base transport when the platform has none.
+type pooledTransport struct {
+	base  http.RoundTripper
+	route func(platform string) string
+}
+
+func (t *pooledTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	platform := downloadPlatform(req.Context())
+	if pool := t.route(platform); pool != "" {
+		return httpproxy.PoolTransport(pool).RoundTrip(req)
+	}
+	return t.base.RoundTrip(req)
+}
+
 func setDownloadPolicyHeaders(req *http.Request, headers map[string]string) {
 	if req == nil {
 		return
@@ -770,7 +814,8 @@ func (s *DownloadService) downloadOnce(ctx context.Context, rawURL string, info
 	if totalSize <= 0 && resp.ContentLength > 0 {
 		totalSize = resp.ContentLength
 	}
-	written, err := util.CopyWithProgress(file, resp.Body, totalSize, throttledProgress)
+	body := s.bandwidth.Reader(ctx, resp.Body, downloadPlatform(ctx))
+	written, err := util.CopyWithProgress(file, body, totalSize, throttledProgress)
 	closeErr := file.Close()
 	if err != nil {
 		return written, wrapDownloadIntegrityReadError(err, written, expectedSize, "download body", info.SizeIsAdvisory)
@@ -1,9 +1,18 @@
 package download
 
 import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
+
+	"github.com/liuran001/MusicBot-Go/bot/bandwidth"
+	"github.com/liuran001/MusicBot-Go/bot/platform"
 )
 
 func TestCopyToPath_NoProgress_CopiesContent(t *testing.T) {
@@ -31,3 +40,40 @@ func TestCopyToPath_NoProgress_CopiesContent(t *testing.T) {
 		t.Fatalf("content mismatch: got %q want %q", string(got), string(data))
 	}
 }
+
+func TestDownloadAppliesPlatformBandwidth(t *testing.T) {
+	const limit = 256 * 1024
+	payload := bytes.Repeat([]byte{0x5a}, 2*limit)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.ServeContent(w, r, "track.mp3", time.Time{}, bytes.NewReader(payload))
+	}))
+	defer server.Close()
+
+	for _, multipart := range []bool{false, true} {
+		t.Run(fmt.Sprintf("multipart=%v", multipart), func(t *testing.T) {
+			service := NewDownloadService(DownloadServiceOptions{
+				Timeout:              10 * time.Second,
+				MaxRetries:           1,
+				EnableMultipart:      multipart,
+				MultipartConcurrency: 2,
+				MultipartMinSize:     1,
+				Bandwidth:            bandwidth.NewShaper(bandwidth.Limits{PerPlatform: map[string]int64{"kuwo": limit}}),
+			})
+			dest := filepath.Join(t.TempDir(), "track.mp3")
+			info := &platform.DownloadInfo{URL: localhostTestURL(t, server.URL), Size: int64(len(payload))}
+
+			start := time.Now()
+			written, err := service.Download(WithPlatform(context.Background(), "kuwo"), info, dest, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if written != int64(len(payload)) {
+				t.Fatalf("written = %d, want %d", written, len(payload))
+			}
+			// One second of burst, then a second of waiting for tokens.
+			if elapsed := time.Since(start); elapsed < 800*time.Millisecond {
+				t.Fatalf("download took %s, want about 1s at %d B/s", elapsed, limit)
+			}
+		})
+	}
+}
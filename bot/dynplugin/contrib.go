@@ -0,0 +1,343 @@
+package dynplugin
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	botpkg "github.com/liuran001/MusicBot-Go/bot"
+	"github.com/liuran001/MusicBot-Go/bot/admincmd"
+	"github.com/liuran001/MusicBot-Go/bot/id3"
+	"github.com/liuran001/MusicBot-Go/bot/platform"
+)
+
+// scriptCommandInfo declares an admin command served by the script's
+// RunCommand export.
+type scriptCommandInfo struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+type scriptSettingOption struct {
+	Value string `json:"value"`
+	Label string `json:"label"`
+}
+
+// scriptSettingInfo declares a /settings entry. Plugin defaults to the script
+// plugin's name and may instead name one of its platforms.
+type scriptSettingInfo struct {
+	Plugin                string                `json:"plugin"`
+	Key                   string                `json:"key"`
+	Title                 string                `json:"title"`
+	Description           string                `json:"description"`
+	DefaultUser           string                `json:"default_user"`
+	DefaultGroup          string                `json:"default_group"`
+	Options               []scriptSettingOption `json:"options"`
+	RequireAutoLinkDetect bool                  `json:"require_auto_link_detect"`
+	GroupOnly             bool                  `json:"group_only"`
+	Order                 int                   `json:"order"`
+}
+
+// Contributions is what the loaded scripts add besides platforms, in the
+// shape compiled plugins provide through plugins.Contribution.
+type Contributions struct {
+	Commands           []admincmd.Command
+	SettingDefinitions []botpkg.PluginSettingDefinition
+	// TagProviders maps platform names to the script's GetTagData hook.
+	TagProviders map[string]id3.ID3TagProvider
+}
+
+// scriptContribution holds one plugin's validated declarations.
+type scriptContribution struct {
+	commands  []scriptCommandInfo
+	settings  []botpkg.PluginSettingDefinition
+	platforms []string
+}
+
+var scriptCommandName = regexp.MustCompile(`^[a-z0-9_]{1,32}$`)
+
+// validateContribution checks the commands and settings a script declares in
+// Meta. Invalid entries are dropped and reported, like unbacked capability
+// flags.
+func validateContribution(pluginName string, meta *pluginMeta, exports scriptExports) (scriptContribution, []string) {
+	var result scriptContribution
+	var problems []string
+	for _, info := range meta.Platforms {
+		if info.Name != "" {
+			result.platforms = append(result.platforms, info.Name)
+		}
+	}
+
+	seen := make(map[string]bool)
+	for _, cmd := range meta.Commands {
+		name := strings.ToLower(strings.TrimSpace(cmd.Name))
+		switch {
+		case !exports.has("RunCommand"):
+			problems = append(problems, fmt.Sprintf("command %q declared but RunCommand is not exported; dropped", cmd.Name))
+			continue
+		case !scriptCommandName.MatchString(name):
+			problems = append(problems, fmt.Sprintf("command %q is not a valid command name; dropped", cmd.Name))
+			continue
+		case seen[name]:
+			problems = append(problems, fmt.Sprintf("command %q declared twice; dropped", name))
+			continue
+		}
+		seen[name] = true
+		result.commands = append(result.commands, scriptCommandInfo{Name: name, Description: strings.TrimSpace(cmd.Description)})
+	}
+	if len(meta.Commands) == 0 && exports.has("RunCommand") {
+		problems = append(problems, "RunCommand is exported but no commands are declared; unused")
+	}
+
+	scopes := map[string]bool{pluginName: true}
+	for _, name := range result.platforms {
+		scopes[name] = true
+	}
+	for _, setting := range meta.Settings {
+		def, problem := buildSettingDefinition(pluginName, setting, scopes)
+		if problem != "" {
+			problems = append(problems, problem)
+			continue
+		}
+		result.settings = append(result.settings, def)
+	}
+	return result, problems
+}
+
+func buildSettingDefinition(pluginName string, setting scriptSettingInfo, scopes map[string]bool) (botpkg.PluginSettingDefinition, string) {
+	key := strings.TrimSpace(setting.Key)
+	if key == "" {
+		return botpkg.PluginSettingDefinition{}, "setting without key; dropped"
+	}
+	scope := strings.TrimSpace(setting.Plugin)
+	if scope == "" {
+		scope = pluginName
+	}
+	if !scopes[scope] {
+		return botpkg.PluginSettingDefinition{}, fmt.Sprintf("setting %q uses plugin %q, which is neither the plugin nor one of its platforms; dropped", key, scope)
+	}
+	if len(setting.Options) < 2 {
+		return botpkg.PluginSettingDefinition{}, fmt.Sprintf("setting %q needs at least two options; dropped", key)
+	}
+	def := botpkg.PluginSettingDefinition{
+		Plugin:                scope,
+		Key:                   key,
+		Title:                 strings.TrimSpace(setting.Title),
+		Description:           strings.TrimSpace(setting.Description),
+		DefaultUser:           strings.TrimSpace(setting.DefaultUser),
+		DefaultGroup:          strings.TrimSpace(setting.DefaultGroup),
+		RequireAutoLinkDetect: setting.RequireAutoLinkDetect,
+		GroupOnly:             setting.GroupOnly,
+		Order:                 setting.Order,
+	}
+	if def.Title == "" {
+		def.Title = key
+	}
+	for _, opt := range setting.Options {
+		value := strings.TrimSpace(opt.Value)
+		if value == "" {
+			return botpkg.PluginSettingDefinition{}, fmt.Sprintf("setting %q has an option without value; dropped", key)
+		}
+		label := strings.TrimSpace(opt.Label)
+		if label == "" {
+			label = value
+		}
+		def.Options = append(def.Options, botpkg.PluginSettingOption{Value: value, Label: label})
+	}
+	if def.DefaultUser == "" || !def.Validate(def.DefaultUser) {
+		def.DefaultUser = def.Options[0].Value
+	}
+	if def.DefaultGroup == "" || !def.Validate(def.DefaultGroup) {
+		def.DefaultGroup = def.DefaultUser
+	}
+	return def, ""
+}
+
+// Contributions returns the admin commands, setting definitions and tag
+// providers of the loaded scripts. Command handlers and tag providers resolve
+// the plugin at call time, so they follow later reloads of the same plugin.
+func (m *Manager) Contributions() Contributions {
+	result := Contributions{TagProviders: make(map[string]id3.ID3TagProvider)}
+	if m == nil {
+		return result
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for _, pluginName := range sortedKeys(m.contribs) {
+		contrib := m.contribs[pluginName]
+		for _, cmd := range contrib.commands {
+			result.Commands = append(result.Commands, m.scriptCommand(pluginName, cmd))
+		}
+		result.SettingDefinitions = append(result.SettingDefinitions, contrib.settings...)
+		if plug := m.scripts[pluginName]; plug != nil && plug.exports.has("GetTagData") {
+			for _, platformName := range contrib.platforms {
+				result.TagProviders[platformName] = &scriptTagProvider{manager: m, plugin: pluginName, platform: platformName}
+			}
+		}
+	}
+	return result
+}
+
+func sortedKeys(contribs map[string]scriptContribution) []string {
+	keys := make([]string, 0, len(contribs))
+	for key := range contribs {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func (m *Manager) script(pluginName string) *scriptPlugin {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.scripts[pluginName]
+}
+
+func (m *Manager) scriptCommand(pluginName string, cmd scriptCommandInfo) admincmd.Command {
+	description := cmd.Description
+	if description == "" {
+		description = fmt.Sprintf("%s 插件命令", pluginName)
+	}
+	return admincmd.Command{
+		Name:        cmd.Name,
+		Description: description,
+		Handler: func(ctx context.Context, args string) (string, error) {
+			plug := m.script(pluginName)
+			if plug == nil {
+				return "", fmt.Errorf("script plugin %s is not loaded", pluginName)
+			}
+			chatID, _ := admincmd.ChatIDFromContext(ctx)
+			return plug.RunCommand(ctx, cmd.Name, args, chatID)
+		},
+	}
+}
+
+func (p *scriptPlugin) RunCommand(ctx context.Context, name, args string, chatID int64) (string, error) {
+	if !p.exports.has("RunCommand") {
+		return "", platform.NewUnsupportedError(p.name, "command")
+	}
+	result, err := p.call(ctx, "RunCommand", "command", name, name, args, chatID)
+	if err != nil {
+		return "", err
+	}
+	if result == nil {
+		return "", nil
+	}
+	text, ok := result.(string)
+	if !ok {
+		return "", fmt.Errorf("script plugin %s: RunCommand must return a string", p.name)
+	}
+	return text, nil
+}
+
+type tagDataPayload struct {
+	Title       string         `json:"title"`
+	Artist      string         `json:"artist"`
+	Album       string         `json:"album"`
+	AlbumArtist string         `json:"album_artist"`
+	Year        string         `json:"year"`
+	TrackNumber int            `json:"track_number"`
+	DiscNumber  int            `json:"disc_number"`
+	Genre       string         `json:"genre"`
+	Comment     string         `json:"comment"`
+	CoverURL    string         `json:"cover_url"`
+	Lyrics      string         `json:"lyrics"`
+	Extra       map[string]any `json:"extra"`
+
+	Artists      []string `json:"artists"`
+	AlbumArtists []string `json:"album_artists"`
+	Genres       []string `json:"genres"`
+	Composers    []string `json:"composers"`
+	Lyricists    []string `json:"lyricists"`
+	Label        string   `json:"label"`
+	ISRC         string   `json:"isrc"`
+	Copyright    string   `json:"copyright"`
+	ReleaseDate  string   `json:"release_date"`
+}
+
+// GetTagData passes the track and download info to the script as JSON-shaped
+// maps. A nil result leaves tagging to the bot's defaults.
+func (p *scriptPlugin) GetTagData(ctx context.Context, platformName string, track *platform.Track, info *platform.DownloadInfo) (*id3.TagData, error) {
+	if !p.exports.has("GetTagData") {
+		return nil, platform.NewUnsupportedError(platformName, "tags")
+	}
+	var trackArg, infoArg map[string]interface{}
+	var err error
+	trackID := ""
+	if track != nil {
+		trackID = track.ID
+		if trackArg, err = toJSONMap(track); err != nil {
+			return nil, err
+		}
+	}
+	if info != nil {
+		if infoArg, err = toJSONMap(info); err != nil {
+			return nil, err
+		}
+	}
+	result, err := p.call(ctx, "GetTagData", "track", trackID, platformName, trackArg, infoArg)
+	if err != nil || result == nil {
+		return nil, err
+	}
+	if m, ok := result.(map[string]interface{}); ok && m == nil {
+		return nil, nil
+	}
+	var payload tagDataPayload
+	if err := decodeJSON(result, &payload); err != nil {
+		return nil, err
+	}
+	return &id3.TagData{
+		Title:       payload.Title,
+		Artist:      payload.Artist,
+		Album:       payload.Album,
+		AlbumArtist: payload.AlbumArtist,
+		Year:        payload.Year,
+		TrackNumber: payload.TrackNumber,
+		DiscNumber:  payload.DiscNumber,
+		Genre:       payload.Genre,
+		Comment:     payload.Comment,
+		CoverURL:    payload.CoverURL,
+		Lyrics:      payload.Lyrics,
+		Extra:       payload.Extra,
+
+		Artists:      payload.Artists,
+		AlbumArtists: payload.AlbumArtists,
+		Genres:       payload.Genres,
+		Composers:    payload.Composers,
+		Lyricists:    payload.Lyricists,
+		Label:        payload.Label,
+		ISRC:         payload.ISRC,
+		Copyright:    payload.Copyright,
+		ReleaseDate:  payload.ReleaseDate,
+	}, nil
+}
+
+func toJSONMap(value interface{}) (map[string]interface{}, error) {
+	b, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	var out map[string]interface{}
+	if err := json.Unmarshal(b, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// scriptTagProvider adapts a script's GetTagData to id3.ID3TagProvider.
+type scriptTagProvider struct {
+	manager  *Manager
+	plugin   string
+	platform string
+}
+
+func (t *scriptTagProvider) GetTagData(ctx context.Context, track *platform.Track, info *platform.DownloadInfo) (*id3.TagData, error) {
+	plug := t.manager.script(t.plugin)
+	if plug == nil {
+		return nil, nil
+	}
+	return plug.GetTagData(ctx, t.platform, track, info)
+}
@@ -0,0 +1,106 @@
+package dynplugin
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/liuran001/MusicBot-Go/bot/admincmd"
+	"github.com/liuran001/MusicBot-Go/bot/platform"
+)
+
+const contribScript = `package demo
+
+import "fmt"
+
+func RunCommand(name, args string, chatID int64) (string, error) {
+	return fmt.Sprintf("%s(%s) in %d", name, args, chatID), nil
+}
+
+func GetTagData(platform string, track map[string]interface{}, info map[string]interface{}) (map[string]interface{}, error) {
+	if track["id"] == "plain" {
+		return nil, nil
+	}
+	return map[string]interface{}{
+		"title":        track["title"],
+		"genre":        "Vocaloid",
+		"track_number": 3,
+		"comment":      fmt.Sprint(info["format"]),
+	}, nil
+}
+`
+
+func TestValidateContribution(t *testing.T) {
+	meta := &pluginMeta{
+		Platforms: []platformInfo{{Name: "demo_music"}},
+		Commands: []scriptCommandInfo{
+			{Name: "Demo_Sync", Description: "sync"},
+			{Name: "demo_sync"},
+			{Name: "bad name"},
+		},
+		Settings: []scriptSettingInfo{
+			{Key: "mode", Title: "Mode", DefaultUser: "missing", Options: []scriptSettingOption{{Value: "a"}, {Value: "b", Label: "B"}}},
+			{Plugin: "demo_music", Key: "filter", DefaultGroup: "b", Options: []scriptSettingOption{{Value: "a"}, {Value: "b"}}},
+			{Plugin: "netease", Key: "steal", Options: []scriptSettingOption{{Value: "a"}, {Value: "b"}}},
+			{Key: "single", Options: []scriptSettingOption{{Value: "a"}}},
+		},
+	}
+	contrib, problems := validateContribution("demo", meta, scriptExports{"RunCommand": true})
+	if len(contrib.commands) != 1 || contrib.commands[0].Name != "demo_sync" {
+		t.Fatalf("commands = %+v", contrib.commands)
+	}
+	if len(contrib.settings) != 2 {
+		t.Fatalf("settings = %+v", contrib.settings)
+	}
+	mode := contrib.settings[0]
+	if mode.Plugin != "demo" || mode.DefaultUser != "a" || mode.DefaultGroup != "a" || mode.Options[0].Label != "a" {
+		t.Fatalf("mode setting = %+v", mode)
+	}
+	if filter := contrib.settings[1]; filter.Plugin != "demo_music" || filter.DefaultGroup != "b" || filter.Title != "filter" {
+		t.Fatalf("filter setting = %+v", filter)
+	}
+	// duplicate command, bad command name, foreign plugin scope, single option.
+	if len(problems) != 4 {
+		t.Fatalf("problems = %q", problems)
+	}
+
+	_, problems = validateContribution("demo", &pluginMeta{Commands: []scriptCommandInfo{{Name: "sync"}}}, scriptExports{})
+	if len(problems) != 1 || !strings.Contains(problems[0], "RunCommand") {
+		t.Fatalf("commands without RunCommand should be dropped: %q", problems)
+	}
+}
+
+func TestScriptContributionsCallIntoInterpreter(t *testing.T) {
+	plug := loadScript(t, contribScript, scriptLimits{PoolSize: 1})
+	meta := &pluginMeta{
+		Platforms: []platformInfo{{Name: "demo_music"}},
+		Commands:  []scriptCommandInfo{{Name: "sync"}},
+	}
+	contrib, _ := validateContribution("demo", meta, plug.exports)
+	manager := NewManager(nil)
+	manager.scripts["demo"] = plug
+	manager.contribs["demo"] = contrib
+
+	contributions := manager.Contributions()
+	if len(contributions.Commands) != 1 {
+		t.Fatalf("commands = %+v", contributions.Commands)
+	}
+	ctx := admincmd.WithChatID(context.Background(), 42)
+	out, err := contributions.Commands[0].Handler(ctx, "now")
+	if err != nil || out != "sync(now) in 42" {
+		t.Fatalf("command output = %q, %v", out, err)
+	}
+
+	provider, ok := contributions.TagProviders["demo_music"]
+	if !ok {
+		t.Fatalf("GetTagData export should register a tag provider: %v", contributions.TagProviders)
+	}
+	tags, err := provider.GetTagData(context.Background(), &platform.Track{ID: "1", Title: "Song"}, &platform.DownloadInfo{Format: "flac"})
+	if err != nil || tags.Title != "Song" || tags.Genre != "Vocaloid" || tags.TrackNumber != 3 || tags.Comment != "flac" {
+		t.Fatalf("tags = %+v, %v", tags, err)
+	}
+	tags, err = provider.GetTagData(context.Background(), &platform.Track{ID: "plain"}, nil)
+	if err != nil || tags != nil {
+		t.Fatalf("nil script result should fall back to default tags, got %+v, %v", tags, err)
+	}
+}
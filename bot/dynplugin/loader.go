@@ -7,6 +7,7 @@ import (
 	"path/filepath"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/liuran001/MusicBot-Go/bot/config"
 	logpkg "github.com/liuran001/MusicBot-Go/bot/logger"
@@ -35,28 +36,53 @@ func loadScriptPlugin(ctx context.Context, name string, cfg *config.Config, logg
 		return nil, nil, fmt.Errorf("script plugin %s not found", name)
 	}
 
-	interpreter, err := newInterpreter(plugPath)
-	if err != nil {
-		return nil, nil, err
-	}
-
-	for _, file := range files {
-		if _, err := interpreter.EvalPath(file); err != nil {
-			return nil, nil, fmt.Errorf("script %s: %w", file, err)
+	build := func() (*interp.Interpreter, error) {
+		interpreter, err := newInterpreter(plugPath)
+		if err != nil {
+			return nil, err
+		}
+		for _, file := range files {
+			if _, err := interpreter.EvalPath(file); err != nil {
+				return nil, fmt.Errorf("script %s: %w", file, err)
+			}
 		}
+		return interpreter, nil
 	}
 
-	plug := newScriptPlugin(name, interpreter, logger)
-	if err := plug.Init(ctx, pluginConfig(cfg, name)); err != nil {
+	plug, err := newScriptPlugin(ctx, name, pluginConfig(cfg, name), scriptLimitsFor(cfg, name), build, logger)
+	if err != nil {
 		return nil, nil, err
 	}
 	meta, err := plug.Meta(ctx)
 	if err != nil {
+		plug.close()
 		return nil, nil, err
 	}
 	return plug, meta, nil
 }
 
+// scriptLimitsFor reads the global PluginScript* limits, overridden per plugin
+// by pool_size / call_timeout / call_rate_limit in [plugins.<name>].
+func scriptLimitsFor(cfg *config.Config, name string) scriptLimits {
+	limits := scriptLimits{
+		PoolSize:       cfg.GetInt("PluginScriptPoolSize"),
+		CallTimeout:    time.Duration(cfg.GetInt("PluginScriptCallTimeout")) * time.Second,
+		CallsPerMinute: cfg.GetInt("PluginScriptCallRateLimit"),
+	}
+	if pluginCfg, ok := cfg.GetPluginConfig(name); ok {
+		if _, exists := pluginCfg["pool_size"]; exists {
+			limits.PoolSize = cfg.GetPluginInt(name, "pool_size")
+		}
+		if _, exists := pluginCfg["call_timeout"]; exists {
+			limits.CallTimeout = time.Duration(cfg.GetPluginInt(name, "call_timeout")) * time.Second
+		}
+		if _, exists := pluginCfg["call_rate_limit"]; exists {
+			limits.CallsPerMinute = cfg.GetPluginInt(name, "call_rate_limit")
+		}
+	}
+	return limits
+}
+
 func listScriptFiles(dir string) ([]string, error) {
 	entries, err := os.ReadDir(dir)
 	if err != nil {
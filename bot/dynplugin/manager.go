@@ -14,10 +14,17 @@ import (
 )
 
 type Manager struct {
+	// reloadMu serialises full and single-plugin reloads.
+	reloadMu  sync.Mutex
 	mu        sync.RWMutex
 	platforms map[string]*scriptPlatform
-	plugins   map[string]PluginInfo
-	logger    *logpkg.Logger
+	// surfaces holds the value registered with the platform manager for each
+	// platform, so a single-plugin reload can swap it in place.
+	surfaces map[string]platform.Platform
+	plugins  map[string]PluginInfo
+	scripts  map[string]*scriptPlugin
+	contribs map[string]scriptContribution
+	logger   *logpkg.Logger
 }
 
 // PluginInfo describes metadata for a loaded script plugin.
@@ -30,7 +37,10 @@ type PluginInfo struct {
 func NewManager(logger *logpkg.Logger) *Manager {
 	return &Manager{
 		platforms: make(map[string]*scriptPlatform),
+		surfaces:  make(map[string]platform.Platform),
 		plugins:   make(map[string]PluginInfo),
+		scripts:   make(map[string]*scriptPlugin),
+		contribs:  make(map[string]scriptContribution),
 		logger:    logger,
 	}
 }
@@ -43,16 +53,40 @@ func (m *Manager) Reload(ctx context.Context, cfg *config.Config, platformManage
 	return m.reload(ctx, cfg, platformManager)
 }
 
+// providerReplacer is implemented by platform.DefaultManager.
+type providerReplacer interface {
+	ReplaceProvider(old, replacement platform.Platform) bool
+}
+
+// loadedScript is one successfully compiled plugin, ready to be attached.
+type loadedScript struct {
+	name      string
+	plug      *scriptPlugin
+	info      PluginInfo
+	contrib   scriptContribution
+	platforms []platformInfo
+}
+
 func (m *Manager) reload(ctx context.Context, cfg *config.Config, platformManager platform.Manager) error {
 	if cfg == nil {
 		return fmt.Errorf("config required")
 	}
+	m.reloadMu.Lock()
+	defer m.reloadMu.Unlock()
 	pluginNames := cfg.PluginNames()
 	if len(pluginNames) == 0 {
 		return nil
 	}
 	loaded := make(map[string]struct{})
 	pluginInfos := make(map[string]PluginInfo)
+	scripts := make(map[string]*scriptPlugin)
+	contribs := make(map[string]scriptContribution)
+
+	// The platform manager is reset before a full reload, so nothing the
+	// previous load registered is still there.
+	m.mu.Lock()
+	m.surfaces = make(map[string]platform.Platform)
+	m.mu.Unlock()
 
 	for _, name := range pluginNames {
 		if name == "" {
@@ -61,51 +95,21 @@ func (m *Manager) reload(ctx context.Context, cfg *config.Config, platformManage
 		if !pluginEnabled(cfg, name) {
 			continue
 		}
-		if _, ok := platformplugins.Get(name); ok {
+		if _, ok := platformplugins.Lookup(cfg, name); ok {
 			continue
 		}
-		plug, meta, err := loadScriptPlugin(ctx, name, cfg, m.logger)
+		script, err := m.loadScript(ctx, cfg, name)
 		if err != nil {
 			if m.logger != nil {
 				m.logger.Warn("script plugin load failed", "plugin", name, "error", err)
 			}
 			continue
 		}
-		if meta == nil || len(meta.Platforms) == 0 {
-			if m.logger != nil {
-				m.logger.Warn("script plugin returned no platforms", "plugin", name)
-			}
-			continue
-		}
-		pluginInfo := PluginInfo{
-			Name:    strings.TrimSpace(meta.Name),
-			Version: strings.TrimSpace(meta.Version),
-			URL:     strings.TrimSpace(meta.URL),
-		}
-		if pluginInfo.Name == "" {
-			pluginInfo.Name = name
-		}
-		pluginInfos[name] = pluginInfo
-		for _, info := range meta.Platforms {
-			if info.Name == "" {
-				continue
-			}
-			loaded[info.Name] = struct{}{}
-			m.mu.Lock()
-			if existing, ok := m.platforms[info.Name]; ok {
-				existing.update(plug, info)
-				m.mu.Unlock()
-				continue
-			}
-			plat := newScriptPlatform(plug, info)
-			m.platforms[info.Name] = plat
-			m.mu.Unlock()
-			if platformManager != nil {
-				platformManager.Register(plat)
-			}
-			if m.logger != nil {
-				m.logger.Info("script platform registered", "plugin", name, "platform", info.Name)
-			}
+		scripts[name] = script.plug
+		contribs[name] = script.contrib
+		pluginInfos[name] = script.info
+		for _, platformName := range m.attach(script, platformManager) {
+			loaded[platformName] = struct{}{}
 		}
 	}
 
@@ -120,12 +124,162 @@ func (m *Manager) reload(ctx context.Context, cfg *config.Config, platformManage
 	}
 	m.mu.RUnlock()
 	m.mu.Lock()
+	previous := m.scripts
 	m.plugins = pluginInfos
+	m.scripts = scripts
+	m.contribs = contribs
 	m.mu.Unlock()
+	for _, plug := range previous {
+		plug.close()
+	}
+
+	return nil
+}
+
+// ReloadPlugin recompiles one script plugin and swaps it in without touching
+// the others. If the new version fails to load, the running one is kept and
+// the error is returned. The plugin must have a [plugins.<name>] section, as
+// for a full reload. Platforms the plugin no longer declares are
+// disabled. New admin commands and settings only show up after a full reload;
+// existing ones follow the new version immediately.
+func (m *Manager) ReloadPlugin(ctx context.Context, cfg *config.Config, platformManager platform.Manager, name string) error {
+	if cfg == nil {
+		return fmt.Errorf("config required")
+	}
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return fmt.Errorf("plugin name required")
+	}
+	if _, ok := platformplugins.Lookup(cfg, name); ok {
+		return fmt.Errorf("plugin %s is compiled in, not a script", name)
+	}
+	if _, ok := cfg.GetPluginConfig(name); !ok {
+		return fmt.Errorf("script plugin %s is not configured, add [plugins.%s]", name, name)
+	}
+	if !pluginEnabled(cfg, name) {
+		return fmt.Errorf("script plugin %s is disabled", name)
+	}
+	m.reloadMu.Lock()
+	defer m.reloadMu.Unlock()
+
+	script, err := m.loadScript(ctx, cfg, name)
+	if err != nil {
+		return err
+	}
+	m.mu.RLock()
+	previous := m.scripts[name]
+	previousPlatforms := m.contribs[name].platforms
+	m.mu.RUnlock()
 
+	current := make(map[string]struct{})
+	for _, platformName := range m.attach(script, platformManager) {
+		current[platformName] = struct{}{}
+	}
+	m.mu.Lock()
+	for _, platformName := range previousPlatforms {
+		if _, ok := current[platformName]; ok {
+			continue
+		}
+		if plat, ok := m.platforms[platformName]; ok {
+			plat.disable()
+			if m.logger != nil {
+				m.logger.Info("script platform disabled", "plugin", name, "platform", platformName)
+			}
+		}
+	}
+	m.scripts[name] = script.plug
+	m.contribs[name] = script.contrib
+	m.plugins[name] = script.info
+	m.mu.Unlock()
+	if previous != nil {
+		previous.close()
+	}
+	if m.logger != nil {
+		m.logger.Info("script plugin reloaded", "plugin", name, "version", script.info.Version)
+	}
 	return nil
 }
 
+// loadScript compiles a plugin and validates what its Meta declares.
+func (m *Manager) loadScript(ctx context.Context, cfg *config.Config, name string) (*loadedScript, error) {
+	plug, meta, err := loadScriptPlugin(ctx, name, cfg, m.logger)
+	if err != nil {
+		return nil, err
+	}
+	if meta == nil || len(meta.Platforms) == 0 {
+		plug.close()
+		return nil, fmt.Errorf("script plugin %s returned no platforms", name)
+	}
+	contrib, problems := validateContribution(name, meta, plug.exports)
+	for _, problem := range problems {
+		if m.logger != nil {
+			m.logger.Warn("script plugin contribution invalid", "plugin", name, "problem", problem)
+		}
+	}
+	script := &loadedScript{
+		name:    name,
+		plug:    plug,
+		contrib: contrib,
+		info: PluginInfo{
+			Name:    strings.TrimSpace(meta.Name),
+			Version: strings.TrimSpace(meta.Version),
+			URL:     strings.TrimSpace(meta.URL),
+		},
+	}
+	if script.info.Name == "" {
+		script.info.Name = name
+	}
+	for _, info := range meta.Platforms {
+		if info.Name == "" {
+			continue
+		}
+		info, problems := validatePlatformInfo(info, plug.exports)
+		for _, problem := range problems {
+			if m.logger != nil {
+				m.logger.Warn("script plugin capability mismatch", "plugin", name, "platform", info.Name, "problem", problem)
+			}
+		}
+		script.platforms = append(script.platforms, info)
+	}
+	return script, nil
+}
+
+// attach points the script's platforms at the loaded plugin and registers
+// them. A platform that is already registered is swapped in place, because
+// the optional interfaces attached by surface follow the script's current
+// exports. It returns the names of the attached platforms.
+func (m *Manager) attach(script *loadedScript, platformManager platform.Manager) []string {
+	names := make([]string, 0, len(script.platforms))
+	for _, info := range script.platforms {
+		names = append(names, info.Name)
+		m.mu.Lock()
+		plat, ok := m.platforms[info.Name]
+		if ok {
+			plat.update(script.plug, info)
+		} else {
+			plat = newScriptPlatform(script.plug, info)
+			m.platforms[info.Name] = plat
+		}
+		previous := m.surfaces[info.Name]
+		surface := plat.surface()
+		m.surfaces[info.Name] = surface
+		m.mu.Unlock()
+		if platformManager != nil {
+			replaced := false
+			if replacer, ok := platformManager.(providerReplacer); ok && previous != nil {
+				replaced = replacer.ReplaceProvider(previous, surface)
+			}
+			if !replaced {
+				platformManager.Register(surface)
+			}
+		}
+		if m.logger != nil {
+			m.logger.Info("script platform registered", "plugin", script.name, "platform", info.Name)
+		}
+	}
+	return names
+}
+
 // PluginInfos returns metadata for loaded script plugins.
 func (m *Manager) PluginInfos() []PluginInfo {
 	if m == nil {
@@ -143,6 +297,27 @@ func (m *Manager) PluginInfos() []PluginInfo {
 	return result
 }
 
+// ScriptStats returns execution counters of the loaded script plugins.
+func (m *Manager) ScriptStats() []ScriptStats {
+	if m == nil {
+		return nil
+	}
+	m.mu.RLock()
+	plugs := make([]*scriptPlugin, 0, len(m.scripts))
+	for _, plug := range m.scripts {
+		plugs = append(plugs, plug)
+	}
+	m.mu.RUnlock()
+	result := make([]ScriptStats, 0, len(plugs))
+	for _, plug := range plugs {
+		result = append(result, plug.snapshotStats())
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Plugin < result[j].Plugin
+	})
+	return result
+}
+
 func pluginEnabled(cfg *config.Config, name string) bool {
 	pluginCfg, ok := cfg.GetPluginConfig(name)
 	if !ok {
@@ -0,0 +1,124 @@
+package dynplugin
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/liuran001/MusicBot-Go/bot/config"
+	"github.com/liuran001/MusicBot-Go/bot/platform"
+	"github.com/liuran001/MusicBot-Go/bot/platform/registry"
+)
+
+const reloadScriptV1 = `package demo
+
+func Meta() map[string]interface{} {
+	return map[string]interface{}{
+		"name":    "demo",
+		"version": "1.0.0",
+		"platforms": []interface{}{
+			map[string]interface{}{"name": "demo", "display_name": "Demo One", "aliases": []interface{}{"d1"}},
+		},
+	}
+}
+`
+
+const reloadScriptV2 = `package demo
+
+func Meta() map[string]interface{} {
+	return map[string]interface{}{
+		"name":    "demo",
+		"version": "2.0.0",
+		"platforms": []interface{}{
+			map[string]interface{}{"name": "demo", "display_name": "Demo Two", "aliases": []interface{}{"d2"}},
+		},
+	}
+}
+
+func CheckCookie(platform string) (map[string]interface{}, error) {
+	return map[string]interface{}{"ok": true, "message": "v2"}, nil
+}
+`
+
+func writeReloadFixture(t *testing.T, src string) (*config.Config, string) {
+	t.Helper()
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "go.mod"), []byte("module scripttest\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	scriptDir := filepath.Join(root, "scripts")
+	writeReloadScript(t, scriptDir, src)
+	cfgPath := filepath.Join(root, "config.ini")
+	content := "BOT_TOKEN = test\nPluginScriptDir = " + scriptDir + "\nPluginScriptPoolSize = 1\n\n[plugins.demo]\nenabled = true\n"
+	if err := os.WriteFile(cfgPath, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := config.Load(cfgPath)
+	if err != nil {
+		t.Fatalf("load config: %v", err)
+	}
+	return cfg, scriptDir
+}
+
+func writeReloadScript(t *testing.T, scriptDir, src string) {
+	t.Helper()
+	dir := filepath.Join(scriptDir, "demo")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "demo.go"), []byte(src), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestReloadPluginSwapsOnlyThatPlugin(t *testing.T) {
+	ctx := context.Background()
+	cfg, scriptDir := writeReloadFixture(t, reloadScriptV1)
+	platforms := platform.NewManagerWithRegistry(registry.New())
+	manager := NewManager(nil)
+	if err := manager.Load(ctx, cfg, platforms); err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if meta, _ := platforms.Meta("demo"); meta.DisplayName != "Demo One" {
+		t.Fatalf("initial meta = %+v", meta)
+	}
+	first := manager.script("demo")
+
+	// A broken save keeps the running version.
+	writeReloadScript(t, scriptDir, "package demo\n\nfunc Meta( {\n")
+	if err := manager.ReloadPlugin(ctx, cfg, platforms, "demo"); err == nil {
+		t.Fatal("expected compile error")
+	}
+	if manager.script("demo") != first || first.closed.Load() {
+		t.Fatal("failed reload must keep the old plugin running")
+	}
+
+	writeReloadScript(t, scriptDir, reloadScriptV2)
+	if err := manager.ReloadPlugin(ctx, cfg, platforms, "demo"); err != nil {
+		t.Fatalf("reload: %v", err)
+	}
+	if !first.closed.Load() {
+		t.Fatal("old plugin should be closed after a successful reload")
+	}
+	if infos := manager.PluginInfos(); len(infos) != 1 || infos[0].Version != "2.0.0" {
+		t.Fatalf("plugin infos = %+v", infos)
+	}
+	if names := platforms.List(); len(names) != 1 {
+		t.Fatalf("platform registered twice: %v", names)
+	}
+	checker, ok := platforms.Get("demo").(platform.CookieChecker)
+	if !ok {
+		t.Fatal("new CheckCookie export should be attached after reload")
+	}
+	if result, err := checker.CheckCookie(ctx); err != nil || !strings.Contains(result.Message, "v2") {
+		t.Fatalf("CheckCookie = %+v, %v", result, err)
+	}
+	if name, ok := platforms.ResolveAlias("d2"); !ok || name != "demo" {
+		t.Fatalf("new alias not indexed: %q, %v", name, ok)
+	}
+	if _, ok := platforms.ResolveAlias("d1"); ok {
+		t.Fatal("stale alias should be dropped")
+	}
+}
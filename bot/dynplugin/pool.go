@@ -0,0 +1,365 @@
+package dynplugin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"runtime/debug"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/traefik/yaegi/interp"
+	"golang.org/x/time/rate"
+)
+
+const (
+	defaultScriptPoolSize = 2
+	// scriptSpawnTimeout bounds Init of a replacement interpreter.
+	scriptSpawnTimeout = time.Minute
+	// scriptAbandonedPerInstance caps timed-out calls still running in the
+	// background at this many per pool slot; retired instances are only
+	// replaced while the plugin is under the cap.
+	scriptAbandonedPerInstance = 2
+	scriptRespawnMaxBackoff    = time.Minute
+)
+
+// scriptRespawnBackoff is the first wait before a failed or capped respawn is
+// retried; it doubles up to scriptRespawnMaxBackoff.
+var scriptRespawnBackoff = time.Second
+
+// scriptLimits controls how calls into one script plugin are executed.
+type scriptLimits struct {
+	// PoolSize is the number of interpreter instances, i.e. how many calls
+	// into the plugin may run at once.
+	PoolSize int
+	// CallTimeout is the host-enforced deadline of a single call, including
+	// the wait for a free instance. Zero disables it.
+	CallTimeout time.Duration
+	// CallsPerMinute caps the call rate across all instances. Zero means
+	// unlimited.
+	CallsPerMinute int
+}
+
+func (l scriptLimits) normalized() scriptLimits {
+	if l.PoolSize <= 0 {
+		l.PoolSize = defaultScriptPoolSize
+	}
+	if l.CallTimeout < 0 {
+		l.CallTimeout = 0
+	}
+	if l.CallsPerMinute < 0 {
+		l.CallsPerMinute = 0
+	}
+	return l
+}
+
+// scriptInstance is one interpreter with the plugin's files evaluated. An
+// instance serves one call at a time; it is owned by whoever took it from the
+// pool.
+type scriptInstance struct {
+	interp *interp.Interpreter
+	funcs  map[string]reflect.Value
+}
+
+func newScriptInstance(interpreter *interp.Interpreter) *scriptInstance {
+	return &scriptInstance{interp: interpreter, funcs: make(map[string]reflect.Value)}
+}
+
+func (i *scriptInstance) lookup(pkg, name string) (reflect.Value, bool) {
+	if fn, ok := i.funcs[name]; ok {
+		return fn, fn.IsValid()
+	}
+	value, err := i.interp.Eval(fmt.Sprintf("%s.%s", pkg, name))
+	if err != nil {
+		value = reflect.Value{}
+	}
+	i.funcs[name] = value
+	return value, value.IsValid()
+}
+
+var errScriptFuncMissing = errors.New("script function missing")
+
+type scriptOutcome struct {
+	outputs  []reflect.Value
+	panicked interface{}
+	stack    []byte
+}
+
+// invoke runs fnName on inst in its own goroutine, so a script that ignores
+// its deadline or panics cannot take the caller down with it. After a timeout
+// the script keeps running in the background, counted in p.abandoned until it
+// returns; the caller must retire inst.
+func (p *scriptPlugin) invoke(ctx context.Context, inst *scriptInstance, fnName string, args []interface{}) ([]reflect.Value, error) {
+	fn, ok := inst.lookup(p.name, fnName)
+	if !ok {
+		return nil, errScriptFuncMissing
+	}
+	inputs := make([]reflect.Value, 0, len(args))
+	for _, arg := range args {
+		inputs = append(inputs, reflect.ValueOf(arg))
+	}
+	done := make(chan scriptOutcome, 1)
+	// finished is swapped by the call when it returns and by the caller when
+	// it gives up; whichever comes second settles the abandoned count.
+	var finished atomic.Bool
+	go func() {
+		defer func() {
+			if finished.Swap(true) {
+				p.abandoned.Add(-1)
+			}
+		}()
+		defer func() {
+			if r := recover(); r != nil {
+				done <- scriptOutcome{panicked: r, stack: debug.Stack()}
+			}
+		}()
+		done <- scriptOutcome{outputs: fn.Call(inputs)}
+	}()
+	select {
+	case out := <-done:
+		if out.panicked != nil {
+			if p.logger != nil {
+				p.logger.Warn("script plugin panicked", "plugin", p.name, "func", fnName, "panic", fmt.Sprint(out.panicked), "stack", string(out.stack))
+			}
+			return nil, &scriptPanicError{plugin: p.name, fn: fnName, value: out.panicked}
+		}
+		return out.outputs, nil
+	case <-ctx.Done():
+		p.abandoned.Add(1)
+		if finished.Swap(true) {
+			p.abandoned.Add(-1)
+		}
+		return nil, fmt.Errorf("script %s.%s: %w", p.name, fnName, ctx.Err())
+	}
+}
+
+type scriptPanicError struct {
+	plugin string
+	fn     string
+	value  interface{}
+}
+
+func (e *scriptPanicError) Error() string {
+	return fmt.Sprintf("script %s.%s panicked: %v", e.plugin, e.fn, e.value)
+}
+
+// acquire takes an idle instance, waiting until ctx is done.
+func (p *scriptPlugin) acquire(ctx context.Context) (*scriptInstance, error) {
+	select {
+	case inst := <-p.idle:
+		return inst, nil
+	default:
+	}
+	select {
+	case inst := <-p.idle:
+		return inst, nil
+	case <-ctx.Done():
+		return nil, fmt.Errorf("script plugin %s busy: %w", p.name, ctx.Err())
+	}
+}
+
+func (p *scriptPlugin) release(inst *scriptInstance) {
+	p.idle <- inst
+}
+
+// retire drops an instance that timed out or panicked and builds a
+// replacement in the background, keeping the pool at its configured size.
+func (p *scriptPlugin) retire(inst *scriptInstance) {
+	p.live.Add(-1)
+	p.stats.retired.Add(1)
+	if p.closed.Load() || p.build == nil {
+		return
+	}
+	go p.respawn()
+}
+
+// respawn builds one replacement instance, retrying with backoff until it
+// succeeds or the plugin is closed. While too many timed-out calls are still
+// running it waits for them instead, so a script that hangs on every call
+// cannot pile up goroutines without bound.
+func (p *scriptPlugin) respawn() {
+	backoff := scriptRespawnBackoff
+	capped := false
+	for attempt := 1; !p.closed.Load(); attempt++ {
+		if limit := int64(p.limits.PoolSize * scriptAbandonedPerInstance); p.abandoned.Load() >= limit {
+			if !capped && p.logger != nil {
+				p.logger.Warn("script plugin respawn waiting for abandoned calls", "plugin", p.name, "abandoned", p.abandoned.Load(), "limit", limit)
+			}
+			capped = true
+		} else {
+			ctx, cancel := context.WithTimeout(context.Background(), scriptSpawnTimeout)
+			replacement, err := p.spawn(ctx)
+			cancel()
+			if err == nil {
+				if p.closed.Load() {
+					return
+				}
+				p.live.Add(1)
+				p.release(replacement)
+				return
+			}
+			if p.logger != nil {
+				p.logger.Warn("script plugin instance respawn failed", "plugin", p.name, "attempt", attempt, "retry_in", backoff, "error", err)
+			}
+		}
+		time.Sleep(backoff)
+		backoff = min(backoff*2, scriptRespawnMaxBackoff)
+	}
+}
+
+// spawn builds a fresh instance and runs the script's Init on it.
+func (p *scriptPlugin) spawn(ctx context.Context) (*scriptInstance, error) {
+	interpreter, err := p.build()
+	if err != nil {
+		return nil, err
+	}
+	inst := newScriptInstance(interpreter)
+	if _, ok := inst.lookup(p.name, "Init"); !ok {
+		return inst, nil
+	}
+	if p.limits.CallTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, p.limits.CallTimeout)
+		defer cancel()
+	}
+	outputs, err := p.invoke(ctx, inst, "Init", []interface{}{p.initCfg})
+	if err != nil {
+		return nil, err
+	}
+	if len(outputs) > 0 {
+		if err := asError(outputs[len(outputs)-1]); err != nil {
+			return nil, err
+		}
+	}
+	return inst, nil
+}
+
+// close stops replacing retired instances; calls already running finish.
+func (p *scriptPlugin) close() {
+	p.closed.Store(true)
+}
+
+func newCallLimiter(callsPerMinute int) *rate.Limiter {
+	if callsPerMinute <= 0 {
+		return nil
+	}
+	burst := callsPerMinute / 6
+	if burst < 1 {
+		burst = 1
+	}
+	return rate.NewLimiter(rate.Limit(float64(callsPerMinute)/60), burst)
+}
+
+type scriptCallResult int
+
+const (
+	scriptCallOK scriptCallResult = iota
+	scriptCallError
+	scriptCallTimeout
+	scriptCallPanic
+	scriptCallRejected
+)
+
+type scriptFuncCounters struct {
+	calls, errors, timeouts, panics, rejected int64
+	total, max                                time.Duration
+}
+
+type scriptStats struct {
+	mu      sync.Mutex
+	funcs   map[string]*scriptFuncCounters
+	retired atomic.Int64
+}
+
+func newScriptStats() *scriptStats {
+	return &scriptStats{funcs: make(map[string]*scriptFuncCounters)}
+}
+
+func (s *scriptStats) record(fnName string, latency time.Duration, result scriptCallResult) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c, ok := s.funcs[fnName]
+	if !ok {
+		c = &scriptFuncCounters{}
+		s.funcs[fnName] = c
+	}
+	c.calls++
+	switch result {
+	case scriptCallError:
+		c.errors++
+	case scriptCallTimeout:
+		c.timeouts++
+	case scriptCallPanic:
+		c.panics++
+	case scriptCallRejected:
+		c.rejected++
+		return
+	}
+	c.total += latency
+	if latency > c.max {
+		c.max = latency
+	}
+}
+
+// ScriptStats is a snapshot of one script plugin's execution counters since
+// it was (re)loaded.
+type ScriptStats struct {
+	Plugin   string
+	PoolSize int
+	Live     int
+	Retired  int64
+	// Abandoned counts timed-out calls whose script is still running.
+	Abandoned      int64
+	CallTimeout    time.Duration
+	CallsPerMinute int
+	Funcs          []ScriptFuncStats
+}
+
+// ScriptFuncStats holds the counters of one exported script function.
+// Rejected calls (rate limit, no free instance) are not part of the latency.
+type ScriptFuncStats struct {
+	Name       string
+	Calls      int64
+	Errors     int64
+	Timeouts   int64
+	Panics     int64
+	Rejected   int64
+	AvgLatency time.Duration
+	MaxLatency time.Duration
+}
+
+func (p *scriptPlugin) snapshotStats() ScriptStats {
+	result := ScriptStats{
+		Plugin:         p.name,
+		PoolSize:       p.limits.PoolSize,
+		Live:           int(p.live.Load()),
+		Retired:        p.stats.retired.Load(),
+		Abandoned:      p.abandoned.Load(),
+		CallTimeout:    p.limits.CallTimeout,
+		CallsPerMinute: p.limits.CallsPerMinute,
+	}
+	p.stats.mu.Lock()
+	for name, c := range p.stats.funcs {
+		item := ScriptFuncStats{
+			Name:       name,
+			Calls:      c.calls,
+			Errors:     c.errors,
+			Timeouts:   c.timeouts,
+			Panics:     c.panics,
+			Rejected:   c.rejected,
+			MaxLatency: c.max,
+		}
+		if ran := c.calls - c.rejected; ran > 0 {
+			item.AvgLatency = c.total / time.Duration(ran)
+		}
+		result.Funcs = append(result.Funcs, item)
+	}
+	p.stats.mu.Unlock()
+	sort.Slice(result.Funcs, func(i, j int) bool {
+		return result.Funcs[i].Name < result.Funcs[j].Name
+	})
+	return result
+}
@@ -0,0 +1,174 @@
+package dynplugin
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/liuran001/MusicBot-Go/bot/platform"
+	"github.com/traefik/yaegi/interp"
+)
+
+const poolScript = `package demo
+
+import "time"
+
+var initialized int
+
+func Init(cfg map[string]string) error {
+	initialized++
+	return nil
+}
+
+func Sleep(platform string, ms int) (map[string]interface{}, error) {
+	time.Sleep(time.Duration(ms) * time.Millisecond)
+	return map[string]interface{}{"init": initialized}, nil
+}
+
+func Boom(platform string) (map[string]interface{}, error) {
+	var m map[string]int
+	m["x"] = 1
+	return nil, nil
+}
+`
+
+func waitForLive(t *testing.T, plug *scriptPlugin, want int32) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for plug.live.Load() != want {
+		if time.Now().After(deadline) {
+			t.Fatalf("live instances = %d, want %d", plug.live.Load(), want)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestScriptPoolRunsCallsConcurrently(t *testing.T) {
+	plug := loadScript(t, poolScript, scriptLimits{PoolSize: 2})
+	start := time.Now()
+	var wg sync.WaitGroup
+	errs := make(chan error, 2)
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := plug.call(context.Background(), "Sleep", "track", "", "demo", 200)
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("call: %v", err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed > 380*time.Millisecond {
+		t.Fatalf("two calls on a pool of two took %s, want them in parallel", elapsed)
+	}
+}
+
+func TestScriptPoolRetiresTimedOutInstance(t *testing.T) {
+	plug := loadScript(t, poolScript, scriptLimits{PoolSize: 1, CallTimeout: 50 * time.Millisecond})
+	_, err := plug.call(context.Background(), "Sleep", "track", "", "demo", 500)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("slow call error = %v, want deadline exceeded", err)
+	}
+	// The hung instance is replaced by a freshly initialized one.
+	waitForLive(t, plug, 1)
+	result, err := plug.call(context.Background(), "Sleep", "track", "", "demo", 0)
+	if err != nil {
+		t.Fatalf("call after respawn: %v", err)
+	}
+	if got := result.(map[string]interface{})["init"]; got != 1 {
+		t.Fatalf("replacement instance init count = %v, want 1", got)
+	}
+	stats := plug.snapshotStats()
+	if stats.Retired != 1 || len(stats.Funcs) != 1 || stats.Funcs[0].Timeouts != 1 || stats.Funcs[0].Calls != 2 {
+		t.Fatalf("stats = %+v", stats)
+	}
+}
+
+func TestScriptPoolRetriesFailedRespawn(t *testing.T) {
+	defer func(prev time.Duration) { scriptRespawnBackoff = prev }(scriptRespawnBackoff)
+	scriptRespawnBackoff = 10 * time.Millisecond
+
+	plug := loadScript(t, poolScript, scriptLimits{PoolSize: 1})
+	var failures atomic.Int32
+	failures.Store(2)
+	build := plug.build
+	plug.build = func() (*interp.Interpreter, error) {
+		if failures.Add(-1) >= 0 {
+			return nil, errors.New("build failed")
+		}
+		return build()
+	}
+	if _, err := plug.call(context.Background(), "Boom", "track", "", "demo"); err == nil {
+		t.Fatal("panicking call should fail")
+	}
+	// Two failed builds must not leave the pool empty.
+	waitForLive(t, plug, 1)
+	if _, err := plug.call(context.Background(), "Sleep", "track", "", "demo", 0); err != nil {
+		t.Fatalf("call after respawn retries: %v", err)
+	}
+}
+
+func TestScriptPoolCapsAbandonedCalls(t *testing.T) {
+	defer func(prev time.Duration) { scriptRespawnBackoff = prev }(scriptRespawnBackoff)
+	scriptRespawnBackoff = 10 * time.Millisecond
+
+	plug := loadScript(t, poolScript, scriptLimits{PoolSize: 1, CallTimeout: 20 * time.Millisecond})
+	for i := 0; i < scriptAbandonedPerInstance; i++ {
+		waitForLive(t, plug, 1)
+		if _, err := plug.call(context.Background(), "Sleep", "track", "", "demo", 400); !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("call %d error = %v, want deadline exceeded", i, err)
+		}
+	}
+	if got := plug.snapshotStats().Abandoned; got != scriptAbandonedPerInstance {
+		t.Fatalf("abandoned = %d, want %d", got, scriptAbandonedPerInstance)
+	}
+	time.Sleep(100 * time.Millisecond)
+	if live := plug.live.Load(); live != 0 {
+		t.Fatalf("live = %d while at the abandoned cap, want 0", live)
+	}
+	// Once the hung calls return the pool is refilled.
+	waitForLive(t, plug, 1)
+	if got := plug.snapshotStats().Abandoned; got != 0 {
+		t.Fatalf("abandoned after the calls returned = %d, want 0", got)
+	}
+}
+
+func TestScriptPoolIsolatesPanics(t *testing.T) {
+	plug := loadScript(t, poolScript, scriptLimits{PoolSize: 1})
+	_, err := plug.call(context.Background(), "Boom", "track", "", "demo")
+	var panicErr *scriptPanicError
+	if !errors.As(err, &panicErr) {
+		t.Fatalf("panicking call error = %v", err)
+	}
+	waitForLive(t, plug, 1)
+	if _, err := plug.call(context.Background(), "Sleep", "track", "", "demo", 0); err != nil {
+		t.Fatalf("call after panic: %v", err)
+	}
+	stats := plug.snapshotStats()
+	if stats.Retired != 1 {
+		t.Fatalf("retired = %d, want 1", stats.Retired)
+	}
+}
+
+func TestScriptPoolCallRateLimit(t *testing.T) {
+	plug := loadScript(t, poolScript, scriptLimits{PoolSize: 1, CallsPerMinute: 6})
+	if _, err := plug.call(context.Background(), "Sleep", "track", "", "demo", 0); err != nil {
+		t.Fatalf("first call: %v", err)
+	}
+	_, err := plug.call(context.Background(), "Sleep", "track", "", "demo", 0)
+	if !errors.Is(err, platform.ErrRateLimited) {
+		t.Fatalf("second call error = %v, want rate limited", err)
+	}
+	stats := plug.snapshotStats()
+	if stats.Funcs[0].Rejected != 1 || stats.Funcs[0].Calls != 2 {
+		t.Fatalf("stats = %+v", stats.Funcs[0])
+	}
+}
@@ -3,23 +3,28 @@ package dynplugin
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"reflect"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	logpkg "github.com/liuran001/MusicBot-Go/bot/logger"
 	"github.com/liuran001/MusicBot-Go/bot/platform"
 	"github.com/traefik/yaegi/interp"
+	"golang.org/x/time/rate"
 )
 
 type pluginMeta struct {
-	Name      string         `json:"name"`
-	Version   string         `json:"version"`
-	URL       string         `json:"url"`
-	Platforms []platformInfo `json:"platforms"`
+	Name      string              `json:"name"`
+	Version   string              `json:"version"`
+	URL       string              `json:"url"`
+	Platforms []platformInfo      `json:"platforms"`
+	Commands  []scriptCommandInfo `json:"commands"`
+	Settings  []scriptSettingInfo `json:"settings"`
 }
 
 type platformInfo struct {
@@ -34,32 +39,59 @@ type platformInfo struct {
 	SupportsMatchText bool                  `json:"supports_match_text"`
 }
 
+// scriptPlugin runs one script plugin on a pool of interpreter instances.
+// Every call takes an idle instance, so up to limits.PoolSize calls run in
+// parallel; see pool.go for deadlines, panic isolation and counters.
 type scriptPlugin struct {
-	name   string
-	interp *interp.Interpreter
-	logger *logpkg.Logger
-	mu     sync.Mutex
-}
-
-func newScriptPlugin(name string, interpreter *interp.Interpreter, logger *logpkg.Logger) *scriptPlugin {
-	return &scriptPlugin{name: name, interp: interpreter, logger: logger}
-}
-
-func (p *scriptPlugin) Init(ctx context.Context, cfg map[string]string) error {
-	fn, ok := p.lookup("Init")
-	if !ok {
-		return nil
+	name    string
+	logger  *logpkg.Logger
+	limits  scriptLimits
+	initCfg map[string]string
+	build   func() (*interp.Interpreter, error)
+	idle    chan *scriptInstance
+	live    atomic.Int32
+	closed  atomic.Bool
+	// abandoned counts calls that timed out but are still running.
+	abandoned atomic.Int64
+	limiter   *rate.Limiter
+	stats     *scriptStats
+	exports   scriptExports
+}
+
+// newScriptPlugin builds the instance pool, running Init on every instance.
+// build must return an interpreter with the plugin's files evaluated.
+func newScriptPlugin(ctx context.Context, name string, cfg map[string]string, limits scriptLimits, build func() (*interp.Interpreter, error), logger *logpkg.Logger) (*scriptPlugin, error) {
+	limits = limits.normalized()
+	plug := &scriptPlugin{
+		name:    name,
+		logger:  logger,
+		limits:  limits,
+		initCfg: cfg,
+		build:   build,
+		idle:    make(chan *scriptInstance, limits.PoolSize),
+		limiter: newCallLimiter(limits.CallsPerMinute),
+		stats:   newScriptStats(),
+	}
+	for i := 0; i < limits.PoolSize; i++ {
+		inst, err := plug.spawn(ctx)
+		if err != nil {
+			plug.close()
+			return nil, err
+		}
+		if i == 0 {
+			plug.exports = plug.detectExports(inst)
+		}
+		plug.live.Add(1)
+		plug.release(inst)
 	}
-	_, err := p.call(ctx, fn, "init", "", cfg)
-	return err
+	return plug, nil
 }
 
 func (p *scriptPlugin) Meta(ctx context.Context) (*pluginMeta, error) {
-	fn, ok := p.lookup("Meta")
-	if !ok {
+	result, err := p.call(ctx, "Meta", "meta", "")
+	if errors.Is(err, errScriptFuncMissing) {
 		return nil, fmt.Errorf("script plugin %s missing Meta", p.name)
 	}
-	result, err := p.call(ctx, fn, "meta", "")
 	if err != nil {
 		return nil, err
 	}
@@ -71,11 +103,28 @@ func (p *scriptPlugin) Meta(ctx context.Context) (*pluginMeta, error) {
 }
 
 func (p *scriptPlugin) MatchURL(ctx context.Context, platformName, rawURL string) (string, bool) {
-	fn, ok := p.lookup("MatchURL")
-	if !ok {
+	return p.matchID(ctx, "MatchURL", platformName, rawURL)
+}
+
+func (p *scriptPlugin) MatchText(ctx context.Context, platformName, text string) (string, bool) {
+	return p.matchID(ctx, "MatchText", platformName, text)
+}
+
+func (p *scriptPlugin) MatchPlaylistURL(ctx context.Context, platformName, rawURL string) (string, bool) {
+	return p.matchID(ctx, "MatchPlaylistURL", platformName, rawURL)
+}
+
+func (p *scriptPlugin) MatchArtistURL(ctx context.Context, platformName, rawURL string) (string, bool) {
+	return p.matchID(ctx, "MatchArtistURL", platformName, rawURL)
+}
+
+// matchID calls one of the Match* script functions, which all answer
+// {"id": ..., "matched": ...}.
+func (p *scriptPlugin) matchID(ctx context.Context, fnName, platformName, input string) (string, bool) {
+	if !p.exports.has(fnName) {
 		return "", false
 	}
-	result, err := p.call(ctx, fn, "match", "", platformName, rawURL)
+	result, err := p.call(ctx, fnName, "match", "", platformName, input)
 	if err != nil {
 		return "", false
 	}
@@ -89,31 +138,26 @@ func (p *scriptPlugin) MatchURL(ctx context.Context, platformName, rawURL string
 	return resp.ID, resp.Matched
 }
 
-func (p *scriptPlugin) MatchText(ctx context.Context, platformName, text string) (string, bool) {
-	fn, ok := p.lookup("MatchText")
-	if !ok {
-		return "", false
-	}
-	result, err := p.call(ctx, fn, "match", "", platformName, text)
-	if err != nil {
-		return "", false
+func (p *scriptPlugin) ShortLinkHosts(ctx context.Context, platformName string) []string {
+	if !p.exports.has("ShortLinkHosts") {
+		return nil
 	}
-	var resp struct {
-		ID      string `json:"id"`
-		Matched bool   `json:"matched"`
+	result, err := p.call(ctx, "ShortLinkHosts", "match", "", platformName)
+	if err != nil || result == nil {
+		return nil
 	}
-	if err := decodeJSON(result, &resp); err != nil {
-		return "", false
+	var hosts []string
+	if err := decodeJSON(result, &hosts); err != nil {
+		return nil
 	}
-	return resp.ID, resp.Matched
+	return hosts
 }
 
 func (p *scriptPlugin) Search(ctx context.Context, platformName, query string, limit int) ([]platform.Track, error) {
-	fn, ok := p.lookup("Search")
-	if !ok {
+	if !p.exports.has("Search") {
 		return nil, platform.NewUnsupportedError(platformName, "search")
 	}
-	result, err := p.call(ctx, fn, "search", "", platformName, query, limit)
+	result, err := p.call(ctx, "Search", "search", "", platformName, query, limit)
 	if err != nil {
 		return nil, err
 	}
@@ -125,11 +169,10 @@ func (p *scriptPlugin) Search(ctx context.Context, platformName, query string, l
 }
 
 func (p *scriptPlugin) GetTrack(ctx context.Context, platformName, trackID string) (*platform.Track, error) {
-	fn, ok := p.lookup("GetTrack")
-	if !ok {
+	if !p.exports.has("GetTrack") {
 		return nil, platform.NewUnsupportedError(platformName, "track")
 	}
-	result, err := p.call(ctx, fn, "track", trackID, platformName, trackID)
+	result, err := p.call(ctx, "GetTrack", "track", trackID, platformName, trackID)
 	if err != nil {
 		return nil, err
 	}
@@ -141,11 +184,10 @@ func (p *scriptPlugin) GetTrack(ctx context.Context, platformName, trackID strin
 }
 
 func (p *scriptPlugin) GetLyrics(ctx context.Context, platformName, trackID string) (*platform.Lyrics, error) {
-	fn, ok := p.lookup("GetLyrics")
-	if !ok {
+	if !p.exports.has("GetLyrics") {
 		return nil, platform.NewUnsupportedError(platformName, "lyrics")
 	}
-	result, err := p.call(ctx, fn, "lyrics", trackID, platformName, trackID)
+	result, err := p.call(ctx, "GetLyrics", "lyrics", trackID, platformName, trackID)
 	if err != nil {
 		return nil, err
 	}
@@ -157,11 +199,10 @@ func (p *scriptPlugin) GetLyrics(ctx context.Context, platformName, trackID stri
 }
 
 func (p *scriptPlugin) GetDownloadInfo(ctx context.Context, platformName, trackID string, quality platform.Quality) (*platform.DownloadInfo, error) {
-	fn, ok := p.lookup("GetDownloadInfo")
-	if !ok {
+	if !p.exports.has("GetDownloadInfo") {
 		return nil, platform.NewUnsupportedError(platformName, "download")
 	}
-	result, err := p.call(ctx, fn, "track", trackID, platformName, trackID, quality.String())
+	result, err := p.call(ctx, "GetDownloadInfo", "track", trackID, platformName, trackID, quality.String())
 	if err != nil {
 		return nil, err
 	}
@@ -169,11 +210,10 @@ func (p *scriptPlugin) GetDownloadInfo(ctx context.Context, platformName, trackI
 }
 
 func (p *scriptPlugin) GetPlaylist(ctx context.Context, platformName, playlistID string) (*platform.Playlist, error) {
-	fn, ok := p.lookup("GetPlaylist")
-	if !ok {
+	if !p.exports.has("GetPlaylist") {
 		return nil, platform.NewUnsupportedError(platformName, "playlist")
 	}
-	result, err := p.call(ctx, fn, "playlist", playlistID, platformName, playlistID)
+	result, err := p.call(ctx, "GetPlaylist", "playlist", playlistID, platformName, playlistID)
 	if err != nil {
 		return nil, err
 	}
@@ -184,40 +224,192 @@ func (p *scriptPlugin) GetPlaylist(ctx context.Context, platformName, playlistID
 	return &playlist, nil
 }
 
-func (p *scriptPlugin) lookup(name string) (reflect.Value, bool) {
-	p.mu.Lock()
-	defer p.mu.Unlock()
-	value, err := p.interp.Eval(fmt.Sprintf("%s.%s", p.name, name))
+func (p *scriptPlugin) GetArtist(ctx context.Context, platformName, artistID string) (*platform.Artist, error) {
+	if !p.exports.has("GetArtist") {
+		return nil, platform.NewUnsupportedError(platformName, "get artist")
+	}
+	result, err := p.call(ctx, "GetArtist", "artist", artistID, platformName, artistID)
+	if err != nil {
+		return nil, err
+	}
+	var artist platform.Artist
+	if err := decodeJSON(result, &artist); err != nil {
+		return nil, err
+	}
+	return &artist, nil
+}
+
+func (p *scriptPlugin) GetAlbum(ctx context.Context, platformName, albumID string) (*platform.Album, error) {
+	if !p.exports.has("GetAlbum") {
+		return nil, platform.NewUnsupportedError(platformName, "get album")
+	}
+	result, err := p.call(ctx, "GetAlbum", "album", albumID, platformName, albumID)
 	if err != nil {
-		return reflect.Value{}, false
+		return nil, err
 	}
-	return value, value.IsValid()
+	var album platform.Album
+	if err := decodeJSON(result, &album); err != nil {
+		return nil, err
+	}
+	return &album, nil
 }
 
-func (p *scriptPlugin) call(ctx context.Context, fn reflect.Value, resource, id string, args ...interface{}) (interface{}, error) {
-	if !fn.IsValid() {
-		return nil, fmt.Errorf("script function missing")
+// maxScriptRecognitionBytes bounds the audio handed to a script's
+// RecognizeAudio; scripts receive the sample as a byte slice.
+const maxScriptRecognitionBytes = 16 << 20 // 16 MiB
+
+func (p *scriptPlugin) RecognizeAudio(ctx context.Context, platformName string, audioData io.Reader) (*platform.Track, error) {
+	if !p.exports.has("RecognizeAudio") {
+		return nil, platform.NewUnsupportedError(platformName, "audio recognition")
+	}
+	if audioData == nil {
+		return nil, fmt.Errorf("audio data required")
+	}
+	data, err := io.ReadAll(io.LimitReader(audioData, maxScriptRecognitionBytes))
+	if err != nil {
+		return nil, fmt.Errorf("read audio data: %w", err)
+	}
+	result, err := p.call(ctx, "RecognizeAudio", "track", "", platformName, data)
+	if err != nil {
+		return nil, err
+	}
+	var track platform.Track
+	if err := decodeJSON(result, &track); err != nil {
+		return nil, err
 	}
-	inputs := make([]reflect.Value, 0, len(args))
-	for _, arg := range args {
-		inputs = append(inputs, reflect.ValueOf(arg))
+	return &track, nil
+}
+
+func (p *scriptPlugin) ListEpisodes(ctx context.Context, platformName, trackID string) ([]platform.Episode, error) {
+	if !p.exports.has("ListEpisodes") {
+		return nil, platform.NewUnsupportedError(platformName, "episodes")
+	}
+	result, err := p.call(ctx, "ListEpisodes", "track", trackID, platformName, trackID)
+	if err != nil {
+		return nil, err
+	}
+	var episodes []platform.Episode
+	if err := decodeJSON(result, &episodes); err != nil {
+		return nil, err
+	}
+	return episodes, nil
+}
+
+func (p *scriptPlugin) CheckCookie(ctx context.Context, platformName string) (platform.CookieCheckResult, error) {
+	if !p.exports.has("CheckCookie") {
+		return platform.CookieCheckResult{}, platform.NewUnsupportedError(platformName, "cookie check")
+	}
+	result, err := p.call(ctx, "CheckCookie", "account", "", platformName)
+	if err != nil {
+		return platform.CookieCheckResult{}, err
+	}
+	var resp struct {
+		OK      bool   `json:"ok"`
+		Message string `json:"message"`
+	}
+	if err := decodeJSON(result, &resp); err != nil {
+		return platform.CookieCheckResult{}, err
 	}
-	p.mu.Lock()
-	outputs := fn.Call(inputs)
-	p.mu.Unlock()
+	return platform.CookieCheckResult{OK: resp.OK, Message: resp.Message}, nil
+}
+
+type accountStatusPayload struct {
+	LoggedIn        bool       `json:"logged_in"`
+	UserID          string     `json:"user_id"`
+	Nickname        string     `json:"nickname"`
+	Summary         string     `json:"summary"`
+	AuthMode        string     `json:"auth_mode"`
+	SessionSource   string     `json:"session_source"`
+	SupportedLogins []string   `json:"supported_logins"`
+	ExpiresAt       *time.Time `json:"expires_at,omitempty"`
+}
+
+func (p *scriptPlugin) AccountStatus(ctx context.Context, platformName string) (platform.AccountStatus, error) {
+	if !p.exports.has("AccountStatus") {
+		return platform.AccountStatus{}, platform.NewUnsupportedError(platformName, "account status")
+	}
+	result, err := p.call(ctx, "AccountStatus", "account", "", platformName)
+	if err != nil {
+		return platform.AccountStatus{}, err
+	}
+	var payload accountStatusPayload
+	if err := decodeJSON(result, &payload); err != nil {
+		return platform.AccountStatus{}, err
+	}
+	return platform.AccountStatus{
+		Platform:        platformName,
+		LoggedIn:        payload.LoggedIn,
+		UserID:          payload.UserID,
+		Nickname:        payload.Nickname,
+		Summary:         payload.Summary,
+		AuthMode:        payload.AuthMode,
+		SessionSource:   payload.SessionSource,
+		CanCheckCookie:  p.exports.has("CheckCookie"),
+		SupportedLogins: payload.SupportedLogins,
+		ExpiresAt:       payload.ExpiresAt,
+	}, nil
+}
+
+// call runs one exported script function under the plugin's rate limit and
+// per-call deadline. An instance that times out or panics is retired.
+func (p *scriptPlugin) call(ctx context.Context, fnName, resource, id string, args ...interface{}) (interface{}, error) {
+	start := time.Now()
+	if p.limiter != nil && !p.limiter.Allow() {
+		p.stats.record(fnName, 0, scriptCallRejected)
+		plat, _ := firstStringArg(args)
+		return nil, platform.NewRateLimitedError(plat)
+	}
+	if p.limits.CallTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, p.limits.CallTimeout)
+		defer cancel()
+	}
+	inst, err := p.acquire(ctx)
+	if err != nil {
+		p.stats.record(fnName, 0, scriptCallRejected)
+		return nil, err
+	}
+	outputs, err := p.invoke(ctx, inst, fnName, args)
+	if err != nil {
+		if errors.Is(err, errScriptFuncMissing) {
+			p.release(inst)
+			return nil, err
+		}
+		p.retire(inst)
+		result := scriptCallTimeout
+		var panicErr *scriptPanicError
+		if errors.As(err, &panicErr) {
+			result = scriptCallPanic
+		}
+		p.stats.record(fnName, time.Since(start), result)
+		return nil, err
+	}
+	p.release(inst)
 	if len(outputs) == 0 {
+		p.stats.record(fnName, time.Since(start), scriptCallOK)
 		return nil, nil
 	}
 	if len(outputs) == 1 {
+		p.stats.record(fnName, time.Since(start), scriptCallOK)
 		return outputs[0].Interface(), nil
 	}
 	result := outputs[0].Interface()
 	if err := asError(outputs[1]); err != nil {
+		p.stats.record(fnName, time.Since(start), scriptCallError)
 		return nil, mapScriptError(err, resource, id, args)
 	}
+	p.stats.record(fnName, time.Since(start), scriptCallOK)
 	return result, nil
 }
 
+func firstStringArg(args []interface{}) (string, bool) {
+	if len(args) == 0 {
+		return "", false
+	}
+	v, ok := args[0].(string)
+	return v, ok
+}
+
 func asError(value reflect.Value) error {
 	if !value.IsValid() || value.IsNil() {
 		return nil
@@ -236,12 +428,7 @@ func mapScriptError(err error, resource, id string, args []interface{}) error {
 	if coder, ok := err.(interface{ Code() string }); ok {
 		code = strings.ToLower(strings.TrimSpace(coder.Code()))
 	}
-	plat := ""
-	if len(args) > 0 {
-		if v, ok := args[0].(string); ok {
-			plat = v
-		}
-	}
+	plat, _ := firstStringArg(args)
 	if resource == "" {
 		resource = "track"
 	}
@@ -312,19 +499,33 @@ func decodeDownloadInfo(value interface{}, platformName, trackID string) (*platf
 }
 
 type scriptPlatform struct {
-	mu       sync.RWMutex
-	plug     *scriptPlugin
-	info     platformInfo
-	name     string
-	disabled bool
+	mu         sync.RWMutex
+	plug       *scriptPlugin
+	info       platformInfo
+	name       string
+	shortHosts []string
+	disabled   bool
 }
 
 func newScriptPlatform(plugin *scriptPlugin, info platformInfo) *scriptPlatform {
-	return &scriptPlatform{plug: plugin, info: info, name: info.Name}
+	s := &scriptPlatform{}
+	s.update(plugin, info)
+	return s
 }
 
 func (s *scriptPlatform) Name() string { return s.name }
 
+// active returns the backing plugin and platform name, or a nil plugin once the
+// platform was disabled by a reload.
+func (s *scriptPlatform) active() (*scriptPlugin, string) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.disabled {
+		return nil, s.name
+	}
+	return s.plug, s.name
+}
+
 func (s *scriptPlatform) SupportsDownload() bool {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
@@ -370,72 +571,64 @@ func (s *scriptPlatform) Metadata() platform.Meta {
 }
 
 func (s *scriptPlatform) GetDownloadInfo(ctx context.Context, trackID string, quality platform.Quality) (*platform.DownloadInfo, error) {
-	s.mu.RLock()
-	plug := s.plug
-	name := s.name
-	disabled := s.disabled
-	s.mu.RUnlock()
-	if disabled || plug == nil {
+	plug, name := s.active()
+	if plug == nil {
 		return nil, platform.NewUnsupportedError(name, "download")
 	}
 	return plug.GetDownloadInfo(ctx, name, trackID, quality)
 }
 
 func (s *scriptPlatform) Search(ctx context.Context, query string, limit int) ([]platform.Track, error) {
-	s.mu.RLock()
-	plug := s.plug
-	name := s.name
-	disabled := s.disabled
-	s.mu.RUnlock()
-	if disabled || plug == nil {
+	plug, name := s.active()
+	if plug == nil {
 		return nil, platform.NewUnsupportedError(name, "search")
 	}
 	return plug.Search(ctx, name, query, limit)
 }
 
 func (s *scriptPlatform) GetLyrics(ctx context.Context, trackID string) (*platform.Lyrics, error) {
-	s.mu.RLock()
-	plug := s.plug
-	name := s.name
-	disabled := s.disabled
-	s.mu.RUnlock()
-	if disabled || plug == nil {
+	plug, name := s.active()
+	if plug == nil {
 		return nil, platform.NewUnsupportedError(name, "lyrics")
 	}
 	return plug.GetLyrics(ctx, name, trackID)
 }
 
 func (s *scriptPlatform) RecognizeAudio(ctx context.Context, audioData io.Reader) (*platform.Track, error) {
-	return nil, platform.NewUnsupportedError(s.name, "audio recognition")
+	plug, name := s.active()
+	if plug == nil || !s.SupportsRecognition() {
+		return nil, platform.NewUnsupportedError(name, "audio recognition")
+	}
+	return plug.RecognizeAudio(ctx, name, audioData)
 }
 
 func (s *scriptPlatform) GetTrack(ctx context.Context, trackID string) (*platform.Track, error) {
-	s.mu.RLock()
-	plug := s.plug
-	name := s.name
-	disabled := s.disabled
-	s.mu.RUnlock()
-	if disabled || plug == nil {
+	plug, name := s.active()
+	if plug == nil {
 		return nil, platform.NewUnsupportedError(name, "track")
 	}
 	return plug.GetTrack(ctx, name, trackID)
 }
 
 func (s *scriptPlatform) GetArtist(ctx context.Context, artistID string) (*platform.Artist, error) {
-	return nil, platform.NewUnsupportedError(s.name, "get artist")
+	plug, name := s.active()
+	if plug == nil {
+		return nil, platform.NewUnsupportedError(name, "get artist")
+	}
+	return plug.GetArtist(ctx, name, artistID)
 }
 
 func (s *scriptPlatform) GetAlbum(ctx context.Context, albumID string) (*platform.Album, error) {
-	return nil, platform.NewUnsupportedError(s.name, "get album")
+	plug, name := s.active()
+	if plug == nil {
+		return nil, platform.NewUnsupportedError(name, "get album")
+	}
+	return plug.GetAlbum(ctx, name, albumID)
 }
 
 func (s *scriptPlatform) GetPlaylist(ctx context.Context, playlistID string) (*platform.Playlist, error) {
-	s.mu.RLock()
-	plug := s.plug
-	name := s.name
-	disabled := s.disabled
-	s.mu.RUnlock()
-	if disabled || plug == nil {
+	plug, name := s.active()
+	if plug == nil {
 		return nil, platform.NewUnsupportedError(name, "playlist")
 	}
 	return plug.GetPlaylist(ctx, name, playlistID)
@@ -443,12 +636,10 @@ func (s *scriptPlatform) GetPlaylist(ctx context.Context, playlistID string) (*p
 
 func (s *scriptPlatform) MatchURL(rawURL string) (trackID string, matched bool) {
 	s.mu.RLock()
-	plug := s.plug
-	info := s.info
-	name := s.name
-	disabled := s.disabled
+	supported := s.info.SupportsMatchURL
 	s.mu.RUnlock()
-	if disabled || plug == nil || !info.SupportsMatchURL {
+	plug, name := s.active()
+	if plug == nil || !supported {
 		return "", false
 	}
 	return plug.MatchURL(context.Background(), name, rawURL)
@@ -456,22 +647,78 @@ func (s *scriptPlatform) MatchURL(rawURL string) (trackID string, matched bool)
 
 func (s *scriptPlatform) MatchText(text string) (trackID string, matched bool) {
 	s.mu.RLock()
-	plug := s.plug
-	info := s.info
-	name := s.name
-	disabled := s.disabled
+	supported := s.info.SupportsMatchText
 	s.mu.RUnlock()
-	if disabled || plug == nil || !info.SupportsMatchText {
+	plug, name := s.active()
+	if plug == nil || !supported {
 		return "", false
 	}
 	return plug.MatchText(context.Background(), name, text)
 }
 
+// MatchPlaylistURL, MatchArtistURL and ShortLinkHosts are harmless to expose
+// on every script platform: without the script export they simply never match.
+func (s *scriptPlatform) MatchPlaylistURL(rawURL string) (playlistID string, matched bool) {
+	plug, name := s.active()
+	if plug == nil || !plug.exports.has("MatchPlaylistURL") {
+		return "", false
+	}
+	return plug.MatchPlaylistURL(context.Background(), name, rawURL)
+}
+
+func (s *scriptPlatform) MatchArtistURL(rawURL string) (artistID string, matched bool) {
+	plug, name := s.active()
+	if plug == nil || !plug.exports.has("MatchArtistURL") {
+		return "", false
+	}
+	return plug.MatchArtistURL(context.Background(), name, rawURL)
+}
+
+func (s *scriptPlatform) ShortLinkHosts() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.disabled {
+		return nil
+	}
+	return s.shortHosts
+}
+
+func (s *scriptPlatform) listEpisodes(ctx context.Context, trackID string) ([]platform.Episode, error) {
+	plug, name := s.active()
+	if plug == nil {
+		return nil, platform.NewUnsupportedError(name, "episodes")
+	}
+	return plug.ListEpisodes(ctx, name, trackID)
+}
+
+func (s *scriptPlatform) checkCookie(ctx context.Context) (platform.CookieCheckResult, error) {
+	plug, name := s.active()
+	if plug == nil {
+		return platform.CookieCheckResult{}, platform.NewUnsupportedError(name, "cookie check")
+	}
+	return plug.CheckCookie(ctx, name)
+}
+
+func (s *scriptPlatform) accountStatus(ctx context.Context) (platform.AccountStatus, error) {
+	plug, name := s.active()
+	if plug == nil {
+		return platform.AccountStatus{}, platform.NewUnsupportedError(name, "account status")
+	}
+	return plug.AccountStatus(ctx, name)
+}
+
+// update points the platform at a (re)loaded plugin. Short-link hosts are
+// fetched once here because they are consulted for every incoming link.
 func (s *scriptPlatform) update(plug *scriptPlugin, info platformInfo) {
+	var hosts []string
+	if plug != nil && plug.exports.has("ShortLinkHosts") {
+		hosts = plug.ShortLinkHosts(context.Background(), info.Name)
+	}
 	s.mu.Lock()
 	s.plug = plug
 	s.info = info
 	s.name = info.Name
+	s.shortHosts = hosts
 	s.disabled = false
 	s.mu.Unlock()
 }
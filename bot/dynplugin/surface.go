@@ -0,0 +1,175 @@
+package dynplugin
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/liuran001/MusicBot-Go/bot/platform"
+)
+
+// scriptFuncs lists the exported functions a script may implement besides
+// Init and Meta. See plugins/scripts/README.md for their signatures.
+var scriptFuncs = []string{
+	"MatchURL",
+	"MatchText",
+	"Search",
+	"GetTrack",
+	"GetDownloadInfo",
+	"GetLyrics",
+	"GetPlaylist",
+	"GetArtist",
+	"GetAlbum",
+	"RecognizeAudio",
+	"MatchPlaylistURL",
+	"MatchArtistURL",
+	"ShortLinkHosts",
+	"ListEpisodes",
+	"CheckCookie",
+	"AccountStatus",
+	"RunCommand",
+	"GetTagData",
+}
+
+// scriptExports records which of scriptFuncs a loaded script exports.
+type scriptExports map[string]bool
+
+func (e scriptExports) has(name string) bool {
+	return e[name]
+}
+
+func (p *scriptPlugin) detectExports(inst *scriptInstance) scriptExports {
+	exports := make(scriptExports, len(scriptFuncs))
+	for _, name := range scriptFuncs {
+		if _, ok := inst.lookup(p.name, name); ok {
+			exports[name] = true
+		}
+	}
+	return exports
+}
+
+// capabilityExports ties each Meta flag to the export that backs it.
+var capabilityExports = []struct {
+	flag   string
+	export string
+	get    func(info *platformInfo) *bool
+}{
+	{"capabilities.search", "Search", func(info *platformInfo) *bool { return &info.Capabilities.Search }},
+	{"capabilities.download", "GetDownloadInfo", func(info *platformInfo) *bool { return &info.Capabilities.Download }},
+	{"capabilities.lyrics", "GetLyrics", func(info *platformInfo) *bool { return &info.Capabilities.Lyrics }},
+	{"capabilities.recognition", "RecognizeAudio", func(info *platformInfo) *bool { return &info.Capabilities.Recognition }},
+	{"supports_match_url", "MatchURL", func(info *platformInfo) *bool { return &info.SupportsMatchURL }},
+	{"supports_match_text", "MatchText", func(info *platformInfo) *bool { return &info.SupportsMatchText }},
+}
+
+// validatePlatformInfo checks the capability flags a script declares in Meta
+// against the functions it exports. A flag without its function is cleared, so
+// the bot never advertises (or routes to) something the script cannot do; a
+// function without its flag stays unused. Both cases are reported.
+func validatePlatformInfo(info platformInfo, exports scriptExports) (platformInfo, []string) {
+	var problems []string
+	for _, rule := range capabilityExports {
+		flag := rule.get(&info)
+		switch {
+		case *flag && !exports.has(rule.export):
+			*flag = false
+			problems = append(problems, fmt.Sprintf("%s declared but %s is not exported; disabled", rule.flag, rule.export))
+		case !*flag && exports.has(rule.export):
+			problems = append(problems, fmt.Sprintf("%s is exported but %s is not declared; unused", rule.export, rule.flag))
+		}
+	}
+	if !info.Capabilities.Download && (info.Capabilities.HiRes || info.Capabilities.Atmos) {
+		info.Capabilities.HiRes = false
+		info.Capabilities.Atmos = false
+		problems = append(problems, "capabilities.hi_res/atmos require capabilities.download; disabled")
+	}
+	return info, problems
+}
+
+// The optional interfaces below change bot behaviour by their mere presence
+// (an account row in /status, an episode picker that spends rate-limit
+// budget), so they are only attached to a platform whose script exports the
+// matching function. surface composes the registered value accordingly.
+
+type scriptEpisodeLister struct{ p *scriptPlatform }
+
+func (a scriptEpisodeLister) ListEpisodes(ctx context.Context, trackID string) ([]platform.Episode, error) {
+	return a.p.listEpisodes(ctx, trackID)
+}
+
+type scriptCookieChecker struct{ p *scriptPlatform }
+
+func (a scriptCookieChecker) CheckCookie(ctx context.Context) (platform.CookieCheckResult, error) {
+	return a.p.checkCookie(ctx)
+}
+
+type scriptAccountReporter struct{ p *scriptPlatform }
+
+func (a scriptAccountReporter) AccountStatus(ctx context.Context) (platform.AccountStatus, error) {
+	return a.p.accountStatus(ctx)
+}
+
+func (s *scriptPlatform) surface() platform.Platform {
+	s.mu.RLock()
+	var exports scriptExports
+	if s.plug != nil {
+		exports = s.plug.exports
+	}
+	s.mu.RUnlock()
+	episodes := scriptEpisodeLister{s}
+	cookie := scriptCookieChecker{s}
+	account := scriptAccountReporter{s}
+	mask := 0
+	if exports.has("ListEpisodes") {
+		mask |= 1
+	}
+	if exports.has("CheckCookie") {
+		mask |= 2
+	}
+	if exports.has("AccountStatus") {
+		mask |= 4
+	}
+	switch mask {
+	case 1:
+		return struct {
+			*scriptPlatform
+			scriptEpisodeLister
+		}{s, episodes}
+	case 2:
+		return struct {
+			*scriptPlatform
+			scriptCookieChecker
+		}{s, cookie}
+	case 3:
+		return struct {
+			*scriptPlatform
+			scriptEpisodeLister
+			scriptCookieChecker
+		}{s, episodes, cookie}
+	case 4:
+		return struct {
+			*scriptPlatform
+			scriptAccountReporter
+		}{s, account}
+	case 5:
+		return struct {
+			*scriptPlatform
+			scriptEpisodeLister
+			scriptAccountReporter
+		}{s, episodes, account}
+	case 6:
+		return struct {
+			*scriptPlatform
+			scriptCookieChecker
+			scriptAccountReporter
+		}{s, cookie, account}
+	case 7:
+		return struct {
+			*scriptPlatform
+			scriptEpisodeLister
+			scriptCookieChecker
+			scriptAccountReporter
+		}{s, episodes, cookie, account}
+	default:
+		return s
+	}
+}
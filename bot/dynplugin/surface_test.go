@@ -0,0 +1,157 @@
+package dynplugin
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/liuran001/MusicBot-Go/bot/platform"
+	"github.com/traefik/yaegi/interp"
+	"github.com/traefik/yaegi/stdlib"
+)
+
+const demoScript = `package demo
+
+import (
+	"errors"
+	"strings"
+)
+
+func Search(platform, query string, limit int) ([]map[string]interface{}, error) {
+	return nil, nil
+}
+
+func GetArtist(platform, id string) (map[string]interface{}, error) {
+	if id == "missing" {
+		return nil, errors.New("no such artist")
+	}
+	return map[string]interface{}{"id": id, "platform": platform, "name": "Artist " + id}, nil
+}
+
+func RecognizeAudio(platform string, audio []byte) (map[string]interface{}, error) {
+	if len(audio) == 0 {
+		return nil, errors.New("empty sample")
+	}
+	return map[string]interface{}{"id": "r1", "title": string(audio)}, nil
+}
+
+func MatchPlaylistURL(platform, url string) (map[string]interface{}, error) {
+	if !strings.HasPrefix(url, "https://demo.example/list/") {
+		return map[string]interface{}{"matched": false}, nil
+	}
+	return map[string]interface{}{"id": strings.TrimPrefix(url, "https://demo.example/list/"), "matched": true}, nil
+}
+
+func ShortLinkHosts(platform string) []string {
+	return []string{"dm.example"}
+}
+
+func AccountStatus(platform string) (map[string]interface{}, error) {
+	return map[string]interface{}{"logged_in": true, "nickname": "tester", "auth_mode": "cookie"}, nil
+}
+`
+
+func loadScript(t *testing.T, src string, limits scriptLimits) *scriptPlugin {
+	t.Helper()
+	build := func() (*interp.Interpreter, error) {
+		interpreter := interp.New(interp.Options{})
+		if err := interpreter.Use(stdlib.Symbols); err != nil {
+			return nil, err
+		}
+		if _, err := interpreter.Eval(src); err != nil {
+			return nil, err
+		}
+		return interpreter, nil
+	}
+	plug, err := newScriptPlugin(context.Background(), "demo", nil, limits, build, nil)
+	if err != nil {
+		t.Fatalf("load script: %v", err)
+	}
+	t.Cleanup(plug.close)
+	return plug
+}
+
+func loadDemoPlugin(t *testing.T) *scriptPlugin {
+	return loadScript(t, demoScript, scriptLimits{PoolSize: 1})
+}
+
+func TestValidatePlatformInfoClearsUnbackedFlags(t *testing.T) {
+	exports := scriptExports{"Search": true, "RecognizeAudio": true}
+	info := platformInfo{
+		Name:             "demo",
+		Capabilities:     platform.Capabilities{Search: true, Download: true, HiRes: true},
+		SupportsMatchURL: true,
+	}
+	got, problems := validatePlatformInfo(info, exports)
+	if !got.Capabilities.Search || got.Capabilities.Download || got.Capabilities.HiRes || got.SupportsMatchURL {
+		t.Fatalf("validated info = %+v", got)
+	}
+	if got.Capabilities.Recognition {
+		t.Fatalf("undeclared recognition must stay off")
+	}
+	// download, match_url, the undeclared RecognizeAudio export, hi_res.
+	if len(problems) != 4 {
+		t.Fatalf("problems = %q", problems)
+	}
+}
+
+func TestScriptPlatformSurfaceFollowsExports(t *testing.T) {
+	plug := loadDemoPlugin(t)
+	for _, name := range []string{"Search", "GetArtist", "RecognizeAudio", "MatchPlaylistURL", "ShortLinkHosts", "AccountStatus"} {
+		if !plug.exports.has(name) {
+			t.Fatalf("export %s not detected: %v", name, plug.exports)
+		}
+	}
+	if plug.exports.has("ListEpisodes") || plug.exports.has("CheckCookie") {
+		t.Fatalf("unexpected exports: %v", plug.exports)
+	}
+
+	info, _ := validatePlatformInfo(platformInfo{Name: "demo", Capabilities: platform.Capabilities{Search: true, Recognition: true}}, plug.exports)
+	plat := newScriptPlatform(plug, info)
+	registered := plat.surface()
+	if _, ok := registered.(platform.AccountStatusProvider); !ok {
+		t.Fatalf("AccountStatus export should expose AccountStatusProvider")
+	}
+	if _, ok := registered.(platform.EpisodeProvider); ok {
+		t.Fatalf("EpisodeProvider must not be exposed without ListEpisodes")
+	}
+	if _, ok := registered.(platform.CookieChecker); ok {
+		t.Fatalf("CookieChecker must not be exposed without CheckCookie")
+	}
+
+	ctx := context.Background()
+	artist, err := registered.GetArtist(ctx, "7")
+	if err != nil || artist.Name != "Artist 7" || artist.Platform != "demo" {
+		t.Fatalf("GetArtist = %+v, %v", artist, err)
+	}
+	if _, err := registered.GetArtist(ctx, "missing"); err == nil || !strings.Contains(err.Error(), "no such artist") {
+		t.Fatalf("script error should surface, got %v", err)
+	}
+	if _, err := registered.GetAlbum(ctx, "1"); !errors.Is(err, platform.ErrUnsupported) {
+		t.Fatalf("GetAlbum without export should be unsupported, got %v", err)
+	}
+	track, err := registered.RecognizeAudio(ctx, bytes.NewReader([]byte("sample")))
+	if err != nil || track.Title != "sample" {
+		t.Fatalf("RecognizeAudio = %+v, %v", track, err)
+	}
+	if id, ok := registered.(platform.PlaylistURLMatcher).MatchPlaylistURL("https://demo.example/list/42"); !ok || id != "42" {
+		t.Fatalf("MatchPlaylistURL = %q, %v", id, ok)
+	}
+	if _, ok := registered.(platform.ArtistURLMatcher).MatchArtistURL("https://demo.example/artist/1"); ok {
+		t.Fatalf("MatchArtistURL without export must not match")
+	}
+	if hosts := registered.(platform.ShortLinkProvider).ShortLinkHosts(); strings.Join(hosts, ",") != "dm.example" {
+		t.Fatalf("ShortLinkHosts = %v", hosts)
+	}
+	status, err := registered.(platform.AccountStatusProvider).AccountStatus(ctx)
+	if err != nil || !status.LoggedIn || status.Nickname != "tester" || status.Platform != "demo" || status.CanCheckCookie {
+		t.Fatalf("AccountStatus = %+v, %v", status, err)
+	}
+
+	plat.disable()
+	if _, err := registered.GetArtist(ctx, "7"); !errors.Is(err, platform.ErrUnsupported) {
+		t.Fatalf("disabled platform should be unsupported, got %v", err)
+	}
+}
@@ -0,0 +1,203 @@
+// Package filelink serves prepared files over short-lived HTTP links, used
+// when a track is too large to upload to Telegram.
+package filelink
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+type entry struct {
+	path    string
+	name    string
+	expires time.Time
+}
+
+// Server publishes files under /f/<token>/<name> until they expire. Published
+// files are hard-linked (or copied) into its own directory so the caller may
+// clean up the original right away.
+type Server struct {
+	baseURL string
+	dir     string
+	ttl     time.Duration
+
+	mu      sync.Mutex
+	entries map[string]entry
+	now     func() time.Time
+
+	srv      *http.Server
+	listener net.Listener
+}
+
+// New creates a server storing published files in dir. baseURL is the
+// externally reachable prefix links are built from.
+func New(baseURL, dir string, ttl time.Duration) (*Server, error) {
+	base := strings.TrimRight(strings.TrimSpace(baseURL), "/")
+	if base == "" {
+		return nil, errors.New("filelink: base URL is required")
+	}
+	if ttl <= 0 {
+		return nil, errors.New("filelink: ttl must be positive")
+	}
+	if err := os.RemoveAll(dir); err != nil {
+		return nil, fmt.Errorf("filelink: reset dir: %w", err)
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("filelink: create dir: %w", err)
+	}
+	return &Server{baseURL: base, dir: dir, ttl: ttl, entries: make(map[string]entry), now: time.Now}, nil
+}
+
+// TTL returns how long a published link stays valid.
+func (s *Server) TTL() time.Duration {
+	return s.ttl
+}
+
+// Start listens on addr and serves links until ctx is done or Close is
+// called. Expired files are swept once a minute.
+func (s *Server) Start(ctx context.Context, addr string) error {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("filelink: listen %s: %w", addr, err)
+	}
+	s.listener = listener
+	s.srv = &http.Server{Handler: s, ReadHeaderTimeout: 10 * time.Second}
+	go func() { _ = s.srv.Serve(listener) }()
+	go func() {
+		ticker := time.NewTicker(time.Minute)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				s.sweep()
+			}
+		}
+	}()
+	return nil
+}
+
+// Close stops the listener and removes every published file.
+func (s *Server) Close() error {
+	var err error
+	if s.srv != nil {
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		err = s.srv.Shutdown(shutdownCtx)
+		cancel()
+	}
+	s.mu.Lock()
+	s.entries = make(map[string]entry)
+	s.mu.Unlock()
+	_ = os.RemoveAll(s.dir)
+	return err
+}
+
+// Publish makes path downloadable as name and returns the link and its
+// expiry time.
+func (s *Server) Publish(path, name string) (string, time.Time, error) {
+	token, err := newToken()
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	name = filepath.Base(strings.TrimSpace(name))
+	if name == "" || name == "." || name == string(filepath.Separator) {
+		name = filepath.Base(path)
+	}
+	stored := filepath.Join(s.dir, token+filepath.Ext(name))
+	if err := linkOrCopy(path, stored); err != nil {
+		return "", time.Time{}, fmt.Errorf("filelink: store %s: %w", path, err)
+	}
+	expires := s.now().Add(s.ttl)
+	s.mu.Lock()
+	s.entries[token] = entry{path: stored, name: name, expires: expires}
+	s.mu.Unlock()
+	return s.baseURL + "/f/" + token + "/" + url.PathEscape(name), expires, nil
+}
+
+// ServeHTTP serves a published file with Range support.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	rest, ok := strings.CutPrefix(r.URL.Path, "/f/")
+	token, _, _ := strings.Cut(rest, "/")
+	s.mu.Lock()
+	item, found := s.entries[token]
+	s.mu.Unlock()
+	if !ok || !found || !s.now().Before(item.expires) {
+		http.NotFound(w, r)
+		return
+	}
+	file, err := os.Open(item.path)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	defer file.Close()
+	stat, err := file.Stat()
+	if err != nil {
+		http.Error(w, "unavailable", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Disposition", "attachment; filename*=UTF-8''"+url.PathEscape(item.name))
+	http.ServeContent(w, r, item.name, stat.ModTime(), file)
+}
+
+func (s *Server) sweep() {
+	now := s.now()
+	s.mu.Lock()
+	expired := make([]string, 0)
+	for token, item := range s.entries {
+		if !now.Before(item.expires) {
+			expired = append(expired, item.path)
+			delete(s.entries, token)
+		}
+	}
+	s.mu.Unlock()
+	for _, path := range expired {
+		_ = os.Remove(path)
+	}
+}
+
+func newToken() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("filelink: token: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+func linkOrCopy(src, dst string) error {
+	if err := os.Link(src, dst); err == nil {
+		return nil
+	}
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		_ = os.Remove(dst)
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,68 @@
+package filelink
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPublishServesUntilExpiry(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "song.flac")
+	if err := os.WriteFile(src, []byte("0123456789"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	server, err := New("https://bot.example.com/dl/", filepath.Join(dir, "links"), time.Hour)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	server.now = func() time.Time { return now }
+
+	link, expires, err := server.Publish(src, "Artist - Song.flac")
+	if err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+	if !strings.HasPrefix(link, "https://bot.example.com/dl/f/") || !expires.Equal(now.Add(time.Hour)) {
+		t.Fatalf("link = %q expires = %v", link, expires)
+	}
+	// The published copy must survive removal of the prepared file.
+	if err := os.Remove(src); err != nil {
+		t.Fatal(err)
+	}
+	path := strings.TrimPrefix(link, "https://bot.example.com/dl")
+
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	req.Header.Set("Range", "bytes=2-5")
+	rec := httptest.NewRecorder()
+	server.ServeHTTP(rec, req)
+	body, _ := io.ReadAll(rec.Body)
+	if rec.Code != http.StatusPartialContent || string(body) != "2345" {
+		t.Fatalf("range response = %d %q", rec.Code, body)
+	}
+	if cd := rec.Header().Get("Content-Disposition"); !strings.Contains(cd, "Artist%20-%20Song.flac") {
+		t.Fatalf("Content-Disposition = %q", cd)
+	}
+
+	rec = httptest.NewRecorder()
+	server.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/f/unknown/x.flac", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("unknown token = %d", rec.Code)
+	}
+
+	now = now.Add(2 * time.Hour)
+	rec = httptest.NewRecorder()
+	server.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("expired link = %d", rec.Code)
+	}
+	server.sweep()
+	if entries, _ := os.ReadDir(filepath.Join(dir, "links")); len(entries) != 0 {
+		t.Fatalf("sweep left %d files", len(entries))
+	}
+}
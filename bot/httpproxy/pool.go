@@ -0,0 +1,498 @@
+package httpproxy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultProbeURL      = "https://www.gstatic.com/generate_204"
+	DefaultProbeInterval = time.Minute
+	DefaultPoolCooldown  = 2 * time.Minute
+	// unknownLatency weights members that have not been measured yet.
+	unknownLatency = 500 * time.Millisecond
+	// latencySmoothing is the weight of a new sample in the moving average.
+	latencySmoothing = 0.3
+)
+
+// PoolSpec declares a named proxy pool, read from a [proxy_pool.<name>]
+// section.
+type PoolSpec struct {
+	Name    string
+	Proxies []Config
+	// Platforms routes these platforms through the pool unless their plugin
+	// section picks another one.
+	Platforms     []string
+	ProbeURL      string
+	ProbeInterval time.Duration
+	Cooldown      time.Duration
+	Timeout       time.Duration
+}
+
+// ParseProxyURL parses one pool entry, "scheme://[auth@]host:port" with
+// scheme http, https or baidu. A missing scheme means http.
+func ParseProxyURL(raw string) (Config, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return Config{}, errors.New("empty proxy")
+	}
+	if !strings.Contains(raw, "://") {
+		raw = "http://" + raw
+	}
+	parsed, err := url.Parse(raw)
+	if err != nil {
+		return Config{}, err
+	}
+	proxyType := normalizeType(parsed.Scheme)
+	switch proxyType {
+	case "http", "https", "baidu":
+	default:
+		return Config{}, fmt.Errorf("unsupported proxy type %q", parsed.Scheme)
+	}
+	port, _ := strconv.Atoi(parsed.Port())
+	if parsed.Hostname() == "" || port <= 0 {
+		return Config{}, fmt.Errorf("proxy %s: host and port required", parsed.Host)
+	}
+	cfg := Config{Enabled: true, Type: proxyType, Host: parsed.Hostname(), Port: port}
+	if parsed.User != nil {
+		cfg.Auth = parsed.User.Username()
+		if password, ok := parsed.User.Password(); ok {
+			cfg.Auth += ":" + password
+		}
+	}
+	return cfg.Normalized(), nil
+}
+
+type poolMember struct {
+	cfg       Config
+	transport http.RoundTripper
+	latency   time.Duration
+	downUntil time.Time
+	requests  int64
+	failures  int64
+	lastError string
+	lastProbe time.Time
+}
+
+// MemberStatus is a snapshot of one proxy of a pool, without credentials.
+type MemberStatus struct {
+	Proxy     string
+	Healthy   bool
+	Latency   time.Duration
+	DownUntil time.Time
+	Requests  int64
+	Failures  int64
+	LastError string
+	LastProbe time.Time
+}
+
+// PoolStatus is a snapshot of a pool for the /proxy admin command.
+type PoolStatus struct {
+	Name      string
+	Platforms []string
+	Members   []MemberStatus
+}
+
+// Pool spreads requests over several proxies. Healthy members are picked at
+// random weighted by inverse latency; a member failing with a transport
+// error or HTTP 407, or refused with HTTP 403 or 451 where another member is
+// not, is taken out for the cooldown and the request is retried on another
+// member when it can be replayed. Background probes keep latencies fresh and
+// bring members back.
+type Pool struct {
+	spec PoolSpec
+	now  func() time.Time
+
+	mu      sync.Mutex
+	members []*poolMember
+	rng     *rand.Rand
+
+	stop chan struct{}
+	once sync.Once
+}
+
+// NewPool builds a pool from spec. Probes start with Start.
+func NewPool(spec PoolSpec) (*Pool, error) {
+	spec.Name = strings.ToLower(strings.TrimSpace(spec.Name))
+	if spec.Name == "" {
+		return nil, errors.New("proxy pool name required")
+	}
+	if len(spec.Proxies) == 0 {
+		return nil, fmt.Errorf("proxy pool %s: no proxies", spec.Name)
+	}
+	if spec.ProbeURL == "" {
+		spec.ProbeURL = DefaultProbeURL
+	}
+	if spec.Cooldown <= 0 {
+		spec.Cooldown = DefaultPoolCooldown
+	}
+	if spec.Timeout <= 0 {
+		spec.Timeout = DefaultTimeout
+	}
+	pool := &Pool{
+		spec: spec,
+		now:  time.Now,
+		rng:  rand.New(rand.NewSource(time.Now().UnixNano())),
+		stop: make(chan struct{}),
+	}
+	for _, cfg := range spec.Proxies {
+		cfg = cfg.Normalized()
+		cfg.Enabled = true
+		transport, err := newProxyTransport(cfg, spec.Timeout)
+		if err != nil {
+			return nil, fmt.Errorf("proxy pool %s: %w", spec.Name, err)
+		}
+		pool.members = append(pool.members, &poolMember{cfg: cfg, transport: transport})
+	}
+	return pool, nil
+}
+
+// Name returns the pool name.
+func (p *Pool) Name() string { return p.spec.Name }
+
+// Platforms returns the platforms routed to the pool by its own section.
+func (p *Pool) Platforms() []string { return p.spec.Platforms }
+
+// Start probes every member now and then every ProbeInterval until Close.
+// A negative interval disables probing.
+func (p *Pool) Start() {
+	interval := p.spec.ProbeInterval
+	if interval < 0 {
+		return
+	}
+	if interval == 0 {
+		interval = DefaultProbeInterval
+	}
+	go func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go func() {
+			<-p.stop
+			cancel()
+		}()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			p.Probe(ctx)
+			select {
+			case <-p.stop:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Close stops the probes.
+func (p *Pool) Close() {
+	p.once.Do(func() { close(p.stop) })
+}
+
+// Probe checks every member concurrently against the probe URL.
+func (p *Pool) Probe(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, m := range p.members {
+		wg.Add(1)
+		go func(m *poolMember) {
+			defer wg.Done()
+			p.probe(ctx, m)
+		}(m)
+	}
+	wg.Wait()
+}
+
+func (p *Pool) probe(ctx context.Context, m *poolMember) {
+	ctx, cancel := context.WithTimeout(ctx, minDuration(p.spec.Timeout, 10*time.Second))
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.spec.ProbeURL, nil)
+	if err != nil {
+		return
+	}
+	start := p.now()
+	resp, err := m.transport.RoundTrip(req)
+	if err == nil {
+		drainAndClose(resp.Body)
+		if resp.StatusCode >= http.StatusInternalServerError || isBlockedStatus(resp.StatusCode) || isProxyStatus(resp.StatusCode) {
+			err = fmt.Errorf("probe http %d", resp.StatusCode)
+		}
+	}
+	if err != nil && errors.Is(err, context.Canceled) {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	m.lastProbe = p.now()
+	if err != nil {
+		m.downUntil = m.lastProbe.Add(p.spec.Cooldown)
+		m.lastError = err.Error()
+		return
+	}
+	m.downUntil = time.Time{}
+	m.observeLatency(m.lastProbe.Sub(start))
+}
+
+func (m *poolMember) observeLatency(sample time.Duration) {
+	if sample <= 0 {
+		sample = time.Millisecond
+	}
+	if m.latency == 0 {
+		m.latency = sample
+		return
+	}
+	m.latency = time.Duration(latencySmoothing*float64(sample) + (1-latencySmoothing)*float64(m.latency))
+}
+
+// pick returns a member not yet tried: a healthy one chosen by inverse
+// latency weight, or, when all are down, the one back soonest.
+func (p *Pool) pick(tried map[*poolMember]bool) *poolMember {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	now := p.now()
+	healthy := make([]*poolMember, 0, len(p.members))
+	weights := make([]float64, 0, len(p.members))
+	total := 0.0
+	var fallback *poolMember
+	for _, m := range p.members {
+		if tried[m] {
+			continue
+		}
+		if now.Before(m.downUntil) {
+			if fallback == nil || m.downUntil.Before(fallback.downUntil) {
+				fallback = m
+			}
+			continue
+		}
+		latency := m.latency
+		if latency <= 0 {
+			latency = unknownLatency
+		}
+		weight := 1 / latency.Seconds()
+		healthy = append(healthy, m)
+		weights = append(weights, weight)
+		total += weight
+	}
+	if len(healthy) == 0 {
+		return fallback
+	}
+	target := p.rng.Float64() * total
+	for i, weight := range weights {
+		target -= weight
+		if target <= 0 {
+			return healthy[i]
+		}
+	}
+	return healthy[len(healthy)-1]
+}
+
+func (p *Pool) markFailure(m *poolMember, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	m.requests++
+	m.failures++
+	m.downUntil = p.now().Add(p.spec.Cooldown)
+	m.lastError = err.Error()
+}
+
+func (p *Pool) markSuccess(m *poolMember, latency time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	m.requests++
+	m.downUntil = time.Time{}
+	m.observeLatency(latency)
+}
+
+// isBlockedStatus reports responses that may mean the proxy's exit IP is
+// refused. The origin sends the same codes for its own reasons, e.g. an
+// expired signed URL, so RoundTrip only blames the proxy once another member
+// gets through.
+func isBlockedStatus(code int) bool {
+	return code == http.StatusForbidden || code == http.StatusUnavailableForLegalReasons
+}
+
+// isProxyStatus reports responses that come from the proxy itself.
+func isProxyStatus(code int) bool {
+	return code == http.StatusProxyAuthRequired
+}
+
+// RoundTrip implements http.RoundTripper with failover across members.
+// Transport errors and proxy statuses take the member out and move on. A
+// blocked status is checked against one other member: the first member is
+// taken out only if the other one gets a different answer, so an origin that
+// refuses every exit IP does not empty the pool.
+func (p *Pool) RoundTrip(req *http.Request) (*http.Response, error) {
+	replayable := isRetryableRequest(req)
+	tried := make(map[*poolMember]bool, len(p.members))
+	var (
+		lastErr   error
+		blocked   *http.Response
+		blockedBy *poolMember
+	)
+	for attempt := 0; attempt < len(p.members); attempt++ {
+		m := p.pick(tried)
+		if m == nil {
+			break
+		}
+		tried[m] = true
+		attemptReq := req
+		if attempt > 0 {
+			attemptReq = req.Clone(req.Context())
+			if req.Body != nil && req.Body != http.NoBody {
+				body, err := req.GetBody()
+				if err != nil {
+					closeResponse(blocked)
+					return nil, err
+				}
+				attemptReq.Body = body
+			}
+		}
+		start := p.now()
+		resp, err := m.transport.RoundTrip(attemptReq)
+		if err != nil {
+			if req.Context().Err() != nil {
+				closeResponse(blocked)
+				return nil, err
+			}
+			p.markFailure(m, err)
+			lastErr = err
+			if !replayable {
+				return nil, err
+			}
+			continue
+		}
+		if isProxyStatus(resp.StatusCode) {
+			p.markFailure(m, fmt.Errorf("http %d", resp.StatusCode))
+			if replayable && len(tried) < len(p.members) {
+				drainAndClose(resp.Body)
+				continue
+			}
+			if blocked != nil {
+				drainAndClose(resp.Body)
+				return blocked, nil
+			}
+			return resp, nil
+		}
+		if isBlockedStatus(resp.StatusCode) {
+			if blocked != nil {
+				// A second member got the same refusal: it is the origin's.
+				drainAndClose(blocked.Body)
+				p.markSuccess(m, p.now().Sub(start))
+				return resp, nil
+			}
+			if replayable && len(tried) < len(p.members) {
+				blocked, blockedBy = resp, m
+				continue
+			}
+			p.markSuccess(m, p.now().Sub(start))
+			return resp, nil
+		}
+		if blocked != nil {
+			drainAndClose(blocked.Body)
+			p.markFailure(blockedBy, fmt.Errorf("http %d", blocked.StatusCode))
+		}
+		p.markSuccess(m, p.now().Sub(start))
+		return resp, nil
+	}
+	if blocked != nil {
+		return blocked, nil
+	}
+	if lastErr == nil {
+		lastErr = fmt.Errorf("proxy pool %s: no proxy available", p.spec.Name)
+	}
+	return nil, lastErr
+}
+
+func closeResponse(resp *http.Response) {
+	if resp != nil {
+		drainAndClose(resp.Body)
+	}
+}
+
+// Status returns a snapshot of the pool.
+func (p *Pool) Status() PoolStatus {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	now := p.now()
+	status := PoolStatus{Name: p.spec.Name, Platforms: append([]string(nil), p.spec.Platforms...)}
+	for _, m := range p.members {
+		status.Members = append(status.Members, MemberStatus{
+			Proxy:     m.cfg.Type + "://" + m.cfg.Address(),
+			Healthy:   !now.Before(m.downUntil),
+			Latency:   m.latency,
+			DownUntil: m.downUntil,
+			Requests:  m.requests,
+			Failures:  m.failures,
+			LastError: m.lastError,
+			LastProbe: m.lastProbe,
+		})
+	}
+	return status
+}
+
+var (
+	poolsMu sync.RWMutex
+	pools   = map[string]*Pool{}
+)
+
+// SetPools replaces the process-wide pool registry and closes the pools it
+// held. Clients built from a Config with Pool set look the pool up per
+// request, so they follow a reload without being rebuilt.
+func SetPools(list []*Pool) {
+	next := make(map[string]*Pool, len(list))
+	for _, pool := range list {
+		if pool != nil {
+			next[pool.Name()] = pool
+		}
+	}
+	poolsMu.Lock()
+	previous := pools
+	pools = next
+	poolsMu.Unlock()
+	for _, pool := range previous {
+		pool.Close()
+	}
+}
+
+// LookupPool returns the registered pool with the given name.
+func LookupPool(name string) (*Pool, bool) {
+	poolsMu.RLock()
+	defer poolsMu.RUnlock()
+	pool, ok := pools[strings.ToLower(strings.TrimSpace(name))]
+	return pool, ok
+}
+
+// Pools returns the registered pools sorted by name.
+func Pools() []*Pool {
+	poolsMu.RLock()
+	list := make([]*Pool, 0, len(pools))
+	for _, pool := range pools {
+		list = append(list, pool)
+	}
+	poolsMu.RUnlock()
+	sort.Slice(list, func(i, j int) bool { return list[i].Name() < list[j].Name() })
+	return list
+}
+
+// PoolTransport routes requests through the registered pool of that name.
+type PoolTransport string
+
+// RoundTrip implements http.RoundTripper.
+func (name PoolTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	pool, ok := LookupPool(string(name))
+	if !ok {
+		if req.Body != nil {
+			_ = req.Body.Close()
+		}
+		return nil, &net.OpError{Op: "proxy", Net: "tcp", Err: fmt.Errorf("proxy pool %q not configured", string(name))}
+	}
+	return pool.RoundTrip(req)
+}
@@ -0,0 +1,206 @@
+package httpproxy
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func newTestPool(t *testing.T, transports ...http.RoundTripper) *Pool {
+	t.Helper()
+	spec := PoolSpec{Name: "Test", Cooldown: time.Minute}
+	for range transports {
+		spec.Proxies = append(spec.Proxies, Config{Type: "http", Host: "127.0.0.1", Port: 1})
+	}
+	pool, err := NewPool(spec)
+	if err != nil {
+		t.Fatalf("NewPool() error = %v", err)
+	}
+	for i, transport := range transports {
+		pool.members[i].transport = transport
+	}
+	return pool
+}
+
+func TestParseProxyURL(t *testing.T) {
+	cfg, err := ParseProxyURL("baidu://token@10.0.0.1:443")
+	if err != nil {
+		t.Fatalf("ParseProxyURL() error = %v", err)
+	}
+	if cfg.Type != "baidu" || cfg.Host != "10.0.0.1" || cfg.Port != 443 || cfg.Auth != "token" {
+		t.Fatalf("unexpected config: %+v", cfg)
+	}
+	if cfg, _ := ParseProxyURL("user:pass@proxy.local:8080"); cfg.Type != "http" || cfg.Auth != "user:pass" {
+		t.Fatalf("bare entry should default to http with auth: %+v", cfg)
+	}
+	for _, raw := range []string{"socks5://h:1", "http://nohost", ""} {
+		if _, err := ParseProxyURL(raw); err == nil {
+			t.Fatalf("ParseProxyURL(%q) should fail", raw)
+		}
+	}
+}
+
+func TestPoolFailsOverOnBlockedStatusAndTransportError(t *testing.T) {
+	var blockedCalls, brokenCalls, okCalls int
+	blocked := roundTripFunc(func(*http.Request) (*http.Response, error) {
+		blockedCalls++
+		return response(http.StatusUnavailableForLegalReasons), nil
+	})
+	broken := roundTripFunc(func(*http.Request) (*http.Response, error) {
+		brokenCalls++
+		return nil, errors.New("connection refused")
+	})
+	ok := roundTripFunc(func(*http.Request) (*http.Response, error) {
+		okCalls++
+		return response(http.StatusOK), nil
+	})
+	pool := newTestPool(t, blocked, broken, ok)
+
+	for i := 0; i < 5; i++ {
+		req, _ := http.NewRequest(http.MethodGet, "https://example.com/", nil)
+		resp, err := pool.RoundTrip(req)
+		if err != nil || resp.StatusCode != http.StatusOK {
+			t.Fatalf("request %d: resp %v err %v", i, resp, err)
+		}
+		resp.Body.Close()
+	}
+	if blockedCalls > 1 || brokenCalls > 1 || okCalls != 5 {
+		t.Fatalf("failed members should sit out the cooldown: blocked=%d broken=%d ok=%d", blockedCalls, brokenCalls, okCalls)
+	}
+	status := pool.Status()
+	if status.Name != "test" || len(status.Members) != 3 {
+		t.Fatalf("unexpected status: %+v", status)
+	}
+	for i, member := range status.Members {
+		failed := i < 2 && member.Requests > 0
+		if failed == member.Healthy && member.Requests > 0 {
+			t.Fatalf("member %d health = %v: %+v", i, member.Healthy, member)
+		}
+	}
+}
+
+func TestPoolKeepsMembersWhenOriginRefusesEveryone(t *testing.T) {
+	calls := 0
+	forbidden := roundTripFunc(func(*http.Request) (*http.Response, error) {
+		calls++
+		return response(http.StatusForbidden), nil
+	})
+	pool := newTestPool(t, forbidden, forbidden, forbidden)
+
+	req, _ := http.NewRequest(http.MethodGet, "https://cdn.example.com/expired", nil)
+	resp, err := pool.RoundTrip(req)
+	if err != nil || resp.StatusCode != http.StatusForbidden {
+		t.Fatalf("origin 403 should be returned: resp %v err %v", resp, err)
+	}
+	resp.Body.Close()
+	if calls != 2 {
+		t.Fatalf("origin 403 tried on %d members, want 2", calls)
+	}
+	for i, member := range pool.Status().Members {
+		if !member.Healthy || member.Failures != 0 {
+			t.Fatalf("member %d quarantined by an origin 403: %+v", i, member)
+		}
+	}
+}
+
+func TestPoolFailsOverOnProxyAuthStatus(t *testing.T) {
+	pool := newTestPool(t,
+		roundTripFunc(func(*http.Request) (*http.Response, error) {
+			return response(http.StatusProxyAuthRequired), nil
+		}),
+		roundTripFunc(func(*http.Request) (*http.Response, error) { return response(http.StatusOK), nil }),
+	)
+	pool.members[0].latency = time.Millisecond
+	pool.members[1].latency = time.Hour
+	for i := 0; i < 3; i++ {
+		req, _ := http.NewRequest(http.MethodGet, "https://example.com/", nil)
+		resp, err := pool.RoundTrip(req)
+		if err != nil || resp.StatusCode != http.StatusOK {
+			t.Fatalf("request %d: resp %v err %v", i, resp, err)
+		}
+		resp.Body.Close()
+	}
+	if status := pool.Status().Members[0]; status.Healthy || status.Failures != 1 {
+		t.Fatalf("407 should take the proxy out once: %+v", status)
+	}
+}
+
+func TestPoolDoesNotReplayNonIdempotentRequests(t *testing.T) {
+	calls := 0
+	broken := roundTripFunc(func(*http.Request) (*http.Response, error) {
+		calls++
+		return nil, errors.New("reset")
+	})
+	pool := newTestPool(t, broken, broken)
+	req, _ := http.NewRequest(http.MethodPost, "https://example.com/", http.NoBody)
+	req.Body = nil
+	req.Method = http.MethodPost
+	if _, err := pool.RoundTrip(req); err == nil || calls != 1 {
+		t.Fatalf("POST should not be retried: err=%v calls=%d", err, calls)
+	}
+}
+
+func TestPoolPrefersLowLatency(t *testing.T) {
+	counts := make([]int, 2)
+	pool := newTestPool(t,
+		roundTripFunc(func(*http.Request) (*http.Response, error) { counts[0]++; return response(http.StatusOK), nil }),
+		roundTripFunc(func(*http.Request) (*http.Response, error) { counts[1]++; return response(http.StatusOK), nil }),
+	)
+	pool.members[0].latency = 10 * time.Millisecond
+	pool.members[1].latency = time.Second
+	for i := 0; i < 200; i++ {
+		m := pool.pick(nil)
+		if m == pool.members[0] {
+			counts[0]++
+		} else {
+			counts[1]++
+		}
+	}
+	if counts[0] < 150 {
+		t.Fatalf("fast proxy picked %d/200 times, want a clear majority", counts[0])
+	}
+}
+
+func TestPoolProbeMarksHealth(t *testing.T) {
+	healthy := true
+	pool := newTestPool(t, roundTripFunc(func(*http.Request) (*http.Response, error) {
+		if !healthy {
+			return nil, errors.New("timeout")
+		}
+		return response(http.StatusNoContent), nil
+	}))
+	pool.Probe(context.Background())
+	if status := pool.Status().Members[0]; !status.Healthy || status.Latency <= 0 || status.LastProbe.IsZero() {
+		t.Fatalf("probe should record latency: %+v", status)
+	}
+	healthy = false
+	pool.Probe(context.Background())
+	if status := pool.Status().Members[0]; status.Healthy || status.LastError == "" {
+		t.Fatalf("failed probe should take the proxy out: %+v", status)
+	}
+}
+
+func TestPoolTransportUsesRegistry(t *testing.T) {
+	pool := newTestPool(t, roundTripFunc(func(*http.Request) (*http.Response, error) {
+		return response(http.StatusOK), nil
+	}))
+	SetPools([]*Pool{pool})
+	defer SetPools(nil)
+
+	client, err := NewHTTPClient(Config{Pool: "TEST"}, time.Second)
+	if err != nil || client == nil {
+		t.Fatalf("NewHTTPClient() = %v, %v", client, err)
+	}
+	resp, err := client.Get("http://example.com/")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	resp.Body.Close()
+
+	SetPools(nil)
+	if _, err := client.Get("http://example.com/"); err == nil {
+		t.Fatal("a removed pool should fail requests instead of going direct")
+	}
+}
@@ -31,6 +31,9 @@ type Config struct {
 	Port    int
 	Auth    string
 	Headers map[string]string
+	// Pool names a registered proxy pool. When set it replaces the single
+	// proxy above.
+	Pool string
 }
 
 func (cfg Config) Normalized() Config {
@@ -52,11 +55,15 @@ func (cfg Config) Normalized() Config {
 		Port:    port,
 		Auth:    strings.TrimSpace(cfg.Auth),
 		Headers: headers,
+		Pool:    strings.ToLower(strings.TrimSpace(cfg.Pool)),
 	}
 }
 
 func (cfg Config) EnabledAndReady() bool {
 	normalized := cfg.Normalized()
+	if normalized.Pool != "" {
+		return true
+	}
 	return normalized.Enabled && strings.TrimSpace(normalized.Host) != "" && normalized.Port > 0
 }
 
@@ -129,17 +136,28 @@ func NewHTTPClient(cfg Config, timeout time.Duration) (*http.Client, error) {
 	if timeout <= 0 {
 		timeout = DefaultTimeout
 	}
+	if normalized.Pool != "" {
+		return &http.Client{Transport: PoolTransport(normalized.Pool), Timeout: timeout}, nil
+	}
+	transport, err := newProxyTransport(normalized, timeout)
+	if err != nil {
+		return nil, err
+	}
+	return &http.Client{Transport: transport, Timeout: timeout}, nil
+}
+
+// newProxyTransport builds the transport for a single normalized proxy.
+func newProxyTransport(normalized Config, timeout time.Duration) (http.RoundTripper, error) {
 	switch normalized.Type {
 	case "baidu":
 		transport := NewBaseTransport(timeout)
 		transport.DialContext = newBaiduConnectDialContext(normalized, timeout)
-		retryTransport := &baiduRetryTransport{
+		return &baiduRetryTransport{
 			base:           transport,
 			maxAttempts:    defaultBaiduRetryAttempts,
 			attemptTimeout: timeout / defaultBaiduRetryAttempts,
 			backoff:        defaultBaiduRetryBackoff,
-		}
-		return &http.Client{Transport: retryTransport, Timeout: timeout}, nil
+		}, nil
 	case "http", "https":
 		proxyURL, err := buildStandardProxyURL(normalized)
 		if err != nil {
@@ -150,7 +168,7 @@ func NewHTTPClient(cfg Config, timeout time.Duration) (*http.Client, error) {
 		if headers := buildStandardProxyConnectHeader(normalized); len(headers) > 0 {
 			transport.ProxyConnectHeader = headers
 		}
-		return &http.Client{Transport: transport, Timeout: timeout}, nil
+		return transport, nil
 	default:
 		return nil, fmt.Errorf("unsupported api proxy type: %s", normalized.Type)
 	}
@@ -124,6 +124,8 @@ func TestNoUntranslatedValues(t *testing.T) {
 //   - the language selector's own-name labels (each language name is shown in
 //     its native script, so set_lang_name_en is "English" in every catalog)
 //   - proper nouns of Chinese music services with no localized Japanese form
+//   - pure-symbol templates (preview_button is "▶" plus an index, cover_caption
+//     "🖼" plus the title)
 var intentionallyEnglish = map[string]bool{
 	"about_title":                       true,
 	"cb_quality_hires":                  true,
@@ -155,6 +157,8 @@ var intentionallyEnglish = map[string]bool{
 	"set_lang_name_ja":                  true,
 	"set_lang_name_ru":                  true,
 	"help_default_platforms":            true,
+	"preview_button":                    true,
+	"cover_caption":                     true,
 }
 
 // hasMeaningfulLatin reports whether s contains a run of 2+ ASCII letters, which
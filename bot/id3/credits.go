@@ -0,0 +1,129 @@
+package id3
+
+import (
+	"strings"
+
+	"github.com/liuran001/MusicBot-Go/bot/platform"
+)
+
+// releaseDateLayout is how ReleaseDate is written.
+const releaseDateLayout = "2006-01-02"
+
+// FillFromTrack fills the multi-value and credit fields tagData leaves empty
+// from the track's catalog metadata, so providers only need to supply what
+// the track does not carry. The artist lists are only taken when they agree
+// with the joined strings already set; writers the catalog lacks are read
+// from the lyric header.
+func FillFromTrack(tagData *TagData, track *platform.Track) {
+	if tagData == nil || track == nil {
+		return
+	}
+	if len(tagData.Artists) == 0 {
+		tagData.Artists = namesMatching(tagData.Artist, artistNames(track.Artists))
+	}
+	if len(tagData.Composers) == 0 {
+		tagData.Composers = cleanNames(track.Composers)
+	}
+	if len(tagData.Lyricists) == 0 {
+		tagData.Lyricists = cleanNames(track.Lyricists)
+	}
+	if len(tagData.Lyricists) == 0 || len(tagData.Composers) == 0 {
+		lyricists, composers := platform.ParseLyricCredits(tagData.Lyrics)
+		if len(tagData.Lyricists) == 0 {
+			tagData.Lyricists = lyricists
+		}
+		if len(tagData.Composers) == 0 {
+			tagData.Composers = composers
+		}
+	}
+	if tagData.ISRC == "" {
+		tagData.ISRC = strings.ToUpper(strings.TrimSpace(track.ISRC))
+	}
+	genres := cleanNames(track.Genres)
+	releaseDate := track.ReleaseDate
+	if album := track.Album; album != nil {
+		if len(tagData.AlbumArtists) == 0 {
+			tagData.AlbumArtists = namesMatching(tagData.AlbumArtist, artistNames(album.Artists))
+		}
+		if tagData.Label == "" {
+			tagData.Label = strings.TrimSpace(album.Label)
+		}
+		if tagData.Copyright == "" {
+			tagData.Copyright = strings.TrimSpace(album.Copyright)
+		}
+		if len(genres) == 0 {
+			genres = cleanNames(album.Genres)
+		}
+		if releaseDate == nil || releaseDate.IsZero() {
+			releaseDate = album.ReleaseDate
+		}
+	}
+	if len(tagData.Genres) == 0 {
+		tagData.Genres = namesMatching(tagData.Genre, genres)
+	}
+	if tagData.ReleaseDate == "" && releaseDate != nil && !releaseDate.IsZero() {
+		tagData.ReleaseDate = releaseDate.Format(releaseDateLayout)
+	}
+	if tagData.Year == "" && tagData.ReleaseDate != "" {
+		tagData.Year = tagData.ReleaseDate[:4]
+	}
+}
+
+func artistNames(artists []platform.Artist) []string {
+	names := make([]string, 0, len(artists))
+	for _, artist := range artists {
+		names = append(names, artist.Name)
+	}
+	return cleanNames(names)
+}
+
+// cleanNames trims names and drops blanks and duplicates.
+func cleanNames(names []string) []string {
+	var out []string
+	seen := make(map[string]bool, len(names))
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		out = append(out, name)
+	}
+	return out
+}
+
+// namesMatching returns names when joined is empty or is those names joined,
+// and nil when joined says something else.
+func namesMatching(joined string, names []string) []string {
+	if len(names) == 0 {
+		return nil
+	}
+	joined = strings.TrimSpace(joined)
+	if joined == "" {
+		return names
+	}
+	parts := platform.SplitCredits(joined)
+	if len(parts) != len(names) {
+		return nil
+	}
+	for i := range parts {
+		if parts[i] != names[i] {
+			return nil
+		}
+	}
+	return names
+}
+
+// dateTag is the value of the date tag: the full release date when it agrees
+// with Year, otherwise Year.
+func dateTag(tagData *TagData) string {
+	year := strings.TrimSpace(tagData.Year)
+	date := strings.TrimSpace(tagData.ReleaseDate)
+	if date == "" {
+		return year
+	}
+	if year == "" || strings.HasPrefix(date, year) {
+		return date
+	}
+	return year
+}
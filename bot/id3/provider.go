@@ -18,7 +18,35 @@ type TagData struct {
 	Comment     string
 	CoverURL    string
 	Lyrics      string
+	ReplayGain  *ReplayGain
 	Extra       map[string]any
+
+	// Multi-value fields. Artists, AlbumArtists and Genres take precedence
+	// over the joined Artist, AlbumArtist and Genre strings when written, so
+	// each name becomes its own tag value.
+	Artists      []string
+	AlbumArtists []string
+	Genres       []string
+	Composers    []string
+	Lyricists    []string
+
+	Label     string
+	ISRC      string
+	Copyright string
+	// ReleaseDate is the full release date (YYYY-MM-DD), written as the date
+	// tag in place of Year when it falls in that year.
+	ReleaseDate string
+}
+
+// ReplayGain holds ReplayGain 2.0 values: gains in dB relative to -18 LUFS and
+// peaks as linear amplitudes. Album values are only written when HasAlbum is
+// set.
+type ReplayGain struct {
+	TrackGain float64
+	TrackPeak float64
+	AlbumGain float64
+	AlbumPeak float64
+	HasAlbum  bool
 }
 
 type ID3TagProvider interface {
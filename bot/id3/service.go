@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"strconv"
 	"strings"
 
@@ -47,9 +48,35 @@ func (s *ID3Service) EmbedTags(audioPath string, tag *TagData, coverPath string)
 	return nil
 }
 
+// ReadCover returns the front cover embedded in audioPath, or nil when the
+// file carries none.
+func (s *ID3Service) ReadCover(audioPath string) ([]byte, error) {
+	if !isSupportedTagExtension(strings.ToLower(filepath.Ext(audioPath))) {
+		return nil, nil
+	}
+	return taglib.ReadImage(audioPath)
+}
+
+// EmbedCover replaces the front cover embedded in audioPath with coverPath.
+func (s *ID3Service) EmbedCover(audioPath, coverPath string) error {
+	ext := strings.ToLower(filepath.Ext(audioPath))
+	if !isSupportedTagExtension(ext) {
+		return errors.New("unsupported audio format for tags")
+	}
+	return s.writeCoverWithTaglib(audioPath, coverPath, ext)
+}
+
+// RemoveCover strips the front cover embedded in audioPath.
+func (s *ID3Service) RemoveCover(audioPath string) error {
+	if !isSupportedTagExtension(strings.ToLower(filepath.Ext(audioPath))) {
+		return errors.New("unsupported audio format for tags")
+	}
+	return taglib.WriteImage(audioPath, nil)
+}
+
 func isSupportedTagExtension(ext string) bool {
 	switch ext {
-	case ".mp3", ".flac", ".m4a", ".mp4":
+	case ".mp3", ".flac", ".m4a", ".mp4", ".ogg", ".opus":
 		return true
 	default:
 		return false
@@ -91,12 +118,17 @@ func buildTaglibTags(tagData *TagData) map[string][]string {
 	}
 
 	addTaglibValue(tags, taglib.Title, tagData.Title)
-	addTaglibValue(tags, taglib.Artist, tagData.Artist)
+	addTaglibValues(tags, taglib.Artist, tagData.Artists, tagData.Artist)
 	addTaglibValue(tags, taglib.Album, tagData.Album)
-	addTaglibValue(tags, taglib.AlbumArtist, tagData.AlbumArtist)
-	addTaglibValue(tags, taglib.Date, tagData.Year)
-	addTaglibValue(tags, taglib.Genre, tagData.Genre)
+	addTaglibValues(tags, taglib.AlbumArtist, tagData.AlbumArtists, tagData.AlbumArtist)
+	addTaglibValue(tags, taglib.Date, dateTag(tagData))
+	addTaglibValues(tags, taglib.Genre, tagData.Genres, tagData.Genre)
 	addTaglibValue(tags, taglib.Comment, tagData.Comment)
+	addTaglibValues(tags, taglib.Composer, tagData.Composers, "")
+	addTaglibValues(tags, taglib.Lyricist, tagData.Lyricists, "")
+	addTaglibValue(tags, taglib.Label, tagData.Label)
+	addTaglibValue(tags, taglib.ISRC, tagData.ISRC)
+	addTaglibValue(tags, taglib.Copyright, tagData.Copyright)
 
 	if tagData.TrackNumber > 0 {
 		tags[taglib.TrackNumber] = []string{strconv.Itoa(tagData.TrackNumber)}
@@ -109,9 +141,26 @@ func buildTaglibTags(tagData *TagData) map[string][]string {
 		tags[taglib.Lyrics] = []string{lyrics}
 	}
 
+	if rg := tagData.ReplayGain; rg != nil {
+		tags["REPLAYGAIN_TRACK_GAIN"] = []string{formatReplayGain(rg.TrackGain)}
+		tags["REPLAYGAIN_TRACK_PEAK"] = []string{formatReplayGainPeak(rg.TrackPeak)}
+		if rg.HasAlbum {
+			tags["REPLAYGAIN_ALBUM_GAIN"] = []string{formatReplayGain(rg.AlbumGain)}
+			tags["REPLAYGAIN_ALBUM_PEAK"] = []string{formatReplayGainPeak(rg.AlbumPeak)}
+		}
+	}
+
 	return tags
 }
 
+func formatReplayGain(gain float64) string {
+	return strconv.FormatFloat(gain, 'f', 2, 64) + " dB"
+}
+
+func formatReplayGainPeak(peak float64) string {
+	return strconv.FormatFloat(peak, 'f', 6, 64)
+}
+
 func addTaglibValue(tags map[string][]string, key, value string) {
 	trimmed := strings.TrimSpace(value)
 	if trimmed == "" {
@@ -120,6 +169,16 @@ func addTaglibValue(tags map[string][]string, key, value string) {
 	tags[key] = []string{trimmed}
 }
 
+// addTaglibValues writes values as a multi-value tag, or joined when there
+// are none.
+func addTaglibValues(tags map[string][]string, key string, values []string, joined string) {
+	if values = cleanNames(values); len(values) > 0 {
+		tags[key] = values
+		return
+	}
+	addTaglibValue(tags, key, joined)
+}
+
 func normalizedLyrics(tagData *TagData) string {
 	if tagData == nil {
 		return ""
@@ -132,6 +191,15 @@ func cloneTagData(tagData *TagData) *TagData {
 		return nil
 	}
 	cloned := *tagData
+	if tagData.ReplayGain != nil {
+		rg := *tagData.ReplayGain
+		cloned.ReplayGain = &rg
+	}
+	cloned.Artists = slices.Clone(tagData.Artists)
+	cloned.AlbumArtists = slices.Clone(tagData.AlbumArtists)
+	cloned.Genres = slices.Clone(tagData.Genres)
+	cloned.Composers = slices.Clone(tagData.Composers)
+	cloned.Lyricists = slices.Clone(tagData.Lyricists)
 	if tagData.Extra != nil {
 		cloned.Extra = make(map[string]any, len(tagData.Extra))
 		for k, v := range tagData.Extra {
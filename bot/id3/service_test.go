@@ -0,0 +1,108 @@
+package id3
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/liuran001/MusicBot-Go/bot/platform"
+)
+
+func TestBuildTaglibTagsWritesReplayGain(t *testing.T) {
+	tags := buildTaglibTags(&TagData{
+		Title:      "Song",
+		ReplayGain: &ReplayGain{TrackGain: -8.7, TrackPeak: 0.977661},
+	})
+	if got := tags["REPLAYGAIN_TRACK_GAIN"]; !reflect.DeepEqual(got, []string{"-8.70 dB"}) {
+		t.Fatalf("track gain = %v", got)
+	}
+	if got := tags["REPLAYGAIN_TRACK_PEAK"]; !reflect.DeepEqual(got, []string{"0.977661"}) {
+		t.Fatalf("track peak = %v", got)
+	}
+	if _, ok := tags["REPLAYGAIN_ALBUM_GAIN"]; ok {
+		t.Fatalf("album gain written without album measurement: %v", tags)
+	}
+
+	tags = buildTaglibTags(&TagData{ReplayGain: &ReplayGain{TrackGain: 1, TrackPeak: 0.5, AlbumGain: 2.345, AlbumPeak: 0.9, HasAlbum: true}})
+	if got := tags["REPLAYGAIN_ALBUM_GAIN"]; !reflect.DeepEqual(got, []string{"2.35 dB"}) {
+		t.Fatalf("album gain = %v", got)
+	}
+	if got := tags["REPLAYGAIN_ALBUM_PEAK"]; !reflect.DeepEqual(got, []string{"0.900000"}) {
+		t.Fatalf("album peak = %v", got)
+	}
+
+	if tags := buildTaglibTags(&TagData{Title: "Song"}); len(tags) != 1 {
+		t.Fatalf("unmeasured track got extra tags: %v", tags)
+	}
+}
+
+func TestBuildTaglibTagsWritesCredits(t *testing.T) {
+	tags := buildTaglibTags(&TagData{
+		Artist:      "A, B",
+		Artists:     []string{"A", "B"},
+		AlbumArtist: "A",
+		Genre:       "Pop",
+		Composers:   []string{"C", " ", "D"},
+		Lyricists:   []string{"E"},
+		Label:       "Label",
+		ISRC:        "USRC17607839",
+		Copyright:   "℗ 2024 Label",
+		Year:        "2024",
+		ReleaseDate: "2024-03-01",
+	})
+	for key, want := range map[string][]string{
+		"ARTIST":      {"A", "B"},
+		"ALBUMARTIST": {"A"},
+		"GENRE":       {"Pop"},
+		"COMPOSER":    {"C", "D"},
+		"LYRICIST":    {"E"},
+		"LABEL":       {"Label"},
+		"ISRC":        {"USRC17607839"},
+		"COPYRIGHT":   {"℗ 2024 Label"},
+		"DATE":        {"2024-03-01"},
+	} {
+		if got := tags[key]; !reflect.DeepEqual(got, want) {
+			t.Errorf("%s = %v, want %v", key, got, want)
+		}
+	}
+	if got := buildTaglibTags(&TagData{Year: "1999", ReleaseDate: "2024-03-01"})["DATE"]; !reflect.DeepEqual(got, []string{"1999"}) {
+		t.Fatalf("DATE with an overridden year = %v", got)
+	}
+}
+
+func TestFillFromTrack(t *testing.T) {
+	released := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	track := &platform.Track{
+		Artists:   []platform.Artist{{Name: "A"}, {Name: "B"}},
+		Composers: []string{"C"},
+		ISRC:      "usrc17607839",
+		Album: &platform.Album{
+			Artists:     []platform.Artist{{Name: "A"}},
+			Label:       "Label",
+			Genres:      []string{"Pop", "Rock"},
+			ReleaseDate: &released,
+		},
+	}
+	tagData := &TagData{Artist: "A, B", AlbumArtist: "Someone Else", Lyricists: []string{"E"}}
+	FillFromTrack(tagData, track)
+	if !reflect.DeepEqual(tagData.Artists, []string{"A", "B"}) || tagData.AlbumArtists != nil {
+		t.Fatalf("artists = %v / %v", tagData.Artists, tagData.AlbumArtists)
+	}
+	if !reflect.DeepEqual(tagData.Composers, []string{"C"}) || !reflect.DeepEqual(tagData.Lyricists, []string{"E"}) {
+		t.Fatalf("credits = %v / %v", tagData.Composers, tagData.Lyricists)
+	}
+	if !reflect.DeepEqual(tagData.Genres, []string{"Pop", "Rock"}) || tagData.Label != "Label" || tagData.ISRC != "USRC17607839" {
+		t.Fatalf("tag data = %+v", tagData)
+	}
+	if tagData.ReleaseDate != "2024-03-01" || tagData.Year != "2024" {
+		t.Fatalf("dates = %q / %q", tagData.ReleaseDate, tagData.Year)
+	}
+}
+
+func TestFillFromTrackReadsLyricCredits(t *testing.T) {
+	tagData := &TagData{Lyrics: "[00:00.00] 作词 : 方文山\n[00:01.00] 作曲 : 周杰伦\n[00:20.00]故事的小黄花"}
+	FillFromTrack(tagData, &platform.Track{Lyricists: []string{"Catalog"}})
+	if !reflect.DeepEqual(tagData.Lyricists, []string{"Catalog"}) || !reflect.DeepEqual(tagData.Composers, []string{"周杰伦"}) {
+		t.Fatalf("credits = %v / %v", tagData.Lyricists, tagData.Composers)
+	}
+}
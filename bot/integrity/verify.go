@@ -0,0 +1,290 @@
+// Package integrity checks downloaded audio by decoding every frame instead of
+// trusting the container headers. FLAC files are additionally checked against
+// the MD5 signature in their STREAMINFO block.
+package integrity
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hajimehoshi/go-mp3"
+	"github.com/mewkiz/flac"
+)
+
+// Status is the outcome of a successful verification.
+type Status string
+
+const (
+	// Verified means the stream decoded fully and matched its embedded
+	// signature (FLAC STREAMINFO MD5).
+	Verified Status = "verified"
+	// Decoded means the stream decoded fully but carries no signature to
+	// compare against.
+	Decoded Status = "decoded"
+)
+
+// shortfallTolerance is how much audio may be missing against the length the
+// file itself declares before it counts as truncated. It absorbs encoder
+// padding and rounding in MP3 frame counts.
+const shortfallTolerance = time.Second
+
+var (
+	// ErrCorrupt is returned when the file fails to decode, is shorter than
+	// it declares, or does not match its signature.
+	ErrCorrupt = errors.New("integrity: corrupt audio")
+	// ErrUnsupported is returned when the file cannot be decoded here, e.g. a
+	// non-FLAC, non-MP3 file without ffmpeg installed.
+	ErrUnsupported = errors.New("integrity: unsupported format")
+)
+
+// Report describes a file that passed verification.
+type Report struct {
+	Status Status
+	// Decoder is the decoder that read the file: flac, mp3 or ffmpeg.
+	Decoder string
+	// Duration is the length of audio actually decoded.
+	Duration time.Duration
+}
+
+// Verify decodes the whole audio file at path. FLAC and MP3 are decoded
+// in-process; everything else goes through ffmpeg. Errors wrapping ErrCorrupt
+// mean the file is broken; other errors mean it could not be checked.
+func Verify(ctx context.Context, path string) (Report, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".flac":
+		return verifyFLAC(ctx, path)
+	case ".mp3":
+		return verifyMP3(ctx, path)
+	default:
+		return verifyFFmpeg(ctx, path)
+	}
+}
+
+func corrupt(format string, args ...any) error {
+	return fmt.Errorf("%w: %s", ErrCorrupt, fmt.Sprintf(format, args...))
+}
+
+func verifyFLAC(ctx context.Context, path string) (Report, error) {
+	stream, err := flac.Open(path)
+	if err != nil {
+		return Report{}, corrupt("flac header: %v", err)
+	}
+	defer stream.Close()
+
+	info := stream.Info
+	if info.SampleRate == 0 {
+		return Report{}, corrupt("flac header: zero sample rate")
+	}
+	hash := md5.New()
+	var samples uint64
+	for n := 0; ; n++ {
+		if err := ctx.Err(); err != nil {
+			return Report{}, err
+		}
+		frame, err := stream.ParseNext()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return Report{}, corrupt("flac frame %d: %v", n, err)
+		}
+		frame.Hash(hash)
+		samples += uint64(frame.BlockSize)
+	}
+	duration := time.Duration(samples) * time.Second / time.Duration(info.SampleRate)
+	if info.NSamples != 0 && samples != info.NSamples {
+		return Report{}, corrupt("flac decoded %d of %d samples", samples, info.NSamples)
+	}
+	report := Report{Status: Decoded, Decoder: "flac", Duration: duration}
+	var zero [md5.Size]byte
+	if info.MD5sum == zero {
+		return report, nil
+	}
+	if !bytes.Equal(hash.Sum(nil), info.MD5sum[:]) {
+		return Report{}, corrupt("flac md5 signature mismatch")
+	}
+	report.Status = Verified
+	return report, nil
+}
+
+// verifyMP3 decodes every frame. go-mp3 skips garbage between frames and
+// treats a cut-off last frame as the end of the stream, so truncation is
+// caught by comparing against the frame count in a Xing/Info or VBRI header
+// when the encoder wrote one.
+func verifyMP3(ctx context.Context, path string) (Report, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return Report{}, err
+	}
+	defer f.Close()
+
+	declared, hasDeclared := mp3DeclaredDuration(f)
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return Report{}, err
+	}
+	dec, err := mp3.NewDecoder(bufio.NewReader(f))
+	if err != nil {
+		return Report{}, corrupt("mp3: %v", err)
+	}
+	// go-mp3 always emits 16-bit little-endian stereo.
+	buf := make([]byte, 64*1024)
+	var written int64
+	for {
+		if err := ctx.Err(); err != nil {
+			return Report{}, err
+		}
+		n, err := dec.Read(buf)
+		written += int64(n)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return Report{}, corrupt("mp3 frame at sample %d: %v", written/4, err)
+		}
+	}
+	if written == 0 {
+		return Report{}, corrupt("mp3: no audio frames")
+	}
+	duration := time.Duration(written/4) * time.Second / time.Duration(dec.SampleRate())
+	if hasDeclared && declared-duration > shortfallTolerance {
+		return Report{}, corrupt("mp3 decoded %s of %s", duration.Round(time.Millisecond), declared.Round(time.Millisecond))
+	}
+	return Report{Status: Decoded, Decoder: "mp3", Duration: duration}, nil
+}
+
+var mp3SampleRates = [4][3]int{
+	{11025, 12000, 8000},  // MPEG 2.5
+	{},                    // reserved
+	{22050, 24000, 16000}, // MPEG 2
+	{44100, 48000, 32000}, // MPEG 1
+}
+
+// mp3DeclaredDuration reads the frame count from the Xing/Info or VBRI header
+// in the first audio frame.
+func mp3DeclaredDuration(r io.ReadSeeker) (time.Duration, bool) {
+	var offset int64
+	tag := make([]byte, 10)
+	if _, err := io.ReadFull(r, tag); err == nil && string(tag[:3]) == "ID3" {
+		offset = int64(tag[6]&0x7f)<<21 | int64(tag[7]&0x7f)<<14 | int64(tag[8]&0x7f)<<7 | int64(tag[9]&0x7f)
+		offset += 10
+		if tag[5]&0x10 != 0 {
+			offset += 10
+		}
+	}
+	// The tag may hold a large cover; the first frame follows it.
+	if _, err := r.Seek(offset, io.SeekStart); err != nil {
+		return 0, false
+	}
+	head := make([]byte, 64*1024)
+	n, _ := io.ReadFull(r, head)
+	head = head[:n]
+	start := -1
+	for i := 0; i+4 <= len(head); i++ {
+		if head[i] == 0xff && head[i+1]&0xe0 == 0xe0 {
+			start = i
+			break
+		}
+	}
+	if start < 0 {
+		return 0, false
+	}
+	frame := head[start:]
+	version := (frame[1] >> 3) & 3
+	layer := (frame[1] >> 1) & 3
+	rateIndex := (frame[2] >> 2) & 3
+	if version == 1 || layer != 1 || rateIndex == 3 {
+		return 0, false
+	}
+	rate := mp3SampleRates[version][rateIndex]
+	samplesPerFrame := 576
+	if version == 3 {
+		samplesPerFrame = 1152
+	}
+	mono := frame[3]>>6 == 3
+	sideInfo := 32
+	switch {
+	case version == 3 && mono:
+		sideInfo = 17
+	case version != 3 && !mono:
+		sideInfo = 17
+	case version != 3 && mono:
+		sideInfo = 9
+	}
+
+	var frames uint32
+	if off := 4 + sideInfo; len(frame) >= off+12 {
+		if tag := string(frame[off : off+4]); tag == "Xing" || tag == "Info" {
+			if binary.BigEndian.Uint32(frame[off+4:])&1 == 0 {
+				return 0, false
+			}
+			frames = binary.BigEndian.Uint32(frame[off+8:])
+		}
+	}
+	if off := 4 + 32; frames == 0 && len(frame) >= off+18 && string(frame[off:off+4]) == "VBRI" {
+		frames = binary.BigEndian.Uint32(frame[off+14:])
+	}
+	if frames == 0 {
+		return 0, false
+	}
+	return time.Duration(frames) * time.Duration(samplesPerFrame) * time.Second / time.Duration(rate), true
+}
+
+// verifyFFmpeg decodes the first audio stream to the null muxer. -xerror
+// turns decode errors into a failing exit; anything ffmpeg prints at error
+// level counts as corruption too, since demuxers only log truncation.
+func verifyFFmpeg(ctx context.Context, path string) (Report, error) {
+	ffmpegPath, err := exec.LookPath("ffmpeg")
+	if err != nil {
+		return Report{}, ErrUnsupported
+	}
+	cmd := exec.CommandContext(ctx, ffmpegPath, "-hide_banner", "-nostats", "-v", "error", "-xerror",
+		"-i", path, "-map", "0:a:0", "-f", "null", "-progress", "pipe:1", "-")
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	runErr := cmd.Run()
+	if err := ctx.Err(); err != nil {
+		return Report{}, err
+	}
+	if msg := strings.TrimSpace(stderr.String()); runErr != nil || msg != "" {
+		if msg == "" {
+			msg = runErr.Error()
+		}
+		if i := strings.IndexByte(msg, '\n'); i > 0 {
+			msg = msg[:i]
+		}
+		return Report{}, corrupt("ffmpeg: %s", msg)
+	}
+	duration := ffmpegOutTime(stdout.String())
+	if duration <= 0 {
+		return Report{}, corrupt("ffmpeg: no audio decoded")
+	}
+	return Report{Status: Decoded, Decoder: "ffmpeg", Duration: duration}, nil
+}
+
+// ffmpegOutTime returns the last out_time_us reported by -progress.
+func ffmpegOutTime(progress string) time.Duration {
+	var out time.Duration
+	for _, line := range strings.Split(progress, "\n") {
+		value, ok := strings.CutPrefix(strings.TrimSpace(line), "out_time_us=")
+		if !ok {
+			continue
+		}
+		if us, err := strconv.ParseInt(value, 10, 64); err == nil && us > 0 {
+			out = time.Duration(us) * time.Microsecond
+		}
+	}
+	return out
+}
@@ -0,0 +1,164 @@
+package integrity
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mewkiz/flac"
+	"github.com/mewkiz/flac/frame"
+	"github.com/mewkiz/flac/meta"
+)
+
+// writeSineFLAC writes a 16-bit stereo 44.1 kHz FLAC sine; the encoder fills
+// in the STREAMINFO MD5 on Close.
+func writeSineFLAC(t *testing.T, path string, seconds int) {
+	t.Helper()
+	const (
+		rate      = 44100
+		blockSize = 4096
+	)
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	total := rate * seconds
+	info := &meta.StreamInfo{BlockSizeMin: blockSize, BlockSizeMax: blockSize, SampleRate: rate, NChannels: 2, BitsPerSample: 16, NSamples: uint64(total)}
+	enc, err := flac.NewEncoder(f, info)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for start, num := 0, uint64(0); start < total; start, num = start+blockSize, num+1 {
+		n := min(blockSize, total-start)
+		samples := make([]int32, n)
+		for i := range samples {
+			samples[i] = int32(math.Round(8000 * math.Sin(2*math.Pi*440*float64(start+i)/rate)))
+		}
+		fr := &frame.Frame{
+			Header: frame.Header{HasFixedBlockSize: true, BlockSize: uint16(n), SampleRate: rate, Channels: frame.ChannelsLR, BitsPerSample: 16, Num: num},
+			Subframes: []*frame.Subframe{
+				{SubHeader: frame.SubHeader{Pred: frame.PredVerbatim}, Samples: samples, NSamples: n},
+				{SubHeader: frame.SubHeader{Pred: frame.PredVerbatim}, Samples: samples, NSamples: n},
+			},
+		}
+		if err := enc.WriteFrame(fr); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := enc.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func mutateFile(t *testing.T, path string, mutate func([]byte) []byte) {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, mutate(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestVerifyFLAC(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sine.flac")
+	writeSineFLAC(t, path, 2)
+
+	report, err := Verify(context.Background(), path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Status != Verified || report.Decoder != "flac" || report.Duration != 2*time.Second {
+		t.Fatalf("report = %+v, want verified flac of 2s", report)
+	}
+}
+
+func TestVerifyFLACCorrupt(t *testing.T) {
+	tests := []struct {
+		name   string
+		mutate func([]byte) []byte
+	}{
+		{"truncated", func(b []byte) []byte { return b[:len(b)*2/3] }},
+		{"flipped sample", func(b []byte) []byte { b[len(b)/2] ^= 0x55; return b }},
+		// "fLaC" + block header + 18 bytes of STREAMINFO precede the MD5.
+		{"signature mismatch", func(b []byte) []byte { b[26] ^= 0xff; return b }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "sine.flac")
+			writeSineFLAC(t, path, 2)
+			mutateFile(t, path, tt.mutate)
+			if _, err := Verify(context.Background(), path); !errors.Is(err, ErrCorrupt) {
+				t.Fatalf("Verify() error = %v, want ErrCorrupt", err)
+			}
+		})
+	}
+}
+
+func TestVerifyFLACWithoutSignature(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sine.flac")
+	writeSineFLAC(t, path, 1)
+	mutateFile(t, path, func(b []byte) []byte {
+		copy(b[26:42], make([]byte, 16))
+		return b
+	})
+
+	report, err := Verify(context.Background(), path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Status != Decoded {
+		t.Fatalf("status = %q, want %q", report.Status, Decoded)
+	}
+}
+
+func TestMP3DeclaredDuration(t *testing.T) {
+	// MPEG-1 Layer III, 128 kbps, 44.1 kHz, joint stereo, with an Info
+	// header carrying a frame count after 32 bytes of side info.
+	frame := make([]byte, 417)
+	copy(frame, []byte{0xff, 0xfb, 0x90, 0x44})
+	copy(frame[36:], "Info")
+	binary.BigEndian.PutUint32(frame[40:], 0x1)
+	binary.BigEndian.PutUint32(frame[44:], 3828)
+	id3 := []byte{'I', 'D', '3', 4, 0, 0, 0, 0, 0, 5, 0, 0, 0, 0, 0}
+
+	got, ok := mp3DeclaredDuration(bytes.NewReader(append(id3, frame...)))
+	if !ok {
+		t.Fatal("expected a declared duration")
+	}
+	if want := 3828 * 1152 * time.Second / 44100; got != want {
+		t.Fatalf("duration = %s, want %s", got, want)
+	}
+
+	// A tag with a large embedded cover pushes the first frame far past the
+	// read buffer.
+	const coverSize = 200 * 1024
+	bigID3 := []byte{'I', 'D', '3', 4, 0, 0, 0, byte(coverSize >> 14 & 0x7f), byte(coverSize >> 7 & 0x7f), byte(coverSize & 0x7f)}
+	bigID3 = append(bigID3, make([]byte, coverSize)...)
+	got, ok = mp3DeclaredDuration(bytes.NewReader(append(bigID3, frame...)))
+	if want := 3828 * 1152 * time.Second / 44100; !ok || got != want {
+		t.Fatalf("duration behind a %d byte tag = %s, %v; want %s", coverSize, got, ok, want)
+	}
+
+	if _, ok := mp3DeclaredDuration(bytes.NewReader([]byte{0xff, 0xfb, 0x90, 0x44, 0, 0})); ok {
+		t.Fatal("expected no declared duration without a Xing/Info header")
+	}
+}
+
+func TestFFmpegOutTime(t *testing.T) {
+	progress := "out_time_us=1000000\nprogress=continue\nout_time_us=185123456\nprogress=end\n"
+	if got := ffmpegOutTime(progress); got != 185123456*time.Microsecond {
+		t.Fatalf("ffmpegOutTime = %s", got)
+	}
+	if got := ffmpegOutTime("out_time_us=N/A\n"); got != 0 {
+		t.Fatalf("ffmpegOutTime(N/A) = %s, want 0", got)
+	}
+}
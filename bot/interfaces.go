@@ -1,6 +1,9 @@
 package bot
 
-import "context"
+import (
+	"context"
+	"time"
+)
 
 // Logger is the minimal logging abstraction used across modules.
 type Logger interface {
@@ -57,6 +60,44 @@ type SongRepository interface {
 	FindCachedSongMeta(ctx context.Context, platform, trackID string) (*SongInfo, error)
 }
 
+// SongVariantStore looks up and removes transcoded cache variants, keyed by
+// the source quality plus the output format.
+type SongVariantStore interface {
+	FindSongVariant(ctx context.Context, platform, trackID, quality, outputFormat string) (*SongInfo, error)
+	DeleteSongVariant(ctx context.Context, platform, trackID, quality, outputFormat string) error
+}
+
+// ArtistSubscriptionStore persists artist release subscriptions in data.db.
+type ArtistSubscriptionStore interface {
+	AddArtistSubscription(ctx context.Context, sub *ArtistSubscription) error
+	RemoveArtistSubscription(ctx context.Context, chatID int64, id uint) error
+	IsArtistSubscribed(ctx context.Context, chatID int64, platform, artistID string) (bool, error)
+	ListArtistSubscriptions(ctx context.Context, chatID int64) ([]*ArtistSubscription, error)
+	CountArtistSubscriptions(ctx context.Context, chatID int64) (int64, error)
+	ListDueArtistSubscriptions(ctx context.Context, limit int) ([]*ArtistSubscription, error)
+	MarkArtistSubscriptionChecked(ctx context.Context, id uint, knownReleaseIDs []string, checkedAt time.Time) error
+	MarkArtistSubscriptionPolled(ctx context.Context, id uint, polledAt time.Time) error
+}
+
+// PlaylistSubscriptionStore persists playlist sync subscriptions in data.db.
+type PlaylistSubscriptionStore interface {
+	AddPlaylistSubscription(ctx context.Context, sub *PlaylistSubscription) error
+	RemovePlaylistSubscription(ctx context.Context, chatID int64, id uint) error
+	SetPlaylistSubscriptionAutoDownload(ctx context.Context, chatID int64, id uint, enabled bool) error
+	IsPlaylistSubscribed(ctx context.Context, chatID int64, platform, playlistID string) (bool, error)
+	ListPlaylistSubscriptions(ctx context.Context, chatID int64) ([]*PlaylistSubscription, error)
+	CountPlaylistSubscriptions(ctx context.Context, chatID int64) (int64, error)
+	ListDuePlaylistSubscriptions(ctx context.Context, limit int) ([]*PlaylistSubscription, error)
+	MarkPlaylistSubscriptionChecked(ctx context.Context, id uint, trackIDs []string, checkedAt time.Time) error
+}
+
+// PreviewStore caches uploaded preview clips in cache.db.
+type PreviewStore interface {
+	FindPreview(ctx context.Context, platform, trackID string) (*PreviewClip, error)
+	SavePreview(ctx context.Context, clip *PreviewClip) error
+	DeletePreview(ctx context.Context, platform, trackID string) error
+}
+
 // WorkerPool limits concurrency for background tasks.
 type WorkerPool interface {
 	Submit(task func()) error
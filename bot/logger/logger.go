@@ -13,6 +13,7 @@ import (
 	"time"
 
 	"github.com/liuran001/MusicBot-Go/bot"
+	"github.com/liuran001/MusicBot-Go/bot/credential"
 )
 
 // Logger wraps slog.Logger to satisfy bot.Logger.
@@ -21,7 +22,7 @@ type Logger struct {
 	logFile *os.File // Keep reference to close on shutdown
 }
 
-const redactionPlaceholder = "[REDACTED]"
+const redactionPlaceholder = credential.Placeholder
 
 // New creates a new Logger with configurable output format.
 func New(level, format string, addSource bool) (*Logger, error) {
@@ -29,7 +30,9 @@ func New(level, format string, addSource bool) (*Logger, error) {
 }
 
 // NewWithSecrets creates a Logger that replaces exact secret values before
-// records reach the configured output handler.
+// records reach the configured output handler. Independently of the list,
+// string fields named like credentials (cookie, *_token, music_u, ...) and
+// cookie/token pairs inside messages are always masked.
 func NewWithSecrets(level, format string, addSource bool, secrets ...string) (*Logger, error) {
 	logFile, output, err := logOutput()
 	if err != nil {
@@ -69,13 +72,9 @@ type redactingHandler struct {
 }
 
 func newRedactingHandler(handler slog.Handler, secrets []string) slog.Handler {
-	normalized := normalizedSecrets(secrets)
-	if len(normalized) == 0 {
-		return handler
-	}
 	return &redactingHandler{
 		handler: handler,
-		secrets: normalized,
+		secrets: normalizedSecrets(secrets),
 	}
 }
 
@@ -116,6 +115,12 @@ func (h *redactingHandler) WithGroup(name string) slog.Handler {
 }
 
 func (h *redactingHandler) redactAttr(attr slog.Attr) slog.Attr {
+	if credential.IsCredentialField(attr.Key) {
+		if value := attr.Value.Resolve(); value.Kind() == slog.KindString && value.String() != "" {
+			attr.Value = slog.StringValue(credential.Placeholder)
+			return attr
+		}
+	}
 	attr.Key = h.redactString(attr.Key)
 	attr.Value = h.redactValue(attr.Value)
 	return attr
@@ -141,7 +146,20 @@ func (h *redactingHandler) redactValue(value slog.Value) slog.Value {
 			return slog.AnyValue(errors.New(h.redactString(typed.Error())))
 		case slog.Attr:
 			return slog.AnyValue(h.redactAttr(typed))
+		case map[string]string:
+			redacted := make(map[string]string, len(typed))
+			for key, item := range typed {
+				if credential.IsCredentialField(key) && item != "" {
+					redacted[key] = credential.Placeholder
+					continue
+				}
+				redacted[key] = h.redactString(item)
+			}
+			return slog.AnyValue(redacted)
 		default:
+			if len(h.secrets) == 0 {
+				return value
+			}
 			return slog.StringValue(h.redactString(fmt.Sprint(typed)))
 		}
 	}
@@ -152,7 +170,7 @@ func (h *redactingHandler) redactString(value string) string {
 	for _, secret := range h.secrets {
 		value = strings.ReplaceAll(value, secret, redactionPlaceholder)
 	}
-	return value
+	return credential.RedactText(value)
 }
 
 func normalizedSecrets(secrets []string) []string {
@@ -171,6 +171,29 @@ func TestLoggerRedactsUnhandledAnyValuesInTextAndJSON(t *testing.T) {
 	}
 }
 
+func TestLoggerRedactsCredentialFieldsWithoutSecretList(t *testing.T) {
+	log := newTestLogger(t)
+	log.Debug(
+		"renew failed: MUSIC_U=cookie-value; os=pc",
+		"pairs", map[string]string{"media_user_token": "token-value", "language": "en-US"},
+		"cookie", "cookie-attr-value",
+		"cookie_len", 42,
+		"key", "cache-key-stays",
+	)
+
+	output := closeAndReadLog(t, log)
+	for _, leaked := range []string{"cookie-value", "token-value", "cookie-attr-value"} {
+		if strings.Contains(output, leaked) {
+			t.Fatalf("logger leaked %q: %q", leaked, output)
+		}
+	}
+	for _, want := range []string{"os=pc", "en-US", "cookie_len=42", "cache-key-stays"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("logger output missing %q: %q", want, output)
+		}
+	}
+}
+
 func newTestLogger(t *testing.T, secrets ...string) *Logger {
 	t.Helper()
 	return newTestLoggerWithFormat(t, "text", secrets...)
@@ -0,0 +1,167 @@
+package loudness
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/hajimehoshi/go-mp3"
+	"github.com/mewkiz/flac"
+)
+
+// errUnsupported marks formats without a pure-Go decoder.
+var errUnsupported = errors.New("loudness: unsupported format")
+
+// Analyze measures the audio file at path. FLAC and MP3 are decoded in-process;
+// other formats, and files the pure-Go decoders reject, go through ffmpeg's
+// ebur128 filter when ffmpeg is installed.
+func Analyze(ctx context.Context, path string) (Result, error) {
+	var (
+		res Result
+		err error
+	)
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".flac":
+		res, err = analyzeFLAC(ctx, path)
+	case ".mp3":
+		res, err = analyzeMP3(ctx, path)
+	default:
+		err = errUnsupported
+	}
+	if err == nil || errors.Is(err, ErrNoSignal) || ctx.Err() != nil {
+		return res, err
+	}
+	ffmpegPath, lookErr := exec.LookPath("ffmpeg")
+	if lookErr != nil {
+		return Result{}, err
+	}
+	return analyzeFFmpeg(ctx, ffmpegPath, path)
+}
+
+func analyzeFLAC(ctx context.Context, path string) (Result, error) {
+	stream, err := flac.Open(path)
+	if err != nil {
+		return Result{}, err
+	}
+	defer stream.Close()
+
+	channels := int(stream.Info.NChannels)
+	scale := 1 / float64(int64(1)<<(stream.Info.BitsPerSample-1))
+	meter := NewMeter(int(stream.Info.SampleRate), channels)
+	var buf []float64
+	for {
+		if err := ctx.Err(); err != nil {
+			return Result{}, err
+		}
+		frame, err := stream.ParseNext()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return Result{}, err
+		}
+		n := int(frame.BlockSize)
+		buf = buf[:0]
+		for i := range n {
+			for c := range channels {
+				buf = append(buf, float64(frame.Subframes[c].Samples[i])*scale)
+			}
+		}
+		meter.Write(buf)
+	}
+	return meter.Result()
+}
+
+func analyzeMP3(ctx context.Context, path string) (Result, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return Result{}, err
+	}
+	defer f.Close()
+
+	// A plain reader keeps go-mp3 from scanning the whole file up front.
+	dec, err := mp3.NewDecoder(bufio.NewReader(f))
+	if err != nil {
+		return Result{}, err
+	}
+	// go-mp3 always outputs 16-bit little-endian stereo.
+	meter := NewMeter(dec.SampleRate(), 2)
+	raw := make([]byte, 64*1024)
+	samples := make([]float64, 0, len(raw)/2)
+	for {
+		if err := ctx.Err(); err != nil {
+			return Result{}, err
+		}
+		n, err := io.ReadFull(dec, raw)
+		n -= n % 4
+		samples = samples[:0]
+		for i := 0; i < n; i += 2 {
+			samples = append(samples, float64(int16(uint16(raw[i])|uint16(raw[i+1])<<8))/32768)
+		}
+		meter.Write(samples)
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			break
+		}
+		if err != nil {
+			return Result{}, err
+		}
+	}
+	return meter.Result()
+}
+
+var (
+	ffmpegIntegratedRe = regexp.MustCompile(`I:\s+(-?[0-9.]+|-inf)\s+LUFS`)
+	ffmpegPeakRe       = regexp.MustCompile(`Peak:\s+(-?[0-9.]+|-inf)\s+dBFS`)
+)
+
+// analyzeFFmpeg runs the ebur128 filter and reads the summary it prints to
+// stderr. Results from ffmpeg carry no block data and cannot join an album
+// measurement.
+func analyzeFFmpeg(ctx context.Context, ffmpegPath, path string) (Result, error) {
+	cmd := exec.CommandContext(ctx, ffmpegPath, "-hide_banner", "-nostats", "-i", path,
+		"-map", "0:a:0", "-filter:a", "ebur128=peak=true", "-f", "null", "-")
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return Result{}, fmt.Errorf("ffmpeg ebur128: %w", err)
+	}
+	return parseFFmpegSummary(stderr.String())
+}
+
+func parseFFmpegSummary(output string) (Result, error) {
+	// The summary is printed last; earlier matches are per-frame logs.
+	if idx := strings.LastIndex(output, "Summary:"); idx >= 0 {
+		output = output[idx:]
+	}
+	im := ffmpegIntegratedRe.FindStringSubmatch(output)
+	if im == nil {
+		return Result{}, errors.New("ffmpeg ebur128: no integrated loudness in output")
+	}
+	if im[1] == "-inf" {
+		return Result{}, ErrNoSignal
+	}
+	integrated, err := strconv.ParseFloat(im[1], 64)
+	if err != nil {
+		return Result{}, err
+	}
+	if integrated <= absoluteGateLUFS {
+		return Result{}, ErrNoSignal
+	}
+	res := Result{Integrated: integrated}
+	if pm := ffmpegPeakRe.FindStringSubmatch(output); pm != nil && pm[1] != "-inf" {
+		if db, err := strconv.ParseFloat(pm[1], 64); err == nil {
+			res.TruePeak = math.Pow(10, db/20)
+		}
+	}
+	return res, nil
+}
@@ -0,0 +1,70 @@
+package loudness
+
+import (
+	"context"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mewkiz/flac"
+	"github.com/mewkiz/flac/frame"
+	"github.com/mewkiz/flac/meta"
+)
+
+// writeSineFLAC writes a 16-bit stereo 44.1 kHz FLAC sine at the given
+// amplitude.
+func writeSineFLAC(t *testing.T, path string, amplitude float64, seconds int) {
+	t.Helper()
+	const (
+		rate      = 44100
+		blockSize = 4096
+	)
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	total := rate * seconds
+	info := &meta.StreamInfo{BlockSizeMin: blockSize, BlockSizeMax: blockSize, SampleRate: rate, NChannels: 2, BitsPerSample: 16, NSamples: uint64(total)}
+	enc, err := flac.NewEncoder(f, info)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for start, num := 0, uint64(0); start < total; start, num = start+blockSize, num+1 {
+		n := min(blockSize, total-start)
+		samples := make([]int32, n)
+		for i := range samples {
+			samples[i] = int32(math.Round(amplitude * 32767 * math.Sin(2*math.Pi*997*float64(start+i)/rate)))
+		}
+		fr := &frame.Frame{
+			Header: frame.Header{HasFixedBlockSize: true, BlockSize: uint16(n), SampleRate: rate, Channels: frame.ChannelsLR, BitsPerSample: 16, Num: num},
+			Subframes: []*frame.Subframe{
+				{SubHeader: frame.SubHeader{Pred: frame.PredVerbatim}, Samples: samples, NSamples: n},
+				{SubHeader: frame.SubHeader{Pred: frame.PredVerbatim}, Samples: samples, NSamples: n},
+			},
+		}
+		if err := enc.WriteFrame(fr); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := enc.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestAnalyzeFLAC(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sine.flac")
+	writeSineFLAC(t, path, 0.25, 4)
+
+	res, err := Analyze(context.Background(), path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(res.Integrated+12.04) > 0.1 {
+		t.Fatalf("integrated = %.2f LUFS, want -12.04", res.Integrated)
+	}
+	if math.Abs(res.TruePeak-0.25) > 0.01 {
+		t.Fatalf("true peak = %.4f, want 0.25", res.TruePeak)
+	}
+}
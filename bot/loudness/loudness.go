@@ -0,0 +1,309 @@
+// Package loudness measures integrated loudness and true peak following
+// ITU-R BS.1770-4 / EBU R128, and derives ReplayGain 2.0 values from them.
+package loudness
+
+import (
+	"errors"
+	"math"
+)
+
+// ReferenceLUFS is the ReplayGain 2.0 target loudness.
+const ReferenceLUFS = -18.0
+
+const (
+	absoluteGateLUFS = -70.0
+	relativeGateLU   = -10.0
+	// Blocks are 400 ms long and start every 100 ms (75% overlap).
+	subBlocksPerBlock = 4
+)
+
+// ErrNoSignal is returned when every block falls below the absolute gate,
+// e.g. for digital silence.
+var ErrNoSignal = errors.New("loudness: no signal above the absolute gate")
+
+// Result is the outcome of one measurement.
+type Result struct {
+	// Integrated is the gated integrated loudness in LUFS.
+	Integrated float64
+	// TruePeak is the highest inter-sample peak as a linear amplitude
+	// (1.0 = full scale).
+	TruePeak float64
+	// blocks keeps the per-block mean-square energies so several results can
+	// be gated together for album gain.
+	blocks []float64
+}
+
+// Gain returns the ReplayGain 2.0 gain in dB.
+func (r Result) Gain() float64 {
+	return ReferenceLUFS - r.Integrated
+}
+
+// TruePeakDB returns the true peak in dBTP.
+func (r Result) TruePeakDB() float64 {
+	if r.TruePeak <= 0 {
+		return math.Inf(-1)
+	}
+	return 20 * math.Log10(r.TruePeak)
+}
+
+// Album gates the blocks of all tracks together, giving the album loudness
+// and the highest peak.
+func Album(results ...Result) (Result, error) {
+	var album Result
+	for _, r := range results {
+		album.blocks = append(album.blocks, r.blocks...)
+		album.TruePeak = math.Max(album.TruePeak, r.TruePeak)
+	}
+	integrated, err := gatedLoudness(album.blocks)
+	if err != nil {
+		return Result{}, err
+	}
+	album.Integrated = integrated
+	return album, nil
+}
+
+// Meter accumulates interleaved samples and reports their loudness.
+type Meter struct {
+	channels int
+	weights  []float64
+	filters  []kWeighting
+	peaks    []*truePeak
+
+	subBlockLen int
+	subFill     int
+	subSum      float64
+	recent      []float64
+	blocks      []float64
+	peak        float64
+}
+
+// NewMeter returns a meter for the given sample rate and channel count.
+func NewMeter(sampleRate, channels int) *Meter {
+	if channels < 1 {
+		channels = 1
+	}
+	m := &Meter{
+		channels:    channels,
+		weights:     channelWeights(channels),
+		filters:     make([]kWeighting, channels),
+		peaks:       make([]*truePeak, channels),
+		subBlockLen: max(sampleRate/10, 1),
+	}
+	for c := range channels {
+		m.filters[c] = newKWeighting(float64(sampleRate))
+		m.peaks[c] = newTruePeak(sampleRate)
+	}
+	return m
+}
+
+// channelWeights follows BS.1770: surround channels count +1.5 dB and the
+// LFE (fourth channel of a 5.1/7.1 layout) is excluded.
+func channelWeights(channels int) []float64 {
+	w := make([]float64, channels)
+	for i := range w {
+		w[i] = 1
+	}
+	if channels == 6 || channels == 8 {
+		w[3] = 0
+		for i := 4; i < channels; i++ {
+			w[i] = 1.41
+		}
+	}
+	return w
+}
+
+// Write adds interleaved samples scaled to [-1, 1]. A trailing partial frame
+// is ignored.
+func (m *Meter) Write(samples []float64) {
+	frames := len(samples) / m.channels
+	for f := range frames {
+		frame := samples[f*m.channels : (f+1)*m.channels]
+		for c, s := range frame {
+			if p := m.peaks[c].process(s); p > m.peak {
+				m.peak = p
+			}
+			if m.weights[c] == 0 {
+				continue
+			}
+			y := m.filters[c].process(s)
+			m.subSum += m.weights[c] * y * y
+		}
+		m.subFill++
+		if m.subFill == m.subBlockLen {
+			m.closeSubBlock()
+		}
+	}
+}
+
+func (m *Meter) closeSubBlock() {
+	m.recent = append(m.recent, m.subSum)
+	if len(m.recent) > subBlocksPerBlock {
+		m.recent = m.recent[1:]
+	}
+	if len(m.recent) == subBlocksPerBlock {
+		var sum float64
+		for _, v := range m.recent {
+			sum += v
+		}
+		m.blocks = append(m.blocks, sum/float64(subBlocksPerBlock*m.subBlockLen))
+	}
+	m.subSum = 0
+	m.subFill = 0
+}
+
+// Result returns the loudness of everything written so far.
+func (m *Meter) Result() (Result, error) {
+	integrated, err := gatedLoudness(m.blocks)
+	if err != nil {
+		return Result{}, err
+	}
+	blocks := make([]float64, len(m.blocks))
+	copy(blocks, m.blocks)
+	return Result{Integrated: integrated, TruePeak: m.peak, blocks: blocks}, nil
+}
+
+func blockLoudness(energy float64) float64 {
+	return -0.691 + 10*math.Log10(energy)
+}
+
+// gatedLoudness applies the absolute and relative gates of BS.1770-4.
+func gatedLoudness(blocks []float64) (float64, error) {
+	var sum float64
+	var n int
+	for _, e := range blocks {
+		if e > 0 && blockLoudness(e) > absoluteGateLUFS {
+			sum += e
+			n++
+		}
+	}
+	if n == 0 {
+		return 0, ErrNoSignal
+	}
+	threshold := blockLoudness(sum/float64(n)) + relativeGateLU
+	sum, n = 0, 0
+	for _, e := range blocks {
+		if e > 0 {
+			if l := blockLoudness(e); l > absoluteGateLUFS && l > threshold {
+				sum += e
+				n++
+			}
+		}
+	}
+	if n == 0 {
+		return 0, ErrNoSignal
+	}
+	return blockLoudness(sum / float64(n)), nil
+}
+
+// biquad is a direct form I second-order section.
+type biquad struct {
+	b0, b1, b2, a1, a2 float64
+	x1, x2, y1, y2     float64
+}
+
+func (q *biquad) process(x float64) float64 {
+	y := q.b0*x + q.b1*q.x1 + q.b2*q.x2 - q.a1*q.y1 - q.a2*q.y2
+	q.x2, q.x1 = q.x1, x
+	q.y2, q.y1 = q.y1, y
+	return y
+}
+
+// kWeighting is the BS.1770 pre-filter (high shelf) followed by the RLB
+// high-pass, designed for the actual sample rate.
+type kWeighting struct {
+	shelf, highPass biquad
+}
+
+func newKWeighting(rate float64) kWeighting {
+	var k kWeighting
+
+	f0, gain, q := 1681.974450955533, 3.999843853973347, 0.7071752369554196
+	K := math.Tan(math.Pi * f0 / rate)
+	vh := math.Pow(10, gain/20)
+	vb := math.Pow(vh, 0.4996667741545416)
+	a0 := 1 + K/q + K*K
+	k.shelf = biquad{
+		b0: (vh + vb*K/q + K*K) / a0,
+		b1: 2 * (K*K - vh) / a0,
+		b2: (vh - vb*K/q + K*K) / a0,
+		a1: 2 * (K*K - 1) / a0,
+		a2: (1 - K/q + K*K) / a0,
+	}
+
+	f0, q = 38.13547087602444, 0.5003270373238773
+	K = math.Tan(math.Pi * f0 / rate)
+	a0 = 1 + K/q + K*K
+	k.highPass = biquad{
+		b0: 1,
+		b1: -2,
+		b2: 1,
+		a1: 2 * (K*K - 1) / a0,
+		a2: (1 - K/q + K*K) / a0,
+	}
+	return k
+}
+
+func (k *kWeighting) process(x float64) float64 {
+	return k.highPass.process(k.shelf.process(x))
+}
+
+// truePeak estimates inter-sample peaks by polyphase oversampling: 4x below
+// 96 kHz, 2x below 192 kHz, sample peak above that.
+type truePeak struct {
+	phases [][]tap
+	delay  []float64
+	pos    int
+}
+
+type tap struct {
+	index int
+	coeff float64
+}
+
+func newTruePeak(sampleRate int) *truePeak {
+	factor := 1
+	switch {
+	case sampleRate < 96000:
+		factor = 4
+	case sampleRate < 192000:
+		factor = 2
+	}
+	p := &truePeak{}
+	if factor == 1 {
+		return p
+	}
+	const taps = 49
+	p.phases = make([][]tap, factor)
+	p.delay = make([]float64, (taps+factor-1)/factor)
+	for j := range taps {
+		m := float64(j) - float64(taps-1)/2
+		c := 1.0
+		if math.Abs(m) > 1e-6 {
+			x := m * math.Pi / float64(factor)
+			c = math.Sin(x) / x
+		}
+		c *= 0.5 * (1 - math.Cos(2*math.Pi*float64(j)/float64(taps-1)))
+		if math.Abs(c) > 1e-6 {
+			p.phases[j%factor] = append(p.phases[j%factor], tap{index: j / factor, coeff: c})
+		}
+	}
+	return p
+}
+
+func (p *truePeak) process(x float64) float64 {
+	peak := math.Abs(x)
+	if len(p.phases) == 0 {
+		return peak
+	}
+	n := len(p.delay)
+	p.delay[p.pos] = x
+	for _, phase := range p.phases {
+		var acc float64
+		for _, t := range phase {
+			acc += t.coeff * p.delay[(p.pos-t.index+n)%n]
+		}
+		peak = math.Max(peak, math.Abs(acc))
+	}
+	p.pos = (p.pos + 1) % n
+	return peak
+}
@@ -0,0 +1,127 @@
+package loudness
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func sine(rate, channels int, freq, amplitude, seconds float64) []float64 {
+	frames := int(float64(rate) * seconds)
+	out := make([]float64, 0, frames*channels)
+	for i := range frames {
+		s := amplitude * math.Sin(2*math.Pi*freq*float64(i)/float64(rate))
+		for range channels {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+func TestMeterSineMatchesReference(t *testing.T) {
+	// EBU Tech 3341: a stereo 1 kHz sine at -N dBFS per channel reads
+	// -N LUFS.
+	for _, rate := range []int{44100, 48000, 96000} {
+		m := NewMeter(rate, 2)
+		m.Write(sine(rate, 2, 997, 0.5, 5))
+		res, err := m.Result()
+		if err != nil {
+			t.Fatalf("%d Hz: %v", rate, err)
+		}
+		if want := -6.02; math.Abs(res.Integrated-want) > 0.1 {
+			t.Fatalf("%d Hz: integrated = %.2f LUFS, want %.2f", rate, res.Integrated, want)
+		}
+		if math.Abs(res.TruePeak-0.5) > 0.01 {
+			t.Fatalf("%d Hz: true peak = %.4f, want 0.5", rate, res.TruePeak)
+		}
+		if want := ReferenceLUFS - res.Integrated; res.Gain() != want {
+			t.Fatalf("gain = %.2f, want %.2f", res.Gain(), want)
+		}
+	}
+}
+
+func TestTruePeakCatchesInterSamplePeaks(t *testing.T) {
+	// fs/4 sampled at 45° hits only ±0.707 of the real 1.0 amplitude.
+	rate := 48000
+	samples := make([]float64, rate)
+	for i := range samples {
+		samples[i] = math.Sin(math.Pi/2*float64(i) + math.Pi/4)
+	}
+	m := NewMeter(rate, 1)
+	m.Write(samples)
+	res, err := m.Result()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.TruePeak < 0.95 {
+		t.Fatalf("true peak = %.3f, want close to 1.0", res.TruePeak)
+	}
+}
+
+func TestMeterGatesSilence(t *testing.T) {
+	m := NewMeter(48000, 2)
+	m.Write(make([]float64, 48000*2*2))
+	if _, err := m.Result(); !errors.Is(err, ErrNoSignal) {
+		t.Fatalf("silence err = %v, want ErrNoSignal", err)
+	}
+
+	// Silence around a tone is gated out; only the few blocks straddling the
+	// edges pull the value down slightly.
+	m = NewMeter(48000, 2)
+	m.Write(make([]float64, 48000*2*3))
+	m.Write(sine(48000, 2, 997, 0.5, 5))
+	m.Write(make([]float64, 48000*2*3))
+	res, err := m.Result()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(res.Integrated+6.02) > 0.5 {
+		t.Fatalf("gated integrated = %.2f LUFS", res.Integrated)
+	}
+}
+
+func TestAlbumCombinesBlocks(t *testing.T) {
+	loud := NewMeter(48000, 2)
+	loud.Write(sine(48000, 2, 997, 0.5, 5))
+	quiet := NewMeter(48000, 2)
+	quiet.Write(sine(48000, 2, 997, 0.25, 5))
+	a, _ := loud.Result()
+	b, _ := quiet.Result()
+
+	album, err := Album(a, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if album.Integrated <= b.Integrated || album.Integrated >= a.Integrated {
+		t.Fatalf("album %.2f not between %.2f and %.2f", album.Integrated, b.Integrated, a.Integrated)
+	}
+	if album.TruePeak != a.TruePeak {
+		t.Fatalf("album peak = %.4f, want %.4f", album.TruePeak, a.TruePeak)
+	}
+}
+
+func TestParseFFmpegSummary(t *testing.T) {
+	output := `[Parsed_ebur128_0 @ 0x1] t: 0.4 TARGET:-23 LUFS M: -20.1 S:-120.7 I: -20.1 LUFS LRA: 0.0 LU FTPK: -6.0 dBFS TPK: -6.0 dBFS
+[Parsed_ebur128_0 @ 0x1] Summary:
+
+  Integrated loudness:
+    I:         -12.4 LUFS
+    Threshold: -22.5 LUFS
+
+  True peak:
+    Peak:       -0.3 dBFS`
+	res, err := parseFFmpegSummary(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Integrated != -12.4 {
+		t.Fatalf("integrated = %v", res.Integrated)
+	}
+	if math.Abs(res.TruePeakDB()+0.3) > 1e-9 {
+		t.Fatalf("peak = %v dBTP", res.TruePeakDB())
+	}
+
+	if _, err := parseFFmpegSummary("Summary:\n    I:         -inf LUFS\n"); !errors.Is(err, ErrNoSignal) {
+		t.Fatalf("silent summary err = %v", err)
+	}
+}
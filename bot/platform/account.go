@@ -18,6 +18,9 @@ type AccountStatus struct {
 	CanRenewCookie  bool
 	SupportedLogins []string
 	ExpiresAt       *time.Time
+	// Account and Pool are set for members of a multi-account platform.
+	Account string
+	Pool    *PoolAccountHealth
 }
 
 type AccountStatusProvider interface {
@@ -0,0 +1,31 @@
+package platform
+
+import (
+	"context"
+	"sort"
+)
+
+// ArtistReleaseProvider is an optional interface for platforms that can list an
+// artist's albums and singles. The bot polls it to announce new releases to
+// chats that follow the artist.
+type ArtistReleaseProvider interface {
+	// GetArtistReleases returns up to limit of the artist's releases, newest
+	// first. Each Album should carry ID, Title and, when known, ReleaseDate,
+	// CoverURL and URL. The album ID must be accepted by GetPlaylist when wrapped
+	// with EncodeAlbumCollectionID so the bot can offer track downloads.
+	//
+	// Returns ErrNotFound if the artist does not exist.
+	GetArtistReleases(ctx context.Context, artistID string, limit int) ([]Album, error)
+}
+
+// SortReleasesNewestFirst orders releases by release date, newest first.
+// Releases without a date keep their relative order after dated ones.
+func SortReleasesNewestFirst(releases []Album) {
+	sort.SliceStable(releases, func(i, j int) bool {
+		left, right := releases[i].ReleaseDate, releases[j].ReleaseDate
+		if left == nil || right == nil {
+			return left != nil && right == nil
+		}
+		return left.After(*right)
+	})
+}
@@ -0,0 +1,42 @@
+package platform
+
+import "context"
+
+// ArtworkProvider is an optional interface for platforms whose image CDN
+// serves a cover at any size through its URL. The bot uses it to embed or
+// send covers larger than the default CoverURL.
+type ArtworkProvider interface {
+	// CoverURLAtSize rewrites coverURL, as returned in Track.CoverURL or
+	// Album.CoverURL, to a variant whose longest edge is at most size pixels.
+	// size 0 asks for the original, largest variant. URLs the platform does
+	// not recognise are returned unchanged.
+	CoverURLAtSize(coverURL string, size int) string
+}
+
+// AnimatedArtwork is a motion cover: an HLS or MP4 video loop.
+type AnimatedArtwork struct {
+	URL    string
+	Width  int
+	Height int
+}
+
+// AnimatedArtworkProvider is an optional interface for platforms whose catalog
+// carries motion artwork for albums (Apple Music).
+type AnimatedArtworkProvider interface {
+	// GetAnimatedArtwork returns the album's motion cover.
+	//
+	// Returns ErrNotFound if the album has none.
+	GetAnimatedArtwork(ctx context.Context, albumID string) (*AnimatedArtwork, error)
+}
+
+// CoverURLAtSize returns coverURL resized through p when it implements
+// ArtworkProvider, and coverURL unchanged otherwise.
+func CoverURLAtSize(p Platform, coverURL string, size int) string {
+	if coverURL == "" {
+		return ""
+	}
+	if provider, ok := As[ArtworkProvider](p); ok {
+		return provider.CoverURLAtSize(coverURL, size)
+	}
+	return coverURL
+}
@@ -0,0 +1,50 @@
+package platform
+
+import (
+	"context"
+	"strings"
+)
+
+// Chart describes one browsable chart/toplist published by a platform
+// (e.g. NetEase 飙升榜, QQ 巅峰榜, Spotify Top 50).
+type Chart struct {
+	// ID is the platform-specific chart identifier accepted by GetChart.
+	ID string `json:"id"`
+
+	// Platform is the source platform name.
+	Platform string `json:"platform"`
+
+	// Title is the chart display name.
+	Title string `json:"title"`
+
+	// Description is a short human-readable description (optional).
+	Description string `json:"description,omitempty"`
+}
+
+// ChartProvider is an optional interface for platforms that publish charts.
+// A chart is returned as a Playlist so the bot can reuse the collection
+// pagination and download buttons; implementations should honor
+// WithPlaylistOffset / WithPlaylistLimit in the same way GetPlaylist does.
+type ChartProvider interface {
+	// ListCharts returns the charts available on this platform, in display order.
+	ListCharts(ctx context.Context) ([]Chart, error)
+
+	// GetChart loads a chart by ID.
+	//
+	// Returns ErrNotFound if the chart ID is unknown.
+	GetChart(ctx context.Context, chartID string) (*Playlist, error)
+}
+
+// FindChart looks up a chart by ID within a static chart list.
+func FindChart(charts []Chart, chartID string) (Chart, bool) {
+	chartID = strings.TrimSpace(chartID)
+	if chartID == "" {
+		return Chart{}, false
+	}
+	for _, chart := range charts {
+		if chart.ID == chartID {
+			return chart, true
+		}
+	}
+	return Chart{}, false
+}
@@ -0,0 +1,107 @@
+package platform
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+)
+
+// SplitCredits splits a joined credit string such as "A / B、C & D" into
+// individual names, dropping blanks and duplicates.
+func SplitCredits(s string) []string {
+	fields := strings.FieldsFunc(s, func(r rune) bool {
+		switch r {
+		case '/', ',', '，', '、', ';', '；', '&', '＆', '|':
+			return true
+		}
+		return false
+	})
+	var out []string
+	seen := make(map[string]bool, len(fields))
+	for _, field := range fields {
+		name := strings.TrimSpace(field)
+		if name == "" || seen[strings.ToLower(name)] {
+			continue
+		}
+		seen[strings.ToLower(name)] = true
+		out = append(out, name)
+	}
+	return out
+}
+
+// creditScanLines bounds how far into a lyric credit lines are looked for;
+// they are always at the top.
+const creditScanLines = 20
+
+var (
+	lrcTimestampPrefix = regexp.MustCompile(`^(\[[^\]]*\])+`)
+	lyricistLabels     = []string{"作词", "作詞", "填词", "填詞", "词", "詞", "lyrics by", "lyricist", "lyrics"}
+	composerLabels     = []string{"作曲", "曲", "composed by", "composer", "music by", "music"}
+)
+
+// ParseLyricCredits reads the "作词 : A" / "作曲 : B" header lines Chinese
+// platforms put at the top of their LRC lyrics. Lines in NetEase's JSON
+// header format are understood too.
+func ParseLyricCredits(lyrics string) (lyricists, composers []string) {
+	lines := strings.Split(lyrics, "\n")
+	if len(lines) > creditScanLines {
+		lines = lines[:creditScanLines]
+	}
+	for _, line := range lines {
+		label, value, ok := splitCreditLine(creditLineText(line))
+		if !ok {
+			continue
+		}
+		switch {
+		case lyricists == nil && matchesCreditLabel(label, lyricistLabels):
+			lyricists = SplitCredits(value)
+		case composers == nil && matchesCreditLabel(label, composerLabels):
+			composers = SplitCredits(value)
+		}
+	}
+	return lyricists, composers
+}
+
+// creditLineText strips LRC timestamps, or flattens a JSON header line.
+func creditLineText(line string) string {
+	line = strings.TrimSpace(line)
+	if strings.HasPrefix(line, "{") {
+		var header struct {
+			C []struct {
+				Tx string `json:"tx"`
+			} `json:"c"`
+		}
+		if json.Unmarshal([]byte(line), &header) != nil {
+			return ""
+		}
+		var sb strings.Builder
+		for _, part := range header.C {
+			sb.WriteString(part.Tx)
+		}
+		return sb.String()
+	}
+	return strings.TrimSpace(lrcTimestampPrefix.ReplaceAllString(line, ""))
+}
+
+func splitCreditLine(text string) (label, value string, ok bool) {
+	i := strings.IndexAny(text, ":：")
+	if i <= 0 {
+		return "", "", false
+	}
+	sep := 1
+	if strings.HasPrefix(text[i:], "：") {
+		sep = len("：")
+	}
+	label = strings.ToLower(strings.TrimSpace(text[:i]))
+	value = strings.TrimSpace(text[i+sep:])
+	return label, value, value != ""
+}
+
+func matchesCreditLabel(label string, labels []string) bool {
+	for _, candidate := range labels {
+		if label == candidate {
+			return true
+		}
+	}
+	return false
+}
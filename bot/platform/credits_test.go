@@ -0,0 +1,55 @@
+package platform
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitCredits(t *testing.T) {
+	got := SplitCredits(" 周杰伦 / 方文山、Vincent Fang & 周杰伦 ,, ")
+	want := []string{"周杰伦", "方文山", "Vincent Fang"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("SplitCredits() = %#v, want %#v", got, want)
+	}
+	if got := SplitCredits(" "); got != nil {
+		t.Fatalf("SplitCredits(blank) = %#v", got)
+	}
+}
+
+func TestParseLyricCredits(t *testing.T) {
+	for _, tt := range []struct {
+		name                 string
+		lyrics               string
+		lyricists, composers []string
+	}{
+		{
+			name:      "lrc header",
+			lyrics:    "[00:00.000] 作词 : 方文山\n[00:01.000] 作曲 : 周杰伦\n[00:02.000] 编曲 : 林迈可\n[00:20.00]故事的小黄花",
+			lyricists: []string{"方文山"},
+			composers: []string{"周杰伦"},
+		},
+		{
+			name:      "qq header",
+			lyrics:    "[ti:晴天]\n[00:00.00]晴天 - 周杰伦\n[00:01.00]词：周杰伦\n[00:02.00]曲：周杰伦/Someone",
+			lyricists: []string{"周杰伦"},
+			composers: []string{"周杰伦", "Someone"},
+		},
+		{
+			name:      "netease json header",
+			lyrics:    `{"t":0,"c":[{"tx":"作词: "},{"tx":"A","li":"x"},{"tx":"/"},{"tx":"B"}]}` + "\n" + `{"t":1000,"c":[{"tx":"Composed by: "},{"tx":"C"}]}`,
+			lyricists: []string{"A", "B"},
+			composers: []string{"C"},
+		},
+		{
+			name:   "no credits",
+			lyrics: "[00:10.00]时间：凌晨三点\n[00:20.00]la la",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			lyricists, composers := ParseLyricCredits(tt.lyrics)
+			if !reflect.DeepEqual(lyricists, tt.lyricists) || !reflect.DeepEqual(composers, tt.composers) {
+				t.Fatalf("ParseLyricCredits() = %#v, %#v; want %#v, %#v", lyricists, composers, tt.lyricists, tt.composers)
+			}
+		})
+	}
+}
@@ -75,6 +75,54 @@ func (m *DefaultManager) Register(platform Platform) {
 	}
 }
 
+// ReplaceProvider swaps a registered provider for another one of the same
+// name, keeping its position among the providers, and rebuilds the name's
+// metadata and aliases. It reports false when old is not registered. Used to
+// reload a single script plugin without resetting every platform.
+func (m *DefaultManager) ReplaceProvider(old, replacement Platform) bool {
+	if old == nil || replacement == nil || old.Name() != replacement.Name() {
+		return false
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	name := old.Name()
+	list := m.providers[name]
+	index := -1
+	for i, p := range list {
+		if p == old {
+			index = i
+			break
+		}
+	}
+	if index < 0 {
+		return false
+	}
+	list[index] = replacement
+	delete(m.composites, name)
+
+	var meta Meta
+	for i, p := range list {
+		if i == 0 {
+			meta = buildMeta(p, name)
+			continue
+		}
+		meta = mergeMeta(meta, buildMeta(p, name))
+	}
+	m.meta[name] = meta
+	for alias, target := range m.aliases {
+		if target == name {
+			delete(m.aliases, alias)
+		}
+	}
+	m.indexAliases(meta)
+
+	if index == 0 && m.registry != nil {
+		_ = m.registry.Replace(&platformWrapper{platform: replacement})
+	}
+	return true
+}
+
 // Reset clears all registered providers and metadata.
 //
 // 清空前先关闭实现了 io.Closer 的 provider（如持有后台 Cookie 自动续期守护协程的
@@ -134,11 +182,23 @@ func closeProviders(providers []Platform) {
 // Get retrieves a platform by name.
 // If multiple providers are registered for the same name, returns a composite
 // platform that tries providers in registration order with automatic fallback.
+// A "name@account" form returns that single account of an AccountPool.
 // Returns nil if no platform with that name is registered.
 func (m *DefaultManager) Get(name string) Platform {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
+	if base, label, ok := strings.Cut(name, AccountSeparator); ok {
+		for _, p := range m.providers[base] {
+			if pool, ok := p.(*AccountPool); ok {
+				if account, ok := pool.Account(label); ok {
+					return account
+				}
+			}
+		}
+		return nil
+	}
+
 	providers, ok := m.providers[name]
 	if !ok || len(providers) == 0 {
 		return nil
@@ -440,7 +500,7 @@ func (m *DefaultManager) MatchText(text string) (platformName, trackID string, m
 		if platform == nil {
 			continue
 		}
-		if matcher, ok := platform.(TextMatcher); ok {
+		if matcher, ok := As[TextMatcher](platform); ok {
 			if id, ok := matcher.MatchText(text); ok {
 				return name, id, true
 			}
@@ -519,7 +579,7 @@ func (m *DefaultManager) indexAliases(meta Meta) {
 
 func buildMeta(platform Platform, name string) Meta {
 	meta := Meta{}
-	if provider, ok := platform.(MetadataProvider); ok {
+	if provider, ok := As[MetadataProvider](platform); ok {
 		meta = provider.Metadata()
 	}
 	if meta.Name == "" {
@@ -700,7 +760,7 @@ func (w *platformWrapper) Name() string {
 // If the underlying platform implements URLMatcher, it delegates to that.
 // Otherwise, it returns false (no match).
 func (w *platformWrapper) MatchURL(url string) (string, bool) {
-	if matcher, ok := w.platform.(URLMatcher); ok {
+	if matcher, ok := As[URLMatcher](w.platform); ok {
 		return matcher.MatchURL(url)
 	}
 	return "", false
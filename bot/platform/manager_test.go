@@ -1228,3 +1228,41 @@ func TestManager_InvalidatesCompositeCacheOnRegister(t *testing.T) {
 		t.Fatal("expected composite cache invalidation on register")
 	}
 }
+
+func TestManager_ReplaceProvider(t *testing.T) {
+	reg := registry.New()
+	manager := NewManagerWithRegistry(reg)
+
+	old := &mockPlatform{
+		name: "test-platform",
+		matchURLFunc: func(url string) (string, bool) {
+			return "old", url == "https://example.com/old"
+		},
+	}
+	replacement := &mockPlatform{
+		name: "test-platform",
+		matchURLFunc: func(url string) (string, bool) {
+			return "new", url == "https://example.com/new"
+		},
+	}
+	manager.Register(old)
+
+	if manager.ReplaceProvider(&mockPlatform{name: "test-platform"}, replacement) {
+		t.Fatal("replacing an unregistered provider should fail")
+	}
+	if !manager.ReplaceProvider(old, replacement) {
+		t.Fatal("expected replacement to succeed")
+	}
+	if got := manager.Get("test-platform"); got != replacement {
+		t.Fatalf("Get returned %v, want replacement", got)
+	}
+	if _, _, matched := manager.MatchURL("https://example.com/old"); matched {
+		t.Error("old provider should no longer match URLs")
+	}
+	if _, trackID, matched := manager.MatchURL("https://example.com/new"); !matched || trackID != "new" {
+		t.Errorf("expected replacement to match, got %q, %v", trackID, matched)
+	}
+	if names := manager.List(); len(names) != 1 {
+		t.Errorf("expected a single platform, got %v", names)
+	}
+}
@@ -3,6 +3,7 @@ package plugins
 import (
 	"fmt"
 	"sort"
+	"strings"
 	"sync"
 
 	"github.com/liuran001/MusicBot-Go/bot"
@@ -27,9 +28,14 @@ type Contribution struct {
 // Factory creates a plugin contribution based on config and logger.
 type Factory func(cfg *config.Config, logger *logpkg.Logger) (*Contribution, error)
 
+// KindFactory builds a plugin whose behaviour is declared by its config
+// section instead of code. name is the section's plugin name.
+type KindFactory func(name string, cfg *config.Config, logger *logpkg.Logger) (*Contribution, error)
+
 var (
 	mu        sync.RWMutex
 	factories = make(map[string]Factory)
+	kinds     = make(map[string]KindFactory)
 )
 
 // Register registers a plugin factory by name.
@@ -68,3 +74,45 @@ func Names() []string {
 	sort.Strings(nameList)
 	return nameList
 }
+
+// RegisterKind registers a factory for config-declared plugins, selected by
+// `type = <kind>` in their [plugins.<name>] section.
+func RegisterKind(kind string, factory KindFactory) error {
+	if kind == "" {
+		return fmt.Errorf("plugin kind required")
+	}
+	if factory == nil {
+		return fmt.Errorf("plugin factory required")
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if _, exists := kinds[kind]; exists {
+		return fmt.Errorf("plugin kind %s already registered", kind)
+	}
+	kinds[kind] = factory
+	return nil
+}
+
+// Lookup returns the factory for a configured plugin: the compiled plugin of
+// that name, or else the kind named by the section's `type` key.
+func Lookup(cfg *config.Config, name string) (Factory, bool) {
+	if factory, ok := Get(name); ok {
+		return factory, true
+	}
+	if cfg == nil {
+		return nil, false
+	}
+	kind := strings.ToLower(strings.TrimSpace(cfg.GetPluginString(name, "type")))
+	if kind == "" {
+		return nil, false
+	}
+	mu.RLock()
+	factory, ok := kinds[kind]
+	mu.RUnlock()
+	if !ok {
+		return nil, false
+	}
+	return func(cfg *config.Config, logger *logpkg.Logger) (*Contribution, error) {
+		return factory(name, cfg, logger)
+	}, true
+}
@@ -0,0 +1,420 @@
+package platform
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+// AccountSeparator joins a platform name and an account label, e.g.
+// "netease@alt". Manager.Get resolves such names to a single pool member so
+// /login and cookie commands can target one account.
+const AccountSeparator = "@"
+
+// PrimaryAccount is the label of the account configured in the plugin's own
+// section; extra accounts use their section suffix as label.
+const PrimaryAccount = "default"
+
+// PoolStrategy selects which healthy account serves the next request.
+type PoolStrategy string
+
+const (
+	// PoolRoundRobin rotates through the healthy accounts in order.
+	PoolRoundRobin PoolStrategy = "round_robin"
+	// PoolLeastThrottled prefers the account whose last rate limit or auth
+	// failure is the oldest, spreading load away from recently hot accounts.
+	PoolLeastThrottled PoolStrategy = "least_throttled"
+)
+
+// DefaultPoolCooldown is how long an account sits out after it returned
+// ErrRateLimited or ErrAuthRequired.
+const DefaultPoolCooldown = 10 * time.Minute
+
+// ParsePoolStrategy maps a config value to a strategy, defaulting to round robin.
+func ParsePoolStrategy(raw string) PoolStrategy {
+	switch strings.ToLower(strings.TrimSpace(strings.ReplaceAll(raw, "-", "_"))) {
+	case string(PoolLeastThrottled), "least_recently_throttled", "lrt":
+		return PoolLeastThrottled
+	default:
+		return PoolRoundRobin
+	}
+}
+
+// PoolAccount is one credential set of a pooled platform: a separate instance
+// of the same plugin built from its own config section.
+type PoolAccount struct {
+	Label    string
+	Platform Platform
+	// VIP marks accounts able to fetch lossless and better streams. Requests
+	// for those qualities try VIP accounts first.
+	VIP bool
+}
+
+// PoolAccountHealth is a snapshot of one pool member for /status.
+type PoolAccountHealth struct {
+	Label            string
+	VIP              bool
+	Strategy         PoolStrategy
+	QuarantinedUntil time.Time
+	LastThrottled    time.Time
+	LastError        string
+	Requests         int64
+	Failures         int64
+}
+
+// Quarantined reports whether the account was sitting out at the snapshot time.
+func (h PoolAccountHealth) Quarantined(now time.Time) bool {
+	return now.Before(h.QuarantinedUntil)
+}
+
+// Unwrapper is implemented by wrappers that serve a platform through other
+// instances. Optional interfaces are looked up on the wrapped platform.
+type Unwrapper interface {
+	Unwrap() Platform
+}
+
+// AccountPoolReporter is implemented by platforms served from several
+// accounts. PoolStatus returns one entry per account, primary first, with
+// Account and Pool set and the login fields taken from that account.
+type AccountPoolReporter interface {
+	PoolStatus(ctx context.Context) []AccountStatus
+}
+
+// As reports whether p, or a platform it wraps, implements T. Use it instead
+// of a plain type assertion when probing optional interfaces, so pooled
+// platforms keep the behaviour of their primary account.
+func As[T any](p Platform) (T, bool) {
+	for p != nil {
+		if v, ok := p.(T); ok {
+			return v, true
+		}
+		u, ok := p.(Unwrapper)
+		if !ok {
+			break
+		}
+		p = u.Unwrap()
+	}
+	var zero T
+	return zero, false
+}
+
+type poolMember struct {
+	PoolAccount
+	quarantinedUntil time.Time
+	lastThrottled    time.Time
+	lastError        string
+	requests         int64
+	failures         int64
+}
+
+// AccountPool serves one platform from several accounts. Core Platform calls
+// go to a healthy account picked by the strategy; an account answering with
+// ErrRateLimited or ErrAuthRequired is quarantined for the cooldown and the
+// call is retried on the next one. Optional interfaces (login, cookie checks,
+// URL matching) stay with the primary account and are reached through As.
+type AccountPool struct {
+	name     string
+	strategy PoolStrategy
+	cooldown time.Duration
+	now      func() time.Time
+
+	mu      sync.Mutex
+	members []*poolMember
+	next    int
+}
+
+// NewAccountPool builds a pool. The first account is the primary one; all
+// accounts must report the same platform name.
+func NewAccountPool(strategy PoolStrategy, cooldown time.Duration, accounts ...PoolAccount) (*AccountPool, error) {
+	if len(accounts) == 0 || accounts[0].Platform == nil {
+		return nil, fmt.Errorf("account pool: primary account required")
+	}
+	if cooldown <= 0 {
+		cooldown = DefaultPoolCooldown
+	}
+	name := accounts[0].Platform.Name()
+	pool := &AccountPool{
+		name:     name,
+		strategy: strategy,
+		cooldown: cooldown,
+		now:      time.Now,
+	}
+	seen := make(map[string]struct{}, len(accounts))
+	for _, account := range accounts {
+		if account.Platform == nil {
+			continue
+		}
+		if account.Platform.Name() != name {
+			return nil, fmt.Errorf("account pool %s: account %q serves %s", name, account.Label, account.Platform.Name())
+		}
+		if account.Label == "" {
+			account.Label = PrimaryAccount
+		}
+		if _, ok := seen[account.Label]; ok {
+			return nil, fmt.Errorf("account pool %s: duplicate account %q", name, account.Label)
+		}
+		seen[account.Label] = struct{}{}
+		pool.members = append(pool.members, &poolMember{PoolAccount: account})
+	}
+	return pool, nil
+}
+
+// Unwrap returns the primary account's platform.
+func (p *AccountPool) Unwrap() Platform {
+	return p.members[0].Platform
+}
+
+// Account returns the platform of the account with the given label.
+func (p *AccountPool) Account(label string) (Platform, bool) {
+	for _, m := range p.members {
+		if strings.EqualFold(m.Label, label) {
+			return m.Platform, true
+		}
+	}
+	return nil, false
+}
+
+// Health returns a snapshot of every account, primary first.
+func (p *AccountPool) Health() []PoolAccountHealth {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	out := make([]PoolAccountHealth, 0, len(p.members))
+	for _, m := range p.members {
+		out = append(out, PoolAccountHealth{
+			Label:            m.Label,
+			VIP:              m.VIP,
+			Strategy:         p.strategy,
+			QuarantinedUntil: m.quarantinedUntil,
+			LastThrottled:    m.lastThrottled,
+			LastError:        m.lastError,
+			Requests:         m.requests,
+			Failures:         m.failures,
+		})
+	}
+	return out
+}
+
+// PoolStatus implements AccountPoolReporter.
+func (p *AccountPool) PoolStatus(ctx context.Context) []AccountStatus {
+	health := p.Health()
+	statuses := make([]AccountStatus, 0, len(health))
+	for i, h := range health {
+		status := AccountStatus{Platform: p.name}
+		if provider, ok := As[AccountStatusProvider](p.members[i].Platform); ok {
+			if got, err := provider.AccountStatus(ctx); err == nil {
+				status = got
+			} else {
+				status.Summary = err.Error()
+			}
+		}
+		status.Platform = p.name
+		status.Account = h.Label
+		snapshot := h
+		status.Pool = &snapshot
+		statuses = append(statuses, status)
+	}
+	return statuses
+}
+
+// Close closes every account implementing io.Closer.
+func (p *AccountPool) Close() error {
+	var errs []error
+	for _, m := range p.members {
+		if closer, ok := m.Platform.(io.Closer); ok {
+			if err := closer.Close(); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// pick returns the next account to try, skipping those already tried. Healthy
+// accounts come first (VIP ones when wantVIP); when every remaining account is
+// quarantined, the one whose cooldown ends soonest is used rather than failing.
+func (p *AccountPool) pick(tried map[*poolMember]bool, wantVIP bool) *poolMember {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	now := p.now()
+	n := len(p.members)
+	var best, fallback *poolMember
+	bestIndex := -1
+	for offset := 0; offset < n; offset++ {
+		index := (p.next + offset) % n
+		m := p.members[index]
+		if tried[m] {
+			continue
+		}
+		if now.Before(m.quarantinedUntil) {
+			if fallback == nil || m.quarantinedUntil.Before(fallback.quarantinedUntil) {
+				fallback = m
+			}
+			continue
+		}
+		if best == nil || p.prefer(m, best, wantVIP) {
+			best, bestIndex = m, index
+		}
+	}
+	if best == nil {
+		return fallback
+	}
+	p.next = (bestIndex + 1) % n
+	return best
+}
+
+// prefer reports whether candidate beats current. Candidates are visited in
+// rotation order, so ties keep the earlier (round robin) one.
+func (p *AccountPool) prefer(candidate, current *poolMember, wantVIP bool) bool {
+	if wantVIP && candidate.VIP != current.VIP {
+		return candidate.VIP
+	}
+	if p.strategy == PoolLeastThrottled {
+		return candidate.lastThrottled.Before(current.lastThrottled)
+	}
+	return false
+}
+
+func (p *AccountPool) record(m *poolMember, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	m.requests++
+	if err == nil {
+		m.quarantinedUntil = time.Time{}
+		return
+	}
+	if !isAccountError(err) {
+		return
+	}
+	now := p.now()
+	m.failures++
+	m.lastThrottled = now
+	m.quarantinedUntil = now.Add(p.cooldown)
+	m.lastError = err.Error()
+}
+
+// isAccountError reports errors caused by the account rather than the request.
+func isAccountError(err error) bool {
+	return errors.Is(err, ErrRateLimited) || errors.Is(err, ErrAuthRequired)
+}
+
+func (p *AccountPool) do(ctx context.Context, wantVIP bool, call func(Platform) error) error {
+	tried := make(map[*poolMember]bool, len(p.members))
+	var lastErr error
+	for len(tried) < len(p.members) {
+		m := p.pick(tried, wantVIP)
+		if m == nil {
+			break
+		}
+		tried[m] = true
+		err := call(m.Platform)
+		p.record(m, err)
+		if err == nil || !isAccountError(err) || ctx.Err() != nil {
+			return err
+		}
+		lastErr = err
+	}
+	return lastErr
+}
+
+func (p *AccountPool) Name() string { return p.name }
+
+func (p *AccountPool) SupportsDownload() bool { return p.Unwrap().SupportsDownload() }
+
+func (p *AccountPool) SupportsSearch() bool { return p.Unwrap().SupportsSearch() }
+
+func (p *AccountPool) SupportsLyrics() bool { return p.Unwrap().SupportsLyrics() }
+
+func (p *AccountPool) SupportsRecognition() bool { return p.Unwrap().SupportsRecognition() }
+
+func (p *AccountPool) Capabilities() Capabilities { return p.Unwrap().Capabilities() }
+
+func (p *AccountPool) GetDownloadInfo(ctx context.Context, trackID string, quality Quality) (*DownloadInfo, error) {
+	var info *DownloadInfo
+	err := p.do(ctx, quality >= QualityLossless, func(plat Platform) error {
+		var err error
+		info, err = plat.GetDownloadInfo(ctx, trackID, quality)
+		return err
+	})
+	return info, err
+}
+
+func (p *AccountPool) Search(ctx context.Context, query string, limit int) ([]Track, error) {
+	var tracks []Track
+	err := p.do(ctx, false, func(plat Platform) error {
+		var err error
+		tracks, err = plat.Search(ctx, query, limit)
+		return err
+	})
+	return tracks, err
+}
+
+func (p *AccountPool) GetLyrics(ctx context.Context, trackID string) (*Lyrics, error) {
+	var lyrics *Lyrics
+	err := p.do(ctx, false, func(plat Platform) error {
+		var err error
+		lyrics, err = plat.GetLyrics(ctx, trackID)
+		return err
+	})
+	return lyrics, err
+}
+
+func (p *AccountPool) RecognizeAudio(ctx context.Context, audioData io.Reader) (*Track, error) {
+	// Buffered like compositePlatform so a retry on another account can
+	// re-read the sample.
+	data, err := io.ReadAll(io.LimitReader(audioData, maxRecognitionAudioBytes))
+	if err != nil {
+		return nil, fmt.Errorf("read audio data: %w", err)
+	}
+	var track *Track
+	err = p.do(ctx, false, func(plat Platform) error {
+		var err error
+		track, err = plat.RecognizeAudio(ctx, bytes.NewReader(data))
+		return err
+	})
+	return track, err
+}
+
+func (p *AccountPool) GetTrack(ctx context.Context, trackID string) (*Track, error) {
+	var track *Track
+	err := p.do(ctx, false, func(plat Platform) error {
+		var err error
+		track, err = plat.GetTrack(ctx, trackID)
+		return err
+	})
+	return track, err
+}
+
+func (p *AccountPool) GetArtist(ctx context.Context, artistID string) (*Artist, error) {
+	var artist *Artist
+	err := p.do(ctx, false, func(plat Platform) error {
+		var err error
+		artist, err = plat.GetArtist(ctx, artistID)
+		return err
+	})
+	return artist, err
+}
+
+func (p *AccountPool) GetAlbum(ctx context.Context, albumID string) (*Album, error) {
+	var album *Album
+	err := p.do(ctx, false, func(plat Platform) error {
+		var err error
+		album, err = plat.GetAlbum(ctx, albumID)
+		return err
+	})
+	return album, err
+}
+
+func (p *AccountPool) GetPlaylist(ctx context.Context, playlistID string) (*Playlist, error) {
+	var playlist *Playlist
+	err := p.do(ctx, false, func(plat Platform) error {
+		var err error
+		playlist, err = plat.GetPlaylist(ctx, playlistID)
+		return err
+	})
+	return playlist, err
+}
@@ -0,0 +1,183 @@
+package platform
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/liuran001/MusicBot-Go/bot/platform/registry"
+)
+
+type accountPlatform struct {
+	*mockPlatform
+	label string
+}
+
+func (a *accountPlatform) AccountStatus(ctx context.Context) (AccountStatus, error) {
+	return AccountStatus{LoggedIn: true, Nickname: a.label}, nil
+}
+
+func newPoolAccount(label string, vip bool, calls *[]string, err func() error) PoolAccount {
+	plat := &accountPlatform{label: label, mockPlatform: &mockPlatform{
+		name:             "music",
+		supportsDownload: true,
+		downloadInfoFunc: func(ctx context.Context, trackID string, quality Quality) (*DownloadInfo, error) {
+			*calls = append(*calls, label)
+			if e := err(); e != nil {
+				return nil, e
+			}
+			return &DownloadInfo{URL: label}, nil
+		},
+	}}
+	return PoolAccount{Label: label, Platform: plat, VIP: vip}
+}
+
+func TestAccountPoolRoundRobin(t *testing.T) {
+	var calls []string
+	ok := func() error { return nil }
+	pool, err := NewAccountPool(PoolRoundRobin, time.Minute,
+		newPoolAccount("a", false, &calls, ok),
+		newPoolAccount("b", false, &calls, ok),
+	)
+	if err != nil {
+		t.Fatalf("NewAccountPool: %v", err)
+	}
+	for i := 0; i < 4; i++ {
+		if _, err := pool.GetDownloadInfo(context.Background(), "1", QualityHigh); err != nil {
+			t.Fatalf("GetDownloadInfo: %v", err)
+		}
+	}
+	if got := strings.Join(calls, ","); got != "a,b,a,b" {
+		t.Fatalf("calls = %s, want a,b,a,b", got)
+	}
+}
+
+func TestAccountPoolQuarantinesThrottledAccount(t *testing.T) {
+	var calls []string
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	limited := func() error { return NewRateLimitedError("music") }
+	pool, _ := NewAccountPool(PoolRoundRobin, time.Minute,
+		newPoolAccount("a", false, &calls, limited),
+		newPoolAccount("b", false, &calls, func() error { return nil }),
+	)
+	pool.now = func() time.Time { return now }
+
+	info, err := pool.GetDownloadInfo(context.Background(), "1", QualityHigh)
+	if err != nil || info.URL != "b" {
+		t.Fatalf("expected retry on b, got %v %v", info, err)
+	}
+	calls = nil
+	for i := 0; i < 3; i++ {
+		_, _ = pool.GetDownloadInfo(context.Background(), "1", QualityHigh)
+	}
+	if got := strings.Join(calls, ","); got != "b,b,b" {
+		t.Fatalf("quarantined account used: %s", got)
+	}
+
+	health := pool.Health()
+	if !health[0].Quarantined(now) || health[0].Failures != 1 || health[1].Quarantined(now) {
+		t.Fatalf("unexpected health: %+v", health)
+	}
+
+	now = now.Add(2 * time.Minute)
+	calls = nil
+	_, _ = pool.GetDownloadInfo(context.Background(), "1", QualityHigh)
+	_, _ = pool.GetDownloadInfo(context.Background(), "1", QualityHigh)
+	if got := strings.Join(calls, ","); got != "a,b,b" {
+		t.Fatalf("after cooldown calls = %s, want a,b,b", got)
+	}
+}
+
+func TestAccountPoolStopsOnRequestErrors(t *testing.T) {
+	var calls []string
+	notFound := func() error { return NewNotFoundError("music", "track", "1") }
+	pool, _ := NewAccountPool(PoolRoundRobin, time.Minute,
+		newPoolAccount("a", false, &calls, notFound),
+		newPoolAccount("b", false, &calls, notFound),
+	)
+	_, err := pool.GetDownloadInfo(context.Background(), "1", QualityHigh)
+	if !errors.Is(err, ErrNotFound) || len(calls) != 1 {
+		t.Fatalf("expected a single attempt with ErrNotFound, got %v after %v", err, calls)
+	}
+	if health := pool.Health(); health[0].Failures != 0 {
+		t.Fatalf("request errors must not count against the account: %+v", health[0])
+	}
+}
+
+func TestAccountPoolPrefersVIPForLossless(t *testing.T) {
+	var calls []string
+	ok := func() error { return nil }
+	pool, _ := NewAccountPool(PoolRoundRobin, time.Minute,
+		newPoolAccount("a", false, &calls, ok),
+		newPoolAccount("b", true, &calls, ok),
+	)
+	_, _ = pool.GetDownloadInfo(context.Background(), "1", QualityLossless)
+	_, _ = pool.GetDownloadInfo(context.Background(), "1", QualityHiRes)
+	_, _ = pool.GetDownloadInfo(context.Background(), "1", QualityHigh)
+	if got := strings.Join(calls, ","); got != "b,b,a" {
+		t.Fatalf("calls = %s, want b,b,a", got)
+	}
+}
+
+func TestAccountPoolLeastThrottled(t *testing.T) {
+	var calls []string
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	failA, failB := true, true
+	pool, _ := NewAccountPool(PoolLeastThrottled, time.Second,
+		newPoolAccount("a", false, &calls, func() error {
+			if failA {
+				return NewAuthRequiredError("music")
+			}
+			return nil
+		}),
+		newPoolAccount("b", false, &calls, func() error {
+			if failB {
+				return NewAuthRequiredError("music")
+			}
+			return nil
+		}),
+	)
+	pool.now = func() time.Time {
+		now = now.Add(time.Millisecond)
+		return now
+	}
+	_, _ = pool.GetDownloadInfo(context.Background(), "1", QualityHigh) // a, then b throttled
+	failA, failB = false, false
+	now = now.Add(time.Minute)
+	calls = nil
+	_, _ = pool.GetDownloadInfo(context.Background(), "1", QualityHigh)
+	_, _ = pool.GetDownloadInfo(context.Background(), "1", QualityHigh)
+	if got := strings.Join(calls, ","); got != "a,a" {
+		t.Fatalf("calls = %s, want a,a (a throttled longest ago)", got)
+	}
+}
+
+func TestManagerResolvesPoolAccounts(t *testing.T) {
+	var calls []string
+	ok := func() error { return nil }
+	primary := newPoolAccount("default", false, &calls, ok)
+	alt := newPoolAccount("alt", false, &calls, ok)
+	pool, _ := NewAccountPool(PoolRoundRobin, 0, primary, alt)
+
+	mgr := NewManagerWithRegistry(registry.New())
+	mgr.Register(pool)
+	if got := mgr.Get("music@alt"); got != alt.Platform {
+		t.Fatalf("Get(music@alt) = %v", got)
+	}
+	if got := mgr.Get("music@missing"); got != nil {
+		t.Fatalf("unknown account should be nil, got %v", got)
+	}
+	provider, ok2 := As[AccountStatusProvider](mgr.Get("music"))
+	if !ok2 {
+		t.Fatal("optional interfaces should be reachable through the pool")
+	}
+	if status, _ := provider.AccountStatus(context.Background()); status.Nickname != "default" {
+		t.Fatalf("optional interfaces should use the primary account, got %q", status.Nickname)
+	}
+	statuses := pool.PoolStatus(context.Background())
+	if len(statuses) != 2 || statuses[1].Account != "alt" || statuses[1].Pool == nil || !statuses[1].LoggedIn {
+		t.Fatalf("unexpected pool status: %+v", statuses)
+	}
+}
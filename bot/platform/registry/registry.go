@@ -96,6 +96,31 @@ func (r *Registry) MatchURL(url string) (string, Platform, bool) {
 	return "", nil, false
 }
 
+// Replace swaps the registered platform of the same name in place, keeping its
+// position in the match order. Returns an error if no such platform exists.
+func (r *Registry) Replace(p Platform) error {
+	if p == nil {
+		return errors.New("platform cannot be nil")
+	}
+	name := p.Name()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.platforms[name]; !exists {
+		return fmt.Errorf("platform not registered: %s", name)
+	}
+	r.platforms[name] = p
+	for i, existing := range r.ordered {
+		if existing.Name() == name {
+			r.ordered[i] = p
+			break
+		}
+	}
+
+	return nil
+}
+
 // Reset clears all registered platforms.
 func (r *Registry) Reset() {
 	r.mu.Lock()
@@ -44,6 +44,17 @@ type Track struct {
 	// DiscNumber is the disc number within multi-disc album (if available).
 	DiscNumber int `json:"disc_number,omitempty"`
 
+	// ReleaseDate is the track's own release date when the platform gives one
+	// more precise than Year; Album.ReleaseDate is used otherwise.
+	ReleaseDate *time.Time `json:"release_date,omitempty"`
+
+	// Composers and Lyricists are the credited writers, one name per entry.
+	Composers []string `json:"composers,omitempty"`
+	Lyricists []string `json:"lyricists,omitempty"`
+
+	// Genres are the track's genres; Album.Genres applies when empty.
+	Genres []string `json:"genres,omitempty"`
+
 	// LyricsAvailable reports whether the platform explicitly says this track has
 	// lyrics. Nil means the platform did not provide this metadata.
 	LyricsAvailable *bool `json:"lyrics_available,omitempty"`
@@ -105,6 +116,15 @@ type Album struct {
 
 	// Year is the album release year (if available).
 	Year int `json:"year,omitempty"`
+
+	// Label is the record label or publisher (if available).
+	Label string `json:"label,omitempty"`
+
+	// Copyright is the copyright line, e.g. "℗ 2024 Label" (if available).
+	Copyright string `json:"copyright,omitempty"`
+
+	// Genres are the album's genres (if available).
+	Genres []string `json:"genres,omitempty"`
 }
 
 // Playlist represents a music playlist from any platform.
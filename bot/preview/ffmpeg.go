@@ -0,0 +1,91 @@
+package preview
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// envelopeRate is the sample rate audio is decoded at for the envelope;
+// loudness needs no treble.
+const envelopeRate = 8000
+
+// ErrNoFFmpeg is returned when ffmpeg is not installed.
+var ErrNoFFmpeg = errors.New("preview: ffmpeg not found")
+
+// Available reports whether clips can be made on this host.
+func Available() bool {
+	_, err := exec.LookPath("ffmpeg")
+	return err == nil
+}
+
+// Analyze decodes length of the audio file at path, starting at start, and
+// returns its energy envelope in BlockDuration steps. The file may be a
+// fragment of a stream; ffmpeg resyncs on the first complete frame.
+func Analyze(ctx context.Context, path string, start, length time.Duration) ([]float64, error) {
+	ffmpegPath, err := exec.LookPath("ffmpeg")
+	if err != nil {
+		return nil, ErrNoFFmpeg
+	}
+	cmd := exec.CommandContext(ctx, ffmpegPath, "-hide_banner", "-nostats", "-v", "error",
+		"-ss", seconds(start), "-i", path, "-map", "0:a:0", "-t", seconds(length),
+		"-ac", "1", "-ar", strconv.Itoa(envelopeRate), "-f", "s16le", "-")
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+	raw, readErr := io.ReadAll(bufio.NewReaderSize(stdout, 64*1024))
+	waitErr := cmd.Wait()
+	if readErr != nil {
+		return nil, readErr
+	}
+	samples := make([]int16, len(raw)/2)
+	for i := range samples {
+		samples[i] = int16(binary.LittleEndian.Uint16(raw[i*2:]))
+	}
+	// A truncated fragment makes ffmpeg exit non-zero after decoding what it
+	// could; only fail when nothing came out.
+	if len(samples) == 0 {
+		if waitErr != nil {
+			return nil, fmt.Errorf("ffmpeg decode: %w", waitErr)
+		}
+		return nil, errors.New("preview: no audio decoded")
+	}
+	return Envelope(samples, envelopeRate*int(BlockDuration)/int(time.Second)), nil
+}
+
+// Encode cuts length of audio starting at start from src into dst as a mono
+// OGG/Opus voice note, with short fades so the clip does not start or stop
+// mid-note.
+func Encode(ctx context.Context, src, dst string, start, length time.Duration) error {
+	ffmpegPath, err := exec.LookPath("ffmpeg")
+	if err != nil {
+		return ErrNoFFmpeg
+	}
+	fade := min(time.Second, length/4)
+	filter := fmt.Sprintf("afade=t=in:d=%s,afade=t=out:st=%s:d=%s", seconds(fade), seconds(length-fade), seconds(fade))
+	cmd := exec.CommandContext(ctx, ffmpegPath, "-hide_banner", "-nostats", "-v", "error", "-y",
+		"-ss", seconds(start), "-i", src, "-map", "0:a:0", "-vn", "-map_metadata", "-1", "-t", seconds(length),
+		"-af", filter, "-ac", "1", "-c:a", "libopus", "-b:a", "64k", "-f", "ogg", dst)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("encode preview: %w, stderr: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
+
+func seconds(d time.Duration) string {
+	return strconv.FormatFloat(max(d, 0).Seconds(), 'f', 3, 64)
+}
@@ -0,0 +1,109 @@
+// Package preview cuts short listening clips out of a track: it picks the
+// loudest stretch of the middle of the song as a stand-in for the chorus and
+// renders a text waveform of the result.
+package preview
+
+import (
+	"math"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultClipLength is the clip length used when none is configured.
+	DefaultClipLength = 30 * time.Second
+	// BlockDuration is the resolution of an energy envelope.
+	BlockDuration = 250 * time.Millisecond
+	// searchClips is how many clip lengths of audio are scanned for the chorus.
+	searchClips = 3
+)
+
+// waveformBars are the glyphs of a waveform, quietest first.
+var waveformBars = []rune("▁▂▃▄▅▆▇█")
+
+// Region returns the part of a track worth fetching for a clip of the given
+// length. A positive offset pins the clip there. Otherwise the region spans a
+// few clip lengths starting a quarter into the track, where the first chorus
+// usually lands; an unknown duration scans from the start.
+func Region(duration, clip, offset time.Duration) (start, end time.Duration) {
+	if clip <= 0 {
+		clip = DefaultClipLength
+	}
+	span := clip * searchClips
+	if offset > 0 {
+		start, span = offset, clip
+	} else if duration > 0 {
+		start = duration / 4
+	}
+	end = start + span
+	if duration > 0 && end > duration {
+		end = duration
+		start = max(0, min(start, end-clip))
+	}
+	return start, end
+}
+
+// Envelope returns the RMS level of each block of mono samples.
+func Envelope(samples []int16, blockSize int) []float64 {
+	if blockSize <= 0 {
+		return nil
+	}
+	env := make([]float64, 0, (len(samples)+blockSize-1)/blockSize)
+	for i := 0; i < len(samples); i += blockSize {
+		block := samples[i:min(i+blockSize, len(samples))]
+		var sum float64
+		for _, s := range block {
+			v := float64(s) / 32768
+			sum += v * v
+		}
+		env = append(env, math.Sqrt(sum/float64(len(block))))
+	}
+	return env
+}
+
+// LoudestWindow returns the first block of the window of the given number of
+// blocks with the most energy. Choruses are the loudest, densest part of most
+// songs, so this lands on one far more often than a fixed offset does.
+func LoudestWindow(env []float64, blocks int) int {
+	if blocks <= 0 || len(env) <= blocks {
+		return 0
+	}
+	var sum float64
+	for _, v := range env[:blocks] {
+		sum += v * v
+	}
+	best, bestSum := 0, sum
+	for i := blocks; i < len(env); i++ {
+		sum += env[i]*env[i] - env[i-blocks]*env[i-blocks]
+		if sum > bestSum+1e-12 {
+			best, bestSum = i-blocks+1, sum
+		}
+	}
+	return best
+}
+
+// Waveform renders env as width bars scaled to the loudest block.
+func Waveform(env []float64, width int) string {
+	if width <= 0 || len(env) == 0 {
+		return ""
+	}
+	width = min(width, len(env))
+	peaks := make([]float64, width)
+	var top float64
+	for i := range peaks {
+		lo, hi := i*len(env)/width, (i+1)*len(env)/width
+		for _, v := range env[lo:hi] {
+			peaks[i] = max(peaks[i], v)
+		}
+		top = max(top, peaks[i])
+	}
+	var b strings.Builder
+	for _, p := range peaks {
+		level := 0
+		if top > 0 {
+			level = int(math.Round(p / top * float64(len(waveformBars)-1)))
+		}
+		b.WriteRune(waveformBars[level])
+	}
+	return b.String()
+}
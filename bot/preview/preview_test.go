@@ -0,0 +1,68 @@
+package preview
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRegion(t *testing.T) {
+	const clip = 30 * time.Second
+	for _, tt := range []struct {
+		name               string
+		duration, offset   time.Duration
+		wantStart, wantEnd time.Duration
+	}{
+		{name: "quarter in", duration: 4 * time.Minute, wantStart: time.Minute, wantEnd: time.Minute + 90*time.Second},
+		{name: "short track", duration: 20 * time.Second, wantStart: 0, wantEnd: 20 * time.Second},
+		{name: "clamped to end", duration: 80 * time.Second, wantStart: 20 * time.Second, wantEnd: 80 * time.Second},
+		{name: "unknown duration", wantStart: 0, wantEnd: 90 * time.Second},
+		{name: "fixed offset", duration: 4 * time.Minute, offset: 45 * time.Second, wantStart: 45 * time.Second, wantEnd: 75 * time.Second},
+		{name: "offset past end", duration: time.Minute, offset: 2 * time.Minute, wantStart: 30 * time.Second, wantEnd: time.Minute},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			start, end := Region(tt.duration, clip, tt.offset)
+			if start != tt.wantStart || end != tt.wantEnd {
+				t.Fatalf("Region() = (%v, %v), want (%v, %v)", start, end, tt.wantStart, tt.wantEnd)
+			}
+		})
+	}
+}
+
+func TestEnvelope(t *testing.T) {
+	samples := []int16{16384, -16384, 16384, -16384, 0, 0, 8192}
+	env := Envelope(samples, 4)
+	if len(env) != 2 {
+		t.Fatalf("len(Envelope()) = %d, want 2", len(env))
+	}
+	if env[0] != 0.5 {
+		t.Fatalf("env[0] = %v, want 0.5", env[0])
+	}
+	if want := 0.25 / 1.7320508075688772; env[1] < want-1e-9 || env[1] > want+1e-9 {
+		t.Fatalf("env[1] = %v, want %v", env[1], want)
+	}
+}
+
+func TestLoudestWindow(t *testing.T) {
+	env := []float64{0.1, 0.1, 0.2, 0.9, 0.8, 0.9, 0.2, 0.9, 0.1}
+	if got := LoudestWindow(env, 3); got != 3 {
+		t.Fatalf("LoudestWindow() = %d, want 3", got)
+	}
+	if got := LoudestWindow(env, len(env)); got != 0 {
+		t.Fatalf("LoudestWindow() over the whole envelope = %d, want 0", got)
+	}
+	if got := LoudestWindow([]float64{0.5, 0.5, 0.5, 0.5}, 2); got != 0 {
+		t.Fatalf("LoudestWindow() on a flat envelope = %d, want the earliest window", got)
+	}
+}
+
+func TestWaveform(t *testing.T) {
+	if got := Waveform([]float64{0, 0.5, 1, 0.5, 0, 0.25, 1, 0}, 4); got != "▅█▃█" {
+		t.Fatalf("Waveform() = %q", got)
+	}
+	if got := Waveform([]float64{0, 0}, 8); got != "▁▁" {
+		t.Fatalf("Waveform() of silence = %q", got)
+	}
+	if got := Waveform(nil, 8); got != "" {
+		t.Fatalf("Waveform(nil) = %q", got)
+	}
+}
@@ -0,0 +1,402 @@
+// Package render evaluates the filename, caption and tag templates a chat can
+// set in /settings. Templates use Go template syntax restricted to actions and
+// conditionals over a small function set: loops and nested templates are
+// rejected, so a template runs in time proportional to its length, and the
+// output is capped.
+package render
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"html"
+	htmltemplate "html/template"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/template"
+	"text/template/parse"
+	"unicode/utf8"
+)
+
+const (
+	// MaxSourceLen bounds the length of a template source.
+	MaxSourceLen = 2048
+	// maxOutputLen bounds what a template may write.
+	maxOutputLen = 8192
+	// maxCaptionLen is the Bot API caption limit, counted after tags are
+	// stripped.
+	maxCaptionLen = 1024
+)
+
+// Default templates reproduce the output the bot used before templates were
+// configurable.
+const (
+	DefaultFilename = `{{replace .Artists "/" ","}} - {{.Title}}`
+	DefaultCaption  = `<b>「{{.TitleLink}}」- {{.ArtistLinks}}</b>
+{{if .Album}}{{.AlbumLabel}}{{.AlbumLink}}
+{{end}}<blockquote>{{if .Info}}{{.Info}}
+{{end}}{{.Hashtags}}
+</blockquote>via @{{.Bot}}`
+)
+
+// Tag template fields. An empty template keeps the value the platform
+// supplied.
+const (
+	TagTitle       = "title"
+	TagArtist      = "artist"
+	TagAlbum       = "album"
+	TagAlbumArtist = "albumartist"
+	TagDate        = "date"
+	TagGenre       = "genre"
+	TagComment     = "comment"
+	TagTrack       = "track"
+	TagDisc        = "disc"
+	TagComposer    = "composer"
+	TagLyricist    = "lyricist"
+	TagLabel       = "label"
+	TagISRC        = "isrc"
+)
+
+// TagFields lists the tag template fields in display order.
+var TagFields = []string{TagTitle, TagArtist, TagAlbum, TagAlbumArtist, TagDate, TagGenre, TagComment, TagTrack, TagDisc, TagComposer, TagLyricist, TagLabel, TagISRC}
+
+// IsTagField reports whether name is a tag template field.
+func IsTagField(name string) bool {
+	for _, field := range TagFields {
+		if field == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Track is the data every template sees. Fields the source does not know are
+// zero; captions of cached songs only carry what the cache stores.
+type Track struct {
+	Title       string
+	Artists     string
+	Album       string
+	AlbumArtist string
+	Year        string
+	Genre       string
+	TrackNumber int
+	DiscNumber  int
+	ReleaseDate string // YYYY-MM-DD
+	Composer    string
+	Lyricist    string
+	Label       string
+	ISRC        string
+	Platform    string
+	TrackID     string
+	Quality     string
+	Codec       string // upper-case codec or container, e.g. "FLAC"
+	Ext         string
+	SampleRate  int // Hz
+	BitDepth    int
+	Bitrate     int // bits per second
+	Size        int64
+	Duration    int // seconds
+	URL         string
+	AlbumURL    string
+}
+
+// Caption is the data of caption templates: the track plus the pieces of the
+// default caption, already rendered. The *Link fields are HTML; everything
+// else is escaped on output.
+type Caption struct {
+	Track
+	TitleLink   htmltemplate.HTML
+	ArtistLinks htmltemplate.HTML
+	AlbumLink   htmltemplate.HTML
+	AlbumLabel  string
+	Info        string // size, bitrate and loudness
+	Hashtags    string
+	Bot         string
+}
+
+// ErrForbidden reports a template construct outside the allowed subset.
+var ErrForbidden = errors.New("loops and nested templates are not allowed")
+
+var funcs = map[string]any{
+	"pad":      pad,
+	"upper":    func(s string) (string, error) { return capped(strings.ToUpper(s)) },
+	"lower":    func(s string) (string, error) { return capped(strings.ToLower(s)) },
+	"trim":     strings.TrimSpace,
+	"replace":  replace,
+	"truncate": truncate,
+	"default":  fallback,
+	"khz":      khz,
+	"kbps":     func(bps int) int { return (bps + 500) / 1000 },
+	"mb":       func(size int64) string { return strconv.FormatFloat(float64(size)/1024/1024, 'f', 2, 64) },
+}
+
+var errResultTooLong = errors.New("function result too long")
+
+// capped fails results longer than a template may write. limitWriter only
+// sees what reaches the output, so without this nested calls could build
+// arbitrarily large strings in memory first.
+func capped(s string) (string, error) {
+	if len(s) > maxOutputLen {
+		return "", errResultTooLong
+	}
+	return s, nil
+}
+
+// replace replaces every old in s with new. An empty old is rejected: it
+// would insert new between every character.
+func replace(s, old, new string) (string, error) {
+	if old == "" {
+		return "", errors.New("replace: empty search string")
+	}
+	if n := strings.Count(s, old); n > 0 && len(new) > len(old) && len(s)+n*(len(new)-len(old)) > maxOutputLen {
+		return "", errResultTooLong
+	}
+	return strings.ReplaceAll(s, old, new), nil
+}
+
+// pad zero-pads n to width digits: {{pad 2 .TrackNumber}} gives "01".
+func pad(width, n int) string {
+	width = min(max(width, 0), 8)
+	return fmt.Sprintf("%0*d", width, n)
+}
+
+// truncate shortens s to at most n characters.
+func truncate(n int, s string) string {
+	if n < 0 || utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	return string([]rune(s)[:n])
+}
+
+// fallback returns value unless it is empty: {{default "Unknown" .Album}}.
+func fallback(def string, value any) string {
+	switch v := value.(type) {
+	case nil:
+		return def
+	case string:
+		if strings.TrimSpace(v) == "" {
+			return def
+		}
+		return v
+	case int:
+		if v == 0 {
+			return def
+		}
+		return strconv.Itoa(v)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// khz formats a sample rate in kHz without trailing zeros: 44100 → "44.1".
+func khz(hz int) string {
+	return strconv.FormatFloat(float64(hz)/1000, 'f', -1, 64)
+}
+
+// checkTree rejects range loops, template calls and extra definitions.
+func checkTree(trees int, root *parse.ListNode) error {
+	if trees > 1 {
+		return ErrForbidden
+	}
+	var walk func(node parse.Node) error
+	walk = func(node parse.Node) error {
+		switch n := node.(type) {
+		case *parse.ListNode:
+			if n == nil {
+				return nil
+			}
+			for _, child := range n.Nodes {
+				if err := walk(child); err != nil {
+					return err
+				}
+			}
+		case *parse.IfNode:
+			if err := walk(n.List); err != nil {
+				return err
+			}
+			return walk(n.ElseList)
+		case *parse.WithNode:
+			if err := walk(n.List); err != nil {
+				return err
+			}
+			return walk(n.ElseList)
+		case *parse.RangeNode, *parse.TemplateNode, *parse.BreakNode, *parse.ContinueNode:
+			return ErrForbidden
+		}
+		return nil
+	}
+	return walk(root)
+}
+
+func checkSource(src string) error {
+	if len(src) > MaxSourceLen {
+		return fmt.Errorf("template longer than %d bytes", MaxSourceLen)
+	}
+	return nil
+}
+
+func parseText(src string) (*template.Template, error) {
+	if err := checkSource(src); err != nil {
+		return nil, err
+	}
+	tmpl, err := template.New("t").Funcs(funcs).Option("missingkey=error").Parse(src)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkTree(len(tmpl.Templates()), tmpl.Tree.Root); err != nil {
+		return nil, err
+	}
+	return tmpl, nil
+}
+
+func parseHTML(src string) (*htmltemplate.Template, error) {
+	// The text parse is only for the structural check; html/template keeps
+	// its own tree.
+	if _, err := parseText(src); err != nil {
+		return nil, err
+	}
+	return htmltemplate.New("t").Funcs(funcs).Option("missingkey=error").Parse(src)
+}
+
+// limitWriter fails writes past its budget.
+type limitWriter struct {
+	buf  bytes.Buffer
+	left int
+}
+
+var errOutputTooLong = errors.New("template output too long")
+
+func (w *limitWriter) Write(p []byte) (int, error) {
+	if len(p) > w.left {
+		return 0, errOutputTooLong
+	}
+	w.left -= len(p)
+	return w.buf.Write(p)
+}
+
+func execute(run func(io.Writer) error) (string, error) {
+	w := &limitWriter{left: maxOutputLen}
+	if err := run(w); err != nil {
+		return "", err
+	}
+	return w.buf.String(), nil
+}
+
+// Text renders a filename or tag template.
+func Text(src string, data Track) (string, error) {
+	tmpl, err := parseText(src)
+	if err != nil {
+		return "", err
+	}
+	return execute(func(w io.Writer) error { return tmpl.Execute(w, data) })
+}
+
+// HTML renders a caption template into Telegram HTML.
+func HTML(src string, data Caption) (string, error) {
+	tmpl, err := parseHTML(src)
+	if err != nil {
+		return "", err
+	}
+	return execute(func(w io.Writer) error { return tmpl.Execute(w, data) })
+}
+
+// Sample is the track templates are checked against when saved.
+var Sample = Track{
+	Title:       "Song",
+	Artists:     "Artist A/Artist B",
+	Album:       "Album",
+	AlbumArtist: "Artist A",
+	Year:        "2024",
+	Genre:       "Pop",
+	TrackNumber: 1,
+	DiscNumber:  1,
+	ReleaseDate: "2024-03-01",
+	Composer:    "Composer",
+	Lyricist:    "Lyricist",
+	Label:       "Label",
+	ISRC:        "USRC17607839",
+	Platform:    "netease",
+	TrackID:     "1",
+	Quality:     "lossless",
+	Codec:       "FLAC",
+	Ext:         "flac",
+	SampleRate:  44100,
+	BitDepth:    16,
+	Bitrate:     1411000,
+	Size:        31457280,
+	Duration:    180,
+	URL:         "https://example.com/song/1",
+	AlbumURL:    "https://example.com/album/1",
+}
+
+// telegramTags are the HTML tags the Bot API accepts in captions.
+var telegramTags = map[string]bool{
+	"b": true, "strong": true, "i": true, "em": true, "u": true, "ins": true,
+	"s": true, "strike": true, "del": true, "span": true, "tg-spoiler": true,
+	"a": true, "code": true, "pre": true, "blockquote": true, "tg-emoji": true,
+}
+
+var tagPattern = regexp.MustCompile(`</?([a-zA-Z][a-zA-Z0-9-]*)[^>]*>`)
+
+// ValidateFilename checks that src parses and renders a non-empty name.
+func ValidateFilename(src string) error {
+	out, err := Text(src, Sample)
+	if err != nil {
+		return err
+	}
+	if strings.TrimSpace(out) == "" {
+		return errors.New("filename renders empty")
+	}
+	return nil
+}
+
+// ValidateTag checks that src parses and renders; a track or disc template
+// must render a number.
+func ValidateTag(field, src string) error {
+	if !IsTagField(field) {
+		return fmt.Errorf("unknown tag field %q", field)
+	}
+	out, err := Text(src, Sample)
+	if err != nil {
+		return err
+	}
+	if field == TagTrack || field == TagDisc {
+		if _, err := strconv.Atoi(strings.TrimSpace(out)); err != nil {
+			return fmt.Errorf("%s must render a number", field)
+		}
+	}
+	return nil
+}
+
+// ValidateCaption checks that src parses, renders within the caption limit
+// and only uses tags Telegram understands.
+func ValidateCaption(src string) error {
+	out, err := HTML(src, Caption{
+		Track:       Sample,
+		TitleLink:   `<a href="https://example.com/song/1">Song</a>`,
+		ArtistLinks: "Artist A / Artist B",
+		AlbumLink:   "Album",
+		AlbumLabel:  "Album: ",
+		Info:        "30.00MB 1411kbps",
+		Hashtags:    "#netease #lossless #flac",
+		Bot:         "bot",
+	})
+	if err != nil {
+		return err
+	}
+	for _, m := range tagPattern.FindAllStringSubmatch(out, -1) {
+		if !telegramTags[strings.ToLower(m[1])] {
+			return fmt.Errorf("tag <%s> is not supported by Telegram", m[1])
+		}
+	}
+	text := tagPattern.ReplaceAllString(out, "")
+	if strings.TrimSpace(text) == "" {
+		return errors.New("caption renders empty")
+	}
+	if utf8.RuneCountInString(html.UnescapeString(text)) > maxCaptionLen {
+		return fmt.Errorf("caption longer than %d characters", maxCaptionLen)
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+package render
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestText(t *testing.T) {
+	track := Sample
+	track.TrackNumber = 3
+	for _, tt := range []struct {
+		name, src, want string
+	}{
+		{name: "default filename", src: DefaultFilename, want: "Artist A,Artist B - Song"},
+		{name: "archive filename", src: `{{.AlbumArtist}} - {{.Album}} - {{pad 2 .TrackNumber}} - {{.Title}}`, want: "Artist A - Album - 03 - Song"},
+		{name: "audio properties", src: `{{.Codec}} {{khz .SampleRate}}kHz/{{.BitDepth}}bit {{kbps .Bitrate}}kbps`, want: "FLAC 44.1kHz/16bit 1411kbps"},
+		{name: "default", src: `{{default "Unknown" .Genre}}|{{default "-" .DiscNumber}}`, want: "Pop|1"},
+		{name: "conditional", src: `{{if .Year}}({{.Year}}) {{end}}{{upper .Platform}}`, want: "(2024) NETEASE"},
+		{name: "truncate", src: `{{truncate 3 .Artists}}`, want: "Art"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Text(tt.src, track)
+			if err != nil {
+				t.Fatalf("Text() error = %v", err)
+			}
+			if got != tt.want {
+				t.Fatalf("Text() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTextRejectsUnsafeTemplates(t *testing.T) {
+	for _, src := range []string{
+		`{{range 1000000000}}x{{end}}`,
+		`{{define "a"}}x{{end}}{{template "a"}}`,
+		`{{if .Title}}{{range 10}}{{end}}{{end}}`,
+		`{{block "b" .}}x{{end}}`,
+	} {
+		if _, err := Text(src, Sample); !errors.Is(err, ErrForbidden) {
+			t.Errorf("Text(%q) error = %v, want ErrForbidden", src, err)
+		}
+	}
+	if _, err := Text(strings.Repeat("x", MaxSourceLen+1), Sample); err == nil {
+		t.Error("Text() accepted an oversized template")
+	}
+	if _, err := Text(`{{printf "%0100000d" 1}}`, Sample); err == nil {
+		t.Error("Text() accepted output past the limit")
+	}
+	if _, err := Text(`{{.Missing}}`, Sample); err == nil {
+		t.Error("Text() accepted an unknown field")
+	}
+	for _, src := range []string{
+		`{{replace .Title "" "x"}}`,
+		`{{replace (replace (replace .Title "o" "oooooooooooooooooooooooooooooooo") "o" "oooooooooooooooooooooooooooooooo") "o" "oooooooooooooooooooooooooooooooo"}}`,
+		`{{upper (printf "%09000d" 1)}}`,
+	} {
+		if err := ValidateFilename(src); err == nil {
+			t.Errorf("ValidateFilename(%q) accepted an empty search or oversized result", src)
+		}
+	}
+}
+
+func TestHTMLEscapesPlainFields(t *testing.T) {
+	track := Sample
+	track.Title = `<b>&"`
+	got, err := HTML(`{{.Title}} {{.TitleLink}}`, Caption{Track: track, TitleLink: `<a href="x">y</a>`})
+	if err != nil {
+		t.Fatalf("HTML() error = %v", err)
+	}
+	if want := `&lt;b&gt;&amp;&#34; <a href="x">y</a>`; got != want {
+		t.Fatalf("HTML() = %q, want %q", got, want)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	if err := ValidateCaption(DefaultCaption); err != nil {
+		t.Fatalf("ValidateCaption(default) error = %v", err)
+	}
+	if err := ValidateCaption(`<div>{{.Title}}</div>`); err == nil {
+		t.Error("ValidateCaption() accepted a tag Telegram rejects")
+	}
+	if err := ValidateCaption(strings.Repeat("a", 1100)); err == nil {
+		t.Error("ValidateCaption() accepted an over-long caption")
+	}
+	if err := ValidateFilename(DefaultFilename); err != nil {
+		t.Fatalf("ValidateFilename(default) error = %v", err)
+	}
+	if err := ValidateFilename(`{{if false}}x{{end}}`); err == nil {
+		t.Error("ValidateFilename() accepted an empty name")
+	}
+	if err := ValidateTag(TagTrack, `{{.TrackNumber}}`); err != nil {
+		t.Fatalf("ValidateTag(track) error = %v", err)
+	}
+	if err := ValidateTag(TagTrack, `{{.Title}}`); err == nil {
+		t.Error("ValidateTag() accepted a non-numeric track number")
+	}
+	if err := ValidateTag("mood", `x`); err == nil {
+		t.Error("ValidateTag() accepted an unknown field")
+	}
+}
@@ -0,0 +1,171 @@
+package spectrum
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/mewkiz/flac"
+)
+
+// maxSeconds bounds how much audio is decoded; two minutes is plenty to find a
+// cutoff and keeps hi-res files cheap to check.
+const maxSeconds = 120
+
+var (
+	// ErrLossyCodec is returned for files that are lossy to begin with.
+	ErrLossyCodec = errors.New("spectrum: lossy codec")
+	// ErrUnsupported is returned when the file cannot be decoded, e.g. a
+	// non-FLAC file without ffmpeg installed.
+	ErrUnsupported = errors.New("spectrum: unsupported format")
+)
+
+// losslessCodecs are the ffprobe codec names worth analysing.
+var losslessCodecs = map[string]bool{
+	"alac": true, "flac": true, "wavpack": true, "ape": true, "tta": true,
+}
+
+// IsLosslessCodec reports whether codec, an ffprobe codec name, is lossless.
+func IsLosslessCodec(codec string) bool {
+	return losslessCodecs[codec] || strings.HasPrefix(codec, "pcm_")
+}
+
+// Analyze inspects the lossless audio file at path. FLAC is decoded
+// in-process; ALAC, WAV and other lossless codecs go through ffmpeg.
+func Analyze(ctx context.Context, path string) (Report, error) {
+	if strings.EqualFold(filepath.Ext(path), ".flac") {
+		return analyzeFLAC(ctx, path)
+	}
+	return analyzeFFmpeg(ctx, path)
+}
+
+func analyzeFLAC(ctx context.Context, path string) (Report, error) {
+	stream, err := flac.Open(path)
+	if err != nil {
+		return Report{}, err
+	}
+	defer stream.Close()
+
+	info := stream.Info
+	channels := int(info.NChannels)
+	bps := int(info.BitsPerSample)
+	a := NewAnalyzer(int(info.SampleRate), channels, bps, bps)
+	limit := int(info.SampleRate) * maxSeconds
+	var buf []int32
+	for decoded := 0; decoded < limit; {
+		if err := ctx.Err(); err != nil {
+			return Report{}, err
+		}
+		frame, err := stream.ParseNext()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return Report{}, err
+		}
+		n := int(frame.BlockSize)
+		buf = buf[:0]
+		for i := range n {
+			for c := range channels {
+				buf = append(buf, frame.Subframes[c].Samples[i])
+			}
+		}
+		a.Write(buf)
+		decoded += n
+	}
+	return a.Report()
+}
+
+// analyzeFFmpeg decodes to 32-bit stereo PCM. Widening keeps the padding bits
+// of the source at the bottom, so EffectiveBits stays meaningful.
+func analyzeFFmpeg(ctx context.Context, path string) (Report, error) {
+	ffmpegPath, err := exec.LookPath("ffmpeg")
+	if err != nil {
+		return Report{}, ErrUnsupported
+	}
+	ffprobePath, err := exec.LookPath("ffprobe")
+	if err != nil {
+		return Report{}, ErrUnsupported
+	}
+	codec, rate, declared, err := probeStream(ctx, ffprobePath, path)
+	if err != nil {
+		return Report{}, err
+	}
+	if !IsLosslessCodec(codec) {
+		return Report{}, ErrLossyCodec
+	}
+
+	cmd := exec.CommandContext(ctx, ffmpegPath, "-hide_banner", "-nostats", "-v", "error",
+		"-i", path, "-map", "0:a:0", "-t", strconv.Itoa(maxSeconds), "-ac", "2", "-f", "s32le", "-")
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return Report{}, err
+	}
+	if err := cmd.Start(); err != nil {
+		return Report{}, err
+	}
+	a := NewAnalyzer(rate, 2, 32, declared)
+	r := bufio.NewReaderSize(stdout, 64*1024)
+	raw := make([]byte, 64*1024)
+	samples := make([]int32, len(raw)/4)
+	var readErr error
+	for {
+		n, err := io.ReadFull(r, raw)
+		n -= n % 8
+		for i := 0; i < n; i += 4 {
+			samples[i/4] = int32(binary.LittleEndian.Uint32(raw[i:]))
+		}
+		a.Write(samples[:n/4])
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			break
+		}
+		if err != nil {
+			readErr = err
+			_ = cmd.Process.Kill()
+			break
+		}
+	}
+	waitErr := cmd.Wait()
+	if readErr != nil {
+		return Report{}, readErr
+	}
+	if waitErr != nil {
+		return Report{}, fmt.Errorf("ffmpeg decode: %w", waitErr)
+	}
+	return a.Report()
+}
+
+func probeStream(ctx context.Context, ffprobePath, path string) (codec string, rate, bitsPerSample int, err error) {
+	out, err := exec.CommandContext(ctx, ffprobePath, "-v", "error", "-select_streams", "a:0",
+		"-show_entries", "stream=codec_name,sample_rate,bits_per_raw_sample,bits_per_sample",
+		"-of", "default=noprint_wrappers=1", path).Output()
+	if err != nil {
+		return "", 0, 0, fmt.Errorf("ffprobe: %w", err)
+	}
+	for _, line := range strings.Split(string(out), "\n") {
+		key, value, ok := strings.Cut(strings.TrimSpace(line), "=")
+		if !ok {
+			continue
+		}
+		n, _ := strconv.Atoi(value)
+		switch key {
+		case "codec_name":
+			codec = value
+		case "sample_rate":
+			rate = n
+		case "bits_per_raw_sample", "bits_per_sample":
+			bitsPerSample = max(bitsPerSample, n)
+		}
+	}
+	if rate <= 0 {
+		return "", 0, 0, ErrUnsupported
+	}
+	return codec, rate, bitsPerSample, nil
+}
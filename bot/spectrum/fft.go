@@ -0,0 +1,34 @@
+package spectrum
+
+import (
+	"math"
+	"math/bits"
+)
+
+// fft computes an in-place radix-2 complex FFT. len(re) must be a power of two
+// and equal len(im).
+func fft(re, im []float64) {
+	n := len(re)
+	shift := 64 - uint(bits.TrailingZeros(uint(n)))
+	for i := range n {
+		j := int(bits.Reverse64(uint64(i)) >> shift)
+		if j > i {
+			re[i], re[j] = re[j], re[i]
+			im[i], im[j] = im[j], im[i]
+		}
+	}
+	for size := 2; size <= n; size <<= 1 {
+		half := size / 2
+		step := -2 * math.Pi / float64(size)
+		for k := range half {
+			wr, wi := math.Cos(step*float64(k)), math.Sin(step*float64(k))
+			for start := 0; start < n; start += size {
+				a, b := start+k, start+k+half
+				tr := wr*re[b] - wi*im[b]
+				ti := wr*im[b] + wi*re[b]
+				re[b], im[b] = re[a]-tr, im[a]-ti
+				re[a], im[a] = re[a]+tr, im[a]+ti
+			}
+		}
+	}
+}
@@ -0,0 +1,201 @@
+// Package spectrum inspects lossless audio for signs that it was made from a
+// lossy or lower-resolution source: a sharp high-frequency cutoff below what
+// the sample rate allows, or a high bit depth whose low bits are never used.
+package spectrum
+
+import (
+	"errors"
+	"math"
+	"math/bits"
+)
+
+// Verdict summarises an analysis.
+type Verdict string
+
+const (
+	// Genuine means nothing suspicious was found.
+	Genuine Verdict = "genuine"
+	// LossySource means the spectrum stops well below 20 kHz, as it does for
+	// lossless files transcoded from MP3/AAC.
+	LossySource Verdict = "lossy_source"
+	// Upsampled means a high sample rate file has no content above what a
+	// 44.1/48 kHz source can carry.
+	Upsampled Verdict = "upsampled"
+	// PaddedBits means a 24/32-bit file only ever uses the top 16 bits.
+	PaddedBits Verdict = "padded_bits"
+)
+
+const (
+	fftSize = 8192
+	// bandHz is the width the averaged spectrum is smoothed over before
+	// looking for a cutoff.
+	bandHz = 200
+	// cliffDB is how far the spectrum must fall within transitionBands for a
+	// cutoff to count; natural roll-off is far gentler.
+	cliffDB         = 25
+	transitionBands = 2
+	minCutoffHz     = 4000
+	// silenceDBFS skips near-silent windows so fades and gaps do not dilute
+	// the average spectrum.
+	silenceDBFS = -60
+
+	lossyCutoffHz = 19500
+	hiResCutoffHz = 24500
+)
+
+// ErrNoSignal is returned when every analysed window was silent.
+var ErrNoSignal = errors.New("spectrum: no signal to analyse")
+
+// Report is the outcome of one analysis.
+type Report struct {
+	SampleRate int
+	// DeclaredBits is the bit depth the container claims.
+	DeclaredBits int
+	// EffectiveBits is the bit depth actually used by the samples.
+	EffectiveBits int
+	// CutoffHz is the frequency above which the spectrum falls off a cliff,
+	// or the Nyquist frequency when there is no cliff.
+	CutoffHz int
+	Verdict  Verdict
+}
+
+// Analyzer accumulates interleaved integer samples.
+type Analyzer struct {
+	sampleRate   int
+	channels     int
+	sampleBits   int
+	declaredBits int
+	scale        float64
+
+	window []float64
+	frame  []float64
+	re, im []float64
+	power  []float64
+	frames int
+	used   uint32
+}
+
+// NewAnalyzer returns an analyzer for samples stored in sampleBits-wide
+// integers. declaredBits is the bit depth the file claims, which differs from
+// sampleBits when samples were widened on decode (e.g. ffmpeg s32le output).
+func NewAnalyzer(sampleRate, channels, sampleBits, declaredBits int) *Analyzer {
+	if channels < 1 {
+		channels = 1
+	}
+	a := &Analyzer{
+		sampleRate:   sampleRate,
+		channels:     channels,
+		sampleBits:   sampleBits,
+		declaredBits: declaredBits,
+		scale:        1 / float64(int64(1)<<(sampleBits-1)) / float64(channels),
+		window:       make([]float64, fftSize),
+		frame:        make([]float64, 0, fftSize),
+		re:           make([]float64, fftSize),
+		im:           make([]float64, fftSize),
+		power:        make([]float64, fftSize/2+1),
+	}
+	for i := range a.window {
+		a.window[i] = 0.5 * (1 - math.Cos(2*math.Pi*float64(i)/float64(fftSize-1)))
+	}
+	return a
+}
+
+// Write adds interleaved samples. A trailing partial frame is ignored.
+func (a *Analyzer) Write(samples []int32) {
+	n := len(samples) / a.channels
+	for f := range n {
+		var mono float64
+		for _, s := range samples[f*a.channels : (f+1)*a.channels] {
+			a.used |= uint32(s)
+			mono += float64(s)
+		}
+		a.frame = append(a.frame, mono*a.scale)
+		if len(a.frame) == fftSize {
+			a.analyzeWindow()
+			a.frame = a.frame[:0]
+		}
+	}
+}
+
+func (a *Analyzer) analyzeWindow() {
+	var sum float64
+	for _, s := range a.frame {
+		sum += s * s
+	}
+	if rms := math.Sqrt(sum / fftSize); rms == 0 || 20*math.Log10(rms) < silenceDBFS {
+		return
+	}
+	for i, s := range a.frame {
+		a.re[i] = s * a.window[i]
+		a.im[i] = 0
+	}
+	fft(a.re, a.im)
+	for k := range a.power {
+		a.power[k] += a.re[k]*a.re[k] + a.im[k]*a.im[k]
+	}
+	a.frames++
+}
+
+// Report returns the verdict for everything written so far.
+func (a *Analyzer) Report() (Report, error) {
+	if a.frames == 0 {
+		return Report{}, ErrNoSignal
+	}
+	r := Report{
+		SampleRate:    a.sampleRate,
+		DeclaredBits:  a.declaredBits,
+		EffectiveBits: a.effectiveBits(),
+		CutoffHz:      a.cutoff(),
+	}
+	switch {
+	case a.sampleRate >= 44100 && r.CutoffHz < lossyCutoffHz:
+		r.Verdict = LossySource
+	case a.sampleRate > 48000 && r.CutoffHz <= hiResCutoffHz:
+		r.Verdict = Upsampled
+	case r.DeclaredBits > 16 && r.EffectiveBits > 0 && r.EffectiveBits <= 16:
+		r.Verdict = PaddedBits
+	default:
+		r.Verdict = Genuine
+	}
+	return r, nil
+}
+
+// effectiveBits counts the bits in use: trailing bits that are zero in every
+// sample were padding.
+func (a *Analyzer) effectiveBits() int {
+	if a.used == 0 {
+		return 0
+	}
+	return a.sampleBits - bits.TrailingZeros32(a.used)
+}
+
+// cutoff finds the highest band below which the averaged spectrum drops by at
+// least cliffDB and never recovers.
+func (a *Analyzer) cutoff() int {
+	nyquist := a.sampleRate / 2
+	binHz := float64(a.sampleRate) / fftSize
+	bandBins := max(int(math.Round(bandHz/binHz)), 1)
+	nb := (len(a.power) - 1) / bandBins
+	levels := make([]float64, nb)
+	for b := range levels {
+		var sum float64
+		for _, p := range a.power[1+b*bandBins : 1+(b+1)*bandBins] {
+			sum += p
+		}
+		levels[b] = 10 * math.Log10(sum/float64(bandBins*a.frames)+1e-30)
+	}
+	// ceiling[b] is the loudest band at or above b.
+	ceiling := make([]float64, nb+1)
+	ceiling[nb] = math.Inf(-1)
+	for b := nb - 1; b >= 0; b-- {
+		ceiling[b] = math.Max(levels[b], ceiling[b+1])
+	}
+	minBand := int(minCutoffHz / (binHz * float64(bandBins)))
+	for b := nb - transitionBands - 2; b >= max(minBand, 2); b-- {
+		below := (levels[b] + levels[b-1] + levels[b-2]) / 3
+		if below-ceiling[b+transitionBands+1] >= cliffDB {
+			return min(int(float64((b+1)*bandBins)*binHz), nyquist)
+		}
+	}
+	return nyquist
+}
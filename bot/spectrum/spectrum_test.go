@@ -0,0 +1,117 @@
+package spectrum
+
+import (
+	"errors"
+	"math"
+	"math/rand/v2"
+	"testing"
+)
+
+// synth returns stereo int16-range samples: sines spread up to maxHz with
+// random phases plus ±1 LSB of dither, like a lossy decode written to 16 bit.
+// maxHz = 0 gives full-band white noise instead.
+func synth(rate int, seconds float64, maxHz float64, shift uint) []int32 {
+	rng := rand.New(rand.NewPCG(1, 2))
+	type tone struct{ freq, phase float64 }
+	var tones []tone
+	if maxHz > 0 {
+		for range 300 {
+			tones = append(tones, tone{50 + rng.Float64()*(maxHz-50), rng.Float64() * 2 * math.Pi})
+		}
+	}
+	frames := int(float64(rate) * seconds)
+	out := make([]int32, 0, frames*2)
+	for i := range frames {
+		var s float64
+		if maxHz > 0 {
+			t := float64(i) / float64(rate)
+			for _, tn := range tones {
+				s += math.Sin(2*math.Pi*tn.freq*t + tn.phase)
+			}
+			s *= 8000 / math.Sqrt(float64(len(tones)))
+			s += float64(rng.IntN(3) - 1)
+		} else {
+			s = (rng.Float64()*2 - 1) * 8000
+		}
+		v := int32(math.Round(s)) << shift
+		out = append(out, v, v)
+	}
+	return out
+}
+
+func report(t *testing.T, rate, bits, declared int, samples []int32) Report {
+	t.Helper()
+	a := NewAnalyzer(rate, 2, bits, declared)
+	a.Write(samples)
+	r, err := a.Report()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+func TestFullBandIsGenuine(t *testing.T) {
+	r := report(t, 44100, 16, 16, synth(44100, 3, 0, 0))
+	if r.Verdict != Genuine || r.CutoffHz != 22050 || r.EffectiveBits != 16 {
+		t.Fatalf("report = %+v", r)
+	}
+}
+
+func TestLowpassedSourceIsLossy(t *testing.T) {
+	r := report(t, 44100, 16, 16, synth(44100, 3, 16000, 0))
+	if r.Verdict != LossySource {
+		t.Fatalf("verdict = %q, want %q (%+v)", r.Verdict, LossySource, r)
+	}
+	if r.CutoffHz < 15500 || r.CutoffHz > 16800 {
+		t.Fatalf("cutoff = %d Hz, want about 16 kHz", r.CutoffHz)
+	}
+}
+
+func TestUpsampledHiRes(t *testing.T) {
+	r := report(t, 96000, 24, 24, synth(96000, 3, 21000, 8))
+	if r.Verdict != Upsampled {
+		t.Fatalf("verdict = %q, want %q (%+v)", r.Verdict, Upsampled, r)
+	}
+}
+
+func TestPaddedBitDepth(t *testing.T) {
+	r := report(t, 48000, 24, 24, synth(48000, 3, 0, 8))
+	if r.Verdict != PaddedBits || r.EffectiveBits != 16 {
+		t.Fatalf("report = %+v", r)
+	}
+	// The same samples widened to 32 bits on decode keep their padding.
+	r = report(t, 48000, 32, 24, synth(48000, 3, 0, 16))
+	if r.Verdict != PaddedBits || r.EffectiveBits != 16 {
+		t.Fatalf("widened report = %+v", r)
+	}
+}
+
+func TestSilenceHasNoReport(t *testing.T) {
+	a := NewAnalyzer(44100, 2, 16, 16)
+	a.Write(make([]int32, 44100*2))
+	if _, err := a.Report(); !errors.Is(err, ErrNoSignal) {
+		t.Fatalf("err = %v, want ErrNoSignal", err)
+	}
+}
+
+func TestFFTMatchesDFT(t *testing.T) {
+	const n = 16
+	re := make([]float64, n)
+	im := make([]float64, n)
+	for i := range re {
+		re[i] = math.Sin(float64(i)) + 0.5*math.Cos(3*float64(i))
+	}
+	in := append([]float64(nil), re...)
+	fft(re, im)
+	for k := range n {
+		var wr, wi float64
+		for i, x := range in {
+			angle := -2 * math.Pi * float64(k*i) / n
+			wr += x * math.Cos(angle)
+			wi += x * math.Sin(angle)
+		}
+		if math.Abs(wr-re[k]) > 1e-9 || math.Abs(wi-im[k]) > 1e-9 {
+			t.Fatalf("bin %d = %v%+vi, want %v%+vi", k, re[k], im[k], wr, wi)
+		}
+	}
+}
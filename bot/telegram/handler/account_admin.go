@@ -55,7 +55,7 @@ func handleAccountLogin(ctx context.Context, manager platform.Manager, args stri
 	case "", "help":
 		return &admincmd.Response{Text: buildPlatformLoginHelp(ctx, manager, plat)}, nil
 	case "sign":
-		signer, ok := plat.(platform.SignInProvider)
+		signer, ok := platform.As[platform.SignInProvider](plat)
 		if !ok {
 			return &admincmd.Response{Text: tr(ctx, "adm_sign_unsupported", map[string]any{"Platform": platformDisplayName(ctx, manager, platformName)})}, nil
 		}
@@ -93,7 +93,7 @@ func handleAccountLogin(ctx context.Context, manager platform.Manager, args stri
 		}
 		return &admincmd.Response{Text: message}, nil
 	case "lang", "language":
-		provider, ok := plat.(platform.LanguageProvider)
+		provider, ok := platform.As[platform.LanguageProvider](plat)
 		if !ok {
 			return &admincmd.Response{Text: tr(ctx, "adm_lang_unsupported", map[string]any{"Platform": platformDisplayName(ctx, manager, platformName)})}, nil
 		}
@@ -110,7 +110,7 @@ func handleAccountLogin(ctx context.Context, manager platform.Manager, args stri
 		}
 		return &admincmd.Response{Text: message}, nil
 	case "cookie":
-		importer, ok := plat.(platform.CookieImporter)
+		importer, ok := platform.As[platform.CookieImporter](plat)
 		if !ok {
 			return &admincmd.Response{Text: tr(ctx, "adm_cookie_import_unsupported", map[string]any{"Platform": platformDisplayName(ctx, manager, platformName)})}, nil
 		}
@@ -127,7 +127,7 @@ func handleAccountLogin(ctx context.Context, manager platform.Manager, args stri
 		}
 		return &admincmd.Response{Text: sanitizeSensitiveText(text)}, nil
 	case "qr":
-		provider, ok := plat.(platform.QRLoginProvider)
+		provider, ok := platform.As[platform.QRLoginProvider](plat)
 		if !ok {
 			return &admincmd.Response{Text: tr(ctx, "adm_qr_unsupported", map[string]any{"Platform": platformDisplayName(ctx, manager, platformName)})}, nil
 		}
@@ -210,7 +210,7 @@ func handleGlobalLoginActions(ctx context.Context, manager platform.Manager, arg
 			if plat == nil {
 				return "", false, nil
 			}
-			signer, ok := plat.(platform.SignInProvider)
+			signer, ok := platform.As[platform.SignInProvider](plat)
 			if !ok {
 				return tr(ctx, "adm_sign_unsupported", map[string]any{"Platform": platformDisplayName(ctx, manager, platformName)}), true, nil
 			}
@@ -258,7 +258,7 @@ func handleAccountLoginCallback(ctx context.Context, manager platform.Manager, b
 	}
 	for _, name := range manager.List() {
 		plat := manager.Get(name)
-		provider, ok := plat.(platform.QRLoginProvider)
+		provider, ok := platform.As[platform.QRLoginProvider](plat)
 		if !ok {
 			continue
 		}
@@ -325,17 +325,17 @@ func buildPlatformLoginHelp(ctx context.Context, manager platform.Manager, plat
 	}
 	name := plat.Name()
 	methods := make([]string, 0, 4)
-	if provider, ok := plat.(platform.LoginMethodProvider); ok {
+	if provider, ok := platform.As[platform.LoginMethodProvider](plat); ok {
 		methods = append(methods, provider.SupportedLoginMethods()...)
 	}
 	if len(methods) == 0 {
-		if _, ok := plat.(platform.QRLoginProvider); ok {
+		if _, ok := platform.As[platform.QRLoginProvider](plat); ok {
 			methods = append(methods, "qr")
 		}
-		if _, ok := plat.(platform.CookieImporter); ok {
+		if _, ok := platform.As[platform.CookieImporter](plat); ok {
 			methods = append(methods, "cookie")
 		}
-		if _, ok := plat.(platform.CookieRenewer); ok {
+		if _, ok := platform.As[platform.CookieRenewer](plat); ok {
 			methods = append(methods, "renew")
 		}
 	}
@@ -362,7 +362,7 @@ func buildPlatformLoginHelp(ctx context.Context, manager platform.Manager, plat
 	if containsLoginMethod(methods, "auto") || implementsAutoRenew(plat) {
 		examples = append(examples, fmt.Sprintf("/login %s auto on 21600", name), fmt.Sprintf("/login %s auto status", name), "/login auto status")
 	}
-	if _, ok := plat.(platform.LanguageProvider); ok {
+	if _, ok := platform.As[platform.LanguageProvider](plat); ok {
 		examples = append(examples, fmt.Sprintf("/login %s lang", name), fmt.Sprintf("/login %s lang <%s>", name, tr(ctx, "adm_lang_arg")))
 	}
 	return tr(ctx, "adm_login_help", map[string]any{
@@ -383,23 +383,23 @@ func containsLoginMethod(methods []string, target string) bool {
 }
 
 func implementsAccountCheck(plat platform.Platform) bool {
-	_, ok := plat.(platform.CookieChecker)
+	_, ok := platform.As[platform.CookieChecker](plat)
 	return ok
 }
 
 func implementsRenew(plat platform.Platform) bool {
-	_, ok := plat.(platform.CookieRenewer)
+	_, ok := platform.As[platform.CookieRenewer](plat)
 	return ok
 }
 
 func implementsAutoRenew(plat platform.Platform) bool {
-	_, ok := plat.(platform.AutoRenewer)
+	_, ok := platform.As[platform.AutoRenewer](plat)
 	return ok
 }
 
 func handlePlatformAutoRenew(ctx context.Context, manager platform.Manager, platformName, payload string) (string, error) {
 	plat := manager.Get(platformName)
-	autoRenewer, ok := plat.(platform.AutoRenewer)
+	autoRenewer, ok := platform.As[platform.AutoRenewer](plat)
 	if !ok {
 		return tr(ctx, "adm_auto_unsupported", map[string]any{"Platform": platformDisplayName(ctx, manager, platformName)}), nil
 	}
@@ -456,7 +456,7 @@ func handleAllPlatformAutoRenew(ctx context.Context, manager platform.Manager, p
 	failures := 0
 	for _, name := range platforms {
 		plat := manager.Get(name)
-		autoRenewer, ok := plat.(platform.AutoRenewer)
+		autoRenewer, ok := platform.As[platform.AutoRenewer](plat)
 		if !ok {
 			continue
 		}
@@ -294,6 +294,18 @@ func resolveCookiePlatform(manager platform.Manager, raw string) string {
 	if trimmed == "" || manager == nil {
 		return ""
 	}
+	// "netease@alt" targets one account of a multi-account platform.
+	if base, account, ok := strings.Cut(trimmed, platform.AccountSeparator); ok {
+		name, ok := manager.ResolveAlias(base)
+		if !ok {
+			return ""
+		}
+		qualified := name + platform.AccountSeparator + strings.TrimSpace(account)
+		if manager.Get(qualified) == nil {
+			return ""
+		}
+		return qualified
+	}
 	if name, ok := manager.ResolveAlias(trimmed); ok {
 		return name
 	}
@@ -305,7 +317,7 @@ func checkCookieForPlatform(ctx context.Context, manager platform.Manager, platf
 	if plat == nil {
 		return "", nil
 	}
-	checker, ok := plat.(platform.CookieChecker)
+	checker, ok := platform.As[platform.CookieChecker](plat)
 	if !ok {
 		return "", nil
 	}
@@ -330,7 +342,7 @@ func renewCookieForPlatform(ctx context.Context, manager platform.Manager, platf
 	if plat == nil {
 		return "", nil
 	}
-	renewer, ok := plat.(platform.CookieRenewer)
+	renewer, ok := platform.As[platform.CookieRenewer](plat)
 	if !ok {
 		return "", nil
 	}
@@ -19,7 +19,9 @@ type ArtistHandler struct {
 	PlatformManager platform.Manager
 	RateLimiter     *telegram.RateLimiter
 	ResourceLimiter *ResourceRateLimiter
-	Logger          interface {
+	// Watch, when set, adds a "follow new releases" button to the artist card.
+	Watch  *ArtistWatchHandler
+	Logger interface {
 		Warn(msg string, keysAndValues ...any)
 	}
 }
@@ -93,6 +95,9 @@ func (h *ArtistHandler) TryHandle(ctx context.Context, b *telego.Bot, update *te
 			URL:        strings.TrimSpace(artist.AvatarURL),
 		},
 	}
+	if keyboard := h.Watch.followKeyboard(ctx, platformName, artistID); keyboard != nil {
+		params.ReplyMarkup = keyboard
+	}
 	if h.RateLimiter != nil {
 		if _, err := telegram.SendMessageWithRetry(ctx, h.RateLimiter, b, params); err != nil && h.Logger != nil {
 			h.Logger.Warn("failed to send artist message", "chatID", message.Chat.ID, "error", err)
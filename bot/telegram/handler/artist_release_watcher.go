@@ -0,0 +1,348 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	botpkg "github.com/liuran001/MusicBot-Go/bot"
+	"github.com/liuran001/MusicBot-Go/bot/i18n"
+	"github.com/liuran001/MusicBot-Go/bot/platform"
+	"github.com/liuran001/MusicBot-Go/bot/telegram"
+	"github.com/mymmrac/telego"
+)
+
+const (
+	defaultArtistWatchInterval  = 30 * time.Minute
+	defaultArtistWatchBatchSize = 50
+	// artistWatchStartDelay keeps the first poll out of the startup burst.
+	artistWatchStartDelay = time.Minute
+	// artistWatchReleaseLimit is how many of the newest releases each poll
+	// fetches; artistWatchKnownCap bounds the seen-ID list kept per
+	// subscription, comfortably above the fetch size so a release that briefly
+	// drops out of the first page is not announced again.
+	artistWatchReleaseLimit = 10
+	artistWatchKnownCap     = 50
+	// artistWatchMaxPosts caps announcements per subscription per poll, so an
+	// artist that drops a batch of singles cannot flood a chat.
+	artistWatchMaxPosts = 3
+	// artistWatchStaleAge filters out "new" IDs whose release date is far older
+	// than the previous poll, e.g. a re-uploaded back-catalogue album.
+	artistWatchStaleAge = 30 * 24 * time.Hour
+	// artistWatchTrackButtons caps the download buttons under one release.
+	artistWatchTrackButtons = 10
+	artistWatchPollTimeout  = 30 * time.Second
+)
+
+// ArtistReleaseWatcher periodically polls followed artists for new releases
+// and posts each one (cover, tracklist and download buttons) to every
+// subscribing chat. Subscriptions of the same artist share one platform call
+// per poll, and every call is admitted through ResourceLimiter's
+// ActionArtistWatch rule, so the per-platform budget bounds how hard the
+// watcher can hit one platform regardless of how many chats follow artists.
+type ArtistReleaseWatcher struct {
+	Store           botpkg.ArtistSubscriptionStore
+	PlatformManager platform.Manager
+	RateLimiter     *telegram.RateLimiter
+	ResourceLimiter *ResourceRateLimiter
+	Interval        time.Duration
+	BatchSize       int
+	Logger          botpkg.Logger
+}
+
+// Start runs the poll loop until ctx is cancelled.
+func (w *ArtistReleaseWatcher) Start(ctx context.Context, b *telego.Bot) {
+	if w == nil || w.Store == nil || w.PlatformManager == nil || b == nil {
+		return
+	}
+	interval := w.Interval
+	if interval <= 0 {
+		interval = defaultArtistWatchInterval
+	}
+	go func() {
+		timer := time.NewTimer(artistWatchStartDelay)
+		defer timer.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-timer.C:
+			}
+			w.RunOnce(ctx, b)
+			timer.Reset(interval)
+		}
+	}()
+}
+
+type artistWatchGroup struct {
+	platform string
+	artistID string
+	subs     []*botpkg.ArtistSubscription
+}
+
+// RunOnce polls the least recently checked subscriptions once. Artists whose
+// platform budget is exhausted are left unmarked and so lead the next poll.
+func (w *ArtistReleaseWatcher) RunOnce(ctx context.Context, b *telego.Bot) {
+	batch := w.BatchSize
+	if batch <= 0 {
+		batch = defaultArtistWatchBatchSize
+	}
+	subs, err := w.Store.ListDueArtistSubscriptions(ctx, batch)
+	if err != nil {
+		if w.Logger != nil {
+			w.Logger.Warn("failed to list artist subscriptions", "error", err)
+		}
+		return
+	}
+	for _, group := range groupArtistSubscriptions(subs) {
+		if ctx.Err() != nil {
+			return
+		}
+		w.pollArtist(ctx, b, group)
+	}
+}
+
+func groupArtistSubscriptions(subs []*botpkg.ArtistSubscription) []artistWatchGroup {
+	groups := make([]artistWatchGroup, 0, len(subs))
+	index := make(map[string]int, len(subs))
+	for _, sub := range subs {
+		if sub == nil {
+			continue
+		}
+		key := sub.Platform + "\x00" + sub.ArtistID
+		if idx, ok := index[key]; ok {
+			groups[idx].subs = append(groups[idx].subs, sub)
+			continue
+		}
+		index[key] = len(groups)
+		groups = append(groups, artistWatchGroup{platform: sub.Platform, artistID: sub.ArtistID, subs: []*botpkg.ArtistSubscription{sub}})
+	}
+	return groups
+}
+
+func (w *ArtistReleaseWatcher) pollArtist(ctx context.Context, b *telego.Bot, group artistWatchGroup) {
+	now := time.Now()
+	provider, ok := platform.As[platform.ArtistReleaseProvider](w.PlatformManager.Get(group.platform))
+	if !ok {
+		// The platform was disabled or lost the capability; rotate the rows to
+		// the back of the queue instead of retrying them every poll.
+		w.markFailed(ctx, group.subs, now)
+		return
+	}
+	if !w.ResourceLimiter.AllowFor(ActionArtistWatch, 0, 0, group.platform) {
+		return
+	}
+	pollCtx, cancel := context.WithTimeout(ctx, artistWatchPollTimeout)
+	releases, err := provider.GetArtistReleases(pollCtx, group.artistID, artistWatchReleaseLimit)
+	cancel()
+	if err != nil {
+		if w.Logger != nil {
+			w.Logger.Warn("failed to poll artist releases", "platform", group.platform, "artistID", group.artistID, "error", err)
+		}
+		w.markFailed(ctx, group.subs, now)
+		return
+	}
+
+	var tracks map[string][]platform.Track
+	for _, sub := range group.subs {
+		fresh, known := diffArtistReleases(sub, releases)
+		for _, release := range fresh {
+			if tracks == nil {
+				tracks = make(map[string][]platform.Track)
+			}
+			if _, loaded := tracks[release.ID]; !loaded {
+				tracks[release.ID] = w.releaseTracks(ctx, group.platform, release.ID)
+			}
+			w.postRelease(ctx, b, sub, release, tracks[release.ID])
+		}
+		w.mark(ctx, sub, known, now)
+	}
+}
+
+func (w *ArtistReleaseWatcher) mark(ctx context.Context, sub *botpkg.ArtistSubscription, known []string, checkedAt time.Time) {
+	if err := w.Store.MarkArtistSubscriptionChecked(ctx, sub.ID, known, checkedAt); err != nil && w.Logger != nil {
+		w.Logger.Warn("failed to update artist subscription", "id", sub.ID, "error", err)
+	}
+}
+
+// markFailed rotates subs after a poll that fetched nothing. Subscriptions
+// that were never seeded only get their poll time: diffArtistReleases seeds
+// only while LastCheckedAt is nil, so marking them checked would make the
+// next good poll announce the whole back catalogue.
+func (w *ArtistReleaseWatcher) markFailed(ctx context.Context, subs []*botpkg.ArtistSubscription, checkedAt time.Time) {
+	for _, sub := range subs {
+		if sub.LastCheckedAt != nil {
+			w.mark(ctx, sub, sub.KnownReleaseIDs, checkedAt)
+			continue
+		}
+		if err := w.Store.MarkArtistSubscriptionPolled(ctx, sub.ID, checkedAt); err != nil && w.Logger != nil {
+			w.Logger.Warn("failed to update artist subscription", "id", sub.ID, "error", err)
+		}
+	}
+}
+
+// diffArtistReleases returns the releases to announce for sub (oldest first)
+// and the updated seen-ID list (newest first). The first poll of a
+// subscription only seeds the list.
+func diffArtistReleases(sub *botpkg.ArtistSubscription, releases []platform.Album) ([]platform.Album, []string) {
+	seen := make(map[string]struct{}, len(sub.KnownReleaseIDs))
+	for _, id := range sub.KnownReleaseIDs {
+		seen[id] = struct{}{}
+	}
+	known := make([]string, 0, len(releases)+len(sub.KnownReleaseIDs))
+	var fresh []platform.Album
+	for _, release := range releases {
+		id := strings.TrimSpace(release.ID)
+		if id == "" {
+			continue
+		}
+		known = append(known, id)
+		if _, ok := seen[id]; ok || sub.LastCheckedAt == nil {
+			continue
+		}
+		if release.ReleaseDate != nil && release.ReleaseDate.Before(sub.LastCheckedAt.Add(-artistWatchStaleAge)) {
+			continue
+		}
+		fresh = append(fresh, release)
+	}
+	added := make(map[string]struct{}, len(known))
+	for _, id := range known {
+		added[id] = struct{}{}
+	}
+	for _, id := range sub.KnownReleaseIDs {
+		if _, ok := added[id]; !ok {
+			known = append(known, id)
+		}
+	}
+	if len(known) > artistWatchKnownCap {
+		known = known[:artistWatchKnownCap]
+	}
+	if len(fresh) > artistWatchMaxPosts {
+		fresh = fresh[:artistWatchMaxPosts]
+	}
+	for i, j := 0, len(fresh)-1; i < j; i, j = i+1, j-1 {
+		fresh[i], fresh[j] = fresh[j], fresh[i]
+	}
+	return fresh, known
+}
+
+// releaseTracks loads the release's tracklist through the platform's album
+// collection support. It shares the watcher's budget; on refusal or failure
+// the release is announced without download buttons.
+func (w *ArtistReleaseWatcher) releaseTracks(ctx context.Context, platformName, albumID string) []platform.Track {
+	plat := w.PlatformManager.Get(platformName)
+	if plat == nil || !w.ResourceLimiter.AllowFor(ActionArtistWatch, 0, 0, platformName) {
+		return nil
+	}
+	pollCtx, cancel := context.WithTimeout(platform.WithPlaylistLimit(ctx, artistWatchTrackButtons), artistWatchPollTimeout)
+	defer cancel()
+	playlist, err := plat.GetPlaylist(pollCtx, platform.EncodeAlbumCollectionID(albumID))
+	if err != nil || playlist == nil {
+		return nil
+	}
+	if len(playlist.Tracks) > artistWatchTrackButtons {
+		return playlist.Tracks[:artistWatchTrackButtons]
+	}
+	return playlist.Tracks
+}
+
+func (w *ArtistReleaseWatcher) postRelease(ctx context.Context, b *telego.Bot, sub *botpkg.ArtistSubscription, release platform.Album, tracks []platform.Track) {
+	lctx := i18n.WithLocalizer(ctx, i18n.For(i18n.Resolve(sub.Language, "")))
+	caption := formatArtistRelease(lctx, w.PlatformManager, sub, release, tracks)
+	keyboard := artistReleaseKeyboard(lctx, sub.Platform, release, tracks)
+
+	if cover := strings.TrimSpace(release.CoverURL); cover != "" {
+		params := &telego.SendPhotoParams{
+			ChatID:  telego.ChatID{ID: sub.ChatID},
+			Photo:   telego.InputFile{URL: cover},
+			Caption: caption,
+		}
+		if keyboard != nil {
+			params.ReplyMarkup = keyboard
+		}
+		var err error
+		if w.RateLimiter != nil {
+			_, err = telegram.SendPhotoWithRetry(ctx, w.RateLimiter, b, params)
+		} else {
+			_, err = b.SendPhoto(ctx, params)
+		}
+		if err == nil {
+			return
+		}
+		if w.Logger != nil {
+			w.Logger.Warn("failed to send release cover, falling back to text", "chatID", sub.ChatID, "release", release.ID, "error", err)
+		}
+	}
+	params := &telego.SendMessageParams{
+		ChatID:             telego.ChatID{ID: sub.ChatID},
+		Text:               caption,
+		LinkPreviewOptions: &telego.LinkPreviewOptions{IsDisabled: true},
+	}
+	if keyboard != nil {
+		params.ReplyMarkup = keyboard
+	}
+	var err error
+	if w.RateLimiter != nil {
+		_, err = telegram.SendMessageWithRetry(ctx, w.RateLimiter, b, params)
+	} else {
+		_, err = b.SendMessage(ctx, params)
+	}
+	if err != nil && w.Logger != nil {
+		w.Logger.Warn("failed to post artist release", "chatID", sub.ChatID, "release", release.ID, "error", err)
+	}
+}
+
+func formatArtistRelease(ctx context.Context, manager platform.Manager, sub *botpkg.ArtistSubscription, release platform.Album, tracks []platform.Track) string {
+	artist := inlineArtistsLabel(release.Artists)
+	if artist == "" {
+		artist = sub.ArtistName
+	}
+	lines := []string{
+		fmt.Sprintf("%s %s", platformEmoji(manager, sub.Platform), tr(ctx, "artw_release_title", map[string]any{"Artist": artist})),
+		"💿 " + release.Title,
+	}
+	if release.ReleaseDate != nil {
+		lines = append(lines, tr(ctx, "artw_release_date", map[string]any{"Date": release.ReleaseDate.Format("2006-01-02")}))
+	}
+	if release.TrackCount > 0 {
+		lines = append(lines, tr(ctx, "artw_release_tracks", map[string]any{"Count": release.TrackCount}))
+	}
+	if len(tracks) > 0 {
+		lines = append(lines, "")
+		for idx, track := range tracks {
+			lines = append(lines, fmt.Sprintf("%d. %s", idx+1, truncateText(track.Title, 48)))
+		}
+	}
+	if url := strings.TrimSpace(release.URL); url != "" {
+		lines = append(lines, "", url)
+	}
+	return truncateText(strings.Join(lines, "\n"), 1000)
+}
+
+// artistReleaseKeyboard numbers the tracklist in the caption; tracks whose ID
+// cannot fit plain callback data are left without a button rather than being
+// routed through the in-memory token store, which would not survive until a
+// subscriber taps a days-old announcement.
+func artistReleaseKeyboard(ctx context.Context, platformName string, release platform.Album, tracks []platform.Track) *telego.InlineKeyboardMarkup {
+	buttons := make([]telego.InlineKeyboardButton, 0, len(tracks))
+	for idx, track := range tracks {
+		trackID := strings.TrimSpace(track.ID)
+		if !isInlineStartToken(trackID) {
+			continue
+		}
+		data := fmt.Sprintf("artw d %s %s", platformName, trackID)
+		if len(data) > 64 {
+			continue
+		}
+		buttons = append(buttons, telego.InlineKeyboardButton{Text: fmt.Sprintf("%d", idx+1), CallbackData: data})
+	}
+	rows := chunkButtons(buttons, artistWatchButtonsPerRow)
+	if url := strings.TrimSpace(release.URL); url != "" {
+		rows = append(rows, []telego.InlineKeyboardButton{{Text: tr(ctx, "artw_release_open"), URL: url}})
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+	return &telego.InlineKeyboardMarkup{InlineKeyboard: rows}
+}
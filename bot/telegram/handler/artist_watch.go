@@ -0,0 +1,377 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	botpkg "github.com/liuran001/MusicBot-Go/bot"
+	"github.com/liuran001/MusicBot-Go/bot/i18n"
+	"github.com/liuran001/MusicBot-Go/bot/platform"
+	"github.com/liuran001/MusicBot-Go/bot/telegram"
+	"github.com/mymmrac/telego"
+)
+
+// Artist-watch callback data formats (space separated, <=64 bytes):
+//
+//	follow:   "artw f <platform> <artistID>"        (artist card, current chat)
+//	unfollow: "artw u <chatID> <subscriptionID>"    (/watch list; the list may
+//	                                                 manage a channel from a DM)
+//	download: "artw d <platform> <trackID>"         (release announcement)
+
+const (
+	defaultArtistWatchPerChatLimit = 20
+	artistWatchButtonsPerRow       = 5
+)
+
+// ArtistWatchHandler serves /watch and the follow button on the artist card.
+// A chat (private chat, group or channel) follows an artist; the
+// ArtistReleaseWatcher later posts that artist's new releases into the chat.
+type ArtistWatchHandler struct {
+	Store           botpkg.ArtistSubscriptionStore
+	PlatformManager platform.Manager
+	RateLimiter     *telegram.RateLimiter
+	ResourceLimiter *ResourceRateLimiter
+	Music           *MusicHandler
+	PerChatLimit    int
+	Logger          botpkg.Logger
+}
+
+func (h *ArtistWatchHandler) perChatLimit() int {
+	if h == nil || h.PerChatLimit <= 0 {
+		return defaultArtistWatchPerChatLimit
+	}
+	return h.PerChatLimit
+}
+
+// followKeyboard returns the artist card's follow button, or nil when watching
+// is disabled or the platform cannot list artist releases.
+func (h *ArtistWatchHandler) followKeyboard(ctx context.Context, platformName, artistID string) *telego.InlineKeyboardMarkup {
+	if h == nil || h.Store == nil || h.PlatformManager == nil {
+		return nil
+	}
+	if _, ok := platform.As[platform.ArtistReleaseProvider](h.PlatformManager.Get(platformName)); !ok {
+		return nil
+	}
+	if !isInlineStartToken(platformName) || !isInlineStartToken(artistID) {
+		return nil
+	}
+	data := fmt.Sprintf("artw f %s %s", platformName, artistID)
+	if len(data) > 64 {
+		return nil
+	}
+	return &telego.InlineKeyboardMarkup{InlineKeyboard: [][]telego.InlineKeyboardButton{{
+		{Text: tr(ctx, "artw_follow_button"), CallbackData: data},
+	}}}
+}
+
+// Handle serves /watch:
+//
+//	/watch                         list this chat's followed artists
+//	/watch <artist link>           follow in this chat
+//	/watch <artist link> <@chan>   follow in a channel/group the caller administers
+//	/watch <@chan>                 list that chat's followed artists
+func (h *ArtistWatchHandler) Handle(ctx context.Context, b *telego.Bot, update *telego.Update) {
+	if update == nil || update.Message == nil {
+		return
+	}
+	message := update.Message
+	if h.Store == nil {
+		sendText(ctx, b, message.Chat.ID, message.MessageID, tr(ctx, "artw_failed"))
+		return
+	}
+	var userID int64
+	if message.From != nil {
+		userID = message.From.ID
+	}
+
+	link, target := splitArtistWatchArgs(commandArguments(message.Text))
+	targetChatID := message.Chat.ID
+	targetName := ""
+	if target != "" {
+		chatID, name, ok := resolveManagedChat(ctx, b, target, userID)
+		if !ok {
+			sendText(ctx, b, message.Chat.ID, message.MessageID, tr(ctx, "artw_target_invalid"))
+			return
+		}
+		targetChatID, targetName = chatID, name
+	} else if message.Chat.Type != "private" && link != "" && !isRequesterOrAdmin(ctx, b, message.Chat.ID, userID, 0) {
+		sendText(ctx, b, message.Chat.ID, message.MessageID, tr(ctx, "artw_admin_only"))
+		return
+	}
+
+	if link == "" {
+		text, keyboard := h.buildList(ctx, targetChatID, targetName)
+		h.send(ctx, b, message, text, keyboard)
+		return
+	}
+
+	resolved := resolveShortLinkText(ctx, h.PlatformManager, link)
+	platformName, artistID, ok := matchArtistURL(ctx, h.PlatformManager, resolved)
+	if !ok {
+		sendText(ctx, b, message.Chat.ID, message.MessageID, tr(ctx, "artw_usage"))
+		return
+	}
+	text, followed := h.follow(ctx, targetChatID, userID, message.Chat.ID, platformName, artistID)
+	if followed && targetName != "" {
+		text = tr(ctx, "artw_followed_in", map[string]any{"Chat": targetName}) + "\n" + text
+	}
+	h.send(ctx, b, message, text, nil)
+}
+
+func (h *ArtistWatchHandler) send(ctx context.Context, b *telego.Bot, message *telego.Message, text string, keyboard *telego.InlineKeyboardMarkup) {
+	params := &telego.SendMessageParams{
+		ChatID:             telego.ChatID{ID: message.Chat.ID},
+		MessageThreadID:    message.MessageThreadID,
+		Text:               text,
+		ReplyParameters:    buildReplyParams(message),
+		LinkPreviewOptions: &telego.LinkPreviewOptions{IsDisabled: true},
+	}
+	if keyboard != nil {
+		params.ReplyMarkup = keyboard
+	}
+	if h.RateLimiter != nil {
+		_, _ = telegram.SendMessageWithRetry(ctx, h.RateLimiter, b, params)
+	} else {
+		_, _ = b.SendMessage(ctx, params)
+	}
+}
+
+// splitArtistWatchArgs separates the optional trailing target chat (@username
+// or numeric chat ID) from the artist link.
+func splitArtistWatchArgs(args string) (link, target string) {
+	fields := strings.Fields(args)
+	if len(fields) == 0 {
+		return "", ""
+	}
+	last := fields[len(fields)-1]
+	if strings.HasPrefix(last, "@") && len(last) > 1 {
+		return strings.Join(fields[:len(fields)-1], " "), last
+	}
+	if id, err := strconv.ParseInt(last, 10, 64); err == nil && id < 0 {
+		return strings.Join(fields[:len(fields)-1], " "), last
+	}
+	return strings.Join(fields, " "), ""
+}
+
+// resolveManagedChat resolves a target chat reference and checks that userID
+// administers it. The bot must already be a member for GetChat to succeed.
+func resolveManagedChat(ctx context.Context, b *telego.Bot, target string, userID int64) (int64, string, bool) {
+	if b == nil || userID == 0 {
+		return 0, "", false
+	}
+	chatRef := telego.ChatID{Username: target}
+	if id, err := strconv.ParseInt(target, 10, 64); err == nil {
+		chatRef = telego.ChatID{ID: id}
+	}
+	chat, err := b.GetChat(ctx, &telego.GetChatParams{ChatID: chatRef})
+	if err != nil || chat == nil || chat.Type == telego.ChatTypePrivate {
+		return 0, "", false
+	}
+	if !isRequesterOrAdmin(ctx, b, chat.ID, userID, 0) {
+		return 0, "", false
+	}
+	name := strings.TrimSpace(chat.Title)
+	if chat.Username != "" {
+		name = "@" + chat.Username
+	}
+	return chat.ID, name, true
+}
+
+// follow subscribes chatID to an artist and returns the user-facing result and
+// whether the subscription is now in place. requestChatID is the chat the
+// request came from, charged for the artist lookup.
+func (h *ArtistWatchHandler) follow(ctx context.Context, chatID, userID, requestChatID int64, platformName, artistID string) (string, bool) {
+	plat := h.PlatformManager.Get(platformName)
+	if _, ok := platform.As[platform.ArtistReleaseProvider](plat); !ok {
+		return tr(ctx, "artw_unsupported"), false
+	}
+	if subscribed, err := h.Store.IsArtistSubscribed(ctx, chatID, platformName, artistID); err == nil && !subscribed {
+		count, err := h.Store.CountArtistSubscriptions(ctx, chatID)
+		if err != nil {
+			return tr(ctx, "artw_failed"), false
+		}
+		if int(count) >= h.perChatLimit() {
+			return tr(ctx, "artw_limit", map[string]any{"Limit": h.perChatLimit()}), false
+		}
+	}
+	if !h.ResourceLimiter.AllowFor(ActionArtist, userID, requestChatID, platformName) {
+		return tr(ctx, "err_rate_limited"), false
+	}
+	artist, err := plat.GetArtist(ctx, artistID)
+	if err != nil || artist == nil {
+		return userVisibleArtistError(ctx, err), false
+	}
+	name := strings.TrimSpace(artist.Name)
+	if name == "" {
+		name = artistID
+	}
+	err = h.Store.AddArtistSubscription(ctx, &botpkg.ArtistSubscription{
+		ChatID:          chatID,
+		Platform:        platformName,
+		ArtistID:        artistID,
+		ArtistName:      name,
+		ArtistURL:       strings.TrimSpace(artist.URL),
+		CreatedByUserID: userID,
+		Language:        i18n.From(ctx).Lang(),
+		KnownReleaseIDs: h.seedArtistReleases(ctx, plat, platformName, artistID),
+	})
+	if err != nil {
+		if h.Logger != nil {
+			h.Logger.Warn("failed to add artist subscription", "chatID", chatID, "platform", platformName, "artistID", artistID, "error", err)
+		}
+		return tr(ctx, "artw_failed"), false
+	}
+	return tr(ctx, "artw_followed", map[string]any{"Artist": name}), true
+}
+
+// seedArtistReleases lists the artist's current releases so a new
+// subscription starts with its back catalogue already known, even if the
+// watcher's first poll fails. The call is charged to the watcher's
+// ActionArtistWatch budget. It returns nil when that budget is spent or the
+// call fails; the first successful poll then seeds instead.
+func (h *ArtistWatchHandler) seedArtistReleases(ctx context.Context, plat platform.Platform, platformName, artistID string) []string {
+	provider, ok := platform.As[platform.ArtistReleaseProvider](plat)
+	if !ok || !h.ResourceLimiter.AllowFor(ActionArtistWatch, 0, 0, platformName) {
+		return nil
+	}
+	seedCtx, cancel := context.WithTimeout(ctx, artistWatchPollTimeout)
+	defer cancel()
+	releases, err := provider.GetArtistReleases(seedCtx, artistID, artistWatchReleaseLimit)
+	if err != nil {
+		if h.Logger != nil {
+			h.Logger.Warn("failed to seed artist releases", "platform", platformName, "artistID", artistID, "error", err)
+		}
+		return nil
+	}
+	_, known := diffArtistReleases(&botpkg.ArtistSubscription{}, releases)
+	return known
+}
+
+func (h *ArtistWatchHandler) buildList(ctx context.Context, chatID int64, chatName string) (string, *telego.InlineKeyboardMarkup) {
+	subs, err := h.Store.ListArtistSubscriptions(ctx, chatID)
+	if err != nil {
+		return tr(ctx, "artw_failed"), nil
+	}
+	var lines []string
+	if chatName != "" {
+		lines = append(lines, tr(ctx, "artw_list_chat", map[string]any{"Chat": chatName}))
+	}
+	if len(subs) == 0 {
+		lines = append(lines, tr(ctx, "artw_list_empty"))
+		return strings.Join(lines, "\n"), nil
+	}
+	lines = append(lines, tr(ctx, "artw_list_title", map[string]any{"Count": len(subs), "Limit": h.perChatLimit()}))
+	buttons := make([]telego.InlineKeyboardButton, 0, len(subs))
+	for idx, sub := range subs {
+		lines = append(lines, fmt.Sprintf("%d. %s %s", idx+1, platformEmoji(h.PlatformManager, sub.Platform), sub.ArtistName))
+		buttons = append(buttons, telego.InlineKeyboardButton{
+			Text:         fmt.Sprintf("✖ %d", idx+1),
+			CallbackData: fmt.Sprintf("artw u %d %d", chatID, sub.ID),
+		})
+	}
+	lines = append(lines, "", tr(ctx, "artw_list_hint"))
+	return strings.Join(lines, "\n"), &telego.InlineKeyboardMarkup{InlineKeyboard: chunkButtons(buttons, artistWatchButtonsPerRow)}
+}
+
+// ArtistWatchCallbackHandler handles "artw ..." callbacks.
+type ArtistWatchCallbackHandler struct {
+	Watch *ArtistWatchHandler
+}
+
+func (h *ArtistWatchCallbackHandler) Handle(ctx context.Context, b *telego.Bot, update *telego.Update) {
+	if update == nil || update.CallbackQuery == nil || h.Watch == nil || h.Watch.Store == nil {
+		return
+	}
+	query := update.CallbackQuery
+	parts := strings.Fields(query.Data)
+	if len(parts) < 4 || parts[0] != "artw" || query.Message == nil {
+		return
+	}
+	msg := query.Message.Message()
+	if msg == nil {
+		return
+	}
+	answer := func(text string, alert bool) {
+		_ = b.AnswerCallbackQuery(ctx, &telego.AnswerCallbackQueryParams{CallbackQueryID: query.ID, Text: text, ShowAlert: alert})
+	}
+
+	switch parts[1] {
+	case "f":
+		if msg.Chat.Type != telego.ChatTypePrivate && !isRequesterOrAdmin(ctx, b, msg.Chat.ID, query.From.ID, 0) {
+			answer(tr(ctx, "artw_admin_only"), true)
+			return
+		}
+		text, _ := h.Watch.follow(ctx, msg.Chat.ID, query.From.ID, msg.Chat.ID, parts[2], parts[3])
+		answer(text, true)
+	case "u":
+		chatID, errChat := strconv.ParseInt(parts[2], 10, 64)
+		subID, errSub := strconv.ParseUint(parts[3], 10, 64)
+		if errChat != nil || errSub != nil {
+			return
+		}
+		privateSelf := msg.Chat.Type == telego.ChatTypePrivate && chatID == msg.Chat.ID
+		if !privateSelf && !isRequesterOrAdmin(ctx, b, chatID, query.From.ID, 0) {
+			answer(tr(ctx, "artw_admin_only"), true)
+			return
+		}
+		if err := h.Watch.Store.RemoveArtistSubscription(ctx, chatID, uint(subID)); err != nil {
+			answer(tr(ctx, "artw_failed"), true)
+			return
+		}
+		answer(tr(ctx, "artw_unfollowed"), false)
+		chatName := ""
+		if chatID != msg.Chat.ID {
+			chatName = strconv.FormatInt(chatID, 10)
+		}
+		text, keyboard := h.Watch.buildList(ctx, chatID, chatName)
+		params := &telego.EditMessageTextParams{
+			ChatID:             telego.ChatID{ID: msg.Chat.ID},
+			MessageID:          msg.MessageID,
+			Text:               text,
+			LinkPreviewOptions: &telego.LinkPreviewOptions{IsDisabled: true},
+		}
+		if keyboard != nil {
+			params.ReplyMarkup = keyboard
+		}
+		if h.Watch.RateLimiter != nil {
+			_, _ = telegram.EditMessageTextWithRetry(ctx, h.Watch.RateLimiter, b, params)
+		} else {
+			_, _ = b.EditMessageText(ctx, params)
+		}
+	case "d":
+		dispatchAnnouncedTrack(ctx, b, h.Watch.Music, query, msg, parts[2], parts[3])
+	}
+}
+
+// dispatchAnnouncedTrack sends one track offered by a bot-posted announcement
+// (artist release or playlist additions). Anyone in a private chat or group
+// may tap; in channels only admins can, since the audio is posted to every
+// subscriber. The tapping user is attributed as the requester so per-user
+// download quotas apply to them rather than to the bot's post.
+func dispatchAnnouncedTrack(ctx context.Context, b *telego.Bot, music *MusicHandler, query *telego.CallbackQuery, msg *telego.Message, platformName, trackID string) {
+	if msg.Chat.Type == telego.ChatTypeChannel && !isRequesterOrAdmin(ctx, b, msg.Chat.ID, query.From.ID, 0) {
+		_ = b.AnswerCallbackQuery(ctx, &telego.AnswerCallbackQueryParams{CallbackQueryID: query.ID, Text: tr(ctx, "cb_denied"), ShowAlert: true})
+		return
+	}
+	if music == nil {
+		return
+	}
+	release, acquired := tryAcquireCallbackInFlight(fmt.Sprintf("announce:%d:%s:%s", msg.Chat.ID, platformName, trackID), 5*time.Second)
+	if !acquired {
+		_ = b.AnswerCallbackQuery(ctx, &telego.AnswerCallbackQueryParams{CallbackQueryID: query.ID, Text: tr(ctx, "callback_success")})
+		return
+	}
+	defer release()
+	request := *msg
+	from := query.From
+	request.From = &from
+	accepted := music.dispatch(withSuppressDownloadRejectedMessage(withDisableFallback(withForceNonSilent(ctx))), b, &request, platformName, trackID, "")
+	if !accepted {
+		_ = b.AnswerCallbackQuery(ctx, &telego.AnswerCallbackQueryParams{CallbackQueryID: query.ID, Text: tr(ctx, "err_download_overloaded"), ShowAlert: true})
+		return
+	}
+	_ = b.AnswerCallbackQuery(ctx, &telego.AnswerCallbackQueryParams{CallbackQueryID: query.ID, Text: tr(ctx, "callback_success")})
+}
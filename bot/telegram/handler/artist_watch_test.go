@@ -0,0 +1,255 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	botpkg "github.com/liuran001/MusicBot-Go/bot"
+	"github.com/liuran001/MusicBot-Go/bot/platform"
+	"github.com/liuran001/MusicBot-Go/bot/platform/registry"
+)
+
+type stubReleasePlatform struct {
+	stubSearchPlatform
+	releases []platform.Album
+	calls    *int
+	err      error
+}
+
+func (s stubReleasePlatform) GetArtistReleases(ctx context.Context, artistID string, limit int) ([]platform.Album, error) {
+	if s.calls != nil {
+		*s.calls++
+	}
+	return s.releases, s.err
+}
+
+type memoryArtistStore struct {
+	subs    []*botpkg.ArtistSubscription
+	checked map[uint][]string
+	polled  map[uint]time.Time
+}
+
+func (m *memoryArtistStore) AddArtistSubscription(ctx context.Context, sub *botpkg.ArtistSubscription) error {
+	m.subs = append(m.subs, sub)
+	return nil
+}
+func (m *memoryArtistStore) RemoveArtistSubscription(ctx context.Context, chatID int64, id uint) error {
+	return nil
+}
+func (m *memoryArtistStore) IsArtistSubscribed(ctx context.Context, chatID int64, platformName, artistID string) (bool, error) {
+	return false, nil
+}
+func (m *memoryArtistStore) ListArtistSubscriptions(ctx context.Context, chatID int64) ([]*botpkg.ArtistSubscription, error) {
+	return m.subs, nil
+}
+func (m *memoryArtistStore) CountArtistSubscriptions(ctx context.Context, chatID int64) (int64, error) {
+	return int64(len(m.subs)), nil
+}
+func (m *memoryArtistStore) ListDueArtistSubscriptions(ctx context.Context, limit int) ([]*botpkg.ArtistSubscription, error) {
+	return m.subs, nil
+}
+func (m *memoryArtistStore) MarkArtistSubscriptionChecked(ctx context.Context, id uint, known []string, checkedAt time.Time) error {
+	if m.checked == nil {
+		m.checked = make(map[uint][]string)
+	}
+	m.checked[id] = known
+	return nil
+}
+func (m *memoryArtistStore) MarkArtistSubscriptionPolled(ctx context.Context, id uint, polledAt time.Time) error {
+	if m.polled == nil {
+		m.polled = make(map[uint]time.Time)
+	}
+	m.polled[id] = polledAt
+	return nil
+}
+
+func releaseAt(id string, date time.Time) platform.Album {
+	return platform.Album{ID: id, Title: "Release " + id, ReleaseDate: &date}
+}
+
+func TestDiffArtistReleasesSeedsOnFirstPoll(t *testing.T) {
+	now := time.Now()
+	releases := []platform.Album{releaseAt("b", now), releaseAt("a", now.Add(-time.Hour))}
+	fresh, known := diffArtistReleases(&botpkg.ArtistSubscription{}, releases)
+	if len(fresh) != 0 {
+		t.Fatalf("first poll announced %d releases, want 0", len(fresh))
+	}
+	if strings.Join(known, ",") != "b,a" {
+		t.Fatalf("known = %v, want [b a]", known)
+	}
+}
+
+func TestDiffArtistReleasesAnnouncesOnlyNewRecentReleases(t *testing.T) {
+	now := time.Now()
+	lastChecked := now.Add(-time.Hour)
+	sub := &botpkg.ArtistSubscription{KnownReleaseIDs: []string{"a", "old-dropped"}, LastCheckedAt: &lastChecked}
+	releases := []platform.Album{
+		releaseAt("c", now),
+		releaseAt("b", now.Add(-10*time.Minute)),
+		releaseAt("reissue", now.Add(-365*24*time.Hour)),
+		releaseAt("a", now.Add(-48*time.Hour)),
+	}
+	fresh, known := diffArtistReleases(sub, releases)
+	if len(fresh) != 2 || fresh[0].ID != "b" || fresh[1].ID != "c" {
+		t.Fatalf("fresh = %+v, want b then c (oldest first, stale reissue skipped)", fresh)
+	}
+	if strings.Join(known, ",") != "c,b,reissue,a,old-dropped" {
+		t.Fatalf("known = %v", known)
+	}
+}
+
+func TestDiffArtistReleasesCapsPostsPerPoll(t *testing.T) {
+	now := time.Now()
+	lastChecked := now.Add(-time.Hour)
+	sub := &botpkg.ArtistSubscription{KnownReleaseIDs: []string{"x"}, LastCheckedAt: &lastChecked}
+	var releases []platform.Album
+	for _, id := range []string{"r5", "r4", "r3", "r2", "r1"} {
+		releases = append(releases, releaseAt(id, now))
+	}
+	fresh, known := diffArtistReleases(sub, releases)
+	if len(fresh) != artistWatchMaxPosts {
+		t.Fatalf("fresh = %d, want %d", len(fresh), artistWatchMaxPosts)
+	}
+	if len(known) != 6 {
+		t.Fatalf("every fetched release must be marked seen, got %v", known)
+	}
+}
+
+func TestSplitArtistWatchArgs(t *testing.T) {
+	cases := []struct{ in, link, target string }{
+		{"", "", ""},
+		{"https://music.163.com/artist?id=6452", "https://music.163.com/artist?id=6452", ""},
+		{"https://music.163.com/artist?id=6452 @mychannel", "https://music.163.com/artist?id=6452", "@mychannel"},
+		{"https://music.163.com/artist?id=6452 -1001234567890", "https://music.163.com/artist?id=6452", "-1001234567890"},
+		{"@mychannel", "", "@mychannel"},
+	}
+	for _, tc := range cases {
+		link, target := splitArtistWatchArgs(tc.in)
+		if link != tc.link || target != tc.target {
+			t.Errorf("splitArtistWatchArgs(%q) = %q, %q; want %q, %q", tc.in, link, target, tc.link, tc.target)
+		}
+	}
+}
+
+func TestArtistReleaseKeyboardSkipsOversizedTrackIDs(t *testing.T) {
+	release := platform.Album{ID: "1", URL: "https://music.163.com/album?id=1"}
+	tracks := []platform.Track{
+		{ID: "186016", Title: "晴天"},
+		{ID: strings.Repeat("x", 80), Title: "Too long"},
+		{ID: "has space", Title: "Unsafe"},
+	}
+	keyboard := artistReleaseKeyboard(enCtx(), "netease", release, tracks)
+	rows := keyboard.InlineKeyboard
+	if len(rows) != 2 || len(rows[0]) != 1 {
+		t.Fatalf("rows = %+v, want one download button and one link row", rows)
+	}
+	if got := rows[0][0].CallbackData; got != "artw d netease 186016" {
+		t.Fatalf("callback data = %q", got)
+	}
+	if rows[1][0].URL != release.URL {
+		t.Fatalf("link button URL = %q", rows[1][0].URL)
+	}
+}
+
+func TestFollowKeyboardRequiresReleaseProvider(t *testing.T) {
+	manager := platform.NewManagerWithRegistry(registry.New())
+	manager.Register(stubSearchPlatform{name: "bilibili"})
+	manager.Register(stubReleasePlatform{stubSearchPlatform: stubSearchPlatform{name: "netease"}})
+	h := &ArtistWatchHandler{Store: &memoryArtistStore{}, PlatformManager: manager}
+
+	if keyboard := h.followKeyboard(enCtx(), "bilibili", "1"); keyboard != nil {
+		t.Fatalf("platform without releases got a follow button")
+	}
+	keyboard := h.followKeyboard(enCtx(), "netease", "6452")
+	if keyboard == nil || keyboard.InlineKeyboard[0][0].CallbackData != "artw f netease 6452" {
+		t.Fatalf("follow keyboard = %+v", keyboard)
+	}
+	var disabled *ArtistWatchHandler
+	if disabled.followKeyboard(enCtx(), "netease", "6452") != nil {
+		t.Fatalf("nil watch handler must not add a button")
+	}
+}
+
+func TestArtistReleaseWatcherSharesPollsAndRespectsPlatformBudget(t *testing.T) {
+	calls := 0
+	manager := platform.NewManagerWithRegistry(registry.New())
+	manager.Register(stubReleasePlatform{
+		stubSearchPlatform: stubSearchPlatform{name: "netease"},
+		releases:           []platform.Album{releaseAt("a", time.Now())},
+		calls:              &calls,
+	})
+	store := &memoryArtistStore{subs: []*botpkg.ArtistSubscription{
+		{ID: 1, ChatID: 10, Platform: "netease", ArtistID: "6452"},
+		{ID: 2, ChatID: 20, Platform: "netease", ArtistID: "6452"},
+		{ID: 3, ChatID: 30, Platform: "netease", ArtistID: "7777"},
+	}}
+	limiter := NewResourceRateLimiter(map[string]ResourceLimit{
+		ActionArtistWatch: {Window: time.Hour, PerPlatform: 1},
+	})
+	watcher := &ArtistReleaseWatcher{Store: store, PlatformManager: manager, ResourceLimiter: limiter}
+
+	// First polls only seed, so no Telegram client is needed.
+	watcher.RunOnce(context.Background(), nil)
+	if calls != 1 {
+		t.Fatalf("platform calls = %d, want 1 (shared artist poll, then budget exhausted)", calls)
+	}
+	if len(store.checked[1]) != 1 || len(store.checked[2]) != 1 {
+		t.Fatalf("both subscribers of the polled artist should be seeded: %v", store.checked)
+	}
+	if _, ok := store.checked[3]; ok {
+		t.Fatalf("over-budget artist must stay due for the next poll")
+	}
+}
+
+func TestArtistReleaseWatcherFailedPollKeepsUnseededSubscriptionsDue(t *testing.T) {
+	manager := platform.NewManagerWithRegistry(registry.New())
+	manager.Register(stubReleasePlatform{
+		stubSearchPlatform: stubSearchPlatform{name: "netease"},
+		err:                errors.New("upstream down"),
+	})
+	checkedAt := time.Now().Add(-time.Hour)
+	store := &memoryArtistStore{subs: []*botpkg.ArtistSubscription{
+		{ID: 1, ChatID: 10, Platform: "netease", ArtistID: "6452"},
+		{ID: 2, ChatID: 20, Platform: "netease", ArtistID: "6452", LastCheckedAt: &checkedAt, KnownReleaseIDs: []string{"a"}},
+	}}
+	watcher := &ArtistReleaseWatcher{Store: store, PlatformManager: manager}
+
+	watcher.RunOnce(context.Background(), nil)
+	if _, ok := store.checked[1]; ok {
+		t.Fatal("a failed poll must not mark a never-seeded subscription checked")
+	}
+	if _, ok := store.polled[1]; !ok {
+		t.Fatal("a never-seeded subscription should still rotate after a failed poll")
+	}
+	if _, ok := store.checked[2]; !ok {
+		t.Fatal("a seeded subscription should rotate after a failed poll")
+	}
+}
+
+func TestSeedArtistReleases(t *testing.T) {
+	plat := stubReleasePlatform{
+		stubSearchPlatform: stubSearchPlatform{name: "netease"},
+		releases:           []platform.Album{releaseAt("new", time.Now()), releaseAt("old", time.Now().AddDate(-5, 0, 0))},
+	}
+	h := &ArtistWatchHandler{}
+	if got := h.seedArtistReleases(context.Background(), plat, "netease", "6452"); strings.Join(got, ",") != "new,old" {
+		t.Fatalf("seedArtistReleases = %v, want the whole catalogue", got)
+	}
+	plat.err = errors.New("upstream down")
+	if got := h.seedArtistReleases(context.Background(), plat, "netease", "6452"); got != nil {
+		t.Fatalf("seedArtistReleases on failure = %v, want nil", got)
+	}
+
+	calls := 0
+	plat.err, plat.calls = nil, &calls
+	h.ResourceLimiter = NewResourceRateLimiter(map[string]ResourceLimit{
+		ActionArtistWatch: {Window: time.Hour, PerPlatform: 1},
+	})
+	h.seedArtistReleases(context.Background(), plat, "netease", "6452")
+	if got := h.seedArtistReleases(context.Background(), plat, "netease", "6452"); got != nil || calls != 1 {
+		t.Fatalf("seed over the watch budget = %v after %d calls, want nil after 1", got, calls)
+	}
+}
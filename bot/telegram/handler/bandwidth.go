@@ -0,0 +1,51 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/liuran001/MusicBot-Go/bot/bandwidth"
+	"github.com/mymmrac/telego"
+	"github.com/mymmrac/telego/telegoutil"
+)
+
+// withBandwidthUser tags ctx with the requesting user so their downloads and
+// uploads share the per-user bandwidth bucket.
+func withBandwidthUser(ctx context.Context, userID int64) context.Context {
+	if userID == 0 {
+		return ctx
+	}
+	return bandwidth.WithUser(ctx, userID)
+}
+
+// audioUpload returns the InputFile for uploading file to Telegram, shaped by
+// UploadBandwidth for the user tagged on ctx. The name is kept as os.File
+// reports it so the multipart field matches an unshaped upload.
+func (h *MusicHandler) audioUpload(ctx context.Context, file *os.File) telego.InputFile {
+	if h == nil || h.UploadBandwidth == nil || file == nil {
+		return telego.InputFile{File: file}
+	}
+	return telego.InputFile{File: telegoutil.NameReader(h.UploadBandwidth.Reader(ctx, file, ""), file.Name())}
+}
+
+// formatThroughput renders a byte rate with its limit for /queue, e.g.
+// "1.5 MB/s / 2.0 MB/s".
+func formatThroughput(bytesPerSecond, limit int64) string {
+	text := formatByteRate(bytesPerSecond)
+	if limit > 0 {
+		text += " / " + formatByteRate(limit)
+	}
+	return text
+}
+
+func formatByteRate(bytesPerSecond int64) string {
+	switch {
+	case bytesPerSecond >= 1024*1024:
+		return fmt.Sprintf("%.1f MB/s", float64(bytesPerSecond)/1024/1024)
+	case bytesPerSecond >= 1024:
+		return fmt.Sprintf("%.0f KB/s", float64(bytesPerSecond)/1024)
+	default:
+		return fmt.Sprintf("%d B/s", bytesPerSecond)
+	}
+}
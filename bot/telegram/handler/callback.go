@@ -374,7 +374,7 @@ func (h *CallbackMusicHandler) fetchEpisodes(ctx context.Context, userID, chatID
 	if plat == nil {
 		return nil, platform.ErrUnavailable
 	}
-	provider, ok := plat.(platform.EpisodeProvider)
+	provider, ok := platform.As[platform.EpisodeProvider](plat)
 	if !ok {
 		return nil, platform.ErrUnsupported
 	}
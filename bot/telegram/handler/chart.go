@@ -0,0 +1,329 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	botpkg "github.com/liuran001/MusicBot-Go/bot"
+	"github.com/liuran001/MusicBot-Go/bot/platform"
+	"github.com/liuran001/MusicBot-Go/bot/telegram"
+	"github.com/mymmrac/telego"
+)
+
+const (
+	// chartTrackLimit caps how many entries of a chart are loaded. Charts are
+	// fetched once and paged from memory, so the cap bounds both the API call
+	// and the cached state.
+	chartTrackLimit = 100
+	chartsPerRow    = 2
+)
+
+// ChartsHandler serves /charts: a platform → chart picker whose final step
+// renders the chart through PlaylistHandler, so paging and download buttons
+// behave exactly like an album/playlist link.
+type ChartsHandler struct {
+	PlatformManager platform.Manager
+	Playlist        *PlaylistHandler
+	RateLimiter     *telegram.RateLimiter
+	ResourceLimiter *ResourceRateLimiter
+}
+
+func (h *ChartsHandler) Handle(ctx context.Context, b *telego.Bot, update *telego.Update) {
+	if update == nil || update.Message == nil {
+		return
+	}
+	message := update.Message
+	requesterID := int64(0)
+	if message.From != nil {
+		requesterID = message.From.ID
+	}
+	platforms := chartPlatforms(h.PlatformManager)
+	if len(platforms) == 0 {
+		sendText(ctx, b, message.Chat.ID, message.MessageID, tr(ctx, "chart_none"))
+		return
+	}
+
+	text := tr(ctx, "chart_pick_platform")
+	keyboard := h.platformKeyboard(ctx, platforms, requesterID)
+	if alias := strings.TrimSpace(commandArguments(message.Text)); alias != "" {
+		platformName, ok := h.PlatformManager.ResolveAlias(alias)
+		if !ok || !containsString(platforms, platformName) {
+			sendText(ctx, b, message.Chat.ID, message.MessageID, tr(ctx, "chart_platform_unsupported"))
+			return
+		}
+		charts, err := listPlatformCharts(ctx, h.PlatformManager, platformName)
+		if err != nil || len(charts) == 0 {
+			sendText(ctx, b, message.Chat.ID, message.MessageID, tr(ctx, "chart_none"))
+			return
+		}
+		text = tr(ctx, "chart_pick_chart", map[string]any{"Platform": platformDisplayName(ctx, h.PlatformManager, platformName)})
+		keyboard = h.chartKeyboard(ctx, platformName, charts, requesterID)
+	}
+
+	params := &telego.SendMessageParams{
+		ChatID:          telego.ChatID{ID: message.Chat.ID},
+		MessageThreadID: message.MessageThreadID,
+		Text:            text,
+		ReplyParameters: buildReplyParams(message),
+		ReplyMarkup:     keyboard,
+	}
+	if h.RateLimiter != nil {
+		_, _ = telegram.SendMessageWithRetry(ctx, h.RateLimiter, b, params)
+	} else {
+		_, _ = b.SendMessage(ctx, params)
+	}
+}
+
+func (h *ChartsHandler) platformKeyboard(ctx context.Context, platforms []string, requesterID int64) *telego.InlineKeyboardMarkup {
+	buttons := make([]telego.InlineKeyboardButton, 0, len(platforms))
+	for _, name := range platforms {
+		buttons = append(buttons, telego.InlineKeyboardButton{
+			Text:         fmt.Sprintf("%s %s", platformEmoji(h.PlatformManager, name), platformDisplayName(ctx, h.PlatformManager, name)),
+			CallbackData: fmt.Sprintf("chart p %s %d", name, requesterID),
+		})
+	}
+	rows := chunkButtons(buttons, chartsPerRow)
+	rows = append(rows, []telego.InlineKeyboardButton{{Text: tr(ctx, "pl_close"), CallbackData: fmt.Sprintf("chart close %d", requesterID)}})
+	return &telego.InlineKeyboardMarkup{InlineKeyboard: rows}
+}
+
+// chartKeyboard addresses charts by list index rather than ID: chart IDs such
+// as Apple Music playlist IDs would overflow the 64-byte callback data limit.
+func (h *ChartsHandler) chartKeyboard(ctx context.Context, platformName string, charts []platform.Chart, requesterID int64) *telego.InlineKeyboardMarkup {
+	buttons := make([]telego.InlineKeyboardButton, 0, len(charts))
+	for idx, chart := range charts {
+		title := strings.TrimSpace(chart.Title)
+		if title == "" {
+			title = chart.ID
+		}
+		buttons = append(buttons, telego.InlineKeyboardButton{
+			Text:         title,
+			CallbackData: fmt.Sprintf("chart c %s %d %d", platformName, idx, requesterID),
+		})
+	}
+	rows := chunkButtons(buttons, chartsPerRow)
+	rows = append(rows, []telego.InlineKeyboardButton{
+		{Text: tr(ctx, "chart_back"), CallbackData: fmt.Sprintf("chart home %d", requesterID)},
+		{Text: tr(ctx, "pl_close"), CallbackData: fmt.Sprintf("chart close %d", requesterID)},
+	})
+	return &telego.InlineKeyboardMarkup{InlineKeyboard: rows}
+}
+
+// ChartCallbackHandler handles the "chart ..." picker callbacks.
+type ChartCallbackHandler struct {
+	Charts      *ChartsHandler
+	RateLimiter *telegram.RateLimiter
+}
+
+func (h *ChartCallbackHandler) Handle(ctx context.Context, b *telego.Bot, update *telego.Update) {
+	if update == nil || update.CallbackQuery == nil || h.Charts == nil {
+		return
+	}
+	query := update.CallbackQuery
+	parts := strings.Fields(query.Data)
+	if len(parts) < 3 || parts[0] != "chart" {
+		return
+	}
+	if query.Message == nil {
+		return
+	}
+	msg := query.Message.Message()
+	if msg == nil {
+		return
+	}
+	requesterID, _ := strconv.ParseInt(parts[len(parts)-1], 10, 64)
+	if msg.Chat.Type != "private" && !isRequesterOrAdmin(ctx, b, msg.Chat.ID, query.From.ID, requesterID) {
+		_ = b.AnswerCallbackQuery(ctx, &telego.AnswerCallbackQueryParams{
+			CallbackQueryID: query.ID,
+			Text:            tr(ctx, "callback_denied"),
+			ShowAlert:       true,
+		})
+		return
+	}
+	answer := func(text string) {
+		_ = b.AnswerCallbackQuery(ctx, &telego.AnswerCallbackQueryParams{CallbackQueryID: query.ID, Text: text})
+	}
+	manager := h.Charts.PlatformManager
+
+	switch parts[1] {
+	case "close":
+		deleteParams := &telego.DeleteMessageParams{ChatID: telego.ChatID{ID: msg.Chat.ID}, MessageID: msg.MessageID}
+		if h.RateLimiter != nil {
+			_ = telegram.DeleteMessageWithRetry(ctx, h.RateLimiter, b, deleteParams)
+		} else {
+			_ = b.DeleteMessage(ctx, deleteParams)
+		}
+		answer("")
+	case "home":
+		platforms := chartPlatforms(manager)
+		h.editPicker(ctx, b, msg, tr(ctx, "chart_pick_platform"), h.Charts.platformKeyboard(ctx, platforms, requesterID))
+		answer("")
+	case "p":
+		if len(parts) < 4 {
+			return
+		}
+		platformName := parts[2]
+		charts, err := listPlatformCharts(ctx, manager, platformName)
+		if err != nil || len(charts) == 0 {
+			answer(tr(ctx, "chart_none"))
+			return
+		}
+		text := tr(ctx, "chart_pick_chart", map[string]any{"Platform": platformDisplayName(ctx, manager, platformName)})
+		h.editPicker(ctx, b, msg, text, h.Charts.chartKeyboard(ctx, platformName, charts, requesterID))
+		answer("")
+	case "c":
+		if len(parts) < 5 {
+			return
+		}
+		index, err := strconv.Atoi(parts[3])
+		if err != nil {
+			return
+		}
+		guardKey := fmt.Sprintf("chart:%d:%d", msg.Chat.ID, msg.MessageID)
+		release, acquired := tryAcquireCallbackInFlight(guardKey, 15*time.Second)
+		if !acquired {
+			answer(tr(ctx, "pl_processing"))
+			return
+		}
+		defer release()
+		if errText := h.Charts.openChart(ctx, b, msg, query.From.ID, parts[2], index, requesterID); errText != "" {
+			answer(errText)
+			return
+		}
+		answer("")
+	}
+}
+
+func (h *ChartCallbackHandler) editPicker(ctx context.Context, b *telego.Bot, msg *telego.Message, text string, keyboard *telego.InlineKeyboardMarkup) {
+	params := &telego.EditMessageTextParams{
+		ChatID:      telego.ChatID{ID: msg.Chat.ID},
+		MessageID:   msg.MessageID,
+		Text:        text,
+		ReplyMarkup: keyboard,
+	}
+	if h.RateLimiter != nil {
+		_, _ = telegram.EditMessageTextWithRetry(ctx, h.RateLimiter, b, params)
+	} else {
+		_, _ = b.EditMessageText(ctx, params)
+	}
+}
+
+// openChart loads a chart and turns the picker message into a playlist page.
+// It returns a non-empty callback answer on failure.
+func (h *ChartsHandler) openChart(ctx context.Context, b *telego.Bot, msg *telego.Message, userID int64, platformName string, index int, requesterID int64) string {
+	if h.Playlist == nil || h.PlatformManager == nil {
+		return tr(ctx, "no_results")
+	}
+	plat := h.PlatformManager.Get(platformName)
+	provider, ok := platform.As[platform.ChartProvider](plat)
+	if !ok {
+		return tr(ctx, "chart_platform_unsupported")
+	}
+	charts, err := provider.ListCharts(ctx)
+	if err != nil || index < 0 || index >= len(charts) {
+		return tr(ctx, "pl_list_expired")
+	}
+	if !h.ResourceLimiter.AllowFor(ActionPlaylist, userID, msg.Chat.ID, platformName) {
+		return tr(ctx, "err_rate_limited")
+	}
+	chart, err := provider.GetChart(platform.WithPlaylistLimit(platform.WithPlaylistOffset(ctx, 0), chartTrackLimit), charts[index].ID)
+	if err != nil {
+		return userVisiblePlaylistError(ctx, err)
+	}
+	if chart == nil || len(chart.Tracks) == 0 {
+		return tr(ctx, "playlist_empty")
+	}
+	if len(chart.Tracks) > chartTrackLimit {
+		chart.Tracks = chart.Tracks[:chartTrackLimit]
+	}
+	if strings.TrimSpace(chart.Title) == "" {
+		chart.Title = charts[index].Title
+	}
+	// Charts are paged from the loaded window only: the platform-reported total
+	// (e.g. Kugou TOP500) would otherwise advertise pages that were never fetched.
+	chart.TrackCount = len(chart.Tracks)
+
+	qualityValue := h.Playlist.resolveDefaultQuality(ctx, msg, requesterID)
+	scopeType := botpkg.PluginScopeUser
+	scopeID := requesterID
+	if msg.Chat.Type != "private" {
+		scopeType = botpkg.PluginScopeGroup
+		scopeID = msg.Chat.ID
+	}
+	qualityValue = resolvePlatformQualityValue(ctx, h.Playlist.Repo, scopeType, scopeID, platformName, qualityValue, false)
+	qualityValue = qualityIntentToken(qualityValue, false)
+
+	collectionLabel := collectionTypeLabel(ctx, collectionTypeChart)
+	textHeader := fmt.Sprintf("%s *%s* %s\n\n", platformEmoji(h.PlatformManager, platformName), mdV2Replacer.Replace(platformDisplayName(ctx, h.PlatformManager, platformName)), collectionLabel)
+	textHeader += formatPlaylistInfo(ctx, chart, collectionLabel)
+	pageTracks, pageOffset := h.Playlist.slicePlaylistPage(chart.Tracks, 1)
+	pageText, keyboard := h.Playlist.buildPlaylistPage(ctx, pageTracks, chart.TrackCount, pageOffset, platformName, qualityValue, requesterID, msg.MessageID, 1)
+	h.Playlist.editPlaylistMessage(ctx, b, msg, textHeader+pageText, keyboard)
+
+	h.Playlist.storePlaylistState(msg.MessageID, &playlistState{
+		playlist:    *chart,
+		platform:    platformName,
+		collection:  collectionTypeChart,
+		quality:     qualityValue,
+		requesterID: requesterID,
+		currentPage: 1,
+		updatedAt:   time.Now(),
+		totalTracks: chart.TrackCount,
+	})
+	return ""
+}
+
+// chartPlatforms returns the registered platforms implementing
+// platform.ChartProvider, sorted by name for a stable picker layout.
+func chartPlatforms(manager platform.Manager) []string {
+	if manager == nil {
+		return nil
+	}
+	names := make([]string, 0)
+	for _, name := range manager.List() {
+		if _, ok := platform.As[platform.ChartProvider](manager.Get(name)); ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+func listPlatformCharts(ctx context.Context, manager platform.Manager, platformName string) ([]platform.Chart, error) {
+	if manager == nil {
+		return nil, platform.ErrUnsupported
+	}
+	provider, ok := platform.As[platform.ChartProvider](manager.Get(platformName))
+	if !ok {
+		return nil, platform.NewUnsupportedError(platformName, "charts")
+	}
+	return provider.ListCharts(ctx)
+}
+
+func chunkButtons(buttons []telego.InlineKeyboardButton, perRow int) [][]telego.InlineKeyboardButton {
+	if perRow <= 0 {
+		perRow = 1
+	}
+	rows := make([][]telego.InlineKeyboardButton, 0, (len(buttons)+perRow-1)/perRow)
+	for i := 0; i < len(buttons); i += perRow {
+		end := i + perRow
+		if end > len(buttons) {
+			end = len(buttons)
+		}
+		rows = append(rows, buttons[i:end])
+	}
+	return rows
+}
+
+func containsString(values []string, target string) bool {
+	for _, value := range values {
+		if value == target {
+			return true
+		}
+	}
+	return false
+}
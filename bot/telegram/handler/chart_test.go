@@ -0,0 +1,77 @@
+package handler
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/liuran001/MusicBot-Go/bot/platform"
+	"github.com/liuran001/MusicBot-Go/bot/platform/registry"
+)
+
+type stubChartPlatform struct {
+	stubSearchPlatform
+	charts []platform.Chart
+}
+
+func (s stubChartPlatform) ListCharts(ctx context.Context) ([]platform.Chart, error) {
+	return s.charts, nil
+}
+
+func (s stubChartPlatform) GetChart(ctx context.Context, chartID string) (*platform.Playlist, error) {
+	return &platform.Playlist{ID: chartID, Platform: s.name}, nil
+}
+
+func TestChartPlatformsOnlyListsChartProviders(t *testing.T) {
+	manager := platform.NewManagerWithRegistry(registry.New())
+	manager.Register(stubSearchPlatform{name: "bilibili"})
+	manager.Register(stubChartPlatform{stubSearchPlatform: stubSearchPlatform{name: "qqmusic"}})
+	manager.Register(stubChartPlatform{stubSearchPlatform: stubSearchPlatform{name: "netease"}})
+
+	got := chartPlatforms(manager)
+	if strings.Join(got, ",") != "netease,qqmusic" {
+		t.Fatalf("chartPlatforms() = %v, want [netease qqmusic]", got)
+	}
+}
+
+func TestChartKeyboardUsesIndexInCallbackData(t *testing.T) {
+	manager := platform.NewManagerWithRegistry(registry.New())
+	charts := []platform.Chart{
+		{ID: "pl.d25f5d1181894928af76c85c967f8f31", Title: "Top 100: Global"},
+		{ID: "pl.606afcbb70264d2eb2b51d8dbcfa6a12", Title: "Top 100: USA"},
+		{ID: "pl.x"},
+	}
+	manager.Register(stubChartPlatform{stubSearchPlatform: stubSearchPlatform{name: "applemusic"}, charts: charts})
+	h := &ChartsHandler{PlatformManager: manager}
+
+	keyboard := h.chartKeyboard(zhCtx(), "applemusic", charts, 1234567890)
+	rows := keyboard.InlineKeyboard
+	if len(rows) != 3 {
+		t.Fatalf("rows = %d, want 3 (2 chart rows + nav row)", len(rows))
+	}
+	if got := rows[0][1].CallbackData; got != "chart c applemusic 1 1234567890" {
+		t.Fatalf("callback data = %q", got)
+	}
+	if got := rows[1][0].Text; got != "pl.x" {
+		t.Fatalf("untitled chart button text = %q, want chart ID", got)
+	}
+	for _, row := range rows {
+		for _, button := range row {
+			if len(button.CallbackData) > 64 {
+				t.Fatalf("callback data %q exceeds 64 bytes", button.CallbackData)
+			}
+		}
+	}
+	if got := rows[2][0].CallbackData; got != "chart home 1234567890" {
+		t.Fatalf("back callback data = %q", got)
+	}
+}
+
+func TestCollectionTypeChartRoundTrip(t *testing.T) {
+	if got := detectCollectionType(collectionTypeChart, "https://y.qq.com/n/ryqq_v2/toplist/62"); got != collectionTypeChart {
+		t.Fatalf("detectCollectionType(chart) = %q", got)
+	}
+	if got := collectionTypeLabel(zhCtx(), collectionTypeChart); got != "榜单" {
+		t.Fatalf("collectionTypeLabel(chart) = %q", got)
+	}
+}
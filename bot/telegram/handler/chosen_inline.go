@@ -117,7 +117,7 @@ func (h *ChosenInlineMusicHandler) tryPresentChosenInlineEpisodePicker(ctx conte
 	if plat == nil {
 		return false
 	}
-	provider, ok := plat.(platform.EpisodeProvider)
+	provider, ok := platform.As[platform.EpisodeProvider](plat)
 	if !ok {
 		return false
 	}
@@ -234,7 +234,7 @@ func (h *ChosenInlineMusicHandler) handleChosenEpisodeCollection(ctx context.Con
 	if plat == nil {
 		return
 	}
-	provider, ok := plat.(platform.EpisodeProvider)
+	provider, ok := platform.As[platform.EpisodeProvider](plat)
 	if !ok {
 		return
 	}
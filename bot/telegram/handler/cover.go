@@ -0,0 +1,368 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	botpkg "github.com/liuran001/MusicBot-Go/bot"
+	"github.com/liuran001/MusicBot-Go/bot/download"
+	"github.com/liuran001/MusicBot-Go/bot/platform"
+	"github.com/liuran001/MusicBot-Go/bot/telegram"
+	"github.com/mymmrac/telego"
+	telegoutil "github.com/mymmrac/telego/telegoutil"
+)
+
+// Embedded-cover policies. Besides these, a number caps the longest edge of
+// the embedded cover in pixels.
+const (
+	coverEmbedDefault  = ""
+	coverEmbedOriginal = "original"
+	coverEmbedNone     = "none"
+)
+
+// coverEmbedChoices are the policies offered in /settings, in display order.
+var coverEmbedChoices = []string{coverEmbedDefault, coverEmbedOriginal, "1400", "600", coverEmbedNone}
+
+// animatedCoverTimeout bounds fetching and remuxing a motion cover.
+const animatedCoverTimeout = 2 * time.Minute
+
+// normalizeCoverEmbed returns the stored form of a policy, accepting
+// "default" for the empty default.
+func normalizeCoverEmbed(value string) (string, bool) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if value == "default" {
+		value = coverEmbedDefault
+	}
+	for _, choice := range coverEmbedChoices {
+		if value == choice {
+			return value, true
+		}
+	}
+	return "", false
+}
+
+// coverEmbedMaxEdge is the size cap of a numeric policy, 0 otherwise.
+func coverEmbedMaxEdge(policy string) int {
+	n, err := strconv.Atoi(policy)
+	if err != nil || n <= 0 {
+		return 0
+	}
+	return n
+}
+
+// trackCoverURL is the track's own cover, falling back to its album's.
+func trackCoverURL(track *platform.Track) string {
+	if track == nil {
+		return ""
+	}
+	if track.CoverURL != "" {
+		return track.CoverURL
+	}
+	if track.Album != nil {
+		return track.Album.CoverURL
+	}
+	return ""
+}
+
+// downloadCover fetches coverURL to dst and fails on an empty body.
+func (h *MusicHandler) downloadCover(ctx context.Context, track *platform.Track, coverURL, dst string) error {
+	if h.DownloadService == nil {
+		return errors.New("download service not configured")
+	}
+	if _, err := h.DownloadService.Download(download.WithPlatform(ctx, track.Platform), &platform.DownloadInfo{URL: coverURL}, dst, nil); err != nil {
+		_ = os.Remove(dst)
+		return err
+	}
+	if stat, err := os.Stat(dst); err != nil || stat.Size() <= 0 {
+		_ = os.Remove(dst)
+		return errors.New("cover file is empty")
+	}
+	return nil
+}
+
+// applyCoverEmbed replaces the cover embedded in audioPath according to
+// policy. Files it downloads go to dir.
+func (h *MusicHandler) applyCoverEmbed(ctx context.Context, policy string, plat platform.Platform, track *platform.Track, dir, audioPath string, songInfo *botpkg.SongInfo) error {
+	if h.ID3Service == nil {
+		return errors.New("tag service not configured")
+	}
+	if policy == coverEmbedNone {
+		if err := h.ID3Service.RemoveCover(audioPath); err != nil {
+			return err
+		}
+		songInfo.EmbPicSize = 0
+		return nil
+	}
+	coverURL := trackCoverURL(track)
+	if coverURL == "" {
+		return nil
+	}
+	maxEdge := coverEmbedMaxEdge(policy)
+	coverURL = platform.CoverURLAtSize(plat, coverURL, maxEdge)
+	picPath := filepath.Join(dir, "cover-"+path.Base(strings.SplitN(coverURL, "?", 2)[0]))
+	if err := h.downloadCover(ctx, track, coverURL, picPath); err != nil {
+		return err
+	}
+	if maxEdge > 0 {
+		// Platforms without an ArtworkProvider, or whose CDN rounds sizes,
+		// may still serve a larger image than asked.
+		fitted, err := fitImg(picPath, maxEdge)
+		if err != nil {
+			return err
+		}
+		picPath = fitted
+	}
+	if err := h.ID3Service.EmbedCover(audioPath, picPath); err != nil {
+		return err
+	}
+	songInfo.EmbPicSize = int(fileSizeOf(picPath))
+	return nil
+}
+
+// CoverHandler handles /cover: the track's full-resolution cover as a file,
+// plus the album's motion artwork as an MP4 where the platform has one.
+type CoverHandler struct {
+	Music       *MusicHandler
+	RateLimiter *telegram.RateLimiter
+}
+
+func (h *CoverHandler) Handle(ctx context.Context, b *telego.Bot, update *telego.Update) {
+	if h == nil || h.Music == nil || update == nil || update.Message == nil {
+		return
+	}
+	message := update.Message
+	args := commandArguments(message.Text)
+	if args == "" && message.ReplyToMessage != nil {
+		args = repliedMessageQuery(message.ReplyToMessage)
+	}
+	platformName, trackID, found := "", "", false
+	if args != "" && h.Music.PlatformManager != nil {
+		platformName, trackID, found = extractPlatformTrackFromMessage(ctx, args, h.Music.PlatformManager)
+	}
+	if !found {
+		h.reply(ctx, b, message, tr(ctx, "cover_usage"))
+		return
+	}
+
+	userID := int64(0)
+	if message.From != nil {
+		userID = message.From.ID
+	}
+	if !h.Music.ResourceLimiter.AllowFor(ActionDownload, userID, message.Chat.ID, platformName) {
+		h.reply(ctx, b, message, userVisibleDownloadError(ctx, platform.ErrRateLimited))
+		return
+	}
+
+	status := h.reply(ctx, b, message, tr(ctx, "cover_fetching"))
+	finish := func(text string) {
+		if status == nil {
+			return
+		}
+		if text == "" {
+			params := &telego.DeleteMessageParams{ChatID: telego.ChatID{ID: status.Chat.ID}, MessageID: status.MessageID}
+			if h.RateLimiter != nil {
+				_ = telegram.DeleteMessageWithRetry(ctx, h.RateLimiter, b, params)
+			} else {
+				_ = b.DeleteMessage(ctx, params)
+			}
+			return
+		}
+		params := &telego.EditMessageTextParams{ChatID: telego.ChatID{ID: status.Chat.ID}, MessageID: status.MessageID, Text: text}
+		if h.RateLimiter != nil {
+			_, _ = telegram.EditMessageTextWithRetry(ctx, h.RateLimiter, b, params)
+		} else {
+			_, _ = b.EditMessageText(ctx, params)
+		}
+	}
+
+	plat := h.Music.PlatformManager.Get(platformName)
+	track, err := h.Music.getTrackSingleflight(ctx, platformName, trackID)
+	if err != nil || track == nil || plat == nil {
+		if h.Music.Logger != nil {
+			h.Music.Logger.Warn("cover: failed to load track", "platform", platformName, "trackID", trackID, "error", err)
+		}
+		finish(tr(ctx, "cover_failed"))
+		return
+	}
+	coverURL := trackCoverURL(track)
+	if coverURL == "" {
+		finish(tr(ctx, "cover_not_found"))
+		return
+	}
+
+	dir, err := os.MkdirTemp(h.Music.CacheDir, "cover-")
+	if err != nil {
+		finish(tr(ctx, "cover_failed"))
+		return
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	title := coverTitle(track)
+	picPath := filepath.Join(dir, "cover")
+	if err := h.Music.downloadCover(ctx, track, platform.CoverURLAtSize(plat, coverURL, 0), picPath); err != nil {
+		// The original variant is not always available; the catalog cover is.
+		if err := h.Music.downloadCover(ctx, track, coverURL, picPath); err != nil {
+			if h.Music.Logger != nil {
+				h.Music.Logger.Warn("cover: download failed", "platform", platformName, "trackID", trackID, "error", downloadErrorForLog(err))
+			}
+			finish(tr(ctx, "cover_failed"))
+			return
+		}
+	}
+	if err := h.sendFile(ctx, b, message, picPath, sanitizeFileName(title+coverFileExt(picPath)), tr(ctx, "cover_caption", map[string]any{"Title": title})); err != nil {
+		if h.Music.Logger != nil {
+			h.Music.Logger.Warn("cover: send failed", "platform", platformName, "trackID", trackID, "error", err)
+		}
+		finish(tr(ctx, "cover_failed"))
+		return
+	}
+
+	provider, ok := platform.As[platform.AnimatedArtworkProvider](plat)
+	if !ok || track.Album == nil || track.Album.ID == "" {
+		finish("")
+		return
+	}
+	animated, err := provider.GetAnimatedArtwork(ctx, track.Album.ID)
+	if err != nil || animated == nil || animated.URL == "" {
+		finish("")
+		return
+	}
+	finish(tr(ctx, "cover_animated_converting"))
+	videoPath := filepath.Join(dir, "animated.mp4")
+	if err := h.Music.saveAnimatedCover(withBandwidthUser(ctx, userID), platformName, animated.URL, dir, videoPath); err != nil {
+		if h.Music.Logger != nil {
+			h.Music.Logger.Warn("cover: animated artwork remux failed", "platform", platformName, "albumID", track.Album.ID, "error", err)
+		}
+		finish(tr(ctx, "cover_animated_failed"))
+		return
+	}
+	if err := h.sendFile(ctx, b, message, videoPath, sanitizeFileName(title+".mp4"), tr(ctx, "cover_animated_caption", map[string]any{"Title": title})); err != nil {
+		if h.Music.Logger != nil {
+			h.Music.Logger.Warn("cover: animated artwork send failed", "platform", platformName, "albumID", track.Album.ID, "error", err)
+		}
+		finish(tr(ctx, "cover_animated_failed"))
+		return
+	}
+	finish("")
+}
+
+func (h *CoverHandler) reply(ctx context.Context, b *telego.Bot, message *telego.Message, text string) *telego.Message {
+	params := &telego.SendMessageParams{
+		ChatID:          telego.ChatID{ID: message.Chat.ID},
+		MessageThreadID: message.MessageThreadID,
+		Text:            text,
+		ReplyParameters: buildReplyParams(message),
+	}
+	var sent *telego.Message
+	if h.RateLimiter != nil {
+		sent, _ = telegram.SendMessageWithRetry(ctx, h.RateLimiter, b, params)
+	} else {
+		sent, _ = b.SendMessage(ctx, params)
+	}
+	return sent
+}
+
+// sendFile sends filePath as a document so Telegram keeps it uncompressed.
+func (h *CoverHandler) sendFile(ctx context.Context, b *telego.Bot, message *telego.Message, filePath, fileName, caption string) error {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	params := &telego.SendDocumentParams{
+		ChatID:                      telego.ChatID{ID: message.Chat.ID},
+		MessageThreadID:             message.MessageThreadID,
+		Document:                    telego.InputFile{File: telegoutil.NameReader(file, fileName)},
+		Caption:                     caption,
+		DisableContentTypeDetection: true,
+		ReplyParameters:             buildReplyParams(message),
+	}
+	if h.RateLimiter != nil {
+		_, err = telegram.SendDocumentWithRetry(ctx, h.RateLimiter, b, params)
+	} else {
+		_, err = b.SendDocument(ctx, params)
+	}
+	return err
+}
+
+// coverTitle names cover files "<artists> - <album>", or the track title for
+// singles without album metadata.
+func coverTitle(track *platform.Track) string {
+	names := make([]string, 0, len(track.Artists))
+	for _, artist := range track.Artists {
+		names = append(names, artist.Name)
+	}
+	if track.Album != nil && len(track.Album.Artists) > 0 {
+		names = names[:0]
+		for _, artist := range track.Album.Artists {
+			names = append(names, artist.Name)
+		}
+	}
+	name := track.Title
+	if track.Album != nil && strings.TrimSpace(track.Album.Title) != "" {
+		name = track.Album.Title
+	}
+	if len(names) == 0 {
+		return name
+	}
+	return strings.Join(names, ",") + " - " + name
+}
+
+// coverFileExt picks the extension from the image's content.
+func coverFileExt(picPath string) string {
+	file, err := os.Open(picPath)
+	if err != nil {
+		return ".jpg"
+	}
+	defer file.Close()
+	head := make([]byte, 512)
+	n, _ := file.Read(head)
+	switch http.DetectContentType(head[:n]) {
+	case "image/png":
+		return ".png"
+	case "image/webp":
+		return ".webp"
+	default:
+		return ".jpg"
+	}
+}
+
+// saveAnimatedCover fetches the HLS motion cover at playlistURL into dir and
+// remuxes it to out.
+func (h *MusicHandler) saveAnimatedCover(ctx context.Context, platformName, playlistURL, dir, out string) error {
+	ctx, cancel := context.WithTimeout(ctx, animatedCoverTimeout)
+	defer cancel()
+	local, err := h.fetchAnimatedCover(ctx, platformName, playlistURL, dir)
+	if err != nil {
+		return err
+	}
+	return remuxAnimatedCover(ctx, local, out)
+}
+
+// remuxAnimatedCover copies a locally fetched HLS motion cover into an MP4
+// without re-encoding; the loops carry no audio worth keeping. ffmpeg may only
+// open local files, so nothing bypasses the download service.
+func remuxAnimatedCover(ctx context.Context, playlistPath, out string) error {
+	ffmpegPath, err := exec.LookPath("ffmpeg")
+	if err != nil {
+		return err
+	}
+	args := []string{"-y", "-protocol_whitelist", "file", "-i", playlistPath, "-c", "copy", "-an", "-movflags", "+faststart", out}
+	cmd := exec.CommandContext(ctx, ffmpegPath, args...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("remux animated cover: %w, stderr: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
@@ -0,0 +1,197 @@
+package handler
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/liuran001/MusicBot-Go/bot/download"
+	"github.com/liuran001/MusicBot-Go/bot/platform"
+)
+
+// animatedCoverMaxSegments caps the files fetched for one motion cover; the
+// loops last seconds, so a longer playlist is not artwork.
+const animatedCoverMaxSegments = 300
+
+// hlsSegmentExts are the segment extensions ffmpeg's HLS demuxer opens from
+// local files; anything else is stored as .ts.
+var hlsSegmentExts = map[string]bool{".ts": true, ".mp4": true, ".m4s": true, ".m4v": true, ".aac": true}
+
+// fetchAnimatedCover downloads the HLS motion cover at playlistURL into dir
+// and returns a local media playlist for remuxAnimatedCover. Every request
+// goes through DownloadService, so the fetch takes the platform's proxy route
+// and counts against the download bandwidth limits. Of a master playlist the
+// highest-bandwidth variant is taken.
+func (h *MusicHandler) fetchAnimatedCover(ctx context.Context, platformName, playlistURL, dir string) (string, error) {
+	if h.DownloadService == nil {
+		return "", errors.New("download service not configured")
+	}
+	ctx = download.WithPlatform(ctx, platformName)
+	mediaURL := playlistURL
+	lines, err := h.fetchHLSPlaylist(ctx, playlistURL, filepath.Join(dir, "master.m3u8"))
+	if err != nil {
+		return "", err
+	}
+	if variant := bestHLSVariant(lines); variant != "" {
+		if mediaURL, err = resolveHLSURI(playlistURL, variant); err != nil {
+			return "", err
+		}
+		if lines, err = h.fetchHLSPlaylist(ctx, mediaURL, filepath.Join(dir, "media.m3u8")); err != nil {
+			return "", err
+		}
+	}
+
+	// Byte-range playlists name one file many times; fetch it once.
+	fetched := make(map[string]string)
+	fetch := func(uri string) (string, error) {
+		if name, ok := fetched[uri]; ok {
+			return name, nil
+		}
+		if len(fetched) >= animatedCoverMaxSegments {
+			return "", fmt.Errorf("animated cover has more than %d segments", animatedCoverMaxSegments)
+		}
+		abs, err := resolveHLSURI(mediaURL, uri)
+		if err != nil {
+			return "", err
+		}
+		ext := strings.ToLower(path.Ext(strings.SplitN(uri, "?", 2)[0]))
+		if !hlsSegmentExts[ext] {
+			ext = ".ts"
+		}
+		name := fmt.Sprintf("segment-%04d%s", len(fetched), ext)
+		if _, err := h.DownloadService.Download(ctx, &platform.DownloadInfo{URL: abs}, filepath.Join(dir, name), nil); err != nil {
+			return "", err
+		}
+		fetched[uri] = name
+		return name, nil
+	}
+
+	local := make([]string, 0, len(lines))
+	segments := 0
+	for _, line := range lines {
+		switch {
+		case strings.HasPrefix(line, "#EXT-X-KEY:") && hlsAttribute(line, "METHOD") != "NONE":
+			return "", errors.New("animated cover is encrypted")
+		case strings.HasPrefix(line, "#EXT-X-MAP:"):
+			uri := hlsAttribute(line, "URI")
+			name, err := fetch(uri)
+			if err != nil {
+				return "", err
+			}
+			line = strings.Replace(line, strconv.Quote(uri), strconv.Quote(name), 1)
+		case line == "" || strings.HasPrefix(line, "#"):
+		default:
+			name, err := fetch(line)
+			if err != nil {
+				return "", err
+			}
+			line = name
+			segments++
+		}
+		local = append(local, line)
+	}
+	if segments == 0 {
+		return "", errors.New("animated cover playlist has no segments")
+	}
+	out := filepath.Join(dir, "local.m3u8")
+	if err := os.WriteFile(out, []byte(strings.Join(local, "\n")+"\n"), 0o644); err != nil {
+		return "", err
+	}
+	return out, nil
+}
+
+// fetchHLSPlaylist downloads a playlist to dst and returns its trimmed lines.
+func (h *MusicHandler) fetchHLSPlaylist(ctx context.Context, playlistURL, dst string) ([]string, error) {
+	if _, err := h.DownloadService.Download(ctx, &platform.DownloadInfo{URL: playlistURL}, dst, nil); err != nil {
+		return nil, err
+	}
+	f, err := os.Open(dst)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var lines []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		lines = append(lines, strings.TrimSpace(scanner.Text()))
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(lines) == 0 || !strings.HasPrefix(lines[0], "#EXTM3U") {
+		return nil, errors.New("animated cover is not an HLS playlist")
+	}
+	return lines, nil
+}
+
+// bestHLSVariant returns the URI of the highest-bandwidth variant of a master
+// playlist, or "" for a media playlist.
+func bestHLSVariant(lines []string) string {
+	best, bestBandwidth := "", int64(-1)
+	for i, line := range lines {
+		if !strings.HasPrefix(line, "#EXT-X-STREAM-INF:") {
+			continue
+		}
+		bandwidth, _ := strconv.ParseInt(hlsAttribute(line, "BANDWIDTH"), 10, 64)
+		for _, next := range lines[i+1:] {
+			if next == "" || strings.HasPrefix(next, "#") {
+				continue
+			}
+			if bandwidth > bestBandwidth {
+				best, bestBandwidth = next, bandwidth
+			}
+			break
+		}
+	}
+	return best
+}
+
+// hlsAttribute returns the value of key in a tag's attribute list, unquoted.
+func hlsAttribute(line, key string) string {
+	_, attrs, ok := strings.Cut(line, ":")
+	if !ok {
+		return ""
+	}
+	for attrs != "" {
+		name, rest, ok := strings.Cut(attrs, "=")
+		if !ok {
+			return ""
+		}
+		var value string
+		if strings.HasPrefix(rest, `"`) {
+			end := strings.Index(rest[1:], `"`)
+			if end < 0 {
+				return ""
+			}
+			value, rest = rest[1:end+1], rest[end+2:]
+		} else {
+			value, rest, _ = strings.Cut(rest, ",")
+			rest = "," + rest
+		}
+		if strings.TrimSpace(name) == key {
+			return value
+		}
+		attrs = strings.TrimPrefix(rest, ",")
+	}
+	return ""
+}
+
+// resolveHLSURI resolves a playlist entry against the playlist's URL.
+func resolveHLSURI(base, ref string) (string, error) {
+	baseURL, err := url.Parse(base)
+	if err != nil {
+		return "", err
+	}
+	refURL, err := url.Parse(ref)
+	if err != nil {
+		return "", err
+	}
+	return baseURL.ResolveReference(refURL).String(), nil
+}
@@ -0,0 +1,120 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	botpkg "github.com/liuran001/MusicBot-Go/bot"
+	"github.com/liuran001/MusicBot-Go/bot/download"
+	"github.com/liuran001/MusicBot-Go/bot/platform"
+)
+
+func TestNormalizeCoverEmbed(t *testing.T) {
+	for in, want := range map[string]string{"": "", "Default": "", " original ": "original", "1400": "1400", "none": "none"} {
+		if got, ok := normalizeCoverEmbed(in); !ok || got != want {
+			t.Fatalf("normalizeCoverEmbed(%q) = %q, %v; want %q", in, got, ok, want)
+		}
+	}
+	if _, ok := normalizeCoverEmbed("999"); ok {
+		t.Fatal("normalizeCoverEmbed accepted a size not offered in settings")
+	}
+	if coverEmbedMaxEdge("600") != 600 || coverEmbedMaxEdge(coverEmbedOriginal) != 0 {
+		t.Fatal("coverEmbedMaxEdge() mismatch")
+	}
+}
+
+func TestSetCoverEmbed(t *testing.T) {
+	group := &botpkg.GroupSettings{}
+	if !setCoverEmbed("supergroup", nil, group, coverEmbedOriginal) || group.CoverEmbed != coverEmbedOriginal {
+		t.Fatalf("setCoverEmbed(group) = %q", group.CoverEmbed)
+	}
+	if setCoverEmbed("supergroup", nil, group, coverEmbedOriginal) {
+		t.Fatal("setCoverEmbed() reported a change for the same policy")
+	}
+	user := &botpkg.UserSettings{CoverEmbed: "600"}
+	if !setCoverEmbed("private", user, nil, coverEmbedDefault) || user.CoverEmbed != "" {
+		t.Fatalf("setCoverEmbed(private) = %q", user.CoverEmbed)
+	}
+}
+
+func TestCoverTitle(t *testing.T) {
+	track := &platform.Track{Title: "Song", Artists: []platform.Artist{{Name: "A"}, {Name: "B"}}}
+	if got := coverTitle(track); got != "A,B - Song" {
+		t.Fatalf("coverTitle(single) = %q", got)
+	}
+	track.Album = &platform.Album{Title: "Album", Artists: []platform.Artist{{Name: "A"}}}
+	if got := coverTitle(track); got != "A - Album" {
+		t.Fatalf("coverTitle(album) = %q", got)
+	}
+}
+
+func TestFetchAnimatedCoverGoesThroughDownloadService(t *testing.T) {
+	files := map[string]string{
+		"/master.m3u8":     "#EXTM3U\n#EXT-X-STREAM-INF:BANDWIDTH=800000\nlow/index.m3u8\n#EXT-X-STREAM-INF:BANDWIDTH=4000000\nhigh/index.m3u8\n",
+		"/high/index.m3u8": "#EXTM3U\n#EXT-X-MAP:URI=\"init.mp4\"\n#EXTINF:2.0,\nseg0.m4s?sig=a\n#EXTINF:2.0,\n/high/seg1.m4s\n#EXT-X-ENDLIST\n",
+		"/high/init.mp4":   "init",
+		"/high/seg0.m4s":   "seg0",
+		"/high/seg1.m4s":   "seg1",
+	}
+	var mu sync.Mutex
+	var requested []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requested = append(requested, r.URL.Path)
+		mu.Unlock()
+		body, ok := files[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte(body))
+	}))
+	defer server.Close()
+
+	h := &MusicHandler{DownloadService: download.NewDownloadService(download.DownloadServiceOptions{Timeout: 10 * time.Second})}
+	dir := t.TempDir()
+	local, err := h.fetchAnimatedCover(context.Background(), "applemusic", server.URL+"/master.m3u8", dir)
+	if err != nil {
+		t.Fatalf("fetchAnimatedCover() error = %v", err)
+	}
+	data, err := os.ReadFile(local)
+	if err != nil {
+		t.Fatal(err)
+	}
+	playlist := string(data)
+	for _, want := range []string{`#EXT-X-MAP:URI="segment-0000.mp4"`, "\nsegment-0001.m4s\n", "\nsegment-0002.m4s\n"} {
+		if !strings.Contains(playlist, want) {
+			t.Fatalf("local playlist missing %q:\n%s", want, playlist)
+		}
+	}
+	if strings.Contains(playlist, "http") {
+		t.Fatalf("local playlist still points at the network:\n%s", playlist)
+	}
+	if got, _ := os.ReadFile(filepath.Join(dir, "segment-0002.m4s")); string(got) != "seg1" {
+		t.Fatalf("segment-0002.m4s = %q, want seg1", got)
+	}
+	for _, path := range requested {
+		if strings.HasPrefix(path, "/low/") {
+			t.Fatalf("fetched the low-bandwidth variant: %v", requested)
+		}
+	}
+}
+
+func TestFetchAnimatedCoverRejectsEncryptedPlaylist(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("#EXTM3U\n#EXT-X-KEY:METHOD=AES-128,URI=\"key\"\n#EXTINF:2.0,\nseg0.ts\n"))
+	}))
+	defer server.Close()
+
+	h := &MusicHandler{DownloadService: download.NewDownloadService(download.DownloadServiceOptions{Timeout: 10 * time.Second})}
+	if _, err := h.fetchAnimatedCover(context.Background(), "", server.URL+"/index.m3u8", t.TempDir()); err == nil {
+		t.Fatal("fetchAnimatedCover() accepted an encrypted playlist")
+	}
+}
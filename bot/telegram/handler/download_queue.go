@@ -139,6 +139,10 @@ func formatDownloadQueueSnapshot(ctx context.Context, s DownloadQueueSnapshot, s
 		"UploadRunning":    s.UploadRunning,
 		"UploadLimit":      uploadLimit,
 	})
+	text += "\n" + tr(ctx, "download_queue_throughput", map[string]any{
+		"Download": formatThroughput(s.DownloadRate, s.DownloadRateLimit),
+		"Upload":   formatThroughput(s.UploadRate, s.UploadRateLimit),
+	})
 	if send == nil || send.capacity <= 0 {
 		return text
 	}
@@ -380,3 +380,20 @@ func TestTruncateCallbackText(t *testing.T) {
 		t.Fatalf("truncated callback text should end with ellipsis: %q", got)
 	}
 }
+
+func TestFormatThroughput(t *testing.T) {
+	cases := []struct {
+		rate, limit int64
+		want        string
+	}{
+		{0, 0, "0 B/s"},
+		{512, 0, "512 B/s"},
+		{300 * 1024, 0, "300 KB/s"},
+		{1536 * 1024, 2 * 1024 * 1024, "1.5 MB/s / 2.0 MB/s"},
+	}
+	for _, tc := range cases {
+		if got := formatThroughput(tc.rate, tc.limit); got != tc.want {
+			t.Fatalf("formatThroughput(%d, %d) = %q, want %q", tc.rate, tc.limit, got, tc.want)
+		}
+	}
+}
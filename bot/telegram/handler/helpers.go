@@ -6,6 +6,8 @@ import (
 	"errors"
 	"fmt"
 	"html"
+	htmltemplate "html/template"
+	"math"
 	"net/http"
 	"net/url"
 	"os"
@@ -20,7 +22,9 @@ import (
 	"unicode/utf8"
 
 	botpkg "github.com/liuran001/MusicBot-Go/bot"
+	"github.com/liuran001/MusicBot-Go/bot/loudness"
 	"github.com/liuran001/MusicBot-Go/bot/platform"
+	"github.com/liuran001/MusicBot-Go/bot/render"
 	"github.com/mymmrac/telego"
 )
 
@@ -489,7 +493,7 @@ func shouldResolveHost(host string, manager platform.Manager) bool {
 		if plat == nil {
 			continue
 		}
-		provider, ok := plat.(platform.ShortLinkProvider)
+		provider, ok := platform.As[platform.ShortLinkProvider](plat)
 		if !ok {
 			continue
 		}
@@ -519,7 +523,7 @@ func matchPlaylistURL(ctx context.Context, manager platform.Manager, text string
 		if plat == nil {
 			continue
 		}
-		if matcher, ok := plat.(platform.PlaylistURLMatcher); ok {
+		if matcher, ok := platform.As[platform.PlaylistURLMatcher](plat); ok {
 			if id, ok := matcher.MatchPlaylistURL(urlStr); ok {
 				return name, id, true
 			}
@@ -541,7 +545,7 @@ func matchArtistURL(ctx context.Context, manager platform.Manager, text string)
 		if plat == nil {
 			continue
 		}
-		if matcher, ok := plat.(platform.ArtistURLMatcher); ok {
+		if matcher, ok := platform.As[platform.ArtistURLMatcher](plat); ok {
 			if id, ok := matcher.MatchArtistURL(urlStr); ok {
 				return name, id, true
 			}
@@ -708,6 +712,9 @@ func userVisibleDownloadError(ctx context.Context, err error) string {
 		if strings.Contains(errLower, "md5 verification failed") {
 			return tr(ctx, "md5_ver_failed")
 		}
+		if errors.Is(err, errIntegrityFailed) || strings.Contains(errLower, "integrity verification failed") {
+			return tr(ctx, "integrity_failed")
+		}
 		if strings.Contains(errLower, "download timed out") || strings.Contains(errLower, "download timeout") {
 			return tr(ctx, "download_timeout")
 		}
@@ -723,6 +730,9 @@ func userVisibleDownloadError(ctx context.Context, err error) string {
 		if strings.Contains(errLower, "upload queue is full") {
 			return tr(ctx, "err_upload_overloaded")
 		}
+		if errors.Is(err, errUploadTooLarge) || strings.Contains(errLower, "request entity too large") || strings.Contains(errLower, "file is too big") {
+			return tr(ctx, "err_upload_too_large")
+		}
 		if errors.Is(err, platform.ErrRateLimited) || strings.Contains(errText, "Too Many Requests") || strings.Contains(errLower, "retry after") {
 			return tr(ctx, "err_rate_limited")
 		}
@@ -793,18 +803,25 @@ func buildMusicCaption(ctx context.Context, manager platform.Manager, songInfo *
 	songNameHTML := songNameText
 	artistsHTML := artistsText
 	albumHTML := albumText
-	infoParts := make([]string, 0, 2)
+	infoParts := make([]string, 0, 3)
 	if sizeText := formatFileSize(songInfo.MusicSize + songInfo.EmbPicSize); sizeText != "" {
 		infoParts = append(infoParts, sizeText)
 	}
 	if bitrateText := formatBitrate(songInfo.BitRate); bitrateText != "" {
 		infoParts = append(infoParts, bitrateText)
 	}
+	if loudnessText := formatLoudness(songInfo.LoudnessLUFS, songInfo.TruePeak); loudnessText != "" {
+		infoParts = append(infoParts, loudnessText)
+	}
 	infoLine := strings.Join(infoParts, " ")
 	if infoLine != "" {
 		infoLine += "\n"
 	}
-	tags := strings.Join(formatInfoTags(ctx, manager, songInfo.Platform, songInfo.Quality, songInfo.FileExt), " ")
+	infoTags := formatInfoTags(ctx, manager, songInfo.Platform, advertisedQuality(songInfo), songInfo.FileExt)
+	if verdictTag := spectralVerdictTag(ctx, songInfo.SpectralVerdict); verdictTag != "" {
+		infoTags = append(infoTags, "#"+verdictTag)
+	}
+	tags := strings.Join(infoTags, " ")
 
 	if strings.TrimSpace(songInfo.TrackURL) != "" {
 		songNameHTML = fmt.Sprintf("<a href=\"%s\">%s</a>", html.EscapeString(songInfo.TrackURL), songNameText)
@@ -833,6 +850,23 @@ func buildMusicCaption(ctx context.Context, manager platform.Manager, songInfo *
 		albumLine = fmt.Sprintf("%s%s\n", tr(ctx, "album_label"), albumHTML)
 	}
 
+	if templates := chatTemplatesFrom(ctx); templates != nil && templates.Caption != "" {
+		caption, err := render.HTML(templates.Caption, render.Caption{
+			Track:       templateTrack(songInfo, nil, nil),
+			TitleLink:   htmltemplate.HTML(songNameHTML),
+			ArtistLinks: htmltemplate.HTML(artistsHTML),
+			AlbumLink:   htmltemplate.HTML(albumHTML),
+			AlbumLabel:  tr(ctx, "album_label"),
+			Info:        strings.TrimSuffix(infoLine, "\n"),
+			Hashtags:    tags,
+			Bot:         botName,
+		})
+		// A template that stopped rendering falls back to the built-in caption.
+		if err == nil && strings.TrimSpace(caption) != "" {
+			return caption
+		}
+	}
+
 	return fmt.Sprintf("<b>「%s」- %s</b>\n%s<blockquote>%s%s\n</blockquote>via @%s",
 		songNameHTML,
 		artistsHTML,
@@ -967,7 +1001,7 @@ func parseEpisodeTrackID(manager platform.Manager, platformName, trackID string)
 	if plat == nil {
 		return "", 0, false, false
 	}
-	resolver, ok := plat.(platform.EpisodeTrackIDResolver)
+	resolver, ok := platform.As[platform.EpisodeTrackIDResolver](plat)
 	if !ok {
 		return "", 0, false, false
 	}
@@ -992,7 +1026,7 @@ func buildEpisodeTrackID(manager platform.Manager, platformName, baseTrackID str
 	if plat == nil {
 		return ""
 	}
-	resolver, ok := plat.(platform.EpisodeTrackIDResolver)
+	resolver, ok := platform.As[platform.EpisodeTrackIDResolver](plat)
 	if !ok {
 		return ""
 	}
@@ -1009,7 +1043,7 @@ func buildEpisodeCollectionID(manager platform.Manager, platformName, baseTrackI
 	if plat == nil {
 		return ""
 	}
-	provider, ok := plat.(platform.EpisodeCollectionProvider)
+	provider, ok := platform.As[platform.EpisodeCollectionProvider](plat)
 	if !ok {
 		return ""
 	}
@@ -1026,7 +1060,7 @@ func parseEpisodeCollectionID(manager platform.Manager, platformName, collection
 	if plat == nil {
 		return "", false
 	}
-	provider, ok := plat.(platform.EpisodeCollectionProvider)
+	provider, ok := platform.As[platform.EpisodeCollectionProvider](plat)
 	if !ok {
 		return "", false
 	}
@@ -1044,7 +1078,7 @@ func getSearchFilterProvider(manager platform.Manager, platformName string) (pla
 	if plat == nil {
 		return nil, false
 	}
-	provider, ok := plat.(platform.SearchFilterProvider)
+	provider, ok := platform.As[platform.SearchFilterProvider](plat)
 	return provider, ok
 }
 
@@ -1563,6 +1597,20 @@ func formatBitrate(bitRate int) string {
 	return fmt.Sprintf("%.2fkbps", float64(bitRate)/1000)
 }
 
+// formatLoudness renders the measured loudness with the ReplayGain 2.0 track
+// gain and true peak, e.g. "-9.3LUFS RG -8.70dB TP -0.2dBTP". Unmeasured
+// tracks (0) render nothing.
+func formatLoudness(lufs, truePeak float64) string {
+	if lufs == 0 {
+		return ""
+	}
+	text := fmt.Sprintf("%.1fLUFS RG %+.2fdB", lufs, loudness.ReferenceLUFS-lufs)
+	if truePeak > 0 {
+		text += fmt.Sprintf(" TP %.1fdBTP", 20*math.Log10(truePeak))
+	}
+	return text
+}
+
 func formatFileInfo(fileExt string, musicSize int) string {
 	if musicSize <= 0 || strings.TrimSpace(fileExt) == "" {
 		return ""
@@ -1661,12 +1709,12 @@ func matchPlatformTrack(ctx context.Context, manager platform.Manager, platformN
 	if plat == nil {
 		return "", false
 	}
-	if matcher, ok := plat.(platform.URLMatcher); ok {
+	if matcher, ok := platform.As[platform.URLMatcher](plat); ok {
 		if id, ok := matcher.MatchURL(text); ok {
 			return id, true
 		}
 	}
-	if matcher, ok := plat.(platform.TextMatcher); ok && !isBareNumericText(text) {
+	if matcher, ok := platform.As[platform.TextMatcher](plat); ok && !isBareNumericText(text) {
 		if id, ok := matcher.MatchText(text); ok {
 			return id, true
 		}
@@ -1751,7 +1799,7 @@ func verifyCachedNeteaseQuality(ctx context.Context, manager platform.Manager, r
 	if manager == nil || repo == nil || cached == nil {
 		return
 	}
-	if platformName != "netease" || cached.QualityVerified {
+	if platformName != "netease" || cached.QualityVerified || cached.OutputFormat != "" {
 		return
 	}
 	plat := manager.Get("netease")
@@ -158,6 +158,55 @@ func TestBuildMusicCaptionHidesAlbumLineWhenEmpty(t *testing.T) {
 	}
 }
 
+func TestBuildMusicCaptionShowsLoudness(t *testing.T) {
+	info := &botpkg.SongInfo{
+		SongName:     "Song",
+		SongArtists:  "Artist",
+		FileExt:      "flac",
+		MusicSize:    1024,
+		BitRate:      900000,
+		LoudnessLUFS: -9.3,
+		TruePeak:     0.977,
+	}
+	caption := buildMusicCaption(zhCtx(), nil, info, "botname")
+	if !strings.Contains(caption, "900.00kbps -9.3LUFS RG -8.70dB TP -0.2dBTP") {
+		t.Fatalf("expected loudness in info line, got %q", caption)
+	}
+
+	info.LoudnessLUFS, info.TruePeak = 0, 0
+	if caption := buildMusicCaption(zhCtx(), nil, info, "botname"); strings.Contains(caption, "LUFS") {
+		t.Fatalf("unmeasured song should not show loudness, got %q", caption)
+	}
+}
+
+func TestBuildMusicCaptionDowngradesFakeLossless(t *testing.T) {
+	info := &botpkg.SongInfo{
+		SongName:        "Song",
+		SongArtists:     "Artist",
+		Quality:         "lossless",
+		FileExt:         "flac",
+		SpectralVerdict: "lossy_source",
+	}
+	caption := buildMusicCaption(enCtx(), nil, info, "botname")
+	if !strings.Contains(caption, "#HiFi #flac #LossySource") || strings.Contains(caption, "#Lossless") {
+		t.Fatalf("expected downgraded quality tag, got %q", caption)
+	}
+
+	info.Quality, info.SpectralVerdict = "hires", "upsampled"
+	caption = buildMusicCaption(enCtx(), nil, info, "botname")
+	if !strings.Contains(caption, "#Lossless #flac #Upsampled") || strings.Contains(caption, "#HiRes") {
+		t.Fatalf("expected fake Hi-Res downgraded to lossless, got %q", caption)
+	}
+	if info.Quality != "hires" {
+		t.Fatalf("cached quality changed to %q", info.Quality)
+	}
+
+	info.SpectralVerdict = "genuine"
+	if caption := buildMusicCaption(enCtx(), nil, info, "botname"); !strings.Contains(caption, "#HiRes #flac") || strings.Contains(caption, "#Upsampled") {
+		t.Fatalf("genuine file should keep its label, got %q", caption)
+	}
+}
+
 func TestBuildMusicCaptionEscapesHTMLAndKeepsLinks(t *testing.T) {
 	info := &botpkg.SongInfo{
 		SongName:        `Song <Test>`,
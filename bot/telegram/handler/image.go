@@ -78,6 +78,42 @@ func resizeImg(filePath string) (string, error) {
 	return outputPath, nil
 }
 
+// fitImg scales the image down so its longest edge is at most maxEdge,
+// keeping the aspect ratio. An image already within bounds is returned as is.
+func fitImg(filePath string, maxEdge int) (string, error) {
+	img, err := decodeJPEGOrPNG(filePath)
+	if err != nil {
+		return "", err
+	}
+	width, height := img.Bounds().Dx(), img.Bounds().Dy()
+	if maxEdge <= 0 || (width <= maxEdge && height <= maxEdge) {
+		return filePath, nil
+	}
+
+	var m image.Image
+	if width >= height {
+		m = resize.Resize(uint(maxEdge), 0, img, resize.Lanczos3)
+	} else {
+		m = resize.Resize(0, uint(maxEdge), img, resize.Lanczos3)
+	}
+
+	outputPath := fmt.Sprintf("%s.%d.jpg", filePath, maxEdge)
+	out, err := os.Create(outputPath)
+	if err != nil {
+		return "", fmt.Errorf("create image file error %s", err)
+	}
+	if err := jpeg.Encode(out, m, &jpeg.Options{Quality: 90}); err != nil {
+		_ = out.Close()
+		_ = os.Remove(outputPath)
+		return "", err
+	}
+	if err := out.Close(); err != nil {
+		_ = os.Remove(outputPath)
+		return "", err
+	}
+	return outputPath, nil
+}
+
 func decodeJPEGOrPNG(filePath string) (image.Image, error) {
 	file, err := os.Open(filePath)
 	if err != nil {
@@ -70,6 +70,27 @@ func TestResizeImgDecodeError(t *testing.T) {
 	}
 }
 
+func TestFitImg(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	inputPath := filepath.Join(dir, "input.png")
+	writeSolidImageAsPNG(t, inputPath, 1200, 600)
+
+	fitted, err := fitImg(inputPath, 600)
+	if err != nil {
+		t.Fatalf("fitImg failed: %v", err)
+	}
+	decoded := mustDecodeJPEG(t, fitted)
+	if gotW, gotH := decoded.Bounds().Dx(), decoded.Bounds().Dy(); gotW != 600 || gotH != 300 {
+		t.Fatalf("expected 600x300, got %dx%d", gotW, gotH)
+	}
+
+	if same, err := fitImg(inputPath, 1400); err != nil || same != inputPath {
+		t.Fatalf("fitImg within bounds = %q, %v; want the input unchanged", same, err)
+	}
+}
+
 func writeSolidImageAsJPEG(t *testing.T, path string, width, height int) {
 	t.Helper()
 	img := image.NewRGBA(image.Rect(0, 0, width, height))
@@ -909,10 +909,10 @@ func (h *InlineSearchHandler) tryInlineDirectEpisodes(ctx context.Context, b *te
 	if plat == nil {
 		return false
 	}
-	if _, ok := plat.(platform.EpisodeTrackIDResolver); !ok {
+	if _, ok := platform.As[platform.EpisodeTrackIDResolver](plat); !ok {
 		return false
 	}
-	provider, ok := plat.(platform.EpisodeProvider)
+	provider, ok := platform.As[platform.EpisodeProvider](plat)
 	if !ok {
 		return false
 	}
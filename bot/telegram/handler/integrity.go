@@ -0,0 +1,45 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	botpkg "github.com/liuran001/MusicBot-Go/bot"
+	"github.com/liuran001/MusicBot-Go/bot/integrity"
+)
+
+// integrityAttempts is how many times a download is fetched before a file that
+// keeps failing verification is given up on.
+const integrityAttempts = 2
+
+// errIntegrityFailed marks downloads that stayed corrupt after re-downloading.
+var errIntegrityFailed = errors.New("integrity verification failed")
+
+// verifyIntegrity decodes the prepared file and records the outcome on
+// songInfo. Only corruption is returned as an error; files that cannot be
+// checked (no ffmpeg, I/O trouble) pass through unverified.
+func (h *MusicHandler) verifyIntegrity(ctx context.Context, filePath string, songInfo *botpkg.SongInfo, trackID string) error {
+	if h == nil || !h.IntegrityCheck || songInfo == nil {
+		return nil
+	}
+	songInfo.IntegrityStatus = ""
+	report, err := integrity.Verify(ctx, filePath)
+	if err != nil {
+		if errors.Is(err, integrity.ErrCorrupt) {
+			if h.Logger != nil {
+				h.Logger.Warn("prepared audio failed integrity check", "platform", songInfo.Platform, "trackID", trackID, "path", filePath, "error", err)
+			}
+			return fmt.Errorf("%w: %w", errIntegrityFailed, err)
+		}
+		if h.Logger != nil && ctx.Err() == nil && !errors.Is(err, integrity.ErrUnsupported) {
+			h.Logger.Warn("failed to verify audio integrity", "platform", songInfo.Platform, "trackID", trackID, "error", err)
+		}
+		return nil
+	}
+	songInfo.IntegrityStatus = string(report.Status)
+	if h.Logger != nil {
+		h.Logger.Debug("prepared audio integrity verified", "platform", songInfo.Platform, "trackID", trackID, "status", report.Status, "decoder", report.Decoder, "duration", report.Duration)
+	}
+	return nil
+}
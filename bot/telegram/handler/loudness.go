@@ -0,0 +1,40 @@
+package handler
+
+import (
+	"context"
+	"errors"
+
+	botpkg "github.com/liuran001/MusicBot-Go/bot"
+	"github.com/liuran001/MusicBot-Go/bot/id3"
+	"github.com/liuran001/MusicBot-Go/bot/loudness"
+)
+
+// measureLoudness stores the EBU R128 integrated loudness and true peak of the
+// prepared file on songInfo. Failures leave the fields at 0, which skips the
+// ReplayGain tags and the caption part.
+func (h *MusicHandler) measureLoudness(ctx context.Context, filePath string, songInfo *botpkg.SongInfo, trackID string) {
+	if h == nil || !h.LoudnessAnalysis || songInfo == nil {
+		return
+	}
+	res, err := loudness.Analyze(ctx, filePath)
+	if err != nil {
+		if h.Logger != nil && !errors.Is(err, loudness.ErrNoSignal) && ctx.Err() == nil {
+			h.Logger.Warn("failed to measure loudness", "platform", songInfo.Platform, "trackID", trackID, "error", err)
+		}
+		return
+	}
+	songInfo.LoudnessLUFS = res.Integrated
+	songInfo.TruePeak = res.TruePeak
+}
+
+// replayGainFromSongInfo returns the track ReplayGain for the measured values,
+// or nil when the song was not measured.
+func replayGainFromSongInfo(songInfo *botpkg.SongInfo) *id3.ReplayGain {
+	if songInfo == nil || songInfo.LoudnessLUFS == 0 {
+		return nil
+	}
+	return &id3.ReplayGain{
+		TrackGain: loudness.ReferenceLUFS - songInfo.LoudnessLUFS,
+		TrackPeak: songInfo.TruePeak,
+	}
+}
@@ -22,6 +22,7 @@ import (
 	"github.com/go-flac/go-flac"
 	botpkg "github.com/liuran001/MusicBot-Go/bot"
 	"github.com/liuran001/MusicBot-Go/bot/admincmd"
+	"github.com/liuran001/MusicBot-Go/bot/bandwidth"
 	"github.com/liuran001/MusicBot-Go/bot/download"
 	"github.com/liuran001/MusicBot-Go/bot/i18n"
 	"github.com/liuran001/MusicBot-Go/bot/id3"
@@ -172,6 +173,25 @@ type MusicHandler struct {
 	UploadBot          *telego.Bot
 	RateLimiter        *telegram.RateLimiter
 	ResourceLimiter    *ResourceRateLimiter
+	// UploadLimitBytes caps uploaded files (0 disables the check); larger
+	// files go through OversizeStrategies in order. FileLinks backs the
+	// "link" strategy and may be nil.
+	UploadLimitBytes   int64
+	OversizeStrategies []string
+	FileLinks          FileLinkPublisher
+	// LoudnessAnalysis measures EBU R128 loudness of new downloads for
+	// ReplayGain tags and the caption.
+	LoudnessAnalysis bool
+	// SpectralAnalysis checks new lossless downloads for lossy or upsampled
+	// sources and downgrades the advertised quality.
+	SpectralAnalysis bool
+	// IntegrityCheck decodes new downloads in full and re-downloads files
+	// with broken frames or a mismatched FLAC MD5 signature.
+	IntegrityCheck bool
+	// DownloadBandwidth and UploadBandwidth shape transfer byte rates and
+	// meter the throughput shown in /queue; nil leaves them unlimited.
+	DownloadBandwidth *bandwidth.Shaper
+	UploadBandwidth   *bandwidth.Shaper
 	// uploadLifecycleMu protects worker acceptance, active tasks, and shutdown.
 	uploadLifecycleMu sync.Mutex
 	uploadAccepting   bool
@@ -196,7 +216,8 @@ type MusicHandler struct {
 	// userJobs tracks each requester's cancellable download/upload work so
 	// /cancel can stop only that user's tasks.
 	userJobs userJobRegistry
-	// downloadQueueMu protects downloadWaiting/downloadRunning accounting.
+	// downloadQueueMu protects downloadWaiting/downloadRunning accounting and the
+	// DownloadQueue*Limit caps, which SetDownloadQueueLimits may change live.
 	downloadQueueMu sync.Mutex
 	// downloadWaiting counts admitted tasks not yet holding a download slot
 	// (waiting for a global slot and/or a per-platform serial gate). It is the
@@ -250,6 +271,11 @@ type DownloadQueueSnapshot struct {
 	UploadRunning    int
 	UploadQueueLimit int
 	UploadLimit      int
+	// Live throughput and limits in bytes per second (0 = unlimited).
+	DownloadRate      int64
+	DownloadRateLimit int64
+	UploadRate        int64
+	UploadRateLimit   int64
 }
 
 type uploadTask struct {
@@ -278,6 +304,8 @@ type uploadTask struct {
 	// Replaces the previous fragile strings.Contains(hitCache) check, which
 	// broke once the text became language-dependent.
 	cacheHit bool
+	// delivery is set when the file was too large for a plain upload.
+	delivery *uploadDelivery
 }
 
 type queuedStatus struct {
@@ -324,6 +352,12 @@ type preparedSongInfo struct {
 	AudioCodec      string
 	SampleRate      int
 	BitDepth        int
+	LoudnessLUFS    float64
+	TruePeak        float64
+	SpectralVerdict string
+	SpectralCutoff  int
+	EffectiveBits   int
+	IntegrityStatus string
 	PicSize         int
 	EmbPicSize      int
 }
@@ -577,7 +611,7 @@ func (h *MusicHandler) isPlatformAutoParseAllowed(ctx context.Context, message *
 	if plat == nil {
 		return true
 	}
-	decider, ok := plat.(platform.AutoParseDecider)
+	decider, ok := platform.As[platform.AutoParseDecider](plat)
 	if !ok {
 		return true
 	}
@@ -638,7 +672,7 @@ func (h *MusicHandler) dispatch(ctx context.Context, b *telego.Bot, message *tel
 		return false
 	}
 
-	processCtx, processCancel := h.processContext(detachContext(ctx))
+	processCtx, processCancel := h.processContext(withBandwidthUser(detachContext(ctx), userID))
 	// Register for /cancel, and make the normal completion path drop the entry
 	// too, so a later /cancel never reports work that already finished.
 	releaseJob := h.trackUserJob(userID, userJobDownload, processCancel)
@@ -695,7 +729,7 @@ func (h *MusicHandler) submitInlineDownloadWork(ctx context.Context, userID, cha
 		return false
 	}
 
-	processCtx, processCancel := h.processContext(detachContext(ctx))
+	processCtx, processCancel := h.processContext(withBandwidthUser(detachContext(ctx), userID))
 	releaseJob := h.trackUserJob(userID, userJobDownload, processCancel)
 	cancel := func() {
 		releaseJob()
@@ -808,6 +842,14 @@ func (h *MusicHandler) processMusic(ctx context.Context, b *telego.Bot, message
 	var songInfo botpkg.SongInfo
 	status := newStatusSession(ctx, b, h.RateLimiter, message.Chat.ID, threadID, replyParams)
 
+	// outputFormat and templates are resolved from settings below; a
+	// non-original format or custom file templates read and write their own
+	// cache variant.
+	outputFormat := outputFormatOriginal
+	cacheVariant := outputFormatOriginal
+	var templates *chatTemplates
+	variants := h.songVariantStore()
+
 	// Request-level cache to avoid duplicate DB queries
 	cacheMap := make(map[string]*botpkg.SongInfo)
 	getCached := func(platform, trackID, quality string) (*botpkg.SongInfo, error) {
@@ -818,7 +860,13 @@ func (h *MusicHandler) processMusic(ctx context.Context, b *telego.Bot, message
 		if h.Repo == nil {
 			return nil, errors.New("repo not configured")
 		}
-		cached, err := h.Repo.FindByPlatformTrackID(ctx, platform, trackID, quality)
+		var cached *botpkg.SongInfo
+		var err error
+		if cacheVariant != outputFormatOriginal {
+			cached, err = variants.FindSongVariant(ctx, platform, trackID, quality, cacheVariant)
+		} else {
+			cached, err = h.Repo.FindByPlatformTrackID(ctx, platform, trackID, quality)
+		}
 		// Do not memoize legacy Apple enhanced records that the current quality
 		// classifier intentionally invalidates. A request waiting on Apple's
 		// serial download gate must query the DB again after the first waiter has
@@ -844,9 +892,7 @@ func (h *MusicHandler) processMusic(ctx context.Context, b *telego.Bot, message
 		if h.Logger != nil {
 			h.Logger.Warn("cached telegram file id invalid, fallback to redownload", "platform", platformName, "trackID", trackID, "quality", cacheQuality, "error", err)
 		}
-		if h.Repo != nil {
-			_ = h.Repo.DeleteByPlatformTrackID(ctx, platformName, trackID, cacheQuality)
-		}
+		h.deleteCachedSong(ctx, platformName, trackID, cacheQuality, cacheVariant)
 		songInfo.FileID = ""
 		songInfo.ThumbFileID = ""
 		return true
@@ -863,6 +909,20 @@ func (h *MusicHandler) processMusic(ctx context.Context, b *telego.Bot, message
 	qualityStr := quality.String()
 	scopeType, scopeID := musicRequestSettingScope(message, userID)
 	preferAtmos := preferAppleMusicAtmosEnabled(ctx, h.Repo, h.PlatformManager, scopeType, scopeID, platformName, explicitQuality) && quality != platform.QualityAtmos
+	if format := h.resolveOutputFormat(ctx, message, userID); format != outputFormatOriginal && variants != nil {
+		if _, err := exec.LookPath("ffmpeg"); err == nil {
+			outputFormat = format
+		} else if h.Logger != nil {
+			h.Logger.Warn("ffmpeg unavailable, sending original format", "platform", platformName, "trackID", trackID, "format", format)
+		}
+	}
+	cacheVariant = outputFormat
+	if templates = h.resolveChatTemplates(ctx, message, userID); templates != nil {
+		ctx = withChatTemplates(ctx, templates)
+		if signature := templates.fileVariant(); signature != "" && variants != nil {
+			cacheVariant = templateCacheVariant(outputFormat, signature)
+		}
+	}
 
 	if handled, err := h.tryPresentDirectEpisodes(ctx, b, message, platformName, trackID, qualityIntentToken(qualityStr, explicitQuality)); handled {
 		return err
@@ -1053,9 +1113,102 @@ func (h *MusicHandler) processMusic(ctx context.Context, b *telego.Bot, message
 		return err
 	}
 
+	// A chosen output format is transcoded from the prepared file, then custom
+	// filename and tag templates are applied to a private copy. On failure
+	// the untemplated or original file is sent and cached as such.
+	var formatCleanup []string
+	convert := func(path, pic string, source *platform.DownloadInfo) string {
+		out := path
+		if outputFormat != outputFormatOriginal {
+			status.Edit(buildMusicInfoText(ctx, songInfo.SongName, songInfo.SongAlbum, formatFileInfo(songInfo.FileExt, songInfo.MusicSize), tr(ctx, "output_format_converting", map[string]any{"Format": outputFormatDisplayName(ctx, outputFormat)})))
+			converted, created, err := h.convertOutputFormat(ctx, plat, track, trackID, source, &songInfo, path, pic, outputFormat)
+			formatCleanup = append(formatCleanup, created...)
+			if err != nil {
+				if h.Logger != nil {
+					h.Logger.Warn("output format transcode failed, sending original", "platform", platformName, "trackID", trackID, "format", outputFormat, "error", err)
+				}
+			} else {
+				out = converted
+			}
+		}
+		if signature := templates.fileVariant(); signature != "" && cacheVariant != outputFormat {
+			templated, created, err := h.applyFileTemplates(ctx, templates, plat, track, trackID, source, &songInfo, out, out != path)
+			formatCleanup = append(formatCleanup, created...)
+			if err != nil {
+				if h.Logger != nil {
+					h.Logger.Warn("applying file templates failed, sending default file", "platform", platformName, "trackID", trackID, "error", err)
+				}
+				return out
+			}
+			songInfo.OutputFormat = templateCacheVariant(songInfo.OutputFormat, signature)
+			return templated
+		}
+		return out
+	}
+	musicPath = convert(musicPath, picPath, info)
+
+	// Files over the Bot API upload limit go through the oversize strategies;
+	// a downgrade re-prepares the track at the next lower quality.
+	downgrade := func(ctx context.Context) (string, error) {
+		for {
+			lower, ok := lowerQuality(quality)
+			if !ok {
+				return "", nil
+			}
+			quality = lower
+			status.Edit(buildMusicInfoText(ctx, songInfo.SongName, songInfo.SongAlbum, formatFileInfo(songInfo.FileExt, songInfo.MusicSize), tr(ctx, "upload_oversize_retrying", map[string]any{"Quality": qualityDisplayName(ctx, lower.String())})))
+			nextInfo, err := h.getDownloadInfoSingleflight(ctx, platformName, trackID, lower)
+			if err != nil {
+				return "", err
+			}
+			if nextInfo == nil || nextInfo.URL == "" {
+				continue
+			}
+			nextQuality := nextInfo.Quality.String()
+			if nextQuality == "unknown" || nextQuality == "" {
+				nextQuality = lower.String()
+			}
+			if nextQuality == songInfo.Quality || (nextInfo.Size > h.UploadLimitBytes && !nextInfo.SizeIsAdvisory) {
+				continue
+			}
+			if nextInfo.Format == "" {
+				nextInfo.Format = "mp3"
+			}
+			next := songInfo
+			next.Quality = nextQuality
+			next.QualityVerified = !needsPreparedAudioQualityVerification(platformName, nextQuality)
+			next.FileExt = nextInfo.Format
+			next.MusicSize = 0
+			next.BitRate = nextInfo.Bitrate * 1000
+			nextPath, nextPic, nextRelease, err := h.acquirePreparedMedia(ctx, platformName, trackID, nextQuality, plat, track, nextInfo, status.Message(), b, message, &next, nil)
+			if err != nil {
+				return "", err
+			}
+			if releasePrepared != nil {
+				releasePrepared()
+			}
+			releasePrepared = nextRelease
+			picPath = nextPic
+			songInfo = next
+			return convert(nextPath, nextPic, nextInfo), nil
+		}
+	}
+	musicPath, delivery, uploadCleanup, err := h.fitUploadLimit(ctx, &songInfo, musicPath, h.OversizeStrategies, downgrade)
+	uploadCleanup = append(formatCleanup, uploadCleanup...)
+	if err != nil {
+		if cleanupErr := cleanupFiles(uploadCleanup...); cleanupErr != nil && h.Logger != nil {
+			h.Logger.Warn("failed to clean oversize artifacts", "platform", platformName, "trackID", trackID, "error", cleanupErr)
+		}
+		if releasePrepared != nil {
+			releasePrepared()
+		}
+		sendFailed(err)
+		return err
+	}
+
 	status.Edit(buildMusicInfoText(ctx, songInfo.SongName, songInfo.SongAlbum, formatFileInfo(songInfo.FileExt, songInfo.MusicSize), tr(ctx, "uploading")))
 
-	if err := h.sendMusic(ctx, b, status.Message(), message, &songInfo, musicPath, picPath, nil, releasePrepared, platformName, trackID); err != nil {
+	if err := h.sendMusic(ctx, b, status.Message(), message, &songInfo, musicPath, picPath, uploadCleanup, releasePrepared, platformName, trackID, delivery); err != nil {
 		if releasePrepared != nil {
 			releasePrepared()
 		}
@@ -1078,10 +1231,10 @@ func (h *MusicHandler) tryPresentDirectEpisodes(ctx context.Context, b *telego.B
 	if plat == nil {
 		return false, nil
 	}
-	if _, ok := plat.(platform.EpisodeTrackIDResolver); !ok {
+	if _, ok := platform.As[platform.EpisodeTrackIDResolver](plat); !ok {
 		return false, nil
 	}
-	provider, ok := plat.(platform.EpisodeProvider)
+	provider, ok := platform.As[platform.EpisodeProvider](plat)
 	if !ok {
 		return false, nil
 	}
@@ -1233,7 +1386,7 @@ func (h *MusicHandler) trySendCachedTrack(
 		return botpkg.SongInfo{}, false, nil
 	}
 	if cached.FileID == "" {
-		_ = h.Repo.DeleteByPlatformTrackID(ctx, platformName, trackID, cacheQuality)
+		h.deleteCachedSong(ctx, platformName, trackID, cacheQuality, cached.OutputFormat)
 		return botpkg.SongInfo{}, false, nil
 	}
 
@@ -1245,7 +1398,7 @@ func (h *MusicHandler) trySendCachedTrack(
 	if !silent {
 		status.Upsert(buildMusicInfoText(ctx, songInfo.SongName, songInfo.SongAlbum, formatFileInfo(songInfo.FileExt, songInfo.MusicSize), tr(ctx, "hit_cache")))
 	}
-	if err := h.sendMusic(ctx, b, status.Message(), message, &songInfo, "", "", nil, nil, platformName, trackID); err != nil {
+	if err := h.sendMusic(ctx, b, status.Message(), message, &songInfo, "", "", nil, nil, platformName, trackID, nil); err != nil {
 		if onInvalidCachedFileID != nil && onInvalidCachedFileID(err, cacheQuality) {
 			return songInfo, false, nil
 		}
@@ -1255,6 +1408,21 @@ func (h *MusicHandler) trySendCachedTrack(
 	return songInfo, true, nil
 }
 
+// deleteCachedSong drops one cache row: the original when outputFormat is
+// empty or "original", otherwise that transcoded variant.
+func (h *MusicHandler) deleteCachedSong(ctx context.Context, platformName, trackID, quality, outputFormat string) {
+	if h == nil || h.Repo == nil {
+		return
+	}
+	if outputFormat == "" || outputFormat == outputFormatOriginal {
+		_ = h.Repo.DeleteByPlatformTrackID(ctx, platformName, trackID, quality)
+		return
+	}
+	if variants := h.songVariantStore(); variants != nil {
+		_ = variants.DeleteSongVariant(ctx, platformName, trackID, quality, outputFormat)
+	}
+}
+
 func (h *MusicHandler) refreshCachedSongLinks(ctx context.Context, songInfo *botpkg.SongInfo) {
 	if h == nil || h.PlatformManager == nil || h.Repo == nil || songInfo == nil {
 		return
@@ -1445,6 +1613,12 @@ func capturePreparedSongInfo(songInfo *botpkg.SongInfo) preparedSongInfo {
 		AudioCodec:      songInfo.AudioCodec,
 		SampleRate:      songInfo.SampleRate,
 		BitDepth:        songInfo.BitDepth,
+		LoudnessLUFS:    songInfo.LoudnessLUFS,
+		TruePeak:        songInfo.TruePeak,
+		SpectralVerdict: songInfo.SpectralVerdict,
+		SpectralCutoff:  songInfo.SpectralCutoff,
+		EffectiveBits:   songInfo.EffectiveBits,
+		IntegrityStatus: songInfo.IntegrityStatus,
 		PicSize:         songInfo.PicSize,
 		EmbPicSize:      songInfo.EmbPicSize,
 	}
@@ -1465,6 +1639,12 @@ func applyPreparedSongInfo(songInfo *botpkg.SongInfo, prepared preparedSongInfo)
 		songInfo.SampleRate = prepared.SampleRate
 		songInfo.BitDepth = prepared.BitDepth
 	}
+	songInfo.LoudnessLUFS = prepared.LoudnessLUFS
+	songInfo.TruePeak = prepared.TruePeak
+	songInfo.SpectralVerdict = prepared.SpectralVerdict
+	songInfo.SpectralCutoff = prepared.SpectralCutoff
+	songInfo.EffectiveBits = prepared.EffectiveBits
+	songInfo.IntegrityStatus = prepared.IntegrityStatus
 	songInfo.PicSize = prepared.PicSize
 	songInfo.EmbPicSize = prepared.EmbPicSize
 }
@@ -2028,26 +2208,45 @@ func (h *MusicHandler) downloadAndPrepareFromPlatform(ctx context.Context, plat
 		}
 	}
 
-	if _, err := h.DownloadService.Download(ctx, info, filePath, progress); err != nil {
+	// Corrupt downloads (cut-off streams, broken frames) are fetched once
+	// more before giving up, so they never reach the file_id cache.
+	downloadPath, downloadFormat := filePath, info.Format
+	for attempt := 1; ; attempt++ {
+		if _, err := h.DownloadService.Download(download.WithPlatform(ctx, songInfo.Platform), info, filePath, progress); err != nil {
+			if cleanupErr := cleanupFiles(cleanupList...); cleanupErr != nil && h.Logger != nil {
+				h.Logger.Warn("failed to clean download artifacts", "path", filePath, "error", cleanupErr)
+			}
+			return "", "", cleanupList, err
+		}
+		if h != nil && h.Logger != nil {
+			h.Logger.Debug("prepared media downloaded", "initial_path", filePath, "initial_ext", songInfo.FileExt, "info_format", info.Format)
+		}
+		filePath, songInfo.FileExt = normalizeExtractedAudioPath(filePath, songInfo.FileExt)
+		if songInfo.FileExt != "" {
+			info.Format = songInfo.FileExt
+		}
+		if h != nil && h.Logger != nil {
+			h.Logger.Debug("prepared media normalized", "normalized_path", filePath, "normalized_ext", songInfo.FileExt, "info_format", info.Format)
+		}
+		err := h.verifyIntegrity(ctx, filePath, songInfo, trackID)
+		if err == nil {
+			break
+		}
 		if cleanupErr := cleanupFiles(cleanupList...); cleanupErr != nil && h.Logger != nil {
 			h.Logger.Warn("failed to clean download artifacts", "path", filePath, "error", cleanupErr)
 		}
-		return "", "", cleanupList, err
-	}
-	if h != nil && h.Logger != nil {
-		h.Logger.Debug("prepared media downloaded", "initial_path", filePath, "initial_ext", songInfo.FileExt, "info_format", info.Format)
-	}
-	filePath, songInfo.FileExt = normalizeExtractedAudioPath(filePath, songInfo.FileExt)
-	if songInfo.FileExt != "" {
-		info.Format = songInfo.FileExt
-	}
-	if h != nil && h.Logger != nil {
-		h.Logger.Debug("prepared media normalized", "normalized_path", filePath, "normalized_ext", songInfo.FileExt, "info_format", info.Format)
+		if attempt >= integrityAttempts || ctx.Err() != nil {
+			return "", "", cleanupList, err
+		}
+		filePath, info.Format, songInfo.FileExt = downloadPath, downloadFormat, downloadFormat
+		songInfo.MusicSize = 0
 	}
 
 	// Derive bitrate from actual file size + duration (from track or FLAC streaminfo)
 	deriveBitrateFromFile(filePath, songInfo)
 	h.verifyPreparedAppleMusicQuality(plat, filePath, songInfo, trackID)
+	h.measureLoudness(ctx, filePath, songInfo, trackID)
+	h.analyzeSpectrum(ctx, filePath, songInfo, trackID)
 
 	picPath, resizePicPath := h.prepareCoverFiles(ctx, track, trackID, stamp, songInfo, &cleanupList)
 
@@ -2071,7 +2270,7 @@ func (h *MusicHandler) downloadAndPrepareFromPlatform(ctx context.Context, plat
 
 	finalDir := filepath.Join(h.CacheDir, fmt.Sprintf("%d", stamp))
 	_ = os.Mkdir(finalDir, os.ModePerm)
-	fileName := sanitizeFileName(fmt.Sprintf("%v - %v.%v", strings.ReplaceAll(songInfo.SongArtists, "/", ","), songInfo.SongName, songInfo.FileExt))
+	fileName := defaultUploadFileName(songInfo)
 	finalPath := filepath.Join(finalDir, fileName)
 	if err := os.Rename(filePath, finalPath); err == nil {
 		filePath = finalPath
@@ -2081,7 +2280,7 @@ func (h *MusicHandler) downloadAndPrepareFromPlatform(ctx context.Context, plat
 	}
 	cleanupList = append(cleanupList, filePath, finalDir)
 
-	h.embedTrackTags(ctx, plat, track, trackID, info, filePath, embedPicPath)
+	h.embedTrackTags(ctx, plat, track, trackID, info, songInfo, filePath, embedPicPath)
 
 	return filePath, thumbPicPath, cleanupList, nil
 }
@@ -2090,18 +2289,13 @@ func (h *MusicHandler) prepareCoverFiles(ctx context.Context, track *platform.Tr
 	if h == nil || track == nil || h.DownloadService == nil {
 		return "", ""
 	}
-	coverURL := ""
-	if track.CoverURL != "" {
-		coverURL = track.CoverURL
-	} else if track.Album != nil && track.Album.CoverURL != "" {
-		coverURL = track.Album.CoverURL
-	}
+	coverURL := trackCoverURL(track)
 	if coverURL == "" {
 		return "", ""
 	}
 
 	picPath := filepath.Join(h.CacheDir, fmt.Sprintf("%d-%s", stamp, path.Base(coverURL)))
-	if _, err := h.DownloadService.Download(ctx, &platform.DownloadInfo{URL: coverURL, Size: 0}, picPath, nil); err != nil {
+	if _, err := h.DownloadService.Download(download.WithPlatform(ctx, track.Platform), &platform.DownloadInfo{URL: coverURL, Size: 0}, picPath, nil); err != nil {
 		_ = os.Remove(picPath)
 		if h.Logger != nil {
 			h.Logger.Warn("failed to download cover", "track", trackID, "url", downloadURLForLog(coverURL), "error", downloadErrorForLog(err))
@@ -2140,11 +2334,18 @@ func (h *MusicHandler) prepareCoverFiles(ctx context.Context, track *platform.Tr
 	return picPath, resizePicPath
 }
 
-func (h *MusicHandler) embedTrackTags(ctx context.Context, plat platform.Platform, track *platform.Track, trackID string, info *platform.DownloadInfo, filePath, embedPicPath string) {
+func (h *MusicHandler) embedTrackTags(ctx context.Context, plat platform.Platform, track *platform.Track, trackID string, info *platform.DownloadInfo, songInfo *botpkg.SongInfo, filePath, embedPicPath string) {
 	if h == nil || h.ID3Service == nil {
 		return
 	}
+	if tagData := h.trackTagData(ctx, plat, track, trackID, info, songInfo, embedPicPath); tagData != nil {
+		h.writeTrackTags(plat, trackID, filePath, tagData, embedPicPath)
+	}
+}
 
+// trackTagData returns the tags for a track: the platform's tag provider when
+// it has one, the catalog metadata otherwise, plus measured ReplayGain.
+func (h *MusicHandler) trackTagData(ctx context.Context, plat platform.Platform, track *platform.Track, trackID string, info *platform.DownloadInfo, songInfo *botpkg.SongInfo, embedPicPath string) *id3.TagData {
 	var tagData *id3.TagData
 	if h.TagProviders != nil {
 		if provider, ok := h.TagProviders[plat.Name()]; ok && provider != nil {
@@ -2163,8 +2364,18 @@ func (h *MusicHandler) embedTrackTags(ctx context.Context, plat platform.Platfor
 		tagData = h.buildFallbackTagData(ctx, plat, track, embedPicPath)
 	}
 	if tagData == nil {
-		return
+		return nil
+	}
+	// Providers may hand out shared tag data; tag a copy.
+	tagged := *tagData
+	id3.FillFromTrack(&tagged, track)
+	if rg := replayGainFromSongInfo(songInfo); rg != nil {
+		tagged.ReplayGain = rg
 	}
+	return &tagged
+}
+
+func (h *MusicHandler) writeTrackTags(plat platform.Platform, trackID, filePath string, tagData *id3.TagData, embedPicPath string) {
 	if err := h.ID3Service.EmbedTags(filePath, tagData, embedPicPath); err != nil && h.Logger != nil {
 		errText := strings.ToLower(strings.TrimSpace(err.Error()))
 		if strings.Contains(errText, "unsupported ftyp") || strings.Contains(errText, "unsupported audio format for tags") {
@@ -2175,7 +2386,7 @@ func (h *MusicHandler) embedTrackTags(ctx context.Context, plat platform.Platfor
 	}
 }
 
-func (h *MusicHandler) sendMusic(ctx context.Context, b *telego.Bot, statusMsg *telego.Message, message *telego.Message, songInfo *botpkg.SongInfo, musicPath, picPath string, cleanup []string, cleanupDone func(), platformName, trackID string) error {
+func (h *MusicHandler) sendMusic(ctx context.Context, b *telego.Bot, statusMsg *telego.Message, message *telego.Message, songInfo *botpkg.SongInfo, musicPath, picPath string, cleanup []string, cleanupDone func(), platformName, trackID string, delivery *uploadDelivery) error {
 	if h == nil {
 		return errors.New("music handler not configured")
 	}
@@ -2224,6 +2435,7 @@ func (h *MusicHandler) sendMusic(ctx context.Context, b *telego.Bot, statusMsg *
 		resultCh:    resultCh,
 		loc:         reqLoc,
 		cacheHit:    musicPath == "",
+		delivery:    delivery,
 		onDone: func(result uploadResult) {
 			cleanupCtx, cleanupCancel := context.WithTimeout(detachContext(baseCtx), 30*time.Second)
 			defer cleanupCancel()
@@ -2233,7 +2445,7 @@ func (h *MusicHandler) sendMusic(ctx context.Context, b *telego.Bot, statusMsg *
 					songCopy.ThumbFileID = result.message.Audio.Thumbnail.FileID
 				}
 			}
-			if h.Repo != nil && result.err == nil && songCopy.FileID != "" {
+			if h.Repo != nil && result.err == nil && songCopy.FileID != "" && delivery.cacheable() {
 				if err := h.Repo.Create(cleanupCtx, &songCopy); err != nil {
 					if h.Logger != nil {
 						h.Logger.Error("failed to save song info", "platform", platformName, "trackID", trackID, "error", err)
@@ -2568,7 +2780,15 @@ func (h *MusicHandler) processUploadTask(task uploadTask) {
 			}
 		}
 	}
-	result.message, result.err = h.sendMusicDirect(task.ctx, task.b, task.message, &task.songInfo, task.musicPath, task.picPath)
+	if delivery := task.delivery; delivery != nil && (len(delivery.parts) > 0 || delivery.link != "") {
+		result.message, result.err = h.sendOversizeDelivery(task.ctx, task.b, task.message, &task.songInfo, task.picPath, delivery)
+		return
+	}
+	notice := ""
+	if task.delivery != nil {
+		notice = task.delivery.notice
+	}
+	result.message, result.err = h.sendMusicDirect(task.ctx, task.b, task.message, &task.songInfo, task.musicPath, task.picPath, notice)
 }
 
 // registerQueuedStatus appends one status message entry into upload-status queue.
@@ -2716,7 +2936,9 @@ func (h *MusicHandler) updateQueuedStatusText(messageID int, text string) {
 	}
 }
 
-func (h *MusicHandler) sendMusicDirect(ctx context.Context, b *telego.Bot, message *telego.Message, songInfo *botpkg.SongInfo, musicPath, picPath string) (*telego.Message, error) {
+// sendMusicDirect uploads one audio file; a non-empty notice (see
+// uploadDelivery) is appended to the caption.
+func (h *MusicHandler) sendMusicDirect(ctx context.Context, b *telego.Bot, message *telego.Message, songInfo *botpkg.SongInfo, musicPath, picPath, notice string) (*telego.Message, error) {
 	if songInfo == nil {
 		return nil, errors.New("song info required")
 	}
@@ -2747,7 +2969,7 @@ func (h *MusicHandler) sendMusicDirect(ctx context.Context, b *telego.Bot, messa
 		if err != nil {
 			return telego.InputFile{}, nil, err
 		}
-		return telego.InputFile{File: file}, file, nil
+		return h.audioUpload(uploadCtx, file), file, nil
 	}
 	openThumbUpload := func() (*telego.InputFile, *os.File) {
 		if strings.TrimSpace(picPath) == "" {
@@ -2775,7 +2997,7 @@ func (h *MusicHandler) sendMusicDirect(ctx context.Context, b *telego.Bot, messa
 		_ = b.SendChatAction(uploadCtx, &telego.SendChatActionParams{ChatID: telego.ChatID{ID: message.Chat.ID}, MessageThreadID: threadID, Action: telego.ChatActionUploadDocument})
 	}
 
-	caption := buildMusicCaption(ctx, h.PlatformManager, songInfo, h.BotName)
+	caption := appendUploadNotice(buildMusicCaption(ctx, h.PlatformManager, songInfo, h.BotName), notice)
 	params := &telego.SendAudioParams{
 		ChatID:          telego.ChatID{ID: message.Chat.ID},
 		MessageThreadID: threadID,
@@ -2851,7 +3073,7 @@ func (h *MusicHandler) sendMusicDirect(ctx context.Context, b *telego.Bot, messa
 			return audio, err
 		}
 		defer file.Close()
-		params.Audio = telego.InputFile{File: file}
+		params.Audio = h.audioUpload(uploadCtx, file)
 		if h.RateLimiter != nil {
 			audio, err = telegram.SendAudioWithRetry(uploadCtx, h.RateLimiter, b, params)
 		} else {
@@ -3251,6 +3473,7 @@ func (h *MusicHandler) prepareInlineSong(
 		}
 		defer releaseAdmission()
 	}
+	ctx = withBandwidthUser(ctx, userID)
 
 	if h.PlatformManager == nil {
 		if preferAtmos {
@@ -3472,6 +3695,19 @@ func (h *MusicHandler) prepareInlineSong(
 		return nil, call.err
 	}
 
+	// Inline results need a single audio file_id, so only a transcode can
+	// rescue an oversized file here.
+	musicPath, delivery, uploadCleanup, err := h.fitUploadLimit(ctx, &songInfo, musicPath, singleFileStrategies(h.OversizeStrategies), nil)
+	defer func() {
+		if cleanupErr := cleanupFiles(uploadCleanup...); cleanupErr != nil && h.Logger != nil {
+			h.Logger.Warn("failed to clean oversize artifacts", "platform", platformName, "trackID", trackID, "error", cleanupErr)
+		}
+	}()
+	if err != nil {
+		call.err = err
+		return nil, err
+	}
+
 	if progress != nil {
 		progress(buildMusicInfoText(ctx, songInfo.SongName, songInfo.SongAlbum, formatFileInfo(songInfo.FileExt, songInfo.MusicSize), tr(ctx, "uploading")))
 	}
@@ -3486,10 +3722,14 @@ func (h *MusicHandler) prepareInlineSong(
 		return nil, err
 	}
 	defer file.Close()
-	caption := buildMusicCaption(ctx, h.PlatformManager, &songInfo, h.BotName)
+	notice := ""
+	if delivery != nil {
+		notice = delivery.notice
+	}
+	caption := appendUploadNotice(buildMusicCaption(ctx, h.PlatformManager, &songInfo, h.BotName), notice)
 	params := &telego.SendAudioParams{
 		ChatID:    telego.ChatID{ID: uploadChatID},
-		Audio:     telego.InputFile{File: file},
+		Audio:     h.audioUpload(ctx, file),
 		Caption:   caption,
 		ParseMode: telego.ModeHTML,
 		Title:     songInfo.SongName,
@@ -3522,7 +3762,7 @@ func (h *MusicHandler) prepareInlineSong(
 		songInfo.ThumbFileID = uploaded.Audio.Thumbnail.FileID
 	}
 
-	if h.Repo != nil {
+	if h.Repo != nil && delivery.cacheable() {
 		_ = h.Repo.Create(ctx, &songInfo)
 	}
 	copy := songInfo
@@ -3652,7 +3892,7 @@ func (h *MusicHandler) serialGateFor(platformName, trackID string, quality platf
 	if plat == nil {
 		return nil
 	}
-	gateProvider, ok := plat.(platform.SerialDownloadGate)
+	gateProvider, ok := platform.As[platform.SerialDownloadGate](plat)
 	if !ok || !gateProvider.NeedsSerialDownload(trackID, quality) {
 		return nil
 	}
@@ -3801,6 +4041,21 @@ func (h *MusicHandler) releaseSlot(gate chan struct{}) func() {
 	}
 }
 
+// SetDownloadQueueLimits changes the admission caps in place, e.g. after a
+// config reload. Work already admitted is not affected; a lowered cap only
+// rejects new tasks until the live counts drop below it.
+func (h *MusicHandler) SetDownloadQueueLimits(waitLimit, perUserLimit, perChatLimit, globalLimit int) {
+	if h == nil {
+		return
+	}
+	h.downloadQueueMu.Lock()
+	defer h.downloadQueueMu.Unlock()
+	h.DownloadQueueWaitLimit = waitLimit
+	h.DownloadQueuePerUserLimit = perUserLimit
+	h.DownloadQueuePerChatLimit = perChatLimit
+	h.DownloadQueueGlobalLimit = globalLimit
+}
+
 // DownloadQueueStats reports the live download queue counters: how many tasks
 // are waiting for a slot/gate, how many are actively downloading, and the
 // configured waiting-queue cap (0 = unlimited). Used by the "view queue" button.
@@ -3837,6 +4092,10 @@ func (h *MusicHandler) DownloadQueueSnapshot() DownloadQueueSnapshot {
 		snapshot.UploadRunning = len(h.UploadLimiter)
 		snapshot.UploadLimit = cap(h.UploadLimiter)
 	}
+	snapshot.DownloadRate = h.DownloadBandwidth.Rate()
+	snapshot.DownloadRateLimit = h.DownloadBandwidth.Limits().Global
+	snapshot.UploadRate = h.UploadBandwidth.Rate()
+	snapshot.UploadRateLimit = h.UploadBandwidth.Limits().Global
 	return snapshot
 }
 
@@ -287,6 +287,7 @@ func TestUploadShutdownRejectsLateSendMusic(t *testing.T) {
 		func() { atomic.AddInt32(&cleanupDoneCalls, 1) },
 		"test",
 		"late",
+		nil,
 	)
 	if !errors.Is(err, errUploadShuttingDown) {
 		t.Fatalf("sendMusic error = %v, want %v", err, errUploadShuttingDown)
@@ -0,0 +1,177 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	botpkg "github.com/liuran001/MusicBot-Go/bot"
+	"github.com/liuran001/MusicBot-Go/bot/platform"
+	"github.com/liuran001/MusicBot-Go/bot/spectrum"
+	"github.com/mymmrac/telego"
+)
+
+// Output formats a chat can pick as DefaultOutputFormat. "original" uploads
+// the file as delivered by the platform.
+const (
+	outputFormatOriginal = "original"
+	outputFormatMP3      = "mp3_320"
+	outputFormatAAC      = "aac_256"
+	outputFormatOpus     = "opus_160"
+	outputFormatFLAC     = "flac"
+)
+
+// settingsOutputFormats is the order the formats appear in /settings.
+var settingsOutputFormats = []string{outputFormatOriginal, outputFormatMP3, outputFormatAAC, outputFormatOpus, outputFormatFLAC}
+
+// outputFormatSpec describes the ffmpeg encode behind an output format.
+type outputFormatSpec struct {
+	ext string
+	// codec is the ffprobe codec name of the result; a source already in this
+	// codec (at or below kbps) is uploaded without re-encoding.
+	codec string
+	// kbps is the target bitrate, 0 for lossless.
+	kbps int
+	args []string
+}
+
+var outputFormatSpecs = map[string]outputFormatSpec{
+	outputFormatMP3:  {ext: "mp3", codec: "mp3", kbps: 320, args: []string{"-c:a", "libmp3lame", "-b:a", "320k", "-ac", "2", "-id3v2_version", "3"}},
+	outputFormatAAC:  {ext: "m4a", codec: "aac", kbps: 256, args: []string{"-c:a", "aac", "-b:a", "256k", "-ac", "2", "-movflags", "+faststart"}},
+	outputFormatOpus: {ext: "opus", codec: "opus", kbps: 160, args: []string{"-c:a", "libopus", "-b:a", "160k", "-ac", "2"}},
+	outputFormatFLAC: {ext: "flac", codec: "flac", args: []string{"-c:a", "flac", "-compression_level", "8"}},
+}
+
+// normalizeOutputFormat maps empty or unknown values to "original".
+func normalizeOutputFormat(value string) string {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if _, ok := outputFormatSpecs[value]; ok {
+		return value
+	}
+	return outputFormatOriginal
+}
+
+func outputFormatDisplayName(ctx context.Context, format string) string {
+	switch normalizeOutputFormat(format) {
+	case outputFormatMP3:
+		return "MP3 320k"
+	case outputFormatAAC:
+		return "AAC 256k"
+	case outputFormatOpus:
+		return "Opus 160k"
+	case outputFormatFLAC:
+		return "FLAC"
+	default:
+		return tr(ctx, "set_output_original")
+	}
+}
+
+// resolveOutputFormat returns the output format configured for the chat the
+// request came from: group settings in groups, user settings otherwise.
+func (h *MusicHandler) resolveOutputFormat(ctx context.Context, message *telego.Message, userID int64) string {
+	if h == nil || h.Repo == nil {
+		return outputFormatOriginal
+	}
+	if message != nil && message.Chat.Type != "private" {
+		if settings, err := h.Repo.GetGroupSettings(ctx, message.Chat.ID); err == nil && settings != nil {
+			return normalizeOutputFormat(settings.DefaultOutputFormat)
+		}
+		return outputFormatOriginal
+	}
+	if userID != 0 {
+		if settings, err := h.Repo.GetUserSettings(ctx, userID); err == nil && settings != nil {
+			return normalizeOutputFormat(settings.DefaultOutputFormat)
+		}
+	}
+	return outputFormatOriginal
+}
+
+// songVariantStore returns the repository's variant lookups, or nil when the
+// repository cannot keep transcodes apart from originals.
+func (h *MusicHandler) songVariantStore() botpkg.SongVariantStore {
+	if h == nil || h.Repo == nil {
+		return nil
+	}
+	store, _ := h.Repo.(botpkg.SongVariantStore)
+	return store
+}
+
+// convertOutputFormat transcodes the prepared file at musicPath to format and
+// re-tags the result. The prepared file is shared between requests, so the
+// output goes to its own directory, returned in created for cleanup. A source
+// already in the target codec is returned unchanged, and so is a lossy source
+// asked for a lossless format, which re-encoding would only inflate.
+func (h *MusicHandler) convertOutputFormat(ctx context.Context, plat platform.Platform, track *platform.Track, trackID string, info *platform.DownloadInfo, songInfo *botpkg.SongInfo, musicPath, picPath, format string) (string, []string, error) {
+	spec, ok := outputFormatSpecs[format]
+	if !ok {
+		return musicPath, nil, nil
+	}
+	if codec, err := detectExtractedAudioCodec(musicPath); err == nil {
+		sameCodec := codec == spec.codec && (spec.kbps == 0 || songInfo.BitRate <= spec.kbps*1000)
+		lossyToLossless := spec.kbps == 0 && !spectrum.IsLosslessCodec(codec)
+		if sameCodec || lossyToLossless {
+			songInfo.OutputFormat = format
+			return musicPath, nil, nil
+		}
+	}
+	dir := filepath.Join(filepath.Dir(musicPath), fmt.Sprintf("format-%d", time.Now().UnixNano()))
+	if err := os.Mkdir(dir, 0o755); err != nil {
+		return "", nil, err
+	}
+	created := []string{dir}
+	out := filepath.Join(dir, strings.TrimSuffix(filepath.Base(musicPath), filepath.Ext(musicPath))+"."+spec.ext)
+	if err := transcodeOutputFormat(ctx, musicPath, out, spec); err != nil {
+		return "", created, err
+	}
+
+	// Carry the full-size embedded cover over; the thumbnail is only a
+	// fallback for sources whose container taglib cannot read.
+	coverPath := picPath
+	if h.ID3Service != nil {
+		if cover, err := h.ID3Service.ReadCover(musicPath); err == nil && len(cover) > 0 {
+			embedded := filepath.Join(dir, "cover")
+			if err := os.WriteFile(embedded, cover, 0o644); err == nil {
+				coverPath = embedded
+			}
+		}
+	}
+	songInfo.OutputFormat = format
+	songInfo.FileExt = spec.ext
+	songInfo.AudioCodec = spec.codec
+	if spec.kbps > 0 {
+		songInfo.BitDepth = 0
+	}
+	if format == outputFormatOpus {
+		songInfo.SampleRate = 48000
+	}
+	deriveBitrateFromFile(out, songInfo)
+	if plat != nil {
+		h.embedTrackTags(ctx, plat, track, trackID, info, songInfo, out, coverPath)
+		songInfo.MusicSize = int(fileSizeOf(out))
+	}
+	return out, created, nil
+}
+
+// transcodeOutputFormat encodes the first audio stream of src into dst. The
+// cover is dropped here and re-embedded by the tagger.
+func transcodeOutputFormat(ctx context.Context, src, dst string, spec outputFormatSpec) error {
+	ffmpegPath, err := exec.LookPath("ffmpeg")
+	if err != nil {
+		return err
+	}
+	args := []string{"-y", "-i", src, "-map", "0:a:0", "-vn", "-map_metadata", "0"}
+	args = append(args, spec.args...)
+	args = append(args, dst)
+	cmd := exec.CommandContext(ctx, ffmpegPath, args...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("transcode to %s: %w, stderr: %s", spec.ext, err, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
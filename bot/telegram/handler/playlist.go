@@ -19,6 +19,7 @@ const (
 	playlistCacheTTL       = 10 * time.Minute
 	collectionTypeAlbum    = "album"
 	collectionTypePlaylist = "playlist"
+	collectionTypeChart    = "chart"
 )
 
 type playlistState struct {
@@ -42,6 +43,7 @@ type PlaylistHandler struct {
 	ResourceLimiter *ResourceRateLimiter
 	DefaultQuality  string
 	PageSize        int
+	Preview         *PreviewHandler
 	playlistMu      sync.Mutex
 	playlistCache   map[int]*playlistState
 }
@@ -378,6 +380,7 @@ func (h *PlaylistHandler) buildPlaylistPage(ctx context.Context, tracks []platfo
 		textMessage.WriteString(tr(ctx, "pl_no_results") + "\n")
 	}
 	buttons := make([]telego.InlineKeyboardButton, 0, len(tracks))
+	var previewRow []telego.InlineKeyboardButton
 	for idx, track := range tracks {
 		escapedTitle := mdV2Replacer.Replace(track.Title)
 		trackLink := escapedTitle
@@ -404,12 +407,18 @@ func (h *PlaylistHandler) buildPlaylistPage(ctx context.Context, tracks []platfo
 			Text:         fmt.Sprintf("%d", displayIndex),
 			CallbackData: callbackData,
 		})
+		if button, ok := h.Preview.button(ctx, platformName, track.ID, requesterID, displayIndex); ok {
+			previewRow = append(previewRow, button)
+		}
 	}
 
 	var rows [][]telego.InlineKeyboardButton
 	if len(buttons) > 0 {
 		rows = append(rows, buttons)
 	}
+	if len(previewRow) > 0 {
+		rows = append(rows, previewRow)
+	}
 	if pageCount > 1 {
 		navRow := make([]telego.InlineKeyboardButton, 0, 2)
 		if page == 1 {
@@ -739,7 +748,7 @@ func detectCollectionType(rawID, collectionURL string) string {
 	if strings.Contains(trimmedURL, "/album") {
 		return collectionTypeAlbum
 	}
-	if rawType := strings.ToLower(strings.TrimSpace(rawID)); rawType == collectionTypeAlbum || rawType == collectionTypePlaylist {
+	if rawType := strings.ToLower(strings.TrimSpace(rawID)); rawType == collectionTypeAlbum || rawType == collectionTypePlaylist || rawType == collectionTypeChart {
 		return rawType
 	}
 	return collectionTypePlaylist
@@ -753,8 +762,11 @@ func collectionTypeFromID(collectionID string) string {
 }
 
 func collectionTypeLabel(ctx context.Context, collectionType string) string {
-	if strings.EqualFold(strings.TrimSpace(collectionType), collectionTypeAlbum) {
+	switch strings.ToLower(strings.TrimSpace(collectionType)) {
+	case collectionTypeAlbum:
 		return tr(ctx, "pl_collection_album")
+	case collectionTypeChart:
+		return tr(ctx, "pl_collection_chart")
 	}
 	return tr(ctx, "pl_collection_playlist")
 }
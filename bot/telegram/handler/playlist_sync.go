@@ -0,0 +1,359 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	botpkg "github.com/liuran001/MusicBot-Go/bot"
+	"github.com/liuran001/MusicBot-Go/bot/i18n"
+	"github.com/liuran001/MusicBot-Go/bot/platform"
+	"github.com/liuran001/MusicBot-Go/bot/telegram"
+	"github.com/mymmrac/telego"
+)
+
+// Playlist-sync callback data formats (space separated, <=64 bytes):
+//
+//	auto:     "psub a <chatID> <subscriptionID> <0|1>"  (/subscribe list)
+//	remove:   "psub u <chatID> <subscriptionID>"        (/subscribe list)
+//	download: "psub d <platform> <trackID>"             (additions post)
+
+const (
+	defaultPlaylistSyncPerChatLimit = 10
+	// playlistSyncPageSize is the page requested per GetPlaylist call while
+	// taking a snapshot; playlistSyncMaxTracks bounds the snapshot so a huge
+	// playlist cannot turn one poll into dozens of platform calls.
+	playlistSyncPageSize  = 100
+	playlistSyncMaxTracks = 2000
+)
+
+// PlaylistSyncHandler serves /subscribe. A chat (private chat, group or
+// channel) subscribes to a playlist; the PlaylistSyncWatcher later posts the
+// tracks added to it, optionally downloading them too.
+type PlaylistSyncHandler struct {
+	Store           botpkg.PlaylistSubscriptionStore
+	PlatformManager platform.Manager
+	RateLimiter     *telegram.RateLimiter
+	ResourceLimiter *ResourceRateLimiter
+	Music           *MusicHandler
+	PerChatLimit    int
+	Logger          botpkg.Logger
+}
+
+func (h *PlaylistSyncHandler) perChatLimit() int {
+	if h == nil || h.PerChatLimit <= 0 {
+		return defaultPlaylistSyncPerChatLimit
+	}
+	return h.PerChatLimit
+}
+
+// Handle serves /subscribe:
+//
+//	/subscribe                                list this chat's subscriptions
+//	/subscribe <playlist link> [auto]         subscribe this chat
+//	/subscribe <playlist link> [auto] <@chan> subscribe a channel/group the caller administers
+//	/subscribe <@chan>                        list that chat's subscriptions
+//
+// "auto" also downloads added tracks at the chat's default quality.
+func (h *PlaylistSyncHandler) Handle(ctx context.Context, b *telego.Bot, update *telego.Update) {
+	if update == nil || update.Message == nil {
+		return
+	}
+	message := update.Message
+	if h.Store == nil {
+		sendText(ctx, b, message.Chat.ID, message.MessageID, tr(ctx, "psub_failed"))
+		return
+	}
+	var userID int64
+	if message.From != nil {
+		userID = message.From.ID
+	}
+
+	link, target, auto := splitPlaylistSyncArgs(commandArguments(message.Text))
+	targetChatID := message.Chat.ID
+	targetName := ""
+	if target != "" {
+		chatID, name, ok := resolveManagedChat(ctx, b, target, userID)
+		if !ok {
+			sendText(ctx, b, message.Chat.ID, message.MessageID, tr(ctx, "artw_target_invalid"))
+			return
+		}
+		targetChatID, targetName = chatID, name
+	} else if message.Chat.Type != "private" && link != "" && !isRequesterOrAdmin(ctx, b, message.Chat.ID, userID, 0) {
+		sendText(ctx, b, message.Chat.ID, message.MessageID, tr(ctx, "psub_admin_only"))
+		return
+	}
+
+	if link == "" {
+		text, keyboard := h.buildList(ctx, targetChatID, targetName)
+		h.send(ctx, b, message, text, keyboard)
+		return
+	}
+
+	platformName, playlistID, ok := matchPlaylistURL(ctx, h.PlatformManager, link)
+	if !ok {
+		sendText(ctx, b, message.Chat.ID, message.MessageID, tr(ctx, "psub_usage"))
+		return
+	}
+	text, subscribed := h.subscribe(ctx, targetChatID, userID, message.Chat.ID, platformName, playlistID, auto)
+	if subscribed && targetName != "" {
+		text = tr(ctx, "artw_followed_in", map[string]any{"Chat": targetName}) + "\n" + text
+	}
+	h.send(ctx, b, message, text, nil)
+}
+
+func (h *PlaylistSyncHandler) send(ctx context.Context, b *telego.Bot, message *telego.Message, text string, keyboard *telego.InlineKeyboardMarkup) {
+	params := &telego.SendMessageParams{
+		ChatID:             telego.ChatID{ID: message.Chat.ID},
+		MessageThreadID:    message.MessageThreadID,
+		Text:               text,
+		ReplyParameters:    buildReplyParams(message),
+		LinkPreviewOptions: &telego.LinkPreviewOptions{IsDisabled: true},
+	}
+	if keyboard != nil {
+		params.ReplyMarkup = keyboard
+	}
+	if h.RateLimiter != nil {
+		_, _ = telegram.SendMessageWithRetry(ctx, h.RateLimiter, b, params)
+	} else {
+		_, _ = b.SendMessage(ctx, params)
+	}
+}
+
+// splitPlaylistSyncArgs extracts the "auto" flag and the optional target chat
+// from the /subscribe arguments.
+func splitPlaylistSyncArgs(args string) (link, target string, auto bool) {
+	fields := strings.Fields(args)
+	kept := fields[:0]
+	for _, field := range fields {
+		if strings.EqualFold(field, "auto") {
+			auto = true
+			continue
+		}
+		kept = append(kept, field)
+	}
+	link, target = splitArtistWatchArgs(strings.Join(kept, " "))
+	return link, target, auto
+}
+
+// subscribe snapshots the playlist and stores the subscription for chatID.
+// requestChatID is the chat the request came from, charged for the snapshot.
+func (h *PlaylistSyncHandler) subscribe(ctx context.Context, chatID, userID, requestChatID int64, platformName, playlistID string, auto bool) (string, bool) {
+	plat := h.PlatformManager.Get(platformName)
+	if plat == nil {
+		return tr(ctx, "psub_failed"), false
+	}
+	if subscribed, err := h.Store.IsPlaylistSubscribed(ctx, chatID, platformName, playlistID); err == nil && !subscribed {
+		count, err := h.Store.CountPlaylistSubscriptions(ctx, chatID)
+		if err != nil {
+			return tr(ctx, "psub_failed"), false
+		}
+		if int(count) >= h.perChatLimit() {
+			return tr(ctx, "psub_limit", map[string]any{"Limit": h.perChatLimit()}), false
+		}
+	}
+	if !h.ResourceLimiter.AllowFor(ActionPlaylist, userID, requestChatID, platformName) {
+		return tr(ctx, "err_rate_limited"), false
+	}
+	playlist, tracks, err := fetchPlaylistSnapshot(ctx, plat, playlistID, playlistSyncMaxTracks)
+	if err != nil {
+		if h.Logger != nil {
+			h.Logger.Warn("failed to snapshot playlist", "platform", platformName, "playlistID", playlistID, "error", err)
+		}
+		return userVisiblePlaylistError(ctx, err), false
+	}
+	title := strings.TrimSpace(playlist.Title)
+	if title == "" {
+		title = playlistID
+	}
+	checkedAt := time.Now()
+	err = h.Store.AddPlaylistSubscription(ctx, &botpkg.PlaylistSubscription{
+		ChatID:          chatID,
+		Platform:        platformName,
+		PlaylistID:      playlistID,
+		Title:           title,
+		URL:             strings.TrimSpace(playlist.URL),
+		CreatedByUserID: userID,
+		Language:        i18n.From(ctx).Lang(),
+		AutoDownload:    auto,
+		TrackIDs:        playlistTrackIDs(tracks),
+		LastCheckedAt:   &checkedAt,
+	})
+	if err != nil {
+		if h.Logger != nil {
+			h.Logger.Warn("failed to add playlist subscription", "chatID", chatID, "platform", platformName, "playlistID", playlistID, "error", err)
+		}
+		return tr(ctx, "psub_failed"), false
+	}
+	text := tr(ctx, "psub_subscribed", map[string]any{"Title": title, "Count": len(tracks)})
+	if auto {
+		text += "\n" + tr(ctx, "psub_subscribed_auto")
+	}
+	return text, true
+}
+
+// fetchPlaylistSnapshot pages through a playlist with WithPlaylistOffset and
+// WithPlaylistLimit and returns its metadata and tracks in playlist order,
+// de-duplicated by ID and capped at maxTracks. Platforms that ignore the
+// paging hints return everything at once; paging stops as soon as a page adds
+// no new track. Any page failure fails the whole snapshot, since a partial one
+// would report the missing tail as additions on the next poll.
+func fetchPlaylistSnapshot(ctx context.Context, plat platform.Platform, playlistID string, maxTracks int) (*platform.Playlist, []platform.Track, error) {
+	var meta *platform.Playlist
+	tracks := make([]platform.Track, 0, playlistSyncPageSize)
+	seen := make(map[string]struct{})
+	offset := 0
+	for len(tracks) < maxTracks {
+		pageCtx := platform.WithPlaylistLimit(platform.WithPlaylistOffset(ctx, offset), playlistSyncPageSize)
+		page, err := plat.GetPlaylist(pageCtx, playlistID)
+		if err != nil {
+			return nil, nil, err
+		}
+		if page == nil {
+			return nil, nil, platform.NewNotFoundError(plat.Name(), "playlist", playlistID)
+		}
+		if meta == nil {
+			meta = page
+		}
+		added := 0
+		for _, track := range page.Tracks {
+			id := strings.TrimSpace(track.ID)
+			if id == "" {
+				continue
+			}
+			if _, dup := seen[id]; dup {
+				continue
+			}
+			seen[id] = struct{}{}
+			tracks = append(tracks, track)
+			added++
+		}
+		if added == 0 || len(page.Tracks) < playlistSyncPageSize {
+			break
+		}
+		if meta.TrackCount > 0 && len(tracks) >= meta.TrackCount {
+			break
+		}
+		offset += len(page.Tracks)
+	}
+	if len(tracks) > maxTracks {
+		tracks = tracks[:maxTracks]
+	}
+	return meta, tracks, nil
+}
+
+func playlistTrackIDs(tracks []platform.Track) []string {
+	ids := make([]string, 0, len(tracks))
+	for _, track := range tracks {
+		ids = append(ids, strings.TrimSpace(track.ID))
+	}
+	return ids
+}
+
+func (h *PlaylistSyncHandler) buildList(ctx context.Context, chatID int64, chatName string) (string, *telego.InlineKeyboardMarkup) {
+	subs, err := h.Store.ListPlaylistSubscriptions(ctx, chatID)
+	if err != nil {
+		return tr(ctx, "psub_failed"), nil
+	}
+	var lines []string
+	if chatName != "" {
+		lines = append(lines, tr(ctx, "artw_list_chat", map[string]any{"Chat": chatName}))
+	}
+	if len(subs) == 0 {
+		lines = append(lines, tr(ctx, "psub_list_empty"))
+		return strings.Join(lines, "\n"), nil
+	}
+	lines = append(lines, tr(ctx, "psub_list_title", map[string]any{"Count": len(subs), "Limit": h.perChatLimit()}))
+	rows := make([][]telego.InlineKeyboardButton, 0, len(subs))
+	for idx, sub := range subs {
+		line := fmt.Sprintf("%d. %s %s", idx+1, platformEmoji(h.PlatformManager, sub.Platform), truncateText(sub.Title, 48))
+		autoText := tr(ctx, "psub_auto_off_button")
+		autoNext := 1
+		if sub.AutoDownload {
+			line += " ⬇️"
+			autoText = tr(ctx, "psub_auto_on_button")
+			autoNext = 0
+		}
+		lines = append(lines, line)
+		rows = append(rows, []telego.InlineKeyboardButton{
+			{Text: fmt.Sprintf("%d · %s", idx+1, autoText), CallbackData: fmt.Sprintf("psub a %d %d %d", chatID, sub.ID, autoNext)},
+			{Text: fmt.Sprintf("✖ %d", idx+1), CallbackData: fmt.Sprintf("psub u %d %d", chatID, sub.ID)},
+		})
+	}
+	lines = append(lines, "", tr(ctx, "psub_list_hint"))
+	return strings.Join(lines, "\n"), &telego.InlineKeyboardMarkup{InlineKeyboard: rows}
+}
+
+// PlaylistSyncCallbackHandler handles "psub ..." callbacks.
+type PlaylistSyncCallbackHandler struct {
+	Sync *PlaylistSyncHandler
+}
+
+func (h *PlaylistSyncCallbackHandler) Handle(ctx context.Context, b *telego.Bot, update *telego.Update) {
+	if update == nil || update.CallbackQuery == nil || h.Sync == nil || h.Sync.Store == nil {
+		return
+	}
+	query := update.CallbackQuery
+	parts := strings.Fields(query.Data)
+	if len(parts) < 4 || parts[0] != "psub" || query.Message == nil {
+		return
+	}
+	msg := query.Message.Message()
+	if msg == nil {
+		return
+	}
+	answer := func(text string, alert bool) {
+		_ = b.AnswerCallbackQuery(ctx, &telego.AnswerCallbackQueryParams{CallbackQueryID: query.ID, Text: text, ShowAlert: alert})
+	}
+
+	switch parts[1] {
+	case "a", "u":
+		chatID, errChat := strconv.ParseInt(parts[2], 10, 64)
+		subID, errSub := strconv.ParseUint(parts[3], 10, 64)
+		if errChat != nil || errSub != nil {
+			return
+		}
+		privateSelf := msg.Chat.Type == telego.ChatTypePrivate && chatID == msg.Chat.ID
+		if !privateSelf && !isRequesterOrAdmin(ctx, b, chatID, query.From.ID, 0) {
+			answer(tr(ctx, "psub_admin_only"), true)
+			return
+		}
+		if parts[1] == "a" {
+			enabled := len(parts) > 4 && parts[4] == "1"
+			if err := h.Sync.Store.SetPlaylistSubscriptionAutoDownload(ctx, chatID, uint(subID), enabled); err != nil {
+				answer(tr(ctx, "psub_failed"), true)
+				return
+			}
+			answer(tr(ctx, "callback_success"), false)
+		} else {
+			if err := h.Sync.Store.RemovePlaylistSubscription(ctx, chatID, uint(subID)); err != nil {
+				answer(tr(ctx, "psub_failed"), true)
+				return
+			}
+			answer(tr(ctx, "psub_unsubscribed"), false)
+		}
+		chatName := ""
+		if chatID != msg.Chat.ID {
+			chatName = strconv.FormatInt(chatID, 10)
+		}
+		text, keyboard := h.Sync.buildList(ctx, chatID, chatName)
+		params := &telego.EditMessageTextParams{
+			ChatID:             telego.ChatID{ID: msg.Chat.ID},
+			MessageID:          msg.MessageID,
+			Text:               text,
+			LinkPreviewOptions: &telego.LinkPreviewOptions{IsDisabled: true},
+		}
+		if keyboard != nil {
+			params.ReplyMarkup = keyboard
+		}
+		if h.Sync.RateLimiter != nil {
+			_, _ = telegram.EditMessageTextWithRetry(ctx, h.Sync.RateLimiter, b, params)
+		} else {
+			_, _ = b.EditMessageText(ctx, params)
+		}
+	case "d":
+		dispatchAnnouncedTrack(ctx, b, h.Sync.Music, query, msg, parts[2], parts[3])
+	}
+}
@@ -0,0 +1,224 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	botpkg "github.com/liuran001/MusicBot-Go/bot"
+	"github.com/liuran001/MusicBot-Go/bot/platform"
+	"github.com/liuran001/MusicBot-Go/bot/platform/registry"
+	"github.com/mymmrac/telego"
+)
+
+// stubPagedPlaylistPlatform serves a fixed playlist. With paged set it honors
+// WithPlaylistOffset/WithPlaylistLimit; otherwise it returns every track.
+type stubPagedPlaylistPlatform struct {
+	stubSearchPlatform
+	tracks []platform.Track
+	paged  bool
+	calls  *int
+}
+
+func (s stubPagedPlaylistPlatform) GetPlaylist(ctx context.Context, playlistID string) (*platform.Playlist, error) {
+	if s.calls != nil {
+		*s.calls++
+	}
+	tracks := s.tracks
+	if s.paged {
+		offset := platform.PlaylistOffsetFromContext(ctx)
+		limit := platform.PlaylistLimitFromContext(ctx)
+		if offset > len(tracks) {
+			offset = len(tracks)
+		}
+		tracks = tracks[offset:]
+		if limit > 0 && len(tracks) > limit {
+			tracks = tracks[:limit]
+		}
+	}
+	return &platform.Playlist{ID: playlistID, Title: "Mix", TrackCount: len(s.tracks), Tracks: tracks}, nil
+}
+
+type memoryPlaylistStore struct {
+	subs    []*botpkg.PlaylistSubscription
+	checked map[uint][]string
+}
+
+func (m *memoryPlaylistStore) AddPlaylistSubscription(ctx context.Context, sub *botpkg.PlaylistSubscription) error {
+	m.subs = append(m.subs, sub)
+	return nil
+}
+func (m *memoryPlaylistStore) RemovePlaylistSubscription(ctx context.Context, chatID int64, id uint) error {
+	return nil
+}
+func (m *memoryPlaylistStore) SetPlaylistSubscriptionAutoDownload(ctx context.Context, chatID int64, id uint, enabled bool) error {
+	return nil
+}
+func (m *memoryPlaylistStore) IsPlaylistSubscribed(ctx context.Context, chatID int64, platformName, playlistID string) (bool, error) {
+	return false, nil
+}
+func (m *memoryPlaylistStore) ListPlaylistSubscriptions(ctx context.Context, chatID int64) ([]*botpkg.PlaylistSubscription, error) {
+	return m.subs, nil
+}
+func (m *memoryPlaylistStore) CountPlaylistSubscriptions(ctx context.Context, chatID int64) (int64, error) {
+	return int64(len(m.subs)), nil
+}
+func (m *memoryPlaylistStore) ListDuePlaylistSubscriptions(ctx context.Context, limit int) ([]*botpkg.PlaylistSubscription, error) {
+	return m.subs, nil
+}
+func (m *memoryPlaylistStore) MarkPlaylistSubscriptionChecked(ctx context.Context, id uint, trackIDs []string, checkedAt time.Time) error {
+	if m.checked == nil {
+		m.checked = make(map[uint][]string)
+	}
+	m.checked[id] = trackIDs
+	return nil
+}
+
+func numberedTracks(n int) []platform.Track {
+	tracks := make([]platform.Track, 0, n)
+	for i := 1; i <= n; i++ {
+		tracks = append(tracks, platform.Track{ID: fmt.Sprintf("%d", i), Title: fmt.Sprintf("Song %d", i)})
+	}
+	return tracks
+}
+
+func TestFetchPlaylistSnapshotPagesThroughPlaylist(t *testing.T) {
+	calls := 0
+	plat := stubPagedPlaylistPlatform{stubSearchPlatform: stubSearchPlatform{name: "netease"}, tracks: numberedTracks(250), paged: true, calls: &calls}
+	meta, tracks, err := fetchPlaylistSnapshot(context.Background(), plat, "1", playlistSyncMaxTracks)
+	if err != nil {
+		t.Fatalf("snapshot: %v", err)
+	}
+	if meta.Title != "Mix" || len(tracks) != 250 || tracks[249].ID != "250" {
+		t.Fatalf("snapshot = %q with %d tracks", meta.Title, len(tracks))
+	}
+	if calls != 3 {
+		t.Fatalf("platform calls = %d, want 3 pages", calls)
+	}
+}
+
+func TestFetchPlaylistSnapshotStopsWhenPagingIgnored(t *testing.T) {
+	calls := 0
+	plat := stubPagedPlaylistPlatform{stubSearchPlatform: stubSearchPlatform{name: "spotify"}, tracks: numberedTracks(150), calls: &calls}
+	_, tracks, err := fetchPlaylistSnapshot(context.Background(), plat, "1", playlistSyncMaxTracks)
+	if err != nil {
+		t.Fatalf("snapshot: %v", err)
+	}
+	if len(tracks) != 150 || calls != 1 {
+		t.Fatalf("tracks = %d, calls = %d; want 150 tracks in one call", len(tracks), calls)
+	}
+}
+
+func TestDiffPlaylistTracks(t *testing.T) {
+	tracks := numberedTracks(4)
+	added, current := diffPlaylistTracks(&botpkg.PlaylistSubscription{}, tracks)
+	if len(added) != 0 || len(current) != 4 {
+		t.Fatalf("never-checked subscription should only seed, got added=%v current=%v", added, current)
+	}
+
+	checked := time.Now()
+	sub := &botpkg.PlaylistSubscription{TrackIDs: []string{"1", "3", "gone"}, LastCheckedAt: &checked}
+	added, current = diffPlaylistTracks(sub, tracks)
+	if len(added) != 2 || added[0].ID != "2" || added[1].ID != "4" {
+		t.Fatalf("added = %+v, want 2 and 4 in playlist order", added)
+	}
+	if strings.Join(current, ",") != "1,2,3,4" {
+		t.Fatalf("current = %v", current)
+	}
+}
+
+func TestSplitPlaylistSyncArgs(t *testing.T) {
+	cases := []struct {
+		in, link, target string
+		auto             bool
+	}{
+		{"", "", "", false},
+		{"https://music.163.com/playlist?id=1", "https://music.163.com/playlist?id=1", "", false},
+		{"https://music.163.com/playlist?id=1 auto", "https://music.163.com/playlist?id=1", "", true},
+		{"https://music.163.com/playlist?id=1 AUTO @mychannel", "https://music.163.com/playlist?id=1", "@mychannel", true},
+		{"@mychannel", "", "@mychannel", false},
+	}
+	for _, tc := range cases {
+		link, target, auto := splitPlaylistSyncArgs(tc.in)
+		if link != tc.link || target != tc.target || auto != tc.auto {
+			t.Errorf("splitPlaylistSyncArgs(%q) = %q, %q, %v; want %q, %q, %v", tc.in, link, target, auto, tc.link, tc.target, tc.auto)
+		}
+	}
+}
+
+func TestFormatPlaylistAdditionsCapsListing(t *testing.T) {
+	sub := &botpkg.PlaylistSubscription{Platform: "netease", Title: "Mix", URL: "https://music.163.com/playlist?id=1"}
+	added := numberedTracks(playlistSyncMaxListed + 3)
+	manager := platform.NewManagerWithRegistry(registry.New())
+	text := formatPlaylistAdditions(enCtx(), manager, sub, added)
+	if !strings.Contains(text, "and 3 more") || strings.Contains(text, fmt.Sprintf("Song %d", playlistSyncMaxListed+1)) {
+		t.Fatalf("text = %q", text)
+	}
+	keyboard := playlistAdditionsKeyboard(enCtx(), sub, added)
+	buttons := 0
+	for _, row := range keyboard.InlineKeyboard {
+		for _, button := range row {
+			if button.CallbackData != "" {
+				buttons++
+			}
+		}
+	}
+	if buttons != playlistSyncMaxListed {
+		t.Fatalf("download buttons = %d, want %d", buttons, playlistSyncMaxListed)
+	}
+	if got := keyboard.InlineKeyboard[0][0].CallbackData; got != "psub d netease 1" {
+		t.Fatalf("callback data = %q", got)
+	}
+}
+
+func TestPlaylistSyncWatcherSharesSnapshotAndRespectsBudget(t *testing.T) {
+	calls := 0
+	manager := platform.NewManagerWithRegistry(registry.New())
+	manager.Register(stubPagedPlaylistPlatform{stubSearchPlatform: stubSearchPlatform{name: "netease"}, tracks: numberedTracks(3), paged: true, calls: &calls})
+	store := &memoryPlaylistStore{subs: []*botpkg.PlaylistSubscription{
+		{ID: 1, ChatID: 10, Platform: "netease", PlaylistID: "p1"},
+		{ID: 2, ChatID: 20, Platform: "netease", PlaylistID: "p1"},
+		{ID: 3, ChatID: 30, Platform: "netease", PlaylistID: "p2"},
+	}}
+	limiter := NewResourceRateLimiter(map[string]ResourceLimit{
+		ActionPlaylistSync: {Window: time.Hour, PerPlatform: 1},
+	})
+	watcher := &PlaylistSyncWatcher{Store: store, PlatformManager: manager, ResourceLimiter: limiter}
+
+	// Never-checked subscriptions only seed, so no Telegram client is needed.
+	watcher.RunOnce(context.Background(), nil)
+	if calls != 1 {
+		t.Fatalf("platform calls = %d, want 1 (shared snapshot, then budget exhausted)", calls)
+	}
+	if len(store.checked[1]) != 3 || len(store.checked[2]) != 3 {
+		t.Fatalf("both subscribers of the polled playlist should be seeded: %v", store.checked)
+	}
+	if _, ok := store.checked[3]; ok {
+		t.Fatalf("over-budget playlist must stay due for the next poll")
+	}
+}
+
+func TestPlaylistSyncAutoDownloadEnqueueNeverBlocks(t *testing.T) {
+	watcher := &PlaylistSyncWatcher{Music: &MusicHandler{}}
+	// No worker drains the queue, as when it waits out a full download queue.
+	watcher.autoOnce.Do(func() { watcher.autoQueue = make(chan playlistAutoDownload, 1) })
+	sub := &botpkg.PlaylistSubscription{ChatID: 10, PlaylistID: "p1", AutoDownload: true}
+
+	done := make(chan struct{})
+	go func() {
+		for range 3 {
+			watcher.enqueueAutoDownload(context.Background(), nil, playlistAutoDownload{sub: sub, posted: &telego.Message{}})
+		}
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("enqueueAutoDownload blocked on a full backlog")
+	}
+	if got := len(watcher.autoQueue); got != 1 {
+		t.Fatalf("backlog holds %d jobs, want 1", got)
+	}
+}
@@ -0,0 +1,344 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	botpkg "github.com/liuran001/MusicBot-Go/bot"
+	"github.com/liuran001/MusicBot-Go/bot/i18n"
+	"github.com/liuran001/MusicBot-Go/bot/platform"
+	"github.com/liuran001/MusicBot-Go/bot/telegram"
+	"github.com/mymmrac/telego"
+)
+
+const (
+	defaultPlaylistSyncInterval  = time.Hour
+	defaultPlaylistSyncBatchSize = 30
+	playlistSyncStartDelay       = 2 * time.Minute
+	// playlistSyncMaxListed caps the tracks listed (and offered as buttons or
+	// auto-downloaded) per additions post; the rest are only counted.
+	playlistSyncMaxListed   = 10
+	playlistSyncPollTimeout = 2 * time.Minute
+	// playlistSyncAdmitRetry / playlistSyncAdmitAttempts pace auto-downloads
+	// when the chat's download queue is full, instead of dropping them.
+	playlistSyncAdmitRetry    = 15 * time.Second
+	playlistSyncAdmitAttempts = 8
+	// playlistSyncAutoQueueSize bounds the additions posts waiting for the
+	// auto-download worker; more are announced without downloads.
+	playlistSyncAutoQueueSize = 32
+)
+
+// PlaylistSyncWatcher periodically re-snapshots subscribed playlists and posts
+// the tracks added since the previous snapshot. Subscriptions of the same
+// playlist share one snapshot per poll, admitted through ResourceLimiter's
+// ActionPlaylistSync rule. Auto-download subscriptions hand the added tracks
+// to a single background worker, which queues them through
+// MusicHandler.dispatch, i.e. the regular download/upload queue, so waiting
+// for queue room never holds up the poll.
+type PlaylistSyncWatcher struct {
+	Store           botpkg.PlaylistSubscriptionStore
+	PlatformManager platform.Manager
+	RateLimiter     *telegram.RateLimiter
+	ResourceLimiter *ResourceRateLimiter
+	Music           *MusicHandler
+	Interval        time.Duration
+	BatchSize       int
+	Logger          botpkg.Logger
+
+	autoOnce  sync.Once
+	autoQueue chan playlistAutoDownload
+}
+
+type playlistAutoDownload struct {
+	sub    *botpkg.PlaylistSubscription
+	posted *telego.Message
+	added  []platform.Track
+}
+
+// Start runs the poll loop until ctx is cancelled.
+func (w *PlaylistSyncWatcher) Start(ctx context.Context, b *telego.Bot) {
+	if w == nil || w.Store == nil || w.PlatformManager == nil || b == nil {
+		return
+	}
+	interval := w.Interval
+	if interval <= 0 {
+		interval = defaultPlaylistSyncInterval
+	}
+	go func() {
+		timer := time.NewTimer(playlistSyncStartDelay)
+		defer timer.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-timer.C:
+			}
+			w.RunOnce(ctx, b)
+			timer.Reset(interval)
+		}
+	}()
+}
+
+type playlistSyncGroup struct {
+	platform   string
+	playlistID string
+	subs       []*botpkg.PlaylistSubscription
+}
+
+// RunOnce checks the least recently checked subscriptions once. Playlists
+// whose platform budget is exhausted are left unmarked and lead the next poll.
+func (w *PlaylistSyncWatcher) RunOnce(ctx context.Context, b *telego.Bot) {
+	batch := w.BatchSize
+	if batch <= 0 {
+		batch = defaultPlaylistSyncBatchSize
+	}
+	subs, err := w.Store.ListDuePlaylistSubscriptions(ctx, batch)
+	if err != nil {
+		if w.Logger != nil {
+			w.Logger.Warn("failed to list playlist subscriptions", "error", err)
+		}
+		return
+	}
+	for _, group := range groupPlaylistSubscriptions(subs) {
+		if ctx.Err() != nil {
+			return
+		}
+		w.pollPlaylist(ctx, b, group)
+	}
+}
+
+func groupPlaylistSubscriptions(subs []*botpkg.PlaylistSubscription) []playlistSyncGroup {
+	groups := make([]playlistSyncGroup, 0, len(subs))
+	index := make(map[string]int, len(subs))
+	for _, sub := range subs {
+		if sub == nil {
+			continue
+		}
+		key := sub.Platform + "\x00" + sub.PlaylistID
+		if idx, ok := index[key]; ok {
+			groups[idx].subs = append(groups[idx].subs, sub)
+			continue
+		}
+		index[key] = len(groups)
+		groups = append(groups, playlistSyncGroup{platform: sub.Platform, playlistID: sub.PlaylistID, subs: []*botpkg.PlaylistSubscription{sub}})
+	}
+	return groups
+}
+
+func (w *PlaylistSyncWatcher) pollPlaylist(ctx context.Context, b *telego.Bot, group playlistSyncGroup) {
+	now := time.Now()
+	plat := w.PlatformManager.Get(group.platform)
+	if plat == nil {
+		for _, sub := range group.subs {
+			w.mark(ctx, sub, sub.TrackIDs, now)
+		}
+		return
+	}
+	if !w.ResourceLimiter.AllowFor(ActionPlaylistSync, 0, 0, group.platform) {
+		return
+	}
+	pollCtx, cancel := context.WithTimeout(ctx, playlistSyncPollTimeout)
+	_, tracks, err := fetchPlaylistSnapshot(pollCtx, plat, group.playlistID, playlistSyncMaxTracks)
+	cancel()
+	if err != nil {
+		if w.Logger != nil {
+			w.Logger.Warn("failed to poll playlist", "platform", group.platform, "playlistID", group.playlistID, "error", err)
+		}
+		for _, sub := range group.subs {
+			w.mark(ctx, sub, sub.TrackIDs, now)
+		}
+		return
+	}
+	for _, sub := range group.subs {
+		if len(tracks) == 0 {
+			// An empty answer is far more likely a platform hiccup than a
+			// cleared playlist; keep the old snapshot.
+			w.mark(ctx, sub, sub.TrackIDs, now)
+			continue
+		}
+		added, current := diffPlaylistTracks(sub, tracks)
+		if len(added) > 0 && b != nil {
+			if posted := w.postAdditions(ctx, b, sub, added); posted != nil && sub.AutoDownload {
+				w.enqueueAutoDownload(ctx, b, playlistAutoDownload{sub: sub, posted: posted, added: added})
+			}
+		}
+		w.mark(ctx, sub, current, now)
+	}
+}
+
+func (w *PlaylistSyncWatcher) mark(ctx context.Context, sub *botpkg.PlaylistSubscription, trackIDs []string, checkedAt time.Time) {
+	if err := w.Store.MarkPlaylistSubscriptionChecked(ctx, sub.ID, trackIDs, checkedAt); err != nil && w.Logger != nil {
+		w.Logger.Warn("failed to update playlist subscription", "id", sub.ID, "error", err)
+	}
+}
+
+// diffPlaylistTracks returns the tracks missing from sub's snapshot, in
+// playlist order, and the IDs forming the new snapshot. A subscription without
+// a snapshot (never checked) is only seeded.
+func diffPlaylistTracks(sub *botpkg.PlaylistSubscription, tracks []platform.Track) ([]platform.Track, []string) {
+	current := playlistTrackIDs(tracks)
+	if sub.LastCheckedAt == nil {
+		return nil, current
+	}
+	known := make(map[string]struct{}, len(sub.TrackIDs))
+	for _, id := range sub.TrackIDs {
+		known[id] = struct{}{}
+	}
+	var added []platform.Track
+	for idx, track := range tracks {
+		if _, ok := known[current[idx]]; !ok {
+			added = append(added, track)
+		}
+	}
+	return added, current
+}
+
+func (w *PlaylistSyncWatcher) postAdditions(ctx context.Context, b *telego.Bot, sub *botpkg.PlaylistSubscription, added []platform.Track) *telego.Message {
+	lctx := i18n.WithLocalizer(ctx, i18n.For(i18n.Resolve(sub.Language, "")))
+	params := &telego.SendMessageParams{
+		ChatID:             telego.ChatID{ID: sub.ChatID},
+		Text:               formatPlaylistAdditions(lctx, w.PlatformManager, sub, added),
+		LinkPreviewOptions: &telego.LinkPreviewOptions{IsDisabled: true},
+	}
+	if keyboard := playlistAdditionsKeyboard(lctx, sub, added); keyboard != nil {
+		params.ReplyMarkup = keyboard
+	}
+	var msg *telego.Message
+	var err error
+	if w.RateLimiter != nil {
+		msg, err = telegram.SendMessageWithRetry(ctx, w.RateLimiter, b, params)
+	} else {
+		msg, err = b.SendMessage(ctx, params)
+	}
+	if err != nil {
+		if w.Logger != nil {
+			w.Logger.Warn("failed to post playlist additions", "chatID", sub.ChatID, "playlistID", sub.PlaylistID, "error", err)
+		}
+		return nil
+	}
+	return msg
+}
+
+func formatPlaylistAdditions(ctx context.Context, manager platform.Manager, sub *botpkg.PlaylistSubscription, added []platform.Track) string {
+	lines := []string{
+		fmt.Sprintf("%s %s", platformEmoji(manager, sub.Platform), tr(ctx, "psub_added_title", map[string]any{"Title": sub.Title, "Count": len(added)})),
+		"",
+	}
+	for idx, track := range added {
+		if idx >= playlistSyncMaxListed {
+			lines = append(lines, tr(ctx, "psub_added_more", map[string]any{"Count": len(added) - playlistSyncMaxListed}))
+			break
+		}
+		line := fmt.Sprintf("%d. %s", idx+1, truncateText(track.Title, 48))
+		if artists := inlineArtistsLabel(track.Artists); artists != "" {
+			line += " - " + truncateText(artists, 32)
+		}
+		lines = append(lines, line)
+	}
+	if url := strings.TrimSpace(sub.URL); url != "" {
+		lines = append(lines, "", url)
+	}
+	return truncateText(strings.Join(lines, "\n"), 4000)
+}
+
+// playlistAdditionsKeyboard offers one download button per listed track. As
+// with release announcements, IDs that do not fit plain callback data get no
+// button.
+func playlistAdditionsKeyboard(ctx context.Context, sub *botpkg.PlaylistSubscription, added []platform.Track) *telego.InlineKeyboardMarkup {
+	buttons := make([]telego.InlineKeyboardButton, 0, playlistSyncMaxListed)
+	for idx, track := range added {
+		if idx >= playlistSyncMaxListed {
+			break
+		}
+		trackID := strings.TrimSpace(track.ID)
+		if !isInlineStartToken(trackID) {
+			continue
+		}
+		data := fmt.Sprintf("psub d %s %s", sub.Platform, trackID)
+		if len(data) > 64 {
+			continue
+		}
+		buttons = append(buttons, telego.InlineKeyboardButton{Text: fmt.Sprintf("%d", idx+1), CallbackData: data})
+	}
+	rows := chunkButtons(buttons, artistWatchButtonsPerRow)
+	if url := strings.TrimSpace(sub.URL); url != "" {
+		rows = append(rows, []telego.InlineKeyboardButton{{Text: tr(ctx, "psub_open"), URL: url}})
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+	return &telego.InlineKeyboardMarkup{InlineKeyboard: rows}
+}
+
+// enqueueAutoDownload hands job to the auto-download worker, starting it on
+// first use. It never blocks: with the worker backlog full the additions stay
+// announced with their download buttons only.
+func (w *PlaylistSyncWatcher) enqueueAutoDownload(ctx context.Context, b *telego.Bot, job playlistAutoDownload) {
+	if w.Music == nil {
+		return
+	}
+	w.autoOnce.Do(func() {
+		w.autoQueue = make(chan playlistAutoDownload, playlistSyncAutoQueueSize)
+		go w.runAutoDownloads(ctx, b)
+	})
+	select {
+	case w.autoQueue <- job:
+	default:
+		if w.Logger != nil {
+			w.Logger.Warn("playlist auto-download backlog full, skipping", "chatID", job.sub.ChatID, "playlistID", job.sub.PlaylistID)
+		}
+	}
+}
+
+func (w *PlaylistSyncWatcher) runAutoDownloads(ctx context.Context, b *telego.Bot) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case job := <-w.autoQueue:
+			w.autoDownload(ctx, b, job.sub, job.posted, job.added)
+		}
+	}
+}
+
+// autoDownload queues the listed additions as replies to the additions post.
+// The request carries no user in groups and channels, so the chat's settings
+// pick the quality and only the per-chat queue limit applies; in a private
+// chat the subscriber is the requester. A full queue is waited out for a
+// while before the remaining tracks are given up on.
+func (w *PlaylistSyncWatcher) autoDownload(ctx context.Context, b *telego.Bot, sub *botpkg.PlaylistSubscription, posted *telego.Message, added []platform.Track) {
+	if w.Music == nil {
+		return
+	}
+	request := *posted
+	request.From = nil
+	if posted.Chat.Type == telego.ChatTypePrivate {
+		request.From = &telego.User{ID: sub.ChatID}
+	}
+	dispatchCtx := withSuppressDownloadRejectedMessage(ctx)
+	for idx, track := range added {
+		if idx >= playlistSyncMaxListed {
+			return
+		}
+		trackID := strings.TrimSpace(track.ID)
+		for attempt := 0; ; attempt++ {
+			if w.Music.dispatch(dispatchCtx, b, &request, sub.Platform, trackID, "") {
+				break
+			}
+			if attempt+1 >= playlistSyncAdmitAttempts {
+				if w.Logger != nil {
+					w.Logger.Warn("playlist auto-download gave up on a full queue", "chatID", sub.ChatID, "playlistID", sub.PlaylistID, "remaining", len(added)-idx)
+				}
+				return
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(playlistSyncAdmitRetry):
+			}
+		}
+	}
+}
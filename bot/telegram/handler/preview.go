@@ -0,0 +1,309 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	botpkg "github.com/liuran001/MusicBot-Go/bot"
+	"github.com/liuran001/MusicBot-Go/bot/download"
+	"github.com/liuran001/MusicBot-Go/bot/platform"
+	"github.com/liuran001/MusicBot-Go/bot/preview"
+	"github.com/liuran001/MusicBot-Go/bot/telegram"
+	"github.com/mymmrac/telego"
+)
+
+const (
+	// previewTimeout bounds fetching, analysing and uploading one preview.
+	previewTimeout = 2 * time.Minute
+	// previewWaveformWidth is the number of bars in the caption waveform.
+	previewWaveformWidth = 24
+	// previewRangeSlack pads byte ranges estimated from the average bitrate,
+	// which undershoots on louder, denser passages of VBR streams.
+	previewRangeSlack = 1.1
+)
+
+// PreviewHandler sends short voice-note previews of tracks from the ▶ buttons
+// under search and playlist results, so a chat can listen before pulling the
+// full file. Clips are cut from the standard-quality stream, fetched with Range
+// requests where the source allows, and cached per track by file ID.
+type PreviewHandler struct {
+	PlatformManager platform.Manager
+	DownloadService *download.DownloadService
+	Store           botpkg.PreviewStore
+	RateLimiter     *telegram.RateLimiter
+	ResourceLimiter *ResourceRateLimiter
+	Logger          botpkg.Logger
+	CacheDir        string
+	// ClipLength is the preview length. Offset, when positive, pins every
+	// clip to that point of the track instead of searching for the chorus.
+	ClipLength time.Duration
+	Offset     time.Duration
+}
+
+// buildPreviewCallbackData returns "pv <platform> <trackID>", or a stored
+// token for IDs too long for the 64-byte callback data limit.
+func buildPreviewCallbackData(platformName, trackID string, requesterID int64) string {
+	data := fmt.Sprintf("pv %s %s", platformName, trackID)
+	if len(data) <= 64 && !strings.ContainsAny(trackID, " \n") {
+		return data
+	}
+	if token := storeInlineCallbackPayload(inlineCallbackPayload{platformName: platformName, trackID: trackID, requesterID: requesterID, action: "preview"}); token != "" {
+		return "pv t " + token
+	}
+	return ""
+}
+
+// button returns the ▶ button previewing the result at the given list index.
+// A nil handler (previews disabled) never yields one.
+func (h *PreviewHandler) button(ctx context.Context, platformName, trackID string, requesterID int64, index int) (telego.InlineKeyboardButton, bool) {
+	if h == nil || strings.TrimSpace(platformName) == "" || strings.TrimSpace(trackID) == "" {
+		return telego.InlineKeyboardButton{}, false
+	}
+	data := buildPreviewCallbackData(platformName, trackID, requesterID)
+	if data == "" {
+		return telego.InlineKeyboardButton{}, false
+	}
+	return telego.InlineKeyboardButton{Text: tr(ctx, "preview_button", map[string]any{"Index": index}), CallbackData: data}, true
+}
+
+// Handle serves "pv ..." callbacks. Anyone in the chat may preview; the clip
+// replies to the result list it came from.
+func (h *PreviewHandler) Handle(ctx context.Context, b *telego.Bot, update *telego.Update) {
+	if h == nil || update == nil || update.CallbackQuery == nil {
+		return
+	}
+	query := update.CallbackQuery
+	parts := strings.Fields(query.Data)
+	if len(parts) != 3 || parts[0] != "pv" {
+		return
+	}
+	platformName, trackID := parts[1], parts[2]
+	if platformName == "t" {
+		payload, ok := getInlineCallbackPayload(trackID)
+		if !ok || payload.action != "preview" {
+			_ = b.AnswerCallbackQuery(ctx, &telego.AnswerCallbackQueryParams{CallbackQueryID: query.ID, Text: tr(ctx, "cb_op_expired"), ShowAlert: true})
+			return
+		}
+		platformName, trackID = payload.platformName, payload.trackID
+	}
+	if query.Message == nil {
+		return
+	}
+	msg := query.Message.Message()
+	if msg == nil {
+		return
+	}
+
+	release, ok := tryAcquireCallbackInFlight(fmt.Sprintf("preview:%d:%s:%s", msg.Chat.ID, platformName, trackID), previewTimeout)
+	if !ok {
+		_ = b.AnswerCallbackQuery(ctx, &telego.AnswerCallbackQueryParams{CallbackQueryID: query.ID, Text: tr(ctx, "cb_processing")})
+		return
+	}
+	defer release()
+
+	ctx, cancel := context.WithTimeout(ctx, previewTimeout)
+	defer cancel()
+
+	if clip := h.cachedPreview(ctx, platformName, trackID); clip != nil {
+		_ = b.AnswerCallbackQuery(ctx, &telego.AnswerCallbackQueryParams{CallbackQueryID: query.ID})
+		if _, err := h.sendVoice(ctx, b, msg, clip, telego.InputFile{FileID: clip.FileID}); err == nil {
+			return
+		} else if !isTelegramFileIDInvalid(err) {
+			h.logWarn("failed to send cached preview", platformName, trackID, err)
+			return
+		}
+		if err := h.Store.DeletePreview(ctx, platformName, trackID); err != nil {
+			h.logWarn("failed to drop stale preview", platformName, trackID, err)
+		}
+	} else {
+		// Only new clips are metered; resending a cached one is free, like
+		// cached downloads.
+		if !h.ResourceLimiter.AllowFor(ActionPreview, query.From.ID, msg.Chat.ID, platformName) {
+			_ = b.AnswerCallbackQuery(ctx, &telego.AnswerCallbackQueryParams{CallbackQueryID: query.ID, Text: tr(ctx, "err_rate_limited"), ShowAlert: true})
+			return
+		}
+		_ = b.AnswerCallbackQuery(ctx, &telego.AnswerCallbackQueryParams{CallbackQueryID: query.ID, Text: tr(ctx, "preview_preparing")})
+	}
+
+	clip, clipPath, cleanup, err := h.prepare(ctx, platformName, trackID)
+	defer func() {
+		if cleanupErr := cleanupFiles(cleanup...); cleanupErr != nil {
+			h.logWarn("failed to clean preview files", platformName, trackID, cleanupErr)
+		}
+	}()
+	if err != nil {
+		h.logWarn("failed to prepare preview", platformName, trackID, err)
+		sendText(ctx, b, msg.Chat.ID, msg.MessageID, tr(ctx, "preview_failed"))
+		return
+	}
+	file, err := os.Open(clipPath)
+	if err != nil {
+		h.logWarn("failed to open preview", platformName, trackID, err)
+		return
+	}
+	defer file.Close()
+	sent, err := h.sendVoice(ctx, b, msg, clip, telego.InputFile{File: file})
+	if err != nil {
+		h.logWarn("failed to send preview", platformName, trackID, err)
+		return
+	}
+	if sent == nil || sent.Voice == nil || h.Store == nil {
+		return
+	}
+	clip.FileID = sent.Voice.FileID
+	if err := h.Store.SavePreview(ctx, clip); err != nil {
+		h.logWarn("failed to cache preview", platformName, trackID, err)
+	}
+}
+
+func (h *PreviewHandler) cachedPreview(ctx context.Context, platformName, trackID string) *botpkg.PreviewClip {
+	if h.Store == nil {
+		return nil
+	}
+	clip, err := h.Store.FindPreview(ctx, platformName, trackID)
+	if err != nil || clip == nil || strings.TrimSpace(clip.FileID) == "" {
+		return nil
+	}
+	return clip
+}
+
+func (h *PreviewHandler) sendVoice(ctx context.Context, b *telego.Bot, msg *telego.Message, clip *botpkg.PreviewClip, voice telego.InputFile) (*telego.Message, error) {
+	params := &telego.SendVoiceParams{
+		ChatID:          telego.ChatID{ID: msg.Chat.ID},
+		MessageThreadID: msg.MessageThreadID,
+		Voice:           voice,
+		Caption:         previewCaption(ctx, clip),
+		Duration:        int(clip.Duration.Round(time.Second) / time.Second),
+		ReplyParameters: &telego.ReplyParameters{MessageID: msg.MessageID, AllowSendingWithoutReply: true},
+	}
+	if h.RateLimiter != nil {
+		return telegram.SendVoiceWithRetry(ctx, h.RateLimiter, b, params)
+	}
+	return b.SendVoice(ctx, params)
+}
+
+func previewCaption(ctx context.Context, clip *botpkg.PreviewClip) string {
+	return tr(ctx, "preview_caption", map[string]any{
+		"Title":    clip.Title,
+		"From":     previewClock(clip.Start),
+		"To":       previewClock(clip.Start + clip.Duration),
+		"Waveform": clip.Waveform,
+	})
+}
+
+func previewClock(d time.Duration) string {
+	seconds := int(max(d, 0).Round(time.Second) / time.Second)
+	return fmt.Sprintf("%d:%02d", seconds/60, seconds%60)
+}
+
+// prepare cuts the preview clip of a track into a voice note. It returns the
+// clip (without a file ID yet), the encoded file, and every file to remove.
+func (h *PreviewHandler) prepare(ctx context.Context, platformName, trackID string) (*botpkg.PreviewClip, string, []string, error) {
+	if h.PlatformManager == nil || h.DownloadService == nil {
+		return nil, "", nil, errors.New("preview not configured")
+	}
+	plat := h.PlatformManager.Get(platformName)
+	if plat == nil {
+		return nil, "", nil, platform.ErrUnsupported
+	}
+	track, err := plat.GetTrack(ctx, trackID)
+	if err != nil {
+		return nil, "", nil, err
+	}
+	info, err := plat.GetDownloadInfo(ctx, trackID, platform.QualityStandard)
+	if err != nil {
+		return nil, "", nil, err
+	}
+	if info == nil || info.URL == "" {
+		return nil, "", nil, errors.New("download info unavailable")
+	}
+
+	clipLength := h.ClipLength
+	if clipLength <= 0 {
+		clipLength = preview.DefaultClipLength
+	}
+	regionStart, regionEnd := preview.Region(track.Duration, clipLength, h.Offset)
+	ensureDir(h.CacheDir)
+	stamp := time.Now().UnixNano()
+	format := strings.TrimSpace(info.Format)
+	if format == "" {
+		format = "mp3"
+	}
+	srcPath := filepath.Join(h.CacheDir, fmt.Sprintf("preview-%d.%s", stamp, format))
+	clipPath := filepath.Join(h.CacheDir, fmt.Sprintf("preview-%d.ogg", stamp))
+	cleanup := []string{srcPath, clipPath}
+
+	seek, err := h.fetchRegion(download.WithPlatform(ctx, platformName), info, format, track.Duration, regionStart, regionEnd, srcPath)
+	if err != nil {
+		return nil, "", cleanup, err
+	}
+	env, err := preview.Analyze(ctx, srcPath, seek, regionEnd-regionStart)
+	if err != nil {
+		return nil, "", cleanup, err
+	}
+	blocks := int(clipLength / preview.BlockDuration)
+	offset := 0
+	if h.Offset <= 0 {
+		offset = preview.LoudestWindow(env, blocks)
+	}
+	skip := time.Duration(offset) * preview.BlockDuration
+	length := min(clipLength, regionEnd-regionStart-skip)
+	if err := preview.Encode(ctx, srcPath, clipPath, seek+skip, length); err != nil {
+		return nil, "", cleanup, err
+	}
+
+	title := strings.TrimSpace(track.Title)
+	if artists := inlineArtistsLabel(track.Artists); artists != "" {
+		title += " - " + artists
+	}
+	return &botpkg.PreviewClip{
+		Platform: platformName,
+		TrackID:  trackID,
+		Title:    title,
+		Start:    regionStart + skip,
+		Duration: length,
+		Waveform: preview.Waveform(env[offset:min(offset+blocks, len(env))], previewWaveformWidth),
+	}, clipPath, cleanup, nil
+}
+
+// fetchRegion stores enough of the stream to cover [start, end) at destPath
+// and returns where that region begins within the stored file. MP3 and ADTS
+// frames decode from anywhere, so only the region itself is fetched; other
+// containers need their header, so the file is fetched from the start. Sources
+// without partial fetches, or without a size or bitrate to map time to bytes,
+// are downloaded whole.
+func (h *PreviewHandler) fetchRegion(ctx context.Context, info *platform.DownloadInfo, format string, duration, start, end time.Duration, destPath string) (time.Duration, error) {
+	var bytesPerSecond float64
+	switch {
+	case info.Size > 0 && duration > 0:
+		bytesPerSecond = float64(info.Size) / duration.Seconds()
+	case info.Bitrate > 0:
+		bytesPerSecond = float64(info.Bitrate) * 1000 / 8
+	}
+	if bytesPerSecond > 0 {
+		offset, seek := int64(0), start
+		if format == "mp3" || format == "aac" {
+			offset, seek = int64(start.Seconds()*bytesPerSecond), 0
+		}
+		length := int64((end.Seconds()*bytesPerSecond)*previewRangeSlack) - offset
+		_, err := h.DownloadService.DownloadRange(ctx, info, destPath, offset, length)
+		if !errors.Is(err, download.ErrRangeUnsupported) {
+			return seek, err
+		}
+	}
+	if _, err := h.DownloadService.Download(ctx, info, destPath, nil); err != nil {
+		return 0, err
+	}
+	return start, nil
+}
+
+func (h *PreviewHandler) logWarn(msg, platformName, trackID string, err error) {
+	if h.Logger != nil && err != nil {
+		h.Logger.Warn(msg, "platform", platformName, "trackID", trackID, "error", err)
+	}
+}
@@ -2,15 +2,24 @@ package handler
 
 import (
 	"context"
+	"fmt"
+	"strings"
 
+	"github.com/liuran001/MusicBot-Go/bot/config"
 	logpkg "github.com/liuran001/MusicBot-Go/bot/logger"
 	"github.com/liuran001/MusicBot-Go/bot/telegram"
 	"github.com/mymmrac/telego"
 )
 
+// reloadMaxChangeLines caps the diff listing so the reply stays well under
+// Telegram's message length limit.
+const reloadMaxChangeLines = 30
+
 // ReloadHandler handles /reload command for runtime config/plugins reload.
+// "/reload check" validates the file and shows the diff without applying it.
 type ReloadHandler struct {
-	Reload      func(ctx context.Context) error
+	Reload      func(ctx context.Context) ([]config.Change, error)
+	Preview     func(ctx context.Context) ([]config.Change, error)
 	RateLimiter *telegram.RateLimiter
 	Logger      *logpkg.Logger
 	AdminIDs    *AdminSet
@@ -26,38 +35,40 @@ func (h *ReloadHandler) Handle(ctx context.Context, b *telego.Bot, update *teleg
 		return
 	}
 
-	if h.Reload == nil {
-		params := &telego.SendMessageParams{
-			ChatID: telego.ChatID{ID: message.Chat.ID},
-			Text:   tr(ctx, "adm_reload_disabled"),
-		}
-		if h.RateLimiter != nil {
-			_, _ = telegram.SendMessageWithRetry(ctx, h.RateLimiter, b, params)
-		} else {
-			_, _ = b.SendMessage(ctx, params)
-		}
+	preview := false
+	switch strings.ToLower(commandArguments(message.Text)) {
+	case "check", "dry", "diff":
+		preview = true
+	}
+	run := h.Reload
+	if preview {
+		run = h.Preview
+	}
+	if run == nil {
+		h.reply(ctx, b, message.Chat.ID, tr(ctx, "adm_reload_disabled"))
 		return
 	}
 
-	if err := h.Reload(ctx); err != nil {
+	changes, err := run(ctx)
+	if err != nil {
 		if h.Logger != nil {
-			h.Logger.Error("reload failed", "error", err)
-		}
-		params := &telego.SendMessageParams{
-			ChatID: telego.ChatID{ID: message.Chat.ID},
-			Text:   tr(ctx, "adm_reload_failed", map[string]any{"Err": err.Error()}),
-		}
-		if h.RateLimiter != nil {
-			_, _ = telegram.SendMessageWithRetry(ctx, h.RateLimiter, b, params)
-		} else {
-			_, _ = b.SendMessage(ctx, params)
+			h.Logger.Error("reload failed", "preview", preview, "error", err)
 		}
+		h.reply(ctx, b, message.Chat.ID, tr(ctx, "adm_reload_failed", map[string]any{"Err": err.Error()}))
 		return
 	}
 
+	header := tr(ctx, "adm_reload_done")
+	if preview {
+		header = tr(ctx, "adm_reload_check_ok")
+	}
+	h.reply(ctx, b, message.Chat.ID, header+"\n\n"+renderConfigChanges(ctx, changes, preview))
+}
+
+func (h *ReloadHandler) reply(ctx context.Context, b *telego.Bot, chatID int64, text string) {
 	params := &telego.SendMessageParams{
-		ChatID: telego.ChatID{ID: message.Chat.ID},
-		Text:   tr(ctx, "adm_reload_done"),
+		ChatID: telego.ChatID{ID: chatID},
+		Text:   text,
 	}
 	if h.RateLimiter != nil {
 		_, _ = telegram.SendMessageWithRetry(ctx, h.RateLimiter, b, params)
@@ -65,3 +76,48 @@ func (h *ReloadHandler) Handle(ctx context.Context, b *telego.Bot, update *teleg
 		_, _ = b.SendMessage(ctx, params)
 	}
 }
+
+// renderConfigChanges lists changed keys, marking those that need a restart.
+func renderConfigChanges(ctx context.Context, changes []config.Change, preview bool) string {
+	if len(changes) == 0 {
+		return tr(ctx, "adm_reload_no_changes")
+	}
+	lines := make([]string, 0, len(changes)+3)
+	lines = append(lines, tr(ctx, "adm_reload_changes", map[string]any{"Count": len(changes)}))
+	restart := make([]string, 0)
+	for i, change := range changes {
+		if change.Restart {
+			restart = append(restart, change.Key)
+		}
+		if i >= reloadMaxChangeLines {
+			continue
+		}
+		marker := ""
+		if change.Restart {
+			marker = " ⚠️"
+		}
+		if change.Secret {
+			lines = append(lines, fmt.Sprintf("• %s: %s%s", change.Key, tr(ctx, "adm_reload_secret"), marker))
+			continue
+		}
+		lines = append(lines, fmt.Sprintf("• %s: %s → %s%s", change.Key, reloadValue(ctx, change.Old), reloadValue(ctx, change.New), marker))
+	}
+	if len(changes) > reloadMaxChangeLines {
+		lines = append(lines, tr(ctx, "adm_reload_more", map[string]any{"Count": len(changes) - reloadMaxChangeLines}))
+	}
+	if len(restart) > 0 {
+		key := "adm_reload_restart"
+		if preview {
+			key = "adm_reload_check_restart"
+		}
+		lines = append(lines, "", tr(ctx, key, map[string]any{"Keys": strings.Join(restart, ", ")}))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func reloadValue(ctx context.Context, value string) string {
+	if value == "" {
+		return tr(ctx, "adm_reload_unset")
+	}
+	return value
+}
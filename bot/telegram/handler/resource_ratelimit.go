@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"context"
 	"sync"
 	"time"
 )
@@ -16,6 +17,20 @@ const (
 	ActionPlaylist  = "playlist"
 	ActionEpisode   = "episode"
 	ActionArtist    = "artist"
+	// ActionTransfer admits a whole /transfer job, which runs one search per
+	// playlist track on the target platform.
+	ActionTransfer = "transfer"
+	// ActionTransferSearch meters the searches of /transfer jobs, apart from
+	// the interactive ActionSearch quota so a transfer never starves /search.
+	ActionTransferSearch = "transfer_search"
+	// ActionPreview admits one new preview clip: a partial fetch plus an
+	// ffmpeg encode, far cheaper than ActionDownload.
+	ActionPreview = "preview"
+	// ActionArtistWatch is not user-initiated: it meters the background
+	// artist-release poller, which only has a platform (and global) dimension.
+	ActionArtistWatch = "artist_watch"
+	// ActionPlaylistSync meters the background playlist sync poller the same way.
+	ActionPlaylistSync = "playlist_sync"
 )
 
 // ResourceLimit defines per-window quotas for one action across four
@@ -57,15 +72,8 @@ type ResourceRateLimiter struct {
 // NewResourceRateLimiter builds a limiter with the given per-action rules. A
 // nil/empty rule map makes every action unlimited.
 func NewResourceRateLimiter(rules map[string]ResourceLimit) *ResourceRateLimiter {
-	copied := make(map[string]ResourceLimit, len(rules))
-	for action, rule := range rules {
-		if rule.Window <= 0 {
-			rule.Window = time.Minute
-		}
-		copied[action] = rule
-	}
 	return &ResourceRateLimiter{
-		rules:    copied,
+		rules:    normalizeResourceRules(rules),
 		users:    make(map[string][]time.Time),
 		chats:    make(map[string][]time.Time),
 		plats:    make(map[string][]time.Time),
@@ -75,6 +83,30 @@ func NewResourceRateLimiter(rules map[string]ResourceLimit) *ResourceRateLimiter
 	}
 }
 
+// SetRules replaces the per-action rules, e.g. after a config reload. Recorded
+// timestamps are kept, so a shorter window or lower quota applies to work
+// already admitted in the current window.
+func (l *ResourceRateLimiter) SetRules(rules map[string]ResourceLimit) {
+	if l == nil {
+		return
+	}
+	normalized := normalizeResourceRules(rules)
+	l.mu.Lock()
+	l.rules = normalized
+	l.mu.Unlock()
+}
+
+func normalizeResourceRules(rules map[string]ResourceLimit) map[string]ResourceLimit {
+	copied := make(map[string]ResourceLimit, len(rules))
+	for action, rule := range rules {
+		if rule.Window <= 0 {
+			rule.Window = time.Minute
+		}
+		copied[action] = rule
+	}
+	return copied
+}
+
 // pruneTimes drops timestamps older than the cutoff and reports how many
 // remain, reusing the backing array.
 func pruneTimes(times []time.Time, cutoff time.Time) []time.Time {
@@ -193,6 +225,47 @@ func (l *ResourceRateLimiter) AllowFor(action string, userID, chatID int64, plat
 	return true
 }
 
+// WaitFor blocks until AllowFor admits the action or ctx is done, checking
+// every poll. Background jobs use it to pace their platform calls against the
+// quotas that would reject the same calls from a user command.
+func (l *ResourceRateLimiter) WaitFor(ctx context.Context, action string, userID, chatID int64, platformName string, poll time.Duration) error {
+	if poll <= 0 {
+		poll = time.Second
+	}
+	for !l.AllowFor(action, userID, chatID, platformName) {
+		timer := time.NewTimer(poll)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+	return nil
+}
+
+// Capacity returns how many times action can be admitted for platformName over
+// d by its per-platform and global quotas alone, or -1 when neither applies.
+func (l *ResourceRateLimiter) Capacity(action, platformName string, d time.Duration) int {
+	if l == nil {
+		return -1
+	}
+	l.mu.Lock()
+	rule, ok := l.rules[action]
+	l.mu.Unlock()
+	if !ok {
+		return -1
+	}
+	quota := rule.Global
+	if rule.PerPlatform > 0 && platformName != "" && (quota <= 0 || rule.PerPlatform < quota) {
+		quota = rule.PerPlatform
+	}
+	if quota <= 0 {
+		return -1
+	}
+	return quota * max(int(d/rule.Window), 1)
+}
+
 func actionUserKey(action string, userID int64) string {
 	return action + "\x00u\x00" + itoa(userID)
 }
@@ -1,6 +1,8 @@
 package handler
 
 import (
+	"context"
+	"errors"
 	"testing"
 	"time"
 )
@@ -135,6 +137,28 @@ func TestResourceRateLimiterWindowExpiry(t *testing.T) {
 	}
 }
 
+func TestResourceRateLimiterWaitFor(t *testing.T) {
+	l := NewResourceRateLimiter(searchRule(50*time.Millisecond, 0, 1, 0))
+	if err := l.WaitFor(context.Background(), ActionSearch, 0, 0, "netease", 10*time.Millisecond); err != nil {
+		t.Fatalf("first wait: %v", err)
+	}
+	start := time.Now()
+	if err := l.WaitFor(context.Background(), ActionSearch, 0, 0, "netease", 10*time.Millisecond); err != nil {
+		t.Fatalf("second wait: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 40*time.Millisecond {
+		t.Fatalf("second wait returned after %s, want the window to pass", elapsed)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	l = NewResourceRateLimiter(searchRule(time.Hour, 0, 1, 0))
+	l.Allow(ActionSearch, 0, "netease")
+	if err := l.WaitFor(ctx, ActionSearch, 0, 0, "netease", 5*time.Millisecond); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("WaitFor on a spent quota = %v, want deadline exceeded", err)
+	}
+}
+
 func TestResourceRateLimiterNilSafe(t *testing.T) {
 	var l *ResourceRateLimiter
 	if !l.Allow(ActionSearch, 1, "netease") {
@@ -182,3 +206,24 @@ func TestResourceRateLimiterActionsIndependent(t *testing.T) {
 		t.Fatal("first lyric should be allowed despite search being exhausted")
 	}
 }
+
+func TestResourceRateLimiterSetRules(t *testing.T) {
+	l := NewResourceRateLimiter(searchRule(time.Minute, 2, 0, 0))
+	if !l.Allow(ActionSearch, 1, "netease") || !l.Allow(ActionSearch, 1, "netease") {
+		t.Fatal("first two requests should be allowed")
+	}
+	if l.Allow(ActionSearch, 1, "netease") {
+		t.Fatal("third request should hit the per-user limit")
+	}
+	l.SetRules(searchRule(time.Minute, 3, 0, 0))
+	if !l.Allow(ActionSearch, 1, "netease") {
+		t.Fatal("raised limit should admit one more request")
+	}
+	if l.Allow(ActionSearch, 1, "netease") {
+		t.Fatal("earlier requests must still count after SetRules")
+	}
+	l.SetRules(nil)
+	if !l.Allow(ActionSearch, 1, "netease") {
+		t.Fatal("clearing the rules should make the action unlimited")
+	}
+}
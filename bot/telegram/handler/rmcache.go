@@ -109,7 +109,7 @@ func (h *RmCacheHandler) Handle(ctx context.Context, b *telego.Bot, update *tele
 
 				resolvedTarget := resolveShortLinkText(ctx, h.PlatformManager, target)
 				trackID := strings.TrimSpace(resolvedTarget)
-				if matcher, ok := plat.(platform.URLMatcher); ok {
+				if matcher, ok := platform.As[platform.URLMatcher](plat); ok {
 					if matchedTrackID, matched := matcher.MatchURL(resolvedTarget); matched {
 						trackID = strings.TrimSpace(matchedTrackID)
 					}
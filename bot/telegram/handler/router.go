@@ -16,8 +16,13 @@ type Router struct {
 	Music                    MessageHandler
 	Playlist                 MessageHandler
 	Artist                   MessageHandler
+	Charts                   MessageHandler
+	ArtistWatch              MessageHandler
+	PlaylistSync             MessageHandler
+	Transfer                 MessageHandler
 	Search                   MessageHandler
 	Lyric                    MessageHandler
+	Cover                    MessageHandler
 	Recognize                MessageHandler
 	About                    MessageHandler
 	Status                   MessageHandler
@@ -36,10 +41,15 @@ type Router struct {
 	SettingsCallback         CallbackHandler
 	SearchCallback           CallbackHandler
 	PlaylistCallback         CallbackHandler
+	ChartCallback            CallbackHandler
+	ArtistWatchCallback      CallbackHandler
+	PlaylistSyncCallback     CallbackHandler
+	TransferCallback         CallbackHandler
 	InlineCollectionCallback CallbackHandler
 	LyricCallback            CallbackHandler
 	FavoriteCallback         CallbackHandler
 	DownloadQueueCallback    CallbackHandler
+	PreviewCallback          CallbackHandler
 	Inline                   InlineHandler
 	ChosenInline             ChosenInlineHandler
 	Pool                     botpkg.WorkerPool
@@ -81,6 +91,9 @@ func (r *Router) Register(bh *th.BotHandler, botName string) {
 	bh.Handle(r.wrapMessage(r.Music), matchCommandFunc(botName, "program"))
 	bh.Handle(r.wrapMessage(r.Search), matchCommandFunc(botName, "search"))
 	bh.Handle(r.wrapMessage(r.Lyric), matchCommandFunc(botName, "lyric"))
+	if r.Cover != nil {
+		bh.Handle(r.wrapMessage(r.Cover), matchCommandFunc(botName, "cover"))
+	}
 	if r.Recognize != nil {
 		bh.Handle(r.wrapMessage(r.Recognize), matchCommandFunc(botName, "recognize"))
 	}
@@ -89,6 +102,18 @@ func (r *Router) Register(bh *th.BotHandler, botName string) {
 	bh.Handle(r.wrapMessage(r.Queue), matchCommandFunc(botName, "queue"))
 	bh.Handle(r.wrapMessage(r.Cancel), matchCommandFunc(botName, "cancel"))
 	bh.Handle(r.wrapMessage(r.Settings), matchCommandFunc(botName, "settings"))
+	if r.Charts != nil {
+		bh.Handle(r.wrapMessage(r.Charts), matchCommandFunc(botName, "charts"))
+	}
+	if r.ArtistWatch != nil {
+		bh.Handle(r.wrapMessage(r.ArtistWatch), matchCommandFunc(botName, "watch"))
+	}
+	if r.PlaylistSync != nil {
+		bh.Handle(r.wrapMessage(r.PlaylistSync), matchCommandFunc(botName, "subscribe"))
+	}
+	if r.Transfer != nil {
+		bh.Handle(r.wrapMessage(r.Transfer), matchCommandFunc(botName, "transfer"))
+	}
 	if r.Favorites != nil {
 		bh.Handle(r.wrapMessage(r.Favorites), matchCommandFunc(botName, "fav"))
 		bh.Handle(r.wrapMessage(r.Favorites), matchCommandFunc(botName, "favorites"))
@@ -275,6 +300,18 @@ func (r *Router) Register(bh *th.BotHandler, botName string) {
 	bh.Handle(r.wrapCallback(r.SearchCallback), callbackPrefix("search"))
 	bh.Handle(r.wrapCallback(r.PlaylistCallback), callbackPrefix("playlist"))
 	bh.Handle(r.wrapCallback(r.InlineCollectionCallback), callbackPrefix("ipl"))
+	if r.ChartCallback != nil {
+		bh.Handle(r.wrapCallback(r.ChartCallback), callbackPrefix("chart "))
+	}
+	if r.ArtistWatchCallback != nil {
+		bh.Handle(r.wrapCallback(r.ArtistWatchCallback), callbackPrefix("artw "))
+	}
+	if r.PlaylistSyncCallback != nil {
+		bh.Handle(r.wrapCallback(r.PlaylistSyncCallback), callbackPrefix("psub "))
+	}
+	if r.TransferCallback != nil {
+		bh.Handle(r.wrapCallback(r.TransferCallback), callbackPrefix("trf "))
+	}
 	if r.LyricCallback != nil {
 		bh.Handle(r.wrapCallback(r.LyricCallback), callbackPrefix("lyric "))
 	}
@@ -293,6 +330,9 @@ func (r *Router) Register(bh *th.BotHandler, botName string) {
 	if r.DownloadQueueCallback != nil {
 		bh.Handle(r.wrapCallback(r.DownloadQueueCallback), callbackPrefix("dlq "))
 	}
+	if r.PreviewCallback != nil {
+		bh.Handle(r.wrapCallback(r.PreviewCallback), callbackPrefix("pv "))
+	}
 	bh.Handle(r.wrapInline(r.Inline), func(ctx context.Context, update telego.Update) bool {
 		return update.InlineQuery != nil
 	})
@@ -23,6 +23,7 @@ type SearchHandler struct {
 	DefaultPlatform  string
 	FallbackPlatform string
 	PageSize         int
+	Preview          *PreviewHandler
 	searchMu         sync.Mutex
 	searchCache      map[int]*searchState
 }
@@ -58,6 +59,11 @@ type searchState struct {
 	playlist *platform.Playlist
 	// collectionLabel is the localized label ("歌单"/"专辑") for playlist mode.
 	collectionLabel string
+	// aggregated holds the merged results when platform is
+	// aggregateSearchPlatform; aggregateSkipped lists platforms that were rate
+	// limited or failed during the fan-out.
+	aggregated       []aggregatedResult
+	aggregateSkipped []string
 }
 
 // resultAction returns the result-button action, defaulting to "music".
@@ -211,6 +217,10 @@ func (h *SearchHandler) runSearch(ctx context.Context, b *telego.Bot, message *t
 	}
 
 	keyword, requestedPlatform, qualityOverride := parseTrailingOptions(keyword, h.PlatformManager)
+	aggregate := false
+	if requestedPlatform == "" && action == "music" {
+		keyword, aggregate = splitAggregateSearchKeyword(keyword)
+	}
 	hasPlatformSuffix := strings.TrimSpace(requestedPlatform) != ""
 	// Get user's default platform from settings
 	platformName := h.DefaultPlatform
@@ -245,6 +255,10 @@ func (h *SearchHandler) runSearch(ctx context.Context, b *telego.Bot, message *t
 		platformName = requestedPlatform
 		fallbackPlatform = ""
 	}
+	if aggregate {
+		h.runAggregatedSearchMessage(ctx, b, message, msgResult, keyword, qualityOverride)
+		return
+	}
 	primaryPlatform := platformName
 
 	biliFilter := true
@@ -512,6 +526,10 @@ func (h *SearchCallbackHandler) Handle(ctx context.Context, b *telego.Bot, updat
 	if h.Search.PlatformManager == nil {
 		return
 	}
+	if state.platform == aggregateSearchPlatform {
+		h.handleAggregatedPage(ctx, b, query, msg, state, messageID, page, action == "platform")
+		return
+	}
 	plat := h.Search.PlatformManager.Get(state.platform)
 	if plat == nil {
 		return
@@ -769,6 +787,7 @@ func (h *SearchHandler) buildSearchPage(ctx context.Context, tracks []platform.T
 		textMessage.WriteString("\n")
 	}
 	buttons := make([]telego.InlineKeyboardButton, 0, pageSize)
+	var previewRow []telego.InlineKeyboardButton
 	for i := start; i < end; i++ {
 		track := tracks[i]
 		escapedTitle := mdV2Replacer.Replace(track.Title)
@@ -798,12 +817,20 @@ func (h *SearchHandler) buildSearchPage(ctx context.Context, tracks []platform.T
 			Text:         fmt.Sprintf("%d", i-start+1),
 			CallbackData: callbackData,
 		})
+		if action == "music" {
+			if button, ok := h.Preview.button(ctx, platformName, track.ID, requesterID, i-start+1); ok {
+				previewRow = append(previewRow, button)
+			}
+		}
 	}
 
 	var rows [][]telego.InlineKeyboardButton
 	if len(buttons) > 0 {
 		rows = append(rows, buttons)
 	}
+	if len(previewRow) > 0 {
+		rows = append(rows, previewRow)
+	}
 	if pageCount > 1 || hasMore {
 		navRow := make([]telego.InlineKeyboardButton, 0, 2)
 		if page == 1 {
@@ -828,6 +855,11 @@ func (h *SearchHandler) buildSearchPage(ctx context.Context, tracks []platform.T
 	if switchRows := h.buildPlatformSwitchRows(ctx, platformName, requesterID, messageID, unavailable); len(switchRows) > 0 {
 		rows = append(rows, switchRows...)
 	}
+	if action == "music" {
+		if allRow := h.buildAggregateSearchRow(ctx, requesterID, messageID); allRow != nil {
+			rows = append(rows, allRow)
+		}
+	}
 
 	if strings.TrimSpace(filterLabel) != "" {
 		filterText := tr(ctx, "srch_filter_on")
@@ -853,13 +885,20 @@ func (h *SearchHandler) buildNoResultsPage(ctx context.Context, state *searchSta
 		return tr(ctx, "no_results"), keyboard
 	}
 	text := tr(ctx, "no_results")
-	if state.platform != "" {
+	if state.platform == aggregateSearchPlatform {
+		text = tr(ctx, "srch_no_results_platform", map[string]any{"Platform": tr(ctx, "srch_all_platforms")})
+	} else if state.platform != "" {
 		text = tr(ctx, "srch_no_results_platform", map[string]any{"Platform": platformDisplayName(ctx, h.PlatformManager, state.platform)})
 	}
 	rows := make([][]telego.InlineKeyboardButton, 0, 2)
 	if switchRows := h.buildPlatformSwitchRows(ctx, state.platform, state.requesterID, messageID, state.unavailable); len(switchRows) > 0 {
 		rows = append(rows, switchRows...)
 	}
+	if state.resultAction() == "music" && state.platform != aggregateSearchPlatform {
+		if allRow := h.buildAggregateSearchRow(ctx, state.requesterID, messageID); allRow != nil {
+			rows = append(rows, allRow)
+		}
+	}
 	rows = append(rows, []telego.InlineKeyboardButton{{Text: tr(ctx, "srch_close"), CallbackData: fmt.Sprintf("search %d close %d", messageID, state.requesterID)}})
 	return text, &telego.InlineKeyboardMarkup{InlineKeyboard: rows}
 }
@@ -0,0 +1,549 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	botpkg "github.com/liuran001/MusicBot-Go/bot"
+	"github.com/liuran001/MusicBot-Go/bot/platform"
+	"github.com/liuran001/MusicBot-Go/bot/telegram"
+	"github.com/mymmrac/telego"
+)
+
+const (
+	// aggregateSearchPlatform is the pseudo platform name used by the "all
+	// platforms" search mode, both as the trailing keyword option
+	// ("/search 晴天 all") and in search callback data.
+	aggregateSearchPlatform = "all"
+	aggregateSearchEmoji    = "🌐"
+	// aggregateSearchPerPlatformLimit bounds each fan-out request. Aggregated
+	// results are fetched once and paged from memory.
+	aggregateSearchPerPlatformLimit = 10
+	aggregateSearchTimeout          = 12 * time.Second
+	// aggregateDurationTolerance is how far two durations may drift and still be
+	// considered the same recording. Platforms round differently and some
+	// include a short silence tail.
+	aggregateDurationTolerance = 3 * time.Second
+)
+
+// aggregatedSource is one platform's copy of an aggregated search result.
+type aggregatedSource struct {
+	platform string
+	track    platform.Track
+	// rank is the zero-based position in that platform's own result list.
+	rank    int
+	quality platform.Quality
+}
+
+// aggregatedResult groups copies of the same recording found on several
+// platforms. sources is ordered best-first: the first entry is the one the
+// number button downloads.
+type aggregatedResult struct {
+	sources []aggregatedSource
+	score   float64
+}
+
+func (r aggregatedResult) best() aggregatedSource {
+	return r.sources[0]
+}
+
+// representative is the copy used for matching: the first source, carrying
+// the group's ISRC when any member reported one so a conflicting ISRC is never
+// merged in through an ISRC-less member.
+func (r *aggregatedResult) representative() platform.Track {
+	track := r.sources[0].track
+	for _, source := range r.sources {
+		if isrc := strings.TrimSpace(source.track.ISRC); isrc != "" {
+			track.ISRC = isrc
+			break
+		}
+	}
+	return track
+}
+
+// isAggregateSearchToken reports whether a trailing keyword token selects the
+// all-platforms search mode.
+func isAggregateSearchToken(token string) bool {
+	return strings.EqualFold(strings.TrimSpace(token), aggregateSearchPlatform)
+}
+
+// splitAggregateSearchKeyword strips a trailing "all" option from keyword.
+// A bare "all" is left alone so it can still be searched for.
+func splitAggregateSearchKeyword(keyword string) (string, bool) {
+	fields := strings.Fields(keyword)
+	if len(fields) < 2 || !isAggregateSearchToken(fields[len(fields)-1]) {
+		return keyword, false
+	}
+	return strings.Join(fields[:len(fields)-1], " "), true
+}
+
+// platformQualityCeiling is the best tier a platform can deliver according to
+// its declared capabilities. Search results carry no per-track quality, so
+// this is what the badges show. Atmos is deliberately not considered: it is a
+// separate resource type, not a tier above Hi-Res.
+func platformQualityCeiling(plat platform.Platform) platform.Quality {
+	if plat == nil {
+		return platform.QualityStandard
+	}
+	caps := plat.Capabilities()
+	switch {
+	case caps.HiRes:
+		return platform.QualityHiRes
+	case caps.Download:
+		return platform.QualityHigh
+	default:
+		return platform.QualityStandard
+	}
+}
+
+// searchAllPlatforms fans the keyword out to every search-capable platform
+// concurrently. Platforms whose SearchRateLimit budget is exhausted, that fail
+// or that time out are returned in skipped instead of failing the whole search.
+func (h *SearchHandler) searchAllPlatforms(ctx context.Context, keyword string, userID, chatID int64, scopeType string, scopeID int64) ([]aggregatedResult, []string) {
+	names := h.searchPlatforms()
+	perPlatform := make(map[string][]platform.Track, len(names))
+	failed := make(map[string]bool)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+
+	searchCtx, cancel := context.WithTimeout(ctx, aggregateSearchTimeout)
+	defer cancel()
+	for _, name := range names {
+		if !h.ResourceLimiter.AllowFor(ActionSearch, userID, chatID, name) {
+			mu.Lock()
+			failed[name] = true
+			mu.Unlock()
+			continue
+		}
+		plat := h.PlatformManager.Get(name)
+		platformCtx := searchCtx
+		if enabled, supported, _ := resolveSearchFilterEnabled(ctx, h.PlatformManager, h.Repo, name, scopeType, scopeID); supported {
+			platformCtx = withSearchFilterContext(searchCtx, h.PlatformManager, name, enabled)
+		}
+		wg.Add(1)
+		go func(name string, plat platform.Platform, platformCtx context.Context) {
+			defer wg.Done()
+			tracks, err := plat.Search(platformCtx, keyword, aggregateSearchPerPlatformLimit)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				failed[name] = true
+				return
+			}
+			perPlatform[name] = tracks
+		}(name, plat, platformCtx)
+	}
+	wg.Wait()
+
+	skipped := make([]string, 0, len(failed))
+	for _, name := range names {
+		if failed[name] {
+			skipped = append(skipped, name)
+		}
+	}
+	qualityOf := func(name string) platform.Quality {
+		return platformQualityCeiling(h.PlatformManager.Get(name))
+	}
+	return mergeSearchResults(keyword, names, perPlatform, qualityOf), skipped
+}
+
+// mergeSearchResults collapses copies of the same recording and ranks the
+// groups. Two tracks are the same recording when their ISRCs match, or — when
+// either lacks an ISRC — when normalized title and primary artist match and
+// the durations agree within aggregateDurationTolerance. platformOrder breaks
+// ties so the output is deterministic.
+func mergeSearchResults(keyword string, platformOrder []string, perPlatform map[string][]platform.Track, qualityOf func(string) platform.Quality) []aggregatedResult {
+	results := make([]*aggregatedResult, 0)
+	byISRC := make(map[string]*aggregatedResult)
+	byKey := make(map[string][]*aggregatedResult)
+
+	for _, name := range platformOrder {
+		quality := platform.QualityStandard
+		if qualityOf != nil {
+			quality = qualityOf(name)
+		}
+		for rank, track := range perPlatform[name] {
+			if strings.TrimSpace(track.ID) == "" {
+				continue
+			}
+			source := aggregatedSource{platform: name, track: track, rank: rank, quality: quality}
+			isrc := strings.ToUpper(strings.TrimSpace(track.ISRC))
+			key := recordingKey(track)
+
+			var group *aggregatedResult
+			if isrc != "" {
+				group = byISRC[isrc]
+			}
+			if group == nil && key != "" {
+				for _, candidate := range byKey[key] {
+					if sameRecording(candidate.representative(), track) {
+						group = candidate
+						break
+					}
+				}
+			}
+			if group == nil {
+				group = &aggregatedResult{}
+				results = append(results, group)
+				if key != "" {
+					byKey[key] = append(byKey[key], group)
+				}
+			} else if groupHasPlatform(group, name) {
+				// A platform listing the same recording twice (e.g. a single and
+				// its album cut) keeps only its better-ranked copy.
+				continue
+			}
+			group.sources = append(group.sources, source)
+			if isrc != "" && byISRC[isrc] == nil {
+				byISRC[isrc] = group
+			}
+		}
+	}
+
+	normalizedKeyword := normalizeSearchText(keyword)
+	merged := make([]aggregatedResult, 0, len(results))
+	for _, group := range results {
+		sort.SliceStable(group.sources, func(i, j int) bool {
+			if group.sources[i].quality != group.sources[j].quality {
+				return group.sources[i].quality > group.sources[j].quality
+			}
+			return group.sources[i].rank < group.sources[j].rank
+		})
+		group.score = scoreAggregatedResult(normalizedKeyword, group)
+		merged = append(merged, *group)
+	}
+	sort.SliceStable(merged, func(i, j int) bool {
+		return merged[i].score > merged[j].score
+	})
+	return merged
+}
+
+func groupHasPlatform(group *aggregatedResult, name string) bool {
+	for _, source := range group.sources {
+		if source.platform == name {
+			return true
+		}
+	}
+	return false
+}
+
+// sameRecording compares two tracks that already share a recording key.
+// Distinct ISRCs always win over matching titles: a live cut and the studio
+// version are different recordings.
+func sameRecording(a, b platform.Track) bool {
+	isrcA := strings.ToUpper(strings.TrimSpace(a.ISRC))
+	isrcB := strings.ToUpper(strings.TrimSpace(b.ISRC))
+	if isrcA != "" && isrcB != "" {
+		return isrcA == isrcB
+	}
+	if a.Duration <= 0 || b.Duration <= 0 {
+		return true
+	}
+	diff := a.Duration - b.Duration
+	if diff < 0 {
+		diff = -diff
+	}
+	return diff <= aggregateDurationTolerance
+}
+
+// recordingKey is the normalized "title|primary artist" used to bucket
+// candidates before the duration check.
+func recordingKey(track platform.Track) string {
+	title := normalizeSearchText(track.Title)
+	if title == "" {
+		return ""
+	}
+	artist := ""
+	if len(track.Artists) > 0 {
+		artist = normalizeSearchText(track.Artists[0].Name)
+	}
+	return title + "|" + artist
+}
+
+// normalizeSearchText lowercases, folds full-width forms and drops everything
+// but letters and digits, so "晴天 (Live)" and "晴天（live）" compare equal.
+func normalizeSearchText(text string) string {
+	var builder strings.Builder
+	builder.Grow(len(text))
+	for _, r := range text {
+		switch {
+		case r >= 0xFF01 && r <= 0xFF5E:
+			r -= 0xFEE0
+		case r == 0x3000:
+			r = ' '
+		}
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			builder.WriteRune(unicode.ToLower(r))
+		}
+	}
+	return builder.String()
+}
+
+// scoreAggregatedResult ranks by relevance first, then by how widely the
+// recording is available and the best quality on offer. The weights keep an
+// exact title hit above any amount of availability.
+func scoreAggregatedResult(normalizedKeyword string, group *aggregatedResult) float64 {
+	best := group.best()
+	title := normalizeSearchText(best.track.Title)
+	artists := ""
+	for _, artist := range best.track.Artists {
+		artists += normalizeSearchText(artist.Name)
+	}
+
+	score := 0.0
+	switch {
+	case normalizedKeyword == "":
+	case title == normalizedKeyword, title+artists == normalizedKeyword, artists+title == normalizedKeyword:
+		score += 100
+	case strings.Contains(normalizedKeyword, title) && title != "":
+		score += 70
+	case strings.Contains(title, normalizedKeyword):
+		score += 50
+	case strings.Contains(artists, normalizedKeyword):
+		score += 30
+	}
+
+	bestRank := best.rank
+	for _, source := range group.sources {
+		if source.rank < bestRank {
+			bestRank = source.rank
+		}
+	}
+	score += float64(aggregateSearchPerPlatformLimit-bestRank) * 2
+	score += float64(len(group.sources)-1) * 6
+	score += float64(best.quality) * 3
+	return score
+}
+
+// buildAggregatedSearchPage renders one page of merged results. Each row shows
+// the per-platform quality badges; the number button downloads from the first
+// (best) source.
+// qualityFor maps the winning platform to its callback quality token, so
+// per-platform defaults still apply to whichever source a row resolves to.
+func (h *SearchHandler) buildAggregatedSearchPage(ctx context.Context, results []aggregatedResult, keyword string, qualityFor func(platformName string) string, requesterID int64, messageID, page int, skipped []string) (string, *telego.InlineKeyboardMarkup) {
+	pageSize := h.pageSize()
+	pageCount := 1
+	if len(results) > 0 {
+		pageCount = (len(results)-1)/pageSize + 1
+	}
+	if page < 1 {
+		page = 1
+	}
+	if page > pageCount {
+		page = pageCount
+	}
+	start := (page - 1) * pageSize
+	end := start + pageSize
+	if end > len(results) {
+		end = len(results)
+	}
+
+	var textMessage strings.Builder
+	textMessage.WriteString(fmt.Sprintf("%s *%s* %s\n\\* %s\n\n", aggregateSearchEmoji, trMd(ctx, "srch_all_platforms"), trMd(ctx, "srch_results"), trMd(ctx, "srch_pick_number_hint")))
+	if strings.TrimSpace(keyword) != "" {
+		textMessage.WriteString(fmt.Sprintf("%s%s\n", trMd(ctx, "srch_keyword_label"), mdV2Replacer.Replace(keyword)))
+	}
+	if len(skipped) > 0 {
+		names := make([]string, 0, len(skipped))
+		for _, name := range skipped {
+			names = append(names, platformDisplayName(ctx, h.PlatformManager, name))
+		}
+		textMessage.WriteString(trMd(ctx, "srch_all_skipped", map[string]any{"Platforms": strings.Join(names, ", ")}) + "\n")
+	}
+	if pageCount > 1 {
+		textMessage.WriteString(trMd(ctx, "srch_page_indicator", map[string]any{"Page": page, "Total": pageCount}) + "\n\n")
+	} else {
+		textMessage.WriteString("\n")
+	}
+
+	buttons := make([]telego.InlineKeyboardButton, 0, pageSize)
+	for i := start; i < end; i++ {
+		best := results[i].best()
+		track := best.track
+		escapedTitle := mdV2Replacer.Replace(track.Title)
+		trackLink := escapedTitle
+		if strings.TrimSpace(track.URL) != "" {
+			trackLink = fmt.Sprintf("[%s](%s)", escapedTitle, track.URL)
+		}
+		artistNames := make([]string, 0, len(track.Artists))
+		for _, artist := range track.Artists {
+			artistNames = append(artistNames, mdV2Replacer.Replace(artist.Name))
+		}
+		textMessage.WriteString(fmt.Sprintf("%d\\. 「%s」 \\- %s\n", i-start+1, trackLink, strings.Join(artistNames, " / ")))
+		badges := make([]string, 0, len(results[i].sources))
+		for _, source := range results[i].sources {
+			badges = append(badges, fmt.Sprintf("%s %s", platformEmoji(h.PlatformManager, source.platform), mdV2Replacer.Replace(qualityDisplayName(ctx, source.quality.String()))))
+		}
+		textMessage.WriteString("    " + strings.Join(badges, " · ") + "\n")
+
+		callbackData := buildMusicSendCallbackData(best.platform, track.ID, qualityFor(best.platform), requesterID)
+		if callbackData == "" {
+			continue
+		}
+		buttons = append(buttons, telego.InlineKeyboardButton{
+			Text:         fmt.Sprintf("%d", i-start+1),
+			CallbackData: callbackData,
+		})
+	}
+
+	var rows [][]telego.InlineKeyboardButton
+	if len(buttons) > 0 {
+		rows = append(rows, buttons)
+	}
+	closeButton := telego.InlineKeyboardButton{Text: tr(ctx, "srch_close"), CallbackData: fmt.Sprintf("search %d close %d", messageID, requesterID)}
+	navRow := make([]telego.InlineKeyboardButton, 0, 2)
+	if page > 1 {
+		navRow = append(navRow, telego.InlineKeyboardButton{Text: tr(ctx, "srch_nav_prev"), CallbackData: fmt.Sprintf("search %d page %d %d", messageID, page-1, requesterID)})
+	} else {
+		navRow = append(navRow, closeButton)
+	}
+	if page < pageCount {
+		navRow = append(navRow, telego.InlineKeyboardButton{Text: tr(ctx, "srch_nav_next"), CallbackData: fmt.Sprintf("search %d page %d %d", messageID, page+1, requesterID)})
+	} else if page > 1 {
+		navRow = append(navRow, telego.InlineKeyboardButton{Text: tr(ctx, "srch_nav_home"), CallbackData: fmt.Sprintf("search %d home %d", messageID, requesterID)})
+	}
+	rows = append(rows, navRow)
+	if switchRows := h.buildPlatformSwitchRows(ctx, aggregateSearchPlatform, requesterID, messageID, nil); len(switchRows) > 0 {
+		rows = append(rows, switchRows...)
+	}
+	return textMessage.String(), &telego.InlineKeyboardMarkup{InlineKeyboard: rows}
+}
+
+// buildAggregateSearchRow returns the "all platforms" button shown under
+// single-platform results, or nil when fewer than two platforms can search.
+func (h *SearchHandler) buildAggregateSearchRow(ctx context.Context, requesterID int64, messageID int) []telego.InlineKeyboardButton {
+	if len(h.searchPlatforms()) < 2 {
+		return nil
+	}
+	return []telego.InlineKeyboardButton{{
+		Text:         aggregateSearchEmoji + " " + tr(ctx, "srch_all_platforms"),
+		CallbackData: fmt.Sprintf("search %d platform %s %d", messageID, aggregateSearchPlatform, requesterID),
+	}}
+}
+
+// aggregateQualityResolver returns the per-platform callback quality for
+// aggregated rows, applying the same platform policy as single-platform search.
+func (h *SearchHandler) aggregateQualityResolver(ctx context.Context, chat telego.Chat, userID int64, qualityIntent string) func(string) string {
+	qualityValue, explicit := qualityIntentValue(qualityIntent)
+	if strings.TrimSpace(qualityValue) == "" {
+		qualityValue = "hires"
+		explicit = false
+	}
+	scopeType := botpkg.PluginScopeUser
+	scopeID := userID
+	if chat.Type != "private" {
+		scopeType = botpkg.PluginScopeGroup
+		scopeID = chat.ID
+	}
+	resolved := make(map[string]string)
+	return func(platformName string) string {
+		if token, ok := resolved[platformName]; ok {
+			return token
+		}
+		value := resolvePlatformQualityValue(ctx, h.Repo, scopeType, scopeID, platformName, qualityValue, explicit)
+		token := qualityIntentToken(value, explicit)
+		resolved[platformName] = token
+		return token
+	}
+}
+
+// runAggregatedSearchMessage is the all-platforms branch of runSearch. It
+// edits the "searching" placeholder in place and stores the merged results so
+// paging never re-queries the platforms.
+func (h *SearchHandler) runAggregatedSearchMessage(ctx context.Context, b *telego.Bot, message, placeholder *telego.Message, keyword, qualityOverride string) {
+	userID := searchRequesterID(message)
+	explicitQuality := strings.TrimSpace(qualityOverride) != ""
+	qualityValue := h.resolveDefaultQuality(ctx, message, userID)
+	if explicitQuality {
+		qualityValue = qualityOverride
+	}
+	state := &searchState{
+		keyword:     keyword,
+		platform:    aggregateSearchPlatform,
+		quality:     qualityIntentToken(qualityValue, explicitQuality),
+		requesterID: userID,
+		limit:       defaultSearchLimit,
+		currentPage: 1,
+		updatedAt:   time.Now(),
+		action:      "music",
+	}
+	params := &telego.EditMessageTextParams{
+		ChatID:    telego.ChatID{ID: placeholder.Chat.ID},
+		MessageID: placeholder.MessageID,
+	}
+	if h.fillAggregatedResults(ctx, state, message.Chat, userID) {
+		params.Text, params.ReplyMarkup = h.buildAggregatedSearchPage(ctx, state.aggregated, keyword, h.aggregateQualityResolver(ctx, message.Chat, userID, state.quality), userID, placeholder.MessageID, 1, state.aggregateSkipped)
+		params.ParseMode = telego.ModeMarkdownV2
+		params.LinkPreviewOptions = &telego.LinkPreviewOptions{IsDisabled: true}
+	} else {
+		params.Text, params.ReplyMarkup = h.buildNoResultsPage(ctx, state, placeholder.MessageID)
+	}
+	if h.RateLimiter != nil {
+		_, _ = telegram.EditMessageTextWithRetry(ctx, h.RateLimiter, b, params)
+	} else {
+		_, _ = b.EditMessageText(ctx, params)
+	}
+	h.storeSearchState(placeholder.MessageID, state)
+}
+
+// handleAggregatedPage serves page turns of an aggregated search. refresh is
+// set when the user just switched to the all-platforms view, which triggers
+// the fan-out; plain page turns are served from the stored results.
+func (h *SearchCallbackHandler) handleAggregatedPage(ctx context.Context, b *telego.Bot, query *telego.CallbackQuery, msg *telego.Message, state *searchState, messageID, page int, refresh bool) {
+	search := h.Search
+	if refresh || len(state.aggregated) == 0 {
+		if !search.fillAggregatedResults(ctx, state, msg.Chat, query.From.ID) {
+			text, keyboard := search.buildNoResultsPage(ctx, state, messageID)
+			params := &telego.EditMessageTextParams{ChatID: telego.ChatID{ID: msg.Chat.ID}, MessageID: msg.MessageID, Text: text, ReplyMarkup: keyboard}
+			if h.RateLimiter != nil {
+				_, _ = telegram.EditMessageTextWithRetry(ctx, h.RateLimiter, b, params)
+			} else {
+				_, _ = b.EditMessageText(ctx, params)
+			}
+			search.storeSearchState(messageID, state)
+			_ = b.AnswerCallbackQuery(ctx, &telego.AnswerCallbackQueryParams{CallbackQueryID: query.ID})
+			return
+		}
+	}
+	text, keyboard := search.buildAggregatedSearchPage(ctx, state.aggregated, state.keyword, search.aggregateQualityResolver(ctx, msg.Chat, state.requesterID, state.quality), state.requesterID, messageID, page, state.aggregateSkipped)
+	params := &telego.EditMessageTextParams{
+		ChatID:             telego.ChatID{ID: msg.Chat.ID},
+		MessageID:          msg.MessageID,
+		Text:               text,
+		ParseMode:          telego.ModeMarkdownV2,
+		ReplyMarkup:        keyboard,
+		LinkPreviewOptions: &telego.LinkPreviewOptions{IsDisabled: true},
+	}
+	var err error
+	if h.RateLimiter != nil {
+		_, err = telegram.EditMessageTextWithRetry(ctx, h.RateLimiter, b, params)
+	} else {
+		_, err = b.EditMessageText(ctx, params)
+	}
+	if err != nil {
+		return
+	}
+	state.currentPage = page
+	search.storeSearchState(messageID, state)
+	_ = b.AnswerCallbackQuery(ctx, &telego.AnswerCallbackQueryParams{CallbackQueryID: query.ID})
+}
+
+// fillAggregatedResults runs the fan-out for state and stores the merged
+// results on it. It returns false when no platform produced a result.
+func (h *SearchHandler) fillAggregatedResults(ctx context.Context, state *searchState, chat telego.Chat, userID int64) bool {
+	scopeType := botpkg.PluginScopeUser
+	scopeID := userID
+	if chat.Type != "private" {
+		scopeType = botpkg.PluginScopeGroup
+		scopeID = chat.ID
+	}
+	results, skipped := h.searchAllPlatforms(ctx, state.keyword, userID, chat.ID, scopeType, scopeID)
+	state.aggregated = results
+	state.aggregateSkipped = skipped
+	return len(results) > 0
+}
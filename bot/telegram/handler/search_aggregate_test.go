@@ -0,0 +1,136 @@
+package handler
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/liuran001/MusicBot-Go/bot/platform"
+)
+
+func aggTrack(id, title, artist string, seconds int, isrc string) platform.Track {
+	return platform.Track{
+		ID:       id,
+		Title:    title,
+		Artists:  []platform.Artist{{Name: artist}},
+		Duration: time.Duration(seconds) * time.Second,
+		ISRC:     isrc,
+	}
+}
+
+func TestSplitAggregateSearchKeyword(t *testing.T) {
+	cases := []struct {
+		in      string
+		want    string
+		wantAll bool
+	}{
+		{"晴天 all", "晴天", true},
+		{"Take My Hand ALL", "Take My Hand", true},
+		{"all", "all", false},
+		{"all of me", "all of me", false},
+	}
+	for _, tc := range cases {
+		got, all := splitAggregateSearchKeyword(tc.in)
+		if got != tc.want || all != tc.wantAll {
+			t.Errorf("splitAggregateSearchKeyword(%q) = %q, %v; want %q, %v", tc.in, got, all, tc.want, tc.wantAll)
+		}
+	}
+}
+
+func TestMergeSearchResultsCollapsesSameRecording(t *testing.T) {
+	perPlatform := map[string][]platform.Track{
+		"netease": {
+			aggTrack("n1", "晴天", "周杰伦", 269, ""),
+			aggTrack("n2", "晴天 (Live)", "周杰伦", 301, ""),
+		},
+		"qqmusic": {
+			aggTrack("q1", "晴天", "周杰伦", 270, "TWK970300032"),
+		},
+		"spotify": {
+			aggTrack("s1", "晴天", "周杰伦", 269, "TWK970300032"),
+			// Same title and artist, but a different ISRC: a separate recording.
+			aggTrack("s2", "晴天", "周杰伦", 269, "TWK971111111"),
+		},
+	}
+	quality := map[string]platform.Quality{
+		"netease": platform.QualityHiRes,
+		"qqmusic": platform.QualityHiRes,
+		"spotify": platform.QualityHigh,
+	}
+	results := mergeSearchResults("晴天", []string{"netease", "qqmusic", "spotify"}, perPlatform, func(name string) platform.Quality {
+		return quality[name]
+	})
+	if len(results) != 3 {
+		t.Fatalf("len(results) = %d, want 3", len(results))
+	}
+
+	top := results[0]
+	if len(top.sources) != 3 {
+		t.Fatalf("top result sources = %d, want 3 (netease+qqmusic+spotify)", len(top.sources))
+	}
+	if top.best().platform != "netease" {
+		t.Errorf("best source = %q, want netease (Hi-Res, best rank)", top.best().platform)
+	}
+	if top.sources[len(top.sources)-1].platform != "spotify" {
+		t.Errorf("lowest-quality source should sort last, got %q", top.sources[len(top.sources)-1].platform)
+	}
+
+	for _, result := range results[1:] {
+		if len(result.sources) != 1 {
+			t.Errorf("result %q merged unexpectedly: %d sources", result.best().track.ID, len(result.sources))
+		}
+	}
+}
+
+func TestMergeSearchResultsRespectsDurationTolerance(t *testing.T) {
+	perPlatform := map[string][]platform.Track{
+		"netease": {aggTrack("n1", "Song", "Artist", 200, "")},
+		"kugou":   {aggTrack("k1", "ＳＯＮＧ", "artist", 260, "")},
+	}
+	results := mergeSearchResults("song", []string{"netease", "kugou"}, perPlatform, nil)
+	if len(results) != 2 {
+		t.Fatalf("len(results) = %d, want 2 (durations differ by a minute)", len(results))
+	}
+
+	perPlatform["kugou"] = []platform.Track{aggTrack("k1", "ＳＯＮＧ", "artist", 202, "")}
+	results = mergeSearchResults("song", []string{"netease", "kugou"}, perPlatform, nil)
+	if len(results) != 1 {
+		t.Fatalf("len(results) = %d, want 1 (full-width title, 2s drift)", len(results))
+	}
+}
+
+func TestMergeSearchResultsRanksExactTitleFirst(t *testing.T) {
+	perPlatform := map[string][]platform.Track{
+		"netease": {
+			aggTrack("n1", "晴天娃娃", "某人", 180, ""),
+			aggTrack("n2", "晴天", "周杰伦", 269, ""),
+		},
+	}
+	results := mergeSearchResults("晴天", []string{"netease"}, perPlatform, nil)
+	if got := results[0].best().track.ID; got != "n2" {
+		t.Fatalf("top result = %q, want exact title match n2", got)
+	}
+}
+
+func TestBuildAggregatedSearchPageShowsBadges(t *testing.T) {
+	handler := &SearchHandler{PageSize: 8}
+	results := mergeSearchResults("晴天", []string{"netease", "qqmusic"}, map[string][]platform.Track{
+		"netease": {aggTrack("n1", "晴天", "周杰伦", 269, "")},
+		"qqmusic": {aggTrack("q1", "晴天", "周杰伦", 269, "")},
+	}, func(name string) platform.Quality {
+		if name == "netease" {
+			return platform.QualityHiRes
+		}
+		return platform.QualityLossless
+	})
+	text, keyboard := handler.buildAggregatedSearchPage(enCtx(), results, "晴天", func(string) string { return "auto-hires" }, 1, 100, 1, []string{"kugou"})
+	if !strings.Contains(text, "Hi\\-Res") || !strings.Contains(text, "Lossless") {
+		t.Fatalf("page text missing quality badges:\n%s", text)
+	}
+	if !strings.Contains(text, "Skipped") {
+		t.Fatalf("page text missing skipped platform:\n%s", text)
+	}
+	if got := keyboard.InlineKeyboard[0][0].CallbackData; !strings.Contains(got, "netease") || !strings.Contains(got, "n1") {
+		t.Fatalf("number button = %q, want best source netease/n1", got)
+	}
+}
@@ -63,6 +63,11 @@ func (h *SettingsHandler) Handle(ctx context.Context, b *telego.Bot, update *tel
 		return
 	}
 
+	if field, src, ok := parseTemplateCommand(commandArguments(message.Text)); ok {
+		h.handleTemplateCommand(ctx, b, message, settings, groupSettings, field, src)
+		return
+	}
+
 	platforms := h.PlatformManager.List()
 
 	chatType := string(message.Chat.Type)
@@ -110,6 +115,11 @@ func (h *SettingsHandler) buildSettingsText(ctx context.Context, chatType string
 	qualityEmoji := h.getQualityEmoji(qualityValue)
 	sb.WriteString(fmt.Sprintf("🎧 %s：%s %s\n", tr(ctx, "set_quality_label"), qualityEmoji, h.getQualityDisplayName(ctx, qualityValue)))
 
+	outputFormat := h.resolveDefaultOutputFormat(chatType, settings, groupSettings)
+	sb.WriteString(fmt.Sprintf("🎼 %s：%s\n", tr(ctx, "set_output_label"), outputFormatDisplayName(ctx, outputFormat)))
+	sb.WriteString(fmt.Sprintf("📝 %s：%s\n", tr(ctx, "set_tpl_label"), h.templatesSummary(ctx, chatType, settings, groupSettings)))
+	sb.WriteString(fmt.Sprintf("🖼 %s：%s\n", tr(ctx, "set_cover_label"), coverEmbedDisplayName(ctx, h.resolveCoverEmbed(chatType, settings, groupSettings))))
+
 	lyricFormat := h.resolveDefaultLyricFormat(chatType, settings, groupSettings)
 	lyricSummary := lyricFormatDisplayName(ctx, lyricFormat)
 	if lyricFormatSupportsSideTracks(lyricFormat) {
@@ -241,6 +251,22 @@ func (h *SettingsHandler) buildSettingsKeyboard(ctx context.Context, chatType st
 		}})
 	}
 
+	outputFormat := h.resolveDefaultOutputFormat(chatType, settings, groupSettings)
+	rows = append(rows, []telego.InlineKeyboardButton{
+		{
+			Text:         fmt.Sprintf("🎼 %s：%s", tr(ctx, "set_output_label"), outputFormatDisplayName(ctx, outputFormat)),
+			CallbackData: "settings outputmenu",
+		},
+		{
+			Text:         fmt.Sprintf("📝 %s：%s", tr(ctx, "set_tpl_label"), h.templatesSummary(ctx, chatType, settings, groupSettings)),
+			CallbackData: "settings tplmenu",
+		},
+	})
+	rows = append(rows, []telego.InlineKeyboardButton{{
+		Text:         fmt.Sprintf("🖼 %s：%s", tr(ctx, "set_cover_label"), coverEmbedDisplayName(ctx, h.resolveCoverEmbed(chatType, settings, groupSettings))),
+		CallbackData: "settings covermenu",
+	}})
+
 	autoDeleteEnabled := h.resolveAutoDeleteList(chatType, settings, groupSettings)
 	autoLinkDetectEnabled := h.resolveAutoLinkDetect(chatType, settings, groupSettings)
 	rows = append(rows, []telego.InlineKeyboardButton{
@@ -325,6 +351,21 @@ func (h *SettingsHandler) resolveDefaultLyricFormat(chatType string, settings *b
 	return lyricpkg.NormalizeFormat(format)
 }
 
+// resolveDefaultOutputFormat returns the scope's transcode target; unset or
+// unknown values mean "original".
+func (h *SettingsHandler) resolveDefaultOutputFormat(chatType string, settings *botpkg.UserSettings, groupSettings *botpkg.GroupSettings) string {
+	if chatType != "private" {
+		if groupSettings != nil {
+			return normalizeOutputFormat(groupSettings.DefaultOutputFormat)
+		}
+		return outputFormatOriginal
+	}
+	if settings != nil {
+		return normalizeOutputFormat(settings.DefaultOutputFormat)
+	}
+	return outputFormatOriginal
+}
+
 // resolveDefaultLyricFlags resolves the persisted translation/roma side-track
 // defaults for the current scope. A nil stored pointer means "unset", in which
 // case the per-format default applies (document formats default translation on;
@@ -558,6 +599,40 @@ func (h *SettingsHandler) getQualityEmoji(quality string) string {
 	}
 }
 
+// buildOutputFormatMenuKeyboard builds the output-format submenu: one button
+// per format (current marked "✅") plus a back button.
+func (h *SettingsHandler) buildOutputFormatMenuKeyboard(ctx context.Context, chatType string, settings *botpkg.UserSettings, groupSettings *botpkg.GroupSettings) *telego.InlineKeyboardMarkup {
+	current := h.resolveDefaultOutputFormat(chatType, settings, groupSettings)
+	button := func(format string) telego.InlineKeyboardButton {
+		label := outputFormatDisplayName(ctx, format)
+		if format == current {
+			label = "✅ " + label
+		}
+		return telego.InlineKeyboardButton{Text: label, CallbackData: fmt.Sprintf("settings output %s", format)}
+	}
+	rows := [][]telego.InlineKeyboardButton{{button(settingsOutputFormats[0])}}
+	for i := 1; i < len(settingsOutputFormats); i += 2 {
+		row := []telego.InlineKeyboardButton{button(settingsOutputFormats[i])}
+		if i+1 < len(settingsOutputFormats) {
+			row = append(row, button(settingsOutputFormats[i+1]))
+		}
+		rows = append(rows, row)
+	}
+	rows = append(rows, []telego.InlineKeyboardButton{{Text: "⬅️ " + tr(ctx, "set_btn_back"), CallbackData: "settings outputback"}})
+	return &telego.InlineKeyboardMarkup{InlineKeyboard: rows}
+}
+
+// buildOutputFormatMenuText is the header text shown above the output-format
+// submenu.
+func (h *SettingsHandler) buildOutputFormatMenuText(ctx context.Context, chatType string, settings *botpkg.UserSettings, groupSettings *botpkg.GroupSettings) string {
+	current := h.resolveDefaultOutputFormat(chatType, settings, groupSettings)
+	var sb strings.Builder
+	sb.WriteString("🎼 " + tr(ctx, "set_output_menu_title") + "\n\n")
+	sb.WriteString(fmt.Sprintf("%s：%s\n", tr(ctx, "set_lyric_menu_current"), outputFormatDisplayName(ctx, current)))
+	sb.WriteString("\n" + tr(ctx, "set_output_menu_hint"))
+	return sb.String()
+}
+
 func (h *SettingsHandler) getQualityDisplayName(ctx context.Context, quality string) string {
 	switch quality {
 	case "standard":
@@ -582,11 +657,22 @@ type SettingsCallbackHandler struct {
 	RateLimiter     *telegram.RateLimiter
 }
 
-// handleLyricMenuNavigation swaps the message between the main settings view and
-// the default-lyric-format submenu. enterMenu picks the direction. It only edits
-// text+keyboard; persistence happens via the "lyricfmt" case. In groups, opening
-// the submenu still requires admin (mirroring the rest of group settings).
-func (h *SettingsCallbackHandler) handleLyricMenuNavigation(ctx context.Context, b *telego.Bot, query *telego.CallbackQuery, msg *telego.Message, enterMenu bool) {
+// Submenus reachable from the main settings view.
+const (
+	settingsMenuMain   = ""
+	settingsMenuLyric  = "lyric"
+	settingsMenuOutput = "output"
+	settingsMenuTpl    = "tpl"
+	settingsMenuCover  = "cover"
+)
+
+// handleSubmenuNavigation swaps the message between the main settings view and
+// a submenu (default lyric format, output format, templates or cover). It only
+// edits text+keyboard; persistence happens via the "lyricfmt"/"output"/
+// "tplreset"/"cover" cases and /settings template. In
+// groups, opening a submenu still requires admin (mirroring the rest of group
+// settings).
+func (h *SettingsCallbackHandler) handleSubmenuNavigation(ctx context.Context, b *telego.Bot, query *telego.CallbackQuery, msg *telego.Message, menu string) {
 	userID := query.From.ID
 	var settings *botpkg.UserSettings
 	var groupSettings *botpkg.GroupSettings
@@ -618,10 +704,20 @@ func (h *SettingsCallbackHandler) handleLyricMenuNavigation(ctx context.Context,
 	chatType := string(msg.Chat.Type)
 	var text string
 	var keyboard *telego.InlineKeyboardMarkup
-	if enterMenu {
+	switch menu {
+	case settingsMenuLyric:
 		text = h.SettingsHandler.buildLyricFormatMenuText(ctx, chatType, settings, groupSettings)
 		keyboard = h.SettingsHandler.buildLyricFormatMenuKeyboard(ctx, chatType, settings, groupSettings)
-	} else {
+	case settingsMenuOutput:
+		text = h.SettingsHandler.buildOutputFormatMenuText(ctx, chatType, settings, groupSettings)
+		keyboard = h.SettingsHandler.buildOutputFormatMenuKeyboard(ctx, chatType, settings, groupSettings)
+	case settingsMenuTpl:
+		text = h.SettingsHandler.buildTemplateMenuText(ctx, chatType, settings, groupSettings)
+		keyboard = h.SettingsHandler.buildTemplateMenuKeyboard(ctx, chatType, settings, groupSettings)
+	case settingsMenuCover:
+		text = h.SettingsHandler.buildCoverMenuText(ctx, chatType, settings, groupSettings)
+		keyboard = h.SettingsHandler.buildCoverMenuKeyboard(ctx, chatType, settings, groupSettings)
+	default:
 		platforms := h.PlatformManager.List()
 		text = h.SettingsHandler.buildSettingsText(ctx, chatType, settings, groupSettings, platforms)
 		keyboard = h.SettingsHandler.buildSettingsKeyboard(ctx, chatType, settings, groupSettings, platforms)
@@ -669,10 +765,24 @@ func (h *SettingsCallbackHandler) Handle(ctx context.Context, b *telego.Bot, upd
 		return
 	}
 
-	// Navigate into / back out of the default-lyric-format submenu. These only
-	// swap the keyboard+text; no setting changes here.
-	if args[1] == "lyricmenu" || args[1] == "lyricback" {
-		h.handleLyricMenuNavigation(ctx, b, query, msg, args[1] == "lyricmenu")
+	// Navigate into / back out of the lyric-format, output-format, template
+	// and cover submenus. These only swap the keyboard+text; no setting changes
+	// here.
+	switch args[1] {
+	case "lyricmenu":
+		h.handleSubmenuNavigation(ctx, b, query, msg, settingsMenuLyric)
+		return
+	case "outputmenu":
+		h.handleSubmenuNavigation(ctx, b, query, msg, settingsMenuOutput)
+		return
+	case "tplmenu":
+		h.handleSubmenuNavigation(ctx, b, query, msg, settingsMenuTpl)
+		return
+	case "covermenu":
+		h.handleSubmenuNavigation(ctx, b, query, msg, settingsMenuCover)
+		return
+	case "lyricback", "outputback", "tplback", "coverback":
+		h.handleSubmenuNavigation(ctx, b, query, msg, settingsMenuMain)
 		return
 	}
 
@@ -914,6 +1024,37 @@ func (h *SettingsCallbackHandler) Handle(ctx context.Context, b *telego.Bot, upd
 			changed = true
 			responseText = "✅ " + tr(ctx, "set_resp_lyricfmt_set", map[string]any{"Name": lyricFormatDisplayName(ctx, resolved)})
 		}
+	case "output":
+		if _, known := outputFormatSpecs[settingValue]; !known && settingValue != outputFormatOriginal {
+			break
+		}
+		if msg != nil && msg.Chat.Type != "private" {
+			if groupSettings != nil && normalizeOutputFormat(groupSettings.DefaultOutputFormat) != settingValue {
+				groupSettings.DefaultOutputFormat = settingValue
+				changed = true
+			}
+		} else if settings != nil && normalizeOutputFormat(settings.DefaultOutputFormat) != settingValue {
+			settings.DefaultOutputFormat = settingValue
+			changed = true
+		}
+		if changed {
+			responseText = "✅ " + tr(ctx, "set_resp_output_set", map[string]any{"Name": outputFormatDisplayName(ctx, settingValue)})
+		}
+	case "tplreset":
+		if settingValue != templateFieldFilename && settingValue != templateFieldCaption && settingValue != templateFieldTags {
+			break
+		}
+		if changed = setTemplate(string(msg.Chat.Type), settings, groupSettings, settingValue, ""); changed {
+			responseText = "✅ " + tr(ctx, "set_resp_tpl_reset", map[string]any{"Field": templateFieldLabel(ctx, settingValue)})
+		}
+	case "cover":
+		policy, known := normalizeCoverEmbed(settingValue)
+		if !known {
+			break
+		}
+		if changed = setCoverEmbed(string(msg.Chat.Type), settings, groupSettings, policy); changed {
+			responseText = "✅ " + tr(ctx, "set_resp_cover_set", map[string]any{"Name": coverEmbedDisplayName(ctx, policy)})
+		}
 	case "lyrictrans", "lyricroma":
 		if settingValue != "on" && settingValue != "off" {
 			break
@@ -983,6 +1124,15 @@ func (h *SettingsCallbackHandler) Handle(ctx context.Context, b *telego.Bot, upd
 				// Stay in the lyric-format submenu so the ✅/toggle state updates.
 				text = h.SettingsHandler.buildLyricFormatMenuText(ctx, chatType, settings, groupSettings)
 				keyboard = h.SettingsHandler.buildLyricFormatMenuKeyboard(ctx, chatType, settings, groupSettings)
+			} else if settingType == "output" {
+				text = h.SettingsHandler.buildOutputFormatMenuText(ctx, chatType, settings, groupSettings)
+				keyboard = h.SettingsHandler.buildOutputFormatMenuKeyboard(ctx, chatType, settings, groupSettings)
+			} else if settingType == "cover" {
+				text = h.SettingsHandler.buildCoverMenuText(ctx, chatType, settings, groupSettings)
+				keyboard = h.SettingsHandler.buildCoverMenuKeyboard(ctx, chatType, settings, groupSettings)
+			} else if settingType == "tplreset" {
+				text = h.SettingsHandler.buildTemplateMenuText(ctx, chatType, settings, groupSettings)
+				keyboard = h.SettingsHandler.buildTemplateMenuKeyboard(ctx, chatType, settings, groupSettings)
 			} else {
 				platforms := h.PlatformManager.List()
 				text = h.SettingsHandler.buildSettingsText(ctx, chatType, settings, groupSettings, platforms)
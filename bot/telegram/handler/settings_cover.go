@@ -0,0 +1,96 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	botpkg "github.com/liuran001/MusicBot-Go/bot"
+	"github.com/mymmrac/telego"
+)
+
+// resolveCoverEmbed returns the scope's embedded-cover policy.
+func (h *SettingsHandler) resolveCoverEmbed(chatType string, settings *botpkg.UserSettings, groupSettings *botpkg.GroupSettings) string {
+	value := ""
+	if chatType != "private" {
+		if groupSettings != nil {
+			value = groupSettings.CoverEmbed
+		}
+	} else if settings != nil {
+		value = settings.CoverEmbed
+	}
+	policy, _ := normalizeCoverEmbed(value)
+	return policy
+}
+
+// setCoverEmbed stores policy in the scope's settings and reports whether
+// anything changed.
+func setCoverEmbed(chatType string, settings *botpkg.UserSettings, groupSettings *botpkg.GroupSettings, policy string) bool {
+	if chatType != "private" {
+		if groupSettings == nil || groupSettings.CoverEmbed == policy {
+			return false
+		}
+		groupSettings.CoverEmbed = policy
+		return true
+	}
+	if settings == nil || settings.CoverEmbed == policy {
+		return false
+	}
+	settings.CoverEmbed = policy
+	return true
+}
+
+// coverEmbedDisplayName labels a policy in the settings views.
+func coverEmbedDisplayName(ctx context.Context, policy string) string {
+	switch policy {
+	case coverEmbedDefault:
+		return tr(ctx, "set_cover_default")
+	case coverEmbedOriginal:
+		return tr(ctx, "set_cover_original")
+	case coverEmbedNone:
+		return tr(ctx, "set_cover_none")
+	default:
+		return tr(ctx, "set_cover_max", map[string]any{"Size": coverEmbedMaxEdge(policy)})
+	}
+}
+
+// coverEmbedCallbackValue is the "settings cover <v>" value of a policy; the
+// empty default travels as "default".
+func coverEmbedCallbackValue(policy string) string {
+	if policy == coverEmbedDefault {
+		return "default"
+	}
+	return policy
+}
+
+// buildCoverMenuText is the header text shown above the cover submenu.
+func (h *SettingsHandler) buildCoverMenuText(ctx context.Context, chatType string, settings *botpkg.UserSettings, groupSettings *botpkg.GroupSettings) string {
+	var sb strings.Builder
+	sb.WriteString("🖼 " + tr(ctx, "set_cover_menu_title") + "\n\n")
+	sb.WriteString(fmt.Sprintf("%s：%s\n", tr(ctx, "set_lyric_menu_current"), coverEmbedDisplayName(ctx, h.resolveCoverEmbed(chatType, settings, groupSettings))))
+	sb.WriteString("\n" + tr(ctx, "set_cover_menu_hint"))
+	return sb.String()
+}
+
+// buildCoverMenuKeyboard offers one button per policy, two per row, plus a
+// back button.
+func (h *SettingsHandler) buildCoverMenuKeyboard(ctx context.Context, chatType string, settings *botpkg.UserSettings, groupSettings *botpkg.GroupSettings) *telego.InlineKeyboardMarkup {
+	current := h.resolveCoverEmbed(chatType, settings, groupSettings)
+	button := func(policy string) telego.InlineKeyboardButton {
+		label := coverEmbedDisplayName(ctx, policy)
+		if policy == current {
+			label = "✅ " + label
+		}
+		return telego.InlineKeyboardButton{Text: label, CallbackData: "settings cover " + coverEmbedCallbackValue(policy)}
+	}
+	rows := [][]telego.InlineKeyboardButton{{button(coverEmbedChoices[0])}}
+	for i := 1; i < len(coverEmbedChoices); i += 2 {
+		row := []telego.InlineKeyboardButton{button(coverEmbedChoices[i])}
+		if i+1 < len(coverEmbedChoices) {
+			row = append(row, button(coverEmbedChoices[i+1]))
+		}
+		rows = append(rows, row)
+	}
+	rows = append(rows, []telego.InlineKeyboardButton{{Text: "⬅️ " + tr(ctx, "set_btn_back"), CallbackData: "settings coverback"}})
+	return &telego.InlineKeyboardMarkup{InlineKeyboard: rows}
+}
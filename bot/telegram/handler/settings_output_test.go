@@ -0,0 +1,113 @@
+package handler
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	botpkg "github.com/liuran001/MusicBot-Go/bot"
+	"github.com/mymmrac/telego"
+)
+
+func TestOutputFormatMenuMarksCurrent(t *testing.T) {
+	h := &SettingsHandler{}
+	settings := &botpkg.UserSettings{UserID: 1, DefaultOutputFormat: outputFormatOpus}
+
+	kb := h.buildOutputFormatMenuKeyboard(enCtx(), "private", settings, nil)
+	var formats []string
+	checked := ""
+	for _, row := range kb.InlineKeyboard {
+		for _, btn := range row {
+			value, ok := strings.CutPrefix(btn.CallbackData, "settings output ")
+			if !ok {
+				continue
+			}
+			formats = append(formats, value)
+			if strings.HasPrefix(btn.Text, "✅ ") {
+				checked = value
+			}
+		}
+	}
+	if strings.Join(formats, ",") != strings.Join(settingsOutputFormats, ",") {
+		t.Fatalf("menu formats = %v, want %v", formats, settingsOutputFormats)
+	}
+	if checked != outputFormatOpus {
+		t.Fatalf("checked format = %q, want %q", checked, outputFormatOpus)
+	}
+
+	// Rows saved before the setting existed carry an empty value.
+	legacy := &botpkg.GroupSettings{ChatID: -1}
+	if got := h.resolveDefaultOutputFormat("supergroup", nil, legacy); got != outputFormatOriginal {
+		t.Fatalf("empty group format resolved to %q", got)
+	}
+	text := h.buildSettingsText(enCtx(), "supergroup", nil, legacy, nil)
+	if !strings.Contains(text, "Output format：Original") {
+		t.Fatalf("settings text missing output format line:\n%s", text)
+	}
+}
+
+func TestResolveOutputFormatUsesChatScope(t *testing.T) {
+	repo := newStubRepo()
+	repo.userSettings[7] = &botpkg.UserSettings{UserID: 7, DefaultOutputFormat: outputFormatMP3}
+	repo.groupSettings[-100] = &botpkg.GroupSettings{ChatID: -100, DefaultOutputFormat: "wav"}
+	h := &MusicHandler{Repo: repo}
+	ctx := context.Background()
+
+	private := &telego.Message{Chat: telego.Chat{ID: 7, Type: "private"}}
+	if got := h.resolveOutputFormat(ctx, private, 7); got != outputFormatMP3 {
+		t.Fatalf("private format = %q, want %q", got, outputFormatMP3)
+	}
+	group := &telego.Message{Chat: telego.Chat{ID: -100, Type: "supergroup"}}
+	if got := h.resolveOutputFormat(ctx, group, 7); got != outputFormatOriginal {
+		t.Fatalf("unknown group format resolved to %q, want original", got)
+	}
+}
+
+func TestConvertOutputFormatTranscodesAndSkipsSameCodec(t *testing.T) {
+	ffmpegPath, err := exec.LookPath("ffmpeg")
+	if err != nil {
+		t.Skip("ffmpeg not installed")
+	}
+	if _, err := exec.LookPath("ffprobe"); err != nil {
+		t.Skip("ffprobe not installed")
+	}
+	dir := t.TempDir()
+	src := filepath.Join(dir, "Artist - Title.flac")
+	if out, err := exec.Command(ffmpegPath, "-y", "-f", "lavfi", "-i", "sine=frequency=440:duration=2", "-c:a", "flac", src).CombinedOutput(); err != nil {
+		t.Fatalf("make source: %v: %s", err, out)
+	}
+	h := &MusicHandler{}
+	ctx := context.Background()
+
+	song := &botpkg.SongInfo{FileExt: "flac", Duration: 2, BitDepth: 16}
+	out, created, err := h.convertOutputFormat(ctx, nil, nil, "1", nil, song, src, "", outputFormatMP3)
+	if err != nil {
+		t.Fatalf("convert: %v", err)
+	}
+	if len(created) != 1 || filepath.Dir(out) != created[0] || filepath.Base(out) != "Artist - Title.mp3" {
+		t.Fatalf("unexpected output %q (created %v)", out, created)
+	}
+	if song.OutputFormat != outputFormatMP3 || song.FileExt != "mp3" || song.BitDepth != 0 || song.MusicSize <= 0 {
+		t.Fatalf("song info not updated: %+v", song)
+	}
+	if _, err := os.Stat(src); err != nil {
+		t.Fatalf("shared source was touched: %v", err)
+	}
+
+	// Converting the mp3 again is a no-op that still marks the variant.
+	again := &botpkg.SongInfo{FileExt: "mp3", BitRate: 320000}
+	same, created, err := h.convertOutputFormat(ctx, nil, nil, "1", nil, again, out, "", outputFormatMP3)
+	if err != nil || same != out || len(created) != 0 || again.OutputFormat != outputFormatMP3 {
+		t.Fatalf("same-codec source should pass through, got %q %v %v %+v", same, created, err, again)
+	}
+
+	// A lossy source is not inflated into FLAC.
+	lossy := &botpkg.SongInfo{FileExt: "mp3", BitRate: 320000}
+	kept, created, err := h.convertOutputFormat(ctx, nil, nil, "1", nil, lossy, out, "", outputFormatFLAC)
+	if err != nil || kept != out || len(created) != 0 || lossy.FileExt != "mp3" {
+		t.Fatalf("lossy source should pass through a lossless target, got %q %v %v %+v", kept, created, err, lossy)
+	}
+}
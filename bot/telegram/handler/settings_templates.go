@@ -0,0 +1,264 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	botpkg "github.com/liuran001/MusicBot-Go/bot"
+	"github.com/liuran001/MusicBot-Go/bot/render"
+	"github.com/liuran001/MusicBot-Go/bot/telegram"
+	"github.com/mymmrac/telego"
+)
+
+// Template fields editable from /settings besides the tag fields of
+// render.TagFields. "tags" resets every tag template at once.
+const (
+	templateFieldFilename = "filename"
+	templateFieldCaption  = "caption"
+	templateFieldTags     = "tags"
+	templateReset         = "reset"
+)
+
+// templateDataFields lists what templates can reference; it is shown in the
+// submenu untranslated since the names are the syntax.
+const templateDataFields = ".Title .Artists .Album .AlbumArtist .Year .Genre .TrackNumber .DiscNumber .ReleaseDate .Composer .Lyricist .Label .ISRC .Platform .TrackID .Quality .Codec .Ext .SampleRate .BitDepth .Bitrate .Size .Duration .URL .AlbumURL"
+
+const templateCaptionFields = ".TitleLink .ArtistLinks .AlbumLink .AlbumLabel .Info .Hashtags .Bot"
+
+const templateFuncs = "pad upper lower trim replace truncate default khz kbps mb"
+
+// templateDisplayLimit bounds how much of each template the submenu shows.
+const templateDisplayLimit = 400
+
+// resolveTemplateSettings returns the scope's stored templates.
+func resolveTemplateSettings(chatType string, settings *botpkg.UserSettings, groupSettings *botpkg.GroupSettings) (filename, caption string, tags map[string]string) {
+	if chatType != "private" {
+		if groupSettings != nil {
+			return groupSettings.FilenameTemplate, groupSettings.CaptionTemplate, groupSettings.TagTemplates
+		}
+		return "", "", nil
+	}
+	if settings != nil {
+		return settings.FilenameTemplate, settings.CaptionTemplate, settings.TagTemplates
+	}
+	return "", "", nil
+}
+
+// templateFieldNames is the field list shown in usage hints.
+func templateFieldNames() string {
+	return strings.Join(append([]string{templateFieldFilename, templateFieldCaption}, render.TagFields...), ", ")
+}
+
+// validateTemplate checks src for field before it is saved.
+func validateTemplate(field, src string) error {
+	switch field {
+	case templateFieldFilename:
+		return render.ValidateFilename(src)
+	case templateFieldCaption:
+		return render.ValidateCaption(src)
+	default:
+		return render.ValidateTag(field, src)
+	}
+}
+
+// setTemplate stores src (empty resets) for field in the scope's settings
+// and reports whether anything changed.
+func setTemplate(chatType string, settings *botpkg.UserSettings, groupSettings *botpkg.GroupSettings, field, src string) bool {
+	var filename, caption *string
+	var tags *map[string]string
+	if chatType != "private" {
+		if groupSettings == nil {
+			return false
+		}
+		filename, caption, tags = &groupSettings.FilenameTemplate, &groupSettings.CaptionTemplate, &groupSettings.TagTemplates
+	} else {
+		if settings == nil {
+			return false
+		}
+		filename, caption, tags = &settings.FilenameTemplate, &settings.CaptionTemplate, &settings.TagTemplates
+	}
+	switch field {
+	case templateFieldFilename:
+		if *filename == src {
+			return false
+		}
+		*filename = src
+	case templateFieldCaption:
+		if *caption == src {
+			return false
+		}
+		*caption = src
+	case templateFieldTags:
+		if len(*tags) == 0 {
+			return false
+		}
+		*tags = nil
+	default:
+		if !render.IsTagField(field) || (*tags)[field] == src {
+			return false
+		}
+		next := make(map[string]string, len(*tags)+1)
+		for k, v := range *tags {
+			next[k] = v
+		}
+		if src == "" {
+			delete(next, field)
+		} else {
+			next[field] = src
+		}
+		*tags = next
+	}
+	return true
+}
+
+// templatesSummary is the one-word state shown on the main settings view.
+func (h *SettingsHandler) templatesSummary(ctx context.Context, chatType string, settings *botpkg.UserSettings, groupSettings *botpkg.GroupSettings) string {
+	filename, caption, tags := resolveTemplateSettings(chatType, settings, groupSettings)
+	if newChatTemplates(filename, caption, tags, coverEmbedDefault) == nil {
+		return tr(ctx, "set_tpl_default")
+	}
+	return tr(ctx, "set_tpl_custom")
+}
+
+// buildTemplateMenuText lists the scope's templates and how to edit them.
+func (h *SettingsHandler) buildTemplateMenuText(ctx context.Context, chatType string, settings *botpkg.UserSettings, groupSettings *botpkg.GroupSettings) string {
+	filename, caption, tags := resolveTemplateSettings(chatType, settings, groupSettings)
+	show := func(src string) string {
+		if strings.TrimSpace(src) == "" {
+			return tr(ctx, "set_tpl_default")
+		}
+		return truncateText(src, templateDisplayLimit)
+	}
+	var sb strings.Builder
+	sb.WriteString("📝 " + tr(ctx, "set_tpl_menu_title") + "\n\n")
+	sb.WriteString(fmt.Sprintf("%s：%s\n", tr(ctx, "set_tpl_filename"), show(filename)))
+	sb.WriteString(fmt.Sprintf("%s：%s\n", tr(ctx, "set_tpl_caption"), show(caption)))
+	sb.WriteString(tr(ctx, "set_tpl_tags") + "：")
+	custom := false
+	for _, field := range render.TagFields {
+		if src := strings.TrimSpace(tags[field]); src != "" {
+			sb.WriteString(fmt.Sprintf("\n  %s = %s", field, truncateText(src, templateDisplayLimit)))
+			custom = true
+		}
+	}
+	if !custom {
+		sb.WriteString(tr(ctx, "set_tpl_default"))
+	}
+	sb.WriteString("\n\n" + tr(ctx, "set_tpl_menu_hint", map[string]any{
+		"Fields":        templateFieldNames(),
+		"Example":       `{{.AlbumArtist}} - {{.Album}} - {{pad 2 .TrackNumber}} - {{.Title}}`,
+		"Data":          templateDataFields,
+		"CaptionFields": templateCaptionFields,
+		"Funcs":         templateFuncs,
+	}))
+	return sb.String()
+}
+
+// buildTemplateMenuKeyboard offers a reset button per customised group of
+// templates plus a back button.
+func (h *SettingsHandler) buildTemplateMenuKeyboard(ctx context.Context, chatType string, settings *botpkg.UserSettings, groupSettings *botpkg.GroupSettings) *telego.InlineKeyboardMarkup {
+	filename, caption, tags := resolveTemplateSettings(chatType, settings, groupSettings)
+	var row []telego.InlineKeyboardButton
+	reset := func(field, label string) {
+		row = append(row, telego.InlineKeyboardButton{
+			Text:         "♻️ " + tr(ctx, "set_tpl_reset_btn", map[string]any{"Field": label}),
+			CallbackData: "settings tplreset " + field,
+		})
+	}
+	if strings.TrimSpace(filename) != "" {
+		reset(templateFieldFilename, tr(ctx, "set_tpl_filename"))
+	}
+	if strings.TrimSpace(caption) != "" {
+		reset(templateFieldCaption, tr(ctx, "set_tpl_caption"))
+	}
+	if len(tags) > 0 {
+		reset(templateFieldTags, tr(ctx, "set_tpl_tags"))
+	}
+	var rows [][]telego.InlineKeyboardButton
+	if len(row) > 0 {
+		rows = append(rows, row)
+	}
+	rows = append(rows, []telego.InlineKeyboardButton{{Text: "⬅️ " + tr(ctx, "set_btn_back"), CallbackData: "settings tplback"}})
+	return &telego.InlineKeyboardMarkup{InlineKeyboard: rows}
+}
+
+// templateFieldLabel names field in replies.
+func templateFieldLabel(ctx context.Context, field string) string {
+	switch field {
+	case templateFieldFilename:
+		return tr(ctx, "set_tpl_filename")
+	case templateFieldCaption:
+		return tr(ctx, "set_tpl_caption")
+	case templateFieldTags:
+		return tr(ctx, "set_tpl_tags")
+	default:
+		return field
+	}
+}
+
+// parseTemplateCommand splits "/settings template <field> <template>"
+// arguments. The template keeps its inner spacing and newlines.
+func parseTemplateCommand(args string) (field, src string, ok bool) {
+	verb, rest, _ := strings.Cut(strings.TrimSpace(args), " ")
+	if !strings.EqualFold(verb, "template") && !strings.EqualFold(verb, "tpl") {
+		return "", "", false
+	}
+	rest = strings.TrimLeft(rest, " ")
+	if i := strings.IndexAny(rest, " \n"); i >= 0 {
+		field, src = rest[:i], strings.TrimSpace(rest[i+1:])
+	} else {
+		field = rest
+	}
+	return strings.ToLower(field), src, true
+}
+
+// handleTemplateCommand saves or resets one template from
+// "/settings template <field> <template|reset>" and replies with the result.
+func (h *SettingsHandler) handleTemplateCommand(ctx context.Context, b *telego.Bot, message *telego.Message, settings *botpkg.UserSettings, groupSettings *botpkg.GroupSettings, field, src string) {
+	chatType := string(message.Chat.Type)
+	known := field == templateFieldFilename || field == templateFieldCaption || render.IsTagField(field) || (field == templateFieldTags && strings.EqualFold(src, templateReset))
+	var text string
+	switch {
+	case !known || src == "":
+		text = "❌ " + tr(ctx, "set_tpl_usage", map[string]any{"Fields": templateFieldNames()})
+	case strings.EqualFold(src, templateReset):
+		if setTemplate(chatType, settings, groupSettings, field, "") {
+			if err := h.saveTemplateSettings(ctx, chatType, settings, groupSettings); err != nil {
+				text = "❌ " + tr(ctx, "set_err_save")
+				break
+			}
+		}
+		text = "✅ " + tr(ctx, "set_resp_tpl_reset", map[string]any{"Field": templateFieldLabel(ctx, field)})
+	default:
+		if err := validateTemplate(field, src); err != nil {
+			text = "❌ " + tr(ctx, "set_tpl_invalid", map[string]any{"Error": err.Error()})
+			break
+		}
+		if setTemplate(chatType, settings, groupSettings, field, src) {
+			if err := h.saveTemplateSettings(ctx, chatType, settings, groupSettings); err != nil {
+				text = "❌ " + tr(ctx, "set_err_save")
+				break
+			}
+		}
+		text = "✅ " + tr(ctx, "set_resp_tpl_set", map[string]any{"Field": templateFieldLabel(ctx, field)})
+	}
+	params := &telego.SendMessageParams{
+		ChatID:          telego.ChatID{ID: message.Chat.ID},
+		MessageThreadID: message.MessageThreadID,
+		Text:            text,
+		ReplyParameters: &telego.ReplyParameters{MessageID: message.MessageID},
+	}
+	if h.RateLimiter != nil {
+		_, _ = telegram.SendMessageWithRetry(ctx, h.RateLimiter, b, params)
+	} else {
+		_, _ = b.SendMessage(ctx, params)
+	}
+}
+
+func (h *SettingsHandler) saveTemplateSettings(ctx context.Context, chatType string, settings *botpkg.UserSettings, groupSettings *botpkg.GroupSettings) error {
+	if chatType != "private" {
+		return h.Repo.UpdateGroupSettings(ctx, groupSettings)
+	}
+	return h.Repo.UpdateUserSettings(ctx, settings)
+}
@@ -0,0 +1,77 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	botpkg "github.com/liuran001/MusicBot-Go/bot"
+	"github.com/liuran001/MusicBot-Go/bot/spectrum"
+)
+
+// analyzeSpectrum checks files advertised as lossless or Hi-Res for a lossy or
+// upsampled source and records the verdict on songInfo. The cached Quality is
+// left alone so lookups by requested quality keep hitting; only the label in
+// the caption is downgraded (see advertisedQuality).
+func (h *MusicHandler) analyzeSpectrum(ctx context.Context, filePath string, songInfo *botpkg.SongInfo, trackID string) {
+	if h == nil || !h.SpectralAnalysis || songInfo == nil {
+		return
+	}
+	switch strings.ToLower(strings.TrimSpace(songInfo.Quality)) {
+	case "lossless", "hires":
+	default:
+		return
+	}
+	report, err := spectrum.Analyze(ctx, filePath)
+	if err != nil {
+		if h.Logger != nil && ctx.Err() == nil && !errors.Is(err, spectrum.ErrNoSignal) && !errors.Is(err, spectrum.ErrLossyCodec) && !errors.Is(err, spectrum.ErrUnsupported) {
+			h.Logger.Warn("failed to analyse spectrum", "platform", songInfo.Platform, "trackID", trackID, "error", err)
+		}
+		return
+	}
+	songInfo.SpectralVerdict = string(report.Verdict)
+	songInfo.SpectralCutoff = report.CutoffHz
+	songInfo.EffectiveBits = report.EffectiveBits
+	if report.Verdict != spectrum.Genuine && h.Logger != nil {
+		h.Logger.Warn("spectral analysis flagged prepared audio",
+			"platform", songInfo.Platform,
+			"trackID", trackID,
+			"quality", songInfo.Quality,
+			"verdict", report.Verdict,
+			"cutoff_hz", report.CutoffHz,
+			"sample_rate", report.SampleRate,
+			"declared_bits", report.DeclaredBits,
+			"effective_bits", report.EffectiveBits)
+	}
+}
+
+// advertisedQuality is the quality shown to users: a lossy source drops to
+// "high" and fake Hi-Res to "lossless".
+func advertisedQuality(songInfo *botpkg.SongInfo) string {
+	quality := songInfo.Quality
+	switch spectrum.Verdict(songInfo.SpectralVerdict) {
+	case spectrum.LossySource:
+		if q := strings.ToLower(quality); q == "lossless" || q == "hires" {
+			return "high"
+		}
+	case spectrum.Upsampled, spectrum.PaddedBits:
+		if strings.EqualFold(quality, "hires") {
+			return "lossless"
+		}
+	}
+	return quality
+}
+
+// spectralVerdictTag is the caption hashtag explaining a downgrade, or "".
+func spectralVerdictTag(ctx context.Context, verdict string) string {
+	switch spectrum.Verdict(verdict) {
+	case spectrum.LossySource:
+		return tr(ctx, "quality_tag_lossy_source")
+	case spectrum.Upsampled:
+		return tr(ctx, "quality_tag_upsampled")
+	case spectrum.PaddedBits:
+		return tr(ctx, "quality_tag_padded_bits")
+	default:
+		return ""
+	}
+}
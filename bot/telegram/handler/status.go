@@ -6,8 +6,10 @@ import (
 	"html"
 	"sort"
 	"strings"
+	"time"
 
 	botpkg "github.com/liuran001/MusicBot-Go/bot"
+	"github.com/liuran001/MusicBot-Go/bot/credential"
 	"github.com/liuran001/MusicBot-Go/bot/platform"
 	"github.com/liuran001/MusicBot-Go/bot/telegram"
 	"github.com/mymmrac/telego"
@@ -141,7 +143,15 @@ func (h *StatusHandler) buildAccountStatusSection(ctx context.Context, detailed
 	statuses := make([]platform.AccountStatus, 0, len(platforms))
 	for _, name := range platforms {
 		plat := h.PlatformManager.Get(name)
-		provider, ok := plat.(platform.AccountStatusProvider)
+		if reporter, ok := plat.(platform.AccountPoolReporter); ok {
+			displayName := platformDisplayName(ctx, h.PlatformManager, name)
+			for _, status := range reporter.PoolStatus(ctx) {
+				status.DisplayName = displayName + " · " + status.Account
+				statuses = append(statuses, status)
+			}
+			continue
+		}
+		provider, ok := platform.As[platform.AccountStatusProvider](plat)
 		if !ok {
 			continue
 		}
@@ -183,6 +193,10 @@ func renderSafeAccountStatuses(ctx context.Context, statuses []platform.AccountS
 		} else if strings.TrimSpace(status.Summary) != "" {
 			state = classifySafeStatus(ctx, status)
 		}
+		if status.Pool != nil && status.Pool.Quarantined(time.Now()) {
+			icon = "⏸️"
+			state = tr(ctx, "status_state_quarantined", map[string]any{"Until": status.Pool.QuarantinedUntil.Format("15:04")})
+		}
 		lines = append(lines, fmt.Sprintf("%s %s：%s", icon, status.DisplayName, state))
 	}
 	return fmt.Sprintf("%s：%d/%d\n%s", tr(ctx, "status_logged_in"), available, len(statuses), strings.Join(lines, "\n"))
@@ -215,6 +229,9 @@ func renderDetailedAccountStatusesHTML(ctx context.Context, statuses []platform.
 		if strings.TrimSpace(status.SessionSource) != "" {
 			detailLines = append(detailLines, tr(ctx, "status_field_source")+": "+strings.TrimSpace(status.SessionSource))
 		}
+		if status.Pool != nil {
+			detailLines = append(detailLines, poolStatusLines(ctx, *status.Pool)...)
+		}
 		if strings.TrimSpace(status.Summary) != "" {
 			for _, line := range strings.Split(strings.TrimSpace(status.Summary), "\n") {
 				trimmed := strings.TrimSpace(strings.TrimPrefix(line, "- "))
@@ -226,13 +243,33 @@ func renderDetailedAccountStatusesHTML(ctx context.Context, statuses []platform.
 		}
 		escapedLines := make([]string, 0, len(detailLines))
 		for _, line := range detailLines {
-			escapedLines = append(escapedLines, html.EscapeString(strings.TrimSpace(line)))
+			// Plugins put free text in Summary; never let a cookie or token through.
+			escapedLines = append(escapedLines, html.EscapeString(credential.RedactText(strings.TrimSpace(line))))
 		}
 		blocks = append(blocks, header+"\n<blockquote expandable>"+strings.Join(escapedLines, "\n")+"</blockquote>")
 	}
 	return strings.Join(blocks, "\n")
 }
 
+// poolStatusLines describes one account of a multi-account platform.
+func poolStatusLines(ctx context.Context, health platform.PoolAccountHealth) []string {
+	vip := tr(ctx, "status_pool_vip_no")
+	if health.VIP {
+		vip = tr(ctx, "status_pool_vip_yes")
+	}
+	lines := []string{
+		tr(ctx, "status_field_pool", map[string]any{"Strategy": string(health.Strategy), "VIP": vip}),
+		tr(ctx, "status_field_pool_requests", map[string]any{"Requests": health.Requests, "Failures": health.Failures}),
+	}
+	if health.Quarantined(time.Now()) {
+		lines = append(lines, tr(ctx, "status_field_pool_quarantine", map[string]any{"Until": health.QuarantinedUntil.Format("2006-01-02 15:04:05")}))
+	}
+	if strings.TrimSpace(health.LastError) != "" {
+		lines = append(lines, tr(ctx, "status_field_pool_error", map[string]any{"Err": health.LastError}))
+	}
+	return lines
+}
+
 // buildStatusInfoMarkdown renders the cache summary block as MarkdownV2. Labels
 // come from the catalog; the structural markdown lives here.
 func buildStatusInfoMarkdown(ctx context.Context, fromCount int64, chatInfo string, chatCount int64, userID int64, userCount int64, sendCount int64) string {
@@ -0,0 +1,331 @@
+package handler
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	botpkg "github.com/liuran001/MusicBot-Go/bot"
+	"github.com/liuran001/MusicBot-Go/bot/id3"
+	"github.com/liuran001/MusicBot-Go/bot/platform"
+	"github.com/liuran001/MusicBot-Go/bot/render"
+	"github.com/mymmrac/telego"
+)
+
+// chatTemplates are the upload templates of the chat a request came from,
+// plus its embedded-cover policy. Empty fields keep the built-in output.
+type chatTemplates struct {
+	Filename string
+	Caption  string
+	Tags     map[string]string
+	Cover    string
+}
+
+type chatTemplatesKey struct{}
+
+// withChatTemplates attaches a chat's templates to ctx so captions built
+// further down the send path pick them up.
+func withChatTemplates(ctx context.Context, templates *chatTemplates) context.Context {
+	if templates == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, chatTemplatesKey{}, templates)
+}
+
+func chatTemplatesFrom(ctx context.Context) *chatTemplates {
+	if ctx == nil {
+		return nil
+	}
+	templates, _ := ctx.Value(chatTemplatesKey{}).(*chatTemplates)
+	return templates
+}
+
+// newChatTemplates returns nil when nothing is customised.
+func newChatTemplates(filename, caption string, tags map[string]string, cover string) *chatTemplates {
+	templates := &chatTemplates{Filename: strings.TrimSpace(filename), Caption: strings.TrimSpace(caption)}
+	templates.Cover, _ = normalizeCoverEmbed(cover)
+	for field, src := range tags {
+		if src = strings.TrimSpace(src); src != "" && render.IsTagField(field) {
+			if templates.Tags == nil {
+				templates.Tags = make(map[string]string)
+			}
+			templates.Tags[field] = src
+		}
+	}
+	if templates.Filename == "" && templates.Caption == "" && len(templates.Tags) == 0 && templates.Cover == coverEmbedDefault {
+		return nil
+	}
+	return templates
+}
+
+// fileVariant returns a short signature of the templates that change the
+// uploaded file itself, or "" when the file is the shared default. Captions
+// are rendered per send and do not count.
+func (t *chatTemplates) fileVariant() string {
+	if t == nil || (t.Filename == "" && len(t.Tags) == 0 && t.Cover == coverEmbedDefault) {
+		return ""
+	}
+	sum := sha256.New()
+	_, _ = io.WriteString(sum, "filename\x00"+t.Filename+"\x00")
+	if t.Cover != coverEmbedDefault {
+		// Only hashed when set, so signatures from before cover policies
+		// existed stay valid.
+		_, _ = io.WriteString(sum, "cover\x00"+t.Cover+"\x00")
+	}
+	fields := make([]string, 0, len(t.Tags))
+	for field := range t.Tags {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	for _, field := range fields {
+		_, _ = io.WriteString(sum, field+"\x00"+t.Tags[field]+"\x00")
+	}
+	return hex.EncodeToString(sum.Sum(nil))[:8]
+}
+
+// templateCacheVariant is the cache variant of an upload made with custom
+// file templates: the output format plus the template signature, so each
+// distinct set of templates keeps its own Telegram file.
+func templateCacheVariant(format, signature string) string {
+	if signature == "" {
+		return format
+	}
+	format, _, _ = strings.Cut(format, "~")
+	if format == "" {
+		format = outputFormatOriginal
+	}
+	return format + "~" + signature
+}
+
+// resolveChatTemplates returns the templates configured for the chat the
+// request came from: group settings in groups, user settings otherwise.
+func (h *MusicHandler) resolveChatTemplates(ctx context.Context, message *telego.Message, userID int64) *chatTemplates {
+	if h == nil || h.Repo == nil {
+		return nil
+	}
+	if message != nil && message.Chat.Type != "private" {
+		if settings, err := h.Repo.GetGroupSettings(ctx, message.Chat.ID); err == nil && settings != nil {
+			return newChatTemplates(settings.FilenameTemplate, settings.CaptionTemplate, settings.TagTemplates, settings.CoverEmbed)
+		}
+		return nil
+	}
+	if userID != 0 {
+		if settings, err := h.Repo.GetUserSettings(ctx, userID); err == nil && settings != nil {
+			return newChatTemplates(settings.FilenameTemplate, settings.CaptionTemplate, settings.TagTemplates, settings.CoverEmbed)
+		}
+	}
+	return nil
+}
+
+// templateTrack is the template view of a song. track and tagData add what
+// the cache row does not store and may be nil.
+func templateTrack(songInfo *botpkg.SongInfo, track *platform.Track, tagData *id3.TagData) render.Track {
+	var data render.Track
+	if songInfo != nil {
+		codec := songInfo.AudioCodec
+		if strings.TrimSpace(codec) == "" {
+			codec = songInfo.FileExt
+		}
+		data = render.Track{
+			Title:      strings.TrimSpace(songInfo.SongName),
+			Artists:    strings.TrimSpace(songInfo.SongArtists),
+			Album:      strings.TrimSpace(songInfo.SongAlbum),
+			Platform:   songInfo.Platform,
+			TrackID:    songInfo.TrackID,
+			Quality:    songInfo.Quality,
+			Codec:      strings.ToUpper(strings.TrimSpace(codec)),
+			Ext:        songInfo.FileExt,
+			SampleRate: songInfo.SampleRate,
+			BitDepth:   songInfo.BitDepth,
+			Bitrate:    songInfo.BitRate,
+			Size:       int64(songInfo.MusicSize),
+			Duration:   songInfo.Duration,
+			URL:        songInfo.TrackURL,
+			AlbumURL:   songInfo.AlbumURL,
+		}
+	}
+	if track != nil {
+		data.ISRC = track.ISRC
+		data.TrackNumber = track.TrackNumber
+		data.DiscNumber = track.DiscNumber
+		if track.Year > 0 {
+			data.Year = strconv.Itoa(track.Year)
+		}
+		if track.Album != nil {
+			if data.Year == "" && track.Album.Year > 0 {
+				data.Year = strconv.Itoa(track.Album.Year)
+			}
+			names := make([]string, 0, len(track.Album.Artists))
+			for _, artist := range track.Album.Artists {
+				names = append(names, artist.Name)
+			}
+			data.AlbumArtist = strings.Join(names, ", ")
+		}
+	}
+	if tagData != nil {
+		if albumArtist := joinedTagValue(tagData.AlbumArtists, tagData.AlbumArtist); albumArtist != "" {
+			data.AlbumArtist = albumArtist
+		}
+		if tagData.Year != "" {
+			data.Year = tagData.Year
+		}
+		if genre := joinedTagValue(tagData.Genres, tagData.Genre); genre != "" {
+			data.Genre = genre
+		}
+		data.ReleaseDate = tagData.ReleaseDate
+		data.Composer = strings.Join(tagData.Composers, ", ")
+		data.Lyricist = strings.Join(tagData.Lyricists, ", ")
+		data.Label = tagData.Label
+		data.ISRC = tagData.ISRC
+		if tagData.TrackNumber > 0 {
+			data.TrackNumber = tagData.TrackNumber
+		}
+		if tagData.DiscNumber > 0 {
+			data.DiscNumber = tagData.DiscNumber
+		}
+	}
+	return data
+}
+
+// applyTagTemplates returns a copy of tagData with each templated field
+// replaced. A template that fails or renders empty keeps the original value.
+func applyTagTemplates(templates map[string]string, tagData *id3.TagData, data render.Track) *id3.TagData {
+	tagged := *tagData
+	for field, src := range templates {
+		out, err := render.Text(src, data)
+		if out = strings.TrimSpace(out); err != nil || out == "" {
+			continue
+		}
+		switch field {
+		case render.TagTitle:
+			tagged.Title = out
+		case render.TagArtist:
+			tagged.Artist, tagged.Artists = out, nil
+		case render.TagAlbum:
+			tagged.Album = out
+		case render.TagAlbumArtist:
+			tagged.AlbumArtist, tagged.AlbumArtists = out, nil
+		case render.TagDate:
+			tagged.Year, tagged.ReleaseDate = out, ""
+		case render.TagGenre:
+			tagged.Genre, tagged.Genres = out, nil
+		case render.TagComment:
+			tagged.Comment = out
+		case render.TagComposer:
+			tagged.Composers = []string{out}
+		case render.TagLyricist:
+			tagged.Lyricists = []string{out}
+		case render.TagLabel:
+			tagged.Label = out
+		case render.TagISRC:
+			tagged.ISRC = out
+		case render.TagTrack:
+			if n, err := strconv.Atoi(out); err == nil {
+				tagged.TrackNumber = n
+			}
+		case render.TagDisc:
+			if n, err := strconv.Atoi(out); err == nil {
+				tagged.DiscNumber = n
+			}
+		}
+	}
+	return &tagged
+}
+
+// joinedTagValue is a tag as one string: the multi-value form joined, or the
+// single value.
+func joinedTagValue(values []string, single string) string {
+	if len(values) > 0 {
+		return strings.Join(values, ", ")
+	}
+	return single
+}
+
+// defaultUploadFileName is the built-in upload name, render.DefaultFilename
+// plus the extension.
+func defaultUploadFileName(songInfo *botpkg.SongInfo) string {
+	return sanitizeFileName(fmt.Sprintf("%v - %v.%v", strings.ReplaceAll(songInfo.SongArtists, "/", ","), songInfo.SongName, songInfo.FileExt))
+}
+
+// templateFileName renders a filename template; the extension is always the
+// file's own. Failures fall back to the built-in name.
+func templateFileName(src string, data render.Track, songInfo *botpkg.SongInfo) string {
+	if src != "" {
+		if out, err := render.Text(src, data); err == nil && strings.TrimSpace(out) != "" {
+			return sanitizeFileName(strings.TrimSpace(out) + "." + songInfo.FileExt)
+		}
+	}
+	return defaultUploadFileName(songInfo)
+}
+
+// applyFileTemplates gives an upload the chat's filename and tag templates
+// and embeds the cover its policy asks for.
+// musicPath may be the prepared file other requests share, so the result is a
+// copy in a new directory, returned in created for cleanup; when owned the
+// file is moved instead.
+func (h *MusicHandler) applyFileTemplates(ctx context.Context, templates *chatTemplates, plat platform.Platform, track *platform.Track, trackID string, info *platform.DownloadInfo, songInfo *botpkg.SongInfo, musicPath string, owned bool) (string, []string, error) {
+	dir := filepath.Join(filepath.Dir(musicPath), fmt.Sprintf("template-%d", time.Now().UnixNano()))
+	if err := os.Mkdir(dir, 0o755); err != nil {
+		return "", nil, err
+	}
+	created := []string{dir}
+
+	var tagData *id3.TagData
+	if len(templates.Tags) > 0 && h.ID3Service != nil && plat != nil {
+		tagData = h.trackTagData(ctx, plat, track, trackID, info, songInfo, "")
+	}
+	data := templateTrack(songInfo, track, tagData)
+	if tagData != nil {
+		tagData = applyTagTemplates(templates.Tags, tagData, data)
+		data = templateTrack(songInfo, track, tagData)
+	}
+
+	out := filepath.Join(dir, templateFileName(templates.Filename, data, songInfo))
+	if owned {
+		if err := os.Rename(musicPath, out); err != nil {
+			return "", created, err
+		}
+	} else if err := copyFile(musicPath, out); err != nil {
+		return "", created, err
+	}
+	if tagData != nil {
+		// No cover path: the copy keeps the artwork already embedded.
+		h.writeTrackTags(plat, trackID, out, tagData, "")
+		songInfo.MusicSize = int(fileSizeOf(out))
+	}
+	if templates.Cover != coverEmbedDefault && plat != nil {
+		if err := h.applyCoverEmbed(ctx, templates.Cover, plat, track, dir, out, songInfo); err != nil {
+			// The copy still carries the default cover.
+			if h.Logger != nil {
+				h.Logger.Warn("failed to apply cover policy", "platform", plat.Name(), "trackID", trackID, "policy", templates.Cover, "error", downloadErrorForLog(err))
+			}
+		}
+		songInfo.MusicSize = int(fileSizeOf(out))
+	}
+	return out, created, nil
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		_ = out.Close()
+		return err
+	}
+	return out.Close()
+}
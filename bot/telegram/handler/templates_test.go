@@ -0,0 +1,114 @@
+package handler
+
+import (
+	"strings"
+	"testing"
+
+	botpkg "github.com/liuran001/MusicBot-Go/bot"
+	"github.com/liuran001/MusicBot-Go/bot/id3"
+	"github.com/liuran001/MusicBot-Go/bot/platform"
+	"github.com/liuran001/MusicBot-Go/bot/render"
+)
+
+func TestDefaultCaptionTemplateMatchesBuiltIn(t *testing.T) {
+	for _, info := range []*botpkg.SongInfo{
+		{SongName: "Song <1>", SongArtists: "A/B", SongArtistsURLs: "https://a,https://b", SongAlbum: "Album & Co", AlbumURL: "https://album", TrackURL: "https://track", Platform: "netease", Quality: "hires", FileExt: "flac", MusicSize: 30 << 20, BitRate: 1411000, LoudnessLUFS: -9.3, TruePeak: 0.97},
+		{SongName: "Single", SongArtists: "Solo", Quality: "standard", FileExt: "mp3"},
+	} {
+		want := buildMusicCaption(zhCtx(), nil, info, "botname")
+		ctx := withChatTemplates(zhCtx(), &chatTemplates{Caption: render.DefaultCaption})
+		if got := buildMusicCaption(ctx, nil, info, "botname"); got != want {
+			t.Fatalf("default caption template = %q, built-in = %q", got, want)
+		}
+	}
+}
+
+func TestCaptionTemplate(t *testing.T) {
+	info := &botpkg.SongInfo{SongName: "Song", SongArtists: "Artist", Quality: "lossless", FileExt: "flac", AudioCodec: "flac", SampleRate: 96000, BitDepth: 24}
+	ctx := withChatTemplates(zhCtx(), newChatTemplates("", "<b>{{.Title}}</b> {{.Codec}} {{khz .SampleRate}}kHz/{{.BitDepth}}bit", nil, ""))
+	if got := buildMusicCaption(ctx, nil, info, "botname"); got != "<b>Song</b> FLAC 96kHz/24bit" {
+		t.Fatalf("caption = %q", got)
+	}
+	broken := withChatTemplates(zhCtx(), &chatTemplates{Caption: "{{.Missing}}"})
+	if got := buildMusicCaption(broken, nil, info, "botname"); !strings.Contains(got, "via @botname") {
+		t.Fatalf("broken template did not fall back to the built-in caption: %q", got)
+	}
+}
+
+func TestChatTemplatesFileVariant(t *testing.T) {
+	if newChatTemplates(" ", "", map[string]string{"title": " "}, "default") != nil {
+		t.Fatal("blank templates should mean no customisation")
+	}
+	captionOnly := newChatTemplates("", "{{.Title}}", nil, "")
+	if captionOnly.fileVariant() != "" {
+		t.Fatal("a caption template must not fork the cached file")
+	}
+	a := newChatTemplates("{{.Title}}", "", map[string]string{"albumartist": "{{.Artists}}", "bogus": "x"}, "")
+	b := newChatTemplates("{{.Title}}", "", map[string]string{"albumartist": "{{.Artists}}"}, "")
+	if a.fileVariant() == "" || a.fileVariant() != b.fileVariant() {
+		t.Fatalf("fileVariant() = %q / %q", a.fileVariant(), b.fileVariant())
+	}
+	if got := templateCacheVariant("", a.fileVariant()); got != "original~"+a.fileVariant() {
+		t.Fatalf("templateCacheVariant() = %q", got)
+	}
+	if got := templateCacheVariant("mp3_320~0000", "abcd"); got != "mp3_320~abcd" {
+		t.Fatalf("templateCacheVariant() = %q", got)
+	}
+	coverOnly := newChatTemplates("", "", nil, "1400")
+	if coverOnly == nil || coverOnly.fileVariant() == "" {
+		t.Fatal("a cover policy must fork the cached file")
+	}
+	withCover := newChatTemplates("{{.Title}}", "", map[string]string{"albumartist": "{{.Artists}}"}, coverEmbedOriginal)
+	if withCover.fileVariant() == b.fileVariant() || withCover.fileVariant() == coverOnly.fileVariant() {
+		t.Fatalf("cover policies share a signature: %q", withCover.fileVariant())
+	}
+	if newChatTemplates("", "", nil, "bogus") != nil {
+		t.Fatal("an unknown cover policy should mean no customisation")
+	}
+}
+
+func TestFileAndTagTemplates(t *testing.T) {
+	info := &botpkg.SongInfo{SongName: "Title", SongArtists: "A/B", SongAlbum: "Album", FileExt: "flac"}
+	track := &platform.Track{TrackNumber: 1, Album: &platform.Album{Artists: []platform.Artist{{Name: "A"}}}}
+	tags := applyTagTemplates(map[string]string{"albumartist": "{{.Artists}}", "track": "{{.TrackNumber}}0"}, &id3.TagData{Title: "Title", AlbumArtist: "A"}, templateTrack(info, track, nil))
+	if tags.AlbumArtist != "A/B" || tags.TrackNumber != 10 || tags.Title != "Title" {
+		t.Fatalf("applyTagTemplates() = %+v", tags)
+	}
+	data := templateTrack(info, track, tags)
+	if got := templateFileName(`{{.AlbumArtist}} - {{.Album}} - {{pad 2 .TrackNumber}} - {{.Title}}`, data, info); got != "A B - Album - 10 - Title.flac" {
+		t.Fatalf("templateFileName() = %q", got)
+	}
+	if got, want := templateFileName("", data, info), defaultUploadFileName(info); got != want {
+		t.Fatalf("templateFileName(default) = %q, want %q", got, want)
+	}
+	if got, want := templateFileName(render.DefaultFilename, templateTrack(info, nil, nil), info), defaultUploadFileName(info); got != want {
+		t.Fatalf("render.DefaultFilename = %q, built-in = %q", got, want)
+	}
+}
+
+func TestParseTemplateCommand(t *testing.T) {
+	field, src, ok := parseTemplateCommand("template Caption <b>{{.Title}}</b>\n{{.Codec}}")
+	if !ok || field != "caption" || src != "<b>{{.Title}}</b>\n{{.Codec}}" {
+		t.Fatalf("parseTemplateCommand() = %q, %q, %v", field, src, ok)
+	}
+	if _, _, ok := parseTemplateCommand("platform netease"); ok {
+		t.Fatal("parseTemplateCommand() accepted another subcommand")
+	}
+}
+
+func TestSetTemplate(t *testing.T) {
+	group := &botpkg.GroupSettings{}
+	if !setTemplate("group", nil, group, "albumartist", "{{.Artists}}") || group.TagTemplates["albumartist"] != "{{.Artists}}" {
+		t.Fatalf("setTemplate(albumartist) = %+v", group.TagTemplates)
+	}
+	if setTemplate("group", nil, group, "albumartist", "{{.Artists}}") {
+		t.Fatal("setTemplate() reported a change for the same template")
+	}
+	if !setTemplate("group", nil, group, templateFieldTags, "") || len(group.TagTemplates) != 0 {
+		t.Fatalf("resetting tags left %+v", group.TagTemplates)
+	}
+	user := &botpkg.UserSettings{}
+	if !setTemplate("private", user, nil, templateFieldFilename, "{{.Title}}") || user.FilenameTemplate != "{{.Title}}" {
+		t.Fatalf("setTemplate(filename) = %q", user.FilenameTemplate)
+	}
+}
@@ -0,0 +1,818 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	botpkg "github.com/liuran001/MusicBot-Go/bot"
+	"github.com/liuran001/MusicBot-Go/bot/platform"
+	"github.com/liuran001/MusicBot-Go/bot/telegram"
+	"github.com/mymmrac/telego"
+	"github.com/mymmrac/telego/telegoutil"
+)
+
+// Transfer callback data formats (space separated; the job is looked up by the
+// report message, so only the item index travels in the data):
+//
+//	pick:   "trf p <item>"            open the candidate picker of an uncertain item
+//	choose: "trf c <item> <cand|-1>"  accept a candidate, or -1 for "none"
+//	back:   "trf b"                   return to the report
+//	export: "trf e"                   re-send CSV/M3U8 with the manual picks
+
+const (
+	defaultTransferMaxTracks = 200
+	transferJobTTL           = 30 * time.Minute
+	transferJobMaxEntries    = 64
+	transferJobTimeout       = 15 * time.Minute
+	// transferReportTimeout bounds posting the report after the job context
+	// has run out.
+	transferReportTimeout = 30 * time.Second
+	transferSearchLimit   = 8
+	transferSearchTimeout = 15 * time.Second
+	// transferSearchesPerTrack is the most searches matchTransferTrack runs.
+	transferSearchesPerTrack = 2
+	// transferSearchConcurrency keeps a transfer from bursting the target
+	// platform; the whole job is admitted once through ActionTransfer, and
+	// each search then waits for the ActionTransferSearch quota.
+	transferSearchConcurrency = 3
+	transferSearchPacePoll    = time.Second
+	transferProgressInterval  = 5 * time.Second
+	transferCandidates        = 5
+	transferPickButtons       = 20
+	// Score thresholds of matchTransferCandidate, in [0,1].
+	transferMatchedScore   = 0.85
+	transferUncertainScore = 0.5
+)
+
+type transferStatus int
+
+const (
+	transferMissing transferStatus = iota
+	transferUncertain
+	transferMatched
+	// transferUnsearched marks a track the job timed out before searching.
+	transferUnsearched
+)
+
+// transferItem is one source track and its ranked candidates on the target.
+// choice indexes candidates; -1 means no candidate was accepted.
+type transferItem struct {
+	source     platform.Track
+	candidates []transferCandidate
+	status     transferStatus
+	choice     int
+	picked     bool
+}
+
+type transferCandidate struct {
+	track platform.Track
+	score float64
+}
+
+func (item *transferItem) chosen() (transferCandidate, bool) {
+	if item.choice < 0 || item.choice >= len(item.candidates) {
+		return transferCandidate{}, false
+	}
+	return item.candidates[item.choice], true
+}
+
+type transferJob struct {
+	requesterID    int64
+	sourcePlatform string
+	targetPlatform string
+	playlist       platform.Playlist
+	items          []transferItem
+	truncated      bool
+	updatedAt      time.Time
+}
+
+type transferJobKey struct {
+	chatID    int64
+	messageID int
+}
+
+// TransferHandler serves /transfer: it loads a playlist from one platform and
+// matches every track on another, producing a matched/uncertain/missing report
+// with CSV and M3U8 exports and a picker for uncertain matches.
+type TransferHandler struct {
+	PlatformManager platform.Manager
+	RateLimiter     *telegram.RateLimiter
+	ResourceLimiter *ResourceRateLimiter
+	MaxTracks       int
+	Logger          botpkg.Logger
+
+	mu      sync.Mutex
+	jobs    map[transferJobKey]*transferJob
+	running map[int64]bool
+}
+
+func (h *TransferHandler) maxTracks() int {
+	if h == nil || h.MaxTracks <= 0 {
+		return defaultTransferMaxTracks
+	}
+	return h.MaxTracks
+}
+
+// trackBudget caps the tracks of one job at what the target platform's
+// ActionTransferSearch quota can search within transferJobTimeout.
+func (h *TransferHandler) trackBudget(targetName string) int {
+	limit := h.maxTracks()
+	if capacity := h.ResourceLimiter.Capacity(ActionTransferSearch, targetName, transferJobTimeout); capacity >= 0 {
+		limit = min(limit, max(capacity/transferSearchesPerTrack, 1))
+	}
+	return limit
+}
+
+// Handle serves "/transfer <playlist link> <target platform>".
+func (h *TransferHandler) Handle(ctx context.Context, b *telego.Bot, update *telego.Update) {
+	if update == nil || update.Message == nil {
+		return
+	}
+	message := update.Message
+	var userID int64
+	if message.From != nil {
+		userID = message.From.ID
+	}
+	link, target := splitTransferArgs(commandArguments(message.Text))
+	if link == "" || target == "" {
+		sendText(ctx, b, message.Chat.ID, message.MessageID, tr(ctx, "trf_usage"))
+		return
+	}
+	targetName, ok := resolvePlatformAlias(h.PlatformManager, target)
+	if !ok {
+		targetName = strings.ToLower(target)
+	}
+	targetPlat := h.PlatformManager.Get(targetName)
+	if targetPlat == nil || !targetPlat.SupportsSearch() {
+		sendText(ctx, b, message.Chat.ID, message.MessageID, tr(ctx, "trf_target_invalid", map[string]any{"Platform": target}))
+		return
+	}
+	sourceName, playlistID, ok := matchPlaylistURL(ctx, h.PlatformManager, link)
+	if !ok {
+		sendText(ctx, b, message.Chat.ID, message.MessageID, tr(ctx, "trf_usage"))
+		return
+	}
+	if sourceName == targetName {
+		sendText(ctx, b, message.Chat.ID, message.MessageID, tr(ctx, "trf_same_platform"))
+		return
+	}
+	if !h.ResourceLimiter.AllowFor(ActionTransfer, userID, message.Chat.ID, targetName) {
+		sendText(ctx, b, message.Chat.ID, message.MessageID, tr(ctx, "err_rate_limited"))
+		return
+	}
+	if !h.beginUserJob(userID) {
+		sendText(ctx, b, message.Chat.ID, message.MessageID, tr(ctx, "trf_busy"))
+		return
+	}
+
+	status, err := sendStatusMessage(ctx, b, h.RateLimiter, message.Chat.ID, message.MessageThreadID, buildReplyParams(message), tr(ctx, "trf_loading"))
+	if err != nil || status == nil {
+		h.endUserJob(userID)
+		return
+	}
+	go func() {
+		defer h.endUserJob(userID)
+		jobCtx, cancel := context.WithTimeout(detachContext(ctx), transferJobTimeout)
+		defer cancel()
+		h.run(jobCtx, b, status, userID, sourceName, playlistID, targetName)
+	}()
+}
+
+// splitTransferArgs takes the trailing token as the target platform.
+func splitTransferArgs(args string) (link, target string) {
+	fields := strings.Fields(args)
+	if len(fields) < 2 {
+		return "", ""
+	}
+	return strings.Join(fields[:len(fields)-1], " "), fields[len(fields)-1]
+}
+
+func (h *TransferHandler) beginUserJob(userID int64) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.running == nil {
+		h.running = make(map[int64]bool)
+	}
+	if h.running[userID] {
+		return false
+	}
+	h.running[userID] = true
+	return true
+}
+
+func (h *TransferHandler) endUserJob(userID int64) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.running, userID)
+}
+
+func (h *TransferHandler) run(ctx context.Context, b *telego.Bot, status *telego.Message, userID int64, sourceName, playlistID, targetName string) {
+	sourcePlat := h.PlatformManager.Get(sourceName)
+	targetPlat := h.PlatformManager.Get(targetName)
+	if sourcePlat == nil || targetPlat == nil {
+		h.editStatus(ctx, b, status, tr(ctx, "trf_failed"), nil)
+		return
+	}
+	limit := h.trackBudget(targetName)
+	playlist, tracks, err := fetchPlaylistSnapshot(ctx, sourcePlat, playlistID, limit+1)
+	if err != nil {
+		h.editStatus(ctx, b, status, userVisiblePlaylistError(ctx, err), nil)
+		return
+	}
+	if len(tracks) == 0 {
+		h.editStatus(ctx, b, status, tr(ctx, "trf_empty"), nil)
+		return
+	}
+	job := &transferJob{
+		requesterID:    userID,
+		sourcePlatform: sourceName,
+		targetPlatform: targetName,
+		playlist:       *playlist,
+	}
+	if len(tracks) > limit {
+		tracks = tracks[:limit]
+		job.truncated = true
+	}
+	job.playlist.Tracks = nil
+
+	var done int
+	var progressMu sync.Mutex
+	lastProgress := time.Now()
+	job.items = matchTransferTracks(ctx, h.ResourceLimiter, targetPlat, tracks, func() {
+		progressMu.Lock()
+		done++
+		current := done
+		due := time.Since(lastProgress) >= transferProgressInterval
+		if due {
+			lastProgress = time.Now()
+		}
+		progressMu.Unlock()
+		if due {
+			h.editStatus(ctx, b, status, tr(ctx, "trf_progress", map[string]any{"Done": current, "Total": len(tracks)}), nil)
+		}
+	})
+	// A timed-out job still reports the tracks it got to.
+	ctx, cancel := context.WithTimeout(detachContext(ctx), transferReportTimeout)
+	defer cancel()
+	if _, _, _, unsearched := transferCounts(job); unsearched == len(job.items) {
+		h.editStatus(ctx, b, status, tr(ctx, "trf_failed"), nil)
+		return
+	}
+
+	h.storeJob(status.Chat.ID, status.MessageID, job)
+	text, keyboard := h.renderReport(ctx, job)
+	h.editStatus(ctx, b, status, text, keyboard)
+	h.sendExports(ctx, b, status, transferExports(job))
+}
+
+// matchTransferTracks searches every source track on the target platform with
+// bounded concurrency. onDone is called after each track. Tracks whose
+// searches did not finish before ctx ended are marked transferUnsearched.
+func matchTransferTracks(ctx context.Context, limiter *ResourceRateLimiter, target platform.Platform, tracks []platform.Track, onDone func()) []transferItem {
+	items := make([]transferItem, len(tracks))
+	sem := make(chan struct{}, transferSearchConcurrency)
+	var wg sync.WaitGroup
+	for idx := range tracks {
+		if ctx.Err() != nil {
+			items[idx] = transferItem{source: tracks[idx], status: transferUnsearched, choice: -1}
+			continue
+		}
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(idx int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			item, complete := matchTransferTrack(ctx, limiter, target, tracks[idx])
+			if !complete {
+				item = transferItem{source: tracks[idx], status: transferUnsearched, choice: -1}
+			}
+			items[idx] = item
+			if onDone != nil {
+				onDone()
+			}
+		}(idx)
+	}
+	wg.Wait()
+	return items
+}
+
+// matchTransferTrack searches "title artist" first and falls back to the bare
+// title when that finds no confident match, since artist names are often
+// transliterated differently across platforms. Every search is metered as an
+// ActionTransferSearch on the target platform, waiting for quota when it is
+// spent. complete is false when ctx ended before the searches finished.
+func matchTransferTrack(ctx context.Context, limiter *ResourceRateLimiter, target platform.Platform, source platform.Track) (item transferItem, complete bool) {
+	item = transferItem{source: source, choice: -1}
+	queries := []string{strings.TrimSpace(source.Title)}
+	if len(source.Artists) > 0 {
+		queries = []string{strings.TrimSpace(source.Title + " " + source.Artists[0].Name), strings.TrimSpace(source.Title)}
+	}
+	seen := make(map[string]bool)
+	for _, query := range queries {
+		if query == "" {
+			continue
+		}
+		if limiter.WaitFor(ctx, ActionTransferSearch, 0, 0, target.Name(), transferSearchPacePoll) != nil {
+			return item, false
+		}
+		searchCtx, cancel := context.WithTimeout(ctx, transferSearchTimeout)
+		results, err := target.Search(searchCtx, query, transferSearchLimit)
+		cancel()
+		if ctx.Err() != nil {
+			return item, false
+		}
+		if err != nil {
+			continue
+		}
+		for _, candidate := range results {
+			id := strings.TrimSpace(candidate.ID)
+			if id == "" || seen[id] {
+				continue
+			}
+			seen[id] = true
+			item.candidates = append(item.candidates, transferCandidate{track: candidate, score: matchTransferCandidate(source, candidate)})
+		}
+		sort.SliceStable(item.candidates, func(i, j int) bool { return item.candidates[i].score > item.candidates[j].score })
+		if len(item.candidates) > 0 && item.candidates[0].score >= transferMatchedScore {
+			break
+		}
+	}
+	if len(item.candidates) > transferCandidates {
+		item.candidates = item.candidates[:transferCandidates]
+	}
+	if len(item.candidates) > 0 {
+		best := item.candidates[0].score
+		switch {
+		case best >= transferMatchedScore:
+			item.status, item.choice = transferMatched, 0
+		case best >= transferUncertainScore:
+			item.status, item.choice = transferUncertain, 0
+		}
+	}
+	return item, true
+}
+
+// matchTransferCandidate scores how likely candidate is the same recording as
+// source: title 50%, artist 25%, duration 25%. Equal ISRCs are conclusive;
+// differing ISRCs usually mean another release of the song (remaster, live,
+// compilation), so the score is damped below the matched threshold.
+func matchTransferCandidate(source, candidate platform.Track) float64 {
+	isrcA := strings.ToUpper(strings.TrimSpace(source.ISRC))
+	isrcB := strings.ToUpper(strings.TrimSpace(candidate.ISRC))
+	if isrcA != "" && isrcA == isrcB {
+		return 1
+	}
+
+	titleScore := 0.0
+	titleA, titleB := normalizeSearchText(source.Title), normalizeSearchText(candidate.Title)
+	baseA, baseB := normalizeSearchText(stripTitleDecorations(source.Title)), normalizeSearchText(stripTitleDecorations(candidate.Title))
+	switch {
+	case titleA == "" || titleB == "":
+	case titleA == titleB:
+		titleScore = 1
+	case baseA != "" && baseA == baseB:
+		titleScore = 0.7
+	case strings.Contains(titleA, titleB) || strings.Contains(titleB, titleA):
+		titleScore = 0.5
+	}
+
+	artistScore := 0.5
+	if len(source.Artists) > 0 && len(candidate.Artists) > 0 {
+		artistScore = 0
+	outer:
+		for _, a := range source.Artists {
+			nameA := normalizeSearchText(a.Name)
+			if nameA == "" {
+				continue
+			}
+			for _, c := range candidate.Artists {
+				nameB := normalizeSearchText(c.Name)
+				if nameB != "" && (nameA == nameB || strings.Contains(nameA, nameB) || strings.Contains(nameB, nameA)) {
+					artistScore = 1
+					break outer
+				}
+			}
+		}
+	}
+
+	durationScore := 0.5
+	if source.Duration > 0 && candidate.Duration > 0 {
+		diff := source.Duration - candidate.Duration
+		if diff < 0 {
+			diff = -diff
+		}
+		switch {
+		case diff <= aggregateDurationTolerance:
+			durationScore = 1
+		case diff <= 10*time.Second:
+			durationScore = 0.6
+		case diff <= 30*time.Second:
+			durationScore = 0.2
+		default:
+			durationScore = 0
+		}
+	}
+
+	score := 0.5*titleScore + 0.25*artistScore + 0.25*durationScore
+	if isrcA != "" && isrcB != "" && score >= transferMatchedScore {
+		score = transferMatchedScore - 0.05
+	}
+	return score
+}
+
+// stripTitleDecorations drops bracketed suffixes such as "(Live)", "[Remix]" or
+// "（伴奏）" and anything after " - ".
+func stripTitleDecorations(title string) string {
+	if idx := strings.Index(title, " - "); idx > 0 {
+		title = title[:idx]
+	}
+	var builder strings.Builder
+	depth := 0
+	for _, r := range title {
+		switch r {
+		case '(', '[', '（', '【', '「':
+			depth++
+			continue
+		case ')', ']', '）', '】', '」':
+			if depth > 0 {
+				depth--
+			}
+			continue
+		}
+		if depth == 0 {
+			builder.WriteRune(r)
+		}
+	}
+	return builder.String()
+}
+
+func (h *TransferHandler) storeJob(chatID int64, messageID int, job *transferJob) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.jobs == nil {
+		h.jobs = make(map[transferJobKey]*transferJob)
+	}
+	job.updatedAt = time.Now()
+	h.jobs[transferJobKey{chatID: chatID, messageID: messageID}] = job
+	cutoff := time.Now().Add(-transferJobTTL)
+	for key, stored := range h.jobs {
+		if stored.updatedAt.Before(cutoff) {
+			delete(h.jobs, key)
+		}
+	}
+	for len(h.jobs) > transferJobMaxEntries {
+		var oldestKey transferJobKey
+		var oldest time.Time
+		for key, stored := range h.jobs {
+			if oldest.IsZero() || stored.updatedAt.Before(oldest) {
+				oldestKey, oldest = key, stored.updatedAt
+			}
+		}
+		delete(h.jobs, oldestKey)
+	}
+}
+
+func (h *TransferHandler) getJob(chatID int64, messageID int) (*transferJob, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	job, ok := h.jobs[transferJobKey{chatID: chatID, messageID: messageID}]
+	if !ok || job.updatedAt.Before(time.Now().Add(-transferJobTTL)) {
+		return nil, false
+	}
+	job.updatedAt = time.Now()
+	return job, true
+}
+
+func transferCounts(job *transferJob) (matched, uncertain, missing, unsearched int) {
+	for idx := range job.items {
+		switch job.items[idx].status {
+		case transferMatched:
+			matched++
+		case transferUncertain:
+			uncertain++
+		case transferUnsearched:
+			unsearched++
+		default:
+			missing++
+		}
+	}
+	return matched, uncertain, missing, unsearched
+}
+
+func transferTrackLabel(track platform.Track) string {
+	label := truncateText(track.Title, 40)
+	if artists := inlineArtistsLabel(track.Artists); artists != "" {
+		label += " - " + truncateText(artists, 28)
+	}
+	return label
+}
+
+func transferDuration(d time.Duration) string {
+	if d <= 0 {
+		return "?"
+	}
+	seconds := int(d.Round(time.Second) / time.Second)
+	return fmt.Sprintf("%d:%02d", seconds/60, seconds%60)
+}
+
+// renderReport lists uncertain and missing tracks by their playlist position;
+// matched ones are only counted and appear in the exports.
+func (h *TransferHandler) renderReport(ctx context.Context, job *transferJob) (string, *telego.InlineKeyboardMarkup) {
+	matched, uncertain, missing, unsearched := transferCounts(job)
+	lines := []string{
+		fmt.Sprintf("🔁 %s → %s: %s",
+			platformDisplayName(ctx, h.PlatformManager, job.sourcePlatform),
+			platformDisplayName(ctx, h.PlatformManager, job.targetPlatform),
+			job.playlist.Title),
+		tr(ctx, "trf_report_counts", map[string]any{"Matched": matched, "Uncertain": uncertain, "Missing": missing}),
+	}
+	if job.truncated {
+		lines = append(lines, tr(ctx, "trf_truncated", map[string]any{"Limit": len(job.items)}))
+	}
+	if unsearched > 0 {
+		lines = append(lines, tr(ctx, "trf_timed_out", map[string]any{"Count": unsearched}))
+	}
+	var uncertainLines, missingLines []string
+	var buttons []telego.InlineKeyboardButton
+	for idx := range job.items {
+		item := &job.items[idx]
+		switch item.status {
+		case transferUncertain:
+			line := fmt.Sprintf("%d. %s", idx+1, transferTrackLabel(item.source))
+			if candidate, ok := item.chosen(); ok {
+				line += " → " + transferTrackLabel(candidate.track)
+			}
+			uncertainLines = append(uncertainLines, line)
+			if len(buttons) < transferPickButtons {
+				buttons = append(buttons, telego.InlineKeyboardButton{Text: fmt.Sprintf("%d", idx+1), CallbackData: fmt.Sprintf("trf p %d", idx)})
+			}
+		case transferMissing:
+			missingLines = append(missingLines, fmt.Sprintf("%d. %s", idx+1, transferTrackLabel(item.source)))
+		}
+	}
+	if len(uncertainLines) > 0 {
+		lines = append(lines, "", tr(ctx, "trf_report_uncertain"))
+		lines = append(lines, uncertainLines...)
+	}
+	if len(missingLines) > 0 {
+		lines = append(lines, "", tr(ctx, "trf_report_missing"))
+		lines = append(lines, missingLines...)
+	}
+	if len(buttons) > 0 {
+		lines = append(lines, "", tr(ctx, "trf_report_pick_hint"))
+	}
+	rows := chunkButtons(buttons, artistWatchButtonsPerRow)
+	rows = append(rows, []telego.InlineKeyboardButton{{Text: tr(ctx, "trf_export_button"), CallbackData: "trf e"}})
+	return truncateText(strings.Join(lines, "\n"), 4000), &telego.InlineKeyboardMarkup{InlineKeyboard: rows}
+}
+
+func (h *TransferHandler) renderPicker(ctx context.Context, job *transferJob, idx int) (string, *telego.InlineKeyboardMarkup) {
+	item := &job.items[idx]
+	lines := []string{
+		tr(ctx, "trf_pick_title", map[string]any{"Index": idx + 1}),
+		fmt.Sprintf("%s (%s)", transferTrackLabel(item.source), transferDuration(item.source.Duration)),
+		"",
+	}
+	buttons := make([]telego.InlineKeyboardButton, 0, len(item.candidates))
+	for cidx, candidate := range item.candidates {
+		marker := ""
+		if cidx == item.choice {
+			marker = " ✅"
+		}
+		lines = append(lines, fmt.Sprintf("%d. %s (%s) %d%%%s", cidx+1, transferTrackLabel(candidate.track), transferDuration(candidate.track.Duration), int(candidate.score*100), marker))
+		buttons = append(buttons, telego.InlineKeyboardButton{Text: fmt.Sprintf("%d", cidx+1), CallbackData: fmt.Sprintf("trf c %d %d", idx, cidx)})
+	}
+	rows := chunkButtons(buttons, artistWatchButtonsPerRow)
+	rows = append(rows, []telego.InlineKeyboardButton{
+		{Text: tr(ctx, "trf_pick_none"), CallbackData: fmt.Sprintf("trf c %d -1", idx)},
+		{Text: tr(ctx, "trf_back"), CallbackData: "trf b"},
+	})
+	return truncateText(strings.Join(lines, "\n"), 4000), &telego.InlineKeyboardMarkup{InlineKeyboard: rows}
+}
+
+func (h *TransferHandler) editStatus(ctx context.Context, b *telego.Bot, status *telego.Message, text string, keyboard *telego.InlineKeyboardMarkup) {
+	params := &telego.EditMessageTextParams{
+		ChatID:             telego.ChatID{ID: status.Chat.ID},
+		MessageID:          status.MessageID,
+		Text:               text,
+		LinkPreviewOptions: &telego.LinkPreviewOptions{IsDisabled: true},
+	}
+	if keyboard != nil {
+		params.ReplyMarkup = keyboard
+	}
+	var err error
+	if h.RateLimiter != nil {
+		_, err = telegram.EditMessageTextWithRetry(ctx, h.RateLimiter, b, params)
+	} else {
+		_, err = b.EditMessageText(ctx, params)
+	}
+	if err != nil && h.Logger != nil && !strings.Contains(err.Error(), "message is not modified") {
+		h.Logger.Debug("failed to edit transfer message", "error", err)
+	}
+}
+
+// buildTransferCSV writes one row per source track. Uncertain rows carry the
+// currently chosen candidate so they can be reviewed offline.
+func buildTransferCSV(job *transferJob) []byte {
+	var buf bytes.Buffer
+	// A UTF-8 BOM makes spreadsheet apps detect the encoding of CJK titles.
+	buf.WriteString("\ufeff")
+	writer := csv.NewWriter(&buf)
+	_ = writer.Write([]string{"index", "status", "score", "source_title", "source_artists", "source_url", "target_title", "target_artists", "target_url"})
+	for idx := range job.items {
+		item := &job.items[idx]
+		status := "missing"
+		switch {
+		case item.status == transferMatched && item.picked:
+			status = "picked"
+		case item.status == transferMatched:
+			status = "matched"
+		case item.status == transferUncertain:
+			status = "uncertain"
+		case item.status == transferUnsearched:
+			status = "unsearched"
+		}
+		row := []string{strconv.Itoa(idx + 1), status, "", item.source.Title, inlineArtistsLabel(item.source.Artists), item.source.URL, "", "", ""}
+		if candidate, ok := item.chosen(); ok {
+			row[2] = strconv.FormatFloat(candidate.score, 'f', 2, 64)
+			row[6] = candidate.track.Title
+			row[7] = inlineArtistsLabel(candidate.track.Artists)
+			row[8] = candidate.track.URL
+		}
+		_ = writer.Write(row)
+	}
+	writer.Flush()
+	return buf.Bytes()
+}
+
+// buildTransferM3U8 lists the matched (including manually picked) target
+// tracks in playlist order. Tracks without a URL are kept as comments.
+func buildTransferM3U8(job *transferJob) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("#EXTM3U\n")
+	if title := strings.TrimSpace(job.playlist.Title); title != "" {
+		buf.WriteString("#PLAYLIST:" + title + "\n")
+	}
+	for idx := range job.items {
+		item := &job.items[idx]
+		candidate, ok := item.chosen()
+		if item.status != transferMatched || !ok {
+			continue
+		}
+		track := candidate.track
+		seconds := -1
+		if track.Duration > 0 {
+			seconds = int(track.Duration.Round(time.Second) / time.Second)
+		}
+		name := track.Title
+		if artists := inlineArtistsLabel(track.Artists); artists != "" {
+			name = artists + " - " + name
+		}
+		fmt.Fprintf(&buf, "#EXTINF:%d,%s\n", seconds, name)
+		if url := strings.TrimSpace(track.URL); url != "" {
+			buf.WriteString(url + "\n")
+		} else {
+			fmt.Fprintf(&buf, "# %s:%s\n", job.targetPlatform, track.ID)
+		}
+	}
+	return buf.Bytes()
+}
+
+func transferFileBase(job *transferJob) string {
+	name := "playlist"
+	if title := strings.TrimSpace(job.playlist.Title); title != "" {
+		name = truncateText(sanitizeFileName(title), 60)
+	}
+	return name + "_" + job.targetPlatform
+}
+
+type transferExport struct {
+	name string
+	data []byte
+}
+
+func transferExports(job *transferJob) []transferExport {
+	base := transferFileBase(job)
+	return []transferExport{
+		{name: base + ".csv", data: buildTransferCSV(job)},
+		{name: base + ".m3u8", data: buildTransferM3U8(job)},
+	}
+}
+
+func (h *TransferHandler) sendExports(ctx context.Context, b *telego.Bot, report *telego.Message, files []transferExport) {
+	for _, file := range files {
+		params := &telego.SendDocumentParams{
+			ChatID:          telego.ChatID{ID: report.Chat.ID},
+			MessageThreadID: report.MessageThreadID,
+			Document:        telego.InputFile{File: telegoutil.NameReader(bytes.NewReader(file.data), file.name)},
+			ReplyParameters: &telego.ReplyParameters{MessageID: report.MessageID},
+		}
+		var err error
+		if h.RateLimiter != nil {
+			_, err = telegram.SendDocumentWithRetry(ctx, h.RateLimiter, b, params)
+		} else {
+			_, err = b.SendDocument(ctx, params)
+		}
+		if err != nil && h.Logger != nil {
+			h.Logger.Warn("failed to send transfer export", "file", file.name, "error", err)
+		}
+	}
+}
+
+// TransferCallbackHandler handles "trf ..." callbacks on a transfer report.
+type TransferCallbackHandler struct {
+	Transfer *TransferHandler
+}
+
+func (h *TransferCallbackHandler) Handle(ctx context.Context, b *telego.Bot, update *telego.Update) {
+	if update == nil || update.CallbackQuery == nil || h.Transfer == nil {
+		return
+	}
+	query := update.CallbackQuery
+	parts := strings.Fields(query.Data)
+	if len(parts) < 2 || parts[0] != "trf" || query.Message == nil {
+		return
+	}
+	msg := query.Message.Message()
+	if msg == nil {
+		return
+	}
+	answer := func(text string, alert bool) {
+		_ = b.AnswerCallbackQuery(ctx, &telego.AnswerCallbackQueryParams{CallbackQueryID: query.ID, Text: text, ShowAlert: alert})
+	}
+	job, ok := h.Transfer.getJob(msg.Chat.ID, msg.MessageID)
+	if !ok {
+		answer(tr(ctx, "trf_expired"), true)
+		return
+	}
+	if !isRequesterOrAdmin(ctx, b, msg.Chat.ID, query.From.ID, job.requesterID) {
+		answer(tr(ctx, "cb_denied"), true)
+		return
+	}
+	itemIndex := func(raw string) (int, bool) {
+		idx, err := strconv.Atoi(raw)
+		return idx, err == nil && idx >= 0 && idx < len(job.items)
+	}
+
+	switch parts[1] {
+	case "p":
+		if len(parts) < 3 {
+			return
+		}
+		idx, ok := itemIndex(parts[2])
+		if !ok {
+			return
+		}
+		answer("", false)
+		h.Transfer.mu.Lock()
+		text, keyboard := h.Transfer.renderPicker(ctx, job, idx)
+		h.Transfer.mu.Unlock()
+		h.Transfer.editStatus(ctx, b, msg, text, keyboard)
+	case "c":
+		if len(parts) < 4 {
+			return
+		}
+		idx, ok := itemIndex(parts[2])
+		choice, err := strconv.Atoi(parts[3])
+		if !ok || err != nil || choice >= len(job.items[idx].candidates) {
+			return
+		}
+		h.Transfer.mu.Lock()
+		item := &job.items[idx]
+		if choice < 0 {
+			item.status, item.choice = transferMissing, -1
+		} else {
+			item.status, item.choice = transferMatched, choice
+		}
+		item.picked = true
+		text, keyboard := h.Transfer.renderReport(ctx, job)
+		h.Transfer.mu.Unlock()
+		answer(tr(ctx, "callback_success"), false)
+		h.Transfer.editStatus(ctx, b, msg, text, keyboard)
+	case "b":
+		answer("", false)
+		h.Transfer.mu.Lock()
+		text, keyboard := h.Transfer.renderReport(ctx, job)
+		h.Transfer.mu.Unlock()
+		h.Transfer.editStatus(ctx, b, msg, text, keyboard)
+	case "e":
+		release, acquired := tryAcquireCallbackInFlight(fmt.Sprintf("trf:%d:%d", msg.Chat.ID, msg.MessageID), 10*time.Second)
+		if !acquired {
+			answer(tr(ctx, "callback_success"), false)
+			return
+		}
+		defer release()
+		answer(tr(ctx, "callback_success"), false)
+		h.Transfer.mu.Lock()
+		files := transferExports(job)
+		h.Transfer.mu.Unlock()
+		h.Transfer.sendExports(ctx, b, msg, files)
+	}
+}
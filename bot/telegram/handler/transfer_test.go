@@ -0,0 +1,231 @@
+package handler
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/liuran001/MusicBot-Go/bot/platform"
+)
+
+// stubTransferTarget answers searches from a query → results table and records
+// the queries it saw.
+type stubTransferTarget struct {
+	stubSearchPlatform
+	results map[string][]platform.Track
+	queries *[]string
+}
+
+func (s stubTransferTarget) Search(ctx context.Context, query string, limit int) ([]platform.Track, error) {
+	if s.queries != nil {
+		*s.queries = append(*s.queries, query)
+	}
+	return s.results[query], nil
+}
+
+func transferTrack(id, title, artist string, seconds int) platform.Track {
+	return platform.Track{
+		ID:       id,
+		Title:    title,
+		Artists:  []platform.Artist{{Name: artist}},
+		Duration: time.Duration(seconds) * time.Second,
+		URL:      "https://example.com/track/" + id,
+	}
+}
+
+func TestMatchTransferCandidateScoring(t *testing.T) {
+	source := transferTrack("1", "晴天", "周杰伦", 269)
+	cases := []struct {
+		name      string
+		candidate platform.Track
+		want      transferStatus
+	}{
+		{"exact", transferTrack("a", "晴天", "周杰伦", 270), transferMatched},
+		{"translated artist", transferTrack("b", "晴天", "Jay Chou", 269), transferUncertain},
+		{"live version", transferTrack("c", "晴天 (Live)", "周杰伦", 301), transferUncertain},
+		{"different song", transferTrack("d", "七里香", "周杰伦", 299), transferMissing},
+	}
+	for _, tc := range cases {
+		score := matchTransferCandidate(source, tc.candidate)
+		got := transferMissing
+		switch {
+		case score >= transferMatchedScore:
+			got = transferMatched
+		case score >= transferUncertainScore:
+			got = transferUncertain
+		}
+		if got != tc.want {
+			t.Errorf("%s: score %.2f classified %d, want %d", tc.name, score, got, tc.want)
+		}
+	}
+
+	withISRC := source
+	withISRC.ISRC = "TWK970100001"
+	other := transferTrack("e", "Sunny Day", "Jay Chou", 100)
+	other.ISRC = "twk970100001"
+	if score := matchTransferCandidate(withISRC, other); score != 1 {
+		t.Fatalf("equal ISRC score = %.2f, want 1", score)
+	}
+	exact := transferTrack("f", "晴天", "周杰伦", 269)
+	exact.ISRC = "TWK970100099"
+	if score := matchTransferCandidate(withISRC, exact); score >= transferMatchedScore {
+		t.Fatalf("conflicting ISRC must not auto-match, score %.2f", score)
+	}
+}
+
+func TestStripTitleDecorations(t *testing.T) {
+	cases := map[string]string{
+		"晴天 (Live)":            "晴天 ",
+		"Song [Remix] (Edit)":  "Song  ",
+		"Song - 2011 Remaster": "Song",
+		"告白气球（伴奏）":             "告白气球",
+		"Plain":                "Plain",
+	}
+	for in, want := range cases {
+		if got := stripTitleDecorations(in); got != want {
+			t.Errorf("stripTitleDecorations(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestMatchTransferTrackFallsBackToTitleSearch(t *testing.T) {
+	var queries []string
+	target := stubTransferTarget{
+		stubSearchPlatform: stubSearchPlatform{name: "spotify"},
+		results: map[string][]platform.Track{
+			"晴天 周杰伦": {transferTrack("x", "Other", "Someone", 200)},
+			"晴天":     {transferTrack("y", "晴天", "周杰伦", 269)},
+		},
+		queries: &queries,
+	}
+	item, _ := matchTransferTrack(context.Background(), nil, target, transferTrack("1", "晴天", "周杰伦", 269))
+	if item.status != transferMatched || item.candidates[item.choice].track.ID != "y" {
+		t.Fatalf("item = %+v", item)
+	}
+	if strings.Join(queries, "|") != "晴天 周杰伦|晴天" {
+		t.Fatalf("queries = %v", queries)
+	}
+
+	queries = nil
+	item, complete := matchTransferTrack(context.Background(), nil, target, transferTrack("2", "不存在", "无名", 100))
+	if item.status != transferMissing || item.choice != -1 || !complete {
+		t.Fatalf("missing item = %+v", item)
+	}
+}
+
+func TestMatchTransferTrackMetersSearches(t *testing.T) {
+	var queries []string
+	target := stubTransferTarget{stubSearchPlatform: stubSearchPlatform{name: "spotify"}, queries: &queries}
+	limiter := NewResourceRateLimiter(map[string]ResourceLimit{
+		ActionSearch:         {Window: time.Hour, PerPlatform: 1},
+		ActionTransferSearch: {Window: time.Hour, PerPlatform: 1},
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	// Both queries miss, but the quota only covers the first search.
+	item, complete := matchTransferTrack(ctx, limiter, target, transferTrack("1", "晴天", "周杰伦", 269))
+	if item.status != transferMissing || len(queries) != 1 || complete {
+		t.Fatalf("searches beyond the ActionTransferSearch quota ran: queries = %v, complete = %v", queries, complete)
+	}
+	if limiter.Allow(ActionTransferSearch, 0, "spotify") {
+		t.Fatal("transfer search was not recorded against its platform quota")
+	}
+	if !limiter.Allow(ActionSearch, 0, "spotify") {
+		t.Fatal("transfer search used the interactive search quota")
+	}
+}
+
+func TestMatchTransferTracksMarksUnsearchedOnTimeout(t *testing.T) {
+	target := stubTransferTarget{stubSearchPlatform: stubSearchPlatform{name: "spotify"}}
+	limiter := NewResourceRateLimiter(map[string]ResourceLimit{
+		ActionTransferSearch: {Window: time.Hour, PerPlatform: 3},
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	// Tracks without artists take one search each, so the quota covers three.
+	tracks := make([]platform.Track, 6)
+	for i := range tracks {
+		tracks[i] = platform.Track{ID: strconv.Itoa(i), Title: "song " + strconv.Itoa(i)}
+	}
+	job := &transferJob{items: matchTransferTracks(ctx, limiter, target, tracks, nil)}
+	if _, _, missing, unsearched := transferCounts(job); missing != 3 || unsearched != 3 {
+		t.Fatalf("missing = %d, unsearched = %d; want 3 searched and 3 cut off", missing, unsearched)
+	}
+	for i, item := range job.items {
+		if item.source.ID != strconv.Itoa(i) {
+			t.Fatalf("items[%d] is track %q", i, item.source.ID)
+		}
+	}
+}
+
+func TestTransferTrackBudget(t *testing.T) {
+	h := &TransferHandler{MaxTracks: 200, ResourceLimiter: NewResourceRateLimiter(map[string]ResourceLimit{
+		ActionTransferSearch: {Window: time.Minute, PerPlatform: 10, Global: 20},
+	})}
+	// 10 searches a minute for 15 minutes, two searches per track.
+	if got := h.trackBudget("spotify"); got != 75 {
+		t.Fatalf("trackBudget = %d, want 75", got)
+	}
+	h.ResourceLimiter = nil
+	if got := h.trackBudget("spotify"); got != 200 {
+		t.Fatalf("unlimited trackBudget = %d, want MaxTracks", got)
+	}
+}
+
+func TestTransferExports(t *testing.T) {
+	job := &transferJob{
+		targetPlatform: "spotify",
+		playlist:       platform.Playlist{Title: "Mix"},
+		items: []transferItem{
+			{source: transferTrack("1", "晴天", "周杰伦", 269), status: transferMatched, choice: 0, candidates: []transferCandidate{{track: transferTrack("a", "晴天", "Jay Chou", 269), score: 0.9}}},
+			{source: transferTrack("2", "稻香", "周杰伦", 223), status: transferUncertain, choice: 0, candidates: []transferCandidate{{track: transferTrack("b", "Rice Field", "Jay Chou", 223), score: 0.6}}},
+			{source: transferTrack("3", "不存在", "无名", 100), status: transferMissing, choice: -1},
+		},
+	}
+	csvText := string(buildTransferCSV(job))
+	lines := strings.Split(strings.TrimSpace(strings.TrimPrefix(csvText, "\ufeff")), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("csv lines = %d:\n%s", len(lines), csvText)
+	}
+	if !strings.HasPrefix(lines[1], "1,matched,0.90,晴天,周杰伦,") || !strings.HasPrefix(lines[2], "2,uncertain,") || !strings.HasPrefix(lines[3], "3,missing,,") {
+		t.Fatalf("csv rows:\n%s", csvText)
+	}
+
+	m3u := string(buildTransferM3U8(job))
+	if !strings.HasPrefix(m3u, "#EXTM3U\n#PLAYLIST:Mix\n") {
+		t.Fatalf("m3u8 header: %q", m3u)
+	}
+	if !strings.Contains(m3u, "#EXTINF:269,Jay Chou - 晴天\nhttps://example.com/track/a\n") || strings.Contains(m3u, "Rice Field") {
+		t.Fatalf("m3u8 should list matched tracks only:\n%s", m3u)
+	}
+
+	handler := &TransferHandler{}
+	text, keyboard := handler.renderReport(enCtx(), job)
+	if !strings.Contains(text, "Matched 1") || !strings.Contains(text, "2. 稻香") || strings.Contains(text, "timed out") {
+		t.Fatalf("report text:\n%s", text)
+	}
+	job.items = append(job.items, transferItem{source: transferTrack("4", "七里香", "周杰伦", 299), status: transferUnsearched, choice: -1})
+	if text, _ = handler.renderReport(enCtx(), job); !strings.Contains(text, "1 tracks were not searched") || strings.Contains(text, "七里香") {
+		t.Fatalf("timed-out report text:\n%s", text)
+	}
+	if csvText := string(buildTransferCSV(job)); !strings.Contains(csvText, "4,unsearched,,七里香") {
+		t.Fatalf("csv rows:\n%s", csvText)
+	}
+	if got := keyboard.InlineKeyboard[0][0].CallbackData; got != "trf p 1" {
+		t.Fatalf("pick button data = %q", got)
+	}
+}
+
+func TestSplitTransferArgs(t *testing.T) {
+	link, target := splitTransferArgs("https://music.163.com/playlist?id=1 spotify")
+	if link != "https://music.163.com/playlist?id=1" || target != "spotify" {
+		t.Fatalf("split = %q, %q", link, target)
+	}
+	if link, target = splitTransferArgs("spotify"); link != "" || target != "" {
+		t.Fatalf("single argument should be rejected, got %q, %q", link, target)
+	}
+}
@@ -0,0 +1,381 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"math"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	botpkg "github.com/liuran001/MusicBot-Go/bot"
+	"github.com/liuran001/MusicBot-Go/bot/config"
+	"github.com/liuran001/MusicBot-Go/bot/platform"
+	"github.com/liuran001/MusicBot-Go/bot/telegram"
+	"github.com/mymmrac/telego"
+)
+
+const (
+	// uploadSizeHeadroom is kept free below the limit for container overhead
+	// and the embedded cover when sizing a transcode or split.
+	uploadSizeHeadroom = 512 * 1024
+	minTranscodeKbps   = 64
+	maxTranscodeKbps   = 320
+	maxSplitParts      = 10
+)
+
+var errUploadTooLarge = errors.New("file exceeds the upload size limit")
+
+// FileLinkPublisher serves a prepared file over a temporary download link.
+type FileLinkPublisher interface {
+	Publish(path, name string) (string, time.Time, error)
+}
+
+// uploadDelivery describes how an oversized file reaches the chat; a nil
+// delivery is a plain single upload.
+type uploadDelivery struct {
+	strategy string
+	// notice is the localized line telling the user what was done.
+	notice string
+	// parts holds the split files, uploaded in order.
+	parts []string
+	// link is the temporary download URL for the "link" strategy.
+	link string
+}
+
+// cacheable reports whether the uploaded file_id may be stored for reuse.
+// Only a downgrade produces a file that matches its recorded quality.
+func (d *uploadDelivery) cacheable() bool {
+	return d == nil || d.strategy == config.OversizeDowngrade
+}
+
+// uploadDowngrade swaps the prepared file for the next lower quality and
+// returns its path, or "" when no lower quality is available.
+type uploadDowngrade func(ctx context.Context) (string, error)
+
+// fitUploadLimit applies strategies in order until musicPath can be
+// delivered. It returns the path to upload, the delivery (nil when the file
+// already fits) and any files it created for the caller to clean up.
+func (h *MusicHandler) fitUploadLimit(ctx context.Context, songInfo *botpkg.SongInfo, musicPath string, strategies []string, downgrade uploadDowngrade) (string, *uploadDelivery, []string, error) {
+	if h == nil || h.UploadLimitBytes <= 0 {
+		return musicPath, nil, nil, nil
+	}
+	limit := h.UploadLimitBytes
+	size := fileSizeOf(musicPath)
+	if size <= limit {
+		return musicPath, nil, nil, nil
+	}
+	limitMB := fmt.Sprintf("%.0f", float64(limit)/1024/1024)
+	sizeMB := fmt.Sprintf("%.1f", float64(size)/1024/1024)
+	var created []string
+	for _, strategy := range strategies {
+		switch strategy {
+		case config.OversizeDowngrade:
+			if downgrade == nil {
+				continue
+			}
+			from := songInfo.Quality
+			for {
+				path, err := downgrade(ctx)
+				if err != nil || path == "" {
+					if err != nil && h.Logger != nil {
+						h.Logger.Warn("upload downgrade failed", "platform", songInfo.Platform, "trackID", songInfo.TrackID, "error", err)
+					}
+					break
+				}
+				musicPath = path
+				if fileSizeOf(path) <= limit {
+					notice := tr(ctx, "upload_oversize_downgrade", map[string]any{"LimitMB": limitMB, "From": qualityDisplayName(ctx, from), "To": qualityDisplayName(ctx, songInfo.Quality)})
+					return musicPath, &uploadDelivery{strategy: strategy, notice: notice}, created, nil
+				}
+			}
+			size = fileSizeOf(musicPath)
+			sizeMB = fmt.Sprintf("%.1f", float64(size)/1024/1024)
+		case config.OversizeTranscode:
+			out, kbps, err := transcodeToFit(ctx, musicPath, songInfo.Duration, limit)
+			if out != "" {
+				created = append(created, out)
+			}
+			if err != nil {
+				if h.Logger != nil {
+					h.Logger.Warn("upload transcode failed", "platform", songInfo.Platform, "trackID", songInfo.TrackID, "error", err)
+				}
+				continue
+			}
+			songInfo.FileExt = "mp3"
+			songInfo.BitRate = kbps * 1000
+			songInfo.MusicSize = int(fileSizeOf(out))
+			notice := tr(ctx, "upload_oversize_transcode", map[string]any{"LimitMB": limitMB, "SizeMB": sizeMB, "Bitrate": kbps})
+			return out, &uploadDelivery{strategy: strategy, notice: notice}, created, nil
+		case config.OversizeSplit:
+			dir, parts, err := splitToFit(ctx, musicPath, songInfo.Duration, size, limit)
+			if dir != "" {
+				created = append(created, dir)
+			}
+			if err != nil {
+				if h.Logger != nil {
+					h.Logger.Warn("upload split failed", "platform", songInfo.Platform, "trackID", songInfo.TrackID, "error", err)
+				}
+				continue
+			}
+			notice := tr(ctx, "upload_oversize_split", map[string]any{"LimitMB": limitMB, "SizeMB": sizeMB, "Parts": len(parts)})
+			return musicPath, &uploadDelivery{strategy: strategy, notice: notice, parts: parts}, created, nil
+		case config.OversizeLink:
+			if h.FileLinks == nil {
+				continue
+			}
+			link, expires, err := h.FileLinks.Publish(musicPath, filepath.Base(musicPath))
+			if err != nil {
+				if h.Logger != nil {
+					h.Logger.Warn("upload link publish failed", "platform", songInfo.Platform, "trackID", songInfo.TrackID, "error", err)
+				}
+				continue
+			}
+			notice := tr(ctx, "upload_oversize_link", map[string]any{"LimitMB": limitMB, "SizeMB": sizeMB, "Expires": expires.Format("2006-01-02 15:04")})
+			return musicPath, &uploadDelivery{strategy: strategy, notice: notice, link: link}, created, nil
+		}
+	}
+	return musicPath, nil, created, fmt.Errorf("%w: %s MB > %s MB", errUploadTooLarge, sizeMB, limitMB)
+}
+
+// singleFileStrategies keeps the strategies that still yield one uploadable
+// audio file, for paths such as inline results that cannot send parts or
+// links.
+func singleFileStrategies(strategies []string) []string {
+	out := make([]string, 0, len(strategies))
+	for _, strategy := range strategies {
+		if strategy == config.OversizeDowngrade || strategy == config.OversizeTranscode {
+			out = append(out, strategy)
+		}
+	}
+	return out
+}
+
+// lowerQuality returns the next quality to try when a file is too large.
+// Atmos is a separate rendition, so it falls back to lossless stereo.
+func lowerQuality(q platform.Quality) (platform.Quality, bool) {
+	switch q {
+	case platform.QualityAtmos, platform.QualityHiRes:
+		return platform.QualityLossless, true
+	case platform.QualityLossless:
+		return platform.QualityHigh, true
+	case platform.QualityHigh:
+		return platform.QualityStandard, true
+	}
+	return platform.QualityStandard, false
+}
+
+// transcodeBitrate picks the MP3 bitrate (kbps) that keeps a track of the
+// given duration under limit, or 0 when even the minimum would not fit.
+func transcodeBitrate(durationSec int, limit int64) int {
+	if durationSec <= 0 {
+		return 0
+	}
+	budget := limit - uploadSizeHeadroom
+	if budget <= 0 {
+		return 0
+	}
+	kbps := int(math.Floor(float64(budget) * 8 / float64(durationSec) / 1000))
+	if kbps > maxTranscodeKbps {
+		kbps = maxTranscodeKbps
+	}
+	if kbps < minTranscodeKbps {
+		return 0
+	}
+	return kbps
+}
+
+// transcodeToFit re-encodes src to an MP3 sized for limit, keeping tags and
+// the attached cover. The output gets a unique name because the prepared file
+// may be shared by concurrent requests, and is returned even on failure so the
+// caller can clean it up.
+func transcodeToFit(ctx context.Context, src string, durationSec int, limit int64) (string, int, error) {
+	kbps := transcodeBitrate(durationSec, limit)
+	if kbps == 0 {
+		return "", 0, fmt.Errorf("cannot fit %ds into %d bytes", durationSec, limit)
+	}
+	ffmpegPath, err := exec.LookPath("ffmpeg")
+	if err != nil {
+		return "", 0, err
+	}
+	out := fmt.Sprintf("%s.fit%d.mp3", strings.TrimSuffix(src, filepath.Ext(src)), time.Now().UnixNano())
+	cmd := exec.CommandContext(ctx, ffmpegPath, "-y", "-i", src,
+		"-map", "0:a:0", "-map", "0:v?", "-map_metadata", "0",
+		"-c:a", "libmp3lame", "-b:a", fmt.Sprintf("%dk", kbps),
+		"-c:v", "copy", "-id3v2_version", "3", out)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return out, 0, fmt.Errorf("transcode: %w, stderr: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	if fileSizeOf(out) > limit {
+		return out, 0, fmt.Errorf("transcoded file still exceeds %d bytes", limit)
+	}
+	return out, kbps, nil
+}
+
+// splitSegmentSeconds returns the segment length that keeps every part of a
+// size-byte track under limit, and the resulting part count.
+func splitSegmentSeconds(durationSec int, size, limit int64) (int, int) {
+	if durationSec <= 0 || size <= 0 || limit <= uploadSizeHeadroom {
+		return 0, 0
+	}
+	perSecond := float64(size) / float64(durationSec)
+	segment := int(math.Floor(float64(limit-uploadSizeHeadroom) * 0.9 / perSecond))
+	if segment <= 0 {
+		return 0, 0
+	}
+	return segment, int(math.Ceil(float64(durationSec) / float64(segment)))
+}
+
+// splitToFit cuts src into stream-copied parts that each fit under limit.
+// Parts are written to a fresh directory next to src, which is returned for
+// cleanup together with the part paths in order.
+func splitToFit(ctx context.Context, src string, durationSec int, size, limit int64) (string, []string, error) {
+	segment, count := splitSegmentSeconds(durationSec, size, limit)
+	if segment == 0 {
+		return "", nil, errors.New("track duration unknown")
+	}
+	if count > maxSplitParts {
+		return "", nil, fmt.Errorf("would need %d parts (max %d)", count, maxSplitParts)
+	}
+	ffmpegPath, err := exec.LookPath("ffmpeg")
+	if err != nil {
+		return "", nil, err
+	}
+	dir := filepath.Join(filepath.Dir(src), fmt.Sprintf("split-%d", time.Now().UnixNano()))
+	if err := os.Mkdir(dir, 0o755); err != nil {
+		return "", nil, err
+	}
+	ext := filepath.Ext(src)
+	pattern := filepath.Join(dir, "part%02d"+ext)
+	cmd := exec.CommandContext(ctx, ffmpegPath, "-y", "-i", src,
+		"-map", "0:a:0", "-map_metadata", "0", "-c", "copy",
+		"-f", "segment", "-segment_time", fmt.Sprintf("%d", segment), "-reset_timestamps", "1", pattern)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return dir, nil, fmt.Errorf("split: %w, stderr: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	var segments []string
+	for i := 0; ; i++ {
+		part := fmt.Sprintf(pattern, i)
+		if _, err := os.Stat(part); err != nil {
+			break
+		}
+		segments = append(segments, part)
+	}
+	if len(segments) == 0 {
+		return dir, nil, errors.New("split produced no parts")
+	}
+	base := strings.TrimSuffix(filepath.Base(src), ext)
+	parts := make([]string, 0, len(segments))
+	for i, segmentPath := range segments {
+		if fileSizeOf(segmentPath) > limit {
+			return dir, nil, fmt.Errorf("part %d still exceeds %d bytes", i+1, limit)
+		}
+		named := filepath.Join(dir, fmt.Sprintf("%s (%d-%d)%s", base, i+1, len(segments), ext))
+		if err := os.Rename(segmentPath, named); err != nil {
+			named = segmentPath
+		}
+		parts = append(parts, named)
+	}
+	return dir, parts, nil
+}
+
+func fileSizeOf(path string) int64 {
+	if strings.TrimSpace(path) == "" {
+		return 0
+	}
+	stat, err := os.Stat(path)
+	if err != nil {
+		return 0
+	}
+	return stat.Size()
+}
+
+// appendUploadNotice adds an oversize notice to an HTML caption.
+func appendUploadNotice(caption, notice string) string {
+	notice = strings.TrimSpace(notice)
+	if notice == "" {
+		return caption
+	}
+	return strings.TrimRight(caption, "\n") + "\n\n" + html.EscapeString(notice)
+}
+
+// sendOversizeDelivery sends a split or linked track. Split parts reply to
+// the request in order with the caption on the first part; a link is sent as
+// a message with a download button.
+func (h *MusicHandler) sendOversizeDelivery(ctx context.Context, b *telego.Bot, message *telego.Message, songInfo *botpkg.SongInfo, picPath string, delivery *uploadDelivery) (*telego.Message, error) {
+	if songInfo == nil || message == nil || message.Chat.ID == 0 || delivery == nil {
+		return nil, errors.New("oversize delivery requires a song, a chat and a plan")
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	caption := appendUploadNotice(buildMusicCaption(ctx, h.PlatformManager, songInfo, h.BotName), delivery.notice)
+	if delivery.link != "" {
+		params := &telego.SendMessageParams{
+			ChatID:          telego.ChatID{ID: message.Chat.ID},
+			MessageThreadID: message.MessageThreadID,
+			Text:            caption,
+			ParseMode:       telego.ModeHTML,
+			ReplyParameters: buildReplyParams(message),
+			ReplyMarkup: &telego.InlineKeyboardMarkup{InlineKeyboard: [][]telego.InlineKeyboardButton{{
+				{Text: tr(ctx, "upload_link_button"), URL: delivery.link},
+			}}},
+		}
+		if h.RateLimiter != nil {
+			return telegram.SendMessageWithRetry(ctx, h.RateLimiter, b, params)
+		}
+		return b.SendMessage(ctx, params)
+	}
+
+	var last *telego.Message
+	for i, part := range delivery.parts {
+		sent, err := h.sendAudioPart(ctx, b, message, songInfo, part, picPath, caption, i, len(delivery.parts))
+		if err != nil {
+			return last, fmt.Errorf("send part %d/%d: %w", i+1, len(delivery.parts), err)
+		}
+		last = sent
+	}
+	return last, nil
+}
+
+func (h *MusicHandler) sendAudioPart(ctx context.Context, b *telego.Bot, message *telego.Message, songInfo *botpkg.SongInfo, partPath, picPath, caption string, index, total int) (*telego.Message, error) {
+	uploadCtx, cancel := context.WithTimeout(ctx, 5*time.Minute)
+	defer cancel()
+	file, err := os.Open(partPath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	params := &telego.SendAudioParams{
+		ChatID:          telego.ChatID{ID: message.Chat.ID},
+		MessageThreadID: message.MessageThreadID,
+		Audio:           h.audioUpload(uploadCtx, file),
+		Title:           fmt.Sprintf("%s (%d/%d)", songInfo.SongName, index+1, total),
+		Performer:       songInfo.SongArtists,
+	}
+	if index == 0 {
+		params.Caption = caption
+		params.ParseMode = telego.ModeHTML
+		params.ReplyParameters = buildReplyParams(message)
+	}
+	if fileSizeOf(picPath) > 0 {
+		if thumb, thumbErr := os.Open(picPath); thumbErr == nil {
+			defer thumb.Close()
+			params.Thumbnail = &telego.InputFile{File: thumb}
+		}
+	}
+	_ = b.SendChatAction(uploadCtx, &telego.SendChatActionParams{ChatID: telego.ChatID{ID: message.Chat.ID}, MessageThreadID: message.MessageThreadID, Action: telego.ChatActionUploadDocument})
+	if h.RateLimiter != nil {
+		return telegram.SendAudioWithRetry(uploadCtx, h.RateLimiter, b, params)
+	}
+	return b.SendAudio(uploadCtx, params)
+}
@@ -0,0 +1,129 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	botpkg "github.com/liuran001/MusicBot-Go/bot"
+	"github.com/liuran001/MusicBot-Go/bot/config"
+	"github.com/liuran001/MusicBot-Go/bot/platform"
+)
+
+type fakeFileLinks struct {
+	published []string
+}
+
+func (f *fakeFileLinks) Publish(path, name string) (string, time.Time, error) {
+	f.published = append(f.published, path)
+	return "https://dl.example.com/f/token/" + name, time.Date(2026, 1, 2, 3, 4, 0, 0, time.UTC), nil
+}
+
+func writeSizedFile(t *testing.T, dir, name string, size int) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, make([]byte, size), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestFitUploadLimitLeavesSmallFilesAlone(t *testing.T) {
+	dir := t.TempDir()
+	path := writeSizedFile(t, dir, "small.mp3", 100)
+	h := &MusicHandler{UploadLimitBytes: 1000, OversizeStrategies: []string{config.OversizeLink}, FileLinks: &fakeFileLinks{}}
+	got, delivery, created, err := h.fitUploadLimit(enCtx(), &botpkg.SongInfo{}, path, h.OversizeStrategies, nil)
+	if err != nil || got != path || delivery != nil || len(created) != 0 {
+		t.Fatalf("fitUploadLimit() = %q, %+v, %v, %v", got, delivery, created, err)
+	}
+}
+
+func TestFitUploadLimitDowngradesUntilItFits(t *testing.T) {
+	dir := t.TempDir()
+	path := writeSizedFile(t, dir, "hires.flac", 5000)
+	songInfo := &botpkg.SongInfo{Quality: "hires"}
+	sizes := []int{3000, 800}
+	qualities := []string{"lossless", "high"}
+	calls := 0
+	downgrade := func(context.Context) (string, error) {
+		if calls >= len(sizes) {
+			return "", nil
+		}
+		songInfo.Quality = qualities[calls]
+		next := writeSizedFile(t, dir, qualities[calls], sizes[calls])
+		calls++
+		return next, nil
+	}
+	h := &MusicHandler{UploadLimitBytes: 1000, OversizeStrategies: []string{config.OversizeDowngrade, config.OversizeLink}, FileLinks: &fakeFileLinks{}}
+	got, delivery, _, err := h.fitUploadLimit(enCtx(), songInfo, path, h.OversizeStrategies, downgrade)
+	if err != nil {
+		t.Fatalf("fitUploadLimit: %v", err)
+	}
+	if calls != 2 || filepath.Base(got) != "high" || delivery == nil || delivery.strategy != config.OversizeDowngrade {
+		t.Fatalf("got %q after %d downgrades, delivery %+v", got, calls, delivery)
+	}
+	if !delivery.cacheable() || !strings.Contains(delivery.notice, qualityDisplayName(enCtx(), "hires")) || !strings.Contains(delivery.notice, qualityDisplayName(enCtx(), "high")) {
+		t.Fatalf("downgrade notice = %q", delivery.notice)
+	}
+}
+
+func TestFitUploadLimitFallsThroughToLink(t *testing.T) {
+	dir := t.TempDir()
+	path := writeSizedFile(t, dir, "concert.m4a", 5000)
+	links := &fakeFileLinks{}
+	// Without a duration neither transcode nor split can size their output.
+	h := &MusicHandler{UploadLimitBytes: 1000, OversizeStrategies: []string{config.OversizeTranscode, config.OversizeSplit, config.OversizeLink}, FileLinks: links}
+	got, delivery, _, err := h.fitUploadLimit(enCtx(), &botpkg.SongInfo{}, path, h.OversizeStrategies, nil)
+	if err != nil {
+		t.Fatalf("fitUploadLimit: %v", err)
+	}
+	if got != path || delivery == nil || delivery.link == "" || len(links.published) != 1 || links.published[0] != path {
+		t.Fatalf("got %q, delivery %+v, published %v", got, delivery, links.published)
+	}
+	if delivery.cacheable() || !strings.Contains(delivery.notice, "2026-01-02 03:04") {
+		t.Fatalf("link delivery = %+v", delivery)
+	}
+}
+
+func TestFitUploadLimitReportsTooLarge(t *testing.T) {
+	dir := t.TempDir()
+	path := writeSizedFile(t, dir, "big.flac", 5000)
+	h := &MusicHandler{UploadLimitBytes: 1000, OversizeStrategies: []string{config.OversizeLink}}
+	_, _, _, err := h.fitUploadLimit(enCtx(), &botpkg.SongInfo{}, path, singleFileStrategies(h.OversizeStrategies), nil)
+	if !errors.Is(err, errUploadTooLarge) {
+		t.Fatalf("err = %v, want errUploadTooLarge", err)
+	}
+	if got := userVisibleDownloadError(enCtx(), err); got != tr(enCtx(), "err_upload_too_large") {
+		t.Fatalf("user-visible error = %q", got)
+	}
+}
+
+func TestUploadSizeArithmetic(t *testing.T) {
+	limit := int64(50 * 1024 * 1024)
+	if got := transcodeBitrate(600, limit); got != 320 {
+		t.Fatalf("10 min track should keep 320 kbps, got %d", got)
+	}
+	if got := transcodeBitrate(3600, limit); got < minTranscodeKbps || got >= 320 {
+		t.Fatalf("1 h track bitrate = %d", got)
+	}
+	if got := transcodeBitrate(4*3600*10, limit); got != 0 {
+		t.Fatalf("impossibly long track should not transcode, got %d", got)
+	}
+	segment, parts := splitSegmentSeconds(600, 3*limit, limit)
+	if segment <= 0 || parts != 4 {
+		t.Fatalf("split = %ds x %d", segment, parts)
+	}
+	if next, ok := lowerQuality(platform.QualityAtmos); !ok || next != platform.QualityLossless {
+		t.Fatalf("atmos should fall back to lossless, got %v", next)
+	}
+	if _, ok := lowerQuality(platform.QualityStandard); ok {
+		t.Fatal("standard has no lower quality")
+	}
+	if got := singleFileStrategies([]string{"link", "transcode", "split", "downgrade"}); strings.Join(got, ",") != "transcode,downgrade" {
+		t.Fatalf("singleFileStrategies = %v", got)
+	}
+}
@@ -83,6 +83,33 @@ func (rl *RateLimiter) SetLogger(logger Logger) {
 	rl.logger = logger
 }
 
+// SetLimits changes the per-chat and global rates in place, e.g. after a
+// config reload. Existing per-chat limiters are updated rather than dropped so
+// chats keep their current token balance. A non-positive global rate or burst
+// disables the global limiter.
+func (rl *RateLimiter) SetLimits(msgPerSec float64, burst int, globalPerSec float64, globalBurst int) {
+	if rl == nil {
+		return
+	}
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	rl.rate = rate.Limit(msgPerSec)
+	rl.burst = burst
+	for _, current := range rl.limiters {
+		current.limiter.SetLimit(rl.rate)
+		current.limiter.SetBurst(burst)
+	}
+	switch {
+	case globalPerSec <= 0 || globalBurst <= 0:
+		rl.globalLimiter = nil
+	case rl.globalLimiter == nil:
+		rl.globalLimiter = rate.NewLimiter(rate.Limit(globalPerSec), globalBurst)
+	default:
+		rl.globalLimiter.SetLimit(rate.Limit(globalPerSec))
+		rl.globalLimiter.SetBurst(globalBurst)
+	}
+}
+
 // StartQueue enables a bounded Telegram API send queue. The public send/edit
 // helpers still block until their request finishes, but the actual API calls
 // are executed by this worker set instead of by event/download goroutines.
@@ -239,8 +266,11 @@ func (rl *RateLimiter) maybeCleanup(now time.Time) {
 }
 
 func (rl *RateLimiter) Wait(ctx context.Context, chatID int64) error {
-	if rl.globalLimiter != nil {
-		if err := rl.globalLimiter.Wait(ctx); err != nil {
+	rl.mu.RLock()
+	globalLimiter := rl.globalLimiter
+	rl.mu.RUnlock()
+	if globalLimiter != nil {
+		if err := globalLimiter.Wait(ctx); err != nil {
 			return err
 		}
 	}
@@ -596,6 +626,34 @@ func SendAudioWithRetry(ctx context.Context, rl *RateLimiter, b *telego.Bot, par
 	return result, nil
 }
 
+func SendVoiceWithRetry(ctx context.Context, rl *RateLimiter, b *telego.Bot, params *telego.SendVoiceParams) (*telego.Message, error) {
+	var result *telego.Message
+	var lastErr error
+
+	chatID := extractChatID(params.ChatID)
+	err := WithRetry(ctx, rl, chatID, func() error {
+		msg, err := b.SendVoice(ctx, params)
+		if err != nil {
+			lastErr = err
+			return err
+		}
+		result = msg
+		return nil
+	})
+
+	if err != nil {
+		retErr := lastErr
+		if retErr == nil {
+			retErr = err
+		}
+		if rl != nil {
+			rl.logError("SendVoice failed", "chat_id", chatID, "error", retErr)
+		}
+		return result, retErr
+	}
+	return result, nil
+}
+
 func SendDocumentWithRetry(ctx context.Context, rl *RateLimiter, b *telego.Bot, params *telego.SendDocumentParams) (*telego.Message, error) {
 	var result *telego.Message
 	var lastErr error
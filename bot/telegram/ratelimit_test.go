@@ -164,3 +164,30 @@ func TestRateLimiterSendQueueCancellationUnblocksCallers(t *testing.T) {
 	}
 	close(release)
 }
+
+func TestRateLimiterSetLimits(t *testing.T) {
+	rl := NewRateLimiter(1, 1)
+	ctx := context.Background()
+	if err := rl.Wait(ctx, 7); err != nil {
+		t.Fatalf("first wait: %v", err)
+	}
+	rl.SetLimits(1000, 5, 1, 1)
+	if rl.globalLimiter == nil {
+		t.Fatal("global limiter should be created")
+	}
+	limiter := rl.getLimiter(7)
+	if limiter.Burst() != 5 || limiter.Limit() != 1000 {
+		t.Fatalf("existing chat limiter not updated: limit=%v burst=%d", limiter.Limit(), limiter.Burst())
+	}
+	rl.SetLimits(1000, 5, 0, 0)
+	if rl.globalLimiter != nil {
+		t.Fatal("zero global rate should disable the global limiter")
+	}
+	waitCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+	for i := 0; i < 5; i++ {
+		if err := rl.Wait(waitCtx, 7); err != nil {
+			t.Fatalf("wait %d under raised limits: %v", i, err)
+		}
+	}
+}
@@ -16,15 +16,22 @@ type SongInfo struct {
 	CreatedAt       time.Time
 	UpdatedAt       time.Time
 	DeletedAt       *time.Time
-	Platform        string // Platform identifier (e.g., "netease", "spotify")
-	TrackID         string // Platform-specific track identifier
-	Quality         string // Quality level (e.g., "standard", "high", "lossless")
-	QualityVerified bool   // true if Quality has been verified against the platform API
-	QualityRevision int    // classifier revision used when the cached quality was verified
-	AudioCodec      string // verified audio codec when available (e.g. alac, eac3)
-	SampleRate      int    // verified audio sample rate in Hz when available
-	BitDepth        int    // verified audio bit depth when available
-	MusicID         int    // Deprecated: Legacy NetEase music ID (kept for backward compatibility)
+	Platform        string  // Platform identifier (e.g., "netease", "spotify")
+	TrackID         string  // Platform-specific track identifier
+	Quality         string  // Quality level (e.g., "standard", "high", "lossless")
+	OutputFormat    string  // Transcoded output format (e.g. "mp3_320"); empty for the original file
+	QualityVerified bool    // true if Quality has been verified against the platform API
+	QualityRevision int     // classifier revision used when the cached quality was verified
+	AudioCodec      string  // verified audio codec when available (e.g. alac, eac3)
+	SampleRate      int     // verified audio sample rate in Hz when available
+	BitDepth        int     // verified audio bit depth when available
+	LoudnessLUFS    float64 // EBU R128 integrated loudness; 0 when not measured
+	TruePeak        float64 // true peak as linear amplitude (1.0 = full scale); 0 when not measured
+	SpectralVerdict string  // spectral analysis verdict (genuine/lossy_source/upsampled/padded_bits); empty when not analysed
+	SpectralCutoff  int     // frequency in Hz where the spectrum ends
+	EffectiveBits   int     // bit depth actually used by the samples
+	IntegrityStatus string  // full-decode check result (verified/decoded); empty when not checked
+	MusicID         int     // Deprecated: Legacy NetEase music ID (kept for backward compatibility)
 	SongName        string
 	SongArtists     string
 	SongArtistsIDs  string
@@ -82,18 +89,88 @@ type Favorite struct {
 	SongArtistsURLs string
 }
 
+// ArtistSubscription records that a chat (private chat, group or channel)
+// follows an artist's new releases. It is keyed by (ChatID, Platform, ArtistID).
+// KnownReleaseIDs holds the most recent release IDs already seen, newest first;
+// it is empty until the first poll seeds it, so following an artist never
+// announces its back catalogue. Language is the UI language of the follower,
+// used to render announcements posted outside any request.
+type ArtistSubscription struct {
+	ID              uint
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+	ChatID          int64
+	Platform        string
+	ArtistID        string
+	ArtistName      string
+	ArtistURL       string
+	CreatedByUserID int64
+	Language        string
+	KnownReleaseIDs []string
+	LastCheckedAt   *time.Time
+	LastPolledAt    *time.Time
+}
+
+// PreviewClip is a cached preview voice note of a track, keyed by (Platform,
+// TrackID). Title is the "song - artists" label of its caption, Start is where
+// the clip begins in the full track and Waveform the text waveform shown next
+// to it.
+type PreviewClip struct {
+	Platform string
+	TrackID  string
+	FileID   string
+	Title    string
+	Start    time.Duration
+	Duration time.Duration
+	Waveform string
+}
+
+// PlaylistSubscription records that a chat follows a playlist and wants its
+// newly added tracks posted. It is keyed by (ChatID, Platform, PlaylistID).
+// TrackIDs is the snapshot taken at the last check; additions are the IDs a
+// later fetch returns that the snapshot lacks. AutoDownload also queues the
+// added tracks for download at the chat's default quality.
+type PlaylistSubscription struct {
+	ID              uint
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+	ChatID          int64
+	Platform        string
+	PlaylistID      string
+	Title           string
+	URL             string
+	CreatedByUserID int64
+	Language        string
+	AutoDownload    bool
+	TrackIDs        []string
+	LastCheckedAt   *time.Time
+}
+
 // UserSettings represents user preferences for the bot.
 type UserSettings struct {
-	ID                 uint
-	CreatedAt          time.Time
-	UpdatedAt          time.Time
-	DeletedAt          *time.Time
-	UserID             int64
-	DefaultPlatform    string
-	DefaultQuality     string
-	AutoDeleteList     bool
-	AutoLinkDetect     bool
-	DefaultLyricFormat string
+	ID              uint
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+	DeletedAt       *time.Time
+	UserID          int64
+	DefaultPlatform string
+	DefaultQuality  string
+	// DefaultOutputFormat is the format downloads are transcoded to before
+	// upload ("original", "mp3_320", "aac_256", "opus_160", "flac").
+	DefaultOutputFormat string
+	AutoDeleteList      bool
+	AutoLinkDetect      bool
+	DefaultLyricFormat  string
+	// FilenameTemplate, CaptionTemplate and TagTemplates customise uploads
+	// (see package render). Empty values, and tag fields missing from the
+	// map, keep the built-in output.
+	FilenameTemplate string
+	CaptionTemplate  string
+	TagTemplates     map[string]string
+	// CoverEmbed picks the artwork embedded in uploads: "" keeps the
+	// platform's default cover, "original" the full-resolution variant, a
+	// number caps the longest edge in pixels, "none" embeds nothing.
+	CoverEmbed string
 	// Language is the persisted UI-language override (2-letter ISO 639-1, e.g.
 	// "zh"/"en"/"ja"). Empty means "auto-detect from the Telegram client".
 	Language string
@@ -107,16 +184,29 @@ type UserSettings struct {
 
 // GroupSettings represents group-level preferences for the bot.
 type GroupSettings struct {
-	ID                 uint
-	CreatedAt          time.Time
-	UpdatedAt          time.Time
-	DeletedAt          *time.Time
-	ChatID             int64
-	DefaultPlatform    string
-	DefaultQuality     string
-	AutoDeleteList     bool
-	AutoLinkDetect     bool
-	DefaultLyricFormat string
+	ID              uint
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+	DeletedAt       *time.Time
+	ChatID          int64
+	DefaultPlatform string
+	DefaultQuality  string
+	// DefaultOutputFormat is the format downloads are transcoded to before
+	// upload ("original", "mp3_320", "aac_256", "opus_160", "flac").
+	DefaultOutputFormat string
+	AutoDeleteList      bool
+	AutoLinkDetect      bool
+	DefaultLyricFormat  string
+	// FilenameTemplate, CaptionTemplate and TagTemplates customise uploads
+	// (see package render). Empty values, and tag fields missing from the
+	// map, keep the built-in output.
+	FilenameTemplate string
+	CaptionTemplate  string
+	TagTemplates     map[string]string
+	// CoverEmbed picks the artwork embedded in uploads: "" keeps the
+	// platform's default cover, "original" the full-resolution variant, a
+	// number caps the longest edge in pixels, "none" embeds nothing.
+	CoverEmbed string
 	// Language is the persisted UI-language override (2-letter ISO 639-1). Empty
 	// means "auto-detect from the Telegram client".
 	Language string
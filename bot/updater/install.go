@@ -0,0 +1,472 @@
+package updater
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	// ManifestFile sits at the root of a plugin package.
+	ManifestFile = "manifest.json"
+	// SignatureFile holds the base64 ed25519 signature of ManifestFile.
+	SignatureFile = "manifest.sig"
+
+	// maxPackageBytes bounds the unpacked size of a package.
+	maxPackageBytes = 32 << 20
+)
+
+// Manifest describes an installable script plugin package.
+type Manifest struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+	// Files maps every script file of the package to its hex SHA-256.
+	Files map[string]string `json:"files"`
+}
+
+var pluginNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// Install verifies the plugin package at source and swaps it into the script
+// directory. source is a .zip, .tar.gz/.tgz archive or a directory such as a
+// git checkout; the manifest may sit at its root or in a single top-level
+// directory. archiveSHA256, when set, must match the archive file. The
+// previous version is restored if the new one fails to load.
+func (u *Updater) Install(ctx context.Context, source, archiveSHA256 string) (*Manifest, error) {
+	source = strings.TrimSpace(source)
+	if source == "" {
+		return nil, fmt.Errorf("source required")
+	}
+	info, err := os.Stat(source)
+	if err != nil {
+		return nil, err
+	}
+	if want := strings.TrimSpace(archiveSHA256); want != "" {
+		if info.IsDir() {
+			return nil, fmt.Errorf("archive checksum given for directory %s", source)
+		}
+		got, err := fileSHA256(source)
+		if err != nil {
+			return nil, err
+		}
+		if !strings.EqualFold(got, want) {
+			return nil, fmt.Errorf("archive checksum mismatch: got %s", got)
+		}
+	}
+
+	if err := os.MkdirAll(u.srcPath, 0o755); err != nil {
+		return nil, err
+	}
+	staging, err := os.MkdirTemp(u.srcPath, ".staging-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(staging)
+
+	switch {
+	case info.IsDir():
+		err = copyTree(source, staging)
+	case strings.HasSuffix(strings.ToLower(source), ".zip"):
+		err = extractZip(source, staging)
+	case strings.HasSuffix(strings.ToLower(source), ".tar.gz"), strings.HasSuffix(strings.ToLower(source), ".tgz"):
+		err = extractTarGz(source, staging)
+	default:
+		err = fmt.Errorf("unsupported package %s: want .zip, .tar.gz or a directory", filepath.Base(source))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	pkgDir, err := findPackageRoot(staging)
+	if err != nil {
+		return nil, err
+	}
+	manifest, err := u.verify(pkgDir)
+	if err != nil {
+		return nil, err
+	}
+	if err := u.swap(ctx, manifest.Name, pkgDir); err != nil {
+		return nil, err
+	}
+	if u.logger != nil {
+		u.logger.Info("script plugin installed", "plugin", manifest.Name, "version", manifest.Version, "source", source)
+	}
+	return manifest, nil
+}
+
+// verify checks the manifest, its signature, the file checksums and the
+// version pin of an unpacked package.
+func (u *Updater) verify(pkgDir string) (*Manifest, error) {
+	raw, err := os.ReadFile(filepath.Join(pkgDir, ManifestFile))
+	if err != nil {
+		return nil, err
+	}
+	var manifest Manifest
+	if err := json.Unmarshal(raw, &manifest); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", ManifestFile, err)
+	}
+	manifest.Name = strings.TrimSpace(manifest.Name)
+	manifest.Version = strings.TrimSpace(manifest.Version)
+	if !pluginNamePattern.MatchString(manifest.Name) {
+		return nil, fmt.Errorf("invalid plugin name %q", manifest.Name)
+	}
+	if manifest.Version == "" {
+		return nil, fmt.Errorf("plugin %s: version required", manifest.Name)
+	}
+
+	signed, err := u.verifySignature(pkgDir, raw)
+	if err != nil {
+		return nil, err
+	}
+	// A signature only covers the manifest, so it is worthless without the
+	// file checksums.
+	if len(manifest.Files) == 0 && (u.opts.RequireChecksum || signed) {
+		return nil, fmt.Errorf("plugin %s: manifest lists no file checksums", manifest.Name)
+	}
+	if len(manifest.Files) > 0 {
+		if err := verifyFiles(pkgDir, manifest.Files); err != nil {
+			return nil, fmt.Errorf("plugin %s: %w", manifest.Name, err)
+		}
+	}
+	if files, err := scriptFiles(pkgDir); err != nil {
+		return nil, err
+	} else if len(files) == 0 {
+		return nil, fmt.Errorf("plugin %s: no script files", manifest.Name)
+	}
+
+	if u.opts.Pin != nil {
+		if pin := strings.TrimSpace(u.opts.Pin(manifest.Name)); pin != "" && !versionMatches(pin, manifest.Version) {
+			return nil, fmt.Errorf("plugin %s is pinned to %s, package is %s", manifest.Name, pin, manifest.Version)
+		}
+	}
+	return &manifest, nil
+}
+
+// verifySignature reports whether the manifest carries a valid signature.
+func (u *Updater) verifySignature(pkgDir string, manifest []byte) (bool, error) {
+	raw, err := os.ReadFile(filepath.Join(pkgDir, SignatureFile))
+	if errors.Is(err, os.ErrNotExist) {
+		if u.opts.RequireSignature {
+			return false, fmt.Errorf("package is not signed")
+		}
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if len(u.opts.TrustedKeys) == 0 {
+		return false, fmt.Errorf("package is signed but no trusted keys are configured")
+	}
+	sig, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(raw)))
+	if err != nil {
+		return false, fmt.Errorf("invalid %s: %w", SignatureFile, err)
+	}
+	for _, key := range u.opts.TrustedKeys {
+		if ed25519.Verify(key, manifest, sig) {
+			return true, nil
+		}
+	}
+	return false, fmt.Errorf("manifest signature does not match any trusted key")
+}
+
+// verifyFiles requires every script file to be listed with a matching
+// checksum, and every listed file to exist.
+func verifyFiles(pkgDir string, sums map[string]string) error {
+	files, err := scriptFiles(pkgDir)
+	if err != nil {
+		return err
+	}
+	present := make(map[string]bool, len(files))
+	for _, name := range files {
+		present[name] = true
+		if _, ok := sums[name]; !ok {
+			return fmt.Errorf("file %s is not listed in the manifest", name)
+		}
+	}
+	for name, want := range sums {
+		if strings.ContainsAny(name, `/\`) {
+			return fmt.Errorf("manifest entry %s must be a plain file name", name)
+		}
+		if !present[name] {
+			if _, err := os.Stat(filepath.Join(pkgDir, name)); err != nil {
+				return fmt.Errorf("file %s listed in the manifest is missing", name)
+			}
+		}
+		got, err := fileSHA256(filepath.Join(pkgDir, name))
+		if err != nil {
+			return err
+		}
+		if !strings.EqualFold(got, strings.TrimSpace(want)) {
+			return fmt.Errorf("checksum mismatch for %s", name)
+		}
+	}
+	return nil
+}
+
+// versionMatches accepts the exact version or, for a pin like "1.2", any
+// version in that series ("1.2.3").
+func versionMatches(pin, version string) bool {
+	pin = strings.TrimPrefix(pin, "v")
+	version = strings.TrimPrefix(version, "v")
+	return version == pin || strings.HasPrefix(version, pin+".")
+}
+
+// swap moves the verified package into place and reloads the plugin. The
+// previous directory is kept aside until the new version has loaded.
+func (u *Updater) swap(ctx context.Context, name, pkgDir string) error {
+	if u.opts.Reload == nil {
+		return fmt.Errorf("reload not configured")
+	}
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	target := filepath.Join(u.srcPath, name)
+	backup := filepath.Join(u.srcPath, fmt.Sprintf(".backup-%s-%d", name, time.Now().UnixNano()))
+	hadPrevious := false
+	if _, err := os.Stat(target); err == nil {
+		if err := os.Rename(target, backup); err != nil {
+			return err
+		}
+		hadPrevious = true
+	}
+	restore := func() {
+		_ = os.RemoveAll(target)
+		if hadPrevious {
+			_ = os.Rename(backup, target)
+		}
+	}
+	if err := os.Rename(pkgDir, target); err != nil {
+		restore()
+		return err
+	}
+	sum, err := fingerprintDir(target)
+	if err != nil {
+		restore()
+		return err
+	}
+	if err := u.opts.Reload(ctx, name); err != nil {
+		restore()
+		if hadPrevious {
+			if previous, err := fingerprintDir(target); err == nil {
+				u.loaded[name] = previous
+			}
+		}
+		return fmt.Errorf("load %s: %w", name, err)
+	}
+	u.loaded[name] = sum
+	delete(u.pending, name)
+	if hadPrevious {
+		_ = os.RemoveAll(backup)
+	}
+	return nil
+}
+
+// findPackageRoot returns the directory holding the manifest: the unpacked
+// root itself or its only subdirectory.
+func findPackageRoot(root string) (string, error) {
+	if _, err := os.Stat(filepath.Join(root, ManifestFile)); err == nil {
+		return root, nil
+	}
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return "", err
+	}
+	var dirs []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			dirs = append(dirs, entry.Name())
+		}
+	}
+	if len(dirs) == 1 {
+		nested := filepath.Join(root, dirs[0])
+		if _, err := os.Stat(filepath.Join(nested, ManifestFile)); err == nil {
+			return nested, nil
+		}
+	}
+	return "", fmt.Errorf("%s not found in package", ManifestFile)
+}
+
+// copyTree copies regular files from a directory, skipping VCS metadata and
+// symlinks.
+func copyTree(src, dst string) error {
+	var total int64
+	return filepath.WalkDir(src, func(p string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, p)
+		if err != nil {
+			return err
+		}
+		if rel == "." {
+			return nil
+		}
+		if d.IsDir() {
+			if d.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			return os.MkdirAll(filepath.Join(dst, rel), 0o755)
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		f, err := os.Open(p)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		n, err := writeFile(filepath.Join(dst, rel), f, maxPackageBytes-total)
+		total += n
+		return err
+	})
+}
+
+func extractZip(archive, dst string) error {
+	reader, err := zip.OpenReader(archive)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+	var total int64
+	for _, file := range reader.File {
+		target, err := archivePath(dst, file.Name)
+		if err != nil {
+			return err
+		}
+		if file.FileInfo().IsDir() {
+			if err := os.MkdirAll(target, 0o755); err != nil {
+				return err
+			}
+			continue
+		}
+		if !file.Mode().IsRegular() {
+			continue
+		}
+		rc, err := file.Open()
+		if err != nil {
+			return err
+		}
+		n, err := writeFile(target, rc, maxPackageBytes-total)
+		rc.Close()
+		total += n
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func extractTarGz(archive, dst string) error {
+	f, err := os.Open(archive)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return err
+	}
+	defer gz.Close()
+	reader := tar.NewReader(gz)
+	var total int64
+	for {
+		header, err := reader.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		target, err := archivePath(dst, header.Name)
+		if err != nil {
+			return err
+		}
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0o755); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			n, err := writeFile(target, reader, maxPackageBytes-total)
+			total += n
+			if err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// archivePath resolves an archive entry below dst, rejecting entries that
+// would escape it.
+func archivePath(dst, name string) (string, error) {
+	name = strings.ReplaceAll(name, `\`, "/")
+	if path.IsAbs(name) || slices.Contains(strings.Split(name, "/"), "..") {
+		return "", fmt.Errorf("unsafe path in archive: %s", name)
+	}
+	return filepath.Join(dst, filepath.FromSlash(path.Clean(name))), nil
+}
+
+func writeFile(target string, r io.Reader, limit int64) (int64, error) {
+	if limit <= 0 {
+		return 0, fmt.Errorf("package exceeds %d MB", maxPackageBytes>>20)
+	}
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return 0, err
+	}
+	out, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+	if err != nil {
+		return 0, err
+	}
+	n, err := io.Copy(out, io.LimitReader(r, limit+1))
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil && n > limit {
+		err = fmt.Errorf("package exceeds %d MB", maxPackageBytes>>20)
+	}
+	return n, err
+}
+
+func fileSHA256(name string) (string, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	hash := sha256.New()
+	if _, err := io.Copy(hash, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// ParseTrustedKeys decodes comma-separated base64 ed25519 public keys.
+func ParseTrustedKeys(raw string) ([]ed25519.PublicKey, error) {
+	var keys []ed25519.PublicKey
+	for _, part := range strings.FieldsFunc(raw, func(r rune) bool { return r == ',' || r == ' ' || r == '\n' }) {
+		decoded, err := base64.StdEncoding.DecodeString(part)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted key %q: %w", part, err)
+		}
+		if len(decoded) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("invalid trusted key %q: want %d bytes", part, ed25519.PublicKeySize)
+		}
+		keys = append(keys, ed25519.PublicKey(decoded))
+	}
+	return keys, nil
+}
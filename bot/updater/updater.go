@@ -2,43 +2,278 @@ package updater
 
 import (
 	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/liuran001/MusicBot-Go/bot"
 )
 
-// Updater provides a placeholder for dynamic update integration.
+// Options configures an Updater.
+type Options struct {
+	// Interval between scans of the script directory. Zero disables watching;
+	// Install and Reload still work.
+	Interval time.Duration
+	// Plugins returns the configured plugin names. Only their directories are
+	// watched. Nil watches every directory.
+	Plugins func() []string
+	// Reload swaps in the current files of one plugin. It must keep the
+	// running version when the new one fails to load.
+	Reload func(ctx context.Context, name string) error
+	// Pin returns the version a plugin is pinned to, or "" when unpinned.
+	Pin func(name string) string
+	// RequireChecksum rejects packages whose manifest lists no file checksums.
+	RequireChecksum bool
+	// RequireSignature rejects packages without a manifest signature made by
+	// one of TrustedKeys.
+	RequireSignature bool
+	TrustedKeys      []ed25519.PublicKey
+}
+
+// Updater watches PluginScriptDir and reloads a script plugin when its files
+// change, and installs verified plugin packages into that directory.
 type Updater struct {
-	srcPath  string
-	checkMD5 bool
-	logger   bot.Logger
+	srcPath string
+	opts    Options
+	logger  bot.Logger
+
+	mu sync.Mutex
+	// loaded is the fingerprint of each plugin's files as last handed to
+	// Reload, successful or not, so a broken save is not retried until the
+	// files change again.
+	loaded map[string]string
+	// pending holds a changed fingerprint seen by one scan; it is acted on
+	// when the next scan sees it unchanged, so half-written saves are skipped.
+	pending map[string]string
 }
 
-// New creates an updater instance.
-func New(srcPath string, checkMD5 bool, logger bot.Logger) *Updater {
+// New creates an updater for the script plugins under srcPath.
+func New(srcPath string, opts Options, logger bot.Logger) *Updater {
 	return &Updater{
-		srcPath:  srcPath,
-		checkMD5: checkMD5,
-		logger:   logger,
+		srcPath: srcPath,
+		opts:    opts,
+		logger:  logger,
+		loaded:  make(map[string]string),
+		pending: make(map[string]string),
 	}
 }
 
-// CheckUpdate reports whether updates are available.
-func (u *Updater) CheckUpdate(ctx context.Context) (bool, error) {
-	_ = ctx
-	return false, nil
+// Prime records the current files as loaded. Call it once after the initial
+// plugin load so that Start does not reload everything on its first scan.
+func (u *Updater) Prime() error {
+	prints, err := u.fingerprints()
+	if err != nil {
+		return err
+	}
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	for name, sum := range prints {
+		u.loaded[name] = sum
+	}
+	return nil
 }
 
-// LoadEntry loads an entry point from source.
-func (u *Updater) LoadEntry(entry string) (func(), error) {
-	if entry == "" {
-		return nil, fmt.Errorf("entry required")
+// Start scans the script directory every Interval until ctx is done.
+func (u *Updater) Start(ctx context.Context) {
+	if u.opts.Interval <= 0 {
+		return
 	}
-	return nil, nil
+	go func() {
+		ticker := time.NewTicker(u.opts.Interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := u.poll(ctx); err != nil && u.logger != nil {
+					u.logger.Warn("script plugin watch failed", "dir", u.srcPath, "error", err)
+				}
+			}
+		}
+	}()
 }
 
-// Reload reloads the updater source.
-func (u *Updater) Reload(ctx context.Context) error {
+// CheckUpdate reports the plugins whose files differ from the loaded ones.
+func (u *Updater) CheckUpdate(ctx context.Context) ([]string, error) {
 	_ = ctx
+	prints, err := u.fingerprints()
+	if err != nil {
+		return nil, err
+	}
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	changed := make([]string, 0)
+	for name, sum := range prints {
+		if u.loaded[name] != sum {
+			changed = append(changed, name)
+		}
+	}
+	sort.Strings(changed)
+	return changed, nil
+}
+
+// Reload reloads every plugin reported by CheckUpdate, without waiting for
+// the files to settle. Failures are collected; the other plugins still load.
+func (u *Updater) Reload(ctx context.Context) error {
+	changed, err := u.CheckUpdate(ctx)
+	if err != nil {
+		return err
+	}
+	var failed []string
+	for _, name := range changed {
+		if err := u.ReloadPlugin(ctx, name); err != nil {
+			failed = append(failed, fmt.Sprintf("%s: %v", name, err))
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("reload script plugins: %s", strings.Join(failed, "; "))
+	}
 	return nil
 }
+
+// ReloadPlugin reloads one plugin from its current files.
+func (u *Updater) ReloadPlugin(ctx context.Context, name string) error {
+	sum, err := fingerprintDir(filepath.Join(u.srcPath, name))
+	if err != nil {
+		return err
+	}
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	return u.reloadLocked(ctx, name, sum)
+}
+
+func (u *Updater) reloadLocked(ctx context.Context, name, sum string) error {
+	if u.opts.Reload == nil {
+		return fmt.Errorf("reload not configured")
+	}
+	u.loaded[name] = sum
+	delete(u.pending, name)
+	if err := u.opts.Reload(ctx, name); err != nil {
+		if u.logger != nil {
+			u.logger.Warn("script plugin reload failed, keeping previous version", "plugin", name, "error", err)
+		}
+		return err
+	}
+	if u.logger != nil {
+		u.logger.Info("script plugin reloaded from disk", "plugin", name)
+	}
+	return nil
+}
+
+func (u *Updater) poll(ctx context.Context) error {
+	prints, err := u.fingerprints()
+	if err != nil {
+		return err
+	}
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	names := make([]string, 0, len(prints))
+	for name := range prints {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		sum := prints[name]
+		if u.loaded[name] == sum {
+			delete(u.pending, name)
+			continue
+		}
+		if u.pending[name] != sum {
+			u.pending[name] = sum
+			continue
+		}
+		_ = u.reloadLocked(ctx, name, sum)
+	}
+	return nil
+}
+
+// fingerprints hashes the files of every watched plugin directory. Hidden
+// directories (install staging and backups) are skipped.
+func (u *Updater) fingerprints() (map[string]string, error) {
+	entries, err := os.ReadDir(u.srcPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return map[string]string{}, nil
+		}
+		return nil, err
+	}
+	var watched map[string]bool
+	if u.opts.Plugins != nil {
+		watched = make(map[string]bool)
+		for _, name := range u.opts.Plugins() {
+			watched[name] = true
+		}
+	}
+	prints := make(map[string]string)
+	for _, entry := range entries {
+		name := entry.Name()
+		if !entry.IsDir() || strings.HasPrefix(name, ".") {
+			continue
+		}
+		if watched != nil && !watched[name] {
+			continue
+		}
+		sum, err := fingerprintDir(filepath.Join(u.srcPath, name))
+		if err != nil {
+			return nil, err
+		}
+		if sum != "" {
+			prints[name] = sum
+		}
+	}
+	return prints, nil
+}
+
+// fingerprintDir hashes the names and contents of the .go files the loader
+// evaluates. It returns "" for a directory without any.
+func fingerprintDir(dir string) (string, error) {
+	files, err := scriptFiles(dir)
+	if err != nil {
+		return "", err
+	}
+	if len(files) == 0 {
+		return "", nil
+	}
+	hash := sha256.New()
+	for _, name := range files {
+		f, err := os.Open(filepath.Join(dir, name))
+		if err != nil {
+			return "", err
+		}
+		_, _ = io.WriteString(hash, name+"\x00")
+		_, err = io.Copy(hash, f)
+		f.Close()
+		if err != nil {
+			return "", err
+		}
+		_, _ = hash.Write([]byte{0})
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// scriptFiles lists the files dynplugin loads from a plugin directory.
+func scriptFiles(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	files := make([]string, 0)
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".go") || strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+		files = append(files, name)
+	}
+	sort.Strings(files)
+	return files, nil
+}
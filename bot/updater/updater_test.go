@@ -0,0 +1,299 @@
+package updater
+
+import (
+	"archive/zip"
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+type reloadRecorder struct {
+	calls []string
+	fail  map[string]error
+}
+
+func (r *reloadRecorder) reload(ctx context.Context, name string) error {
+	r.calls = append(r.calls, name)
+	return r.fail[name]
+}
+
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func sha256Hex(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
+func TestPollReloadsChangedPluginOnceSettled(t *testing.T) {
+	ctx := context.Background()
+	root := t.TempDir()
+	writeFiles(t, filepath.Join(root, "demo"), map[string]string{"demo.go": "package demo\n"})
+	writeFiles(t, filepath.Join(root, "other"), map[string]string{"other.go": "package other\n"})
+	writeFiles(t, filepath.Join(root, ".staging-1"), map[string]string{"x.go": "package x\n"})
+
+	recorder := &reloadRecorder{fail: map[string]error{}}
+	u := New(root, Options{Reload: recorder.reload}, nil)
+	if err := u.Prime(); err != nil {
+		t.Fatal(err)
+	}
+	if err := u.poll(ctx); err != nil || len(recorder.calls) != 0 {
+		t.Fatalf("unchanged tree reloaded %v, %v", recorder.calls, err)
+	}
+
+	writeFiles(t, filepath.Join(root, "demo"), map[string]string{"demo.go": "package demo\n// v2\n"})
+	if changed, _ := u.CheckUpdate(ctx); strings.Join(changed, ",") != "demo" {
+		t.Fatalf("CheckUpdate = %v", changed)
+	}
+	_ = u.poll(ctx)
+	if len(recorder.calls) != 0 {
+		t.Fatalf("first sighting of a change must wait for the next scan, got %v", recorder.calls)
+	}
+	_ = u.poll(ctx)
+	if strings.Join(recorder.calls, ",") != "demo" {
+		t.Fatalf("reloads = %v", recorder.calls)
+	}
+
+	// A failing version is not retried until the files change again.
+	recorder.fail["demo"] = errors.New("compile error")
+	writeFiles(t, filepath.Join(root, "demo"), map[string]string{"demo.go": "package demo\nfunc (\n"})
+	for i := 0; i < 4; i++ {
+		_ = u.poll(ctx)
+	}
+	if len(recorder.calls) != 2 {
+		t.Fatalf("broken save should be tried once, reloads = %v", recorder.calls)
+	}
+}
+
+func TestPollOnlyWatchesConfiguredPlugins(t *testing.T) {
+	ctx := context.Background()
+	root := t.TempDir()
+	recorder := &reloadRecorder{}
+	u := New(root, Options{Reload: recorder.reload, Plugins: func() []string { return []string{"demo"} }}, nil)
+	writeFiles(t, filepath.Join(root, "demo"), map[string]string{"demo.go": "package demo\n"})
+	writeFiles(t, filepath.Join(root, "stray"), map[string]string{"stray.go": "package stray\n"})
+	_ = u.poll(ctx)
+	_ = u.poll(ctx)
+	if strings.Join(recorder.calls, ",") != "demo" {
+		t.Fatalf("reloads = %v", recorder.calls)
+	}
+}
+
+type testPackage struct {
+	manifest Manifest
+	files    map[string]string
+	sign     ed25519.PrivateKey
+}
+
+// build writes the package below a top-level directory, as archives from
+// source hosting usually are, and returns the package directory.
+func (p testPackage) build(t *testing.T) string {
+	t.Helper()
+	dir := filepath.Join(t.TempDir(), "checkout")
+	files := map[string]string{}
+	for name, content := range p.files {
+		files[name] = content
+	}
+	raw, err := json.Marshal(p.manifest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	files[ManifestFile] = string(raw)
+	if p.sign != nil {
+		files[SignatureFile] = base64.StdEncoding.EncodeToString(ed25519.Sign(p.sign, raw))
+	}
+	writeFiles(t, dir, files)
+	writeFiles(t, filepath.Join(dir, ".git"), map[string]string{"HEAD": "ref: refs/heads/main\n"})
+	return dir
+}
+
+func zipDir(t *testing.T, dir string) string {
+	t.Helper()
+	archive := filepath.Join(t.TempDir(), "plugin.zip")
+	out, err := os.Create(archive)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writer := zip.NewWriter(out)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		content, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		w, err := writer.Create("demo-1.1.0/" + entry.Name())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write(content); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := out.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return archive
+}
+
+func TestInstallVerifiesAndSwapsPackage(t *testing.T) {
+	ctx := context.Background()
+	root := t.TempDir()
+	writeFiles(t, filepath.Join(root, "demo"), map[string]string{"demo.go": "package demo\n// old\n"})
+	source := "package demo\n// new\n"
+	pkg := testPackage{
+		manifest: Manifest{Name: "demo", Version: "1.1.0", Files: map[string]string{"demo.go": sha256Hex(source)}},
+		files:    map[string]string{"demo.go": source},
+	}
+	archive := zipDir(t, pkg.build(t))
+	archiveSum, err := fileSHA256(archive)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	recorder := &reloadRecorder{fail: map[string]error{}}
+	pins := map[string]string{"demo": "1.1"}
+	u := New(root, Options{Reload: recorder.reload, Pin: func(name string) string { return pins[name] }}, nil)
+
+	if _, err := u.Install(ctx, archive, strings.Repeat("0", 64)); err == nil || !strings.Contains(err.Error(), "archive checksum") {
+		t.Fatalf("expected archive checksum error, got %v", err)
+	}
+	manifest, err := u.Install(ctx, archive, archiveSum)
+	if err != nil {
+		t.Fatalf("install: %v", err)
+	}
+	if manifest.Version != "1.1.0" || strings.Join(recorder.calls, ",") != "demo" {
+		t.Fatalf("manifest = %+v, reloads = %v", manifest, recorder.calls)
+	}
+	if got, _ := os.ReadFile(filepath.Join(root, "demo", "demo.go")); string(got) != source {
+		t.Fatalf("installed file = %q", got)
+	}
+	if changed, _ := u.CheckUpdate(ctx); len(changed) != 0 {
+		t.Fatalf("installed version should count as loaded, changed = %v", changed)
+	}
+
+	// A version that fails to load restores the previous files.
+	recorder.fail["demo"] = errors.New("compile error")
+	broken := pkg
+	broken.manifest.Version = "1.1.1"
+	if _, err := u.Install(ctx, broken.build(t), ""); err == nil {
+		t.Fatal("expected load failure")
+	}
+	if got, _ := os.ReadFile(filepath.Join(root, "demo", "demo.go")); string(got) != source {
+		t.Fatalf("previous version not restored: %q", got)
+	}
+	entries, _ := os.ReadDir(root)
+	if len(entries) != 1 {
+		t.Fatalf("staging or backup left behind: %v", entries)
+	}
+
+	pins["demo"] = "2.0"
+	if _, err := u.Install(ctx, pkg.build(t), ""); err == nil || !strings.Contains(err.Error(), "pinned") {
+		t.Fatalf("expected pin error, got %v", err)
+	}
+}
+
+func TestInstallRejectsTamperedOrUnsignedPackages(t *testing.T) {
+	ctx := context.Background()
+	public, private, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, otherKey, _ := ed25519.GenerateKey(nil)
+	source := "package demo\n"
+	base := testPackage{
+		manifest: Manifest{Name: "demo", Version: "1.0.0", Files: map[string]string{"demo.go": sha256Hex(source)}},
+		files:    map[string]string{"demo.go": source},
+	}
+
+	cases := []struct {
+		name string
+		pkg  func() testPackage
+		opts Options
+		want string
+	}{
+		{"tampered file", func() testPackage {
+			p := base
+			p.files = map[string]string{"demo.go": "package demo\n// injected\n"}
+			return p
+		}, Options{}, "checksum mismatch"},
+		{"unlisted file", func() testPackage {
+			p := base
+			p.files = map[string]string{"demo.go": source, "extra.go": "package demo\n"}
+			return p
+		}, Options{}, "not listed"},
+		{"checksums required", func() testPackage {
+			p := base
+			p.manifest.Files = nil
+			return p
+		}, Options{RequireChecksum: true}, "no file checksums"},
+		{"signature required", func() testPackage { return base }, Options{RequireSignature: true, TrustedKeys: []ed25519.PublicKey{public}}, "not signed"},
+		{"untrusted signer", func() testPackage {
+			p := base
+			p.sign = otherKey
+			return p
+		}, Options{TrustedKeys: []ed25519.PublicKey{public}}, "trusted key"},
+		{"invalid name", func() testPackage {
+			p := base
+			p.manifest.Name = "../demo"
+			return p
+		}, Options{}, "invalid plugin name"},
+	}
+	for _, tc := range cases {
+		recorder := &reloadRecorder{}
+		tc.opts.Reload = recorder.reload
+		u := New(t.TempDir(), tc.opts, nil)
+		_, err := u.Install(ctx, tc.pkg().build(t), "")
+		if err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("%s: error = %v, want %q", tc.name, err, tc.want)
+		}
+		if len(recorder.calls) != 0 {
+			t.Errorf("%s: rejected package was loaded", tc.name)
+		}
+	}
+
+	signed := base
+	signed.sign = private
+	recorder := &reloadRecorder{}
+	u := New(t.TempDir(), Options{Reload: recorder.reload, RequireSignature: true, TrustedKeys: []ed25519.PublicKey{public}}, nil)
+	if _, err := u.Install(ctx, signed.build(t), ""); err != nil {
+		t.Fatalf("signed install: %v", err)
+	}
+}
+
+func TestParseTrustedKeys(t *testing.T) {
+	public, _, _ := ed25519.GenerateKey(nil)
+	encoded := base64.StdEncoding.EncodeToString(public)
+	keys, err := ParseTrustedKeys(encoded + ", " + encoded)
+	if err != nil || len(keys) != 2 {
+		t.Fatalf("keys = %d, %v", len(keys), err)
+	}
+	if _, err := ParseTrustedKeys("c2hvcnQ="); err == nil {
+		t.Fatal("short key should be rejected")
+	}
+}
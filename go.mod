@@ -9,6 +9,7 @@ require (
 	github.com/glebarez/sqlite v1.11.0
 	github.com/go-flac/go-flac v1.0.0
 	github.com/guohuiyuan/music-lib v1.0.6-0.20260308165809-ea321c84b16a
+	github.com/hajimehoshi/go-mp3 v0.3.4
 	github.com/hashicorp/go-retryablehttp v0.7.8
 	github.com/iyear/gowidevine v0.1.3
 	github.com/mewkiz/flac v1.0.13
@@ -21,6 +22,7 @@ require (
 	github.com/stretchr/testify v1.11.1
 	github.com/traefik/yaegi v0.16.1
 	go.senan.xyz/taglib v0.11.1
+	golang.org/x/crypto v0.54.0
 	golang.org/x/oauth2 v0.34.0
 	golang.org/x/sync v0.22.0
 	golang.org/x/time v0.14.0
@@ -36,7 +38,6 @@ require (
 	github.com/mewkiz/pkg v0.0.0-20250417130911-3f050ff8c56d // indirect
 	github.com/mewpkg/term v0.0.0-20241026122259-37a80af23985 // indirect
 	github.com/stretchr/objx v0.5.3 // indirect
-	golang.org/x/crypto v0.54.0 // indirect
 	golang.org/x/net v0.57.0 // indirect
 )
 
@@ -3,6 +3,7 @@ package all
 import (
 	_ "github.com/liuran001/MusicBot-Go/plugins/applemusic"
 	_ "github.com/liuran001/MusicBot-Go/plugins/bilibili"
+	_ "github.com/liuran001/MusicBot-Go/plugins/httpapi"
 	_ "github.com/liuran001/MusicBot-Go/plugins/kugou"
 	_ "github.com/liuran001/MusicBot-Go/plugins/kuwo"
 	_ "github.com/liuran001/MusicBot-Go/plugins/netease"
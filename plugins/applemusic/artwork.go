@@ -0,0 +1,94 @@
+package applemusic
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+
+	"github.com/liuran001/MusicBot-Go/bot/platform"
+)
+
+// artworkSizeSuffix matches the size segment mzstatic image URLs end with,
+// e.g. "/1200x1200bb.jpg".
+var artworkSizeSuffix = regexp.MustCompile(`/\d+x\d+[a-z]*(-\d+)?\.(jpg|jpeg|png|webp)$`)
+
+// originalArtworkSuffix asks mzstatic for the uploaded master: a size larger
+// than any artwork is clamped to the source dimensions, and -999 disables
+// recompression.
+const originalArtworkSuffix = "/100000x100000-999.jpg"
+
+// CoverURLAtSize implements platform.ArtworkProvider.
+func (p *AppleMusicPlatform) CoverURLAtSize(coverURL string, size int) string {
+	if !artworkSizeSuffix.MatchString(coverURL) {
+		return coverURL
+	}
+	suffix := originalArtworkSuffix
+	if size > 0 {
+		suffix = fmt.Sprintf("/%dx%dbb.jpg", size, size)
+	}
+	return artworkSizeSuffix.ReplaceAllString(coverURL, suffix)
+}
+
+// editorialVideoKeys lists the motion artwork variants in order of
+// preference: square loops first, the portrait crop last.
+var editorialVideoKeys = []string{"motionDetailSquare", "motionSquareVideo1x1", "motionDetailTall", "motionTallVideo3x4"}
+
+type appleMusicEditorialVideo struct {
+	Video        string             `json:"video"`
+	PreviewFrame *appleMusicArtwork `json:"previewFrame,omitempty"`
+}
+
+// GetAnimatedArtwork implements platform.AnimatedArtworkProvider. The video is
+// an HLS playlist.
+func (p *AppleMusicPlatform) GetAnimatedArtwork(ctx context.Context, albumID string) (*platform.AnimatedArtwork, error) {
+	if p == nil || p.client == nil {
+		return nil, platform.NewUnavailableError("applemusic", "album", albumID)
+	}
+	return p.client.GetAnimatedArtwork(ctx, albumID)
+}
+
+// GetAnimatedArtwork loads an album's motion artwork from the catalog's
+// editorialVideo extension.
+func (c *Client) GetAnimatedArtwork(ctx context.Context, albumID string) (*platform.AnimatedArtwork, error) {
+	reqURL := fmt.Sprintf("%s/v1/catalog/%s/albums/%s?extend=editorialVideo&l=%s",
+		appleMusicBaseURL, c.storefront, albumID, c.language)
+
+	body, err := c.doRequest(ctx, reqURL)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp struct {
+		Data []struct {
+			Attributes struct {
+				EditorialVideo map[string]appleMusicEditorialVideo `json:"editorialVideo"`
+			} `json:"attributes"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, fmt.Errorf("applemusic: parse album response: %w", err)
+	}
+	if len(resp.Data) == 0 {
+		return nil, platform.NewNotFoundError("applemusic", "album", albumID)
+	}
+	if artwork := pickEditorialVideo(resp.Data[0].Attributes.EditorialVideo); artwork != nil {
+		return artwork, nil
+	}
+	return nil, platform.NewNotFoundError("applemusic", "animated artwork", albumID)
+}
+
+func pickEditorialVideo(videos map[string]appleMusicEditorialVideo) *platform.AnimatedArtwork {
+	for _, key := range editorialVideoKeys {
+		video, ok := videos[key]
+		if !ok || video.Video == "" {
+			continue
+		}
+		artwork := &platform.AnimatedArtwork{URL: video.Video}
+		if video.PreviewFrame != nil {
+			artwork.Width, artwork.Height = video.PreviewFrame.Width, video.PreviewFrame.Height
+		}
+		return artwork
+	}
+	return nil
+}
@@ -0,0 +1,35 @@
+package applemusic
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestCoverURLAtSize(t *testing.T) {
+	p := &AppleMusicPlatform{}
+	cover := "https://is1-ssl.mzstatic.com/image/thumb/Music126/v4/aa/bb/cc/x.jpg/1200x1200bb.jpg"
+	if got, want := p.CoverURLAtSize(cover, 0), "https://is1-ssl.mzstatic.com/image/thumb/Music126/v4/aa/bb/cc/x.jpg/100000x100000-999.jpg"; got != want {
+		t.Fatalf("CoverURLAtSize(original) = %q, want %q", got, want)
+	}
+	if got, want := p.CoverURLAtSize(cover, 600), "https://is1-ssl.mzstatic.com/image/thumb/Music126/v4/aa/bb/cc/x.jpg/600x600bb.jpg"; got != want {
+		t.Fatalf("CoverURLAtSize(600) = %q, want %q", got, want)
+	}
+	if got := p.CoverURLAtSize("https://example.com/cover.png", 0); got != "https://example.com/cover.png" {
+		t.Fatalf("CoverURLAtSize(foreign) = %q", got)
+	}
+}
+
+func TestPickEditorialVideo(t *testing.T) {
+	var videos map[string]appleMusicEditorialVideo
+	raw := `{"motionDetailTall":{"video":"https://tall.m3u8"},"motionSquareVideo1x1":{"video":"https://square.m3u8","previewFrame":{"width":3840,"height":3840}}}`
+	if err := json.Unmarshal([]byte(raw), &videos); err != nil {
+		t.Fatal(err)
+	}
+	got := pickEditorialVideo(videos)
+	if got == nil || got.URL != "https://square.m3u8" || got.Width != 3840 {
+		t.Fatalf("pickEditorialVideo() = %+v", got)
+	}
+	if pickEditorialVideo(nil) != nil {
+		t.Fatal("pickEditorialVideo(nil) should find nothing")
+	}
+}
@@ -0,0 +1,29 @@
+package applemusic
+
+import (
+	"context"
+
+	"github.com/liuran001/MusicBot-Go/bot/platform"
+)
+
+// appleMusicCharts lists the Top 100 editorial playlists exposed through /charts.
+var appleMusicCharts = []platform.Chart{
+	{ID: "pl.d25f5d1181894928af76c85c967f8f31", Platform: "applemusic", Title: "Top 100: Global"},
+	{ID: "pl.606afcbb70264d2eb2b51d8dbcfa6a12", Platform: "applemusic", Title: "Top 100: USA"},
+}
+
+// ListCharts implements platform.ChartProvider.
+func (p *AppleMusicPlatform) ListCharts(ctx context.Context) ([]platform.Chart, error) {
+	_ = ctx
+	return append([]platform.Chart(nil), appleMusicCharts...), nil
+}
+
+// GetChart implements platform.ChartProvider. Apple Music charts are catalog
+// playlists, so this only guards the ID and delegates to GetPlaylist.
+func (p *AppleMusicPlatform) GetChart(ctx context.Context, chartID string) (*platform.Playlist, error) {
+	chart, ok := platform.FindChart(appleMusicCharts, chartID)
+	if !ok {
+		return nil, platform.NewNotFoundError("applemusic", "chart", chartID)
+	}
+	return p.GetPlaylist(ctx, chart.ID)
+}
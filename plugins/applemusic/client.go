@@ -1147,6 +1147,9 @@ type appleMusicAttributes struct {
 	ISRC              string                    `json:"isrc"`
 	ReleaseDate       string                    `json:"releaseDate"`
 	GenreNames        []string                  `json:"genreNames"`
+	ComposerName      string                    `json:"composerName"`
+	RecordLabel       string                    `json:"recordLabel"`
+	Copyright         string                    `json:"copyright"`
 	Artwork           *appleMusicArtwork        `json:"artwork,omitempty"`
 	Previews          []appleMusicPreview       `json:"previews,omitempty"`
 	PlayParams        *appleMusicPlayParams     `json:"playParams,omitempty"`
@@ -1222,9 +1225,11 @@ func convertSong(res appleMusicResource) platform.Track {
 	}
 
 	year := 0
+	var releaseDate *time.Time
 	if attrs.ReleaseDate != "" {
 		if t, err := time.Parse("2006-01-02", attrs.ReleaseDate); err == nil {
 			year = t.Year()
+			releaseDate = &t
 		} else if t, err := time.Parse("2006", attrs.ReleaseDate); err == nil {
 			year = t.Year()
 		}
@@ -1243,10 +1248,25 @@ func convertSong(res appleMusicResource) platform.Track {
 		Year:           year,
 		TrackNumber:    attrs.TrackNumber,
 		DiscNumber:     attrs.DiscNumber,
+		ReleaseDate:    releaseDate,
+		Composers:      platform.SplitCredits(attrs.ComposerName),
+		Genres:         appleMusicGenres(attrs.GenreNames),
 		AtmosAvailable: atmosAvailable,
 	}
 }
 
+// appleMusicGenres drops the catch-all "Music" genre Apple appends to every
+// genre list.
+func appleMusicGenres(names []string) []string {
+	var genres []string
+	for _, name := range names {
+		if name = strings.TrimSpace(name); name != "" && !strings.EqualFold(name, "Music") && name != "音乐" && name != "音樂" && name != "ミュージック" {
+			genres = append(genres, name)
+		}
+	}
+	return genres
+}
+
 func appleMusicAtmosAvailability(audioTraits, audioVariants []string) *bool {
 	// audioVariants is the current, more precise catalog signal. Its presence
 	// is authoritative even when the list is empty; audioTraits is retained as
@@ -1303,6 +1323,9 @@ func convertAlbum(res appleMusicResource) platform.Album {
 		TrackCount:  attrs.TrackCount,
 		URL:         attrs.URL,
 		Year:        year,
+		Label:       strings.TrimSpace(attrs.RecordLabel),
+		Copyright:   strings.TrimSpace(attrs.Copyright),
+		Genres:      appleMusicGenres(attrs.GenreNames),
 	}
 }
 
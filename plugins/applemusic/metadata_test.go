@@ -2,6 +2,7 @@ package applemusic
 
 import (
 	"encoding/json"
+	"reflect"
 	"testing"
 )
 
@@ -84,3 +85,22 @@ func TestConvertSongAtmosAvailability(t *testing.T) {
 		})
 	}
 }
+
+func TestConvertSongCredits(t *testing.T) {
+	var resource appleMusicResource
+	fixture := `{"id":"1","attributes":{"name":"Song","composerName":"A, B & C","isrc":"USRC17607839","releaseDate":"2024-03-01","genreNames":["Pop","Music"]},
+		"relationships":{"albums":{"data":[{"id":"2","attributes":{"name":"Album","recordLabel":"Label","copyright":"℗ 2024 Label","genreNames":["Pop","Music"]}}]}}}`
+	if err := json.Unmarshal([]byte(fixture), &resource); err != nil {
+		t.Fatalf("unmarshal fixture: %v", err)
+	}
+	track := convertSong(resource)
+	if !reflect.DeepEqual(track.Composers, []string{"A", "B", "C"}) || !reflect.DeepEqual(track.Genres, []string{"Pop"}) {
+		t.Fatalf("composers = %v, genres = %v", track.Composers, track.Genres)
+	}
+	if track.ReleaseDate == nil || track.ReleaseDate.Format("2006-01-02") != "2024-03-01" {
+		t.Fatalf("ReleaseDate = %v", track.ReleaseDate)
+	}
+	if track.Album == nil || track.Album.Label != "Label" || track.Album.Copyright != "℗ 2024 Label" {
+		t.Fatalf("Album = %+v", track.Album)
+	}
+}
@@ -50,11 +50,12 @@ func buildContribution(cfg *config.Config, logger *logpkg.Logger) (*platformplug
 	client := NewClient(mediaUserToken, storefront, language, time.Duration(timeoutSec)*time.Second, logger)
 	client.languageExplicit = languageExplicit
 	client.wrapperHost = strings.TrimSpace(cfg.GetPluginString("applemusic", "wrapper_host"))
+	persist := cfg.PluginPersister("applemusic")
 	client.persistFunc = func(pairs map[string]string) error {
 		if logger != nil {
 			logger.Debug("applemusic: persist plugin config", "pairs", pairs)
 		}
-		return cfg.PersistPluginConfig("applemusic", pairs)
+		return persist(pairs)
 	}
 
 	// Load Widevine L3 device for native DRM decryption.
@@ -36,9 +36,7 @@ func buildContribution(cfg *config.Config, logger *logpkg.Logger) (*platformplug
 	if searchMaxPages <= 0 {
 		searchMaxPages = 5
 	}
-	persist := func(pairs map[string]string) error {
-		return cfg.PersistPluginConfig("bilibili", pairs)
-	}
+	persist := cfg.PluginPersister("bilibili")
 
 	client := New(logger, cookie, refreshToken, autoRenewEnabled, interval, persist)
 	client.StartAutoRefreshDaemon(context.Background())
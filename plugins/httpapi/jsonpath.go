@@ -0,0 +1,135 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+)
+
+// resolve walks a dot-separated path through decoded JSON. A numeric segment
+// indexes an array and "*" fans out over every element, so "ar.*.name"
+// collects all artist names. An empty path selects the value itself. Arrays
+// left at the end of the path are flattened into the result.
+func resolve(value interface{}, path string) []interface{} {
+	current := []interface{}{value}
+	path = strings.TrimSpace(path)
+	if path != "" {
+		for _, segment := range strings.Split(path, ".") {
+			next := make([]interface{}, 0, len(current))
+			for _, item := range current {
+				next = append(next, step(item, segment)...)
+			}
+			current = next
+			if len(current) == 0 {
+				return nil
+			}
+		}
+	}
+	result := make([]interface{}, 0, len(current))
+	for _, item := range current {
+		if list, ok := item.([]interface{}); ok {
+			result = append(result, list...)
+			continue
+		}
+		if item != nil {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+func step(value interface{}, segment string) []interface{} {
+	switch node := value.(type) {
+	case map[string]interface{}:
+		child, ok := node[segment]
+		if !ok || child == nil {
+			return nil
+		}
+		return []interface{}{child}
+	case []interface{}:
+		if segment == "*" {
+			return node
+		}
+		index, err := strconv.Atoi(segment)
+		if err != nil || index < 0 || index >= len(node) {
+			return nil
+		}
+		return []interface{}{node[index]}
+	}
+	return nil
+}
+
+// first returns the node at path without flattening, for selecting a list or
+// an object.
+func first(value interface{}, path string) interface{} {
+	current := value
+	path = strings.TrimSpace(path)
+	if path == "" {
+		return current
+	}
+	for _, segment := range strings.Split(path, ".") {
+		items := step(current, segment)
+		if len(items) == 0 {
+			return nil
+		}
+		current = items[0]
+	}
+	return current
+}
+
+// stringAt returns the first scalar at path as a string.
+func stringAt(value interface{}, path string) string {
+	if strings.TrimSpace(path) == "" {
+		return ""
+	}
+	for _, item := range resolve(value, path) {
+		if text := scalarString(item); text != "" {
+			return text
+		}
+	}
+	return ""
+}
+
+// stringsAt returns every scalar at path as strings.
+func stringsAt(value interface{}, path string) []string {
+	if strings.TrimSpace(path) == "" {
+		return nil
+	}
+	items := resolve(value, path)
+	result := make([]string, 0, len(items))
+	for _, item := range items {
+		if text := scalarString(item); text != "" {
+			result = append(result, text)
+		}
+	}
+	return result
+}
+
+// int64At returns the first number at path; numeric strings are accepted.
+func int64At(value interface{}, path string) int64 {
+	text := stringAt(value, path)
+	if text == "" {
+		return 0
+	}
+	if n, err := strconv.ParseInt(text, 10, 64); err == nil {
+		return n
+	}
+	if f, err := strconv.ParseFloat(text, 64); err == nil {
+		return int64(f)
+	}
+	return 0
+}
+
+func scalarString(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return strings.TrimSpace(v)
+	case json.Number:
+		return v.String()
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	}
+	return ""
+}
@@ -0,0 +1,376 @@
+package httpapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/liuran001/MusicBot-Go/bot/platform"
+)
+
+// maxResponseBytes bounds a single API response.
+const maxResponseBytes = 8 << 20
+
+// Platform maps a self-hosted music API onto platform.Platform as declared
+// in its config section.
+type Platform struct {
+	spec       *spec
+	httpClient *http.Client
+}
+
+func newPlatform(s *spec, httpClient *http.Client) *Platform {
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: s.timeout}
+	}
+	return &Platform{spec: s, httpClient: httpClient}
+}
+
+func (p *Platform) Name() string { return p.spec.name }
+
+func (p *Platform) SupportsDownload() bool { return p.spec.downloadURL != "" }
+
+func (p *Platform) SupportsSearch() bool { return p.spec.searchURL != "" }
+
+func (p *Platform) SupportsLyrics() bool { return p.spec.lyricsURL != "" }
+
+func (p *Platform) SupportsRecognition() bool { return false }
+
+func (p *Platform) Capabilities() platform.Capabilities {
+	return platform.Capabilities{
+		Download: p.SupportsDownload(),
+		Search:   p.SupportsSearch(),
+		Lyrics:   p.SupportsLyrics(),
+		HiRes:    p.SupportsDownload() && p.spec.hiRes,
+	}
+}
+
+func (p *Platform) Metadata() platform.Meta {
+	return platform.Meta{
+		Name:        p.spec.name,
+		DisplayName: p.spec.displayName,
+		Emoji:       p.spec.emoji,
+		Aliases:     p.spec.aliases,
+	}
+}
+
+func (p *Platform) Search(ctx context.Context, query string, limit int) ([]platform.Track, error) {
+	if p.spec.searchURL == "" {
+		return nil, platform.NewUnsupportedError(p.spec.name, "search")
+	}
+	if limit <= 0 {
+		limit = 10
+	}
+	body, err := p.get(ctx, p.spec.searchURL, map[string]string{"query": query, "limit": strconv.Itoa(limit)}, "search", query)
+	if err != nil {
+		return nil, err
+	}
+	items := resolve(body, p.spec.searchItems)
+	tracks := make([]platform.Track, 0, len(items))
+	for _, item := range items {
+		track, ok := p.toTrack(item)
+		if !ok {
+			continue
+		}
+		tracks = append(tracks, track)
+		if len(tracks) >= limit {
+			break
+		}
+	}
+	return tracks, nil
+}
+
+func (p *Platform) GetTrack(ctx context.Context, trackID string) (*platform.Track, error) {
+	if p.spec.trackURL == "" {
+		return nil, platform.NewUnsupportedError(p.spec.name, "track")
+	}
+	body, err := p.get(ctx, p.spec.trackURL, map[string]string{"id": trackID}, "track", trackID)
+	if err != nil {
+		return nil, err
+	}
+	item := first(body, p.spec.trackItem)
+	if list, ok := item.([]interface{}); ok {
+		if len(list) == 0 {
+			return nil, platform.NewNotFoundError(p.spec.name, "track", trackID)
+		}
+		item = list[0]
+	}
+	track, ok := p.toTrack(item)
+	if !ok {
+		return nil, platform.NewNotFoundError(p.spec.name, "track", trackID)
+	}
+	if track.ID == "" {
+		track.ID = trackID
+	}
+	return &track, nil
+}
+
+func (p *Platform) GetLyrics(ctx context.Context, trackID string) (*platform.Lyrics, error) {
+	if p.spec.lyricsURL == "" {
+		return nil, platform.NewUnsupportedError(p.spec.name, "lyrics")
+	}
+	body, err := p.get(ctx, p.spec.lyricsURL, map[string]string{"id": trackID}, "lyrics", trackID)
+	if err != nil {
+		return nil, err
+	}
+	lyric := stringAt(body, p.spec.lyricsPath)
+	if lyric == "" {
+		return nil, platform.NewUnavailableError(p.spec.name, "lyrics", trackID)
+	}
+	return &platform.Lyrics{
+		Plain:       lyric,
+		Timestamped: platform.ParseLRCTimestampedLines(lyric),
+		Translation: stringAt(body, p.spec.lyricsTranslationPath),
+	}, nil
+}
+
+func (p *Platform) GetDownloadInfo(ctx context.Context, trackID string, quality platform.Quality) (*platform.DownloadInfo, error) {
+	if p.spec.downloadURL == "" {
+		return nil, platform.NewUnsupportedError(p.spec.name, "download")
+	}
+	qualityValue, ok := p.spec.qualities[quality]
+	if !ok {
+		return nil, platform.NewInvalidQualityError(p.spec.name, trackID, quality)
+	}
+	body, err := p.get(ctx, p.spec.downloadURL, map[string]string{"id": trackID, "quality": qualityValue}, "track", trackID)
+	if err != nil {
+		return nil, err
+	}
+	// Some APIs answer with a one-element array.
+	if list, ok := body.([]interface{}); ok && len(list) > 0 {
+		body = list[0]
+	}
+	link := stringAt(body, p.spec.downloadURLPath)
+	if link == "" {
+		return nil, platform.NewUnavailableError(p.spec.name, "track", trackID)
+	}
+	info := &platform.DownloadInfo{
+		URL:            link,
+		Size:           int64At(body, p.spec.downloadSizePath),
+		Format:         strings.ToLower(stringAt(body, p.spec.downloadFormatPath)),
+		MD5:            stringAt(body, p.spec.downloadMD5Path),
+		Quality:        quality,
+		SizeIsAdvisory: true,
+	}
+	if info.Format == "" {
+		info.Format = formatFromURL(link)
+	}
+	if bitrate := int(int64At(body, p.spec.downloadBitratePath)); bitrate > 0 {
+		// Bitrates are reported in bps or kbps depending on the API.
+		if bitrate > 10000 {
+			bitrate /= 1000
+		}
+		info.Bitrate = bitrate
+	} else {
+		info.Bitrate = quality.Bitrate()
+	}
+	if len(p.spec.headers) > 0 {
+		info.Headers = make(map[string]string, len(p.spec.headers))
+		for key, value := range p.spec.headers {
+			info.Headers[key] = value
+		}
+	}
+	return info, nil
+}
+
+func (p *Platform) GetPlaylist(ctx context.Context, playlistID string) (*platform.Playlist, error) {
+	if p.spec.playlistURL == "" {
+		return nil, platform.NewUnsupportedError(p.spec.name, "playlist")
+	}
+	body, err := p.get(ctx, p.spec.playlistURL, map[string]string{"id": playlistID}, "playlist", playlistID)
+	if err != nil {
+		return nil, err
+	}
+	playlist := &platform.Playlist{
+		ID:          playlistID,
+		Platform:    p.spec.name,
+		Title:       stringAt(body, p.spec.playlistTitlePath),
+		CoverURL:    stringAt(body, p.spec.playlistCoverPath),
+		Description: stringAt(body, p.spec.playlistDescriptionPath),
+	}
+	for _, item := range resolve(body, p.spec.playlistItems) {
+		if track, ok := p.toTrack(item); ok {
+			playlist.Tracks = append(playlist.Tracks, track)
+		}
+	}
+	if len(playlist.Tracks) == 0 && playlist.Title == "" {
+		return nil, platform.NewNotFoundError(p.spec.name, "playlist", playlistID)
+	}
+	if playlist.Title == "" {
+		playlist.Title = playlistID
+	}
+	playlist.TrackCount = len(playlist.Tracks)
+	return playlist, nil
+}
+
+func (p *Platform) GetArtist(ctx context.Context, artistID string) (*platform.Artist, error) {
+	return nil, platform.NewUnsupportedError(p.spec.name, "get artist")
+}
+
+func (p *Platform) GetAlbum(ctx context.Context, albumID string) (*platform.Album, error) {
+	return nil, platform.NewUnsupportedError(p.spec.name, "get album")
+}
+
+func (p *Platform) RecognizeAudio(ctx context.Context, audioData io.Reader) (*platform.Track, error) {
+	return nil, platform.NewUnsupportedError(p.spec.name, "audio recognition")
+}
+
+func (p *Platform) MatchURL(rawURL string) (string, bool) {
+	return matchID(p.spec.matchURL, rawURL)
+}
+
+func (p *Platform) MatchPlaylistURL(rawURL string) (string, bool) {
+	return matchID(p.spec.matchPlaylistURL, rawURL)
+}
+
+func matchID(re *regexp.Regexp, rawURL string) (string, bool) {
+	if re == nil {
+		return "", false
+	}
+	matches := re.FindStringSubmatch(rawURL)
+	if len(matches) != 2 || matches[1] == "" {
+		return "", false
+	}
+	return matches[1], true
+}
+
+// creditsAt reads a list of names at path; a single joined string is split
+// like the artist field.
+func (p *Platform) creditsAt(item interface{}, path string) []string {
+	var names []string
+	for _, value := range stringsAt(item, path) {
+		if p.spec.artistSeparator != "" {
+			for _, part := range strings.Split(value, p.spec.artistSeparator) {
+				if part = strings.TrimSpace(part); part != "" {
+					names = append(names, part)
+				}
+			}
+			continue
+		}
+		names = append(names, value)
+	}
+	return names
+}
+
+// toTrack maps one song object through the configured field paths.
+func (p *Platform) toTrack(item interface{}) (platform.Track, bool) {
+	fields := p.spec.fields
+	track := platform.Track{
+		ID:       stringAt(item, fields.id),
+		Platform: p.spec.name,
+		Title:    stringAt(item, fields.title),
+		CoverURL: stringAt(item, fields.cover),
+		ISRC:     stringAt(item, fields.isrc),
+		URL:      stringAt(item, fields.link),
+	}
+	if track.ID == "" || track.Title == "" {
+		return platform.Track{}, false
+	}
+	names := stringsAt(item, fields.artists)
+	if p.spec.artistSeparator != "" {
+		split := make([]string, 0, len(names))
+		for _, name := range names {
+			for _, part := range strings.Split(name, p.spec.artistSeparator) {
+				if part = strings.TrimSpace(part); part != "" {
+					split = append(split, part)
+				}
+			}
+		}
+		names = split
+	}
+	ids := stringsAt(item, fields.artistIDs)
+	for i, name := range names {
+		artist := platform.Artist{Name: name, Platform: p.spec.name}
+		if i < len(ids) {
+			artist.ID = ids[i]
+		}
+		track.Artists = append(track.Artists, artist)
+	}
+	track.Composers = p.creditsAt(item, fields.composers)
+	track.Lyricists = p.creditsAt(item, fields.lyricists)
+	track.Genres = stringsAt(item, fields.genres)
+	if title := stringAt(item, fields.album); title != "" {
+		track.Album = &platform.Album{
+			ID:       stringAt(item, fields.albumID),
+			Platform: p.spec.name,
+			Title:    title,
+			Artists:  track.Artists,
+			CoverURL: track.CoverURL,
+			Label:    stringAt(item, fields.label),
+		}
+	}
+	if fields.duration != "" {
+		track.Duration = time.Duration(int64At(item, fields.duration)) * p.spec.durationUnit
+	}
+	if fields.year != "" {
+		track.Year = int(int64At(item, fields.year))
+	}
+	if fields.trackNumber != "" {
+		track.TrackNumber = int(int64At(item, fields.trackNumber))
+	}
+	if track.URL == "" && p.spec.trackLink != "" {
+		track.URL = strings.ReplaceAll(p.spec.trackLink, "{id}", track.ID)
+	}
+	return track, true
+}
+
+// get fetches and decodes one endpoint, mapping HTTP failures to platform
+// errors for resource/id.
+func (p *Platform) get(ctx context.Context, template string, values map[string]string, resource, id string) (interface{}, error) {
+	endpoint, err := p.spec.endpoint(template, values)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	for key, value := range p.spec.headers {
+		req.Header.Set(key, value)
+	}
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("httpapi %s: %w", p.spec.name, err)
+	}
+	defer resp.Body.Close()
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return nil, platform.NewNotFoundError(p.spec.name, resource, id)
+	case resp.StatusCode == http.StatusTooManyRequests:
+		return nil, platform.NewRateLimitedError(p.spec.name)
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		return nil, platform.NewAuthRequiredError(p.spec.name)
+	case resp.StatusCode < 200 || resp.StatusCode >= 300:
+		return nil, platform.NewUnavailableError(p.spec.name, resource, id)
+	}
+	raw, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseBytes))
+	if err != nil {
+		return nil, fmt.Errorf("httpapi %s: read response: %w", p.spec.name, err)
+	}
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+	var body interface{}
+	if err := decoder.Decode(&body); err != nil {
+		return nil, fmt.Errorf("httpapi %s: decode %s response: %w", p.spec.name, resource, err)
+	}
+	return body, nil
+}
+
+func formatFromURL(rawURL string) string {
+	if i := strings.IndexAny(rawURL, "?#"); i >= 0 {
+		rawURL = rawURL[:i]
+	}
+	switch ext := strings.ToLower(strings.TrimPrefix(path.Ext(rawURL), ".")); ext {
+	case "flac", "mp3", "m4a", "ogg", "opus", "aac", "wav":
+		return ext
+	}
+	return "mp3"
+}
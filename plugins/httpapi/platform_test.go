@@ -0,0 +1,255 @@
+package httpapi
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/liuran001/MusicBot-Go/bot/config"
+	"github.com/liuran001/MusicBot-Go/bot/platform"
+	platformplugins "github.com/liuran001/MusicBot-Go/bot/platform/plugins"
+)
+
+// metingServer answers like a meting-api deployment: /api?type=...&id=...
+func metingServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Token") != "secret" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		q := r.URL.Query()
+		w.Header().Set("Content-Type", "application/json")
+		switch q.Get("type") {
+		case "search":
+			if q.Get("keyword") != "晴天 周杰伦" {
+				_, _ = w.Write([]byte(`[]`))
+				return
+			}
+			_, _ = w.Write([]byte(`[
+				{"id": 186016, "name": "晴天", "artist": ["周杰伦"], "album": "叶惠美", "pic": "https://img.example/1.jpg"},
+				{"id": 186017, "name": "", "artist": ["nobody"]},
+				{"id": "186018", "name": "晴天 (Live)", "artist": ["周杰伦", "五月天"], "album": "Live"}
+			]`))
+		case "song":
+			if q.Get("id") != "186016" {
+				_, _ = w.Write([]byte(`[]`))
+				return
+			}
+			_, _ = w.Write([]byte(`[{"id": 186016, "name": "晴天", "artist": ["周杰伦"], "album": "叶惠美"}]`))
+		case "url":
+			if q.Get("br") != "999" {
+				_, _ = w.Write([]byte(`{"url": "https://cdn.example/186016.mp3?k=1", "size": 4000000, "br": 320000}`))
+				return
+			}
+			_, _ = w.Write([]byte(`{"url": "https://cdn.example/186016.flac", "size": 30000000, "br": 999000}`))
+		case "lrc":
+			_, _ = w.Write([]byte(`{"lyric": "[00:01.00]故事的小黄花\n[00:05.50]从出生那年就飘着", "tlyric": ""}`))
+		case "playlist":
+			_, _ = w.Write([]byte(`[{"id": 1, "name": "晴天", "artist": ["周杰伦"]}, {"id": 2, "name": "七里香", "artist": ["周杰伦"]}]`))
+		case "busy":
+			w.WriteHeader(http.StatusTooManyRequests)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+func loadConfig(t *testing.T, section string) *config.Config {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.ini")
+	if err := os.WriteFile(path, []byte("BOT_TOKEN = test\n\n"+section), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := config.Load(path)
+	if err != nil {
+		t.Fatalf("load config: %v", err)
+	}
+	return cfg
+}
+
+func buildPlatform(t *testing.T, cfg *config.Config, name string) *Platform {
+	t.Helper()
+	factory, ok := platformplugins.Lookup(cfg, name)
+	if !ok {
+		t.Fatalf("no factory for %s", name)
+	}
+	contrib, err := factory(cfg, nil)
+	if err != nil {
+		t.Fatalf("build %s: %v", name, err)
+	}
+	return contrib.Platform.(*Platform)
+}
+
+func TestMetingStyleAdapter(t *testing.T) {
+	server := metingServer(t)
+	defer server.Close()
+	cfg := loadConfig(t, `[plugins.mirror]
+type = httpapi
+base_url = `+server.URL+`/api
+display_name = 私有镜像
+aliases = mm, mirror
+headers = X-Token: secret
+search_url = ?type=search&keyword={query}&limit={limit}
+track_url = ?type=song&id={id}
+lyrics_url = ?type=lrc&id={id}
+download_url = ?type=url&id={id}&br={quality}
+playlist_url = ?type=playlist&id={id}
+match_url = https?://mirror\.example/song/(\d+)
+match_playlist_url = https?://mirror\.example/list/(\d+)
+track_link = https://mirror.example/song/{id}
+`)
+	p := buildPlatform(t, cfg, "mirror")
+	ctx := context.Background()
+
+	if caps := p.Capabilities(); !caps.Search || !caps.Download || !caps.Lyrics || caps.HiRes {
+		t.Fatalf("capabilities = %+v", caps)
+	}
+	if meta := p.Metadata(); meta.DisplayName != "私有镜像" || strings.Join(meta.Aliases, ",") != "mm,mirror" {
+		t.Fatalf("meta = %+v", meta)
+	}
+
+	tracks, err := p.Search(ctx, "晴天 周杰伦", 5)
+	if err != nil {
+		t.Fatalf("search: %v", err)
+	}
+	if len(tracks) != 2 {
+		t.Fatalf("untitled entries should be skipped, got %+v", tracks)
+	}
+	first := tracks[0]
+	if first.ID != "186016" || first.Platform != "mirror" || first.Album.Title != "叶惠美" || first.CoverURL != "https://img.example/1.jpg" || first.URL != "https://mirror.example/song/186016" {
+		t.Fatalf("first track = %+v", first)
+	}
+	if len(tracks[1].Artists) != 2 || tracks[1].Artists[1].Name != "五月天" {
+		t.Fatalf("artists = %+v", tracks[1].Artists)
+	}
+
+	track, err := p.GetTrack(ctx, "186016")
+	if err != nil || track.Title != "晴天" {
+		t.Fatalf("GetTrack = %+v, %v", track, err)
+	}
+	if _, err := p.GetTrack(ctx, "404"); !errors.Is(err, platform.ErrNotFound) {
+		t.Fatalf("missing track should be not found, got %v", err)
+	}
+
+	info, err := p.GetDownloadInfo(ctx, "186016", platform.QualityLossless)
+	if err != nil {
+		t.Fatalf("download info: %v", err)
+	}
+	if info.Format != "flac" || info.Bitrate != 999 || info.Size != 30000000 || info.Headers["X-Token"] != "secret" {
+		t.Fatalf("lossless info = %+v", info)
+	}
+	info, err = p.GetDownloadInfo(ctx, "186016", platform.QualityHigh)
+	if err != nil || info.Format != "mp3" || info.Bitrate != 320 {
+		t.Fatalf("high info = %+v, %v", info, err)
+	}
+
+	lyrics, err := p.GetLyrics(ctx, "186016")
+	if err != nil || len(lyrics.Timestamped) != 2 || lyrics.Timestamped[1].Time != 5500*time.Millisecond {
+		t.Fatalf("lyrics = %+v, %v", lyrics, err)
+	}
+
+	playlist, err := p.GetPlaylist(ctx, "9")
+	if err != nil || playlist.TrackCount != 2 || playlist.Title != "9" {
+		t.Fatalf("playlist = %+v, %v", playlist, err)
+	}
+
+	if id, ok := p.MatchURL("https://mirror.example/song/42?from=share"); !ok || id != "42" {
+		t.Fatalf("MatchURL = %q, %v", id, ok)
+	}
+	if id, ok := p.MatchPlaylistURL("https://mirror.example/list/7"); !ok || id != "7" {
+		t.Fatalf("MatchPlaylistURL = %q, %v", id, ok)
+	}
+	if _, ok := p.MatchURL("https://other.example/song/42"); ok {
+		t.Fatal("foreign URL must not match")
+	}
+}
+
+func TestNestedResponseAdapter(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/cloudsearch":
+			_, _ = w.Write([]byte(`{"code": 200, "result": {"songs": [
+				{"id": 5257138, "name": "屋顶", "dt": 319000, "ar": [{"id": 6452, "name": "周杰伦"}, {"id": 9606, "name": "温岚"}], "al": {"id": 512175, "name": "屋顶", "picUrl": "https://img.example/al.jpg", "company": "阿尔发音乐"}, "credits": {"music": ["周杰伦"], "lyrics": ["周杰伦", "方文山"]}, "genre": "Pop"}
+			]}}`))
+		case "/song/url":
+			_, _ = w.Write([]byte(`{"data": [{"id": 5257138, "url": "https://cdn.example/a.m4a", "type": "M4A", "size": 123}]}`))
+		case "/busy":
+			w.WriteHeader(http.StatusTooManyRequests)
+		}
+	}))
+	defer server.Close()
+	cfg := loadConfig(t, `[plugins.ncm]
+type = httpapi
+base_url = `+server.URL+`/
+search_url = cloudsearch?keywords={query}&limit={limit}
+search_items = result.songs
+field_artists = ar.*.name
+field_artist_ids = ar.*.id
+field_album = al.name
+field_album_id = al.id
+field_cover = al.picUrl
+field_composers = credits.music
+field_lyricists = credits.lyrics.*
+field_genres = genre
+field_label = al.company
+field_duration = dt
+duration_unit = ms
+download_url = song/url?id={id}&br={quality}
+download_url_path = data.0.url
+download_size_path = data.0.size
+download_format_path = data.0.type
+quality_high = 320000
+`)
+	p := buildPlatform(t, cfg, "ncm")
+	ctx := context.Background()
+
+	tracks, err := p.Search(ctx, "屋顶", 3)
+	if err != nil || len(tracks) != 1 {
+		t.Fatalf("search = %+v, %v", tracks, err)
+	}
+	track := tracks[0]
+	if track.Duration != 319*time.Second || len(track.Artists) != 2 || track.Artists[1].ID != "9606" || track.Album.ID != "512175" || track.CoverURL != "https://img.example/al.jpg" {
+		t.Fatalf("track = %+v", track)
+	}
+	if len(track.Composers) != 1 || len(track.Lyricists) != 2 || track.Lyricists[1] != "方文山" || len(track.Genres) != 1 || track.Album.Label != "阿尔发音乐" {
+		t.Fatalf("credits = %v / %v / %v / %q", track.Composers, track.Lyricists, track.Genres, track.Album.Label)
+	}
+	if _, err := p.GetTrack(ctx, "1"); !errors.Is(err, platform.ErrUnsupported) {
+		t.Fatalf("GetTrack without track_url should be unsupported, got %v", err)
+	}
+	info, err := p.GetDownloadInfo(ctx, "5257138", platform.QualityHigh)
+	if err != nil || info.Format != "m4a" || info.Size != 123 {
+		t.Fatalf("download info = %+v, %v", info, err)
+	}
+
+	p.spec.searchURL = "busy"
+	if _, err := p.Search(ctx, "x", 1); !errors.Is(err, platform.ErrRateLimited) {
+		t.Fatalf("429 should map to rate limited, got %v", err)
+	}
+}
+
+func TestLoadSpecValidation(t *testing.T) {
+	cases := map[string]string{
+		"base_url required":       "type = httpapi\nsearch_url = /s\n",
+		"search_url or track_url": "type = httpapi\nbase_url = https://api.example\n",
+		"exactly one capture":     "type = httpapi\nbase_url = https://api.example\nsearch_url = /s\nmatch_url = https://x/(a)/(b)\n",
+		"duration_unit must be s": "type = httpapi\nbase_url = https://api.example\nsearch_url = /s\nduration_unit = min\n",
+	}
+	for want, section := range cases {
+		cfg := loadConfig(t, "[plugins.bad]\n"+section)
+		if _, err := loadSpec("bad", cfg); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("error = %v, want %q", err, want)
+		}
+	}
+	cfg := loadConfig(t, "[plugins.plain]\nbase_url = https://api.example\nsearch_url = /s\n")
+	if _, ok := platformplugins.Lookup(cfg, "plain"); ok {
+		t.Fatal("a section without type must not resolve to the adapter")
+	}
+}
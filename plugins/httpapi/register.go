@@ -0,0 +1,38 @@
+package httpapi
+
+import (
+	"fmt"
+
+	"github.com/liuran001/MusicBot-Go/bot/config"
+	"github.com/liuran001/MusicBot-Go/bot/httpproxy"
+	logpkg "github.com/liuran001/MusicBot-Go/bot/logger"
+	platformplugins "github.com/liuran001/MusicBot-Go/bot/platform/plugins"
+)
+
+// Kind is the `type` value that selects this adapter in [plugins.<name>].
+const Kind = "httpapi"
+
+func init() {
+	if err := platformplugins.RegisterKind(Kind, buildContribution); err != nil {
+		panic(err)
+	}
+}
+
+func buildContribution(name string, cfg *config.Config, logger *logpkg.Logger) (*platformplugins.Contribution, error) {
+	if cfg == nil {
+		return nil, fmt.Errorf("config required")
+	}
+	s, err := loadSpec(name, cfg)
+	if err != nil {
+		return nil, err
+	}
+	httpClient, err := httpproxy.NewHTTPClient(cfg.ResolveAPIProxyConfig(name), s.timeout)
+	if err != nil {
+		return nil, err
+	}
+	plat := newPlatform(s, httpClient)
+	if logger != nil {
+		logger.Debug("httpapi platform configured", "plugin", name, "base_url", s.baseURL.String(), "capabilities", fmt.Sprintf("%+v", plat.Capabilities()))
+	}
+	return &platformplugins.Contribution{Platform: plat}, nil
+}
@@ -0,0 +1,207 @@
+package httpapi
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/liuran001/MusicBot-Go/bot/config"
+	"github.com/liuran001/MusicBot-Go/bot/httpproxy"
+	"github.com/liuran001/MusicBot-Go/bot/platform"
+)
+
+const defaultTimeout = 15 * time.Second
+
+// fieldPaths locate track fields inside one song object of a response.
+type fieldPaths struct {
+	id, title, artists, artistIDs, album, albumID, cover string
+	duration, isrc, year, trackNumber, link              string
+	composers, lyricists, genres, label                  string
+}
+
+// spec is the parsed [plugins.<name>] section of an httpapi platform.
+// Endpoint values are URL templates with {id}, {query}, {limit} and
+// {quality} placeholders, resolved against baseURL.
+type spec struct {
+	name        string
+	displayName string
+	emoji       string
+	aliases     []string
+	baseURL     *url.URL
+	headers     map[string]string
+	timeout     time.Duration
+
+	searchURL   string
+	searchItems string
+
+	trackURL  string
+	trackItem string
+
+	lyricsURL             string
+	lyricsPath            string
+	lyricsTranslationPath string
+
+	downloadURL         string
+	downloadURLPath     string
+	downloadSizePath    string
+	downloadBitratePath string
+	downloadFormatPath  string
+	downloadMD5Path     string
+
+	playlistURL             string
+	playlistItems           string
+	playlistTitlePath       string
+	playlistCoverPath       string
+	playlistDescriptionPath string
+
+	fields          fieldPaths
+	artistSeparator string
+	durationUnit    time.Duration
+	trackLink       string
+	qualities       map[platform.Quality]string
+	hiRes           bool
+
+	matchURL         *regexp.Regexp
+	matchPlaylistURL *regexp.Regexp
+}
+
+// loadSpec reads a platform declaration. Unset paths default to the shapes
+// of meting-api: a bare song array for search, "url" for the download link and
+// "lyric"/"tlyric" for lyrics.
+func loadSpec(name string, cfg *config.Config) (*spec, error) {
+	get := func(key, fallback string) string {
+		if value := strings.TrimSpace(cfg.GetPluginString(name, key)); value != "" {
+			return value
+		}
+		return fallback
+	}
+	s := &spec{
+		name:        name,
+		displayName: get("display_name", name),
+		emoji:       get("emoji", "🎵"),
+		headers:     httpproxy.ParseHeaders(get("headers", "")),
+		timeout:     defaultTimeout,
+
+		searchURL:   get("search_url", ""),
+		searchItems: get("search_items", ""),
+
+		trackURL:  get("track_url", ""),
+		trackItem: get("track_item", ""),
+
+		lyricsURL:             get("lyrics_url", ""),
+		lyricsPath:            get("lyrics_path", "lyric"),
+		lyricsTranslationPath: get("lyrics_translation_path", "tlyric"),
+
+		downloadURL:         get("download_url", ""),
+		downloadURLPath:     get("download_url_path", "url"),
+		downloadSizePath:    get("download_size_path", "size"),
+		downloadBitratePath: get("download_bitrate_path", "br"),
+		downloadFormatPath:  get("download_format_path", ""),
+		downloadMD5Path:     get("download_md5_path", ""),
+
+		playlistURL:             get("playlist_url", ""),
+		playlistItems:           get("playlist_items", ""),
+		playlistTitlePath:       get("playlist_title_path", ""),
+		playlistCoverPath:       get("playlist_cover_path", ""),
+		playlistDescriptionPath: get("playlist_description_path", ""),
+
+		fields: fieldPaths{
+			id:          get("field_id", "id"),
+			title:       get("field_title", "name"),
+			artists:     get("field_artists", "artist"),
+			artistIDs:   get("field_artist_ids", ""),
+			album:       get("field_album", "album"),
+			albumID:     get("field_album_id", ""),
+			cover:       get("field_cover", "pic"),
+			duration:    get("field_duration", ""),
+			isrc:        get("field_isrc", ""),
+			year:        get("field_year", ""),
+			trackNumber: get("field_track_number", ""),
+			link:        get("field_url", ""),
+			composers:   get("field_composers", ""),
+			lyricists:   get("field_lyricists", ""),
+			genres:      get("field_genres", ""),
+			label:       get("field_label", ""),
+		},
+		artistSeparator: cfg.GetPluginString(name, "artist_separator"),
+		durationUnit:    time.Second,
+		trackLink:       get("track_link", ""),
+		hiRes:           cfg.GetPluginBool(name, "hires"),
+	}
+	for _, alias := range strings.Split(get("aliases", ""), ",") {
+		if alias = strings.TrimSpace(alias); alias != "" {
+			s.aliases = append(s.aliases, alias)
+		}
+	}
+	if seconds := cfg.GetPluginInt(name, "timeout"); seconds > 0 {
+		s.timeout = time.Duration(seconds) * time.Second
+	}
+	switch unit := strings.ToLower(get("duration_unit", "s")); unit {
+	case "s":
+	case "ms":
+		s.durationUnit = time.Millisecond
+	default:
+		return nil, fmt.Errorf("httpapi %s: duration_unit must be s or ms, got %q", name, unit)
+	}
+
+	base := get("base_url", "")
+	if base == "" {
+		return nil, fmt.Errorf("httpapi %s: base_url required", name)
+	}
+	parsed, err := url.Parse(base)
+	if err != nil || parsed.Scheme == "" || parsed.Host == "" {
+		return nil, fmt.Errorf("httpapi %s: invalid base_url %q", name, base)
+	}
+	s.baseURL = parsed
+	if s.searchURL == "" && s.trackURL == "" {
+		return nil, fmt.Errorf("httpapi %s: search_url or track_url required", name)
+	}
+
+	s.qualities = map[platform.Quality]string{
+		platform.QualityStandard: get("quality_standard", "128"),
+		platform.QualityHigh:     get("quality_high", "320"),
+		platform.QualityLossless: get("quality_lossless", "999"),
+		platform.QualityHiRes:    get("quality_hires", get("quality_lossless", "999")),
+	}
+
+	if pattern := get("match_url", ""); pattern != "" {
+		if s.matchURL, err = compileMatcher(pattern); err != nil {
+			return nil, fmt.Errorf("httpapi %s: match_url: %w", name, err)
+		}
+	}
+	if pattern := get("match_playlist_url", ""); pattern != "" {
+		if s.matchPlaylistURL, err = compileMatcher(pattern); err != nil {
+			return nil, fmt.Errorf("httpapi %s: match_playlist_url: %w", name, err)
+		}
+	}
+	return s, nil
+}
+
+// compileMatcher requires exactly one capture group, which yields the ID.
+func compileMatcher(pattern string) (*regexp.Regexp, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	if re.NumSubexp() != 1 {
+		return nil, fmt.Errorf("pattern must have exactly one capture group")
+	}
+	return re, nil
+}
+
+// endpoint fills a URL template and resolves it against base_url. Values
+// are query-escaped.
+func (s *spec) endpoint(template string, values map[string]string) (string, error) {
+	pairs := make([]string, 0, len(values)*2)
+	for key, value := range values {
+		pairs = append(pairs, "{"+key+"}", url.QueryEscape(value))
+	}
+	filled := strings.NewReplacer(pairs...).Replace(template)
+	ref, err := url.Parse(filled)
+	if err != nil {
+		return "", fmt.Errorf("httpapi %s: invalid endpoint %q: %w", s.name, template, err)
+	}
+	return s.baseURL.ResolveReference(ref).String(), nil
+}
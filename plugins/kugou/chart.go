@@ -0,0 +1,110 @@
+package kugou
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/guohuiyuan/music-lib/model"
+	"github.com/liuran001/MusicBot-Go/bot/platform"
+)
+
+const (
+	kugouRankSongURL      = "http://mobilecdnbj.kugou.com/api/v3/rank/song"
+	kugouRankDefaultLimit = 100
+	kugouRankMaxPageSize  = 500
+)
+
+// kugouCharts lists the rank lists exposed through /charts. IDs are Kugou
+// rankid values.
+var kugouCharts = []platform.Chart{
+	{ID: "8888", Platform: "kugou", Title: "TOP500"},
+	{ID: "6666", Platform: "kugou", Title: "飙升榜"},
+}
+
+// ListCharts implements platform.ChartProvider.
+func (k *KugouPlatform) ListCharts(ctx context.Context) ([]platform.Chart, error) {
+	_ = ctx
+	return append([]platform.Chart(nil), kugouCharts...), nil
+}
+
+// GetChart implements platform.ChartProvider.
+func (k *KugouPlatform) GetChart(ctx context.Context, chartID string) (*platform.Playlist, error) {
+	chart, ok := platform.FindChart(kugouCharts, chartID)
+	if !ok {
+		return nil, platform.NewNotFoundError("kugou", "chart", chartID)
+	}
+	if k == nil || k.client == nil {
+		return nil, platform.NewUnavailableError("kugou", "chart", chartID)
+	}
+	offset := platform.PlaylistOffsetFromContext(ctx)
+	limit := platform.PlaylistLimitFromContext(ctx)
+	if limit <= 0 {
+		limit = kugouRankDefaultLimit
+	}
+	songs, total, err := k.client.fetchRankSongs(ctx, chart.ID, offset+limit)
+	if err != nil {
+		return nil, err
+	}
+	return convertRankSongs(chart, songs, total, offset, limit), nil
+}
+
+// fetchRankSongs loads the first count entries of a rank list. The endpoint
+// pages from 1, so a single request sized to cover offset+limit keeps the
+// chart view to one round trip.
+func (c *Client) fetchRankSongs(ctx context.Context, rankID string, count int) ([]model.Song, int, error) {
+	if count <= 0 || count > kugouRankMaxPageSize {
+		count = kugouRankMaxPageSize
+	}
+	params := url.Values{}
+	params.Set("rankid", strings.TrimSpace(rankID))
+	params.Set("page", "1")
+	params.Set("pagesize", strconv.Itoa(count))
+	params.Set("plat", "2")
+	var resp kugouAlbumSongsResponse
+	if err := c.doJSONRequest(ctx, http.MethodGet, kugouRankSongURL+"?"+params.Encode(), nil, nil, map[string]string{
+		"User-Agent": "Mozilla/5.0",
+	}, &resp); err != nil {
+		return nil, 0, wrapError("kugou", "chart", rankID, err)
+	}
+	songs := make([]model.Song, 0, len(resp.Data.Info))
+	for _, item := range resp.Data.Info {
+		if song, ok := convertAlbumSongItem(item); ok {
+			songs = append(songs, song)
+		}
+	}
+	return songs, resp.Data.Total, nil
+}
+
+// convertRankSongs slices a fetched rank list to the requested window and
+// wraps it as a playlist.
+func convertRankSongs(chart platform.Chart, songs []model.Song, total, offset, limit int) *platform.Playlist {
+	if offset < 0 {
+		offset = 0
+	}
+	if offset >= len(songs) {
+		songs = nil
+	} else {
+		songs = songs[offset:]
+	}
+	if limit > 0 && len(songs) > limit {
+		songs = songs[:limit]
+	}
+	tracks := make([]platform.Track, 0, len(songs))
+	for _, song := range songs {
+		tracks = append(tracks, convertSong(song))
+	}
+	if total <= 0 {
+		total = offset + len(tracks)
+	}
+	return &platform.Playlist{
+		ID:         chart.ID,
+		Platform:   "kugou",
+		Title:      chart.Title,
+		TrackCount: total,
+		Tracks:     tracks,
+		URL:        "https://www.kugou.com/yy/rank/home/1-" + chart.ID + ".html",
+	}
+}
@@ -0,0 +1,57 @@
+package kugou
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/guohuiyuan/music-lib/model"
+	"github.com/liuran001/MusicBot-Go/bot/platform"
+)
+
+// rankFixture mirrors the live /api/v3/rank/song payload (trimmed).
+const rankFixture = `{"data":{"total":500,"info":[` +
+	`{"hash":"A1B2C3D4E5F60718293A4B5C6D7E8F90","filename":"周杰伦 - 晴天","duration":269,"album_id":"960399","album_audio_id":32218352},` +
+	`{"hash":"","filename":"no hash"},` +
+	`{"hash":"0F1E2D3C4B5A69788796A5B4C3D2E1F0","filename":"林俊杰 - 江南","duration":267}]}}`
+
+func TestConvertRankSongs(t *testing.T) {
+	var resp kugouAlbumSongsResponse
+	if err := json.Unmarshal([]byte(rankFixture), &resp); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	songs := make([]model.Song, 0, len(resp.Data.Info))
+	for _, item := range resp.Data.Info {
+		if song, ok := convertAlbumSongItem(item); ok {
+			songs = append(songs, song)
+		}
+	}
+	if len(songs) != 2 {
+		t.Fatalf("len(songs) = %d, want 2 (hashless entry dropped)", len(songs))
+	}
+
+	playlist := convertRankSongs(kugouCharts[0], songs, resp.Data.Total, 0, 10)
+	if playlist.ID != "8888" || playlist.TrackCount != 500 {
+		t.Fatalf("playlist = %+v", playlist)
+	}
+	if len(playlist.Tracks) != 2 || playlist.Tracks[0].Title != "晴天" {
+		t.Fatalf("tracks = %+v", playlist.Tracks)
+	}
+
+	window := convertRankSongs(kugouCharts[0], songs, resp.Data.Total, 1, 1)
+	if len(window.Tracks) != 1 || window.Tracks[0].Title != "江南" {
+		t.Fatalf("offset window tracks = %+v", window.Tracks)
+	}
+	past := convertRankSongs(kugouCharts[0], songs, resp.Data.Total, 5, 1)
+	if len(past.Tracks) != 0 {
+		t.Fatalf("offset past end tracks = %+v", past.Tracks)
+	}
+}
+
+func TestGetChartRejectsUnknownID(t *testing.T) {
+	p := &KugouPlatform{}
+	if _, err := p.GetChart(context.Background(), "1"); !errors.Is(err, platform.ErrNotFound) {
+		t.Fatalf("GetChart(unknown) error = %v, want ErrNotFound", err)
+	}
+}
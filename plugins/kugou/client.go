@@ -806,36 +806,39 @@ type kugouAlbumInfoResponse struct {
 
 type kugouAlbumSongsResponse struct {
 	Data struct {
-		Total int `json:"total"`
-		Info  []struct {
-			Hash         string `json:"hash"`
-			SQHash       string `json:"sqhash"`
-			Hash320      string `json:"320hash"`
-			AlbumID      string `json:"album_id"`
-			AlbumAudioID any    `json:"album_audio_id"`
-			AudioID      any    `json:"audio_id"`
-			SongName     string `json:"filename"`
-			SongTitle    string `json:"songname"`
-			AuthorName   string `json:"author_name"`
-			Duration     int    `json:"duration"`
-			Bitrate      int    `json:"bitrate"`
-			ExtName      string `json:"extname"`
-			FileSize     int64  `json:"filesize"`
-			Cover        string `json:"img"`
-			Privilege    any    `json:"privilege"`
-			TransParam   struct {
-				Ogg320Hash string      `json:"ogg_320_hash"`
-				Ogg128Hash string      `json:"ogg_128_hash"`
-				SingerID   interface{} `json:"singerid"`
-				UnionCover string      `json:"union_cover"`
-				HashOffset struct {
-					ClipHash string `json:"clip_hash"`
-				} `json:"hash_offset"`
-			} `json:"trans_param"`
-		} `json:"info"`
+		Total int                  `json:"total"`
+		Info  []kugouAlbumSongItem `json:"info"`
 	} `json:"data"`
 }
 
+// kugouAlbumSongItem is one song entry in the mobilecdn album/rank song lists.
+type kugouAlbumSongItem struct {
+	Hash         string `json:"hash"`
+	SQHash       string `json:"sqhash"`
+	Hash320      string `json:"320hash"`
+	AlbumID      string `json:"album_id"`
+	AlbumAudioID any    `json:"album_audio_id"`
+	AudioID      any    `json:"audio_id"`
+	SongName     string `json:"filename"`
+	SongTitle    string `json:"songname"`
+	AuthorName   string `json:"author_name"`
+	Duration     int    `json:"duration"`
+	Bitrate      int    `json:"bitrate"`
+	ExtName      string `json:"extname"`
+	FileSize     int64  `json:"filesize"`
+	Cover        string `json:"img"`
+	Privilege    any    `json:"privilege"`
+	TransParam   struct {
+		Ogg320Hash string      `json:"ogg_320_hash"`
+		Ogg128Hash string      `json:"ogg_128_hash"`
+		SingerID   interface{} `json:"singerid"`
+		UnionCover string      `json:"union_cover"`
+		HashOffset struct {
+			ClipHash string `json:"clip_hash"`
+		} `json:"hash_offset"`
+	} `json:"trans_param"`
+}
+
 type kugouPlaylistBaseResponse struct {
 	Status  int    `json:"status"`
 	ErrCode int    `json:"errcode,omitempty"`
@@ -1017,56 +1020,10 @@ func (c *Client) fetchAlbumSongs(ctx context.Context, albumID string) ([]model.S
 	}
 	results := make([]model.Song, 0, len(resp.Data.Info))
 	for _, item := range resp.Data.Info {
-		primaryHash := firstNonEmpty(item.Hash, item.Hash320, item.SQHash, item.TransParam.Ogg320Hash, item.TransParam.Ogg128Hash, item.TransParam.HashOffset.ClipHash)
-		if normalizeHash(primaryHash) == "" {
+		song, ok := convertAlbumSongItem(item)
+		if !ok {
 			continue
 		}
-		songName := strings.TrimSpace(firstNonEmpty(item.SongTitle, item.SongName))
-		artistName := strings.TrimSpace(item.AuthorName)
-		if title, parsedArtist := splitKugouSongDisplayName(songName); title != "" {
-			songName = title
-			if artistName == "" {
-				artistName = parsedArtist
-			}
-		}
-		if artistName == "" {
-			if title, parsedArtist := splitKugouSongDisplayName(strings.TrimSpace(item.SongName)); title != "" {
-				if songName == "" {
-					songName = title
-				}
-				artistName = parsedArtist
-			}
-		}
-		shareChain := resolveSongShareChain(formatAnyNumericString(item.AlbumAudioID), formatAnyNumericString(item.AudioID))
-		cover := normalizeSizedCover(strings.TrimSpace(firstNonEmpty(item.TransParam.UnionCover, item.Cover)))
-		singerIDs := formatKugouIDList(firstNonEmpty(formatAnyIDList(item.TransParam.SingerID)))
-		song := model.Song{
-			Source:   "kugou",
-			ID:       normalizeHash(primaryHash),
-			Name:     songName,
-			Artist:   artistName,
-			AlbumID:  strings.TrimSpace(item.AlbumID),
-			Duration: item.Duration,
-			Size:     item.FileSize,
-			Bitrate:  item.Bitrate,
-			Ext:      strings.TrimSpace(item.ExtName),
-			Cover:    cover,
-			Link:     buildShareTrackLink(shareChain, primaryHash, item.AlbumID, formatAnyNumericString(item.AlbumAudioID)),
-			Extra: map[string]string{
-				"hash":           normalizeHash(primaryHash),
-				"file_hash":      normalizeHash(item.Hash),
-				"hq_hash":        normalizeHash(item.Hash320),
-				"sq_hash":        normalizeHash(item.SQHash),
-				"ogg_320_hash":   normalizeHash(item.TransParam.Ogg320Hash),
-				"ogg_128_hash":   normalizeHash(item.TransParam.Ogg128Hash),
-				"album_id":       strings.TrimSpace(item.AlbumID),
-				"album_audio_id": formatAnyNumericString(item.AlbumAudioID),
-				"audio_id":       formatAnyNumericString(item.AudioID),
-				"share_chain":    shareChain,
-				"privilege":      formatAnyNumericString(item.Privilege),
-				"singer_ids":     singerIDs,
-			},
-		}
 		if enriched := c.enrichGatewaySongMeta(ctx, &song); enriched != nil {
 			song = *enriched
 		}
@@ -1075,6 +1032,62 @@ func (c *Client) fetchAlbumSongs(ctx context.Context, albumID string) ([]model.S
 	return results, resp.Data.Total, nil
 }
 
+// convertAlbumSongItem maps one mobilecdn album/rank song entry onto the
+// music-lib song model. Entries without any usable hash are skipped.
+func convertAlbumSongItem(item kugouAlbumSongItem) (model.Song, bool) {
+	primaryHash := firstNonEmpty(item.Hash, item.Hash320, item.SQHash, item.TransParam.Ogg320Hash, item.TransParam.Ogg128Hash, item.TransParam.HashOffset.ClipHash)
+	if normalizeHash(primaryHash) == "" {
+		return model.Song{}, false
+	}
+	songName := strings.TrimSpace(firstNonEmpty(item.SongTitle, item.SongName))
+	artistName := strings.TrimSpace(item.AuthorName)
+	if title, parsedArtist := splitKugouSongDisplayName(songName); title != "" {
+		songName = title
+		if artistName == "" {
+			artistName = parsedArtist
+		}
+	}
+	if artistName == "" {
+		if title, parsedArtist := splitKugouSongDisplayName(strings.TrimSpace(item.SongName)); title != "" {
+			if songName == "" {
+				songName = title
+			}
+			artistName = parsedArtist
+		}
+	}
+	shareChain := resolveSongShareChain(formatAnyNumericString(item.AlbumAudioID), formatAnyNumericString(item.AudioID))
+	cover := normalizeSizedCover(strings.TrimSpace(firstNonEmpty(item.TransParam.UnionCover, item.Cover)))
+	singerIDs := formatKugouIDList(firstNonEmpty(formatAnyIDList(item.TransParam.SingerID)))
+	song := model.Song{
+		Source:   "kugou",
+		ID:       normalizeHash(primaryHash),
+		Name:     songName,
+		Artist:   artistName,
+		AlbumID:  strings.TrimSpace(item.AlbumID),
+		Duration: item.Duration,
+		Size:     item.FileSize,
+		Bitrate:  item.Bitrate,
+		Ext:      strings.TrimSpace(item.ExtName),
+		Cover:    cover,
+		Link:     buildShareTrackLink(shareChain, primaryHash, item.AlbumID, formatAnyNumericString(item.AlbumAudioID)),
+		Extra: map[string]string{
+			"hash":           normalizeHash(primaryHash),
+			"file_hash":      normalizeHash(item.Hash),
+			"hq_hash":        normalizeHash(item.Hash320),
+			"sq_hash":        normalizeHash(item.SQHash),
+			"ogg_320_hash":   normalizeHash(item.TransParam.Ogg320Hash),
+			"ogg_128_hash":   normalizeHash(item.TransParam.Ogg128Hash),
+			"album_id":       strings.TrimSpace(item.AlbumID),
+			"album_audio_id": formatAnyNumericString(item.AlbumAudioID),
+			"audio_id":       formatAnyNumericString(item.AudioID),
+			"share_chain":    shareChain,
+			"privilege":      formatAnyNumericString(item.Privilege),
+			"singer_ids":     singerIDs,
+		},
+	}
+	return song, true
+}
+
 func (c *Client) enrichGatewaySongMeta(ctx context.Context, song *model.Song) *model.Song {
 	if song == nil {
 		return song
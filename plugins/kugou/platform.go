@@ -345,6 +345,12 @@ func (k *KugouPlatform) GetAlbum(ctx context.Context, albumID string) (*platform
 	if creator := strings.TrimSpace(playlist.Creator); creator != "" {
 		album.Artists = splitArtists(creator, nil)
 	}
+	if published := strings.TrimSpace(playlist.Extra["publish_time"]); len(published) >= len("2006-01-02") {
+		if released, err := time.Parse("2006-01-02", published[:len("2006-01-02")]); err == nil {
+			album.ReleaseDate = &released
+			album.Year = released.Year()
+		}
+	}
 	return album, nil
 }
 
@@ -20,9 +20,7 @@ func buildContribution(cfg *config.Config, logger *logpkg.Logger) (*platformplug
 	if cfg == nil {
 		return nil, fmt.Errorf("config required")
 	}
-	persist := func(pairs map[string]string) error {
-		return cfg.PersistPluginConfig("kugou", pairs)
-	}
+	persist := cfg.PluginPersister("kugou")
 	client := NewClient("", logger)
 	apiProxyCfg := loadKugouAPIProxyConfig(cfg)
 	if err := client.SetAPIProxy(apiProxyCfg); err != nil {
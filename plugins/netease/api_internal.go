@@ -16,6 +16,7 @@ const (
 	playlistDetailAPI = "/api/v6/playlist/detail"
 	albumDetailAPI    = "/api/album/v3/detail"
 	artistDetailAPI   = "/api/artist/head/info/get"
+	artistAlbumsAPI   = "/api/artist/albums/"
 	programDetailAPI  = "/api/dj/program/detail"
 )
 
@@ -116,6 +117,22 @@ func GetArtistDetail(ctx context.Context, data RequestData, artistID int) (Artis
 	return doJSONRequest[ArtistDetailData](ctx, data, EAPIOption{Path: artistDetailAPI, Url: "https://music.163.com/eapi/artist/head/info/get", Json: string(bodyJSON)})
 }
 
+// GetArtistAlbums lists an artist's albums and singles. The artist ID is part of
+// the path; upstream returns them newest first.
+func GetArtistAlbums(ctx context.Context, data RequestData, artistID, limit int) (ArtistAlbumsData, error) {
+	type reqBody struct {
+		Limit  int  `json:"limit"`
+		Offset int  `json:"offset"`
+		Total  bool `json:"total"`
+	}
+	if limit <= 0 {
+		limit = 10
+	}
+	bodyJSON, _ := json.Marshal(reqBody{Limit: limit, Offset: 0, Total: true})
+	path := artistAlbumsAPI + strconv.Itoa(artistID)
+	return doJSONRequest[ArtistAlbumsData](ctx, data, EAPIOption{Path: path, Url: "https://music.163.com/eapi/artist/albums/" + strconv.Itoa(artistID), Json: string(bodyJSON)})
+}
+
 func GetProgramDetail(ctx context.Context, data RequestData, id int) (ProgramDetailData, error) {
 	type reqBody struct {
 		ID string `json:"id"`
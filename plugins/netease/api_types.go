@@ -244,6 +244,8 @@ type AlbumDetailData struct {
 		Description string `json:"description"`
 		BriefDesc   string `json:"briefDesc"`
 		Size        int    `json:"size"`
+		Company     string `json:"company"`
+		PublishTime int64  `json:"publishTime"`
 		Artist      struct {
 			Id   int    `json:"id"`
 			Name string `json:"name"`
@@ -295,3 +297,23 @@ type ArtistDetailData struct {
 		} `json:"artist"`
 	} `json:"data"`
 }
+
+// ArtistAlbumsData is the /api/artist/albums/{id} response. PublishTime is a
+// Unix timestamp in milliseconds.
+type ArtistAlbumsData struct {
+	RawJson   string `json:"-"`
+	Code      int    `json:"code"`
+	More      bool   `json:"more"`
+	HotAlbums []struct {
+		Id          int    `json:"id"`
+		Name        string `json:"name"`
+		PicUrl      string `json:"picUrl"`
+		PublishTime int64  `json:"publishTime"`
+		Size        int    `json:"size"`
+		Type        string `json:"type"`
+		Artists     []struct {
+			Id   int    `json:"id"`
+			Name string `json:"name"`
+		} `json:"artists"`
+	} `json:"hotAlbums"`
+}
@@ -0,0 +1,72 @@
+package netease
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/liuran001/MusicBot-Go/bot/platform"
+)
+
+// GetArtistReleases implements platform.ArtistReleaseProvider.
+func (n *NeteasePlatform) GetArtistReleases(ctx context.Context, artistID string, limit int) ([]platform.Album, error) {
+	trimmed := strings.TrimSpace(artistID)
+	numericID, err := strconv.Atoi(trimmed)
+	if err != nil || numericID <= 0 {
+		return nil, platform.NewNotFoundError("netease", "artist", artistID)
+	}
+	if n == nil || n.client == nil {
+		return nil, platform.NewUnavailableError("netease", "artist", artistID)
+	}
+	data, err := n.client.GetArtistAlbums(ctx, numericID, limit)
+	if err != nil {
+		return nil, platform.NewUnavailableError("netease", "artist", artistID)
+	}
+	if data == nil || data.Code == 404 {
+		return nil, platform.NewNotFoundError("netease", "artist", artistID)
+	}
+	if data.Code != 200 {
+		return nil, platform.NewUnavailableError("netease", "artist", artistID)
+	}
+	return convertArtistAlbums(data, limit), nil
+}
+
+func convertArtistAlbums(data *ArtistAlbumsData, limit int) []platform.Album {
+	if data == nil {
+		return nil
+	}
+	releases := make([]platform.Album, 0, len(data.HotAlbums))
+	for _, item := range data.HotAlbums {
+		if item.Id <= 0 || strings.TrimSpace(item.Name) == "" {
+			continue
+		}
+		album := platform.Album{
+			ID:         strconv.Itoa(item.Id),
+			Platform:   "netease",
+			Title:      strings.TrimSpace(item.Name),
+			CoverURL:   httpsPortraitURL(item.PicUrl),
+			TrackCount: item.Size,
+			URL:        fmt.Sprintf("https://music.163.com/album?id=%d", item.Id),
+		}
+		if item.PublishTime > 0 {
+			released := time.UnixMilli(item.PublishTime)
+			album.ReleaseDate = &released
+			album.Year = released.Year()
+		}
+		for _, artist := range item.Artists {
+			album.Artists = append(album.Artists, platform.Artist{
+				ID:       strconv.Itoa(artist.Id),
+				Platform: "netease",
+				Name:     artist.Name,
+			})
+		}
+		releases = append(releases, album)
+	}
+	platform.SortReleasesNewestFirst(releases)
+	if limit > 0 && len(releases) > limit {
+		releases = releases[:limit]
+	}
+	return releases
+}
@@ -2,6 +2,7 @@ package netease
 
 import (
 	"context"
+	"encoding/json"
 	"testing"
 
 	"github.com/liuran001/MusicBot-Go/bot/platform"
@@ -113,3 +114,32 @@ func TestSegmentMarkerKindsAreDistinct(t *testing.T) {
 		}
 	}
 }
+
+func TestConvertArtistAlbumsNewestFirst(t *testing.T) {
+	var data ArtistAlbumsData
+	data.Code = 200
+	raw := `{"code":200,"hotAlbums":[
+		{"id":11,"name":"Old","picUrl":"http://p1.music.126.net/a.jpg","publishTime":1600000000000,"size":10,"artists":[{"id":6452,"name":"周杰伦"}]},
+		{"id":0,"name":"Broken"},
+		{"id":22,"name":"New","picUrl":"https://p1.music.126.net/b.jpg","publishTime":1700000000000,"size":1}
+	]}`
+	if err := json.Unmarshal([]byte(raw), &data); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	releases := convertArtistAlbums(&data, 5)
+	if len(releases) != 2 {
+		t.Fatalf("len(releases) = %d, want 2", len(releases))
+	}
+	if releases[0].ID != "22" || releases[1].ID != "11" {
+		t.Fatalf("order = %s,%s; want 22,11", releases[0].ID, releases[1].ID)
+	}
+	if releases[1].CoverURL != "https://p1.music.126.net/a.jpg" {
+		t.Errorf("cover = %q, want https upgrade", releases[1].CoverURL)
+	}
+	if releases[0].ReleaseDate == nil || releases[0].URL != "https://music.163.com/album?id=22" {
+		t.Errorf("release = %+v", releases[0])
+	}
+	if len(convertArtistAlbums(&data, 1)) != 1 {
+		t.Errorf("limit not applied")
+	}
+}
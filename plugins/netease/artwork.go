@@ -0,0 +1,20 @@
+package netease
+
+import (
+	"fmt"
+	"net/url"
+)
+
+// CoverURLAtSize implements platform.ArtworkProvider. NetEase's image CDN
+// serves the uploaded original without a query and scales on ?param=WxH.
+func (n *NeteasePlatform) CoverURLAtSize(coverURL string, size int) string {
+	u, err := url.Parse(coverURL)
+	if err != nil || u.Host == "" {
+		return coverURL
+	}
+	u.RawQuery = ""
+	if size > 0 {
+		u.RawQuery = fmt.Sprintf("param=%dy%d", size, size)
+	}
+	return u.String()
+}
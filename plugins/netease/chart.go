@@ -0,0 +1,31 @@
+package netease
+
+import (
+	"context"
+
+	"github.com/liuran001/MusicBot-Go/bot/platform"
+)
+
+// neteaseCharts lists the official toplists exposed through /charts. NetEase
+// publishes every toplist as an ordinary playlist, so the IDs are playlist IDs.
+var neteaseCharts = []platform.Chart{
+	{ID: "19723756", Platform: "netease", Title: "飙升榜"},
+	{ID: "3779629", Platform: "netease", Title: "新歌榜"},
+	{ID: "3778678", Platform: "netease", Title: "热歌榜"},
+	{ID: "2884035", Platform: "netease", Title: "原创榜"},
+}
+
+// ListCharts implements platform.ChartProvider.
+func (n *NeteasePlatform) ListCharts(ctx context.Context) ([]platform.Chart, error) {
+	_ = ctx
+	return append([]platform.Chart(nil), neteaseCharts...), nil
+}
+
+// GetChart implements platform.ChartProvider.
+func (n *NeteasePlatform) GetChart(ctx context.Context, chartID string) (*platform.Playlist, error) {
+	chart, ok := platform.FindChart(neteaseCharts, chartID)
+	if !ok {
+		return nil, platform.NewNotFoundError("netease", "chart", chartID)
+	}
+	return n.GetPlaylist(ctx, chart.ID)
+}
@@ -233,6 +233,30 @@ func (c *Client) GetArtistDetail(ctx context.Context, artistID int) (*ArtistDeta
 	return &result, nil
 }
 
+// GetArtistAlbums retrieves an artist's most recent albums and singles.
+func (c *Client) GetArtistAlbums(ctx context.Context, artistID, limit int) (*ArtistAlbumsData, error) {
+	if c.logger != nil {
+		c.logger.Debug("fetching artist albums", "artist_id", artistID)
+	}
+
+	var result ArtistAlbumsData
+	err := c.execute(ctx, func() error {
+		data, err := GetArtistAlbums(ctx, c.requestData(), artistID, limit)
+		if err != nil {
+			if c.logger != nil {
+				c.logger.Error("api.GetArtistAlbums failed", "artist_id", artistID, "error", err)
+			}
+			return err
+		}
+		result = data
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
 // GetSongURL retrieves song URL data.
 func (c *Client) GetSongURL(ctx context.Context, musicID int, quality string) (*SongsURLData, error) {
 	var result SongsURLData
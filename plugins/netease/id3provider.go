@@ -95,9 +95,10 @@ func (p *ID3Provider) GetTagData(ctx context.Context, track *platform.Track, inf
 		discNumber = track.DiscNumber
 	}
 
-	return &id3.TagData{
+	tagData := &id3.TagData{
 		Title:       track.Title,
 		Artist:      strings.Join(artists, ", "),
+		Artists:     artists,
 		Album:       albumName,
 		Comment:     key163,
 		CoverURL:    track.CoverURL,
@@ -105,9 +106,46 @@ func (p *ID3Provider) GetTagData(ctx context.Context, track *platform.Track, inf
 		Year:        year,
 		TrackNumber: trackNumber,
 		DiscNumber:  discNumber,
-	}, nil
+	}
+	// The album page carries the label, the exact release date and the
+	// album's own artists; tagging goes ahead without them.
+	if albumID := songDetail.Songs[0].Al.Id; albumID != 0 {
+		if albumDetail, err := p.client.GetAlbumDetail(ctx, albumID); err == nil && albumDetail != nil {
+			applyAlbumDetail(tagData, albumDetail)
+		}
+	}
+	return tagData, nil
+}
+
+// applyAlbumDetail copies the label, release date and album artists of an
+// album detail response into tagData.
+func applyAlbumDetail(tagData *id3.TagData, detail *AlbumDetailData) {
+	tagData.Label = strings.TrimSpace(detail.Album.Company)
+	if detail.Album.PublishTime > 0 {
+		released := time.UnixMilli(detail.Album.PublishTime).In(neteaseReleaseZone)
+		tagData.ReleaseDate = released.Format("2006-01-02")
+		if tagData.Year == "" {
+			tagData.Year = strconv.Itoa(released.Year())
+		}
+	}
+	var albumArtists []string
+	for _, artist := range detail.Album.Artists {
+		if name := strings.TrimSpace(artist.Name); name != "" {
+			albumArtists = append(albumArtists, name)
+		}
+	}
+	if len(albumArtists) == 0 && strings.TrimSpace(detail.Album.Artist.Name) != "" {
+		albumArtists = []string{strings.TrimSpace(detail.Album.Artist.Name)}
+	}
+	if len(albumArtists) > 0 {
+		tagData.AlbumArtists = albumArtists
+		tagData.AlbumArtist = strings.Join(albumArtists, ", ")
+	}
 }
 
+// neteaseReleaseZone is the zone NetEase release timestamps are midnight in.
+var neteaseReleaseZone = time.FixedZone("CST", 8*3600)
+
 func parseNeteaseDiscNumber(disc string) int {
 	disc = strings.TrimSpace(disc)
 	if disc == "" {
@@ -3,6 +3,7 @@ package netease
 import (
 	"testing"
 
+	"github.com/liuran001/MusicBot-Go/bot/id3"
 	"github.com/liuran001/MusicBot-Go/bot/platform"
 )
 
@@ -199,3 +200,32 @@ func TestConvertLyricsSurfacesRawTracks(t *testing.T) {
 		t.Errorf("Plain = %q, want %q", got.Plain, data.Lrc.Lyric)
 	}
 }
+
+func TestApplyAlbumDetail(t *testing.T) {
+	var detail AlbumDetailData
+	detail.Album.Company = " 杰威尔音乐 "
+	detail.Album.PublishTime = 1059580800000 // 2003-07-31 00:00 +08:00
+	detail.Album.Artists = append(detail.Album.Artists, struct {
+		Id   int    `json:"id"`
+		Name string `json:"name"`
+	}{Id: 6452, Name: "周杰伦"})
+	tagData := &id3.TagData{}
+	applyAlbumDetail(tagData, &detail)
+	if tagData.Label != "杰威尔音乐" || tagData.ReleaseDate != "2003-07-31" || tagData.Year != "2003" {
+		t.Fatalf("tag data = %+v", tagData)
+	}
+	if tagData.AlbumArtist != "周杰伦" || len(tagData.AlbumArtists) != 1 {
+		t.Fatalf("album artists = %q / %v", tagData.AlbumArtist, tagData.AlbumArtists)
+	}
+}
+
+func TestCoverURLAtSize(t *testing.T) {
+	n := &NeteasePlatform{}
+	cover := "http://p1.music.126.net/abc/109951163.jpg?param=130y130"
+	if got, want := n.CoverURLAtSize(cover, 0), "http://p1.music.126.net/abc/109951163.jpg"; got != want {
+		t.Fatalf("CoverURLAtSize(original) = %q, want %q", got, want)
+	}
+	if got, want := n.CoverURLAtSize(cover, 1400), "http://p1.music.126.net/abc/109951163.jpg?param=1400y1400"; got != want {
+		t.Fatalf("CoverURLAtSize(1400) = %q, want %q", got, want)
+	}
+}
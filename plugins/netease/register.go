@@ -36,9 +36,7 @@ func buildContribution(cfg *config.Config, logger *logpkg.Logger) (*platformplug
 	if intervalSec > 0 {
 		interval = time.Duration(intervalSec) * time.Second
 	}
-	persist := func(pairs map[string]string) error {
-		return cfg.PersistPluginConfig("netease", pairs)
-	}
+	persist := cfg.PluginPersister("netease")
 	client := New(musicU, spoofIP, logger, persist)
 	client.ConfigureAutoRenew(autoRenewEnabled, interval)
 	client.StartAutoRenewDaemon(context.Background())
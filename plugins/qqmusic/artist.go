@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"strings"
+	"time"
 
 	"github.com/liuran001/MusicBot-Go/bot/platform"
 )
@@ -95,3 +96,97 @@ func (c *Client) GetSingerDetail(ctx context.Context, artistID string) (*platfor
 		URL:       buildArtistURL(artistID),
 	}, 0, nil
 }
+
+// qqSingerAlbumList is the AlbumListServer.GetAlbumList payload. order=1 asks
+// for newest first; publishDate is "YYYY-MM-DD".
+type qqSingerAlbumList struct {
+	Code   int `json:"code"`
+	Albums struct {
+		Code int `json:"code"`
+		Data struct {
+			Total     int `json:"total"`
+			AlbumList []struct {
+				AlbumMid    string `json:"albumMid"`
+				AlbumName   string `json:"albumName"`
+				PublishDate string `json:"publishDate"`
+				TotalNum    int    `json:"totalNum"`
+				SingerName  string `json:"singerName"`
+			} `json:"albumList"`
+		} `json:"data"`
+	} `json:"albums"`
+}
+
+// GetSingerAlbums lists a singer's most recent albums and singles by mid.
+func (c *Client) GetSingerAlbums(ctx context.Context, artistID string, limit int) ([]platform.Album, error) {
+	artistID = strings.TrimSpace(artistID)
+	if artistID == "" || !qqSingerMidPattern.MatchString(artistID) {
+		return nil, platform.NewNotFoundError("qqmusic", "artist", artistID)
+	}
+	if c == nil {
+		return nil, platform.NewUnavailableError("qqmusic", "artist", artistID)
+	}
+	if limit <= 0 {
+		limit = 10
+	}
+
+	payload := map[string]interface{}{
+		"comm": map[string]interface{}{"ct": 24, "cv": 10000},
+		"albums": map[string]interface{}{
+			"module": "music.musichallAlbum.AlbumListServer",
+			"method": "GetAlbumList",
+			"param": map[string]interface{}{
+				"singerMid":  artistID,
+				"order":      1,
+				"begin":      0,
+				"num":        limit,
+				"songNumTag": 0,
+				"singerID":   0,
+			},
+		},
+	}
+
+	body, err := c.postJSON(ctx, musicuEndpoint+"?format=json&inCharset=utf8&outCharset=utf8", payload)
+	if err != nil {
+		return nil, platform.NewUnavailableError("qqmusic", "artist", artistID)
+	}
+	var response qqSingerAlbumList
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, platform.NewUnavailableError("qqmusic", "artist", artistID)
+	}
+	if response.Code != 0 || response.Albums.Code != 0 {
+		return nil, platform.NewUnavailableError("qqmusic", "artist", artistID)
+	}
+	return convertSingerAlbums(response, limit), nil
+}
+
+func convertSingerAlbums(response qqSingerAlbumList, limit int) []platform.Album {
+	releases := make([]platform.Album, 0, len(response.Albums.Data.AlbumList))
+	for _, item := range response.Albums.Data.AlbumList {
+		mid := strings.TrimSpace(item.AlbumMid)
+		title := strings.TrimSpace(item.AlbumName)
+		if mid == "" || title == "" {
+			continue
+		}
+		album := platform.Album{
+			ID:         mid,
+			Platform:   "qqmusic",
+			Title:      title,
+			CoverURL:   buildAlbumCoverURL(mid),
+			TrackCount: item.TotalNum,
+			URL:        buildAlbumURL(mid),
+		}
+		if released, err := time.Parse("2006-01-02", strings.TrimSpace(item.PublishDate)); err == nil {
+			album.ReleaseDate = &released
+			album.Year = released.Year()
+		}
+		if name := strings.TrimSpace(item.SingerName); name != "" {
+			album.Artists = []platform.Artist{{Platform: "qqmusic", Name: name}}
+		}
+		releases = append(releases, album)
+	}
+	platform.SortReleasesNewestFirst(releases)
+	if limit > 0 && len(releases) > limit {
+		releases = releases[:limit]
+	}
+	return releases
+}
@@ -2,6 +2,7 @@ package qqmusic
 
 import (
 	"context"
+	"encoding/json"
 	"testing"
 	"time"
 
@@ -91,3 +92,29 @@ func TestPlatformSatisfiesArtistRouting(t *testing.T) {
 		t.Error("QQMusicPlatform does not satisfy artistDetailProvider")
 	}
 }
+
+func TestConvertSingerAlbums(t *testing.T) {
+	var response qqSingerAlbumList
+	raw := `{"code":0,"albums":{"code":0,"data":{"total":3,"albumList":[
+		{"albumMid":"002Neh8l0uciQZ","albumName":"最伟大的作品","publishDate":"2022-07-15","totalNum":12,"singerName":"周杰伦"},
+		{"albumMid":"","albumName":"Broken"},
+		{"albumMid":"003RMaRI1iFoYd","albumName":"说好不哭","publishDate":"2019-09-16","totalNum":1,"singerName":"周杰伦"}
+	]}}}`
+	if err := json.Unmarshal([]byte(raw), &response); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	releases := convertSingerAlbums(response, 10)
+	if len(releases) != 2 {
+		t.Fatalf("len(releases) = %d, want 2", len(releases))
+	}
+	first := releases[0]
+	if first.ID != "002Neh8l0uciQZ" || first.Year != 2022 {
+		t.Fatalf("first release = %+v", first)
+	}
+	if first.URL != "https://y.qq.com/n/ryqq_v2/albumDetail/002Neh8l0uciQZ" || first.CoverURL == "" {
+		t.Errorf("first release links = %q / %q", first.URL, first.CoverURL)
+	}
+	if want := time.Date(2019, 9, 16, 0, 0, 0, 0, time.UTC); releases[1].ReleaseDate == nil || !releases[1].ReleaseDate.Equal(want) {
+		t.Errorf("second release date = %v", releases[1].ReleaseDate)
+	}
+}
@@ -0,0 +1,25 @@
+package qqmusic
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// qqCoverSize matches the size prefix of a photo_new cover name:
+// "T002M000<mid>" is the uploaded original, "T002R300x300M000<mid>" a
+// scaled copy. T062 is the per-song cover namespace.
+var qqCoverSize = regexp.MustCompile(`/(T00[0-9]|T06[0-9])(R\d+x\d+)?M000`)
+
+// CoverURLAtSize implements platform.ArtworkProvider.
+func (q *QQMusicPlatform) CoverURLAtSize(coverURL string, size int) string {
+	m := qqCoverSize.FindStringSubmatchIndex(coverURL)
+	if m == nil {
+		return coverURL
+	}
+	prefix := coverURL[m[2]:m[3]]
+	replacement := "/" + prefix + "M000"
+	if size > 0 {
+		replacement = fmt.Sprintf("/%sR%dx%dM000", prefix, size, size)
+	}
+	return coverURL[:m[0]] + replacement + coverURL[m[1]:]
+}
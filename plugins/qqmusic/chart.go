@@ -0,0 +1,149 @@
+package qqmusic
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/liuran001/MusicBot-Go/bot/platform"
+)
+
+// qqCharts lists the 巅峰榜 toplists exposed through /charts. IDs are the
+// topId values accepted by musicToplist.ToplistInfoServer.GetDetail.
+var qqCharts = []platform.Chart{
+	{ID: "62", Platform: "qqmusic", Title: "飙升榜"},
+	{ID: "26", Platform: "qqmusic", Title: "热歌榜"},
+	{ID: "27", Platform: "qqmusic", Title: "新歌榜"},
+	{ID: "4", Platform: "qqmusic", Title: "流行指数榜"},
+}
+
+// qqToplistData is the subset of ToplistInfoServer.GetDetail this plugin consumes.
+type qqToplistData struct {
+	Data struct {
+		TopID       int    `json:"topId"`
+		Title       string `json:"title"`
+		TitleDetail string `json:"titleDetail"`
+		Intro       string `json:"intro"`
+		Period      string `json:"period"`
+		FrontPicURL string `json:"frontPicUrl"`
+		HeadPicURL  string `json:"headPicUrl"`
+		TotalNum    int    `json:"totalNum"`
+	} `json:"data"`
+	SongInfoList []qqPlaylistSong `json:"songInfoList"`
+}
+
+// ListCharts implements platform.ChartProvider.
+func (q *QQMusicPlatform) ListCharts(ctx context.Context) ([]platform.Chart, error) {
+	_ = ctx
+	return append([]platform.Chart(nil), qqCharts...), nil
+}
+
+// GetChart implements platform.ChartProvider.
+func (q *QQMusicPlatform) GetChart(ctx context.Context, chartID string) (*platform.Playlist, error) {
+	chart, ok := platform.FindChart(qqCharts, chartID)
+	if !ok {
+		return nil, platform.NewNotFoundError("qqmusic", "chart", chartID)
+	}
+	if q == nil || q.client == nil {
+		return nil, platform.NewUnavailableError("qqmusic", "chart", chartID)
+	}
+	data, err := q.client.GetToplist(ctx, chart.ID)
+	if err != nil {
+		return nil, err
+	}
+	return convertToplist(data, chart)
+}
+
+// GetToplist fetches one toplist page, honoring the playlist offset/limit
+// stored in ctx.
+func (c *Client) GetToplist(ctx context.Context, topID string) (*qqToplistData, error) {
+	id, err := strconv.Atoi(strings.TrimSpace(topID))
+	if err != nil || id <= 0 {
+		return nil, platform.NewNotFoundError("qqmusic", "chart", topID)
+	}
+	limit := platform.PlaylistLimitFromContext(ctx)
+	if limit <= 0 {
+		limit = 100
+	}
+	offset := platform.PlaylistOffsetFromContext(ctx)
+	payload := map[string]interface{}{
+		"comm": map[string]interface{}{
+			"g_tk":        5381,
+			"uin":         0,
+			"format":      "json",
+			"platform":    "h5",
+			"needNewCode": 1,
+		},
+		"req_0": map[string]interface{}{
+			"module": "musicToplist.ToplistInfoServer",
+			"method": "GetDetail",
+			"param": map[string]interface{}{
+				"topId":  id,
+				"offset": offset,
+				"num":    limit,
+				"period": "",
+			},
+		},
+	}
+	body, err := c.postJSON(ctx, musicuEndpoint+"?format=json&inCharset=utf8&outCharset=utf8", payload)
+	if err != nil {
+		return nil, err
+	}
+	var resp struct {
+		Code int `json:"code"`
+		Req0 struct {
+			Code int           `json:"code"`
+			Data qqToplistData `json:"data"`
+		} `json:"req_0"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, fmt.Errorf("qqmusic: decode toplist detail: %w", err)
+	}
+	if resp.Code != 0 || resp.Req0.Code != 0 {
+		return nil, platform.NewUnavailableError("qqmusic", "chart", topID)
+	}
+	return &resp.Req0.Data, nil
+}
+
+// convertToplist maps a toplist payload onto the unified playlist type. It is
+// kept apart from the request so the mapping is testable without network access.
+func convertToplist(data *qqToplistData, chart platform.Chart) (*platform.Playlist, error) {
+	if data == nil || (data.Data.TopID == 0 && len(data.SongInfoList) == 0) {
+		return nil, platform.NewNotFoundError("qqmusic", "chart", chart.ID)
+	}
+	tracks := make([]platform.Track, 0, len(data.SongInfoList))
+	for _, song := range data.SongInfoList {
+		track := convertPlaylistSong(song)
+		if track.ID == "" {
+			continue
+		}
+		tracks = append(tracks, track)
+	}
+	title := strings.TrimSpace(data.Data.Title)
+	if title == "" {
+		title = chart.Title
+	}
+	if period := strings.TrimSpace(data.Data.Period); period != "" {
+		title = fmt.Sprintf("%s (%s)", title, period)
+	}
+	coverURL := strings.TrimSpace(data.Data.FrontPicURL)
+	if coverURL == "" {
+		coverURL = strings.TrimSpace(data.Data.HeadPicURL)
+	}
+	trackCount := data.Data.TotalNum
+	if trackCount <= 0 {
+		trackCount = len(tracks)
+	}
+	return &platform.Playlist{
+		ID:          chart.ID,
+		Platform:    "qqmusic",
+		Title:       title,
+		Description: strings.TrimSpace(data.Data.Intro),
+		CoverURL:    coverURL,
+		TrackCount:  trackCount,
+		Tracks:      tracks,
+		URL:         "https://y.qq.com/n/ryqq_v2/toplist/" + chart.ID,
+	}, nil
+}
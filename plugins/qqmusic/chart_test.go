@@ -0,0 +1,66 @@
+package qqmusic
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/liuran001/MusicBot-Go/bot/platform"
+)
+
+// toplistFixture mirrors the live ToplistInfoServer.GetDetail payload (trimmed).
+const toplistFixture = `{"data":{"topId":62,"title":"飙升榜","intro":"每日更新","period":"2026-10-17",` +
+	`"frontPicUrl":"https://y.gtimg.cn/music/photo_new/T003R300x300M000x.jpg","totalNum":100},` +
+	`"songInfoList":[{"id":1,"mid":"003OUlho2HcRHC","name":"晴天","interval":269,` +
+	`"album":{"mid":"000MkMni19ClKG","name":"叶惠美"},"singer":[{"mid":"0025NhlN2yWrP4","name":"周杰伦"}]},` +
+	`{"id":0,"mid":"","name":"broken"}]}`
+
+func TestConvertToplist(t *testing.T) {
+	var data qqToplistData
+	if err := json.Unmarshal([]byte(toplistFixture), &data); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	playlist, err := convertToplist(&data, qqCharts[0])
+	if err != nil {
+		t.Fatalf("convertToplist() = %v", err)
+	}
+	if playlist.ID != "62" || playlist.Platform != "qqmusic" {
+		t.Fatalf("identity = %q/%q, want 62/qqmusic", playlist.ID, playlist.Platform)
+	}
+	if playlist.Title != "飙升榜 (2026-10-17)" {
+		t.Errorf("Title = %q", playlist.Title)
+	}
+	if playlist.TrackCount != 100 {
+		t.Errorf("TrackCount = %d, want 100", playlist.TrackCount)
+	}
+	// The entry without any ID must be dropped rather than produce a dead button.
+	if len(playlist.Tracks) != 1 {
+		t.Fatalf("len(Tracks) = %d, want 1", len(playlist.Tracks))
+	}
+	track := playlist.Tracks[0]
+	if track.ID != "003OUlho2HcRHC" || track.Title != "晴天" || track.Duration != 269*time.Second {
+		t.Errorf("track = %+v", track)
+	}
+	if len(track.Artists) != 1 || track.Artists[0].Name != "周杰伦" {
+		t.Errorf("artists = %+v", track.Artists)
+	}
+}
+
+func TestConvertToplistEmptyIsNotFound(t *testing.T) {
+	if _, err := convertToplist(&qqToplistData{}, qqCharts[0]); !errors.Is(err, platform.ErrNotFound) {
+		t.Fatalf("convertToplist(empty) error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestGetChartRejectsUnknownID(t *testing.T) {
+	p := NewPlatform(nil)
+	if _, err := p.GetChart(context.Background(), "999999"); !errors.Is(err, platform.ErrNotFound) {
+		t.Fatalf("GetChart(unknown) error = %v, want ErrNotFound", err)
+	}
+	charts, err := p.ListCharts(context.Background())
+	if err != nil || len(charts) == 0 {
+		t.Fatalf("ListCharts() = %v, %v", charts, err)
+	}
+}
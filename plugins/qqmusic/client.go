@@ -451,6 +451,7 @@ func (c *Client) GetSongDetail(ctx context.Context, id string) (*qqSongDetail, e
 	var resp struct {
 		Code int            `json:"code"`
 		Data []qqSongDetail `json:"data"`
+		Info qqSongInfo     `json:"info"`
 	}
 	if err := json.Unmarshal(body, &resp); err != nil {
 		return nil, fmt.Errorf("qqmusic: decode song detail: %w", err)
@@ -458,6 +459,7 @@ func (c *Client) GetSongDetail(ctx context.Context, id string) (*qqSongDetail, e
 	if resp.Code != 0 || len(resp.Data) == 0 {
 		return nil, platform.NewNotFoundError("qqmusic", "track", id)
 	}
+	resp.Data[0].Info = resp.Info
 	return &resp.Data[0], nil
 }
 
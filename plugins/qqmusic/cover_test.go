@@ -45,3 +45,14 @@ func TestConvertSongDetailLeavesCoverEmptyWhenAlbumMissing(t *testing.T) {
 		t.Fatalf("track.Album should be nil when album info missing")
 	}
 }
+
+func TestCoverURLAtSize(t *testing.T) {
+	q := &QQMusicPlatform{}
+	cover := buildAlbumCoverURL("001CMlm52RlccK")
+	if got, want := q.CoverURLAtSize(cover, 800), "https://y.gtimg.cn/music/photo_new/T002R800x800M000001CMlm52RlccK.jpg"; got != want {
+		t.Fatalf("CoverURLAtSize(800) = %q, want %q", got, want)
+	}
+	if got := q.CoverURLAtSize("https://y.gtimg.cn/music/photo_new/T002R300x300M000001CMlm52RlccK.jpg", 0); got != cover {
+		t.Fatalf("CoverURLAtSize(original) = %q, want %q", got, cover)
+	}
+}
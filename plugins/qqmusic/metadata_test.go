@@ -0,0 +1,30 @@
+package qqmusic
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestConvertSongDetailInfoCredits(t *testing.T) {
+	var info qqSongInfo
+	fixture := `{"company":{"title":"唱片公司","content":[{"value":"杰威尔音乐"}]},"genre":{"content":[{"value":"Pop 流行"}]},"pub_time":{"content":[{"value":"2003-07-31"}]}}`
+	if err := json.Unmarshal([]byte(fixture), &info); err != nil {
+		t.Fatalf("unmarshal fixture: %v", err)
+	}
+	track := convertSongDetail(&qqSongDetail{
+		Mid:   "0039MnYb0qxYhV",
+		Name:  "晴天",
+		Album: qqAlbum{Mid: "000MkMni19ClKG", Name: "叶惠美"},
+		Info:  info,
+	})
+	if track.Album == nil || track.Album.Label != "杰威尔音乐" {
+		t.Fatalf("album = %+v", track.Album)
+	}
+	if !reflect.DeepEqual(track.Genres, []string{"Pop 流行"}) {
+		t.Fatalf("genres = %v", track.Genres)
+	}
+	if track.ReleaseDate == nil || track.ReleaseDate.Format("2006-01-02") != "2003-07-31" {
+		t.Fatalf("release date = %v", track.ReleaseDate)
+	}
+}
@@ -262,6 +262,14 @@ func (q *QQMusicPlatform) GetArtistDetails(ctx context.Context, artistID string)
 	return q.client.GetSingerDetail(ctx, artistID)
 }
 
+// GetArtistReleases implements platform.ArtistReleaseProvider.
+func (q *QQMusicPlatform) GetArtistReleases(ctx context.Context, artistID string, limit int) ([]platform.Album, error) {
+	if q == nil || q.client == nil {
+		return nil, platform.NewUnavailableError("qqmusic", "artist", artistID)
+	}
+	return q.client.GetSingerAlbums(ctx, artistID, limit)
+}
+
 // MatchArtistURL implements platform.ArtistURLMatcher so a QQ Music singer link
 // pasted into chat resolves to the artist card.
 func (q *QQMusicPlatform) MatchArtistURL(rawURL string) (string, bool) {
@@ -675,6 +683,7 @@ func convertSongDetail(detail *qqSongDetail) platform.Track {
 			CoverURL: buildAlbumCoverURL(detail.Album.Mid),
 			URL:      buildAlbumURL(detail.Album.Mid),
 			Year:     albumYear,
+			Label:    detail.Info.Company.first(),
 		}
 	}
 	title := strings.TrimSpace(detail.Title)
@@ -700,7 +709,19 @@ func convertSongDetail(detail *qqSongDetail) platform.Track {
 		Year:        year,
 		TrackNumber: trackNo,
 		DiscNumber:  discNo,
+		ReleaseDate: parseQQDate(detail.Info.PubTime.first(), detail.TimePublic, detail.PubTime),
+		Genres:      detail.Info.Genre.values(),
+	}
+}
+
+// parseQQDate returns the first value that is a full YYYY-MM-DD date.
+func parseQQDate(values ...string) *time.Time {
+	for _, value := range values {
+		if t, err := time.Parse("2006-01-02", strings.TrimSpace(value)); err == nil {
+			return &t
+		}
 	}
+	return nil
 }
 
 func resolveQQDiscNumber(discNumber int, indexCD int) int {
@@ -30,9 +30,7 @@ func buildContribution(cfg *config.Config, logger *logpkg.Logger) (*platformplug
 	if intervalSec > 0 {
 		interval = time.Duration(intervalSec) * time.Second
 	}
-	persist := func(pairs map[string]string) error {
-		return cfg.PersistPluginConfig("qqmusic", pairs)
-	}
+	persist := cfg.PluginPersister("qqmusic")
 	client := NewClient(cookie, time.Duration(timeoutSec)*time.Second, logger, autoRenewEnabled, interval, persist)
 	if err := client.SetAPIProxy(cfg.ResolveAPIProxyConfig("qqmusic")); err != nil {
 		return nil, err
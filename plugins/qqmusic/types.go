@@ -1,5 +1,7 @@
 package qqmusic
 
+import "strings"
+
 type qqSinger struct {
 	ID   int64  `json:"id"`
 	Mid  string `json:"mid"`
@@ -66,6 +68,40 @@ type qqSongDetail struct {
 	IndexCD     int        `json:"index_cd"`
 	TrackNumber int        `json:"track_number"`
 	DiscNumber  int        `json:"disc_number"`
+	// Info is the response's top-level "info" block, attached after decoding.
+	Info qqSongInfo `json:"-"`
+}
+
+// qqSongInfo holds the song page's detail rows: record company, genre and
+// release date.
+type qqSongInfo struct {
+	Company qqInfoRow `json:"company"`
+	Genre   qqInfoRow `json:"genre"`
+	PubTime qqInfoRow `json:"pub_time"`
+}
+
+type qqInfoRow struct {
+	Content []struct {
+		Value string `json:"value"`
+	} `json:"content"`
+}
+
+// values returns the row's non-empty values.
+func (r qqInfoRow) values() []string {
+	var out []string
+	for _, item := range r.Content {
+		if value := strings.TrimSpace(item.Value); value != "" {
+			out = append(out, value)
+		}
+	}
+	return out
+}
+
+func (r qqInfoRow) first() string {
+	if values := r.values(); len(values) > 0 {
+		return values[0]
+	}
+	return ""
 }
 
 type qqFileInfo struct {
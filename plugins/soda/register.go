@@ -29,11 +29,12 @@ func buildContribution(cfg *config.Config, logger *logpkg.Logger) (*platformplug
 		cookie = strings.Trim(cfg.GetPluginString("soda", "cookie"), "`\"'")
 	}
 	client := NewClient(cookie, time.Duration(timeoutSec)*time.Second, logger)
+	persist := cfg.PluginPersister("soda")
 	client.persistFunc = func(pairs map[string]string) error {
 		if logger != nil {
 			logger.Debug("soda: persist plugin config", "pairs", pairs)
 		}
-		return cfg.PersistPluginConfig("soda", pairs)
+		return persist(pairs)
 	}
 	if err := client.SetAPIProxy(cfg.ResolveAPIProxyConfig("soda")); err != nil {
 		return nil, err
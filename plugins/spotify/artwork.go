@@ -0,0 +1,44 @@
+package spotify
+
+import "strings"
+
+// Spotify cover URLs are "https://i.scdn.co/image/<kind><size><hash>"; the
+// size code picks one of a few fixed renditions.
+const (
+	spotifyCoverKind     = "ab67616d0000"
+	spotifyCoverOriginal = "82c1" // the uploaded master, usually 2000px+
+)
+
+// spotifyCoverSizes are the fixed renditions, largest first.
+var spotifyCoverSizes = []struct {
+	code string
+	size int
+}{
+	{"b273", 640},
+	{"1e02", 300},
+	{"4851", 64},
+}
+
+// CoverURLAtSize implements platform.ArtworkProvider. Capped sizes round down
+// to the nearest rendition Spotify serves.
+func (p *SpotifyPlatform) CoverURLAtSize(coverURL string, size int) string {
+	i := strings.Index(coverURL, "/image/"+spotifyCoverKind)
+	if i < 0 {
+		return coverURL
+	}
+	codeStart := i + len("/image/"+spotifyCoverKind)
+	if len(coverURL) < codeStart+4 {
+		return coverURL
+	}
+	code := spotifyCoverOriginal
+	if size > 0 {
+		code = spotifyCoverSizes[len(spotifyCoverSizes)-1].code
+		for _, rendition := range spotifyCoverSizes {
+			if rendition.size <= size {
+				code = rendition.code
+				break
+			}
+		}
+	}
+	return coverURL[:codeStart] + code + coverURL[codeStart+4:]
+}
@@ -0,0 +1,30 @@
+package spotify
+
+import (
+	"context"
+
+	"github.com/liuran001/MusicBot-Go/bot/platform"
+)
+
+// spotifyCharts lists the editorial chart playlists exposed through /charts.
+var spotifyCharts = []platform.Chart{
+	{ID: "37i9dQZEVXbMDoHDwVN2tF", Platform: platformName, Title: "Top 50 - Global"},
+	{ID: "37i9dQZEVXbLiRSasKsNU9", Platform: platformName, Title: "Viral 50 - Global"},
+	{ID: "37i9dQZEVXbLRQDuF5jeBp", Platform: platformName, Title: "Top 50 - USA"},
+}
+
+// ListCharts implements platform.ChartProvider.
+func (p *SpotifyPlatform) ListCharts(ctx context.Context) ([]platform.Chart, error) {
+	_ = ctx
+	return append([]platform.Chart(nil), spotifyCharts...), nil
+}
+
+// GetChart implements platform.ChartProvider. Spotify charts are regular
+// playlists, so this only guards the ID and delegates to GetPlaylist.
+func (p *SpotifyPlatform) GetChart(ctx context.Context, chartID string) (*platform.Playlist, error) {
+	chart, ok := platform.FindChart(spotifyCharts, chartID)
+	if !ok {
+		return nil, platform.NewNotFoundError(platformName, "chart", chartID)
+	}
+	return p.GetPlaylist(ctx, chart.ID)
+}
@@ -358,9 +358,31 @@ func (c *Client) GetTrack(ctx context.Context, trackID string) (*platform.Track,
 		return nil, platform.ErrNotFound
 	}
 	track := convertTrack(t)
+	c.addAlbumCredits(ctx, &track)
 	return &track, nil
 }
 
+// addAlbumCredits fills the label, copyright and genres a track's simplified
+// album lacks from the full album. It is best effort: tagging works without.
+func (c *Client) addAlbumCredits(ctx context.Context, track *platform.Track) {
+	if track.Album == nil || strings.TrimSpace(track.Album.ID) == "" {
+		return
+	}
+	q := url.Values{}
+	q.Set("market", c.market)
+	var a spotifyAlbum
+	if err := c.apiGet(ctx, "/albums/"+url.PathEscape(track.Album.ID), q, &a); err != nil {
+		return
+	}
+	full := convertAlbum(a)
+	track.Album.Label = full.Label
+	track.Album.Copyright = full.Copyright
+	track.Album.Genres = full.Genres
+	if full.ReleaseDate != nil {
+		track.Album.ReleaseDate = full.ReleaseDate
+	}
+}
+
 func (c *Client) getTrackPathfinder(ctx context.Context, trackID string) (*platform.Track, error) {
 	var result pathfinderTrackResponse
 	if err := c.pathfinderQuery(ctx, getTrackOperation, getTrackPersistedHash, map[string]any{"uri": "spotify:track:" + trackID}, &result); err != nil {
@@ -584,6 +606,25 @@ func (c *Client) GetArtist(ctx context.Context, artistID string) (*platform.Arti
 	}, nil
 }
 
+// GetArtistReleases lists the artist's albums and singles, newest first. It
+// needs the Web API; the pathfinder fallback has no discography query here.
+func (c *Client) GetArtistReleases(ctx context.Context, artistID string, limit int) ([]platform.Album, error) {
+	if limit <= 0 || limit > 50 {
+		limit = 10
+	}
+	q := url.Values{}
+	q.Set("include_groups", "album,single")
+	q.Set("market", c.market)
+	q.Set("limit", strconv.Itoa(limit))
+	var page struct {
+		Items []spotifyAlbum `json:"items"`
+	}
+	if err := c.apiGet(ctx, "/artists/"+url.PathEscape(artistID)+"/albums", q, &page); err != nil {
+		return nil, err
+	}
+	return convertArtistReleases(page.Items, limit), nil
+}
+
 func (c *Client) getArtistPathfinder(ctx context.Context, artistID string) (*platform.Artist, error) {
 	var result pathfinderArtistResponse
 	if err := c.pathfinderQuery(ctx, queryArtistOperation, queryArtistPersistHash, map[string]any{"uri": "spotify:artist:" + artistID}, &result); err != nil {
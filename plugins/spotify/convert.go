@@ -145,6 +145,8 @@ func convertPathfinderAlbum(a pathfinderAlbum) platform.Album {
 		URL:         spotifyOpenURL("album", albumID),
 		Year:        a.Date.Year,
 		ReleaseDate: pathfinderDate(a.Date.ISOString, a.Date.Precision, a.Date.Year, a.Date.Month, a.Date.Day),
+		Label:       strings.TrimSpace(a.Label),
+		Copyright:   copyrightText(a.Copyright.Items),
 	}
 }
 
@@ -215,9 +217,45 @@ func convertAlbum(a spotifyAlbum) platform.Album {
 		URL:         a.ExternalURLs["spotify"],
 		Year:        parseReleaseYear(a.ReleaseDate),
 		ReleaseDate: parseReleaseDate(a.ReleaseDate, a.ReleaseDatePrecision),
+		Label:       strings.TrimSpace(a.Label),
+		Copyright:   copyrightText(a.Copyrights),
+		Genres:      a.Genres,
 	}
 }
 
+// copyrightText picks the sound recording (℗) line, which is what a file's
+// copyright tag describes, falling back to the first line.
+func copyrightText(items []spotifyCopyright) string {
+	for _, item := range items {
+		if strings.EqualFold(item.Type, "P") && strings.TrimSpace(item.Text) != "" {
+			return strings.TrimSpace(item.Text)
+		}
+	}
+	for _, item := range items {
+		if text := strings.TrimSpace(item.Text); text != "" {
+			return text
+		}
+	}
+	return ""
+}
+
+// convertArtistReleases maps an artist's album page to releases, newest first.
+// Spotify groups albums before singles, so the page is re-sorted by date.
+func convertArtistReleases(items []spotifyAlbum, limit int) []platform.Album {
+	releases := make([]platform.Album, 0, len(items))
+	for _, item := range items {
+		if strings.TrimSpace(item.ID) == "" {
+			continue
+		}
+		releases = append(releases, convertAlbum(item))
+	}
+	platform.SortReleasesNewestFirst(releases)
+	if limit > 0 && len(releases) > limit {
+		releases = releases[:limit]
+	}
+	return releases
+}
+
 // firstImage returns the URL of the first (largest) image, or "".
 func firstImage(images []spotifyImage) string {
 	if len(images) == 0 {
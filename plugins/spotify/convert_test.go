@@ -44,3 +44,47 @@ func TestConvertPathfinderTrack(t *testing.T) {
 		t.Fatalf("release date = %v", got.Album.ReleaseDate)
 	}
 }
+
+func TestConvertArtistReleasesSortsSinglesIn(t *testing.T) {
+	items := []spotifyAlbum{
+		{ID: "album-old", Name: "Album", ReleaseDate: "2020-01-10", ReleaseDatePrecision: "day"},
+		{ID: "", Name: "Broken"},
+		{ID: "single-new", Name: "Single", ReleaseDate: "2024-03-01", ReleaseDatePrecision: "day"},
+	}
+	got := convertArtistReleases(items, 10)
+	if len(got) != 2 {
+		t.Fatalf("len = %d, want 2", len(got))
+	}
+	if got[0].ID != "single-new" || got[1].ID != "album-old" {
+		t.Fatalf("order = %s,%s; want single-new,album-old", got[0].ID, got[1].ID)
+	}
+}
+
+func TestConvertAlbumCredits(t *testing.T) {
+	album := convertAlbum(spotifyAlbum{
+		ID:     "album-id",
+		Label:  " Label ",
+		Genres: []string{"pop"},
+		Copyrights: []spotifyCopyright{
+			{Text: "© 2024 Publisher", Type: "C"},
+			{Text: "℗ 2024 Label", Type: "P"},
+		},
+	})
+	if album.Label != "Label" || album.Copyright != "℗ 2024 Label" || len(album.Genres) != 1 {
+		t.Fatalf("album credits = %q, %q, %v", album.Label, album.Copyright, album.Genres)
+	}
+}
+
+func TestCoverURLAtSize(t *testing.T) {
+	p := &SpotifyPlatform{}
+	cover := "https://i.scdn.co/image/ab67616d0000b273abcdef0123456789abcdef01"
+	for size, want := range map[int]string{
+		0:    "https://i.scdn.co/image/ab67616d000082c1abcdef0123456789abcdef01",
+		1400: "https://i.scdn.co/image/ab67616d0000b273abcdef0123456789abcdef01",
+		600:  "https://i.scdn.co/image/ab67616d00001e02abcdef0123456789abcdef01",
+	} {
+		if got := p.CoverURLAtSize(cover, size); got != want {
+			t.Fatalf("CoverURLAtSize(%d) = %q, want %q", size, got, want)
+		}
+	}
+}
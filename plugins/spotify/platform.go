@@ -106,6 +106,14 @@ func (p *SpotifyPlatform) GetAlbum(ctx context.Context, albumID string) (*platfo
 	return p.client.GetAlbum(ctx, albumID)
 }
 
+// GetArtistReleases implements platform.ArtistReleaseProvider.
+func (p *SpotifyPlatform) GetArtistReleases(ctx context.Context, artistID string, limit int) ([]platform.Album, error) {
+	if p == nil || p.client == nil {
+		return nil, platform.NewUnavailableError(platformName, "artist", artistID)
+	}
+	return p.client.GetArtistReleases(ctx, artistID, limit)
+}
+
 // GetPlaylist resolves both real playlists and albums (the URL matcher encodes
 // albums as "album:<id>" so they can be browsed as track lists).
 func (p *SpotifyPlatform) GetPlaylist(ctx context.Context, playlistID string) (*platform.Playlist, error) {
@@ -23,19 +23,29 @@ type spotifyArtist struct {
 }
 
 type spotifyAlbum struct {
-	ID                   string            `json:"id"`
-	Name                 string            `json:"name"`
-	Artists              []spotifyArtist   `json:"artists"`
-	Images               []spotifyImage    `json:"images"`
-	ReleaseDate          string            `json:"release_date"`
-	ReleaseDatePrecision string            `json:"release_date_precision"`
-	TotalTracks          int               `json:"total_tracks"`
-	ExternalURLs         map[string]string `json:"external_urls"`
+	ID                   string             `json:"id"`
+	Name                 string             `json:"name"`
+	Artists              []spotifyArtist    `json:"artists"`
+	Images               []spotifyImage     `json:"images"`
+	ReleaseDate          string             `json:"release_date"`
+	ReleaseDatePrecision string             `json:"release_date_precision"`
+	TotalTracks          int                `json:"total_tracks"`
+	ExternalURLs         map[string]string  `json:"external_urls"`
+	Label                string             `json:"label"`
+	Genres               []string           `json:"genres"`
+	Copyrights           []spotifyCopyright `json:"copyrights"`
 	Tracks               struct {
 		Items []spotifyTrack `json:"items"`
 	} `json:"tracks"`
 }
 
+// spotifyCopyright is one copyright line; Type is "C" for the composition
+// and "P" for the sound recording.
+type spotifyCopyright struct {
+	Text string `json:"text"`
+	Type string `json:"type"`
+}
+
 type spotifyTrack struct {
 	ID           string            `json:"id"`
 	Name         string            `json:"name"`
@@ -182,6 +192,10 @@ type pathfinderAlbum struct {
 	Artists struct {
 		Items []pathfinderArtist `json:"items"`
 	} `json:"artists"`
+	Label     string `json:"label"`
+	Copyright struct {
+		Items []spotifyCopyright `json:"items"`
+	} `json:"copyright"`
 	TracksV2 struct {
 		Items []struct {
 			Track pathfinderTrack `json:"track"`